@@ -0,0 +1,132 @@
+//! The stability policy of the C ABI.
+//!
+//! The ABI has a major and a minor version:
+//!
+//! - The minor version is bumped when a symbol is added. Bindings built against an older
+//!   minor version keep working, since every symbol they call still exists.
+//! - When the signature or the meaning of a symbol needs to change, a new symbol is added
+//!   with a new name and the minor version is bumped. The old symbol is kept as a wrapper
+//!   around the new one and marked `#[deprecated]`, which names its replacement.
+//! - The major version is only bumped when deprecated symbols are removed, or when a
+//!   `#[repr(C)]` struct that is passed across the ABI changes its layout. The layouts are
+//!   checked below at compile time, so a change to them cannot go unnoticed.
+//!
+//! Every symbol is also listed in `bindings/c/eth_kzg.map`, which is the list of symbols that
+//! the shared library built by `scripts/compile_shared_lib.sh` exports. A test below checks
+//! that the list matches the functions of `lib.rs`.
+//!
+//! Bindings call `eth_kzg_abi_negotiate` with the version they were generated against
+//! when they are loaded, so that a mismatched library is reported with
+//! [`ErrorCode::AbiVersionMismatch`] instead of crashing on the first call.
+//!
+//! History of the minor version:
+//!
+//! 1. `eth_kzg_abi_negotiate` and `eth_kzg_recover_cells_and_kzg_proofs`, which replaces
+//!    the deprecated `eth_kzg_recover_cells_and_proofs`.
+//! 2. The `ProtocolConfig` struct, `eth_kzg_protocol_config_mainnet`,
+//!    `eth_kzg_protocol_config_minimal`, `eth_kzg_das_context_new_with_protocol_config` and
+//!    `eth_kzg_das_context_protocol_config`. These were withdrawn before they were released,
+//!    since a context could only be created for the sizes that the library was compiled with,
+//!    so no released library has them.
+//! 3. `ETH_KZG_FORK_DENEB`, `ETH_KZG_FORK_FULU` and `eth_kzg_das_context_new_for_forks`.
+//! 4. The `Stats` struct, `eth_kzg_das_context_stats` and `eth_kzg_das_context_operation_count`.
+//! 5. The `DASScratch` struct, `eth_kzg_das_scratch_new`, `eth_kzg_das_scratch_free` and
+//!    `eth_kzg_compute_cells_and_kzg_proofs_with_scratch`.
+//! 6. `eth_kzg_das_context_set_verification_cache` and
+//!    `eth_kzg_das_context_verification_cache_stats`.
+//! 7. `eth_kzg_das_context_set_admission_limits`, and the `Overloaded` and `AdmissionTimedOut`
+//!    error codes.
+//! 8. `ETH_KZG_PRECOMP_COMPRESSED`.
+//! 9. `eth_kzg_compute_cells_with_scratch` and `eth_kzg_recover_cells_and_kzg_proofs_with_scratch`.
+
+use std::{
+    ffi::c_void,
+    mem::{align_of, offset_of, size_of},
+    os::raw::c_char,
+};
+
+use crate::{CResult, CResultStatus, ErrorCode, MemoryUsage, MetricsCallback, Stats};
+
+/// The major version of the C ABI. See `bindings/c/src/abi.rs` for the stability policy.
+pub const ETH_KZG_ABI_VERSION_MAJOR: u32 = 1;
+
+/// The minor version of the C ABI. See `bindings/c/src/abi.rs` for the stability policy.
+pub const ETH_KZG_ABI_VERSION_MINOR: u32 = 9;
+
+const POINTER_SIZE: usize = size_of::<*const c_void>();
+
+// The status is passed as a C enum, which has the size of an int.
+const _: () = assert!(size_of::<CResultStatus>() == 4);
+
+const _: () = assert!(offset_of!(CResult, status) == 0);
+const _: () = assert!(offset_of!(CResult, error_msg) == POINTER_SIZE);
+const _: () = assert!(offset_of!(CResult, error_code) == 2 * POINTER_SIZE);
+const _: () = assert!(size_of::<CResult>() == 3 * POINTER_SIZE);
+const _: () = assert!(align_of::<CResult>() == align_of::<*mut c_char>());
+
+const _: () = assert!(offset_of!(MemoryUsage, srs) == 0);
+const _: () = assert!(offset_of!(MemoryUsage, fk20_precomputations) == 8);
+const _: () = assert!(offset_of!(MemoryUsage, domains) == 16);
+const _: () = assert!(offset_of!(MemoryUsage, total) == 24);
+const _: () = assert!(size_of::<MemoryUsage>() == 32);
+
+const _: () = assert!(offset_of!(Stats, operations) == 0);
+const _: () = assert!(offset_of!(Stats, failed_operations) == 8);
+const _: () = assert!(offset_of!(Stats, precomputation_bytes) == 16);
+const _: () = assert!(offset_of!(Stats, memory_bytes) == 24);
+const _: () = assert!(offset_of!(Stats, scratch_bytes) == 32);
+const _: () = assert!(size_of::<Stats>() == 40);
+
+// A null callback is passed as a null function pointer.
+const _: () = assert!(size_of::<Option<MetricsCallback>>() == POINTER_SIZE);
+
+pub(crate) fn _abi_negotiate(major: u32, minor: u32) -> Result<(), CResult> {
+    // Computation
+    //
+    if major != ETH_KZG_ABI_VERSION_MAJOR || minor > ETH_KZG_ABI_VERSION_MINOR {
+        return Err(CResult::with_error(
+            ErrorCode::AbiVersionMismatch,
+            &format!(
+                "the binding expects ABI version {major}.{minor}, but the library has ABI version {ETH_KZG_ABI_VERSION_MAJOR}.{ETH_KZG_ABI_VERSION_MINOR}"
+            ),
+        ));
+    }
+
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    /// The source of the exported functions.
+    const LIB: &str = include_str!("lib.rs");
+
+    /// The version script of the shared library.
+    const MAP: &str = include_str!("../eth_kzg.map");
+
+    #[test]
+    fn every_exported_function_is_in_the_version_script() {
+        let mut defined: Vec<_> = LIB
+            .lines()
+            .filter_map(|line| {
+                line.strip_prefix("pub extern \"C\" fn ")
+                    .or_else(|| line.strip_prefix("pub unsafe extern \"C\" fn "))
+            })
+            .filter_map(|rest| rest.split('(').next())
+            .collect();
+        let mut listed: Vec<_> = MAP
+            .lines()
+            .filter_map(|line| line.trim().strip_suffix(';'))
+            .filter(|symbol| symbol.starts_with("eth_kzg_"))
+            .collect();
+
+        defined.sort_unstable();
+        listed.sort_unstable();
+        assert_eq!(defined, listed);
+    }
+
+    #[test]
+    fn version_node_matches_the_major_version() {
+        let node = format!("ETH_KZG_{} {{", super::ETH_KZG_ABI_VERSION_MAJOR);
+        assert!(MAP.lines().any(|line| line == node));
+    }
+}
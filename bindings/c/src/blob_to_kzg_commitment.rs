@@ -10,18 +10,14 @@ pub(crate) fn _blob_to_kzg_commitment(
     blob: *const u8,
     out: *mut u8,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob);
+    let ctx = deref_const(ctx)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
 
     // Computation
     //
-    let commitment = ctx
-        .blob_to_kzg_commitment(blob)
-        .map_err(|err| CResult::with_error(&format!("{err:?}")))?;
+    let commitment = ctx.blob_to_kzg_commitment(blob).map_err(CResult::from)?;
 
     assert!(
         commitment.len() == BYTES_PER_COMMITMENT,
@@ -32,7 +28,7 @@ pub(crate) fn _blob_to_kzg_commitment(
 
     // Write output to slice
     //
-    write_to_slice(out, &commitment);
+    write_to_slice(out, &commitment)?;
 
     Ok(())
 }
@@ -11,19 +11,17 @@ pub(crate) fn _compute_blob_kzg_proof(
     commitment: *const u8,
     out_proof: *mut u8,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob);
-    let commitment = create_array_ref::<BYTES_PER_COMMITMENT, _>(commitment);
+    let ctx = deref_const(ctx)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
+    let commitment = create_array_ref::<BYTES_PER_COMMITMENT, _>(commitment)?;
 
     // Computation
     //
     let proof = ctx
         .compute_blob_kzg_proof(blob, commitment)
-        .map_err(|err| CResult::with_error(&format!("{err:?}")))?;
+        .map_err(CResult::from)?;
 
     assert!(
         proof.len() == BYTES_PER_COMMITMENT,
@@ -34,7 +32,7 @@ pub(crate) fn _compute_blob_kzg_proof(
 
     // Write output to slice
     //
-    write_to_slice(out_proof, &proof);
+    write_to_slice(out_proof, &proof)?;
 
     Ok(())
 }
@@ -11,23 +11,21 @@ pub(crate) fn _compute_cells_and_kzg_proofs(
     out_cells: *mut *mut u8,
     out_proofs: *mut *mut u8,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Pointer checks
     //
-    let ctx = deref_const(ctx);
-    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob);
+    let ctx = deref_const(ctx)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
 
     // Computation
     //
     let (cells, proofs) = ctx
         .compute_cells_and_kzg_proofs(blob)
-        .map_err(|err| CResult::with_error(&format!("{err:?}")))?;
+        .map_err(CResult::from)?;
     let cells_unboxed = cells.map(|cell| cell.to_vec());
 
     // Write to output
-    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells_unboxed);
-    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_proofs, proofs);
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells_unboxed)?;
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_proofs, proofs)?;
 
     Ok(())
 }
@@ -36,22 +34,18 @@ pub(crate) fn _compute_cells(
     blob: *const u8,
     out_cells: *mut *mut u8,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Pointer checks
     //
-    let ctx = deref_const(ctx);
-    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob);
+    let ctx = deref_const(ctx)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
 
     // Computation
     //
-    let cells = ctx
-        .compute_cells(blob)
-        .map_err(|err| CResult::with_error(&format!("{err:?}")))?;
+    let cells = ctx.compute_cells(blob).map_err(CResult::from)?;
     let cells_unboxed = cells.map(|cell| cell.to_vec());
 
     // Write to output
-    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells_unboxed);
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells_unboxed)?;
 
     Ok(())
 }
@@ -12,19 +12,15 @@ pub(crate) fn _compute_kzg_proof(
     out_proof: *mut u8,
     out_y: *mut u8,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob);
-    let z = create_array_ref::<BYTES_PER_FIELD_ELEMENT, _>(z);
+    let ctx = deref_const(ctx)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
+    let z = create_array_ref::<BYTES_PER_FIELD_ELEMENT, _>(z)?;
 
     // Computation
     //
-    let (proof, y) = ctx
-        .compute_kzg_proof(blob, *z)
-        .map_err(|err| CResult::with_error(&format!("{err:?}")))?;
+    let (proof, y) = ctx.compute_kzg_proof(blob, *z).map_err(CResult::from)?;
 
     assert!(
         proof.len() == BYTES_PER_COMMITMENT,
@@ -42,8 +38,8 @@ pub(crate) fn _compute_kzg_proof(
 
     // Write output to slices
     //
-    write_to_slice(out_proof, &proof);
-    write_to_slice(out_y, &y);
+    write_to_slice(out_proof, &proof)?;
+    write_to_slice(out_y, &y)?;
 
     Ok(())
 }
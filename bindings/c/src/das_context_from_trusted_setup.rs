@@ -0,0 +1,191 @@
+use std::{
+    os::raw::c_char,
+    panic::{catch_unwind, AssertUnwindSafe},
+};
+
+use rust_eth_kzg::{BuildError, DASContextBuilder, Error, ErrorCode, TrustedSetup, UsePrecomp};
+
+use crate::{
+    pointer_utils::{c_str, create_slice_view, deref_mut, into_raw},
+    CResult, DASContext, ETH_KZG_PRECOMP_COMPRESSED,
+};
+
+pub(crate) fn _das_context_new(
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let out = deref_mut(out)?;
+    let use_precomp = use_precomp(precomp_width)?;
+
+    // Computation
+    //
+    // Without a trusted setup, the builder uses the embedded one, or returns an error if the
+    // library was built without it.
+    let ctx = build_context(rust_eth_kzg::DASContext::builder().precompute(use_precomp))?;
+
+    // Write output
+    //
+    *out = into_raw(ctx);
+
+    Ok(())
+}
+
+pub(crate) fn _das_context_new_from_trusted_setup(
+    trusted_setup: *const u8,
+    trusted_setup_len: usize,
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let out = deref_mut(out)?;
+    if trusted_setup.is_null() || trusted_setup_len == 0 {
+        return Err(CResult::with_error(
+            ErrorCode::InvalidArgument,
+            "no trusted setup was provided: a trusted setup must be passed in when the library is built without an embedded setup",
+        ));
+    }
+    let trusted_setup = create_slice_view(trusted_setup, trusted_setup_len as u64)?;
+    let trusted_setup = std::str::from_utf8(trusted_setup).map_err(|err| {
+        CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!("trusted setup is not valid UTF-8: {err}"),
+        )
+    })?;
+
+    // Computation
+    //
+    let trusted_setup = load_trusted_setup(|| Ok(TrustedSetup::parse(trusted_setup)))?;
+
+    // Write output
+    //
+    write_context(&trusted_setup, precomp_width, out)
+}
+
+pub(crate) fn _das_context_new_from_path(
+    path: *const c_char,
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let out = deref_mut(out)?;
+    if path.is_null() {
+        return Err(CResult::with_error(
+            ErrorCode::InvalidArgument,
+            "no trusted setup path was provided",
+        ));
+    }
+    let path = c_str(path)?.to_str().map_err(|err| {
+        CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!("path is not valid UTF-8: {err}"),
+        )
+    })?;
+
+    // Computation
+    //
+    let trusted_setup = load_trusted_setup(|| TrustedSetup::from_path(path))?;
+
+    // Write output
+    //
+    write_context(&trusted_setup, precomp_width, out)
+}
+
+pub(crate) fn _das_context_new_from_env(
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let out = deref_mut(out)?;
+
+    // Computation
+    //
+    let trusted_setup = load_trusted_setup(TrustedSetup::from_env)?;
+
+    // Write output
+    //
+    write_context(&trusted_setup, precomp_width, out)
+}
+
+/// Loads a trusted setup, converting both errors and panics into a `CResult`.
+///
+/// The trusted setup parsers panic on malformed input, since they are normally
+/// called on startup. We must not unwind across the FFI boundary, so the panic
+/// is turned into an error instead.
+pub(crate) fn load_trusted_setup(
+    load: impl FnOnce() -> std::io::Result<TrustedSetup>,
+) -> Result<TrustedSetup, CResult> {
+    match catch_unwind(AssertUnwindSafe(load)) {
+        Ok(Ok(trusted_setup)) => Ok(trusted_setup),
+        Ok(Err(err)) => Err(CResult::with_error(
+            ErrorCode::TrustedSetupUnreadable,
+            &format!("could not load trusted setup: {err}"),
+        )),
+        Err(_) => Err(CResult::with_error(
+            ErrorCode::TrustedSetupMalformed,
+            "trusted setup is malformed",
+        )),
+    }
+}
+
+/// Returns the precomputation policy for a width passed across the FFI, which may have the
+/// `ETH_KZG_PRECOMP_COMPRESSED` flag.
+///
+/// Every constructor goes through this, so that a width whose tables could not be allocated
+/// is rejected instead of overflowing.
+pub(crate) fn use_precomp(precomp_width: usize) -> Result<UsePrecomp, CResult> {
+    let width = precomp_width & !ETH_KZG_PRECOMP_COMPRESSED;
+    let use_precomp = if precomp_width & ETH_KZG_PRECOMP_COMPRESSED == 0 {
+        UsePrecomp::from_width(width)
+    } else {
+        UsePrecomp::compressed_from_width(width)
+    };
+    use_precomp.ok_or_else(|| {
+        CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!(
+                "precomputation width must be at most {}, got {width}",
+                UsePrecomp::MAX_WIDTH
+            ),
+        )
+    })
+}
+
+/// Creates a context with the builder, converting both errors and panics into a `CResult`.
+///
+/// The builder rejects setups that are too short, but like in [`load_trusted_setup`], a
+/// panic while the contexts are created must not unwind across the FFI boundary.
+pub(crate) fn build_context(builder: DASContextBuilder<'_>) -> Result<DASContext, CResult> {
+    match catch_unwind(AssertUnwindSafe(|| builder.build())) {
+        Ok(Ok(inner)) => Ok(DASContext::new(inner)),
+        Ok(Err(BuildError::TrustedSetup(err))) => Err(Error::TrustedSetup(err).into()),
+        Ok(Err(err)) => Err(CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!("could not create a context: {err:?}"),
+        )),
+        Err(_) => Err(CResult::with_error(
+            ErrorCode::TrustedSetupMalformed,
+            "could not create a context from the trusted setup",
+        )),
+    }
+}
+
+fn write_context(
+    trusted_setup: &TrustedSetup,
+    precomp_width: usize,
+    out: &mut *mut DASContext,
+) -> Result<(), CResult> {
+    let use_precomp = use_precomp(precomp_width)?;
+    let ctx = build_context(
+        rust_eth_kzg::DASContext::builder()
+            .trusted_setup(trusted_setup)
+            .precompute(use_precomp),
+    )?;
+    *out = into_raw(ctx);
+
+    Ok(())
+}
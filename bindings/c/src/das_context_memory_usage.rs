@@ -0,0 +1,29 @@
+use crate::{
+    pointer_utils::{deref_const, deref_mut},
+    CResult, DASContext, MemoryUsage,
+};
+
+pub(crate) fn _das_context_memory_usage(
+    ctx: *const DASContext,
+    out: *mut MemoryUsage,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+    let out = deref_mut(out)?;
+
+    // Computation
+    //
+    let breakdown = ctx.memory_usage();
+
+    // Write output
+    //
+    *out = MemoryUsage {
+        srs: breakdown.srs as u64,
+        fk20_precomputations: breakdown.fk20_precomputations as u64,
+        domains: breakdown.domains as u64,
+        total: breakdown.total() as u64,
+    };
+
+    Ok(())
+}
@@ -0,0 +1,44 @@
+use rust_eth_kzg::{ErrorCode, TrustedSetup};
+
+use crate::{
+    das_context_from_trusted_setup::{build_context, load_trusted_setup, use_precomp},
+    pointer_utils::{deref_mut, into_raw},
+    CResult, DASContext, ETH_KZG_FORK_DENEB, ETH_KZG_FORK_FULU,
+};
+
+pub(crate) fn _das_context_new_for_forks(
+    forks: u32,
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let out = deref_mut(out)?;
+    // The blob methods cannot be turned off, so a context without Deneb is rejected instead
+    // of serving them anyway.
+    let known_forks = ETH_KZG_FORK_DENEB | ETH_KZG_FORK_FULU;
+    if forks & ETH_KZG_FORK_DENEB == 0 || forks & !known_forks != 0 {
+        return Err(CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!("forks must include Deneb and no unknown flags, got {forks:#x}"),
+        ));
+    }
+    let use_precomp = use_precomp(precomp_width)?;
+
+    // Computation
+    //
+    let trusted_setup = load_trusted_setup(TrustedSetup::from_env)?;
+    // Deneb only needs the blob methods, which do not use the cell prover tables.
+    let ctx = build_context(
+        rust_eth_kzg::DASContext::builder()
+            .trusted_setup(&trusted_setup)
+            .precompute(use_precomp)
+            .verifier_only(forks & ETH_KZG_FORK_FULU == 0),
+    )?;
+
+    // Write output
+    //
+    *out = into_raw(ctx);
+
+    Ok(())
+}
@@ -0,0 +1,16 @@
+use crate::{pointer_utils::deref_const, CResult, DASContext, ErrorCode};
+
+pub(crate) fn _das_context_self_test(ctx: *const DASContext) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+
+    // Computation
+    //
+    ctx.self_test().map_err(|err| {
+        CResult::with_error(
+            ErrorCode::SelfTestFailed,
+            &format!("self test failed: {err:?}"),
+        )
+    })
+}
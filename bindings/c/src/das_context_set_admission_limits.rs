@@ -0,0 +1,32 @@
+use std::time::Duration;
+
+use rust_eth_kzg::AdmissionLimits;
+
+use crate::{
+    pointer_utils::{checked_usize, deref_mut},
+    CResult, DASContext,
+};
+
+pub(crate) fn _das_context_set_admission_limits(
+    ctx: *mut DASContext,
+    max_in_flight: u64,
+    max_queued: u64,
+    queue_timeout_millis: u64,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_mut(ctx)?;
+    let max_in_flight = checked_usize(max_in_flight, "max_in_flight")?;
+    let max_queued = checked_usize(max_queued, "max_queued")?;
+
+    // Computation
+    //
+    let limits = (max_in_flight != 0).then(|| {
+        let queue_timeout =
+            (queue_timeout_millis != 0).then(|| Duration::from_millis(queue_timeout_millis));
+        AdmissionLimits::new(max_in_flight).with_queue(max_queued, queue_timeout)
+    });
+    ctx.set_admission_limits(limits);
+
+    Ok(())
+}
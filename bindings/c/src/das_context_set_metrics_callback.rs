@@ -0,0 +1,48 @@
+use std::{ffi::c_void, sync::Arc};
+
+use rust_eth_kzg::{Measurement, Metrics};
+
+use crate::{pointer_utils::deref_mut, CResult, DASContext, MetricsCallback};
+
+/// Forwards the measurements of a context to a C callback.
+struct CallbackMetrics {
+    callback: MetricsCallback,
+    // Stored as an integer, since raw pointers are neither `Send` nor `Sync`.
+    user_data: usize,
+}
+
+// The caller of `eth_kzg_das_context_set_metrics_callback` guarantees that the callback
+// can be called from any thread, with the same `user_data`.
+impl Metrics for CallbackMetrics {
+    fn record(&self, measurement: &Measurement) {
+        (self.callback)(
+            self.user_data as *mut c_void,
+            measurement.operation.as_u32(),
+            measurement.batch_size as u64,
+            u64::try_from(measurement.duration.as_nanos()).unwrap_or(u64::MAX),
+            measurement.error.map_or(0, |code| code.as_u32()),
+        );
+    }
+}
+
+pub(crate) fn _das_context_set_metrics_callback(
+    ctx: *mut DASContext,
+    callback: Option<MetricsCallback>,
+    user_data: *mut c_void,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_mut(ctx)?;
+
+    // Computation
+    //
+    let metrics = callback.map(|callback| {
+        Arc::new(CallbackMetrics {
+            callback,
+            user_data: user_data as usize,
+        }) as Arc<dyn Metrics>
+    });
+    ctx.set_metrics(metrics);
+
+    Ok(())
+}
@@ -0,0 +1,19 @@
+use crate::{pointer_utils::deref_mut, CResult, DASContext, ErrorCode};
+
+pub(crate) fn _das_context_set_num_threads(
+    ctx: *mut DASContext,
+    num_threads: usize,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_mut(ctx)?;
+
+    // Computation
+    //
+    ctx.set_num_threads(num_threads).map_err(|err| {
+        CResult::with_error(
+            ErrorCode::ThreadPoolCreationFailed,
+            &format!("could not create thread pool: {err}"),
+        )
+    })
+}
@@ -0,0 +1,123 @@
+use std::sync::{
+    atomic::{AtomicU64, Ordering},
+    Arc,
+};
+
+use rust_eth_kzg::{ErrorCode, Measurement, Metrics, Operation};
+
+use crate::{
+    pointer_utils::{deref_const, deref_mut},
+    CResult, DASContext, Stats,
+};
+
+const NUM_OPERATIONS: usize = Operation::ALL.len();
+
+/// The number of times each operation of a context was called, and has failed.
+#[derive(Debug)]
+pub(crate) struct OperationCounters {
+    calls: [AtomicU64; NUM_OPERATIONS],
+    failures: [AtomicU64; NUM_OPERATIONS],
+}
+
+impl Default for OperationCounters {
+    fn default() -> Self {
+        Self {
+            calls: std::array::from_fn(|_| AtomicU64::new(0)),
+            failures: std::array::from_fn(|_| AtomicU64::new(0)),
+        }
+    }
+}
+
+impl OperationCounters {
+    /// Returns the calls and failures of the operation with the given number, or `None` if
+    /// there is no such operation.
+    fn get(&self, operation: u32) -> Option<(u64, u64)> {
+        let index = (operation as usize).checked_sub(1)?;
+        let calls = self.calls.get(index)?.load(Ordering::Relaxed);
+        let failures = self.failures[index].load(Ordering::Relaxed);
+        Some((calls, failures))
+    }
+
+    fn totals(&self) -> (u64, u64) {
+        let sum = |counters: &[AtomicU64]| {
+            counters
+                .iter()
+                .map(|counter| counter.load(Ordering::Relaxed))
+                .sum()
+        };
+        (sum(&self.calls), sum(&self.failures))
+    }
+}
+
+/// Counts every operation of a context, and forwards it to the metrics hook set by the
+/// caller, if there is one.
+pub(crate) struct CountingMetrics {
+    pub(crate) counters: Arc<OperationCounters>,
+    pub(crate) forward: Option<Arc<dyn Metrics>>,
+}
+
+impl Metrics for CountingMetrics {
+    fn record(&self, measurement: &Measurement) {
+        let index = measurement.operation.as_u32() as usize - 1;
+        self.counters.calls[index].fetch_add(1, Ordering::Relaxed);
+        if measurement.error.is_some() {
+            self.counters.failures[index].fetch_add(1, Ordering::Relaxed);
+        }
+        if let Some(forward) = &self.forward {
+            forward.record(measurement);
+        }
+    }
+}
+
+pub(crate) fn _das_context_stats(ctx: *const DASContext, out: *mut Stats) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+    let out = deref_mut(out)?;
+
+    // Computation
+    //
+    let (operations, failed_operations) = ctx.counters.totals();
+    let memory = ctx.memory_usage();
+
+    // Write output
+    //
+    *out = Stats {
+        operations,
+        failed_operations,
+        precomputation_bytes: memory.fk20_precomputations as u64,
+        memory_bytes: memory.total() as u64,
+        scratch_bytes: ctx.scratch_bytes.load(Ordering::Relaxed),
+    };
+
+    Ok(())
+}
+
+pub(crate) fn _das_context_operation_count(
+    ctx: *const DASContext,
+    operation: u32,
+    out_calls: *mut u64,
+    out_failures: *mut u64,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+    let out_calls = deref_mut(out_calls)?;
+    let out_failures = deref_mut(out_failures)?;
+
+    // Computation
+    //
+    let (calls, failures) = ctx.counters.get(operation).ok_or_else(|| {
+        CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!("unknown operation: {operation}"),
+        )
+    })?;
+
+    // Write output
+    //
+    *out_calls = calls;
+    *out_failures = failures;
+
+    Ok(())
+}
@@ -0,0 +1,43 @@
+use crate::{
+    pointer_utils::{checked_usize, deref_const, deref_mut},
+    CResult, DASContext,
+};
+
+pub(crate) fn _das_context_set_verification_cache(
+    ctx: *mut DASContext,
+    capacity: u64,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_mut(ctx)?;
+    let capacity = checked_usize(capacity, "capacity")?;
+
+    // Computation
+    //
+    ctx.set_verification_cache(Some(capacity));
+
+    Ok(())
+}
+
+pub(crate) fn _das_context_verification_cache_stats(
+    ctx: *const DASContext,
+    out_hits: *mut u64,
+    out_misses: *mut u64,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+    let out_hits = deref_mut(out_hits)?;
+    let out_misses = deref_mut(out_misses)?;
+
+    // Computation
+    //
+    let stats = ctx.verification_cache_stats().unwrap_or_default();
+
+    // Write output
+    //
+    *out_hits = stats.hits;
+    *out_misses = stats.misses;
+
+    Ok(())
+}
@@ -0,0 +1,138 @@
+use std::sync::{atomic::Ordering, Arc};
+
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_CELL, CELLS_PER_EXT_BLOB},
+    ErrorCode,
+};
+
+use crate::{
+    pointer_utils::{
+        create_array_ref, create_slice_view, deref_const, deref_mut, into_raw,
+        ptr_ptr_to_vec_slice_const, write_to_2d_slice,
+    },
+    CResult, DASContext, DASScratch,
+};
+
+pub(crate) fn _das_scratch_new(
+    ctx: *const DASContext,
+    out: *mut *mut DASScratch,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+    let out = deref_mut(out)?;
+
+    // Computation
+    //
+    let inner = ctx.try_new_scratch().map_err(CResult::from)?;
+    let size = inner.size_in_bytes() as u64;
+    ctx.scratch_bytes.fetch_add(size, Ordering::Relaxed);
+
+    // Write output
+    //
+    *out = into_raw(DASScratch {
+        inner,
+        size,
+        scratch_bytes: ctx.scratch_bytes.clone(),
+    });
+
+    Ok(())
+}
+
+pub(crate) fn _compute_cells_and_kzg_proofs_with_scratch(
+    ctx: *const DASContext,
+    scratch: *mut DASScratch,
+    blob: *const u8,
+    out_cells: *mut *mut u8,
+    out_proofs: *mut *mut u8,
+) -> Result<(), CResult> {
+    // Pointer checks
+    //
+    let ctx = deref_const(ctx)?;
+    let scratch = deref_mut(scratch)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
+    check_scratch(ctx, scratch)?;
+
+    // Computation
+    //
+    let (cells, proofs) = ctx
+        .compute_cells_and_kzg_proofs_with_scratch(blob, &mut scratch.inner)
+        .map_err(CResult::from)?;
+
+    // Write to output
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells.each_ref().map(|cell| &cell[..]))?;
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_proofs, proofs.each_ref())?;
+
+    Ok(())
+}
+
+pub(crate) fn _compute_cells_with_scratch(
+    ctx: *const DASContext,
+    scratch: *mut DASScratch,
+    blob: *const u8,
+    out_cells: *mut *mut u8,
+) -> Result<(), CResult> {
+    // Pointer checks
+    //
+    let ctx = deref_const(ctx)?;
+    let scratch = deref_mut(scratch)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
+    check_scratch(ctx, scratch)?;
+
+    // Computation
+    //
+    let cells = ctx
+        .compute_cells_with_scratch(blob, &mut scratch.inner)
+        .map_err(CResult::from)?;
+
+    // Write to output
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells.each_ref().map(|cell| &cell[..]))?;
+
+    Ok(())
+}
+
+#[allow(clippy::too_many_arguments)]
+pub(crate) fn _recover_cells_and_kzg_proofs_with_scratch(
+    ctx: *const DASContext,
+    scratch: *mut DASScratch,
+    cells_length: u64,
+    cells: *const *const u8,
+    cell_indices_length: u64,
+    cell_indices: *const u64,
+    out_cells: *mut *mut u8,
+    out_proofs: *mut *mut u8,
+) -> Result<(), CResult> {
+    // Dereference the input pointers
+    //
+    let ctx = deref_const(ctx)?;
+    let scratch = deref_mut(scratch)?;
+    let cells = ptr_ptr_to_vec_slice_const::<BYTES_PER_CELL>(cells, cells_length)?;
+    let cell_indices = create_slice_view(cell_indices, cell_indices_length)?;
+    check_scratch(ctx, scratch)?;
+
+    // Computation
+    //
+    let (cells, proofs) = ctx
+        .recover_cells_and_kzg_proofs_with_scratch(cell_indices.to_vec(), cells, &mut scratch.inner)
+        .map_err(CResult::from)?;
+
+    // Write to output
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, cells.each_ref().map(|cell| &cell[..]))?;
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_proofs, proofs.each_ref())?;
+
+    Ok(())
+}
+
+/// Returns an error if `scratch` was not created from `ctx`.
+///
+/// The buffers are sized for the prover of the context that created them, so a scratch
+/// cannot be shared between contexts.
+fn check_scratch(ctx: &DASContext, scratch: &DASScratch) -> Result<(), CResult> {
+    if !Arc::ptr_eq(&scratch.scratch_bytes, &ctx.scratch_bytes) {
+        return Err(CResult::with_error(
+            ErrorCode::InvalidArgument,
+            "the scratch was created from a different context",
+        ));
+    }
+    Ok(())
+}
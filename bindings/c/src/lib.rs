@@ -1,3 +1,7 @@
+mod abi;
+use abi::_abi_negotiate;
+pub use abi::{ETH_KZG_ABI_VERSION_MAJOR, ETH_KZG_ABI_VERSION_MINOR};
+
 mod blob_to_kzg_commitment;
 use blob_to_kzg_commitment::_blob_to_kzg_commitment;
 
@@ -26,6 +30,46 @@ use verify_blob_kzg_proof::_verify_blob_kzg_proof;
 mod verify_blob_kzg_proof_batch;
 use verify_blob_kzg_proof_batch::_verify_blob_kzg_proof_batch;
 
+mod das_context_from_trusted_setup;
+use das_context_from_trusted_setup::{
+    _das_context_new, _das_context_new_from_env, _das_context_new_from_path,
+    _das_context_new_from_trusted_setup,
+};
+
+mod das_context_set_num_threads;
+use das_context_set_num_threads::_das_context_set_num_threads;
+
+mod das_context_set_metrics_callback;
+use das_context_set_metrics_callback::_das_context_set_metrics_callback;
+
+mod das_context_memory_usage;
+use das_context_memory_usage::_das_context_memory_usage;
+
+mod das_context_stats;
+use das_context_stats::{
+    CountingMetrics, OperationCounters, _das_context_operation_count, _das_context_stats,
+};
+
+mod das_context_set_admission_limits;
+use das_context_set_admission_limits::_das_context_set_admission_limits;
+
+mod das_context_verification_cache;
+use das_context_verification_cache::{
+    _das_context_set_verification_cache, _das_context_verification_cache_stats,
+};
+
+mod das_context_self_test;
+use das_context_self_test::_das_context_self_test;
+
+mod das_context_new_for_forks;
+use das_context_new_for_forks::_das_context_new_for_forks;
+
+mod das_scratch;
+use das_scratch::{
+    _compute_cells_and_kzg_proofs_with_scratch, _compute_cells_with_scratch, _das_scratch_new,
+    _recover_cells_and_kzg_proofs_with_scratch,
+};
+
 pub(crate) mod pointer_utils;
 
 use std::ops::Deref;
@@ -35,7 +79,7 @@ pub use rust_eth_kzg::{
         BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_COMMITMENT, BYTES_PER_FIELD_ELEMENT,
         CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_BLOB,
     },
-    Error,
+    Error, ErrorCode, ThreadPoolBuildError,
 };
 
 /*
@@ -50,15 +94,67 @@ pub use rust_eth_kzg::{
 // This is a wrapper around the DASContext from the eip7594 library.
 // We need to wrap it as some bindgen tools cannot pick up items
 // not defined in this file.
-#[derive(Default)]
 pub struct DASContext {
     inner: rust_eth_kzg::DASContext,
+    /// Counts the operations of `inner`, for `eth_kzg_das_context_stats`.
+    counters: std::sync::Arc<OperationCounters>,
+    /// The bytes of the scratch buffers created from this context that are still alive.
+    scratch_bytes: std::sync::Arc<std::sync::atomic::AtomicU64>,
+}
+
+#[cfg(not(feature = "no-embedded-setup"))]
+impl Default for DASContext {
+    fn default() -> Self {
+        Self::new(rust_eth_kzg::DASContext::default())
+    }
 }
 
 impl DASContext {
+    /// Wraps a context, counting its operations from now on.
+    pub fn new(inner: rust_eth_kzg::DASContext) -> Self {
+        let mut ctx = Self {
+            inner,
+            counters: std::sync::Arc::default(),
+            scratch_bytes: std::sync::Arc::default(),
+        };
+        ctx.set_metrics(None);
+        ctx
+    }
+
     pub fn inner(&self) -> &rust_eth_kzg::DASContext {
         &self.inner
     }
+
+    /// Runs the methods of this context on a dedicated thread pool with `num_threads` threads.
+    pub fn set_num_threads(
+        &mut self,
+        num_threads: usize,
+    ) -> Result<(), rust_eth_kzg::ThreadPoolBuildError> {
+        self.inner.set_num_threads(num_threads)
+    }
+
+    /// Limits the operations of this context that run at the same time, or removes the limits
+    /// if `limits` is `None`.
+    pub fn set_admission_limits(&mut self, limits: Option<rust_eth_kzg::AdmissionLimits>) {
+        self.inner.set_admission_limits(limits);
+    }
+
+    /// Remembers the last `capacity` cell openings that verified, or disables the cache if
+    /// `capacity` is 0.
+    pub fn set_verification_cache(&mut self, capacity: Option<usize>) {
+        self.inner.set_verification_cache(capacity);
+    }
+
+    /// Replaces the metrics hook of this context, or removes it if `metrics` is `None`.
+    ///
+    /// The operations are still counted for `eth_kzg_das_context_stats` either way.
+    pub fn set_metrics(&mut self, metrics: Option<std::sync::Arc<dyn rust_eth_kzg::Metrics>>) {
+        self.inner
+            .set_metrics(Some(std::sync::Arc::new(CountingMetrics {
+                counters: self.counters.clone(),
+                forward: metrics,
+            })));
+    }
 }
 
 impl Deref for DASContext {
@@ -69,26 +165,443 @@ impl Deref for DASContext {
     }
 }
 
+/// Combined with a precomputation width, ie `8 | ETH_KZG_PRECOMP_COMPRESSED`, to store only the
+/// odd multiples of each point in the precomputed tables.
+///
+/// The tables are half the size of those of the same width without the flag, and computing cell
+/// proofs is a bit slower. Every function that takes a `precomp_width` accepts the flag.
+pub const ETH_KZG_PRECOMP_COMPRESSED: usize = 1 << 8;
+
 /// Create a new DASContext and return a pointer to it.
 ///
+/// If `use_precomp` is true, the recommended precomputation width is used.
+/// See `eth_kzg_das_context_new_with_precomp_width` to choose the width explicitly.
+///
+/// This uses the embedded mainnet trusted setup. If the library was built with the
+/// `no-embedded-setup` feature, this returns a null pointer and
+/// `eth_kzg_das_context_new_from_trusted_setup` must be used instead. The environment is
+/// never read; see `eth_kzg_das_context_new_from_env` to load the trusted setup from it.
+///
 /// # Memory faults
 ///
 /// To avoid memory leaks, one should ensure that the pointer is freed after use
 /// by calling `eth_kzg_das_context_free`.
 #[no_mangle]
 pub extern "C" fn eth_kzg_das_context_new(use_precomp: bool) -> *mut DASContext {
-    let use_precomp = if use_precomp {
-        rust_eth_kzg::UsePrecomp::Yes {
-            width: RECOMMENDED_PRECOMP_WIDTH,
-        }
+    let precomp_width = if use_precomp {
+        RECOMMENDED_PRECOMP_WIDTH
     } else {
-        rust_eth_kzg::UsePrecomp::No
+        0
     };
+    eth_kzg_das_context_new_with_precomp_width(precomp_width)
+}
+
+/// Create a new DASContext with the given precomputation width and return a pointer to it.
+///
+/// The width controls the size of the tables precomputed for computing cell proofs.
+/// A width of zero disables precomputation, which uses the least memory but is the slowest.
+/// Larger widths are faster, but each increment roughly doubles the memory used by the tables.
+/// Widths larger than 15 are rejected.
+/// A width may be combined with `ETH_KZG_PRECOMP_COMPRESSED` to halve the size of the tables.
+///
+/// This uses the embedded mainnet trusted setup. If the library was built with the
+/// `no-embedded-setup` feature, this returns a null pointer and
+/// `eth_kzg_das_context_new_from_trusted_setup` must be used instead. The environment is
+/// never read; see `eth_kzg_das_context_new_from_env` to load the trusted setup from it.
+///
+/// # Memory faults
+///
+/// To avoid memory leaks, one should ensure that the pointer is freed after use
+/// by calling `eth_kzg_das_context_free`.
+#[no_mangle]
+pub extern "C" fn eth_kzg_das_context_new_with_precomp_width(
+    precomp_width: usize,
+) -> *mut DASContext {
+    let mut ctx = std::ptr::null_mut();
+    match _das_context_new(precomp_width, &raw mut ctx) {
+        Ok(()) => ctx,
+        Err(err) => {
+            // The error message was allocated by `CResult::with_error`
+            pointer_utils::free_c_string(err.error_msg);
+            std::ptr::null_mut()
+        }
+    }
+}
+
+/// Create a new DASContext from a trusted setup supplied by the caller.
+///
+/// `trusted_setup` holds the contents of a trusted setup file, either in the JSON format
+/// used by the consensus specs or in the `trusted_setup.txt` format used by c-kzg-4844.
+/// On success, a pointer to the new context is written to `out`.
+///
+/// An error is returned if no trusted setup was provided, ie `trusted_setup` is null or
+/// `trusted_setup_len` is zero, or if the trusted setup could not be parsed.
+///
+/// # Safety
+///
+/// - The caller must ensure that `trusted_setup` points to a region of memory that is at least `trusted_setup_len` bytes.
+/// - The caller must ensure that `out` is a valid pointer.
+///
+/// # Memory faults
+///
+/// To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+/// by calling `eth_kzg_das_context_free`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_new_from_trusted_setup(
+    trusted_setup: *const u8,
+    trusted_setup_len: usize,
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> CResult {
+    match _das_context_new_from_trusted_setup(trusted_setup, trusted_setup_len, precomp_width, out)
+    {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Create a new DASContext from the trusted setup file at `path`.
+///
+/// The file may be in the JSON format used by the consensus specs, the `trusted_setup.txt`
+/// format used by c-kzg-4844 or the binary cache format. On success, a pointer to the new
+/// context is written to `out`.
+///
+/// # Safety
+///
+/// - The caller must ensure that `path` is a valid null-terminated string.
+/// - The caller must ensure that `out` is a valid pointer.
+///
+/// # Memory faults
+///
+/// To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+/// by calling `eth_kzg_das_context_free`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_new_from_path(
+    path: *const std::os::raw::c_char,
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> CResult {
+    match _das_context_new_from_path(path, precomp_width, out) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Create a new DASContext, loading the trusted setup from the environment.
+///
+/// The trusted setup is loaded from the file named by the `ETH_KZG_TRUSTED_SETUP`
+/// environment variable if it is set, then from the default locations, and otherwise
+/// the embedded mainnet trusted setup is used. On success, a pointer to the new context
+/// is written to `out`.
+///
+/// # Safety
+///
+/// - The caller must ensure that `out` is a valid pointer.
+///
+/// # Memory faults
+///
+/// To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+/// by calling `eth_kzg_das_context_free`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_new_from_env(
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> CResult {
+    match _das_context_new_from_env(precomp_width, out) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Run the methods of the DASContext on a dedicated thread pool with `num_threads` threads.
+///
+/// By default, a DASContext uses a global thread pool with one thread per CPU. Setting
+/// `num_threads` to 1 makes the context fully single-threaded, and setting it to 0 creates a
+/// dedicated pool with one thread per CPU; the context does not go back to the global pool
+/// once this was called. This should be called before the context is shared between threads.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_set_num_threads(
+    ctx: *mut DASContext,
+    num_threads: usize,
+) -> CResult {
+    match _das_context_set_num_threads(ctx, num_threads) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// A callback that is called once for every operation a DASContext completes.
+///
+/// - `user_data` is the pointer that was passed to `eth_kzg_das_context_set_metrics_callback`.
+/// - `operation` identifies the method that was called, ie 1 for `blob_to_kzg_commitment` and
+///   8 for `verify_cell_kzg_proof_batch`. The values are stable and match `rust_eth_kzg::Operation`.
+/// - `batch_size` is the number of blobs or cells in the input, or 1 for the methods on a single blob.
+/// - `duration_nanos` is the time the operation took, in nanoseconds.
+/// - `error_code` is 0 if the operation succeeded, and the code of its error otherwise.
+pub type MetricsCallback = extern "C" fn(
+    user_data: *mut std::ffi::c_void,
+    operation: u32,
+    batch_size: u64,
+    duration_nanos: u64,
+    error_code: u32,
+);
+
+/// Report every operation of the DASContext to `callback`, so that they can be exported to
+/// a metrics system such as Prometheus. Passing a null `callback` removes the current one.
+///
+/// The callback is called on the thread that called the operation, once it has finished, so it
+/// should be cheap and must not block. This should be called before the context is shared
+/// between threads.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+/// - The caller must ensure that `callback` can be called from any thread with `user_data`, until
+///   the callback is removed or the context is freed.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_set_metrics_callback(
+    ctx: *mut DASContext,
+    callback: Option<MetricsCallback>,
+    user_data: *mut std::ffi::c_void,
+) -> CResult {
+    match _das_context_set_metrics_callback(ctx, callback, user_data) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// The number of bytes held by a DASContext, split by what they are used for.
+///
+/// The tables are shared between a context and its clones, and buffers owned by
+/// the caller are not included.
+#[repr(C)]
+pub struct MemoryUsage {
+    /// Points of the trusted setup.
+    pub srs: u64,
+    /// Tables precomputed by the FK20 prover, which grow with the precomputation width.
+    pub fk20_precomputations: u64,
+    /// Roots of unity and FFT tables of the evaluation domains.
+    pub domains: u64,
+    /// The sum of the other fields.
+    pub total: u64,
+}
+
+/// Write the number of bytes held by the SRS tables, FK20 precomputations and domains of the DASContext to `out`.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+/// - The caller must ensure that `out` points to a writable `MemoryUsage`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_memory_usage(
+    ctx: *const DASContext,
+    out: *mut MemoryUsage,
+) -> CResult {
+    match _das_context_memory_usage(ctx, out) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Counters of a DASContext, for exporting to a metrics system such as Prometheus.
+///
+/// The counters start at zero when the context is created, and include every operation,
+/// whether or not a metrics callback is set.
+#[repr(C)]
+pub struct Stats {
+    /// The number of operations that the context has performed.
+    pub operations: u64,
+    /// The number of those operations that returned an error, including proofs that did not verify.
+    pub failed_operations: u64,
+    /// The bytes of the tables precomputed by the FK20 prover.
+    pub precomputation_bytes: u64,
+    /// The bytes of every table held by the context, as `total` in `MemoryUsage`.
+    pub memory_bytes: u64,
+    /// The bytes of the scratch buffers created from the context with `eth_kzg_das_scratch_new`
+    /// that have not been freed yet.
+    pub scratch_bytes: u64,
+}
+
+/// Write the counters of the DASContext to `out`.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+/// - The caller must ensure that `out` points to a writable `Stats`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_stats(ctx: *const DASContext, out: *mut Stats) -> CResult {
+    match _das_context_stats(ctx, out) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Write the number of times the DASContext performed `operation`, and the number of those
+/// that returned an error, to `out_calls` and `out_failures`.
+///
+/// `operation` uses the numbering of `MetricsCallback`. An error with the `InvalidArgument`
+/// code is returned for an unknown operation.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+/// - The caller must ensure that `out_calls` and `out_failures` are valid pointers.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_operation_count(
+    ctx: *const DASContext,
+    operation: u32,
+    out_calls: *mut u64,
+    out_failures: *mut u64,
+) -> CResult {
+    match _das_context_operation_count(ctx, operation, out_calls, out_failures) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
 
-    let ctx = Box::new(DASContext {
-        inner: rust_eth_kzg::DASContext::new(&rust_eth_kzg::TrustedSetup::default(), use_precomp),
-    });
-    Box::into_raw(ctx)
+/// Run at most `max_in_flight` operations of the DASContext at the same time, across all the
+/// threads that call it. Setting `max_in_flight` to 0 removes the limits, which is the default.
+///
+/// When `max_in_flight` operations are running, up to `max_queued` others wait for one of them
+/// to finish, for at most `queue_timeout_millis` milliseconds, or without a time limit if
+/// `queue_timeout_millis` is 0. An operation that arrives when the queue is full returns an
+/// error with the `Overloaded` code, and one that waits for too long returns an error with
+/// the `AdmissionTimedOut` code, so that a burst of requests is turned away instead of
+/// slowing down every operation. An error with the `InvalidArgument` code is returned if
+/// `max_in_flight` or `max_queued` does not fit in a `size_t`. This should be called before the
+/// context is shared between threads.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_set_admission_limits(
+    ctx: *mut DASContext,
+    max_in_flight: u64,
+    max_queued: u64,
+    queue_timeout_millis: u64,
+) -> CResult {
+    match _das_context_set_admission_limits(ctx, max_in_flight, max_queued, queue_timeout_millis) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Remember the last `capacity` cell openings that `eth_kzg_verify_cell_kzg_proof_batch`
+/// verified, so that verifying them again returns without any pairing. Setting `capacity`
+/// to 0 disables the cache, which is the default.
+///
+/// Consensus clients receive the same data column sidecars from several peers and on several
+/// topics. Only openings that verified are remembered, so a batch with an opening that did
+/// not verify is still rejected. Setting the cache empties it and resets its counters.
+/// An error with the `InvalidArgument` code is returned if `capacity` does not fit in a
+/// `size_t`. This should be called before the context is shared between threads.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_set_verification_cache(
+    ctx: *mut DASContext,
+    capacity: u64,
+) -> CResult {
+    match _das_context_set_verification_cache(ctx, capacity) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Write the number of cell openings that were found in the verification cache of the
+/// DASContext, and the number that were not, to `out_hits` and `out_misses`.
+///
+/// Both are 0 if the cache is disabled. See `eth_kzg_das_context_set_verification_cache`.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+/// - The caller must ensure that `out_hits` and `out_misses` are valid pointers.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_verification_cache_stats(
+    ctx: *const DASContext,
+    out_hits: *mut u64,
+    out_misses: *mut u64,
+) -> CResult {
+    match _das_context_verification_cache_stats(ctx, out_hits, out_misses) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Selects the blob proof methods of Deneb (EIP-4844) in `eth_kzg_das_context_new_for_forks`.
+pub const ETH_KZG_FORK_DENEB: u32 = 1;
+
+/// Selects the cell proof methods of Fulu (EIP-7594) in `eth_kzg_das_context_new_for_forks`.
+pub const ETH_KZG_FORK_FULU: u32 = 2;
+
+/// Create a new DASContext that serves the methods of every fork in `forks`, loading the
+/// trusted setup from the environment like `eth_kzg_das_context_new_from_env`.
+///
+/// `forks` is a combination of `ETH_KZG_FORK_DENEB` and `ETH_KZG_FORK_FULU`. A single context
+/// serves both forks from one copy of the trusted setup, so clients that straddle the fork
+/// should create one context with both flags instead of one context per fork. Without
+/// `ETH_KZG_FORK_FULU`, the tables that compute cells are skipped and the cell methods that
+/// prove return an error with the `VerifierOnlyContext` code, while the cell methods that
+/// verify keep working. The blob methods are served by every fork since Deneb, so an error
+/// with the `InvalidArgument` code is returned if `forks` does not include `ETH_KZG_FORK_DENEB`
+/// or has unknown flags. On success, a pointer to the new context is written to `out`.
+///
+/// # Safety
+///
+/// - The caller must ensure that `out` is a valid pointer.
+///
+/// # Memory faults
+///
+/// To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+/// by calling `eth_kzg_das_context_free`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_new_for_forks(
+    forks: u32,
+    precomp_width: usize,
+    out: *mut *mut DASContext,
+) -> CResult {
+    match _das_context_new_for_forks(forks, precomp_width, out) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Run known-answer tests against the trusted setup and precomputed tables of the DASContext.
+///
+/// This detects a corrupted setup or precomputation file, or a bad build, and is meant to be
+/// called once at startup. It takes about as long as computing the cells and proofs of a few blobs.
+/// The known answers are for the mainnet trusted setup.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_context_self_test(ctx: *const DASContext) -> CResult {
+    match _das_context_self_test(ctx) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
 }
 
 /// # Safety
@@ -104,17 +617,81 @@ pub extern "C" fn eth_kzg_das_context_new(use_precomp: bool) -> *mut DASContext
 ///
 /// - Since the `ctx` is created in Rust, we can only get undefined behavior, if the caller passes in
 ///   a pointer that was not created by `eth_kzg_das_context_new`.
-#[allow(clippy::not_unsafe_ptr_arg_deref)]
 #[no_mangle]
 pub extern "C" fn eth_kzg_das_context_free(ctx: *mut DASContext) {
-    if ctx.is_null() {
-        return;
+    pointer_utils::free_boxed(ctx);
+}
+
+/// The buffers that `eth_kzg_compute_cells_and_kzg_proofs_with_scratch`,
+/// `eth_kzg_compute_cells_with_scratch` and `eth_kzg_recover_cells_and_kzg_proofs_with_scratch`
+/// compute the cells and proofs of a blob in, so that they do not allocate them on every call.
+///
+/// A scratch can only be used by one thread at a time, while a DASContext can be shared by
+/// any number of threads. Embedders that prove on several threads should create one scratch
+/// per thread from the same context.
+pub struct DASScratch {
+    inner: rust_eth_kzg::Scratch,
+    /// The bytes of `inner`, counted in `scratch_bytes` while it is alive.
+    size: u64,
+    /// The `scratch_bytes` counter of the context that this scratch was created from.
+    scratch_bytes: std::sync::Arc<std::sync::atomic::AtomicU64>,
+}
+
+impl Drop for DASScratch {
+    fn drop(&mut self) {
+        self.scratch_bytes
+            .fetch_sub(self.size, std::sync::atomic::Ordering::Relaxed);
     }
-    unsafe {
-        let _ = Box::from_raw(ctx);
+}
+
+/// Create the scratch buffers for computing the cells and proofs of a blob with the DASContext.
+///
+/// On success, a pointer to the new scratch is written to `out`. An error with the
+/// `VerifierOnlyContext` code is returned if the context cannot compute cells, for example
+/// because it was created by `eth_kzg_das_context_new_for_forks` without `ETH_KZG_FORK_FULU`.
+///
+/// The scratch can outlive the context, but it can only be used with the context that it was
+/// created from.
+///
+/// # Safety
+///
+/// - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+/// - The caller must ensure that `out` is a valid pointer.
+///
+/// # Memory faults
+///
+/// To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+/// by calling `eth_kzg_das_scratch_free`.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_das_scratch_new(
+    ctx: *const DASContext,
+    out: *mut *mut DASScratch,
+) -> CResult {
+    match _das_scratch_new(ctx, out) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
     }
 }
 
+/// # Safety
+///
+/// - The caller must ensure that the pointer is valid. If the pointer is null, this method will return early.
+/// - The caller should also avoid a double-free by setting the pointer to null after calling this method.
+///
+/// # Memory faults
+///
+/// - If this method is called twice on the same pointer, it will result in a double-free.
+///
+/// # Undefined behavior
+///
+/// - Since the `scratch` is created in Rust, we can only get undefined behavior, if the caller passes in
+///   a pointer that was not created by `eth_kzg_das_scratch_new`.
+#[no_mangle]
+pub extern "C" fn eth_kzg_das_scratch_free(scratch: *mut DASScratch) {
+    pointer_utils::free_boxed(scratch);
+}
+
 /// A C-style enum to indicate whether a function call was a success or not.
 #[repr(C)]
 pub enum CResultStatus {
@@ -124,15 +701,18 @@ pub enum CResultStatus {
 
 /// A C-style struct to represent the success result of a function call.
 ///
-/// This includes the status of the call and an error message, if the status was an error.
+/// This includes the status of the call and, if the status was an error, an error message
+/// and the stable code of the error. The codes are listed in `rust_eth_kzg::ErrorCode`;
+/// the code is zero when the status is `Ok`.
 #[repr(C)]
 pub struct CResult {
     pub status: CResultStatus,
     pub error_msg: *mut std::os::raw::c_char,
+    pub error_code: u32,
 }
 
 impl CResult {
-    /// Create a new CResult with an error message.
+    /// Create a new CResult with an error code and message.
     ///
     /// # Memory leaks
     ///
@@ -143,12 +723,13 @@ impl CResult {
     /// # Memory faults
     ///
     /// - If this method is called twice on the same pointer, it will result in a double-free.
-    pub fn with_error(error_msg: &str) -> Self {
-        let error_msg =
-            std::ffi::CString::new(error_msg).expect("Unable to convert error to CString");
+    pub fn with_error(error_code: ErrorCode, error_msg: &str) -> Self {
+        let error_msg = std::ffi::CString::new(format!("{error_code}: {error_msg}"))
+            .expect("Unable to convert error to CString");
         CResult {
             status: CResultStatus::Err,
             error_msg: error_msg.into_raw(),
+            error_code: error_code.as_u32(),
         }
     }
 
@@ -157,10 +738,17 @@ impl CResult {
         CResult {
             status: CResultStatus::Ok,
             error_msg: std::ptr::null_mut(),
+            error_code: 0,
         }
     }
 }
 
+impl From<Error> for CResult {
+    fn from(err: Error) -> Self {
+        CResult::with_error(err.code(), &format!("{err:?}"))
+    }
+}
+
 /// Free the memory allocated for the error message.
 ///
 /// # Safety
@@ -169,14 +757,7 @@ impl CResult {
 /// - The caller should also avoid a double-free by setting the pointer to null after calling this method.
 #[no_mangle]
 pub unsafe extern "C" fn eth_kzg_free_error_message(c_message: *mut std::os::raw::c_char) {
-    // check if the pointer is null
-    if c_message.is_null() {
-        return;
-    }
-    // Safety: Deallocate the memory allocated for the C-style string
-    unsafe {
-        let _ = std::ffi::CString::from_raw(c_message);
-    };
+    pointer_utils::free_c_string(c_message);
 }
 
 /// Compute a commitment from a Blob
@@ -189,8 +770,8 @@ pub unsafe extern "C" fn eth_kzg_free_error_message(c_message: *mut std::os::raw
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_blob_to_kzg_commitment(
@@ -210,7 +791,7 @@ pub extern "C" fn eth_kzg_blob_to_kzg_commitment(
 ///
 /// # Safety
 ///
-/// - The caller must ensure that the pointers are valid. If pointers are null.
+/// - The caller must ensure that the pointers are valid.
 /// - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
 /// - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
 ///   and that each element is at least `BYTES_PER_CELL` bytes.
@@ -219,8 +800,8 @@ pub extern "C" fn eth_kzg_blob_to_kzg_commitment(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_compute_cells_and_kzg_proofs(
@@ -241,15 +822,15 @@ pub extern "C" fn eth_kzg_compute_cells_and_kzg_proofs(
 ///
 /// # Safety
 ///
-/// - The caller must ensure that the pointers are valid. If pointers are null.
+/// - The caller must ensure that the pointers are valid.
 /// - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
 /// - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
 ///   and that each element is at least `BYTES_PER_CELL` bytes.
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_compute_cells(
@@ -265,6 +846,83 @@ pub extern "C" fn eth_kzg_compute_cells(
     }
 }
 
+/// Computes the cells and KZG proofs for a given blob, like `eth_kzg_compute_cells_and_kzg_proofs`,
+/// using the buffers of `scratch` for every intermediate value.
+///
+/// No large allocations are made, and the context is only read, so several threads can call
+/// this with the same context at the same time, as long as each has its own scratch. An error
+/// with the `InvalidArgument` code is returned if `scratch` was created from another context.
+///
+/// # Safety
+///
+/// - The caller must ensure that the pointers are valid.
+/// - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+///   used by another thread during the call.
+/// - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
+/// - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+///   and that each element is at least `BYTES_PER_CELL` bytes.
+/// - The caller must ensure that `out_proofs` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+///   and that each element is at least `BYTES_PER_COMMITMENT` bytes.
+///
+/// # Undefined behavior
+///
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_compute_cells_and_kzg_proofs_with_scratch(
+    ctx: *const DASContext,
+
+    scratch: *mut DASScratch,
+
+    blob: *const u8,
+
+    out_cells: *mut *mut u8,
+    out_proofs: *mut *mut u8,
+) -> CResult {
+    match _compute_cells_and_kzg_proofs_with_scratch(ctx, scratch, blob, out_cells, out_proofs) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Computes the cells for a given blob, like `eth_kzg_compute_cells`, using the buffers of
+/// `scratch` for every intermediate value.
+///
+/// No large allocations are made, and the context is only read, so several threads can call
+/// this with the same context at the same time, as long as each has its own scratch. An error
+/// with the `InvalidArgument` code is returned if `scratch` was created from another context.
+///
+/// # Safety
+///
+/// - The caller must ensure that the pointers are valid.
+/// - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+///   used by another thread during the call.
+/// - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
+/// - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+///   and that each element is at least `BYTES_PER_CELL` bytes.
+///
+/// # Undefined behavior
+///
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_compute_cells_with_scratch(
+    ctx: *const DASContext,
+
+    scratch: *mut DASScratch,
+
+    blob: *const u8,
+
+    out_cells: *mut *mut u8,
+) -> CResult {
+    match _compute_cells_with_scratch(ctx, scratch, blob, out_cells) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
 // The underlying cryptography library, uses a Result enum to indicate a proof failed verification.
 //
 // From the callers perspective, as long as the verification procedure is invalid, it doesn't matter why it is invalid.
@@ -275,7 +933,7 @@ fn verification_result_to_bool_cresult(
     match verification_result {
         Ok(_) => Ok(true),
         Err(x) if x.is_proof_invalid() => Ok(false),
-        Err(err) => Err(CResult::with_error(&format!("{err:?}"))),
+        Err(err) => Err(CResult::from(err)),
     }
 }
 
@@ -302,8 +960,8 @@ fn verification_result_to_bool_cresult(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_verify_cell_kzg_proof_batch(
@@ -359,11 +1017,11 @@ pub extern "C" fn eth_kzg_verify_cell_kzg_proof_batch(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
-pub extern "C" fn eth_kzg_recover_cells_and_proofs(
+pub extern "C" fn eth_kzg_recover_cells_and_kzg_proofs(
     ctx: *const DASContext,
 
     cells_length: u64,
@@ -389,6 +1047,115 @@ pub extern "C" fn eth_kzg_recover_cells_and_proofs(
     }
 }
 
+/// Recovers all cells and their KZG proofs from the given cell indices and cells, like
+/// `eth_kzg_recover_cells_and_kzg_proofs`, using the buffers of `scratch` for the proofs.
+///
+/// The context is only read, so several threads can call this with the same context at the
+/// same time, as long as each has its own scratch. The erasure decoding still allocates its
+/// own buffers, which are about as large as an extended blob. An error with the
+/// `InvalidArgument` code is returned if `scratch` was created from another context.
+///
+/// # Safety
+///
+///  - If the length parameter for a pointer is set to zero, then this implementation will not check if its pointer is
+///    null. This is because the caller might have passed in a null pointer, if the length is zero. Instead an empty slice will be created.
+///
+/// - The caller must ensure that the pointers are valid.
+/// - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+///   used by another thread during the call.
+/// - The caller must ensure that `cells` points to a region of memory that is at least `cells_length` cells
+///   and that each cell is at least `BYTES_PER_CELL` bytes.
+/// - The caller must ensure that `cell_indices` points to a region of memory that is at least `cell_indices_length` cell indices
+///   and that each cell id is 8 bytes.
+/// - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` cells
+///   and that each cell is at least `BYTES_PER_CELL` bytes.
+/// - The caller must ensure that `out_proofs` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` proofs
+///   and that each proof is at least `BYTES_PER_COMMITMENT` bytes.
+///
+/// # Undefined behavior
+///
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_recover_cells_and_kzg_proofs_with_scratch(
+    ctx: *const DASContext,
+
+    scratch: *mut DASScratch,
+
+    cells_length: u64,
+    cells: *const *const u8,
+
+    cell_indices_length: u64,
+    cell_indices: *const u64,
+
+    out_cells: *mut *mut u8,
+    out_proofs: *mut *mut u8,
+) -> CResult {
+    match _recover_cells_and_kzg_proofs_with_scratch(
+        ctx,
+        scratch,
+        cells_length,
+        cells,
+        cell_indices_length,
+        cell_indices,
+        out_cells,
+        out_proofs,
+    ) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
+/// Recovers all cells and their KZG proofs from the given cell indices and cells
+///
+/// Deprecated: this is the same as `eth_kzg_recover_cells_and_kzg_proofs`, which matches
+/// the name used by the consensus specs.
+///
+/// # Safety
+///
+/// - See `eth_kzg_recover_cells_and_kzg_proofs`.
+#[deprecated(note = "use `eth_kzg_recover_cells_and_kzg_proofs`")]
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_recover_cells_and_proofs(
+    ctx: *const DASContext,
+
+    cells_length: u64,
+    cells: *const *const u8,
+
+    cell_indices_length: u64,
+    cell_indices: *const u64,
+
+    out_cells: *mut *mut u8,
+    out_proofs: *mut *mut u8,
+) -> CResult {
+    eth_kzg_recover_cells_and_kzg_proofs(
+        ctx,
+        cells_length,
+        cells,
+        cell_indices_length,
+        cell_indices,
+        out_cells,
+        out_proofs,
+    )
+}
+
+/// Check that this library implements the version of the C ABI that the caller was built against.
+///
+/// Bindings should call this once when they are loaded, with the `ETH_KZG_ABI_VERSION_MAJOR`
+/// and `ETH_KZG_ABI_VERSION_MINOR` of the header they were generated from. An error with the
+/// `AbiVersionMismatch` code is returned if the major versions differ, or if the library is
+/// older than the caller, ie it may be missing some of the symbols the caller uses.
+#[no_mangle]
+#[must_use]
+pub extern "C" fn eth_kzg_abi_negotiate(major: u32, minor: u32) -> CResult {
+    match _abi_negotiate(major, minor) {
+        Ok(_) => CResult::with_ok(),
+        Err(err) => err,
+    }
+}
+
 // Expose the constants to the C API so that languages that have to define them
 // manually can use them in tests.
 #[no_mangle]
@@ -416,8 +1183,8 @@ pub extern "C" fn eth_kzg_constant_cells_per_ext_blob() -> u64 {
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_compute_kzg_proof(
@@ -444,8 +1211,8 @@ pub extern "C" fn eth_kzg_compute_kzg_proof(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_compute_blob_kzg_proof(
@@ -473,8 +1240,8 @@ pub extern "C" fn eth_kzg_compute_blob_kzg_proof(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_verify_kzg_proof(
@@ -503,8 +1270,8 @@ pub extern "C" fn eth_kzg_verify_kzg_proof(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_verify_blob_kzg_proof(
@@ -539,8 +1306,8 @@ pub extern "C" fn eth_kzg_verify_blob_kzg_proof(
 ///
 /// # Undefined behavior
 ///
-/// - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-///   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+/// - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+///   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 #[no_mangle]
 #[must_use]
 pub extern "C" fn eth_kzg_verify_blob_kzg_proof_batch(
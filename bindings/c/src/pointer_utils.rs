@@ -1,77 +1,374 @@
-// Helper methods for dereferencing raw pointers and writing to slices
+//! Helper methods for dereferencing the raw pointers passed over the C ABI, and writing to them.
+//!
+//! This is the only module of the crate that contains `unsafe` blocks, which a test below checks,
+//! so that it is the only module to audit for memory safety. The other modules only see
+//! references and slices of the right size, and never a raw pointer that they dereference.
+//!
+//! The functions of this module are safe to call with the pointers that a C caller passes to
+//! the functions of `lib.rs`, as long as the caller follows the `# Safety` section of those
+//! functions. They must not be called with any other pointer. They check what can be checked:
+//!
+//! - A null pointer is an `InvalidArgument` error, unless it is the pointer of an empty slice.
+//! - A length that does not fit in the address space is an `InvalidArgument` error, so that
+//!   the slices built from it are valid.
+//!
+//! The rest of the contract is documented by debug assertions, which Miri and the sanitizer
+//! test jobs run with:
+//!
+//! - Pointers are aligned for their type.
+//! - The buffers of an output do not overlap each other. Outputs are written with
+//!   `copy_nonoverlapping` once the computation is done, without creating a mutable
+//!   reference to the caller's memory, so an output that overlaps an input is not undefined
+//!   behavior, although the input is overwritten.
+//!
+//! The references returned have an unbounded lifetime, and must not outlive the call that
+//! received the pointer.
 
-/// Dereference a raw pointer to a mutable reference
-pub(crate) fn deref_mut<'a, T>(ptr: *mut T) -> &'a mut T {
-    unsafe { &mut *ptr }
+use std::{ffi::CStr, os::raw::c_char};
+
+use rust_eth_kzg::ErrorCode;
+
+use crate::CResult;
+
+fn null_pointer_error() -> CResult {
+    CResult::with_error(
+        ErrorCode::InvalidArgument,
+        "a null pointer was passed for an argument that is not empty",
+    )
+}
+
+/// Returns `len` as a `usize`, if a slice of `len` elements of `T` fits in the address space.
+fn checked_len<T>(len: u64) -> Result<usize, CResult> {
+    usize::try_from(len)
+        .ok()
+        .filter(|&len| {
+            len.checked_mul(size_of::<T>().max(1))
+                .is_some_and(|bytes| bytes <= isize::MAX as usize)
+        })
+        .ok_or_else(|| {
+            CResult::with_error(
+                ErrorCode::InvalidArgument,
+                &format!("length {len} is too large for the address space"),
+            )
+        })
+}
+
+/// Returns the argument `name` as a `usize`, instead of truncating it on 32-bit targets.
+pub(crate) fn checked_usize(value: u64, name: &str) -> Result<usize, CResult> {
+    usize::try_from(value).map_err(|_| {
+        CResult::with_error(
+            ErrorCode::InvalidArgument,
+            &format!("{name} {value} does not fit in a size_t"),
+        )
+    })
 }
 
 /// Dereference a raw pointer to an immutable reference
-pub(crate) fn deref_const<'a, T>(ptr: *const T) -> &'a T {
-    unsafe { &*ptr }
+pub(crate) fn deref_const<'a, T>(ptr: *const T) -> Result<&'a T, CResult> {
+    if ptr.is_null() {
+        return Err(null_pointer_error());
+    }
+    debug_assert!(ptr.is_aligned(), "pointer is not aligned");
+    // Safety: the pointer is not null, and the caller guarantees that it points to a `T`
+    Ok(unsafe { &*ptr })
 }
 
-/// Dereference a raw pointer to a pointer to a mutable slice of mutable slices
-pub(crate) fn ptr_ptr_to_slice_slice_mut<'a, T>(
-    ptr_ptr: *mut *mut T,
-    outer_len: usize,
-) -> &'a mut [&'a mut T] {
-    if outer_len == 0 {
-        return &mut [];
+/// Dereference a raw pointer to a mutable reference
+///
+/// This is only used for the objects that this library allocated, such as a `DASContext`,
+/// and for outputs that are plain structs.
+pub(crate) fn deref_mut<'a, T>(ptr: *mut T) -> Result<&'a mut T, CResult> {
+    if ptr.is_null() {
+        return Err(null_pointer_error());
     }
-    unsafe { std::slice::from_raw_parts_mut(ptr_ptr as *mut &mut T, outer_len) }
+    debug_assert!(ptr.is_aligned(), "pointer is not aligned");
+    // Safety: the pointer is not null, and the caller guarantees that it points to a `T` that
+    // is not used by anything else during the call
+    Ok(unsafe { &mut *ptr })
 }
 
-/// Dereference a raw pointer to a pointer to a vector of slices
-pub(crate) fn ptr_ptr_to_vec_slice_const<'a, const INNER_LEN: usize>(
-    ptr_ptr: *const *const u8,
-    outer_len: usize,
-) -> Vec<&'a [u8; INNER_LEN]> {
-    if outer_len == 0 {
-        return Vec::new();
+/// Constructs a slice from a pointer and a length.
+///
+/// If the length is 0, an empty slice is returned regardless of the pointer.
+pub(crate) fn create_slice_view<'a, T>(ptr: *const T, len: u64) -> Result<&'a [T], CResult> {
+    let len = checked_len::<T>(len)?;
+    if len == 0 {
+        return Ok(&[]);
     }
+    if ptr.is_null() {
+        return Err(null_pointer_error());
+    }
+    debug_assert!(ptr.is_aligned(), "pointer is not aligned");
+    // Safety: the pointer is not null, the size of the slice fits in an `isize`, and the
+    // caller guarantees that it points to `len` elements
+    Ok(unsafe { std::slice::from_raw_parts(ptr, len) })
+}
 
-    let vec_slice: &[*const u8] = unsafe { std::slice::from_raw_parts(ptr_ptr, outer_len) };
+/// Constructs an array reference from a pointer to `LEN` elements.
+pub(crate) fn create_array_ref<'a, const LEN: usize, T>(
+    ptr: *const T,
+) -> Result<&'a [T; LEN], CResult> {
+    deref_const(ptr.cast::<[T; LEN]>())
+}
 
-    let mut result: Vec<&[u8; INNER_LEN]> = Vec::with_capacity(outer_len);
+/// Constructs a vector of array references from a pointer to `outer_len` pointers, each to
+/// `INNER_LEN` bytes.
+pub(crate) fn ptr_ptr_to_vec_slice_const<'a, const INNER_LEN: usize>(
+    ptr_ptr: *const *const u8,
+    outer_len: u64,
+) -> Result<Vec<&'a [u8; INNER_LEN]>, CResult> {
+    create_slice_view(ptr_ptr, outer_len)?
+        .iter()
+        .map(|&ptr| create_array_ref::<INNER_LEN, _>(ptr))
+        .collect()
+}
 
-    // Convert each inner pointer to a slice of u8
-    for ptr in vec_slice.iter() {
-        let slice = create_array_ref::<INNER_LEN, _>(*ptr);
-        result.push(slice);
+/// Write `value` to `ptr`, without reading or dropping its previous contents.
+pub(crate) fn write_value<T>(ptr: *mut T, value: T) -> Result<(), CResult> {
+    if ptr.is_null() {
+        return Err(null_pointer_error());
     }
-
-    result
+    debug_assert!(ptr.is_aligned(), "pointer is not aligned");
+    // Safety: the pointer is not null, and the caller guarantees that it points to memory
+    // for a `T`
+    unsafe { ptr.write(value) };
+    Ok(())
 }
 
 /// Write `data` to a slice starting at `ptr`
-pub(crate) fn write_to_slice<T: Copy>(ptr: *mut T, data: &[T]) {
-    let slice = unsafe { std::slice::from_raw_parts_mut(ptr, data.len()) };
-    slice.copy_from_slice(data);
+pub(crate) fn write_to_slice<T: Copy>(ptr: *mut T, data: &[T]) -> Result<(), CResult> {
+    if data.is_empty() {
+        return Ok(());
+    }
+    if ptr.is_null() {
+        return Err(null_pointer_error());
+    }
+    debug_assert!(ptr.is_aligned(), "pointer is not aligned");
+    debug_assert!(
+        !overlaps(ptr.cast_const(), data.len(), data.as_ptr(), data.len()),
+        "output buffer overlaps the data written to it"
+    );
+    // Safety: the pointer is not null, the caller guarantees that it points to memory for
+    // `data.len()` elements, and `data` was computed by this library so it does not overlap
+    // the caller's buffer
+    unsafe { std::ptr::copy_nonoverlapping(data.as_ptr(), ptr, data.len()) };
+    Ok(())
 }
 
-/// Write `data` to a 2D slice starting at `ptr`
+/// Write `data` to `N` buffers, whose pointers start at `ptr`
+///
+/// All the pointers are checked before anything is written, so that an error leaves the
+/// buffers untouched.
 pub(crate) fn write_to_2d_slice<T: Copy, const N: usize>(
     ptr: *mut *mut T,
     data: [impl AsRef<[T]>; N],
-) {
-    let out_cells = ptr_ptr_to_slice_slice_mut(ptr, N);
+) -> Result<(), CResult> {
+    let rows = create_slice_view(ptr.cast_const(), N as u64)?;
+    if rows.iter().any(|row| row.is_null()) {
+        return Err(null_pointer_error());
+    }
+    debug_assert!(
+        rows_are_disjoint(rows, &data),
+        "output buffers overlap each other"
+    );
 
-    for (out_cell, result) in out_cells.iter_mut().zip(data) {
-        write_to_slice(*out_cell, result.as_ref());
+    for (&row, data) in rows.iter().zip(&data) {
+        write_to_slice(row, data.as_ref())?;
     }
+    Ok(())
 }
 
-/// Constructs a array_ref from a pointer and a length.
-///
-/// If the length is 0, an empty slice is returned regardless of the pointer.
-pub(crate) fn create_array_ref<'a, const LEN: usize, T>(ptr: *const T) -> &'a [T; LEN] {
-    let slice = create_slice_view(ptr, LEN);
-    slice.try_into().expect("item should have length {LEN}")
+/// Returns whether the buffers of `len_a` and `len_b` elements starting at `a` and `b` share
+/// an element.
+fn overlaps<T>(a: *const T, len_a: usize, b: *const T, len_b: usize) -> bool {
+    let (a, b) = (a as usize, b as usize);
+    let size = size_of::<T>();
+    a < b + len_b * size && b < a + len_a * size
 }
-pub(crate) fn create_slice_view<'a, T>(ptr: *const T, len: usize) -> &'a [T] {
-    if len == 0 {
-        &[]
-    } else {
-        unsafe { std::slice::from_raw_parts(ptr, len) }
+
+/// Returns whether the buffers starting at `rows`, of the lengths of `data`, are disjoint.
+fn rows_are_disjoint<T>(rows: &[*mut T], data: &[impl AsRef<[T]>]) -> bool {
+    let mut buffers: Vec<_> = rows
+        .iter()
+        .zip(data)
+        .map(|(&row, data)| (row.cast_const(), data.as_ref().len()))
+        .filter(|&(_, len)| len > 0)
+        .collect();
+    buffers.sort_unstable_by_key(|&(row, _)| row as usize);
+    buffers
+        .windows(2)
+        .all(|pair| !overlaps(pair[0].0, pair[0].1, pair[1].0, pair[1].1))
+}
+
+/// Dereference a null-terminated C string
+pub(crate) fn c_str<'a>(ptr: *const c_char) -> Result<&'a CStr, CResult> {
+    if ptr.is_null() {
+        return Err(null_pointer_error());
+    }
+    // Safety: the pointer is not null, and the caller guarantees that it points to a
+    // null-terminated string
+    Ok(unsafe { CStr::from_ptr(ptr) })
+}
+
+/// Moves `value` to the heap and returns a pointer that owns it, to be freed by [`free_boxed`].
+pub(crate) fn into_raw<T>(value: T) -> *mut T {
+    Box::into_raw(Box::new(value))
+}
+
+/// Frees a value allocated by [`into_raw`]. A null pointer is ignored.
+pub(crate) fn free_boxed<T>(ptr: *mut T) {
+    if ptr.is_null() {
+        return;
+    }
+    // Safety: the caller guarantees that the pointer was returned by `into_raw` and has not
+    // been freed yet
+    drop(unsafe { Box::from_raw(ptr) });
+}
+
+/// Frees a string allocated by `CString::into_raw`. A null pointer is ignored.
+pub(crate) fn free_c_string(ptr: *mut c_char) {
+    if ptr.is_null() {
+        return;
+    }
+    // Safety: the caller guarantees that the string was allocated by `CString::into_raw`, as
+    // the error messages of `CResult` are, and has not been freed yet
+    drop(unsafe { std::ffi::CString::from_raw(ptr) });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    /// Returns whether `result` is an error, freeing its message.
+    fn is_err<T>(result: Result<T, CResult>) -> bool {
+        result.map_err(|err| free_c_string(err.error_msg)).is_err()
+    }
+
+    #[test]
+    fn null_pointers_are_errors() {
+        assert!(is_err(deref_const(std::ptr::null::<u64>())));
+        assert!(is_err(deref_mut(std::ptr::null_mut::<u64>())));
+        assert!(is_err(create_array_ref::<4, u8>(std::ptr::null())));
+        assert!(is_err(create_slice_view(std::ptr::null::<u8>(), 1)));
+        assert!(is_err(write_value(std::ptr::null_mut::<u64>(), 1)));
+        assert!(is_err(write_to_slice(std::ptr::null_mut::<u8>(), &[1])));
+        assert!(is_err(c_str(std::ptr::null())));
+
+        // Empty slices do not need a pointer
+        assert_eq!(create_slice_view(std::ptr::null::<u8>(), 0).unwrap(), &[]);
+        assert!(ptr_ptr_to_vec_slice_const::<4>(std::ptr::null(), 0)
+            .unwrap()
+            .is_empty());
+        write_to_slice(std::ptr::null_mut::<u8>(), &[]).unwrap();
+
+        free_boxed(std::ptr::null_mut::<u64>());
+        free_c_string(std::ptr::null_mut());
+    }
+
+    #[test]
+    fn lengths_are_checked() {
+        let data = [1u64, 2, 3];
+        assert_eq!(create_slice_view(data.as_ptr(), 2).unwrap(), &data[..2]);
+
+        // Slices larger than the address space cannot be built, whatever the pointer is
+        assert!(is_err(create_slice_view(data.as_ptr(), u64::MAX)));
+        assert!(is_err(create_slice_view(
+            data.as_ptr(),
+            (isize::MAX as u64) / 8 + 1
+        )));
+        assert!(is_err(ptr_ptr_to_vec_slice_const::<4>(
+            std::ptr::null(),
+            u64::MAX
+        )));
+    }
+
+    #[test]
+    fn reads_arrays_through_pointers() {
+        let rows = [[1u8, 2], [3, 4], [5, 6]];
+        let pointers: Vec<*const u8> = rows.iter().map(|row| row.as_ptr()).collect();
+
+        let read = ptr_ptr_to_vec_slice_const::<2>(pointers.as_ptr(), 3).unwrap();
+        assert_eq!(read, [&rows[0], &rows[1], &rows[2]]);
+
+        let pointers = [rows[0].as_ptr(), std::ptr::null()];
+        assert!(is_err(ptr_ptr_to_vec_slice_const::<2>(
+            pointers.as_ptr(),
+            2
+        )));
+    }
+
+    #[test]
+    fn writes_through_pointers() {
+        let mut value = 0u64;
+        write_value(&raw mut value, 7).unwrap();
+        assert_eq!(value, 7);
+
+        let mut out = [0u8; 3];
+        write_to_slice(out.as_mut_ptr(), &[1, 2, 3]).unwrap();
+        assert_eq!(out, [1, 2, 3]);
+
+        let mut buffers = [[0u8; 2]; 3];
+        let pointers: Vec<*mut u8> = buffers.iter_mut().map(|row| row.as_mut_ptr()).collect();
+        write_to_2d_slice(pointers.as_ptr().cast_mut(), [[1, 2], [3, 4], [5, 6]]).unwrap();
+        assert_eq!(buffers, [[1, 2], [3, 4], [5, 6]]);
+    }
+
+    #[test]
+    fn nothing_is_written_if_a_row_is_null() {
+        let mut buffer = [0u8; 2];
+        let pointers = [buffer.as_mut_ptr(), std::ptr::null_mut()];
+
+        assert!(is_err(write_to_2d_slice(
+            pointers.as_ptr().cast_mut(),
+            [[1, 2], [3, 4]]
+        )));
+        assert_eq!(buffer, [0, 0]);
+    }
+
+    #[test]
+    fn detects_overlapping_buffers() {
+        let buffer = [0u64; 4];
+        let ptr = buffer.as_ptr();
+        assert!(overlaps(ptr, 2, ptr.wrapping_add(1), 2));
+        assert!(!overlaps(ptr, 2, ptr.wrapping_add(2), 2));
+
+        let mut buffer = [0u8; 4];
+        let start = buffer.as_mut_ptr();
+        let rows = [start, start.wrapping_add(2)];
+        assert!(rows_are_disjoint(&rows, &[[1, 2], [3, 4]]));
+        assert!(!rows_are_disjoint(&rows, &[[1, 2, 3], [4, 5, 6]]));
+    }
+
+    #[test]
+    fn boxed_values_roundtrip() {
+        let ptr = into_raw(vec![1u8, 2, 3]);
+        assert_eq!(deref_const(ptr).unwrap(), &[1, 2, 3]);
+        free_boxed(ptr);
+
+        let message = std::ffi::CString::new("message").unwrap().into_raw();
+        assert_eq!(c_str(message).unwrap().to_str(), Ok("message"));
+        free_c_string(message);
+    }
+
+    #[cfg_attr(miri, ignore)]
+    #[test]
+    fn unsafe_code_is_only_in_this_module() {
+        let source_dir = std::path::Path::new(env!("CARGO_MANIFEST_DIR")).join("src");
+        for entry in std::fs::read_dir(source_dir).unwrap() {
+            let path = entry.unwrap().path();
+            if path.file_name() == Some("pointer_utils.rs".as_ref()) {
+                continue;
+            }
+
+            // Declaring an unsafe function is allowed, as long as its body calls this module
+            let source = std::fs::read_to_string(&path).unwrap();
+            for pattern in ["unsafe {", "unsafe impl", "unsafe fn "] {
+                assert!(
+                    !source.contains(pattern),
+                    "{} contains `{pattern}`, pointers should be handled by pointer_utils.rs",
+                    path.display()
+                );
+            }
+        }
     }
 }
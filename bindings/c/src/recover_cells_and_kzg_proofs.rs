@@ -16,24 +16,22 @@ pub(crate) fn _recover_cells_and_proofs(
     out_cells: *mut *mut u8,
     out_proofs: *mut *mut u8,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let cells = ptr_ptr_to_vec_slice_const::<BYTES_PER_CELL>(cells, cells_length as usize);
-    let cell_indices = create_slice_view(cell_indices, cell_indices_length as usize);
+    let ctx = deref_const(ctx)?;
+    let cells = ptr_ptr_to_vec_slice_const::<BYTES_PER_CELL>(cells, cells_length)?;
+    let cell_indices = create_slice_view(cell_indices, cell_indices_length)?;
 
     // Computation
     //
     let (recovered_cells, recovered_proofs) = ctx
         .recover_cells_and_kzg_proofs(cell_indices.to_vec(), cells)
-        .map_err(|err| CResult::with_error(&format!("{err:?}")))?;
+        .map_err(CResult::from)?;
     let recovered_cells_unboxed = recovered_cells.map(|cell| cell.to_vec());
 
     // Write to output
-    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, recovered_cells_unboxed);
-    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_proofs, recovered_proofs);
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_cells, recovered_cells_unboxed)?;
+    write_to_2d_slice::<_, CELLS_PER_EXT_BLOB>(out_proofs, recovered_proofs)?;
 
     Ok(())
 }
@@ -1,7 +1,7 @@
 use rust_eth_kzg::constants::{BYTES_PER_BLOB, BYTES_PER_COMMITMENT};
 
 use crate::{
-    pointer_utils::{create_array_ref, deref_const, deref_mut},
+    pointer_utils::{create_array_ref, deref_const, write_value},
     verification_result_to_bool_cresult, CResult, DASContext,
 };
 
@@ -12,15 +12,12 @@ pub(crate) fn _verify_blob_kzg_proof(
     proof: *const u8,
     verified: *mut bool,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob);
-    let commitment = create_array_ref::<BYTES_PER_COMMITMENT, _>(commitment);
-    let proof = create_array_ref::<BYTES_PER_COMMITMENT, _>(proof);
-    let verified = deref_mut(verified);
+    let ctx = deref_const(ctx)?;
+    let blob = create_array_ref::<BYTES_PER_BLOB, _>(blob)?;
+    let commitment = create_array_ref::<BYTES_PER_COMMITMENT, _>(commitment)?;
+    let proof = create_array_ref::<BYTES_PER_COMMITMENT, _>(proof)?;
 
     // Computation
     //
@@ -28,7 +25,7 @@ pub(crate) fn _verify_blob_kzg_proof(
 
     // Write to output
     let proof_is_valid = verification_result_to_bool_cresult(verification_result)?;
-    *verified = proof_is_valid;
+    write_value(verified, proof_is_valid)?;
 
     Ok(())
 }
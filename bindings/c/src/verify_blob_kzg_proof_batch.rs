@@ -1,7 +1,7 @@
 use rust_eth_kzg::constants::{BYTES_PER_BLOB, BYTES_PER_COMMITMENT};
 
 use crate::{
-    pointer_utils::{deref_const, deref_mut, ptr_ptr_to_vec_slice_const},
+    pointer_utils::{deref_const, ptr_ptr_to_vec_slice_const, write_value},
     verification_result_to_bool_cresult, CResult, DASContext,
 };
 
@@ -16,18 +16,13 @@ pub(crate) fn _verify_blob_kzg_proof_batch(
     proofs: *const *const u8,
     verified: *mut bool,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
-
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let blobs = ptr_ptr_to_vec_slice_const::<BYTES_PER_BLOB>(blobs, blobs_length as usize);
-    let commitments = ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(
-        commitments,
-        commitments_length as usize,
-    );
-    let proofs = ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(proofs, proofs_length as usize);
-    let verified = deref_mut(verified);
+    let ctx = deref_const(ctx)?;
+    let blobs = ptr_ptr_to_vec_slice_const::<BYTES_PER_BLOB>(blobs, blobs_length)?;
+    let commitments =
+        ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(commitments, commitments_length)?;
+    let proofs = ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(proofs, proofs_length)?;
 
     // Computation - now all parameters use reference types consistently
     //
@@ -35,7 +30,7 @@ pub(crate) fn _verify_blob_kzg_proof_batch(
 
     // Write to output
     let proof_is_valid = verification_result_to_bool_cresult(verification_result)?;
-    *verified = proof_is_valid;
+    write_value(verified, proof_is_valid)?;
 
     Ok(())
 }
@@ -1,7 +1,7 @@
 use rust_eth_kzg::constants::{BYTES_PER_CELL, BYTES_PER_COMMITMENT};
 
 use crate::{
-    pointer_utils::{create_slice_view, deref_const, deref_mut, ptr_ptr_to_vec_slice_const},
+    pointer_utils::{create_slice_view, deref_const, ptr_ptr_to_vec_slice_const, write_value},
     verification_result_to_bool_cresult, CResult, DASContext,
 };
 
@@ -23,18 +23,14 @@ pub(crate) fn _verify_cell_kzg_proof_batch(
 
     verified: *mut bool,
 ) -> Result<(), CResult> {
-    assert!(!ctx.is_null(), "context pointer is null");
     // Dereference the input pointers
     //
-    let ctx = deref_const(ctx);
-    let commitments = ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(
-        commitments,
-        commitments_length as usize,
-    );
-    let cell_indices = create_slice_view(cell_indices, cell_indices_length as usize);
-    let cells = ptr_ptr_to_vec_slice_const::<BYTES_PER_CELL>(cells, cells_length as usize);
-    let proofs = ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(proofs, proofs_length as usize);
-    let verified = deref_mut(verified);
+    let ctx = deref_const(ctx)?;
+    let commitments =
+        ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(commitments, commitments_length)?;
+    let cell_indices = create_slice_view(cell_indices, cell_indices_length)?;
+    let cells = ptr_ptr_to_vec_slice_const::<BYTES_PER_CELL>(cells, cells_length)?;
+    let proofs = ptr_ptr_to_vec_slice_const::<BYTES_PER_COMMITMENT>(proofs, proofs_length)?;
 
     // Computation
     //
@@ -43,7 +39,7 @@ pub(crate) fn _verify_cell_kzg_proof_batch(
 
     // Write to output
     let proof_is_valid = verification_result_to_bool_cresult(verification_result)?;
-    *verified = proof_is_valid;
+    write_value(verified, proof_is_valid)?;
 
     Ok(())
 }
@@ -0,0 +1,314 @@
+//! Calls the C API the way a C caller does, through raw pointers to buffers that the caller
+//! allocated, so that the pointer handling of the crate can be checked by the sanitizers.
+//!
+//! Run with AddressSanitizer or ThreadSanitizer on nightly:
+//!
+//! ```text
+//! RUSTFLAGS="-Zsanitizer=address" cargo +nightly test -Zbuild-std \
+//!     --target x86_64-unknown-linux-gnu -p c_eth_kzg --test ffi
+//! ```
+//!
+//! These tests call into blst, which Miri cannot run, so under Miri only the unit tests of
+//! `pointer_utils.rs` are run.
+#![cfg(all(not(miri), not(feature = "no-embedded-setup")))]
+
+use c_eth_kzg::*;
+
+/// Panics if `result` is an error, and frees its message.
+fn check(result: CResult) {
+    let failed = matches!(result.status, CResultStatus::Err);
+    let message = if failed {
+        // Safety: the message of an error is a valid C string
+        let message = unsafe { std::ffi::CStr::from_ptr(result.error_msg) };
+        message.to_string_lossy().into_owned()
+    } else {
+        String::new()
+    };
+    // Safety: the message was allocated by the library, and is only freed here
+    unsafe { eth_kzg_free_error_message(result.error_msg) };
+    assert!(!failed, "call failed: {message}");
+}
+
+/// Returns the error code of `result`, and frees its message.
+fn error_code(result: CResult) -> u32 {
+    // Safety: the message was allocated by the library, and is only freed here
+    unsafe { eth_kzg_free_error_message(result.error_msg) };
+    result.error_code
+}
+
+fn blob() -> Vec<u8> {
+    let mut blob = vec![0u8; BYTES_PER_BLOB];
+    for (i, element) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+        element[BYTES_PER_FIELD_ELEMENT - 8..].copy_from_slice(&(i as u64).to_be_bytes());
+    }
+    blob
+}
+
+/// Output buffers for the cells and proofs of a blob, and the pointers to them.
+struct CellsAndProofs {
+    cells: Vec<Vec<u8>>,
+    proofs: Vec<Vec<u8>>,
+}
+
+impl CellsAndProofs {
+    fn new() -> Self {
+        Self {
+            cells: vec![vec![0u8; BYTES_PER_CELL]; CELLS_PER_EXT_BLOB],
+            proofs: vec![vec![0u8; BYTES_PER_COMMITMENT]; CELLS_PER_EXT_BLOB],
+        }
+    }
+
+    fn out_pointers(&mut self) -> (Vec<*mut u8>, Vec<*mut u8>) {
+        (
+            self.cells
+                .iter_mut()
+                .map(|cell| cell.as_mut_ptr())
+                .collect(),
+            self.proofs
+                .iter_mut()
+                .map(|proof| proof.as_mut_ptr())
+                .collect(),
+        )
+    }
+}
+
+#[test]
+fn prove_verify_and_recover_through_pointers() {
+    let ctx = eth_kzg_das_context_new(false);
+    assert!(!ctx.is_null());
+    let blob = blob();
+
+    let mut commitment = [0u8; BYTES_PER_COMMITMENT];
+    check(eth_kzg_blob_to_kzg_commitment(
+        ctx,
+        blob.as_ptr(),
+        commitment.as_mut_ptr(),
+    ));
+
+    let mut computed = CellsAndProofs::new();
+    let (mut out_cells, mut out_proofs) = computed.out_pointers();
+    check(eth_kzg_compute_cells_and_kzg_proofs(
+        ctx,
+        blob.as_ptr(),
+        out_cells.as_mut_ptr(),
+        out_proofs.as_mut_ptr(),
+    ));
+
+    // Verify every other cell
+    let indices: Vec<u64> = (0..CELLS_PER_EXT_BLOB as u64).step_by(2).collect();
+    let commitments = vec![commitment.as_ptr(); indices.len()];
+    let cells: Vec<*const u8> = indices
+        .iter()
+        .map(|&i| computed.cells[i as usize].as_ptr())
+        .collect();
+    let proofs: Vec<*const u8> = indices
+        .iter()
+        .map(|&i| computed.proofs[i as usize].as_ptr())
+        .collect();
+    let mut verified = false;
+    check(eth_kzg_verify_cell_kzg_proof_batch(
+        ctx,
+        commitments.len() as u64,
+        commitments.as_ptr(),
+        indices.len() as u64,
+        indices.as_ptr(),
+        cells.len() as u64,
+        cells.as_ptr(),
+        proofs.len() as u64,
+        proofs.as_ptr(),
+        &raw mut verified,
+    ));
+    assert!(verified);
+
+    // Recover the other half
+    let mut recovered = CellsAndProofs::new();
+    let (mut out_cells, mut out_proofs) = recovered.out_pointers();
+    check(eth_kzg_recover_cells_and_kzg_proofs(
+        ctx,
+        cells.len() as u64,
+        cells.as_ptr(),
+        indices.len() as u64,
+        indices.as_ptr(),
+        out_cells.as_mut_ptr(),
+        out_proofs.as_mut_ptr(),
+    ));
+    assert_eq!(recovered.cells, computed.cells);
+    assert_eq!(recovered.proofs, computed.proofs);
+
+    eth_kzg_das_context_free(ctx);
+}
+
+#[test]
+fn null_arguments_are_errors() {
+    let ctx = eth_kzg_das_context_new(false);
+    let blob = blob();
+    let mut commitment = [0u8; BYTES_PER_COMMITMENT];
+
+    let invalid_argument = ErrorCode::InvalidArgument.as_u32();
+    assert_eq!(
+        error_code(eth_kzg_blob_to_kzg_commitment(
+            ctx,
+            std::ptr::null(),
+            commitment.as_mut_ptr(),
+        )),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_blob_to_kzg_commitment(
+            ctx,
+            blob.as_ptr(),
+            std::ptr::null_mut(),
+        )),
+        invalid_argument
+    );
+
+    // A null cell in the batch, and an empty batch given as null pointers
+    let mut verified = false;
+    let commitments = [commitment.as_ptr()];
+    let index = [0u64];
+    let null_cell = [std::ptr::null::<u8>()];
+    assert_eq!(
+        error_code(eth_kzg_verify_cell_kzg_proof_batch(
+            ctx,
+            1,
+            commitments.as_ptr(),
+            1,
+            index.as_ptr(),
+            1,
+            null_cell.as_ptr(),
+            1,
+            null_cell.as_ptr(),
+            &raw mut verified,
+        )),
+        invalid_argument
+    );
+    check(eth_kzg_verify_cell_kzg_proof_batch(
+        ctx,
+        0,
+        std::ptr::null(),
+        0,
+        std::ptr::null(),
+        0,
+        std::ptr::null(),
+        0,
+        std::ptr::null(),
+        &raw mut verified,
+    ));
+    assert!(verified);
+
+    eth_kzg_das_context_free(ctx);
+}
+
+#[test]
+fn null_context_and_outputs_are_errors() {
+    let blob = blob();
+    let mut commitment = [0u8; BYTES_PER_COMMITMENT];
+    let (mut hits, mut misses) = (0u64, 0u64);
+
+    let invalid_argument = ErrorCode::InvalidArgument.as_u32();
+    assert_eq!(
+        error_code(eth_kzg_blob_to_kzg_commitment(
+            std::ptr::null(),
+            blob.as_ptr(),
+            commitment.as_mut_ptr(),
+        )),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_das_context_set_admission_limits(
+            std::ptr::null_mut(),
+            1,
+            1,
+            0,
+        )),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_das_context_set_verification_cache(
+            std::ptr::null_mut(),
+            16,
+        )),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_das_context_verification_cache_stats(
+            std::ptr::null(),
+            &raw mut hits,
+            &raw mut misses,
+        )),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_das_context_self_test(std::ptr::null())),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_das_context_new_for_forks(
+            ETH_KZG_FORK_DENEB,
+            0,
+            std::ptr::null_mut(),
+        )),
+        invalid_argument
+    );
+
+    // A null scratch, with a valid context
+    let ctx = eth_kzg_das_context_new(false);
+    let mut out = CellsAndProofs::new();
+    let (mut out_cells, _) = out.out_pointers();
+    assert_eq!(
+        error_code(eth_kzg_compute_cells_with_scratch(
+            ctx,
+            std::ptr::null_mut(),
+            blob.as_ptr(),
+            out_cells.as_mut_ptr(),
+        )),
+        invalid_argument
+    );
+    assert_eq!(
+        error_code(eth_kzg_das_scratch_new(ctx, std::ptr::null_mut())),
+        invalid_argument
+    );
+
+    eth_kzg_das_context_free(ctx);
+}
+
+#[test]
+fn context_is_shared_between_threads() {
+    let ctx = eth_kzg_das_context_new(false);
+    let blob = blob();
+
+    let mut commitment = [0u8; BYTES_PER_COMMITMENT];
+    check(eth_kzg_blob_to_kzg_commitment(
+        ctx,
+        blob.as_ptr(),
+        commitment.as_mut_ptr(),
+    ));
+    let mut proof = [0u8; BYTES_PER_COMMITMENT];
+    check(eth_kzg_compute_blob_kzg_proof(
+        ctx,
+        blob.as_ptr(),
+        commitment.as_ptr(),
+        proof.as_mut_ptr(),
+    ));
+
+    // Raw pointers are not `Send`, so the context is passed to the threads as an address, as
+    // a C caller would share it
+    let ctx_address = ctx as usize;
+    std::thread::scope(|scope| {
+        for _ in 0..4 {
+            scope.spawn(|| {
+                let mut verified = false;
+                check(eth_kzg_verify_blob_kzg_proof(
+                    ctx_address as *const DASContext,
+                    blob.as_ptr(),
+                    commitment.as_ptr(),
+                    proof.as_ptr(),
+                    &raw mut verified,
+                ));
+                assert!(verified);
+            });
+        }
+    });
+
+    eth_kzg_das_context_free(ctx);
+}
@@ -0,0 +1,105 @@
+namespace EthKZG;
+
+/// <summary>
+/// The stable codes of the errors returned by the native library.
+/// </summary>
+/// <remarks>
+/// The values match <c>rust_eth_kzg::ErrorCode</c>, so an error seen in .NET can be looked up in
+/// the Rust source. They are never renumbered or reused.
+/// </remarks>
+public enum ErrorCode : uint
+{
+    /// <summary>A field element is not less than the scalar field modulus.</summary>
+    NonCanonicalScalar = 101,
+    /// <summary>A G1 point is not a valid compressed encoding.</summary>
+    InvalidG1PointEncoding = 102,
+    /// <summary>A G1 point is not on the curve.</summary>
+    G1PointNotOnCurve = 103,
+    /// <summary>A G1 point is on the curve, but not in the G1 subgroup.</summary>
+    G1PointNotInSubgroup = 104,
+    /// <summary>A field element does not have the right number of bytes.</summary>
+    ScalarHasInvalidLength = 105,
+    /// <summary>A blob does not have the right number of bytes.</summary>
+    BlobHasInvalidLength = 106,
+    /// <summary>A G1 point does not have the right number of bytes.</summary>
+    G1PointHasInvalidLength = 107,
+    /// <summary>A cell index is not less than the number of cells in an extended blob.</summary>
+    CellIndexOutOfRange = 201,
+    /// <summary>A commitment index does not refer to one of the commitments.</summary>
+    CommitmentIndexOutOfRange = 202,
+    /// <summary>The inputs to a batch operation do not have the same length.</summary>
+    BatchLengthMismatch = 203,
+    /// <summary>The number of cell indices differs from the number of cells.</summary>
+    NumCellIndicesNotEqualToNumCells = 204,
+    /// <summary>Fewer than half of the cells were given to recovery.</summary>
+    NotEnoughCellsToReconstruct = 205,
+    /// <summary>More cells than there are in an extended blob were given to recovery.</summary>
+    TooManyCellsReceived = 206,
+    /// <summary>The cell indices given to recovery are not sorted and unique.</summary>
+    CellIndicesNotUniquelyOrdered = 207,
+    /// <summary>A cell does not contain one evaluation per point in its coset.</summary>
+    InvalidCellLength = 208,
+    /// <summary>A proof failed verification.</summary>
+    InvalidProof = 301,
+    /// <summary>A polynomial had an unexpected number of coefficients.</summary>
+    PolynomialHasInvalidLength = 401,
+    /// <summary>A polynomial had more coefficients than the code allows.</summary>
+    PolynomialHasTooManyCoefficients = 402,
+    /// <summary>More blocks were erased than the code can correct.</summary>
+    TooManyBlockErasures = 403,
+    /// <summary>A block index is not less than the block size.</summary>
+    BlockIndexOutOfRange = 404,
+    /// <summary>The trusted setup has too few points to be checked.</summary>
+    TrustedSetupNotEnoughPoints = 501,
+    /// <summary>The first point of the trusted setup is not the generator.</summary>
+    TrustedSetupFirstPointIsNotGenerator = 502,
+    /// <summary>A G1 point of the trusted setup is not in the G1 subgroup.</summary>
+    TrustedSetupG1PointNotInSubgroup = 503,
+    /// <summary>A G2 point of the trusted setup is not in the G2 subgroup.</summary>
+    TrustedSetupG2PointNotInSubgroup = 504,
+    /// <summary>The G1 points of the trusted setup are not powers of the same secret.</summary>
+    TrustedSetupInconsistentG1Powers = 505,
+    /// <summary>The G2 points of the trusted setup are not powers of the same secret as the G1 points.</summary>
+    TrustedSetupInconsistentG2Powers = 506,
+    /// <summary>The operation was cancelled because the async runtime is shutting down.</summary>
+    Cancelled = 601,
+    /// <summary>A prover method was called on a context that was built without a prover.</summary>
+    VerifierOnlyContext = 602,
+    /// <summary>The operation was rejected because the context was running and queuing as many operations as its admission limits allow.</summary>
+    Overloaded = 603,
+    /// <summary>The operation waited for longer than the queue timeout of the admission limits of the context.</summary>
+    AdmissionTimedOut = 604,
+    /// <summary>An argument could not be passed to the library.</summary>
+    InvalidArgument = 901,
+    /// <summary>The trusted setup could not be read.</summary>
+    TrustedSetupUnreadable = 902,
+    /// <summary>The trusted setup could not be parsed.</summary>
+    TrustedSetupMalformed = 903,
+    /// <summary>A thread pool could not be created.</summary>
+    ThreadPoolCreationFailed = 904,
+    /// <summary>The self test of the context failed.</summary>
+    SelfTestFailed = 905,
+    /// <summary>The library does not implement the version of the C ABI that a binding was built against.</summary>
+    AbiVersionMismatch = 906,
+}
+
+/// <summary>
+/// Thrown when the native library returns an error.
+/// </summary>
+/// <remarks>
+/// This derives from <see cref="ArgumentException"/>, which the bindings threw before error codes
+/// were added, so existing handlers keep working.
+/// </remarks>
+public sealed class EthKZGException : ArgumentException
+{
+    /// <summary>
+    /// The error code returned by the native library. This may be a value that is not defined in
+    /// <see cref="ErrorCode"/> if the native library is newer than the bindings.
+    /// </summary>
+    public ErrorCode Code { get; }
+
+    public EthKZGException(ErrorCode code, string message) : base(message)
+    {
+        Code = code;
+    }
+}
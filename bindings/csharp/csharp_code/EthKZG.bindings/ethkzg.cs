@@ -4,7 +4,7 @@ using System.Runtime.InteropServices;
 
 namespace EthKZG;
 
-public sealed unsafe class EthKZG : IDisposable
+public sealed unsafe partial class EthKZG : IDisposable
 {
     // These constants are copied from the c-kzg csharp bindings file.
     //
@@ -26,9 +26,36 @@ public sealed unsafe class EthKZG : IDisposable
 
     private DASContext* _context;
 
-    public EthKZG(bool usePrecomp = true)
+    // Fail when the class is first used if the native library implements another version
+    // of the C ABI, rather than on the first call to a symbol that is missing or has changed.
+    static EthKZG()
+    {
+        ThrowOnError(eth_kzg_abi_negotiate(ETH_KZG_ABI_VERSION_MAJOR, ETH_KZG_ABI_VERSION_MINOR));
+    }
+
+    /// <summary>
+    /// Creates a new context. If <paramref name="numThreads"/> is set, the context runs on a
+    /// dedicated thread pool with that many threads instead of one thread per CPU. A value of
+    /// 1 makes the context fully single-threaded.
+    /// </summary>
+    public EthKZG(bool usePrecomp = true, uint? numThreads = null)
     {
         _context = eth_kzg_das_context_new(usePrecomp);
+        if (_context == null)
+        {
+            throw new InvalidOperationException("could not create the context: the trusted setup could not be loaded");
+        }
+
+        if (numThreads is uint threads)
+        {
+            CResult result = eth_kzg_das_context_set_num_threads(_context, threads);
+            if (result.status != CResultStatus.Ok)
+            {
+                eth_kzg_das_context_free(_context);
+                _context = null;
+            }
+            ThrowOnError(result);
+        }
     }
 
     public void Dispose()
@@ -46,7 +73,7 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blob.Length != BytesPerBlob)
         {
-            throw new ArgumentException($"blob has an invalid length");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blob has an invalid length");
         }
 
         byte[] commitment = new byte[BytesPerCommitment];
@@ -67,7 +94,7 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blob.Length != BytesPerBlob)
         {
-            throw new ArgumentException($"blob has an invalid length");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blob has an invalid length");
         }
 
         int numProofs = CellsPerExtBlob;
@@ -111,7 +138,7 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blob.Length != BytesPerBlob)
         {
-            throw new ArgumentException($"blob has an invalid length");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blob has an invalid length");
         }
 
         int numCells = CellsPerExtBlob;
@@ -147,7 +174,7 @@ public sealed unsafe class EthKZG : IDisposable
         {
             if (cells[i].Length != BytesPerCell)
             {
-                throw new ArgumentException($"cell at index {i} has an invalid length");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"cell at index {i} has an invalid length");
             }
         }
 
@@ -155,7 +182,7 @@ public sealed unsafe class EthKZG : IDisposable
         {
             if (proofs[i].Length != BytesPerCommitment)
             {
-                throw new ArgumentException($"proof at index {i} has an invalid length");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"proof at index {i} has an invalid length");
             }
         }
 
@@ -163,7 +190,7 @@ public sealed unsafe class EthKZG : IDisposable
         {
             if (commitments[i].Length != BytesPerCommitment)
             {
-                throw new ArgumentException($"commitments at index {i} has an invalid length");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"commitments at index {i} has an invalid length");
             }
         }
 
@@ -224,7 +251,7 @@ public sealed unsafe class EthKZG : IDisposable
         {
             if (cells[i].Length != BytesPerCell)
             {
-                throw new ArgumentException($"cell at index {i} has an invalid length");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"cell at index {i} has an invalid length");
             }
         }
 
@@ -268,7 +295,7 @@ public sealed unsafe class EthKZG : IDisposable
                 outProofsPtrPtr[i] = outProofsPtr + i * BytesPerProof;
             }
 
-            CResult result = eth_kzg_recover_cells_and_proofs(_context, Convert.ToUInt64(numInputCells), inputCellsPtrPtr, Convert.ToUInt64(cellIds.Length), cellIdsPtr, outCellsPtrPtr, outProofsPtrPtr);
+            CResult result = eth_kzg_recover_cells_and_kzg_proofs(_context, Convert.ToUInt64(numInputCells), inputCellsPtrPtr, Convert.ToUInt64(cellIds.Length), cellIdsPtr, outCellsPtrPtr, outProofsPtrPtr);
             ThrowOnError(result);
         }
 
@@ -282,12 +309,12 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blob.Length != BytesPerBlob)
         {
-            throw new ArgumentException($"blob has an invalid length. Expected {BytesPerBlob}, got {blob.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blob has an invalid length. Expected {BytesPerBlob}, got {blob.Length}");
         }
 
         if (z.Length != BytesPerFieldElement)
         {
-            throw new ArgumentException($"z has an invalid length. Expected {BytesPerFieldElement}, got {z.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"z has an invalid length. Expected {BytesPerFieldElement}, got {z.Length}");
         }
 
         byte[] proof = new byte[BytesPerProof];
@@ -310,12 +337,12 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blob.Length != BytesPerBlob)
         {
-            throw new ArgumentException($"blob has an invalid length. Expected {BytesPerBlob}, got {blob.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blob has an invalid length. Expected {BytesPerBlob}, got {blob.Length}");
         }
 
         if (commitment.Length != BytesPerCommitment)
         {
-            throw new ArgumentException($"commitment has an invalid length. Expected {BytesPerCommitment}, got {commitment.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"commitment has an invalid length. Expected {BytesPerCommitment}, got {commitment.Length}");
         }
 
         byte[] proof = new byte[BytesPerProof];
@@ -336,22 +363,22 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (commitment.Length != BytesPerCommitment)
         {
-            throw new ArgumentException($"commitment has an invalid length. Expected {BytesPerCommitment}, got {commitment.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"commitment has an invalid length. Expected {BytesPerCommitment}, got {commitment.Length}");
         }
 
         if (z.Length != BytesPerFieldElement)
         {
-            throw new ArgumentException($"z has an invalid length. Expected {BytesPerFieldElement}, got {z.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"z has an invalid length. Expected {BytesPerFieldElement}, got {z.Length}");
         }
 
         if (y.Length != BytesPerFieldElement)
         {
-            throw new ArgumentException($"y has an invalid length. Expected {BytesPerFieldElement}, got {y.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"y has an invalid length. Expected {BytesPerFieldElement}, got {y.Length}");
         }
 
         if (proof.Length != BytesPerProof)
         {
-            throw new ArgumentException($"proof has an invalid length. Expected {BytesPerProof}, got {proof.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"proof has an invalid length. Expected {BytesPerProof}, got {proof.Length}");
         }
 
         bool verified = false;
@@ -373,17 +400,17 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blob.Length != BytesPerBlob)
         {
-            throw new ArgumentException($"blob has an invalid length. Expected {BytesPerBlob}, got {blob.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blob has an invalid length. Expected {BytesPerBlob}, got {blob.Length}");
         }
 
         if (commitment.Length != BytesPerCommitment)
         {
-            throw new ArgumentException($"commitment has an invalid length. Expected {BytesPerCommitment}, got {commitment.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"commitment has an invalid length. Expected {BytesPerCommitment}, got {commitment.Length}");
         }
 
         if (proof.Length != BytesPerProof)
         {
-            throw new ArgumentException($"proof has an invalid length. Expected {BytesPerProof}, got {proof.Length}");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"proof has an invalid length. Expected {BytesPerProof}, got {proof.Length}");
         }
 
         bool verified = false;
@@ -404,14 +431,14 @@ public sealed unsafe class EthKZG : IDisposable
         // Length checks
         if (blobs.Length != commitments.Length || blobs.Length != proofs.Length)
         {
-            throw new ArgumentException($"blobs, commitments, and proofs must have the same length");
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"blobs, commitments, and proofs must have the same length");
         }
 
         for (int i = 0; i < blobs.Length; i++)
         {
             if (blobs[i].Length != BytesPerBlob)
             {
-                throw new ArgumentException($"blob at index {i} has an invalid length. Expected {BytesPerBlob}, got {blobs[i].Length}");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"blob at index {i} has an invalid length. Expected {BytesPerBlob}, got {blobs[i].Length}");
             }
         }
 
@@ -419,7 +446,7 @@ public sealed unsafe class EthKZG : IDisposable
         {
             if (commitments[i].Length != BytesPerCommitment)
             {
-                throw new ArgumentException($"commitment at index {i} has an invalid length. Expected {BytesPerCommitment}, got {commitments[i].Length}");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"commitment at index {i} has an invalid length. Expected {BytesPerCommitment}, got {commitments[i].Length}");
             }
         }
 
@@ -427,7 +454,7 @@ public sealed unsafe class EthKZG : IDisposable
         {
             if (proofs[i].Length != BytesPerProof)
             {
-                throw new ArgumentException($"proof at index {i} has an invalid length. Expected {BytesPerProof}, got {proofs[i].Length}");
+                throw new EthKZGException(ErrorCode.InvalidArgument, $"proof at index {i} has an invalid length. Expected {BytesPerProof}, got {proofs[i].Length}");
             }
         }
 
@@ -489,18 +516,19 @@ public sealed unsafe class EthKZG : IDisposable
         {
             case CResultStatus.Err:
                 string? errorMessage = Marshal.PtrToStringAnsi((IntPtr)result.error_msg);
+                ErrorCode code = (ErrorCode)result.error_code;
 
                 if (errorMessage != null)
                 {
                     // Free the error message that we allocated on the rust side
                     eth_kzg_free_error_message(result.error_msg);
-                    throw new ArgumentException($"an error occurred from the bindings: {errorMessage}");
+                    throw new EthKZGException(code, $"an error occurred from the bindings: {errorMessage}");
                 }
                 else
                 {
                     // This branch should not be hit, ie when the native library returns
                     // and error, the error_message should always be set.
-                    throw new ArgumentException("an error occurred from the bindings: unknown error");
+                    throw new EthKZGException(code, "an error occurred from the bindings: unknown error");
                 }
             case CResultStatus.Ok:
                 return;
@@ -0,0 +1,257 @@
+using System.Buffers;
+using static EthKZG.Native.NativeMethods;
+
+namespace EthKZG;
+
+// The overloads of the methods of EthKZG that take and return Blob, Cell, KzgCommitment and
+// KzgProof. The arrays of pointers that the batch methods pass to the native library are rented
+// from ArrayPool rather than allocated on every call, and the inputs are pinned until the call
+// returns.
+public sealed unsafe partial class EthKZG
+{
+    public KzgCommitment BlobToKzgCommitment(Blob blob)
+    {
+        byte[] commitment = new byte[BytesPerCommitment];
+
+        using Pinned pinnedBlob = new(blob.Memory, BytesPerBlob, "blob");
+        fixed (byte* commitmentPtr = commitment)
+        {
+            CResult result = eth_kzg_blob_to_kzg_commitment(_context, pinnedBlob.Pointer, commitmentPtr);
+            ThrowOnError(result);
+        }
+
+        return commitment;
+    }
+
+    public (Cell[] Cells, KzgProof[] Proofs) ComputeCellsAndKZGProofs(Blob blob)
+    {
+        byte[] outCells = new byte[CellsPerExtBlob * BytesPerCell];
+        byte[] outProofs = new byte[CellsPerExtBlob * BytesPerProof];
+
+        using Pinned pinnedBlob = new(blob.Memory, BytesPerBlob, "blob");
+        using PinnedPointers outCellsPtrs = PinnedPointers.Segments(outCells, BytesPerCell);
+        using PinnedPointers outProofsPtrs = PinnedPointers.Segments(outProofs, BytesPerProof);
+
+        CResult result = eth_kzg_compute_cells_and_kzg_proofs(_context, pinnedBlob.Pointer, outCellsPtrs.Pointer, outProofsPtrs.Pointer);
+        ThrowOnError(result);
+
+        return (Cells(outCells), Proofs(outProofs));
+    }
+
+    public Cell[] ComputeCells(Blob blob)
+    {
+        byte[] outCells = new byte[CellsPerExtBlob * BytesPerCell];
+
+        using Pinned pinnedBlob = new(blob.Memory, BytesPerBlob, "blob");
+        using PinnedPointers outCellsPtrs = PinnedPointers.Segments(outCells, BytesPerCell);
+
+        CResult result = eth_kzg_compute_cells(_context, pinnedBlob.Pointer, outCellsPtrs.Pointer);
+        ThrowOnError(result);
+
+        return Cells(outCells);
+    }
+
+    public (Cell[] Cells, KzgProof[] Proofs) RecoverCellsAndKZGProofs(ReadOnlySpan<ulong> cellIndices, ReadOnlySpan<Cell> cells)
+    {
+        byte[] outCells = new byte[CellsPerExtBlob * BytesPerCell];
+        byte[] outProofs = new byte[CellsPerExtBlob * BytesPerProof];
+
+        using PinnedPointers cellsPtrs = new(cells.Length);
+        for (int i = 0; i < cells.Length; i++)
+        {
+            cellsPtrs.Set(i, cells[i].Memory, BytesPerCell, "cell");
+        }
+        using PinnedPointers outCellsPtrs = PinnedPointers.Segments(outCells, BytesPerCell);
+        using PinnedPointers outProofsPtrs = PinnedPointers.Segments(outProofs, BytesPerProof);
+
+        fixed (ulong* cellIndicesPtr = cellIndices)
+        {
+            CResult result = eth_kzg_recover_cells_and_kzg_proofs(_context,
+                Convert.ToUInt64(cells.Length), cellsPtrs.Pointer,
+                Convert.ToUInt64(cellIndices.Length), cellIndicesPtr,
+                outCellsPtrs.Pointer, outProofsPtrs.Pointer);
+            ThrowOnError(result);
+        }
+
+        return (Cells(outCells), Proofs(outProofs));
+    }
+
+    public bool VerifyCellKZGProofBatch(ReadOnlySpan<KzgCommitment> commitments, ReadOnlySpan<ulong> cellIndices, ReadOnlySpan<Cell> cells, ReadOnlySpan<KzgProof> proofs)
+    {
+        using PinnedPointers commitmentsPtrs = new(commitments.Length);
+        for (int i = 0; i < commitments.Length; i++)
+        {
+            commitmentsPtrs.Set(i, commitments[i].Memory, BytesPerCommitment, "commitment");
+        }
+        using PinnedPointers cellsPtrs = new(cells.Length);
+        for (int i = 0; i < cells.Length; i++)
+        {
+            cellsPtrs.Set(i, cells[i].Memory, BytesPerCell, "cell");
+        }
+        using PinnedPointers proofsPtrs = new(proofs.Length);
+        for (int i = 0; i < proofs.Length; i++)
+        {
+            proofsPtrs.Set(i, proofs[i].Memory, BytesPerProof, "proof");
+        }
+
+        bool verified = false;
+
+        fixed (ulong* cellIndicesPtr = cellIndices)
+        {
+            CResult result = eth_kzg_verify_cell_kzg_proof_batch(_context,
+                Convert.ToUInt64(commitments.Length), commitmentsPtrs.Pointer,
+                Convert.ToUInt64(cellIndices.Length), cellIndicesPtr,
+                Convert.ToUInt64(cells.Length), cellsPtrs.Pointer,
+                Convert.ToUInt64(proofs.Length), proofsPtrs.Pointer,
+                &verified);
+            ThrowOnError(result);
+        }
+
+        return verified;
+    }
+
+    public KzgProof ComputeBlobKzgProof(Blob blob, KzgCommitment commitment)
+    {
+        byte[] proof = new byte[BytesPerProof];
+
+        using Pinned pinnedBlob = new(blob.Memory, BytesPerBlob, "blob");
+        using Pinned pinnedCommitment = new(commitment.Memory, BytesPerCommitment, "commitment");
+        fixed (byte* proofPtr = proof)
+        {
+            CResult result = eth_kzg_compute_blob_kzg_proof(_context, pinnedBlob.Pointer, pinnedCommitment.Pointer, proofPtr);
+            ThrowOnError(result);
+        }
+
+        return proof;
+    }
+
+    public bool VerifyBlobKzgProof(Blob blob, KzgCommitment commitment, KzgProof proof)
+    {
+        bool verified = false;
+
+        using Pinned pinnedBlob = new(blob.Memory, BytesPerBlob, "blob");
+        using Pinned pinnedCommitment = new(commitment.Memory, BytesPerCommitment, "commitment");
+        using Pinned pinnedProof = new(proof.Memory, BytesPerProof, "proof");
+
+        CResult result = eth_kzg_verify_blob_kzg_proof(_context, pinnedBlob.Pointer, pinnedCommitment.Pointer, pinnedProof.Pointer, &verified);
+        ThrowOnError(result);
+
+        return verified;
+    }
+
+    public bool VerifyBlobKzgProofBatch(ReadOnlySpan<Blob> blobs, ReadOnlySpan<KzgCommitment> commitments, ReadOnlySpan<KzgProof> proofs)
+    {
+        using PinnedPointers blobsPtrs = new(blobs.Length);
+        for (int i = 0; i < blobs.Length; i++)
+        {
+            blobsPtrs.Set(i, blobs[i].Memory, BytesPerBlob, "blob");
+        }
+        using PinnedPointers commitmentsPtrs = new(commitments.Length);
+        for (int i = 0; i < commitments.Length; i++)
+        {
+            commitmentsPtrs.Set(i, commitments[i].Memory, BytesPerCommitment, "commitment");
+        }
+        using PinnedPointers proofsPtrs = new(proofs.Length);
+        for (int i = 0; i < proofs.Length; i++)
+        {
+            proofsPtrs.Set(i, proofs[i].Memory, BytesPerProof, "proof");
+        }
+
+        bool verified = false;
+
+        CResult result = eth_kzg_verify_blob_kzg_proof_batch(_context,
+            Convert.ToUInt64(blobs.Length), blobsPtrs.Pointer,
+            Convert.ToUInt64(commitments.Length), commitmentsPtrs.Pointer,
+            Convert.ToUInt64(proofs.Length), proofsPtrs.Pointer,
+            &verified);
+        ThrowOnError(result);
+
+        return verified;
+    }
+
+    private static Cell[] Cells(byte[] bytes)
+    {
+        Cell[] cells = new Cell[bytes.Length / BytesPerCell];
+        for (int i = 0; i < cells.Length; i++)
+        {
+            cells[i] = new ReadOnlyMemory<byte>(bytes, i * BytesPerCell, BytesPerCell);
+        }
+        return cells;
+    }
+
+    private static KzgProof[] Proofs(byte[] bytes)
+    {
+        KzgProof[] proofs = new KzgProof[bytes.Length / BytesPerProof];
+        for (int i = 0; i < proofs.Length; i++)
+        {
+            proofs[i] = new ReadOnlyMemory<byte>(bytes, i * BytesPerProof, BytesPerProof);
+        }
+        return proofs;
+    }
+
+    // Pins a single input until it is disposed.
+    private ref struct Pinned
+    {
+        private MemoryHandle _handle;
+
+        // The length is checked again, as the default value of the structs wraps no memory.
+        public Pinned(ReadOnlyMemory<byte> memory, int length, string name)
+        {
+            _handle = Length.Check(memory, length, name).Pin();
+        }
+
+        public readonly byte* Pointer => (byte*)_handle.Pointer;
+
+        public void Dispose() => _handle.Dispose();
+    }
+
+    // An array of pointers to pinned memory, rented from ArrayPool, that the memory stays pinned
+    // for and that is returned to the pool when it is disposed.
+    private ref struct PinnedPointers
+    {
+        private readonly nint[] _pointers;
+        private readonly MemoryHandle[] _handles;
+        private readonly int _count;
+        private MemoryHandle _pointersHandle;
+
+        public PinnedPointers(int count)
+        {
+            _count = count;
+            _pointers = ArrayPool<nint>.Shared.Rent(count);
+            _handles = ArrayPool<MemoryHandle>.Shared.Rent(count);
+            Array.Clear(_handles, 0, count);
+            _pointersHandle = _pointers.AsMemory().Pin();
+        }
+
+        // Returns pointers to consecutive segments of an output buffer.
+        public static PinnedPointers Segments(byte[] buffer, int segmentLength)
+        {
+            int count = buffer.Length / segmentLength;
+            PinnedPointers pointers = new(count);
+            for (int i = 0; i < count; i++)
+            {
+                pointers.Set(i, new ReadOnlyMemory<byte>(buffer, i * segmentLength, segmentLength), segmentLength, "output");
+            }
+            return pointers;
+        }
+
+        public readonly void Set(int index, ReadOnlyMemory<byte> memory, int length, string name)
+        {
+            _handles[index] = Length.Check(memory, length, $"{name} at index {index}").Pin();
+            _pointers[index] = (nint)_handles[index].Pointer;
+        }
+
+        public readonly byte** Pointer => (byte**)_pointersHandle.Pointer;
+
+        public void Dispose()
+        {
+            for (int i = 0; i < _count; i++)
+            {
+                _handles[i].Dispose();
+            }
+            _pointersHandle.Dispose();
+            ArrayPool<MemoryHandle>.Shared.Return(_handles, clearArray: true);
+            ArrayPool<nint>.Shared.Return(_pointers);
+        }
+    }
+}
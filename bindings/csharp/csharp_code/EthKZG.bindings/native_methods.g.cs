@@ -14,11 +14,25 @@ namespace EthKZG.Native
     {
         const string __DllName = "c_eth_kzg";
 
+        internal const uint ETH_KZG_ABI_VERSION_MAJOR = 1;
+        internal const uint ETH_KZG_ABI_VERSION_MINOR = 9;
+        internal const nuint ETH_KZG_PRECOMP_COMPRESSED = 256;
+        internal const uint ETH_KZG_FORK_DENEB = 1;
+        internal const uint ETH_KZG_FORK_FULU = 2;
+
 
 
         /// <summary>
         ///  Create a new DASContext and return a pointer to it.
         ///
+        ///  If `use_precomp` is true, the recommended precomputation width is used.
+        ///  See `eth_kzg_das_context_new_with_precomp_width` to choose the width explicitly.
+        ///
+        ///  This uses the embedded mainnet trusted setup. If the library was built with the
+        ///  `no-embedded-setup` feature, this returns a null pointer and
+        ///  `eth_kzg_das_context_new_from_trusted_setup` must be used instead. The environment is
+        ///  never read; see `eth_kzg_das_context_new_from_env` to load the trusted setup from it.
+        ///
         ///  # Memory faults
         ///
         ///  To avoid memory leaks, one should ensure that the pointer is freed after use
@@ -27,6 +41,251 @@ namespace EthKZG.Native
         [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_new", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern DASContext* eth_kzg_das_context_new([MarshalAs(UnmanagedType.U1)] bool use_precomp);
 
+        /// <summary>
+        ///  Create a new DASContext with the given precomputation width and return a pointer to it.
+        ///
+        ///  The width controls the size of the tables precomputed for computing cell proofs.
+        ///  A width of zero disables precomputation, which uses the least memory but is the slowest.
+        ///  Larger widths are faster, but each increment roughly doubles the memory used by the tables.
+        ///  Widths larger than 15 are rejected.
+        ///  A width may be combined with `ETH_KZG_PRECOMP_COMPRESSED` to halve the size of the tables.
+        ///
+        ///  This uses the embedded mainnet trusted setup. If the library was built with the
+        ///  `no-embedded-setup` feature, this returns a null pointer and
+        ///  `eth_kzg_das_context_new_from_trusted_setup` must be used instead. The environment is
+        ///  never read; see `eth_kzg_das_context_new_from_env` to load the trusted setup from it.
+        ///
+        ///  # Memory faults
+        ///
+        ///  To avoid memory leaks, one should ensure that the pointer is freed after use
+        ///  by calling `eth_kzg_das_context_free`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_new_with_precomp_width", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern DASContext* eth_kzg_das_context_new_with_precomp_width(nuint precomp_width);
+
+        /// <summary>
+        ///  Create a new DASContext from a trusted setup supplied by the caller.
+        ///
+        ///  `trusted_setup` holds the contents of a trusted setup file, either in the JSON format
+        ///  used by the consensus specs or in the `trusted_setup.txt` format used by c-kzg-4844.
+        ///  On success, a pointer to the new context is written to `out`.
+        ///
+        ///  An error is returned if no trusted setup was provided, ie `trusted_setup` is null or
+        ///  `trusted_setup_len` is zero, or if the trusted setup could not be parsed.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `trusted_setup` points to a region of memory that is at least `trusted_setup_len` bytes.
+        ///  - The caller must ensure that `out` is a valid pointer.
+        ///
+        ///  # Memory faults
+        ///
+        ///  To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+        ///  by calling `eth_kzg_das_context_free`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_new_from_trusted_setup", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_new_from_trusted_setup(byte* trusted_setup, nuint trusted_setup_len, nuint precomp_width, DASContext** @out);
+
+        /// <summary>
+        ///  Create a new DASContext from the trusted setup file at `path`.
+        ///
+        ///  The file may be in the JSON format used by the consensus specs, the `trusted_setup.txt`
+        ///  format used by c-kzg-4844 or the binary cache format. On success, a pointer to the new
+        ///  context is written to `out`.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `path` is a valid null-terminated string.
+        ///  - The caller must ensure that `out` is a valid pointer.
+        ///
+        ///  # Memory faults
+        ///
+        ///  To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+        ///  by calling `eth_kzg_das_context_free`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_new_from_path", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_new_from_path(byte* path, nuint precomp_width, DASContext** @out);
+
+        /// <summary>
+        ///  Create a new DASContext, loading the trusted setup from the environment.
+        ///
+        ///  The trusted setup is loaded from the file named by the `ETH_KZG_TRUSTED_SETUP`
+        ///  environment variable if it is set, then from the default locations, and otherwise
+        ///  the embedded mainnet trusted setup is used. On success, a pointer to the new context
+        ///  is written to `out`.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `out` is a valid pointer.
+        ///
+        ///  # Memory faults
+        ///
+        ///  To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+        ///  by calling `eth_kzg_das_context_free`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_new_from_env", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_new_from_env(nuint precomp_width, DASContext** @out);
+
+        /// <summary>
+        ///  Run the methods of the DASContext on a dedicated thread pool with `num_threads` threads.
+        ///
+        ///  By default, a DASContext uses a global thread pool with one thread per CPU. Setting
+        ///  `num_threads` to 1 makes the context fully single-threaded, and setting it to 0 creates a
+        ///  dedicated pool with one thread per CPU; the context does not go back to the global pool
+        ///  once this was called. This should be called before the context is shared between threads.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_set_num_threads", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_set_num_threads(DASContext* ctx, nuint num_threads);
+
+        /// <summary>
+        ///  Report every operation of the DASContext to `callback`, so that they can be exported to
+        ///  a metrics system such as Prometheus. Passing a null `callback` removes the current one.
+        ///
+        ///  The callback is called on the thread that called the operation, once it has finished, so it
+        ///  should be cheap and must not block. This should be called before the context is shared
+        ///  between threads.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+        ///  - The caller must ensure that `callback` can be called from any thread with `user_data`, until
+        ///    the callback is removed or the context is freed.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_set_metrics_callback", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_set_metrics_callback(DASContext* ctx, delegate* unmanaged[Cdecl]<void*, uint, ulong, ulong, uint, void> callback, void* user_data);
+
+        /// <summary>
+        ///  Write the number of bytes held by the SRS tables, FK20 precomputations and domains of the DASContext to `out`.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+        ///  - The caller must ensure that `out` points to a writable `MemoryUsage`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_memory_usage", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_memory_usage(DASContext* ctx, MemoryUsage* @out);
+
+        /// <summary>
+        ///  Write the counters of the DASContext to `out`.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+        ///  - The caller must ensure that `out` points to a writable `Stats`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_stats", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_stats(DASContext* ctx, Stats* @out);
+
+        /// <summary>
+        ///  Write the number of times the DASContext performed `operation`, and the number of those
+        ///  that returned an error, to `out_calls` and `out_failures`.
+        ///
+        ///  `operation` uses the numbering of `MetricsCallback`. An error with the `InvalidArgument`
+        ///  code is returned for an unknown operation.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+        ///  - The caller must ensure that `out_calls` and `out_failures` are valid pointers.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_operation_count", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_operation_count(DASContext* ctx, uint operation, ulong* out_calls, ulong* out_failures);
+
+        /// <summary>
+        ///  Run at most `max_in_flight` operations of the DASContext at the same time, across all the
+        ///  threads that call it. Setting `max_in_flight` to 0 removes the limits, which is the default.
+        ///
+        ///  When `max_in_flight` operations are running, up to `max_queued` others wait for one of them
+        ///  to finish, for at most `queue_timeout_millis` milliseconds, or without a time limit if
+        ///  `queue_timeout_millis` is 0. An operation that arrives when the queue is full returns an
+        ///  error with the `Overloaded` code, and one that waits for too long returns an error with
+        ///  the `AdmissionTimedOut` code, so that a burst of requests is turned away instead of
+        ///  slowing down every operation. An error with the `InvalidArgument` code is returned if
+        ///  `max_in_flight` or `max_queued` does not fit in a `size_t`. This should be called before the
+        ///  context is shared between threads.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_set_admission_limits", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_set_admission_limits(DASContext* ctx, ulong max_in_flight, ulong max_queued, ulong queue_timeout_millis);
+
+        /// <summary>
+        ///  Remember the last `capacity` cell openings that `eth_kzg_verify_cell_kzg_proof_batch`
+        ///  verified, so that verifying them again returns without any pairing. Setting `capacity`
+        ///  to 0 disables the cache, which is the default.
+        ///
+        ///  Consensus clients receive the same data column sidecars from several peers and on several
+        ///  topics. Only openings that verified are remembered, so a batch with an opening that did
+        ///  not verify is still rejected. Setting the cache empties it and resets its counters.
+        ///  An error with the `InvalidArgument` code is returned if `capacity` does not fit in a
+        ///  `size_t`. This should be called before the context is shared between threads.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_set_verification_cache", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_set_verification_cache(DASContext* ctx, ulong capacity);
+
+        /// <summary>
+        ///  Write the number of cell openings that were found in the verification cache of the
+        ///  DASContext, and the number that were not, to `out_hits` and `out_misses`.
+        ///
+        ///  Both are 0 if the cache is disabled. See `eth_kzg_das_context_set_verification_cache`.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+        ///  - The caller must ensure that `out_hits` and `out_misses` are valid pointers.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_verification_cache_stats", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_verification_cache_stats(DASContext* ctx, ulong* out_hits, ulong* out_misses);
+
+        /// <summary>
+        ///  Create a new DASContext that serves the methods of every fork in `forks`, loading the
+        ///  trusted setup from the environment like `eth_kzg_das_context_new_from_env`.
+        ///
+        ///  `forks` is a combination of `ETH_KZG_FORK_DENEB` and `ETH_KZG_FORK_FULU`. A single context
+        ///  serves both forks from one copy of the trusted setup, so clients that straddle the fork
+        ///  should create one context with both flags instead of one context per fork. Without
+        ///  `ETH_KZG_FORK_FULU`, the tables that compute cells are skipped and the cell methods that
+        ///  prove return an error with the `VerifierOnlyContext` code, while the cell methods that
+        ///  verify keep working. The blob methods are served by every fork since Deneb, so an error
+        ///  with the `InvalidArgument` code is returned if `forks` does not include `ETH_KZG_FORK_DENEB`
+        ///  or has unknown flags. On success, a pointer to the new context is written to `out`.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `out` is a valid pointer.
+        ///
+        ///  # Memory faults
+        ///
+        ///  To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+        ///  by calling `eth_kzg_das_context_free`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_new_for_forks", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_new_for_forks(uint forks, nuint precomp_width, DASContext** @out);
+
+        /// <summary>
+        ///  Run known-answer tests against the trusted setup and precomputed tables of the DASContext.
+        ///
+        ///  This detects a corrupted setup or precomputation file, or a bad build, and is meant to be
+        ///  called once at startup. It takes about as long as computing the cells and proofs of a few blobs.
+        ///  The known answers are for the mainnet trusted setup.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_self_test", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_context_self_test(DASContext* ctx);
+
         /// <summary>
         ///  # Safety
         ///
@@ -45,6 +304,47 @@ namespace EthKZG.Native
         [DllImport(__DllName, EntryPoint = "eth_kzg_das_context_free", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern void eth_kzg_das_context_free(DASContext* ctx);
 
+        /// <summary>
+        ///  Create the scratch buffers for computing the cells and proofs of a blob with the DASContext.
+        ///
+        ///  On success, a pointer to the new scratch is written to `out`. An error with the
+        ///  `VerifierOnlyContext` code is returned if the context cannot compute cells, for example
+        ///  because it was created by `eth_kzg_das_context_new_for_forks` without `ETH_KZG_FORK_FULU`.
+        ///
+        ///  The scratch can outlive the context, but it can only be used with the context that it was
+        ///  created from.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+        ///  - The caller must ensure that `out` is a valid pointer.
+        ///
+        ///  # Memory faults
+        ///
+        ///  To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+        ///  by calling `eth_kzg_das_scratch_free`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_scratch_new", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_das_scratch_new(DASContext* ctx, DASScratch** @out);
+
+        /// <summary>
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that the pointer is valid. If the pointer is null, this method will return early.
+        ///  - The caller should also avoid a double-free by setting the pointer to null after calling this method.
+        ///
+        ///  # Memory faults
+        ///
+        ///  - If this method is called twice on the same pointer, it will result in a double-free.
+        ///
+        ///  # Undefined behavior
+        ///
+        ///  - Since the `scratch` is created in Rust, we can only get undefined behavior, if the caller passes in
+        ///    a pointer that was not created by `eth_kzg_das_scratch_new`.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_das_scratch_free", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern void eth_kzg_das_scratch_free(DASScratch* scratch);
+
         /// <summary>
         ///  Free the memory allocated for the error message.
         ///
@@ -67,8 +367,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_blob_to_kzg_commitment", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_blob_to_kzg_commitment(DASContext* ctx, byte* blob, byte* @out);
@@ -78,7 +378,7 @@ namespace EthKZG.Native
         ///
         ///  # Safety
         ///
-        ///  - The caller must ensure that the pointers are valid. If pointers are null.
+        ///  - The caller must ensure that the pointers are valid.
         ///  - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
         ///  - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
         ///    and that each element is at least `BYTES_PER_CELL` bytes.
@@ -87,8 +387,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_compute_cells_and_kzg_proofs", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_compute_cells_and_kzg_proofs(DASContext* ctx, byte* blob, byte** out_cells, byte** out_proofs);
@@ -98,19 +398,71 @@ namespace EthKZG.Native
         ///
         ///  # Safety
         ///
-        ///  - The caller must ensure that the pointers are valid. If pointers are null.
+        ///  - The caller must ensure that the pointers are valid.
         ///  - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
         ///  - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
         ///    and that each element is at least `BYTES_PER_CELL` bytes.
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_compute_cells", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_compute_cells(DASContext* ctx, byte* blob, byte** out_cells);
 
+        /// <summary>
+        ///  Computes the cells and KZG proofs for a given blob, like `eth_kzg_compute_cells_and_kzg_proofs`,
+        ///  using the buffers of `scratch` for every intermediate value.
+        ///
+        ///  No large allocations are made, and the context is only read, so several threads can call
+        ///  this with the same context at the same time, as long as each has its own scratch. An error
+        ///  with the `InvalidArgument` code is returned if `scratch` was created from another context.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that the pointers are valid.
+        ///  - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+        ///    used by another thread during the call.
+        ///  - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
+        ///  - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+        ///    and that each element is at least `BYTES_PER_CELL` bytes.
+        ///  - The caller must ensure that `out_proofs` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+        ///    and that each element is at least `BYTES_PER_COMMITMENT` bytes.
+        ///
+        ///  # Undefined behavior
+        ///
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_compute_cells_and_kzg_proofs_with_scratch", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_compute_cells_and_kzg_proofs_with_scratch(DASContext* ctx, DASScratch* scratch, byte* blob, byte** out_cells, byte** out_proofs);
+
+        /// <summary>
+        ///  Computes the cells for a given blob, like `eth_kzg_compute_cells`, using the buffers of
+        ///  `scratch` for every intermediate value.
+        ///
+        ///  No large allocations are made, and the context is only read, so several threads can call
+        ///  this with the same context at the same time, as long as each has its own scratch. An error
+        ///  with the `InvalidArgument` code is returned if `scratch` was created from another context.
+        ///
+        ///  # Safety
+        ///
+        ///  - The caller must ensure that the pointers are valid.
+        ///  - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+        ///    used by another thread during the call.
+        ///  - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
+        ///  - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+        ///    and that each element is at least `BYTES_PER_CELL` bytes.
+        ///
+        ///  # Undefined behavior
+        ///
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_compute_cells_with_scratch", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_compute_cells_with_scratch(DASContext* ctx, DASScratch* scratch, byte* blob, byte** out_cells);
+
         /// <summary>
         ///  Verifies a batch of cells and their KZG proofs.
         ///
@@ -135,8 +487,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_verify_cell_kzg_proof_batch", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_verify_cell_kzg_proof_batch(DASContext* ctx, ulong commitments_length, byte** commitments, ulong cell_indices_length, ulong* cell_indices, ulong cells_length, byte** cells, ulong proofs_length, byte** proofs, bool* verified);
@@ -161,12 +513,70 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_recover_cells_and_kzg_proofs", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_recover_cells_and_kzg_proofs(DASContext* ctx, ulong cells_length, byte** cells, ulong cell_indices_length, ulong* cell_indices, byte** out_cells, byte** out_proofs);
+
+        /// <summary>
+        ///  Recovers all cells and their KZG proofs from the given cell indices and cells, like
+        ///  `eth_kzg_recover_cells_and_kzg_proofs`, using the buffers of `scratch` for the proofs.
+        ///
+        ///  The context is only read, so several threads can call this with the same context at the
+        ///  same time, as long as each has its own scratch. The erasure decoding still allocates its
+        ///  own buffers, which are about as large as an extended blob. An error with the
+        ///  `InvalidArgument` code is returned if `scratch` was created from another context.
+        ///
+        ///  # Safety
+        ///
+        ///   - If the length parameter for a pointer is set to zero, then this implementation will not check if its pointer is
+        ///     null. This is because the caller might have passed in a null pointer, if the length is zero. Instead an empty slice will be created.
+        ///
+        ///  - The caller must ensure that the pointers are valid.
+        ///  - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+        ///    used by another thread during the call.
+        ///  - The caller must ensure that `cells` points to a region of memory that is at least `cells_length` cells
+        ///    and that each cell is at least `BYTES_PER_CELL` bytes.
+        ///  - The caller must ensure that `cell_indices` points to a region of memory that is at least `cell_indices_length` cell indices
+        ///    and that each cell id is 8 bytes.
+        ///  - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` cells
+        ///    and that each cell is at least `BYTES_PER_CELL` bytes.
+        ///  - The caller must ensure that `out_proofs` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` proofs
+        ///    and that each proof is at least `BYTES_PER_COMMITMENT` bytes.
+        ///
+        ///  # Undefined behavior
+        ///
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_recover_cells_and_kzg_proofs_with_scratch", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_recover_cells_and_kzg_proofs_with_scratch(DASContext* ctx, DASScratch* scratch, ulong cells_length, byte** cells, ulong cell_indices_length, ulong* cell_indices, byte** out_cells, byte** out_proofs);
+
+        /// <summary>
+        ///  Recovers all cells and their KZG proofs from the given cell indices and cells
+        ///
+        ///  Deprecated: this is the same as `eth_kzg_recover_cells_and_kzg_proofs`, which matches
+        ///  the name used by the consensus specs.
+        ///
+        ///  # Safety
+        ///
+        ///  - See `eth_kzg_recover_cells_and_kzg_proofs`.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_recover_cells_and_proofs", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_recover_cells_and_proofs(DASContext* ctx, ulong cells_length, byte** cells, ulong cell_indices_length, ulong* cell_indices, byte** out_cells, byte** out_proofs);
 
+        /// <summary>
+        ///  Check that this library implements the version of the C ABI that the caller was built against.
+        ///
+        ///  Bindings should call this once when they are loaded, with the `ETH_KZG_ABI_VERSION_MAJOR`
+        ///  and `ETH_KZG_ABI_VERSION_MINOR` of the header they were generated from. An error with the
+        ///  `AbiVersionMismatch` code is returned if the major versions differ, or if the library is
+        ///  older than the caller, ie it may be missing some of the symbols the caller uses.
+        /// </summary>
+        [DllImport(__DllName, EntryPoint = "eth_kzg_abi_negotiate", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
+        internal static extern CResult eth_kzg_abi_negotiate(uint major, uint minor);
+
         [DllImport(__DllName, EntryPoint = "eth_kzg_constant_bytes_per_cell", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern ulong eth_kzg_constant_bytes_per_cell();
 
@@ -189,8 +599,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_compute_kzg_proof", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_compute_kzg_proof(DASContext* ctx, byte* blob, byte* z, byte* out_proof, byte* out_y);
@@ -207,8 +617,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_compute_blob_kzg_proof", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_compute_blob_kzg_proof(DASContext* ctx, byte* blob, byte* commitment, byte* out_proof);
@@ -227,8 +637,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_verify_kzg_proof", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_verify_kzg_proof(DASContext* ctx, byte* commitment, byte* z, byte* y, byte* proof, bool* verified);
@@ -246,8 +656,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_verify_blob_kzg_proof", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_verify_blob_kzg_proof(DASContext* ctx, byte* blob, byte* commitment, byte* proof, bool* verified);
@@ -272,8 +682,8 @@ namespace EthKZG.Native
         ///
         ///  # Undefined behavior
         ///
-        ///  - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-        ///    If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+        ///  - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+        ///    If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
         /// </summary>
         [DllImport(__DllName, EntryPoint = "eth_kzg_verify_blob_kzg_proof_batch", CallingConvention = CallingConvention.Cdecl, ExactSpelling = true)]
         internal static extern CResult eth_kzg_verify_blob_kzg_proof_batch(DASContext* ctx, ulong blobs_length, byte** blobs, ulong commitments_length, byte** commitments, ulong proofs_length, byte** proofs, bool* verified);
@@ -286,11 +696,36 @@ namespace EthKZG.Native
     {
     }
 
+    [StructLayout(LayoutKind.Sequential)]
+    internal unsafe partial struct DASScratch
+    {
+    }
+
+    [StructLayout(LayoutKind.Sequential)]
+    internal unsafe partial struct MemoryUsage
+    {
+        public ulong srs;
+        public ulong fk20_precomputations;
+        public ulong domains;
+        public ulong total;
+    }
+
+    [StructLayout(LayoutKind.Sequential)]
+    internal unsafe partial struct Stats
+    {
+        public ulong operations;
+        public ulong failed_operations;
+        public ulong precomputation_bytes;
+        public ulong memory_bytes;
+        public ulong scratch_bytes;
+    }
+
     [StructLayout(LayoutKind.Sequential)]
     internal unsafe partial struct CResult
     {
         public CResultStatus status;
         public byte* error_msg;
+        public uint error_code;
     }
 
 
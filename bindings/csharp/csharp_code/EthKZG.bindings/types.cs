@@ -0,0 +1,95 @@
+namespace EthKZG;
+
+// Blob, Cell, KzgCommitment and KzgProof wrap memory that is checked to have the right length
+// when they are created, so a method that takes them cannot be called with the arguments in the
+// wrong order. The methods that take them only check their length again because the default
+// value of each struct wraps no memory. They do not copy the memory they wrap, so the caller must
+// not change it while they are in use.
+
+/// <summary>A blob of <see cref="EthKZG.BytesPerBlob"/> bytes.</summary>
+public readonly struct Blob
+{
+    public ReadOnlyMemory<byte> Memory { get; }
+
+    public Blob(ReadOnlyMemory<byte> memory)
+    {
+        Memory = Length.Check(memory, EthKZG.BytesPerBlob, "blob");
+    }
+
+    public ReadOnlySpan<byte> Span => Memory.Span;
+
+    public byte[] ToArray() => Memory.ToArray();
+
+    public static implicit operator Blob(byte[] bytes) => new(bytes);
+
+    public static implicit operator Blob(ReadOnlyMemory<byte> memory) => new(memory);
+}
+
+/// <summary>A cell of <see cref="EthKZG.BytesPerCell"/> bytes.</summary>
+public readonly struct Cell
+{
+    public ReadOnlyMemory<byte> Memory { get; }
+
+    public Cell(ReadOnlyMemory<byte> memory)
+    {
+        Memory = Length.Check(memory, EthKZG.BytesPerCell, "cell");
+    }
+
+    public ReadOnlySpan<byte> Span => Memory.Span;
+
+    public byte[] ToArray() => Memory.ToArray();
+
+    public static implicit operator Cell(byte[] bytes) => new(bytes);
+
+    public static implicit operator Cell(ReadOnlyMemory<byte> memory) => new(memory);
+}
+
+/// <summary>A KZG commitment of <see cref="EthKZG.BytesPerCommitment"/> bytes.</summary>
+public readonly struct KzgCommitment
+{
+    public ReadOnlyMemory<byte> Memory { get; }
+
+    public KzgCommitment(ReadOnlyMemory<byte> memory)
+    {
+        Memory = Length.Check(memory, EthKZG.BytesPerCommitment, "commitment");
+    }
+
+    public ReadOnlySpan<byte> Span => Memory.Span;
+
+    public byte[] ToArray() => Memory.ToArray();
+
+    public static implicit operator KzgCommitment(byte[] bytes) => new(bytes);
+
+    public static implicit operator KzgCommitment(ReadOnlyMemory<byte> memory) => new(memory);
+}
+
+/// <summary>A KZG proof of <see cref="EthKZG.BytesPerProof"/> bytes.</summary>
+public readonly struct KzgProof
+{
+    public ReadOnlyMemory<byte> Memory { get; }
+
+    public KzgProof(ReadOnlyMemory<byte> memory)
+    {
+        Memory = Length.Check(memory, EthKZG.BytesPerProof, "proof");
+    }
+
+    public ReadOnlySpan<byte> Span => Memory.Span;
+
+    public byte[] ToArray() => Memory.ToArray();
+
+    public static implicit operator KzgProof(byte[] bytes) => new(bytes);
+
+    public static implicit operator KzgProof(ReadOnlyMemory<byte> memory) => new(memory);
+}
+
+internal static class Length
+{
+    public static ReadOnlyMemory<byte> Check(ReadOnlyMemory<byte> memory, int expected, string name)
+    {
+        if (memory.Length != expected)
+        {
+            throw new EthKZGException(ErrorCode.InvalidArgument, $"{name} has an invalid length. Expected {expected}, got {memory.Length}");
+        }
+        return memory;
+    }
+}
@@ -54,6 +54,22 @@ public class ReferenceTests
 
     #endregion
 
+    #region Errors
+
+    [TestCase]
+    public void TestErrorsCarryTheirCode()
+    {
+        byte[] blob = new byte[EthKZG.BytesPerBlob];
+        // A field element that is larger than the modulus.
+        Array.Fill(blob, (byte)0xff, 0, EthKZG.BytesPerFieldElement);
+
+        EthKZGException ex = Assert.Throws<EthKZGException>(() => _context.BlobToKzgCommitment(blob));
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.NonCanonicalScalar));
+        Assert.That(ex.Message, Does.Contain("E101 NonCanonicalScalar"));
+    }
+
+    #endregion
+
     #region BlobToKzgCommitment
 
     private class BlobToKzgCommitmentInput
@@ -501,4 +517,4 @@ public class ReferenceTests
     #endregion
 
     #endregion
-}
\ No newline at end of file
+}
\ No newline at end of file
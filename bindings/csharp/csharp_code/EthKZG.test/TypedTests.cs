@@ -0,0 +1,103 @@
+namespace EthKZG.test;
+
+[TestFixture]
+public class TypedTests
+{
+    [OneTimeSetUp]
+    public void Setup()
+    {
+        _context = new EthKZG();
+
+        _blob = new byte[EthKZG.BytesPerBlob];
+        // Every field element is its index, which is less than the modulus.
+        for (int i = 0; i < EthKZG.BytesPerBlob / EthKZG.BytesPerFieldElement; i++)
+        {
+            _blob[(i + 1) * EthKZG.BytesPerFieldElement - 2] = (byte)(i >> 8);
+            _blob[(i + 1) * EthKZG.BytesPerFieldElement - 1] = (byte)i;
+        }
+    }
+
+    [OneTimeTearDown]
+    public void Teardown()
+    {
+        _context.Dispose();
+    }
+
+    private EthKZG _context;
+    private byte[] _blob;
+
+    private static byte[][] GetByteArrays(Memory<byte>[] arrays) => [.. arrays.Select((memory) => memory.ToArray())];
+
+    private static byte[][] GetByteArrays(Cell[] cells) => [.. cells.Select((cell) => cell.ToArray())];
+
+    private static byte[][] GetByteArrays(KzgProof[] proofs) => [.. proofs.Select((proof) => proof.ToArray())];
+
+    [TestCase]
+    public void TestTypedMethodsMatchByteArrayMethods()
+    {
+        Blob blob = _blob;
+
+        KzgCommitment commitment = _context.BlobToKzgCommitment(blob);
+        Assert.That(commitment.ToArray(), Is.EqualTo(_context.BlobToKzgCommitment(_blob)));
+
+        (Cell[] cells, KzgProof[] proofs) = _context.ComputeCellsAndKZGProofs(blob);
+        (Memory<byte>[] expectedCells, Memory<byte>[] expectedProofs) = _context.ComputeCellsAndKZGProofs(_blob);
+        Assert.That(GetByteArrays(cells), Is.EqualTo(GetByteArrays(expectedCells)));
+        Assert.That(GetByteArrays(proofs), Is.EqualTo(GetByteArrays(expectedProofs)));
+        Assert.That(GetByteArrays(_context.ComputeCells(blob)), Is.EqualTo(GetByteArrays(expectedCells)));
+
+        KzgProof proof = _context.ComputeBlobKzgProof(blob, commitment);
+        Assert.That(proof.ToArray(), Is.EqualTo(_context.ComputeBlobKzgProof(_blob, commitment.ToArray())));
+        Assert.That(_context.VerifyBlobKzgProof(blob, commitment, proof), Is.True);
+    }
+
+    [TestCase]
+    public void TestTypedBatchMethods()
+    {
+        Blob blob = _blob;
+        KzgCommitment commitment = _context.BlobToKzgCommitment(blob);
+        KzgProof proof = _context.ComputeBlobKzgProof(blob, commitment);
+        (Cell[] cells, KzgProof[] proofs) = _context.ComputeCellsAndKZGProofs(blob);
+
+        ulong[] cellIndices = [.. Enumerable.Range(0, cells.Length).Select((i) => (ulong)i)];
+        KzgCommitment[] commitments = [.. Enumerable.Repeat(commitment, cells.Length)];
+        Assert.That(_context.VerifyCellKZGProofBatch(commitments, cellIndices, cells, proofs), Is.True);
+        // A proof for another cell does not verify.
+        Assert.That(_context.VerifyCellKZGProofBatch(commitments[..1], cellIndices[..1], cells[..1], proofs[1..2]), Is.False);
+
+        ulong[] evenIndices = [.. cellIndices.Where((i) => i % 2 == 0)];
+        Cell[] evenCells = [.. evenIndices.Select((i) => cells[i])];
+        (Cell[] recoveredCells, KzgProof[] recoveredProofs) = _context.RecoverCellsAndKZGProofs(evenIndices, evenCells);
+        Assert.That(GetByteArrays(recoveredCells), Is.EqualTo(GetByteArrays(cells)));
+        Assert.That(GetByteArrays(recoveredProofs), Is.EqualTo(GetByteArrays(proofs)));
+
+        Assert.That(_context.VerifyBlobKzgProofBatch([blob, blob], [commitment, commitment], [proof, proof]), Is.True);
+    }
+
+    [TestCase]
+    public void TestWrongLengthIsRejected()
+    {
+        EthKZGException ex = Assert.Throws<EthKZGException>(() => { Blob _ = new byte[EthKZG.BytesPerBlob - 1]; });
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.InvalidArgument));
+
+        ex = Assert.Throws<EthKZGException>(() => { Cell _ = new byte[EthKZG.BytesPerCell + 1]; });
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.InvalidArgument));
+
+        ex = Assert.Throws<EthKZGException>(() => { KzgCommitment _ = new byte[0]; });
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.InvalidArgument));
+
+        ex = Assert.Throws<EthKZGException>(() => { KzgProof _ = new byte[EthKZG.BytesPerCommitment * 2]; });
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.InvalidArgument));
+    }
+
+    [TestCase]
+    public void TestDefaultValuesAreRejected()
+    {
+        EthKZGException ex = Assert.Throws<EthKZGException>(() => _context.BlobToKzgCommitment(default(Blob)));
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.InvalidArgument));
+
+        KzgCommitment commitment = _context.BlobToKzgCommitment((Blob)_blob);
+        ex = Assert.Throws<EthKZGException>(() => _context.VerifyBlobKzgProof(_blob, commitment, default));
+        Assert.That(ex.Code, Is.EqualTo(ErrorCode.InvalidArgument));
+    }
+}
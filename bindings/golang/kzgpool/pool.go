@@ -0,0 +1,152 @@
+// Package kzgpool proves blobs on a bounded pool of workers that share one DASContext.
+//
+// Every call into the library holds an OS thread for as long as it runs, so a client that
+// starts a goroutine per blob ends up with as many threads blocked in cgo as it has blobs.
+// A Pool caps that at its number of workers, and its job queue blocks senders once it is
+// full, which pushes back on the producer instead of buffering blobs without limit.
+//
+// The library already proves a single blob on several threads. When the pool has one worker
+// per CPU, call SetNumThreads(1) on the context so the two do not oversubscribe the CPUs.
+package kzgpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	eth_kzg "github.com/crate-crypto/rust-eth-kzg"
+)
+
+// ErrClosed is returned by Submit after Close has been called.
+var ErrClosed = errors.New("kzgpool: pool is closed")
+
+// Job is a blob to prove, with an ID that is handed back with its result.
+type Job struct {
+	ID   uint64
+	Blob []byte
+}
+
+// Result holds the cells and cell proofs of the blob of a job, or the error that proving
+// it returned.
+type Result struct {
+	ID     uint64
+	Cells  [][]byte
+	Proofs [][]byte
+	Err    error
+}
+
+// Pool runs jobs on a fixed number of workers. Results are sent in the order in which the
+// jobs finish, not the order in which they were submitted.
+type Pool struct {
+	ctx     *eth_kzg.DASContext
+	jobs    chan Job
+	results chan Result
+	workers sync.WaitGroup
+
+	// done is closed by Close, which wakes up the Submit calls that are blocked on a full
+	// queue. The jobs channel is only closed once none of them can send on it anymore.
+	done       chan struct{}
+	submitting sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New starts a pool of workers that prove blobs with ctx. Up to queueSize jobs wait for a
+// worker before Submit blocks, and up to queueSize results wait to be received before the
+// workers block.
+func New(ctx *eth_kzg.DASContext, workers int, queueSize int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	pool := &Pool{
+		ctx:     ctx,
+		jobs:    make(chan Job, queueSize),
+		results: make(chan Result, queueSize),
+		done:    make(chan struct{}),
+	}
+	pool.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	go func() {
+		pool.workers.Wait()
+		close(pool.results)
+	}()
+	return pool
+}
+
+func (pool *Pool) work() {
+	defer pool.workers.Done()
+	for job := range pool.jobs {
+		cells, proofs, err := pool.ctx.ComputeCellsAndKZGProofs(job.Blob)
+		pool.results <- Result{ID: job.ID, Cells: cells, Proofs: proofs, Err: err}
+	}
+}
+
+// Submit queues a job, blocking while the queue is full. It returns the error of ctx if ctx
+// is done first, and ErrClosed if the pool is closed before the job could be queued.
+func (pool *Pool) Submit(ctx context.Context, job Job) error {
+	pool.mu.Lock()
+	if pool.closed {
+		pool.mu.Unlock()
+		return ErrClosed
+	}
+	pool.submitting.Add(1)
+	pool.mu.Unlock()
+	defer pool.submitting.Done()
+
+	select {
+	case pool.jobs <- job:
+		return nil
+	case <-pool.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Results returns the channel that the result of every job is sent on. It is closed once
+// the pool has been closed and every queued job has finished.
+func (pool *Pool) Results() <-chan Result {
+	return pool.results
+}
+
+// Close stops the pool from accepting jobs. The Submit calls that are blocked on a full
+// queue return ErrClosed. The jobs that were already queued still run, so the results
+// channel must be drained for the workers to exit.
+func (pool *Pool) Close() {
+	pool.mu.Lock()
+	if pool.closed {
+		pool.mu.Unlock()
+		return
+	}
+	pool.closed = true
+	close(pool.done)
+	pool.mu.Unlock()
+
+	pool.submitting.Wait()
+	close(pool.jobs)
+}
+
+// ProveAll proves every blob on a pool with the given number of workers, and returns their
+// results in the order of the blobs.
+func ProveAll(ctx *eth_kzg.DASContext, workers int, blobs [][]byte) []Result {
+	pool := New(ctx, workers, workers)
+	go func() {
+		defer pool.Close()
+		for i, blob := range blobs {
+			// The pool is only closed by this goroutine, and the context is never done.
+			_ = pool.Submit(context.Background(), Job{ID: uint64(i), Blob: blob})
+		}
+	}()
+
+	results := make([]Result, len(blobs))
+	for result := range pool.Results() {
+		results[result.ID] = result
+	}
+	return results
+}
@@ -0,0 +1,73 @@
+package kzgpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	eth_kzg "github.com/crate-crypto/rust-eth-kzg"
+)
+
+func TestProveAllMatchesDirectCalls(t *testing.T) {
+	ctx := eth_kzg.NewProverContext()
+	blobs := make([][]byte, 4)
+	for i := range blobs {
+		blobs[i] = make([]byte, eth_kzg.BytesPerBlob)
+		blobs[i][31] = byte(i + 1)
+	}
+	// A blob of the wrong size fails on its own without failing the others
+	blobs = append(blobs, make([]byte, 1))
+
+	results := ProveAll(ctx, 2, blobs)
+	for i, result := range results[:4] {
+		if result.Err != nil {
+			t.Fatalf("blob %d: %v", i, result.Err)
+		}
+		cells, proofs, err := ctx.ComputeCellsAndKZGProofs(blobs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(result.Cells[7], cells[7]) || !bytes.Equal(result.Proofs[7], proofs[7]) {
+			t.Fatalf("blob %d: the pool returned different cells or proofs", i)
+		}
+	}
+	if results[4].Err == nil {
+		t.Fatal("expected an error for a blob of the wrong size")
+	}
+}
+
+func TestSubmitAfterClose(t *testing.T) {
+	pool := New(eth_kzg.NewProverContext(), 1, 0)
+	pool.Close()
+	if err := pool.Submit(context.Background(), Job{}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, ok := <-pool.Results(); ok {
+		t.Fatal("expected the results channel to be closed")
+	}
+}
+
+func TestCloseWakesUpBlockedSubmit(t *testing.T) {
+	pool := New(eth_kzg.NewProverContext(), 1, 0)
+	blob := make([]byte, eth_kzg.BytesPerBlob)
+
+	// Nobody receives the results, so the worker blocks on the result of the first job,
+	// and the queue has no room: the second Submit blocks until the pool is closed.
+	if err := pool.Submit(context.Background(), Job{ID: 0, Blob: blob}); err != nil {
+		t.Fatal(err)
+	}
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.Submit(context.Background(), Job{ID: 1, Blob: blob})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	pool.Close()
+	if err := <-submitted; !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	for range pool.Results() {
+	}
+}
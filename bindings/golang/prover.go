@@ -6,12 +6,14 @@ package eth_kzg
 #cgo linux,amd64 LDFLAGS: ./build/x86_64-unknown-linux-gnu/libc_eth_kzg.a -lm
 #cgo linux,arm64 LDFLAGS: ./build/aarch64-unknown-linux-gnu/libc_eth_kzg.a -lm
 #cgo windows LDFLAGS: ./build/x86_64-pc-windows-gnu/libc_eth_kzg.a -lws2_32 -lntdll -luserenv
+#include <stdlib.h>
 #include "./build/c_eth_kzg.h"
 */
 import "C"
 import (
 	"errors"
 	"runtime"
+	"unsafe"
 )
 
 /*
@@ -41,6 +43,26 @@ const (
 	BytesPerCell = 2048
 )
 
+// Fail when the package is loaded if the library implements another version of the C ABI,
+// rather than on the first call to a symbol that is missing or has changed.
+func init() {
+	result := C.eth_kzg_abi_negotiate(C.ETH_KZG_ABI_VERSION_MAJOR, C.ETH_KZG_ABI_VERSION_MINOR)
+	if err := resultError(result); err != nil {
+		panic(err.Error())
+	}
+}
+
+// resultError returns nil if result is Ok, and otherwise an error with its message, which it
+// frees.
+func resultError(result C.CResult) error {
+	if result.status == C.Ok {
+		return nil
+	}
+	msg := C.GoString(result.error_msg)
+	C.eth_kzg_free_error_message(result.error_msg)
+	return errors.New(msg)
+}
+
 type DASContext struct {
 	_inner *C.DASContext
 }
@@ -55,6 +77,13 @@ func NewProverContext() *DASContext {
 	return self
 }
 
+// SetNumThreads runs the context on a dedicated thread pool with numThreads threads,
+// instead of one thread per CPU. A value of 1 makes the context fully single-threaded.
+func (prover *DASContext) SetNumThreads(numThreads uint) error {
+	result := C.eth_kzg_das_context_set_num_threads(prover.inner(), C.uintptr_t(numThreads))
+	return resultError(result)
+}
+
 func (prover *DASContext) BlobToKZGCommitment(blob []byte) ([]byte, error) {
 	if len(blob) != BytesPerBlob {
 		return nil, errors.New("invalid blob size")
@@ -64,6 +93,108 @@ func (prover *DASContext) BlobToKZGCommitment(blob []byte) ([]byte, error) {
 	return out, nil
 }
 
+// ComputeCellsAndKZGProofs extends a blob into its MaxNumColumns cells and computes the
+// proof of each cell.
+func (prover *DASContext) ComputeCellsAndKZGProofs(blob []byte) ([][]byte, [][]byte, error) {
+	if len(blob) != BytesPerBlob {
+		return nil, nil, errors.New("invalid blob size")
+	}
+
+	// The C side writes through arrays of pointers, which cgo does not allow to point into
+	// Go memory, so the outputs are written to C memory and copied out.
+	cellsBuf := C.malloc(C.size_t(MaxNumColumns * BytesPerCell))
+	defer C.free(cellsBuf)
+	proofsBuf := C.malloc(C.size_t(MaxNumColumns * BytesPerProof))
+	defer C.free(proofsBuf)
+	cellPtrs := (*[MaxNumColumns]*C.uint8_t)(C.malloc(C.size_t(MaxNumColumns) * C.size_t(unsafe.Sizeof(uintptr(0)))))
+	defer C.free(unsafe.Pointer(cellPtrs))
+	proofPtrs := (*[MaxNumColumns]*C.uint8_t)(C.malloc(C.size_t(MaxNumColumns) * C.size_t(unsafe.Sizeof(uintptr(0)))))
+	defer C.free(unsafe.Pointer(proofPtrs))
+	for i := 0; i < MaxNumColumns; i++ {
+		cellPtrs[i] = (*C.uint8_t)(unsafe.Add(cellsBuf, i*BytesPerCell))
+		proofPtrs[i] = (*C.uint8_t)(unsafe.Add(proofsBuf, i*BytesPerProof))
+	}
+
+	result := C.eth_kzg_compute_cells_and_kzg_proofs(prover.inner(), (*C.uint8_t)(&blob[0]), &cellPtrs[0], &proofPtrs[0])
+	runtime.KeepAlive(prover)
+	if err := resultError(result); err != nil {
+		return nil, nil, err
+	}
+
+	cells := make([][]byte, MaxNumColumns)
+	proofs := make([][]byte, MaxNumColumns)
+	for i := range cells {
+		cells[i] = C.GoBytes(unsafe.Pointer(cellPtrs[i]), BytesPerCell)
+		proofs[i] = C.GoBytes(unsafe.Pointer(proofPtrs[i]), BytesPerProof)
+	}
+	return cells, proofs, nil
+}
+
+// operationNames are the names of the operations that a context counts, in the order of the
+// operation numbers of the C API, which start at 1.
+var operationNames = [...]string{
+	"blob_to_kzg_commitment",
+	"compute_cells",
+	"compute_cells_and_kzg_proofs",
+	"compute_cells_and_kzg_proofs_batch",
+	"process_slot",
+	"compute_cell_and_kzg_proof",
+	"recover_cells_and_kzg_proofs",
+	"verify_cell_kzg_proof_batch",
+	"compute_kzg_proof",
+	"compute_blob_kzg_proof",
+	"verify_kzg_proof",
+	"verify_blob_kzg_proof",
+	"verify_blob_kzg_proof_batch",
+}
+
+// OperationStats counts the calls to one operation of a context.
+type OperationStats struct {
+	Calls uint64
+	// Failures is the number of calls that returned an error, including proofs that did not
+	// verify.
+	Failures uint64
+}
+
+// Stats holds the counters of a context, for exporting to a metrics system such as Prometheus.
+type Stats struct {
+	// Operations is keyed by the name of the method, ie "verify_cell_kzg_proof_batch",
+	// which is suitable as a metric label.
+	Operations map[string]OperationStats
+	// PrecomputationBytes is the size of the tables precomputed to speed up proving.
+	PrecomputationBytes uint64
+	// MemoryBytes is the size of every table held by the context.
+	MemoryBytes uint64
+	// ScratchBytes is the size of the scratch buffers created from the context that are
+	// still alive.
+	ScratchBytes uint64
+}
+
+// Stats returns the counters of the context, which start at zero when it is created.
+func (prover *DASContext) Stats() (Stats, error) {
+	var totals C.Stats
+	if err := resultError(C.eth_kzg_das_context_stats(prover.inner(), &totals)); err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{
+		Operations:          make(map[string]OperationStats, len(operationNames)),
+		PrecomputationBytes: uint64(totals.precomputation_bytes),
+		MemoryBytes:         uint64(totals.memory_bytes),
+		ScratchBytes:        uint64(totals.scratch_bytes),
+	}
+	for i, name := range operationNames {
+		var calls, failures C.uint64_t
+		result := C.eth_kzg_das_context_operation_count(prover.inner(), C.uint32_t(i+1), &calls, &failures)
+		if err := resultError(result); err != nil {
+			return Stats{}, err
+		}
+		stats.Operations[name] = OperationStats{Calls: uint64(calls), Failures: uint64(failures)}
+	}
+	runtime.KeepAlive(prover)
+	return stats, nil
+}
+
 func (prover *DASContext) inner() *C.DASContext {
 	return prover._inner
 }
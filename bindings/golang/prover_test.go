@@ -13,3 +13,22 @@ func TestBridgeNewProverCtx(t *testing.T) {
 	_ = comm
 	_ = err
 }
+
+func TestStatsCountOperations(t *testing.T) {
+	ctx := NewProverContext()
+	blob := make([]byte, BytesPerBlob)
+	if _, _, err := ctx.ComputeCellsAndKZGProofs(blob); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := ctx.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stats.Operations["compute_cells_and_kzg_proofs"]; got != (OperationStats{Calls: 1}) {
+		t.Fatalf("unexpected counters for compute_cells_and_kzg_proofs: %+v", got)
+	}
+	if stats.MemoryBytes < stats.PrecomputationBytes || stats.MemoryBytes == 0 {
+		t.Fatalf("unexpected memory usage: %+v", stats)
+	}
+}
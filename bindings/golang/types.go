@@ -0,0 +1,205 @@
+package eth_kzg
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Blob, Cell, KZGCommitment and KZGProof have the layouts of the consensus types of the same
+// name, so they convert to and from the types of go-eth2-client and Prysm without copying
+// through a []byte. They encode to 0x-prefixed hex as text, as in the beacon API, and to
+// their bytes as SSZ, with the methods of the fastssz Marshaler and Unmarshaler interfaces.
+//
+// A Blob is 128 KiB, so its methods have pointer receivers, which keeps a call from copying
+// it. String and MarshalText are the exception: like those of the other types, they have
+// value receivers, so that fmt and encoding/json find them on a Blob that is held by value
+// and is not addressable. They only encode the copy in their receiver, through encodeText.
+type (
+	Blob          [BytesPerBlob]byte
+	Cell          [BytesPerCell]byte
+	KZGCommitment [BytesPerCommitment]byte
+	KZGProof      [BytesPerProof]byte
+)
+
+// encodeHex returns the 0x-prefixed hex encoding of b.
+func encodeHex(b []byte) []byte {
+	out := make([]byte, 2+hex.EncodedLen(len(b)))
+	copy(out, "0x")
+	hex.Encode(out[2:], b)
+	return out
+}
+
+// decodeHex decodes 0x-prefixed hex into dst, which it must fill exactly.
+func decodeHex(dst []byte, text []byte, name string) error {
+	if len(text) < 2 || text[0] != '0' || (text[1] != 'x' && text[1] != 'X') {
+		return fmt.Errorf("%s: hex string must start with 0x", name)
+	}
+	text = text[2:]
+	if len(text) != hex.EncodedLen(len(dst)) {
+		return fmt.Errorf("%s: expected %d hex characters, got %d", name, hex.EncodedLen(len(dst)), len(text))
+	}
+	if _, err := hex.Decode(dst, text); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// decodeSSZ copies buf into dst, which it must fill exactly.
+func decodeSSZ(dst []byte, buf []byte, name string) error {
+	if len(buf) != len(dst) {
+		return fmt.Errorf("%s: expected %d bytes of SSZ, got %d", name, len(dst), len(buf))
+	}
+	copy(dst, buf)
+	return nil
+}
+
+// encodeText returns the 0x-prefixed hex encoding of the blob.
+func (b *Blob) encodeText() []byte { return encodeHex(b[:]) }
+
+func (b Blob) String() string                           { return string(b.encodeText()) }
+func (b Blob) MarshalText() ([]byte, error)             { return b.encodeText(), nil }
+func (b *Blob) UnmarshalText(text []byte) error         { return decodeHex(b[:], text, "blob") }
+func (b *Blob) SizeSSZ() int                            { return BytesPerBlob }
+func (b *Blob) MarshalSSZ() ([]byte, error)             { return append([]byte{}, b[:]...), nil }
+func (b *Blob) MarshalSSZTo(dst []byte) ([]byte, error) { return append(dst, b[:]...), nil }
+func (b *Blob) UnmarshalSSZ(buf []byte) error           { return decodeSSZ(b[:], buf, "blob") }
+
+func (c Cell) String() string                           { return string(encodeHex(c[:])) }
+func (c Cell) MarshalText() ([]byte, error)             { return encodeHex(c[:]), nil }
+func (c *Cell) UnmarshalText(text []byte) error         { return decodeHex(c[:], text, "cell") }
+func (c *Cell) SizeSSZ() int                            { return BytesPerCell }
+func (c *Cell) MarshalSSZ() ([]byte, error)             { return append([]byte{}, c[:]...), nil }
+func (c *Cell) MarshalSSZTo(dst []byte) ([]byte, error) { return append(dst, c[:]...), nil }
+func (c *Cell) UnmarshalSSZ(buf []byte) error           { return decodeSSZ(c[:], buf, "cell") }
+
+func (c KZGCommitment) String() string               { return string(encodeHex(c[:])) }
+func (c KZGCommitment) MarshalText() ([]byte, error) { return encodeHex(c[:]), nil }
+func (c *KZGCommitment) UnmarshalText(text []byte) error {
+	return decodeHex(c[:], text, "commitment")
+}
+func (c *KZGCommitment) SizeSSZ() int                { return BytesPerCommitment }
+func (c *KZGCommitment) MarshalSSZ() ([]byte, error) { return append([]byte{}, c[:]...), nil }
+func (c *KZGCommitment) MarshalSSZTo(dst []byte) ([]byte, error) {
+	return append(dst, c[:]...), nil
+}
+func (c *KZGCommitment) UnmarshalSSZ(buf []byte) error { return decodeSSZ(c[:], buf, "commitment") }
+
+func (p KZGProof) String() string                           { return string(encodeHex(p[:])) }
+func (p KZGProof) MarshalText() ([]byte, error)             { return encodeHex(p[:]), nil }
+func (p *KZGProof) UnmarshalText(text []byte) error         { return decodeHex(p[:], text, "proof") }
+func (p *KZGProof) SizeSSZ() int                            { return BytesPerProof }
+func (p *KZGProof) MarshalSSZ() ([]byte, error)             { return append([]byte{}, p[:]...), nil }
+func (p *KZGProof) MarshalSSZTo(dst []byte) ([]byte, error) { return append(dst, p[:]...), nil }
+func (p *KZGProof) UnmarshalSSZ(buf []byte) error           { return decodeSSZ(p[:], buf, "proof") }
+
+// BlobFromHex decodes a 0x-prefixed hex blob.
+func BlobFromHex(s string) (*Blob, error) {
+	blob := new(Blob)
+	return blob, blob.UnmarshalText([]byte(s))
+}
+
+// CellFromHex decodes a 0x-prefixed hex cell.
+func CellFromHex(s string) (Cell, error) {
+	var cell Cell
+	return cell, cell.UnmarshalText([]byte(s))
+}
+
+// KZGCommitmentFromHex decodes a 0x-prefixed hex commitment.
+func KZGCommitmentFromHex(s string) (KZGCommitment, error) {
+	var commitment KZGCommitment
+	return commitment, commitment.UnmarshalText([]byte(s))
+}
+
+// KZGProofFromHex decodes a 0x-prefixed hex proof.
+func KZGProofFromHex(s string) (KZGProof, error) {
+	var proof KZGProof
+	return proof, proof.UnmarshalText([]byte(s))
+}
+
+// CellsFromBytes converts the cells returned by ComputeCellsAndKZGProofs.
+func CellsFromBytes(cells [][]byte) ([]Cell, error) {
+	out := make([]Cell, len(cells))
+	for i, cell := range cells {
+		if err := decodeSSZ(out[i][:], cell, fmt.Sprintf("cell %d", i)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// KZGProofsFromBytes converts the proofs returned by ComputeCellsAndKZGProofs.
+func KZGProofsFromBytes(proofs [][]byte) ([]KZGProof, error) {
+	out := make([]KZGProof, len(proofs))
+	for i, proof := range proofs {
+		if err := decodeSSZ(out[i][:], proof, fmt.Sprintf("proof %d", i)); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// MarshalCellsSSZ returns the SSZ encoding of a list of cells, like the column of a
+// DataColumnSidecar, which is the concatenation of the cells.
+func MarshalCellsSSZ(cells []Cell) []byte {
+	out := make([]byte, 0, len(cells)*BytesPerCell)
+	for i := range cells {
+		out = append(out, cells[i][:]...)
+	}
+	return out
+}
+
+// UnmarshalCellsSSZ decodes the SSZ encoding of a list of cells.
+func UnmarshalCellsSSZ(buf []byte) ([]Cell, error) {
+	if len(buf)%BytesPerCell != 0 {
+		return nil, fmt.Errorf("cells: %d bytes of SSZ is not a whole number of cells", len(buf))
+	}
+	cells := make([]Cell, len(buf)/BytesPerCell)
+	for i := range cells {
+		copy(cells[i][:], buf[i*BytesPerCell:])
+	}
+	return cells, nil
+}
+
+// MarshalKZGCommitmentsSSZ returns the SSZ encoding of a list of commitments, like the
+// blob_kzg_commitments of a block body.
+func MarshalKZGCommitmentsSSZ(commitments []KZGCommitment) []byte {
+	out := make([]byte, 0, len(commitments)*BytesPerCommitment)
+	for i := range commitments {
+		out = append(out, commitments[i][:]...)
+	}
+	return out
+}
+
+// UnmarshalKZGCommitmentsSSZ decodes the SSZ encoding of a list of commitments.
+func UnmarshalKZGCommitmentsSSZ(buf []byte) ([]KZGCommitment, error) {
+	if len(buf)%BytesPerCommitment != 0 {
+		return nil, fmt.Errorf("commitments: %d bytes of SSZ is not a whole number of commitments", len(buf))
+	}
+	commitments := make([]KZGCommitment, len(buf)/BytesPerCommitment)
+	for i := range commitments {
+		copy(commitments[i][:], buf[i*BytesPerCommitment:])
+	}
+	return commitments, nil
+}
+
+// MarshalKZGProofsSSZ returns the SSZ encoding of a list of proofs, like the kzg_proofs of a
+// DataColumnSidecar.
+func MarshalKZGProofsSSZ(proofs []KZGProof) []byte {
+	out := make([]byte, 0, len(proofs)*BytesPerProof)
+	for i := range proofs {
+		out = append(out, proofs[i][:]...)
+	}
+	return out
+}
+
+// UnmarshalKZGProofsSSZ decodes the SSZ encoding of a list of proofs.
+func UnmarshalKZGProofsSSZ(buf []byte) ([]KZGProof, error) {
+	if len(buf)%BytesPerProof != 0 {
+		return nil, fmt.Errorf("proofs: %d bytes of SSZ is not a whole number of proofs", len(buf))
+	}
+	proofs := make([]KZGProof, len(buf)/BytesPerProof)
+	for i := range proofs {
+		copy(proofs[i][:], buf[i*BytesPerProof:])
+	}
+	return proofs, nil
+}
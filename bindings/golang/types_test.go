@@ -0,0 +1,102 @@
+package eth_kzg
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHexRoundTrip(t *testing.T) {
+	var proof KZGProof
+	proof[0] = 0xc0
+	text := proof.String()
+	if text != "0xc0"+strings.Repeat("00", BytesPerProof-1) {
+		t.Fatalf("unexpected hex: %s", text)
+	}
+	decoded, err := KZGProofFromHex(text)
+	if err != nil || decoded != proof {
+		t.Fatalf("could not decode %s: %v", text, err)
+	}
+
+	// The types encode as hex strings in JSON, as in the beacon API
+	encoded, err := json.Marshal(struct{ Commitment KZGCommitment }{KZGCommitment(proof)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"Commitment":"`+text+`"}` {
+		t.Fatalf("unexpected JSON: %s", encoded)
+	}
+
+	// A blob held by value also encodes as hex, not as an array of numbers, whether or not
+	// the struct that holds it is addressable
+	var blob Blob
+	blob[0] = 0xc0
+	blobJSON := `{"Blob":"0xc0` + strings.Repeat("00", BytesPerBlob-1) + `"}`
+	for _, v := range []any{struct{ Blob Blob }{blob}, &struct{ Blob Blob }{blob}} {
+		encoded, err = json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != blobJSON {
+			t.Fatal("unexpected JSON for a blob")
+		}
+	}
+
+	for _, invalid := range []string{"", "c0", text[:len(text)-2], text + "00", "0x" + strings.Repeat("zz", BytesPerProof)} {
+		if _, err := KZGProofFromHex(invalid); err == nil {
+			t.Fatalf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestSSZRoundTrip(t *testing.T) {
+	cells := make([]Cell, 3)
+	for i := range cells {
+		cells[i][0] = byte(i + 1)
+	}
+	buf := MarshalCellsSSZ(cells)
+	if len(buf) != 3*BytesPerCell {
+		t.Fatalf("unexpected length: %d", len(buf))
+	}
+	decoded, err := UnmarshalCellsSSZ(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range cells {
+		if decoded[i] != cells[i] {
+			t.Fatalf("cell %d does not round trip", i)
+		}
+	}
+	if _, err := UnmarshalCellsSSZ(buf[1:]); err == nil {
+		t.Fatal("expected an error for a partial cell")
+	}
+
+	var cell Cell
+	if err := cell.UnmarshalSSZ(buf[:BytesPerCell]); err != nil || cell != cells[0] {
+		t.Fatalf("could not decode a single cell: %v", err)
+	}
+	if err := cell.UnmarshalSSZ(buf); err == nil {
+		t.Fatal("expected an error for too many bytes")
+	}
+}
+
+func TestTypedValuesFromBinding(t *testing.T) {
+	ctx := NewProverContext()
+	blob := new(Blob)
+	blob[31] = 1
+	cellBytes, proofBytes, err := ctx.ComputeCellsAndKZGProofs(blob[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells, err := CellsFromBytes(cellBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := KZGProofsFromBytes(proofBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != MaxNumColumns || len(proofs) != MaxNumColumns {
+		t.Fatalf("unexpected number of cells or proofs: %d, %d", len(cells), len(proofs))
+	}
+}
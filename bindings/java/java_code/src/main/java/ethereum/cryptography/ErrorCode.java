@@ -0,0 +1,112 @@
+package ethereum.cryptography;
+
+/**
+ * The stable codes of the errors returned by the native library.
+ *
+ * <p>The values match {@code rust_eth_kzg::ErrorCode}, so an error seen in Java can be
+ * looked up in the Rust source. They are never renumbered or reused.
+ */
+public enum ErrorCode {
+    /** A field element is not less than the scalar field modulus. */
+    NON_CANONICAL_SCALAR(101),
+    /** A G1 point is not a valid compressed encoding. */
+    INVALID_G1_POINT_ENCODING(102),
+    /** A G1 point is not on the curve. */
+    G1_POINT_NOT_ON_CURVE(103),
+    /** A G1 point is on the curve, but not in the G1 subgroup. */
+    G1_POINT_NOT_IN_SUBGROUP(104),
+    /** A field element does not have the right number of bytes. */
+    SCALAR_HAS_INVALID_LENGTH(105),
+    /** A blob does not have the right number of bytes. */
+    BLOB_HAS_INVALID_LENGTH(106),
+    /** A G1 point does not have the right number of bytes. */
+    G1_POINT_HAS_INVALID_LENGTH(107),
+    /** A cell index is not less than the number of cells in an extended blob. */
+    CELL_INDEX_OUT_OF_RANGE(201),
+    /** A commitment index does not refer to one of the commitments. */
+    COMMITMENT_INDEX_OUT_OF_RANGE(202),
+    /** The inputs to a batch operation do not have the same length. */
+    BATCH_LENGTH_MISMATCH(203),
+    /** The number of cell indices differs from the number of cells. */
+    NUM_CELL_INDICES_NOT_EQUAL_TO_NUM_CELLS(204),
+    /** Fewer than half of the cells were given to recovery. */
+    NOT_ENOUGH_CELLS_TO_RECONSTRUCT(205),
+    /** More cells than there are in an extended blob were given to recovery. */
+    TOO_MANY_CELLS_RECEIVED(206),
+    /** The cell indices given to recovery are not sorted and unique. */
+    CELL_INDICES_NOT_UNIQUELY_ORDERED(207),
+    /** A cell does not contain one evaluation per point in its coset. */
+    INVALID_CELL_LENGTH(208),
+    /** A proof failed verification. */
+    INVALID_PROOF(301),
+    /** A polynomial had an unexpected number of coefficients. */
+    POLYNOMIAL_HAS_INVALID_LENGTH(401),
+    /** A polynomial had more coefficients than the code allows. */
+    POLYNOMIAL_HAS_TOO_MANY_COEFFICIENTS(402),
+    /** More blocks were erased than the code can correct. */
+    TOO_MANY_BLOCK_ERASURES(403),
+    /** A block index is not less than the block size. */
+    BLOCK_INDEX_OUT_OF_RANGE(404),
+    /** The trusted setup has too few points to be checked. */
+    TRUSTED_SETUP_NOT_ENOUGH_POINTS(501),
+    /** The first point of the trusted setup is not the generator. */
+    TRUSTED_SETUP_FIRST_POINT_IS_NOT_GENERATOR(502),
+    /** A G1 point of the trusted setup is not in the G1 subgroup. */
+    TRUSTED_SETUP_G1_POINT_NOT_IN_SUBGROUP(503),
+    /** A G2 point of the trusted setup is not in the G2 subgroup. */
+    TRUSTED_SETUP_G2_POINT_NOT_IN_SUBGROUP(504),
+    /** The G1 points of the trusted setup are not powers of the same secret. */
+    TRUSTED_SETUP_INCONSISTENT_G1_POWERS(505),
+    /** The G2 points of the trusted setup are not powers of the same secret as the G1 points. */
+    TRUSTED_SETUP_INCONSISTENT_G2_POWERS(506),
+    /** The operation was cancelled because the async runtime is shutting down. */
+    CANCELLED(601),
+    /** A prover method was called on a context that was built without a prover. */
+    VERIFIER_ONLY_CONTEXT(602),
+    /** The operation was rejected because the context was running and queuing as many operations as its admission limits allow. */
+    OVERLOADED(603),
+    /** The operation waited for longer than the queue timeout of the admission limits of the context. */
+    ADMISSION_TIMED_OUT(604),
+    /** An argument could not be passed to the library. */
+    INVALID_ARGUMENT(901),
+    /** The trusted setup could not be read. */
+    TRUSTED_SETUP_UNREADABLE(902),
+    /** The trusted setup could not be parsed. */
+    TRUSTED_SETUP_MALFORMED(903),
+    /** A thread pool could not be created. */
+    THREAD_POOL_CREATION_FAILED(904),
+    /** The self test of the context failed. */
+    SELF_TEST_FAILED(905),
+    /** The library does not implement the version of the C ABI that a binding was built against. */
+    ABI_VERSION_MISMATCH(906);
+
+    private final int code;
+
+    ErrorCode(int code) {
+        this.code = code;
+    }
+
+    /**
+     * Gets the numeric value of the code.
+     *
+     * @return The numeric value of the code.
+     */
+    public int getCode() {
+        return code;
+    }
+
+    /**
+     * Gets the error code with the given numeric value.
+     *
+     * @param code The numeric value of the code.
+     * @return The error code, or null if the value is not a known code.
+     */
+    public static ErrorCode fromCode(int code) {
+        for (ErrorCode errorCode : values()) {
+            if (errorCode.code == code) {
+                return errorCode;
+            }
+        }
+        return null;
+    }
+}
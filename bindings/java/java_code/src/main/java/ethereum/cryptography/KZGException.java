@@ -0,0 +1,42 @@
+package ethereum.cryptography;
+
+/**
+ * Thrown when the native library returns an error.
+ *
+ * <p>This extends {@link IllegalArgumentException}, which the library threw before error
+ * codes were added, so existing handlers keep working.
+ */
+public class KZGException extends IllegalArgumentException {
+
+    private final int code;
+
+    /**
+     * Constructs a KZGException. This is called from the native library.
+     *
+     * @param code    The numeric value of the error code.
+     * @param message The error message.
+     */
+    public KZGException(int code, String message) {
+        super(message);
+        this.code = code;
+    }
+
+    /**
+     * Gets the error code, or null if the native library returned a code that
+     * this version of the bindings does not know about.
+     *
+     * @return The error code.
+     */
+    public ErrorCode getErrorCode() {
+        return ErrorCode.fromCode(code);
+    }
+
+    /**
+     * Gets the numeric value of the error code.
+     *
+     * @return The numeric value of the error code.
+     */
+    public int getCode() {
+        return code;
+    }
+}
@@ -24,6 +24,13 @@ public class LibEthKZG implements AutoCloseable{
     /** The number of bytes in a single cell. */
     public static final int BYTES_PER_CELL = 2048;
 
+    /**
+     * The system property that, when set to true, loads the library with
+     * {@link System#loadLibrary} instead of from the jar. Set it when the static library is
+     * linked into a GraalVM native image, which registers it as a built-in library.
+     */
+    public static final String STATIC_LIBRARY_PROPERTY = "ethereum.cryptography.LibEthKZG.static";
+
     private long contextPtr;
 
     private static volatile boolean libraryLoaded = false;
@@ -49,6 +56,44 @@ public class LibEthKZG implements AutoCloseable{
         this.contextPtr = DASContextNew(usePrecomp);
     }
 
+    /**
+     * Constructs a LibEthKZG instance with the given precomputation width.
+     *
+     * @param precompWidth The window width of the precomputed tables. A width of 0 disables
+     *                     pre-computation, and each increment roughly doubles the memory used
+     *                     by the tables and makes computing cell proofs faster.
+     * @param compressedPrecomp Whether to store only the odd multiples of each point in the
+     *                          tables, which halves their size and makes computing cell proofs
+     *                          a bit slower.
+     * @throws IllegalArgumentException if precompWidth is not between 0 and 15.
+     */
+    public LibEthKZG(int precompWidth, boolean compressedPrecomp) {
+        ensureLibraryLoaded();
+        this.contextPtr = DASContextNewWithPrecompWidth(precompWidth, compressedPrecomp);
+    }
+
+    /**
+     * Constructs a LibEthKZG instance that runs on a dedicated thread pool.
+     *
+     * @param usePrecomp Whether to use pre-computation.
+     * @param numThreads The number of threads to use. A value of 1 makes the context
+     *                   fully single-threaded.
+     * @throws IllegalArgumentException if numThreads is less than 1.
+     */
+    public LibEthKZG(boolean usePrecomp, int numThreads) {
+        if (numThreads < 1) {
+            throw new IllegalArgumentException("numThreads must be at least 1");
+        }
+        ensureLibraryLoaded();
+        this.contextPtr = DASContextNew(usePrecomp);
+        try {
+            DASContextSetNumThreads(contextPtr, numThreads);
+        } catch (IllegalArgumentException e) {
+            destroy();
+            throw e;
+        }
+    }
+
     private static void ensureLibraryLoaded() {
         if (!libraryLoaded) {
             synchronized (libraryLock) {
@@ -215,8 +260,12 @@ public class LibEthKZG implements AutoCloseable{
 
     private static native long DASContextNew(boolean usePrecomp);
 
+    private static native long DASContextNewWithPrecompWidth(int precompWidth, boolean compressedPrecomp);
+
     private static native void DASContextDestroy(long ctx_ptr);
 
+    private static native void DASContextSetNumThreads(long ctx_ptr, int numThreads);
+
     private static native CellsAndProofs computeCellsAndKZGProofs(long context_ptr, byte[] blob);
     
     private static native Cells computeCells(long context_ptr, byte[] blob);
@@ -254,6 +303,10 @@ public class LibEthKZG implements AutoCloseable{
 
     /** Loads the appropriate native library based on your platform. */
     private static void loadNativeLibrary() {
+        if (Boolean.getBoolean(STATIC_LIBRARY_PROPERTY)) {
+            System.loadLibrary(LIBRARY_NAME);
+            return;
+        }
 
         String osName = System.getProperty("os.name").toLowerCase();
         String osArch = getNormalizedArchitecture();
@@ -54,6 +54,34 @@ public class LibEthKZGTest {
         }
     }
 
+    @Test
+    void testCompressedPrecompComputesTheSameProofs() {
+        byte[] blob = new byte[LibEthKZG.BYTES_PER_BLOB];
+        blob[31] = 1;
+        try (LibEthKZG compressed = new LibEthKZG(4, true)) {
+            CellsAndProofs expected = context.computeCellsAndKZGProofs(blob);
+            CellsAndProofs actual = compressed.computeCellsAndKZGProofs(blob);
+            assertArrayEquals(expected.proofs, actual.proofs);
+        }
+
+        for (int precompWidth : new int[] {-1, 16, 256}) {
+            KZGException ex = assertThrows(KZGException.class, () -> new LibEthKZG(precompWidth, true));
+            assertEquals(ErrorCode.INVALID_ARGUMENT, ex.getErrorCode());
+        }
+    }
+
+    @Test
+    void testErrorsCarryTheirCode() {
+        byte[] blob = new byte[LibEthKZG.BYTES_PER_BLOB];
+        // A field element that is larger than the modulus.
+        java.util.Arrays.fill(blob, 0, 32, (byte) 0xff);
+
+        KZGException ex = assertThrows(KZGException.class, () -> context.blobToKZGCommitment(blob));
+        assertEquals(ErrorCode.NON_CANONICAL_SCALAR, ex.getErrorCode());
+        assertEquals(101, ex.getCode());
+        assertTrue(ex.getMessage().contains("E101 NonCanonicalScalar"));
+    }
+
     @ParameterizedTest
     @MethodSource("ethereum.cryptography.TestUtils#getBlobToKzgCommitmentTests")
     public void blobToKzgCommitmentTests(final BlobToKzgCommitmentTest test) {
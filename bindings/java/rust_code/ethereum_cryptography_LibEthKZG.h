@@ -35,6 +35,14 @@ JNIEXPORT jlong JNICALL Java_ethereum_cryptography_LibEthKZG_DASContextNew
 JNIEXPORT void JNICALL Java_ethereum_cryptography_LibEthKZG_DASContextDestroy
   (JNIEnv *, jclass, jlong);
 
+/*
+ * Class:     ethereum_cryptography_LibEthKZG
+ * Method:    DASContextSetNumThreads
+ * Signature: (JI)V
+ */
+JNIEXPORT void JNICALL Java_ethereum_cryptography_LibEthKZG_DASContextSetNumThreads
+  (JNIEnv *, jclass, jlong, jint);
+
 /*
  * Class:     ethereum_cryptography_LibEthKZG
  * Method:    computeCellsAndKZGProofs
@@ -1,4 +1,4 @@
-use c_eth_kzg::Error as KZGError;
+use c_eth_kzg::{Error as KZGError, ErrorCode, ThreadPoolBuildError};
 
 #[derive(Debug)]
 pub enum Error {
@@ -9,6 +9,9 @@ pub enum Error {
         name: &'static str,
     },
     Cryptography(KZGError),
+    ThreadPool(ThreadPoolBuildError),
+    NegativeNumThreads(i32),
+    InvalidPrecompWidth(i32),
 }
 
 impl From<jni::errors::Error> for Error {
@@ -17,8 +20,28 @@ impl From<jni::errors::Error> for Error {
     }
 }
 
+impl From<ThreadPoolBuildError> for Error {
+    fn from(err: ThreadPoolBuildError) -> Self {
+        Self::ThreadPool(err)
+    }
+}
+
 impl From<KZGError> for Error {
     fn from(err: KZGError) -> Self {
         Self::Cryptography(err)
     }
 }
+
+impl Error {
+    /// Returns the stable code that is passed to Java with the exception.
+    pub const fn code(&self) -> ErrorCode {
+        match self {
+            Self::Jni(_)
+            | Self::IncorrectSize { .. }
+            | Self::NegativeNumThreads(_)
+            | Self::InvalidPrecompWidth(_) => ErrorCode::InvalidArgument,
+            Self::Cryptography(err) => err.code(),
+            Self::ThreadPool(_) => ErrorCode::ThreadPoolCreationFailed,
+        }
+    }
+}
@@ -1,12 +1,13 @@
 use c_eth_kzg::DASContext;
 use jni::{
-    objects::{JByteArray, JClass, JLongArray, JObject, JObjectArray, JValue},
-    sys::{jboolean, jlong},
+    objects::{JByteArray, JClass, JLongArray, JObject, JObjectArray, JThrowable, JValue},
+    sys::{jboolean, jint, jlong},
     JNIEnv,
 };
 
 mod errors;
 use errors::Error;
+mod registration;
 
 #[no_mangle]
 pub extern "system" fn Java_ethereum_cryptography_LibEthKZG_DASContextNew(
@@ -18,6 +19,62 @@ pub extern "system" fn Java_ethereum_cryptography_LibEthKZG_DASContextNew(
     c_eth_kzg::eth_kzg_das_context_new(use_precomp) as jlong
 }
 
+#[no_mangle]
+pub extern "system" fn Java_ethereum_cryptography_LibEthKZG_DASContextNewWithPrecompWidth(
+    mut env: JNIEnv,
+    _class: JClass,
+    precomp_width: jint,
+    compressed_precomp: jboolean,
+) -> jlong {
+    match das_context_new_with_precomp_width(precomp_width, compressed_precomp != 0) {
+        Ok(ctx) => ctx as jlong,
+        Err(err) => {
+            throw_on_error(&mut env, err, "DASContextNewWithPrecompWidth");
+            0
+        }
+    }
+}
+fn das_context_new_with_precomp_width(
+    precomp_width: jint,
+    compressed_precomp: bool,
+) -> Result<*mut DASContext, Error> {
+    // A width with the flag bit set would be read as a compressed width.
+    let width = usize::try_from(precomp_width)
+        .ok()
+        .filter(|width| width & c_eth_kzg::ETH_KZG_PRECOMP_COMPRESSED == 0)
+        .ok_or(Error::InvalidPrecompWidth(precomp_width))?;
+    let flags = if compressed_precomp {
+        c_eth_kzg::ETH_KZG_PRECOMP_COMPRESSED
+    } else {
+        0
+    };
+    // The library is built with the embedded trusted setup, so the width is the only argument
+    // that can be rejected.
+    let ctx = c_eth_kzg::eth_kzg_das_context_new_with_precomp_width(width | flags);
+    if ctx.is_null() {
+        return Err(Error::InvalidPrecompWidth(precomp_width));
+    }
+    Ok(ctx)
+}
+
+#[no_mangle]
+pub extern "system" fn Java_ethereum_cryptography_LibEthKZG_DASContextSetNumThreads(
+    mut env: JNIEnv,
+    _class: JClass,
+    ctx_ptr: jlong,
+    num_threads: jint,
+) {
+    let ctx = unsafe { &mut *(ctx_ptr as *mut DASContext) };
+    if let Err(err) = set_num_threads(ctx, num_threads) {
+        throw_on_error(&mut env, err, "DASContextSetNumThreads");
+    }
+}
+fn set_num_threads(ctx: &mut DASContext, num_threads: jint) -> Result<(), Error> {
+    let num_threads =
+        usize::try_from(num_threads).map_err(|_| Error::NegativeNumThreads(num_threads))?;
+    ctx.set_num_threads(num_threads).map_err(Error::from)
+}
+
 #[no_mangle]
 pub extern "system" fn Java_ethereum_cryptography_LibEthKZG_DASContextDestroy(
     _env: JNIEnv,
@@ -538,6 +595,7 @@ fn cells_to_jobject<'local>(
 }
 /// Throws an exception in Java
 fn throw_on_error(env: &mut JNIEnv, err: Error, func_name: &'static str) {
+    let code = err.code();
     let reason = match err {
         Error::Jni(err) => format!("{err:?}"),
         Error::IncorrectSize {
@@ -546,10 +604,29 @@ fn throw_on_error(env: &mut JNIEnv, err: Error, func_name: &'static str) {
             name,
         } => format!("{name} is not the correct size. expected: {expected}\ngot: {got}"),
         Error::Cryptography(err) => format!("{err:?}"),
+        Error::ThreadPool(err) => format!("{err}"),
+        Error::NegativeNumThreads(num_threads) => {
+            format!("numThreads must not be negative, got {num_threads}")
+        }
+        Error::InvalidPrecompWidth(precomp_width) => {
+            format!("precompWidth must be between 0 and 15, got {precomp_width}")
+        }
     };
-    let msg = format!("function {func_name} has thrown an exception, with reason: {reason}");
-    env.throw_new("java/lang/IllegalArgumentException", msg)
-        .expect("Failed to throw exception");
+    let msg =
+        format!("function {func_name} has thrown an exception, with reason: {code}: {reason}");
+    throw_kzg_exception(env, code.as_u32() as jint, &msg).expect("Failed to throw exception");
+}
+
+/// Throws an `ethereum.cryptography.KZGException`, which carries the stable error code
+/// alongside the message.
+fn throw_kzg_exception(env: &mut JNIEnv, code: jint, msg: &str) -> jni::errors::Result<()> {
+    let msg = env.new_string(msg)?;
+    let exception = env.new_object(
+        "ethereum/cryptography/KZGException",
+        "(ILjava/lang/String;)V",
+        &[JValue::Int(code), JValue::Object(&msg)],
+    )?;
+    env.throw(JThrowable::from(exception))
 }
 
 /// Convert a slice into a reference to an array
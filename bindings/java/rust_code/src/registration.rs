@@ -0,0 +1,181 @@
+//! Registers the native methods of `LibEthKZG` when the library is loaded.
+//!
+//! The JVM can find the `Java_ethereum_cryptography_*` functions by their names in a dynamic
+//! library, but GraalVM native-image cannot when the library is linked statically into the
+//! image, so they are also registered with `RegisterNatives`, which works in both cases.
+//!
+//! A statically linked JNI library must name its load hook `JNI_OnLoad_<library name>`, so that
+//! several of them can be linked into one image. The `static` feature exports that hook
+//! instead of `JNI_OnLoad`.
+
+use std::ffi::c_void;
+
+use jni::{
+    sys::{jint, JNI_ERR, JNI_VERSION_1_8},
+    JavaVM, NativeMethod,
+};
+
+use crate::{
+    Java_ethereum_cryptography_LibEthKZG_DASContextDestroy,
+    Java_ethereum_cryptography_LibEthKZG_DASContextNew,
+    Java_ethereum_cryptography_LibEthKZG_DASContextNewWithPrecompWidth,
+    Java_ethereum_cryptography_LibEthKZG_DASContextSetNumThreads,
+    Java_ethereum_cryptography_LibEthKZG_blobToKZGCommitment,
+    Java_ethereum_cryptography_LibEthKZG_computeBlobKzgProof,
+    Java_ethereum_cryptography_LibEthKZG_computeCells,
+    Java_ethereum_cryptography_LibEthKZG_computeCellsAndKZGProofs,
+    Java_ethereum_cryptography_LibEthKZG_computeKzgProof,
+    Java_ethereum_cryptography_LibEthKZG_recoverCellsAndKZGProofs,
+    Java_ethereum_cryptography_LibEthKZG_verifyBlobKzgProof,
+    Java_ethereum_cryptography_LibEthKZG_verifyBlobKzgProofBatch,
+    Java_ethereum_cryptography_LibEthKZG_verifyCellKZGProofBatch,
+    Java_ethereum_cryptography_LibEthKZG_verifyKzgProof,
+};
+
+const CLASS: &str = "ethereum/cryptography/LibEthKZG";
+
+/// The name, JNI signature and implementation of every native method of `LibEthKZG`.
+fn native_methods() -> [(&'static str, &'static str, *mut c_void); 14] {
+    [
+        (
+            "DASContextNew",
+            "(Z)J",
+            Java_ethereum_cryptography_LibEthKZG_DASContextNew as *mut c_void,
+        ),
+        (
+            "DASContextNewWithPrecompWidth",
+            "(IZ)J",
+            Java_ethereum_cryptography_LibEthKZG_DASContextNewWithPrecompWidth as *mut c_void,
+        ),
+        (
+            "DASContextDestroy",
+            "(J)V",
+            Java_ethereum_cryptography_LibEthKZG_DASContextDestroy as *mut c_void,
+        ),
+        (
+            "DASContextSetNumThreads",
+            "(JI)V",
+            Java_ethereum_cryptography_LibEthKZG_DASContextSetNumThreads as *mut c_void,
+        ),
+        (
+            "computeCellsAndKZGProofs",
+            "(J[B)Lethereum/cryptography/CellsAndProofs;",
+            Java_ethereum_cryptography_LibEthKZG_computeCellsAndKZGProofs as *mut c_void,
+        ),
+        (
+            "computeCells",
+            "(J[B)Lethereum/cryptography/Cells;",
+            Java_ethereum_cryptography_LibEthKZG_computeCells as *mut c_void,
+        ),
+        (
+            "blobToKZGCommitment",
+            "(J[B)[B",
+            Java_ethereum_cryptography_LibEthKZG_blobToKZGCommitment as *mut c_void,
+        ),
+        (
+            "verifyCellKZGProofBatch",
+            "(J[[B[J[[B[[B)Z",
+            Java_ethereum_cryptography_LibEthKZG_verifyCellKZGProofBatch as *mut c_void,
+        ),
+        (
+            "recoverCellsAndKZGProofs",
+            "(J[J[[B)Lethereum/cryptography/CellsAndProofs;",
+            Java_ethereum_cryptography_LibEthKZG_recoverCellsAndKZGProofs as *mut c_void,
+        ),
+        (
+            "computeKzgProof",
+            "(J[B[B)[[B",
+            Java_ethereum_cryptography_LibEthKZG_computeKzgProof as *mut c_void,
+        ),
+        (
+            "computeBlobKzgProof",
+            "(J[B[B)[B",
+            Java_ethereum_cryptography_LibEthKZG_computeBlobKzgProof as *mut c_void,
+        ),
+        (
+            "verifyKzgProof",
+            "(J[B[B[B[B)Z",
+            Java_ethereum_cryptography_LibEthKZG_verifyKzgProof as *mut c_void,
+        ),
+        (
+            "verifyBlobKzgProof",
+            "(J[B[B[B)Z",
+            Java_ethereum_cryptography_LibEthKZG_verifyBlobKzgProof as *mut c_void,
+        ),
+        (
+            "verifyBlobKzgProofBatch",
+            "(J[[B[[B[[B)Z",
+            Java_ethereum_cryptography_LibEthKZG_verifyBlobKzgProofBatch as *mut c_void,
+        ),
+    ]
+}
+
+/// Registers the native methods of `LibEthKZG`, returning the JNI version that the library
+/// needs, or `JNI_ERR` if the registration failed, in which case the JVM fails the load.
+fn on_load(vm: *mut jni::sys::JavaVM) -> jint {
+    let register = || -> jni::errors::Result<()> {
+        // SAFETY: the JVM passes a valid pointer to itself to the load hook.
+        let vm = unsafe { JavaVM::from_raw(vm)? };
+        let mut env = vm.get_env()?;
+        let methods: Vec<_> = native_methods()
+            .into_iter()
+            .map(|(name, sig, fn_ptr)| NativeMethod {
+                name: name.into(),
+                sig: sig.into(),
+                fn_ptr,
+            })
+            .collect();
+        env.register_native_methods(CLASS, &methods)
+    };
+    match register() {
+        Ok(()) => JNI_VERSION_1_8,
+        Err(_) => JNI_ERR,
+    }
+}
+
+#[cfg(not(feature = "static"))]
+#[no_mangle]
+pub extern "system" fn JNI_OnLoad(vm: *mut jni::sys::JavaVM, _reserved: *mut c_void) -> jint {
+    on_load(vm)
+}
+
+#[cfg(feature = "static")]
+#[no_mangle]
+pub extern "system" fn JNI_OnLoad_java_eth_kzg(
+    vm: *mut jni::sys::JavaVM,
+    _reserved: *mut c_void,
+) -> jint {
+    on_load(vm)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::native_methods;
+
+    /// The header that `build.rs` generates from `LibEthKZG.java` with `javac -h`, which lists
+    /// the name and signature of every native method.
+    const HEADER: &str = include_str!("../ethereum_cryptography_LibEthKZG.h");
+
+    #[test]
+    fn every_native_method_is_registered_with_its_signature() {
+        let mut declared: Vec<_> = HEADER
+            .lines()
+            .filter_map(|line| line.trim().strip_prefix("* Method:"))
+            .map(str::trim)
+            .zip(
+                HEADER
+                    .lines()
+                    .filter_map(|line| line.trim().strip_prefix("* Signature:"))
+                    .map(str::trim),
+            )
+            .collect();
+        let mut registered: Vec<_> = native_methods()
+            .into_iter()
+            .map(|(name, sig, _)| (name, sig))
+            .collect();
+
+        declared.sort_unstable();
+        registered.sort_unstable();
+        assert_eq!(declared, registered);
+    }
+}
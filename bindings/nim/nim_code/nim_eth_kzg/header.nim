@@ -1,6 +1,24 @@
 # WARNING: This file has been automatically generated by nbindgen. Do not edit by hand.
 
 
+## The major version of the C ABI. See `bindings/c/src/abi.rs` for the stability policy.
+const ETH_KZG_ABI_VERSION_MAJOR*: uint32 = 1
+
+## The minor version of the C ABI. See `bindings/c/src/abi.rs` for the stability policy.
+const ETH_KZG_ABI_VERSION_MINOR*: uint32 = 9
+
+## Combined with a precomputation width, ie `8 | ETH_KZG_PRECOMP_COMPRESSED`, to store only the
+# odd multiples of each point in the precomputed tables.
+#
+# The tables are half the size of those of the same width without the flag, and computing cell
+# proofs is a bit slower. Every function that takes a `precomp_width` accepts the flag.
+const ETH_KZG_PRECOMP_COMPRESSED*: uint = 256
+
+## Selects the blob proof methods of Deneb (EIP-4844) in `eth_kzg_das_context_new_for_forks`.
+const ETH_KZG_FORK_DENEB*: uint32 = 1
+
+## Selects the cell proof methods of Fulu (EIP-7594) in `eth_kzg_das_context_new_for_forks`.
+const ETH_KZG_FORK_FULU*: uint32 = 2
 
 ## A C-style enum to indicate whether a function call was a success or not.
 type CResultStatus* = enum
@@ -9,21 +27,299 @@ type CResultStatus* = enum
 
 type DASContext* {.incompleteStruct.} = object
 
+## The buffers that `eth_kzg_compute_cells_and_kzg_proofs_with_scratch`,
+# `eth_kzg_compute_cells_with_scratch` and `eth_kzg_recover_cells_and_kzg_proofs_with_scratch`
+# compute the cells and proofs of a blob in, so that they do not allocate them on every call.
+#
+# A scratch can only be used by one thread at a time, while a DASContext can be shared by
+# any number of threads. Embedders that prove on several threads should create one scratch
+# per thread from the same context.
+type DASScratch* {.incompleteStruct.} = object
+
 ## A C-style struct to represent the success result of a function call.
 #
-# This includes the status of the call and an error message, if the status was an error.
+# This includes the status of the call and, if the status was an error, an error message
+# and the stable code of the error. The codes are listed in `rust_eth_kzg::ErrorCode`;
+# the code is zero when the status is `Ok`.
 type CResult* = object
   xstatus*: CResultStatus
   xerror_msg*: pointer
+  xerror_code*: uint32
+
+## The number of bytes held by a DASContext, split by what they are used for.
+#
+# The tables are shared between a context and its clones, and buffers owned by
+# the caller are not included.
+type MemoryUsage* = object
+  xsrs*: uint64
+  xfk20_precomputations*: uint64
+  xdomains*: uint64
+  xtotal*: uint64
+
+## Counters of a DASContext, for exporting to a metrics system such as Prometheus.
+#
+# The counters start at zero when the context is created, and include every operation,
+# whether or not a metrics callback is set.
+type Stats* = object
+  xoperations*: uint64
+  xfailed_operations*: uint64
+  xprecomputation_bytes*: uint64
+  xmemory_bytes*: uint64
+  xscratch_bytes*: uint64
+
+## A callback that is called once for every operation a DASContext completes.
+#
+# - `user_data` is the pointer that was passed to `eth_kzg_das_context_set_metrics_callback`.
+# - `operation` identifies the method that was called, ie 1 for `blob_to_kzg_commitment` and
+#   8 for `verify_cell_kzg_proof_batch`. The values are stable and match `rust_eth_kzg::Operation`.
+# - `batch_size` is the number of blobs or cells in the input, or 1 for the methods on a single blob.
+# - `duration_nanos` is the time the operation took, in nanoseconds.
+# - `error_code` is 0 if the operation succeeded, and the code of its error otherwise.
+type MetricsCallback* = proc(user_data: pointer,
+                             operation: uint32,
+                             batch_size: uint64,
+                             duration_nanos: uint64,
+                             error_code: uint32) {.cdecl.}
 
 ## Create a new DASContext and return a pointer to it.
 #
+# If `use_precomp` is true, the recommended precomputation width is used.
+# See `eth_kzg_das_context_new_with_precomp_width` to choose the width explicitly.
+#
+# This uses the embedded mainnet trusted setup. If the library was built with the
+# `no-embedded-setup` feature, this returns a null pointer and
+# `eth_kzg_das_context_new_from_trusted_setup` must be used instead. The environment is
+# never read; see `eth_kzg_das_context_new_from_env` to load the trusted setup from it.
+#
 # # Memory faults
 #
 # To avoid memory leaks, one should ensure that the pointer is freed after use
 # by calling `eth_kzg_das_context_free`.
 proc eth_kzg_das_context_new*(use_precomp: bool): ptr DASContext {.importc: "eth_kzg_das_context_new".}
 
+## Create a new DASContext with the given precomputation width and return a pointer to it.
+#
+# The width controls the size of the tables precomputed for computing cell proofs.
+# A width of zero disables precomputation, which uses the least memory but is the slowest.
+# Larger widths are faster, but each increment roughly doubles the memory used by the tables.
+# Widths larger than 15 are rejected.
+# A width may be combined with `ETH_KZG_PRECOMP_COMPRESSED` to halve the size of the tables.
+#
+# This uses the embedded mainnet trusted setup. If the library was built with the
+# `no-embedded-setup` feature, this returns a null pointer and
+# `eth_kzg_das_context_new_from_trusted_setup` must be used instead. The environment is
+# never read; see `eth_kzg_das_context_new_from_env` to load the trusted setup from it.
+#
+# # Memory faults
+#
+# To avoid memory leaks, one should ensure that the pointer is freed after use
+# by calling `eth_kzg_das_context_free`.
+proc eth_kzg_das_context_new_with_precomp_width*(precomp_width: uint): ptr DASContext {.importc: "eth_kzg_das_context_new_with_precomp_width".}
+
+## Create a new DASContext from a trusted setup supplied by the caller.
+#
+# `trusted_setup` holds the contents of a trusted setup file, either in the JSON format
+# used by the consensus specs or in the `trusted_setup.txt` format used by c-kzg-4844.
+# On success, a pointer to the new context is written to `out`.
+#
+# An error is returned if no trusted setup was provided, ie `trusted_setup` is null or
+# `trusted_setup_len` is zero, or if the trusted setup could not be parsed.
+#
+# # Safety
+#
+# - The caller must ensure that `trusted_setup` points to a region of memory that is at least `trusted_setup_len` bytes.
+# - The caller must ensure that `out` is a valid pointer.
+#
+# # Memory faults
+#
+# To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+# by calling `eth_kzg_das_context_free`.
+proc eth_kzg_das_context_new_from_trusted_setup*(trusted_setup: pointer,
+                                                trusted_setup_len: uint,
+                                                precomp_width: uint,
+                                                outx: ptr ptr DASContext): CResult {.importc: "eth_kzg_das_context_new_from_trusted_setup".}
+
+## Create a new DASContext from the trusted setup file at `path`.
+#
+# The file may be in the JSON format used by the consensus specs, the `trusted_setup.txt`
+# format used by c-kzg-4844 or the binary cache format. On success, a pointer to the new
+# context is written to `out`.
+#
+# # Safety
+#
+# - The caller must ensure that `path` is a valid null-terminated string.
+# - The caller must ensure that `out` is a valid pointer.
+#
+# # Memory faults
+#
+# To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+# by calling `eth_kzg_das_context_free`.
+proc eth_kzg_das_context_new_from_path*(path: cstring,
+                                       precomp_width: uint,
+                                       outx: ptr ptr DASContext): CResult {.importc: "eth_kzg_das_context_new_from_path".}
+
+## Create a new DASContext, loading the trusted setup from the environment.
+#
+# The trusted setup is loaded from the file named by the `ETH_KZG_TRUSTED_SETUP`
+# environment variable if it is set, then from the default locations, and otherwise
+# the embedded mainnet trusted setup is used. On success, a pointer to the new context
+# is written to `out`.
+#
+# # Safety
+#
+# - The caller must ensure that `out` is a valid pointer.
+#
+# # Memory faults
+#
+# To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+# by calling `eth_kzg_das_context_free`.
+proc eth_kzg_das_context_new_from_env*(precomp_width: uint,
+                                      outx: ptr ptr DASContext): CResult {.importc: "eth_kzg_das_context_new_from_env".}
+
+## Run the methods of the DASContext on a dedicated thread pool with `num_threads` threads.
+#
+# By default, a DASContext uses a global thread pool with one thread per CPU. Setting
+# `num_threads` to 1 makes the context fully single-threaded, and setting it to 0 creates a
+# dedicated pool with one thread per CPU; the context does not go back to the global pool
+# once this was called. This should be called before the context is shared between threads.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+proc eth_kzg_das_context_set_num_threads*(ctx: ptr DASContext,
+                                         num_threads: uint): CResult {.importc: "eth_kzg_das_context_set_num_threads".}
+
+## Report every operation of the DASContext to `callback`, so that they can be exported to
+# a metrics system such as Prometheus. Passing a null `callback` removes the current one.
+#
+# The callback is called on the thread that called the operation, once it has finished, so it
+# should be cheap and must not block. This should be called before the context is shared
+# between threads.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+# - The caller must ensure that `callback` can be called from any thread with `user_data`, until
+#   the callback is removed or the context is freed.
+proc eth_kzg_das_context_set_metrics_callback*(ctx: ptr DASContext,
+                                              callback: MetricsCallback,
+                                              user_data: pointer): CResult {.importc: "eth_kzg_das_context_set_metrics_callback".}
+
+## Write the number of bytes held by the SRS tables, FK20 precomputations and domains of the DASContext to `out`.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+# - The caller must ensure that `out` points to a writable `MemoryUsage`.
+proc eth_kzg_das_context_memory_usage*(ctx: ptr DASContext,
+                                      outx: ptr MemoryUsage): CResult {.importc: "eth_kzg_das_context_memory_usage".}
+
+## Write the counters of the DASContext to `out`.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+# - The caller must ensure that `out` points to a writable `Stats`.
+proc eth_kzg_das_context_stats*(ctx: ptr DASContext, outx: ptr Stats): CResult {.importc: "eth_kzg_das_context_stats".}
+
+## Write the number of times the DASContext performed `operation`, and the number of those
+# that returned an error, to `out_calls` and `out_failures`.
+#
+# `operation` uses the numbering of `MetricsCallback`. An error with the `InvalidArgument`
+# code is returned for an unknown operation.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+# - The caller must ensure that `out_calls` and `out_failures` are valid pointers.
+proc eth_kzg_das_context_operation_count*(ctx: ptr DASContext,
+                                         operation: uint32,
+                                         out_calls: ptr uint64,
+                                         out_failures: ptr uint64): CResult {.importc: "eth_kzg_das_context_operation_count".}
+
+## Run at most `max_in_flight` operations of the DASContext at the same time, across all the
+# threads that call it. Setting `max_in_flight` to 0 removes the limits, which is the default.
+#
+# When `max_in_flight` operations are running, up to `max_queued` others wait for one of them
+# to finish, for at most `queue_timeout_millis` milliseconds, or without a time limit if
+# `queue_timeout_millis` is 0. An operation that arrives when the queue is full returns an
+# error with the `Overloaded` code, and one that waits for too long returns an error with
+# the `AdmissionTimedOut` code, so that a burst of requests is turned away instead of
+# slowing down every operation. An error with the `InvalidArgument` code is returned if
+# `max_in_flight` or `max_queued` does not fit in a `size_t`. This should be called before the
+# context is shared between threads.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+proc eth_kzg_das_context_set_admission_limits*(ctx: ptr DASContext,
+                                              max_in_flight: uint64,
+                                              max_queued: uint64,
+                                              queue_timeout_millis: uint64): CResult {.importc: "eth_kzg_das_context_set_admission_limits".}
+
+## Remember the last `capacity` cell openings that `eth_kzg_verify_cell_kzg_proof_batch`
+# verified, so that verifying them again returns without any pairing. Setting `capacity`
+# to 0 disables the cache, which is the default.
+#
+# Consensus clients receive the same data column sidecars from several peers and on several
+# topics. Only openings that verified are remembered, so a batch with an opening that did
+# not verify is still rejected. Setting the cache empties it and resets its counters.
+# An error with the `InvalidArgument` code is returned if `capacity` does not fit in a
+# `size_t`. This should be called before the context is shared between threads.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer and that it is not being used by another thread.
+proc eth_kzg_das_context_set_verification_cache*(ctx: ptr DASContext, capacity: uint64): CResult {.importc: "eth_kzg_das_context_set_verification_cache".}
+
+## Write the number of cell openings that were found in the verification cache of the
+# DASContext, and the number that were not, to `out_hits` and `out_misses`.
+#
+# Both are 0 if the cache is disabled. See `eth_kzg_das_context_set_verification_cache`.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+# - The caller must ensure that `out_hits` and `out_misses` are valid pointers.
+proc eth_kzg_das_context_verification_cache_stats*(ctx: ptr DASContext,
+                                                  out_hits: ptr uint64,
+                                                  out_misses: ptr uint64): CResult {.importc: "eth_kzg_das_context_verification_cache_stats".}
+
+## Create a new DASContext that serves the methods of every fork in `forks`, loading the
+# trusted setup from the environment like `eth_kzg_das_context_new_from_env`.
+#
+# `forks` is a combination of `ETH_KZG_FORK_DENEB` and `ETH_KZG_FORK_FULU`. A single context
+# serves both forks from one copy of the trusted setup, so clients that straddle the fork
+# should create one context with both flags instead of one context per fork. Without
+# `ETH_KZG_FORK_FULU`, the tables that compute cells are skipped and the cell methods that
+# prove return an error with the `VerifierOnlyContext` code, while the cell methods that
+# verify keep working. The blob methods are served by every fork since Deneb, so an error
+# with the `InvalidArgument` code is returned if `forks` does not include `ETH_KZG_FORK_DENEB`
+# or has unknown flags. On success, a pointer to the new context is written to `out`.
+#
+# # Safety
+#
+# - The caller must ensure that `out` is a valid pointer.
+#
+# # Memory faults
+#
+# To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+# by calling `eth_kzg_das_context_free`.
+proc eth_kzg_das_context_new_for_forks*(forks: uint32,
+                                       precomp_width: uint,
+                                       outx: ptr ptr DASContext): CResult {.importc: "eth_kzg_das_context_new_for_forks".}
+
+## Run known-answer tests against the trusted setup and precomputed tables of the DASContext.
+#
+# This detects a corrupted setup or precomputation file, or a bad build, and is meant to be
+# called once at startup. It takes about as long as computing the cells and proofs of a few blobs.
+# The known answers are for the mainnet trusted setup.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+proc eth_kzg_das_context_self_test*(ctx: ptr DASContext): CResult {.importc: "eth_kzg_das_context_self_test".}
+
 ## # Safety
 #
 # - The caller must ensure that the pointer is valid. If the pointer is null, this method will return early.
@@ -39,6 +335,42 @@ proc eth_kzg_das_context_new*(use_precomp: bool): ptr DASContext {.importc: "eth
 #   a pointer that was not created by `eth_kzg_das_context_new`.
 proc eth_kzg_das_context_free*(ctx: ptr DASContext): void {.importc: "eth_kzg_das_context_free".}
 
+## Create the scratch buffers for computing the cells and proofs of a blob with the DASContext.
+#
+# On success, a pointer to the new scratch is written to `out`. An error with the
+# `VerifierOnlyContext` code is returned if the context cannot compute cells, for example
+# because it was created by `eth_kzg_das_context_new_for_forks` without `ETH_KZG_FORK_FULU`.
+#
+# The scratch can outlive the context, but it can only be used with the context that it was
+# created from.
+#
+# # Safety
+#
+# - The caller must ensure that `ctx` is a valid pointer to a DASContext.
+# - The caller must ensure that `out` is a valid pointer.
+#
+# # Memory faults
+#
+# To avoid memory leaks, one should ensure that the pointer written to `out` is freed after use
+# by calling `eth_kzg_das_scratch_free`.
+proc eth_kzg_das_scratch_new*(ctx: ptr DASContext,
+                              outx: ptr ptr DASScratch): CResult {.importc: "eth_kzg_das_scratch_new".}
+
+## # Safety
+#
+# - The caller must ensure that the pointer is valid. If the pointer is null, this method will return early.
+# - The caller should also avoid a double-free by setting the pointer to null after calling this method.
+#
+# # Memory faults
+#
+# - If this method is called twice on the same pointer, it will result in a double-free.
+#
+# # Undefined behavior
+#
+# - Since the `scratch` is created in Rust, we can only get undefined behavior, if the caller passes in
+#   a pointer that was not created by `eth_kzg_das_scratch_new`.
+proc eth_kzg_das_scratch_free*(scratch: ptr DASScratch): void {.importc: "eth_kzg_das_scratch_free".}
+
 ## Free the memory allocated for the error message.
 #
 # # Safety
@@ -57,8 +389,8 @@ proc eth_kzg_free_error_message*(c_message: pointer): void {.importc: "eth_kzg_f
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_blob_to_kzg_commitment*(ctx: ptr DASContext,
                                      blob: pointer,
                                      outx: pointer): CResult {.importc: "eth_kzg_blob_to_kzg_commitment".}
@@ -67,7 +399,7 @@ proc eth_kzg_blob_to_kzg_commitment*(ctx: ptr DASContext,
 #
 # # Safety
 #
-# - The caller must ensure that the pointers are valid. If pointers are null.
+# - The caller must ensure that the pointers are valid.
 # - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
 # - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
 #   and that each element is at least `BYTES_PER_CELL` bytes.
@@ -76,8 +408,8 @@ proc eth_kzg_blob_to_kzg_commitment*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_compute_cells_and_kzg_proofs*(ctx: ptr DASContext,
                                            blob: pointer,
                                            out_cells: ptr pointer,
@@ -87,19 +419,72 @@ proc eth_kzg_compute_cells_and_kzg_proofs*(ctx: ptr DASContext,
 #
 # # Safety
 #
-# - The caller must ensure that the pointers are valid. If pointers are null.
+# - The caller must ensure that the pointers are valid.
 # - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
 # - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
 #   and that each element is at least `BYTES_PER_CELL` bytes.
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_compute_cells*(ctx: ptr DASContext,
                             blob: pointer,
                             out_cells: ptr pointer): CResult {.importc: "eth_kzg_compute_cells".}
 
+## Computes the cells and KZG proofs for a given blob, like `eth_kzg_compute_cells_and_kzg_proofs`,
+# using the buffers of `scratch` for every intermediate value.
+#
+# No large allocations are made, and the context is only read, so several threads can call
+# this with the same context at the same time, as long as each has its own scratch. An error
+# with the `InvalidArgument` code is returned if `scratch` was created from another context.
+#
+# # Safety
+#
+# - The caller must ensure that the pointers are valid.
+# - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+#   used by another thread during the call.
+# - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
+# - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+#   and that each element is at least `BYTES_PER_CELL` bytes.
+# - The caller must ensure that `out_proofs` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+#   and that each element is at least `BYTES_PER_COMMITMENT` bytes.
+#
+# # Undefined behavior
+#
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+proc eth_kzg_compute_cells_and_kzg_proofs_with_scratch*(ctx: ptr DASContext,
+                                                        scratch: ptr DASScratch,
+                                                        blob: pointer,
+                                                        out_cells: ptr pointer,
+                                                        out_proofs: ptr pointer): CResult {.importc: "eth_kzg_compute_cells_and_kzg_proofs_with_scratch".}
+
+## Computes the cells for a given blob, like `eth_kzg_compute_cells`, using the buffers of
+# `scratch` for every intermediate value.
+#
+# No large allocations are made, and the context is only read, so several threads can call
+# this with the same context at the same time, as long as each has its own scratch. An error
+# with the `InvalidArgument` code is returned if `scratch` was created from another context.
+#
+# # Safety
+#
+# - The caller must ensure that the pointers are valid.
+# - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+#   used by another thread during the call.
+# - The caller must ensure that `blob` points to a region of memory that is at least `BYTES_PER_BLOB` bytes.
+# - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` elements
+#   and that each element is at least `BYTES_PER_CELL` bytes.
+#
+# # Undefined behavior
+#
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+proc eth_kzg_compute_cells_with_scratch*(ctx: ptr DASContext,
+                                         scratch: ptr DASScratch,
+                                         blob: pointer,
+                                         out_cells: ptr pointer): CResult {.importc: "eth_kzg_compute_cells_with_scratch".}
+
 ## Verifies a batch of cells and their KZG proofs.
 #
 # # Safety
@@ -123,8 +508,8 @@ proc eth_kzg_compute_cells*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_verify_cell_kzg_proof_batch*(ctx: ptr DASContext,
                                           commitments_length: uint64,
                                           commitments: ptr pointer,
@@ -155,8 +540,62 @@ proc eth_kzg_verify_cell_kzg_proof_batch*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+proc eth_kzg_recover_cells_and_kzg_proofs*(ctx: ptr DASContext,
+                                           cells_length: uint64,
+                                           cells: ptr pointer,
+                                           cell_indices_length: uint64,
+                                           cell_indices: pointer,
+                                           out_cells: ptr pointer,
+                                           out_proofs: ptr pointer): CResult {.importc: "eth_kzg_recover_cells_and_kzg_proofs".}
+
+## Recovers all cells and their KZG proofs from the given cell indices and cells, like
+# `eth_kzg_recover_cells_and_kzg_proofs`, using the buffers of `scratch` for the proofs.
+#
+# The context is only read, so several threads can call this with the same context at the
+# same time, as long as each has its own scratch. The erasure decoding still allocates its
+# own buffers, which are about as large as an extended blob. An error with the
+# `InvalidArgument` code is returned if `scratch` was created from another context.
+#
+# # Safety
+#
+#  - If the length parameter for a pointer is set to zero, then this implementation will not check if its pointer is
+#    null. This is because the caller might have passed in a null pointer, if the length is zero. Instead an empty slice will be created.
+#
+# - The caller must ensure that the pointers are valid.
+# - The caller must ensure that `scratch` was created by `eth_kzg_das_scratch_new`, and is not
+#   used by another thread during the call.
+# - The caller must ensure that `cells` points to a region of memory that is at least `cells_length` cells
+#   and that each cell is at least `BYTES_PER_CELL` bytes.
+# - The caller must ensure that `cell_indices` points to a region of memory that is at least `cell_indices_length` cell indices
+#   and that each cell id is 8 bytes.
+# - The caller must ensure that `out_cells` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` cells
+#   and that each cell is at least `BYTES_PER_CELL` bytes.
+# - The caller must ensure that `out_proofs` points to a region of memory that is at least `CELLS_PER_EXT_BLOB` proofs
+#   and that each proof is at least `BYTES_PER_COMMITMENT` bytes.
+#
+# # Undefined behavior
+#
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
+proc eth_kzg_recover_cells_and_kzg_proofs_with_scratch*(ctx: ptr DASContext,
+                                                        scratch: ptr DASScratch,
+                                                        cells_length: uint64,
+                                                        cells: ptr pointer,
+                                                        cell_indices_length: uint64,
+                                                        cell_indices: pointer,
+                                                        out_cells: ptr pointer,
+                                                        out_proofs: ptr pointer): CResult {.importc: "eth_kzg_recover_cells_and_kzg_proofs_with_scratch".}
+
+## Recovers all cells and their KZG proofs from the given cell indices and cells
+#
+# Deprecated: this is the same as `eth_kzg_recover_cells_and_kzg_proofs`, which matches
+# the name used by the consensus specs.
+#
+# # Safety
+#
+# - See `eth_kzg_recover_cells_and_kzg_proofs`.
 proc eth_kzg_recover_cells_and_proofs*(ctx: ptr DASContext,
                                        cells_length: uint64,
                                        cells: ptr pointer,
@@ -165,6 +604,14 @@ proc eth_kzg_recover_cells_and_proofs*(ctx: ptr DASContext,
                                        out_cells: ptr pointer,
                                        out_proofs: ptr pointer): CResult {.importc: "eth_kzg_recover_cells_and_proofs".}
 
+## Check that this library implements the version of the C ABI that the caller was built against.
+#
+# Bindings should call this once when they are loaded, with the `ETH_KZG_ABI_VERSION_MAJOR`
+# and `ETH_KZG_ABI_VERSION_MINOR` of the header they were generated from. An error with the
+# `AbiVersionMismatch` code is returned if the major versions differ, or if the library is
+# older than the caller, ie it may be missing some of the symbols the caller uses.
+proc eth_kzg_abi_negotiate*(major: uint32, minor: uint32): CResult {.importc: "eth_kzg_abi_negotiate".}
+
 proc eth_kzg_constant_bytes_per_cell*(): uint64 {.importc: "eth_kzg_constant_bytes_per_cell".}
 
 proc eth_kzg_constant_bytes_per_proof*(): uint64 {.importc: "eth_kzg_constant_bytes_per_proof".}
@@ -183,8 +630,8 @@ proc eth_kzg_constant_cells_per_ext_blob*(): uint64 {.importc: "eth_kzg_constant
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_compute_kzg_proof*(ctx: ptr DASContext,
                                 blob: pointer,
                                 z: pointer,
@@ -202,8 +649,8 @@ proc eth_kzg_compute_kzg_proof*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_compute_blob_kzg_proof*(ctx: ptr DASContext,
                                      blob: pointer,
                                      commitment: pointer,
@@ -222,8 +669,8 @@ proc eth_kzg_compute_blob_kzg_proof*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_verify_kzg_proof*(ctx: ptr DASContext,
                                commitment: pointer,
                                z: pointer,
@@ -243,8 +690,8 @@ proc eth_kzg_verify_kzg_proof*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_verify_blob_kzg_proof*(ctx: ptr DASContext,
                                     blob: pointer,
                                     commitment: pointer,
@@ -270,8 +717,8 @@ proc eth_kzg_verify_blob_kzg_proof*(ctx: ptr DASContext,
 #
 # # Undefined behavior
 #
-# - This implementation will check if the ctx pointer is null, but it will not check if the other arguments are null.
-#   If the other arguments are null, this method will dereference a null pointer and result in undefined behavior.
+# - This implementation will return an `InvalidArgument` error if any of the pointers are null, except those of empty slices.
+#   If the pointers are not null but do not point to regions of memory of the documented sizes, this method will result in undefined behavior.
 proc eth_kzg_verify_blob_kzg_proof_batch*(ctx: ptr DASContext,
                                           blobs_length: uint64,
                                           blobs: ptr pointer,
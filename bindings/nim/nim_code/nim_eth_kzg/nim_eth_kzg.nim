@@ -34,6 +34,47 @@ type
     cells*: Cells
     proofs*: array[CELLS_PER_EXT_BLOB, KZGProof]
 
+  # The stable codes of the errors returned by the library. The values match
+  # `rust_eth_kzg::ErrorCode`, and each error message starts with the code and its
+  # name, ie `E104 G1PointNotInSubgroup: ...`.
+  ErrorCode* {.pure.} = enum
+    NonCanonicalScalar = 101
+    InvalidG1PointEncoding = 102
+    G1PointNotOnCurve = 103
+    G1PointNotInSubgroup = 104
+    ScalarHasInvalidLength = 105
+    BlobHasInvalidLength = 106
+    G1PointHasInvalidLength = 107
+    CellIndexOutOfRange = 201
+    CommitmentIndexOutOfRange = 202
+    BatchLengthMismatch = 203
+    NumCellIndicesNotEqualToNumCells = 204
+    NotEnoughCellsToReconstruct = 205
+    TooManyCellsReceived = 206
+    CellIndicesNotUniquelyOrdered = 207
+    InvalidCellLength = 208
+    InvalidProof = 301
+    PolynomialHasInvalidLength = 401
+    PolynomialHasTooManyCoefficients = 402
+    TooManyBlockErasures = 403
+    BlockIndexOutOfRange = 404
+    TrustedSetupNotEnoughPoints = 501
+    TrustedSetupFirstPointIsNotGenerator = 502
+    TrustedSetupG1PointNotInSubgroup = 503
+    TrustedSetupG2PointNotInSubgroup = 504
+    TrustedSetupInconsistentG1Powers = 505
+    TrustedSetupInconsistentG2Powers = 506
+    Cancelled = 601
+    VerifierOnlyContext = 602
+    Overloaded = 603
+    AdmissionTimedOut = 604
+    InvalidArgument = 901
+    TrustedSetupUnreadable = 902
+    TrustedSetupMalformed = 903
+    ThreadPoolCreationFailed = 904
+    SelfTestFailed = 905
+    AbiVersionMismatch = 906
+
 
 template getPtr(x: untyped): auto =
   when (NimMajor, NimMinor) <= (1,6):
@@ -65,12 +106,21 @@ template toPtrPtr(cells: openArray[untyped]): ptr pointer =
 
 template verify_result(res: CResult, ret: untyped): untyped =
   if res.xstatus != CResultStatus.Ok:
-    let errorMsg = $res
+    let errorMsg = $cast[cstring](res.xerror_msg)
     eth_kzg_free_error_message(res.xerror_msg)
     return err(errorMsg)
   ok(ret)
 
 
+# Fail when the module is loaded if the library implements another version of the C ABI,
+# rather than on the first call to a symbol that is missing or has changed.
+block:
+  let res = eth_kzg_abi_negotiate(ETH_KZG_ABI_VERSION_MAJOR, ETH_KZG_ABI_VERSION_MINOR)
+  if res.xstatus != CResultStatus.Ok:
+    let errorMsg = $cast[cstring](res.xerror_msg)
+    eth_kzg_free_error_message(res.xerror_msg)
+    raiseAssert errorMsg
+
 type
   KZGCtx* = ref object
     ctx_ptr: ptr DASContext
@@ -89,6 +139,16 @@ proc newKZGCtx*(use_precomp: bool = true): KZGCtx =
   kzgCtx.ctx_ptr = eth_kzg_das_context_new(use_precomp)
   return kzgCtx
 
+# Run the context on a dedicated thread pool with `num_threads` threads,
+# instead of one thread per CPU. Passing 1 makes the context fully single-threaded.
+proc setNumThreads*(ctx: KZGCtx, num_threads: uint): Result[void, string] =
+  let res = eth_kzg_das_context_set_num_threads(ctx.ctx_ptr, num_threads)
+  if res.xstatus != CResultStatus.Ok:
+    let errorMsg = $cast[cstring](res.xerror_msg)
+    eth_kzg_free_error_message(res.xerror_msg)
+    return err(errorMsg)
+  ok()
+
 
 proc blobToKZGCommitment*(ctx: KZGCtx, blob : Blob): Result[KZGCommitment, string] {.gcsafe.} =
   var ret: KZGCommitment
@@ -171,7 +231,7 @@ proc recoverCellsAndProofs*(ctx: KZGCtx,
   let outProofsPtr = toPtrPtr(ret.proofs)
   let inputCellsPtr = toPtrPtr(cells)
 
-  let res = eth_kzg_recover_cells_and_proofs(
+  let res = eth_kzg_recover_cells_and_kzg_proofs(
     ctx.ctx_ptr,
 
     uint64(len(cells)),
@@ -1,5 +1,7 @@
 import {
+  BACKEND,
   DasContextJs,
+  MAX_NUM_COLUMNS,
 } from "../index.js";
 
 import { readFileSync } from "fs";
@@ -68,6 +70,12 @@ function assertBytesEqual(a: Uint8Array | Buffer, b: Uint8Array | Buffer): void
   }
 }
 
+describe("Backend", () => {
+  it("is the one that was asked for", () => {
+    expect(BACKEND).toBe(process.env.NODE_ETH_KZG_BACKEND === "wasm" ? "wasm" : "native");
+  });
+});
+
 describe("Spec tests", () => {
   const ctx = new DasContextJs();
 
@@ -323,3 +331,40 @@ describe("Spec tests", () => {
     });
   });
 });
+
+describe("Cells and recovery", () => {
+  const ctx = new DasContextJs();
+  const testFile = globSync(COMPUTE_CELLS_AND_KZG_PROOFS_TESTS).sort()[0];
+  const test: ComputeCellsAndKzgProofsTest = yaml.load(readFileSync(testFile, "ascii"));
+  const blob = bytesFromHex(test.input.blob);
+
+  it("async variants match the sync ones", async () => {
+    const cells = ctx.computeCells(blob);
+    const asyncCells = await ctx.asyncComputeCells(blob);
+    expect(asyncCells.length).toBe(MAX_NUM_COLUMNS);
+    for (let i = 0; i < cells.length; i++) {
+      assertBytesEqual(asyncCells[i], cells[i]);
+    }
+
+    const half = [...Array(MAX_NUM_COLUMNS / 2).keys()].map((i) => 2 * i);
+    const recovered = ctx.recoverCellsAndKzgProofs(half, half.map((i) => cells[i]));
+    const asyncRecovered = await ctx.asyncRecoverCellsAndKzgProofs(
+      half.map((i) => BigInt(i)),
+      half.map((i) => cells[i])
+    );
+    const { proofs } = ctx.computeCellsAndKzgProofs(blob);
+    for (let i = 0; i < MAX_NUM_COLUMNS; i++) {
+      assertBytesEqual(recovered.cells[i], cells[i]);
+      assertBytesEqual(recovered.proofs[i], proofs[i]);
+      assertBytesEqual(asyncRecovered.cells[i], cells[i]);
+      assertBytesEqual(asyncRecovered.proofs[i], proofs[i]);
+    }
+  });
+
+  it("rejects cell indices that are not unsigned 64 bit integers", () => {
+    const cells = ctx.computeCells(blob);
+    for (const index of [BigInt(-1), BigInt(2) ** BigInt(64)]) {
+      expect(() => ctx.recoverCellsAndKzgProofs([index], [cells[0]])).toThrow(/^E901 InvalidArgument/);
+    }
+  });
+});
@@ -0,0 +1,91 @@
+/* tslint:disable */
+/* eslint-disable */
+
+/* auto-generated by NAPI-RS */
+
+export const BYTES_PER_COMMITMENT: number
+export const BYTES_PER_PROOF: number
+export const BYTES_PER_FIELD_ELEMENT: number
+export const BYTES_PER_BLOB: number
+export const MAX_NUM_COLUMNS: number
+export const BYTES_PER_CELL: number
+export interface DasContextOptions {
+  usePrecomp: boolean
+  /**
+   * Window width used for the precomputed tables when `use_precomp` is true.
+   *
+   * Defaults to the recommended width. Larger values are faster but use more memory, and
+   * values larger than 15 are rejected.
+   */
+  precompWidth?: number
+  /**
+   * Stores only the odd multiples of each point in the precomputed tables, which halves
+   * their size and makes computing cell proofs a bit slower.
+   *
+   * Defaults to false.
+   */
+  compressedPrecomp?: boolean
+  /**
+   * Number of threads used by the context.
+   *
+   * Defaults to one thread per CPU. A value of 1 makes the context fully single-threaded.
+   */
+  numThreads?: number
+}
+export class CellsAndProofs {
+  cells: Array<Uint8Array>
+  proofs: Array<Uint8Array>
+}
+export type DASContextJs = DasContextJs
+export class DasContextJs {
+  /** Creates a new context with the default options and the embedded mainnet trusted setup. */
+  constructor()
+  /** Creates a new context with the embedded mainnet trusted setup. */
+  static create(options: DasContextOptions): DasContextJs
+  /**
+   * Creates a new context, loading the trusted setup from the environment.
+   *
+   * The trusted setup is loaded from the file named by the `ETH_KZG_TRUSTED_SETUP`
+   * environment variable if it is set, then from the default locations, and otherwise
+   * the embedded mainnet trusted setup is used.
+   */
+  static createFromEnv(options: DasContextOptions): DasContextJs
+  blobToKzgCommitment(blob: Uint8Array): Uint8Array
+  asyncBlobToKzgCommitment(blob: Uint8Array): Promise<Uint8Array>
+  computeCellsAndKzgProofs(blob: Uint8Array): CellsAndProofs
+  asyncComputeCellsAndKzgProofs(blob: Uint8Array): Promise<CellsAndProofs>
+  /**
+   * Extends a blob into its `MAX_NUM_COLUMNS` cells, without computing their proofs.
+   *
+   * This is faster than `computeCellsAndKzgProofs` for callers that only need the cells,
+   * ie to build the columns of a block whose proofs they already have.
+   */
+  computeCells(blob: Uint8Array): Array<Uint8Array>
+  /** Extends a blob into its cells on the libuv thread pool, like `computeCells`. */
+  asyncComputeCells(blob: Uint8Array): Promise<Array<Uint8Array>>
+  /**
+   * Recovers every cell of a blob and their proofs from at least half of its cells.
+   *
+   * `cellIndices[i]` is the index of `cells[i]` in the extended blob, and must be below
+   * `MAX_NUM_COLUMNS`. The indices need not be sorted, but must not repeat. The cells and
+   * proofs are returned in the order of their indices, for every index.
+   */
+  recoverCellsAndKzgProofs(cellIndices: Array<number | bigint>, cells: Array<Uint8Array>): CellsAndProofs
+  /**
+   * Recovers the cells and proofs of a blob on the libuv thread pool, like
+   * `recoverCellsAndKzgProofs`.
+   */
+  asyncRecoverCellsAndKzgProofs(cellIndices: Array<number | bigint>, cells: Array<Uint8Array>): Promise<CellsAndProofs>
+  verifyCellKzgProofBatch(commitments: Array<Uint8Array>, cellIndices: Array<number | bigint>, cells: Array<Uint8Array>, proofs: Array<Uint8Array>): boolean
+  asyncVerifyCellKzgProofBatch(commitments: Array<Uint8Array>, cellIndices: Array<number | bigint>, cells: Array<Uint8Array>, proofs: Array<Uint8Array>): Promise<boolean>
+  computeKzgProof(blob: Uint8Array, z: Uint8Array): Array<Uint8Array>
+  asyncComputeKzgProof(blob: Uint8Array, z: Uint8Array): Promise<Array<Uint8Array>>
+  computeBlobKzgProof(blob: Uint8Array, commitment: Uint8Array): Uint8Array
+  asyncComputeBlobKzgProof(blob: Uint8Array, commitment: Uint8Array): Promise<Uint8Array>
+  verifyKzgProof(commitment: Uint8Array, z: Uint8Array, y: Uint8Array, proof: Uint8Array): boolean
+  asyncVerifyKzgProof(commitment: Uint8Array, z: Uint8Array, y: Uint8Array, proof: Uint8Array): Promise<boolean>
+  verifyBlobKzgProof(blob: Uint8Array, commitment: Uint8Array, proof: Uint8Array): boolean
+  asyncVerifyBlobKzgProof(blob: Uint8Array, commitment: Uint8Array, proof: Uint8Array): Promise<boolean>
+  verifyBlobKzgProofBatch(blobs: Array<Uint8Array>, commitments: Array<Uint8Array>, proofs: Array<Uint8Array>): boolean
+  asyncVerifyBlobKzgProofBatch(blobs: Array<Uint8Array>, commitments: Array<Uint8Array>, proofs: Array<Uint8Array>): Promise<boolean>
+}
@@ -1,317 +1,43 @@
-/* tslint:disable */
 /* eslint-disable */
-/* prettier-ignore */
 
-/* auto-generated by NAPI-RS */
+// Loads the native binding for the platform, and falls back to the WebAssembly build when
+// there is none, so that installing the package never needs a Rust toolchain. Setting
+// NODE_ETH_KZG_BACKEND to "wasm" loads the WebAssembly build even when there is a native one.
 
-const { existsSync, readFileSync } = require('fs')
+const { existsSync } = require('fs')
 const { join } = require('path')
 
-const { platform, arch } = process
-
-let nativeBinding = null
-let localFileExisted = false
-let loadError = null
-
-function isMusl() {
-  // For Node 10
-  if (!process.report || typeof process.report.getReport !== 'function') {
-    try {
-      const lddPath = require('child_process').execSync('which ldd').toString().trim()
-      return readFileSync(lddPath, 'utf8').includes('musl')
-    } catch (e) {
-      return true
-    }
-  } else {
-    const { glibcVersionRuntime } = process.report.getReport().header
-    return !glibcVersionRuntime
+function loadWasm() {
+  if (existsSync(join(__dirname, 'npm', 'wasm32', 'node-eth-kzg.wasm32_bg.wasm'))) {
+    return require('./npm/wasm32')
   }
+  return require('@crate-crypto/node-eth-kzg-wasm32')
 }
 
-switch (platform) {
-  case 'android':
-    switch (arch) {
-      case 'arm64':
-        localFileExisted = existsSync(join(__dirname, 'node-eth-kzg.android-arm64.node'))
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.android-arm64.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-android-arm64')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      case 'arm':
-        localFileExisted = existsSync(join(__dirname, 'node-eth-kzg.android-arm-eabi.node'))
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.android-arm-eabi.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-android-arm-eabi')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      default:
-        throw new Error(`Unsupported architecture on Android ${arch}`)
-    }
-    break
-  case 'win32':
-    switch (arch) {
-      case 'x64':
-        localFileExisted = existsSync(
-          join(__dirname, 'node-eth-kzg.win32-x64-msvc.node')
-        )
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.win32-x64-msvc.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-win32-x64-msvc')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      case 'ia32':
-        localFileExisted = existsSync(
-          join(__dirname, 'node-eth-kzg.win32-ia32-msvc.node')
-        )
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.win32-ia32-msvc.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-win32-ia32-msvc')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      case 'arm64':
-        localFileExisted = existsSync(
-          join(__dirname, 'node-eth-kzg.win32-arm64-msvc.node')
-        )
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.win32-arm64-msvc.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-win32-arm64-msvc')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      default:
-        throw new Error(`Unsupported architecture on Windows: ${arch}`)
-    }
-    break
-  case 'darwin':
-    localFileExisted = existsSync(join(__dirname, 'node-eth-kzg.darwin-universal.node'))
+let binding = null
+let backend = null
+
+if (process.env.NODE_ETH_KZG_BACKEND === 'wasm') {
+  binding = loadWasm()
+  backend = 'wasm'
+} else {
+  try {
+    binding = require('./binding.js')
+    backend = 'native'
+  } catch (nativeError) {
     try {
-      if (localFileExisted) {
-        nativeBinding = require('./node-eth-kzg.darwin-universal.node')
-      } else {
-        nativeBinding = require('@crate-crypto/node-eth-kzg-darwin-universal')
-      }
-      break
-    } catch {}
-    switch (arch) {
-      case 'x64':
-        localFileExisted = existsSync(join(__dirname, 'node-eth-kzg.darwin-x64.node'))
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.darwin-x64.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-darwin-x64')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      case 'arm64':
-        localFileExisted = existsSync(
-          join(__dirname, 'node-eth-kzg.darwin-arm64.node')
-        )
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.darwin-arm64.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-darwin-arm64')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      default:
-        throw new Error(`Unsupported architecture on macOS: ${arch}`)
+      binding = loadWasm()
+      backend = 'wasm'
+    } catch (wasmError) {
+      // The native error says which prebuild was missing, which is the more useful of the two.
+      throw nativeError
     }
-    break
-  case 'freebsd':
-    if (arch !== 'x64') {
-      throw new Error(`Unsupported architecture on FreeBSD: ${arch}`)
-    }
-    localFileExisted = existsSync(join(__dirname, 'node-eth-kzg.freebsd-x64.node'))
-    try {
-      if (localFileExisted) {
-        nativeBinding = require('./node-eth-kzg.freebsd-x64.node')
-      } else {
-        nativeBinding = require('@crate-crypto/node-eth-kzg-freebsd-x64')
-      }
-    } catch (e) {
-      loadError = e
-    }
-    break
-  case 'linux':
-    switch (arch) {
-      case 'x64':
-        if (isMusl()) {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-x64-musl.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-x64-musl.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-x64-musl')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        } else {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-x64-gnu.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-x64-gnu.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-x64-gnu')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        }
-        break
-      case 'arm64':
-        if (isMusl()) {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-arm64-musl.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-arm64-musl.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-arm64-musl')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        } else {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-arm64-gnu.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-arm64-gnu.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-arm64-gnu')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        }
-        break
-      case 'arm':
-        if (isMusl()) {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-arm-musleabihf.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-arm-musleabihf.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-arm-musleabihf')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        } else {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-arm-gnueabihf.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-arm-gnueabihf.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-arm-gnueabihf')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        }
-        break
-      case 'riscv64':
-        if (isMusl()) {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-riscv64-musl.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-riscv64-musl.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-riscv64-musl')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        } else {
-          localFileExisted = existsSync(
-            join(__dirname, 'node-eth-kzg.linux-riscv64-gnu.node')
-          )
-          try {
-            if (localFileExisted) {
-              nativeBinding = require('./node-eth-kzg.linux-riscv64-gnu.node')
-            } else {
-              nativeBinding = require('@crate-crypto/node-eth-kzg-linux-riscv64-gnu')
-            }
-          } catch (e) {
-            loadError = e
-          }
-        }
-        break
-      case 's390x':
-        localFileExisted = existsSync(
-          join(__dirname, 'node-eth-kzg.linux-s390x-gnu.node')
-        )
-        try {
-          if (localFileExisted) {
-            nativeBinding = require('./node-eth-kzg.linux-s390x-gnu.node')
-          } else {
-            nativeBinding = require('@crate-crypto/node-eth-kzg-linux-s390x-gnu')
-          }
-        } catch (e) {
-          loadError = e
-        }
-        break
-      default:
-        throw new Error(`Unsupported architecture on Linux: ${arch}`)
-    }
-    break
-  default:
-    throw new Error(`Unsupported OS: ${platform}, architecture: ${arch}`)
-}
-
-if (!nativeBinding) {
-  if (loadError) {
-    throw loadError
   }
-  throw new Error(`Failed to load native binding`)
 }
 
-const { BYTES_PER_COMMITMENT, BYTES_PER_PROOF, BYTES_PER_FIELD_ELEMENT, BYTES_PER_BLOB, MAX_NUM_COLUMNS, BYTES_PER_CELL, CellsAndProofs, DasContextJs } = nativeBinding
+const { BYTES_PER_COMMITMENT, BYTES_PER_PROOF, BYTES_PER_FIELD_ELEMENT, BYTES_PER_BLOB, MAX_NUM_COLUMNS, BYTES_PER_CELL, CellsAndProofs, DasContextJs } = binding
 
+module.exports.BACKEND = backend
 module.exports.BYTES_PER_COMMITMENT = BYTES_PER_COMMITMENT
 module.exports.BYTES_PER_PROOF = BYTES_PER_PROOF
 module.exports.BYTES_PER_FIELD_ELEMENT = BYTES_PER_FIELD_ELEMENT
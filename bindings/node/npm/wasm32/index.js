@@ -0,0 +1,198 @@
+/* eslint-disable */
+
+// Gives the WebAssembly build the API of the native binding, so that `index.js` can load
+// either one. The module built by wasm-bindgen takes and returns the concatenation of a list
+// of cells, commitments or proofs, which this file joins and splits.
+
+const { WasmContext } = require('./node-eth-kzg.wasm32.js')
+
+const BYTES_PER_COMMITMENT = 48
+const BYTES_PER_PROOF = 48
+const BYTES_PER_FIELD_ELEMENT = 32
+const BYTES_PER_BLOB = 4096 * BYTES_PER_FIELD_ELEMENT
+const MAX_NUM_COLUMNS = 128
+const BYTES_PER_CELL = 64 * BYTES_PER_FIELD_ELEMENT
+
+const MAX_U64 = (BigInt(1) << BigInt(64)) - BigInt(1)
+
+class CellsAndProofs {
+  constructor(cells, proofs) {
+    this.cells = cells
+    this.proofs = proofs
+  }
+}
+
+function invalidArgument(message) {
+  return new Error(`E901 InvalidArgument: ${message}`)
+}
+
+function join(items, size, name) {
+  const out = new Uint8Array(items.length * size)
+  items.forEach((item, i) => {
+    if (item.length !== size) {
+      throw invalidArgument(`${name} must have size ${size}, found size ${item.length}`)
+    }
+    out.set(item, i * size)
+  })
+  return out
+}
+
+function split(bytes, size, count, offset = 0) {
+  const out = []
+  for (let i = 0; i < count; i++) {
+    out.push(bytes.slice(offset + i * size, offset + (i + 1) * size))
+  }
+  return out
+}
+
+function cellIndices(indices) {
+  return BigUint64Array.from(indices, (index) => {
+    if (typeof index === 'number' && Number.isInteger(index) && index >= 0 && index <= 0xffffffff) {
+      return BigInt(index)
+    }
+    if (typeof index === 'bigint' && index >= BigInt(0) && index <= MAX_U64) {
+      return index
+    }
+    throw invalidArgument('cell index must be an unsigned 64 bit integer')
+  })
+}
+
+function cellsAndProofs(bytes) {
+  const proofsOffset = MAX_NUM_COLUMNS * BYTES_PER_CELL
+  return new CellsAndProofs(
+    split(bytes, BYTES_PER_CELL, MAX_NUM_COLUMNS),
+    split(bytes, BYTES_PER_PROOF, MAX_NUM_COLUMNS, proofsOffset)
+  )
+}
+
+// Runs a synchronous method after the current task, and settles a promise with its result.
+// WebAssembly has no threads here, so this keeps the async methods from blocking the caller
+// until it awaits them, but not from blocking the event loop.
+function later(fn) {
+  return new Promise((resolve, reject) => {
+    setImmediate(() => {
+      try {
+        resolve(fn())
+      } catch (e) {
+        reject(e)
+      }
+    })
+  })
+}
+
+class DasContextJs {
+  constructor(options) {
+    const { usePrecomp = true, precompWidth, compressedPrecomp } = options || {}
+    this.inner = new WasmContext(usePrecomp, precompWidth, compressedPrecomp)
+  }
+
+  // numThreads is ignored, as the WebAssembly build is single-threaded.
+  static create(options) {
+    return new DasContextJs(options)
+  }
+
+  // The WebAssembly build cannot read files, so it always uses the embedded trusted setup.
+  static createFromEnv(options) {
+    return new DasContextJs(options)
+  }
+
+  blobToKzgCommitment(blob) {
+    return this.inner.blobToKzgCommitment(blob)
+  }
+
+  asyncBlobToKzgCommitment(blob) {
+    return later(() => this.blobToKzgCommitment(blob))
+  }
+
+  computeCellsAndKzgProofs(blob) {
+    return cellsAndProofs(this.inner.computeCellsAndKzgProofs(blob))
+  }
+
+  asyncComputeCellsAndKzgProofs(blob) {
+    return later(() => this.computeCellsAndKzgProofs(blob))
+  }
+
+  computeCells(blob) {
+    return split(this.inner.computeCells(blob), BYTES_PER_CELL, MAX_NUM_COLUMNS)
+  }
+
+  asyncComputeCells(blob) {
+    return later(() => this.computeCells(blob))
+  }
+
+  recoverCellsAndKzgProofs(indices, cells) {
+    const bytes = this.inner.recoverCellsAndKzgProofs(cellIndices(indices), join(cells, BYTES_PER_CELL, 'cell'))
+    return cellsAndProofs(bytes)
+  }
+
+  asyncRecoverCellsAndKzgProofs(indices, cells) {
+    return later(() => this.recoverCellsAndKzgProofs(indices, cells))
+  }
+
+  verifyCellKzgProofBatch(commitments, indices, cells, proofs) {
+    return this.inner.verifyCellKzgProofBatch(
+      join(commitments, BYTES_PER_COMMITMENT, 'commitment'),
+      cellIndices(indices),
+      join(cells, BYTES_PER_CELL, 'cell'),
+      join(proofs, BYTES_PER_PROOF, 'proof')
+    )
+  }
+
+  asyncVerifyCellKzgProofBatch(commitments, indices, cells, proofs) {
+    return later(() => this.verifyCellKzgProofBatch(commitments, indices, cells, proofs))
+  }
+
+  computeKzgProof(blob, z) {
+    const bytes = this.inner.computeKzgProof(blob, z)
+    return [bytes.slice(0, BYTES_PER_PROOF), bytes.slice(BYTES_PER_PROOF)]
+  }
+
+  asyncComputeKzgProof(blob, z) {
+    return later(() => this.computeKzgProof(blob, z))
+  }
+
+  computeBlobKzgProof(blob, commitment) {
+    return this.inner.computeBlobKzgProof(blob, commitment)
+  }
+
+  asyncComputeBlobKzgProof(blob, commitment) {
+    return later(() => this.computeBlobKzgProof(blob, commitment))
+  }
+
+  verifyKzgProof(commitment, z, y, proof) {
+    return this.inner.verifyKzgProof(commitment, z, y, proof)
+  }
+
+  asyncVerifyKzgProof(commitment, z, y, proof) {
+    return later(() => this.verifyKzgProof(commitment, z, y, proof))
+  }
+
+  verifyBlobKzgProof(blob, commitment, proof) {
+    return this.inner.verifyBlobKzgProof(blob, commitment, proof)
+  }
+
+  asyncVerifyBlobKzgProof(blob, commitment, proof) {
+    return later(() => this.verifyBlobKzgProof(blob, commitment, proof))
+  }
+
+  verifyBlobKzgProofBatch(blobs, commitments, proofs) {
+    return this.inner.verifyBlobKzgProofBatch(
+      join(blobs, BYTES_PER_BLOB, 'blob'),
+      join(commitments, BYTES_PER_COMMITMENT, 'commitment'),
+      join(proofs, BYTES_PER_PROOF, 'proof')
+    )
+  }
+
+  asyncVerifyBlobKzgProofBatch(blobs, commitments, proofs) {
+    return later(() => this.verifyBlobKzgProofBatch(blobs, commitments, proofs))
+  }
+}
+
+module.exports.BYTES_PER_COMMITMENT = BYTES_PER_COMMITMENT
+module.exports.BYTES_PER_PROOF = BYTES_PER_PROOF
+module.exports.BYTES_PER_FIELD_ELEMENT = BYTES_PER_FIELD_ELEMENT
+module.exports.BYTES_PER_BLOB = BYTES_PER_BLOB
+module.exports.MAX_NUM_COLUMNS = MAX_NUM_COLUMNS
+module.exports.BYTES_PER_CELL = BYTES_PER_CELL
+module.exports.CellsAndProofs = CellsAndProofs
+module.exports.DasContextJs = DasContextJs
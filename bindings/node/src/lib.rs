@@ -1,4 +1,4 @@
-use std::sync::Arc;
+use std::{panic::catch_unwind, sync::Arc};
 
 use napi::{
   bindgen_prelude::{BigInt, Error, Uint8Array},
@@ -8,7 +8,7 @@ use napi_derive::napi;
 
 use rust_eth_kzg::{
   constants::{self, RECOMMENDED_PRECOMP_WIDTH},
-  DASContext, TrustedSetup, UsePrecomp,
+  DASContext, ErrorCode, TrustedSetup, UsePrecomp,
 };
 
 #[napi]
@@ -35,45 +35,81 @@ pub struct DASContextJs {
   inner: Arc<DASContext>,
 }
 
-impl Default for DASContextJs {
-  fn default() -> Self {
-    Self::new()
-  }
-}
-
 #[napi(object)]
 pub struct DASContextOptions {
   pub use_precomp: bool,
+  /// Window width used for the precomputed tables when `use_precomp` is true.
+  ///
+  /// Defaults to the recommended width. Larger values are faster but use more memory, and
+  /// values larger than 15 are rejected.
+  pub precomp_width: Option<u32>,
+  /// Stores only the odd multiples of each point in the precomputed tables, which halves
+  /// their size and makes computing cell proofs a bit slower.
+  ///
+  /// Defaults to false.
+  pub compressed_precomp: Option<bool>,
+  /// Number of threads used by the context.
+  ///
+  /// Defaults to one thread per CPU. A value of 1 makes the context fully single-threaded.
+  pub num_threads: Option<u32>,
 }
 
 impl Default for DASContextOptions {
   fn default() -> Self {
-    Self { use_precomp: true }
+    Self {
+      use_precomp: true,
+      precomp_width: None,
+      compressed_precomp: None,
+      num_threads: None,
+    }
+  }
+}
+
+impl Default for DASContextJs {
+  fn default() -> Self {
+    Self::new()
   }
 }
 
 #[napi]
 impl DASContextJs {
+  /// Creates a new context with the default options and the embedded mainnet trusted setup.
   #[napi(constructor)]
   pub fn new() -> Self {
-    Self::create(DASContextOptions::default())
+    Self::create(DASContextOptions::default()).expect("the default options are valid")
   }
 
+  /// Creates a new context with the embedded mainnet trusted setup.
   #[napi(factory)]
-  pub fn create(options: DASContextOptions) -> Self {
-    let use_precomp = options.use_precomp;
+  pub fn create(options: DASContextOptions) -> Result<Self> {
+    Self::with_trusted_setup(&TrustedSetup::default(), options)
+  }
 
-    let precomp = if use_precomp {
-      UsePrecomp::Yes {
-        width: RECOMMENDED_PRECOMP_WIDTH,
+  /// Creates a new context, loading the trusted setup from the environment.
+  ///
+  /// The trusted setup is loaded from the file named by the `ETH_KZG_TRUSTED_SETUP`
+  /// environment variable if it is set, then from the default locations, and otherwise
+  /// the embedded mainnet trusted setup is used.
+  #[napi(factory)]
+  pub fn create_from_env(options: DASContextOptions) -> Result<Self> {
+    // The trusted setup parsers panic on malformed input, which must not unwind into node.
+    let trusted_setup = match catch_unwind(TrustedSetup::from_env) {
+      Ok(Ok(trusted_setup)) => trusted_setup,
+      Ok(Err(err)) => {
+        return Err(Error::from_reason(format!(
+          "{}: failed to load trusted setup: {err}",
+          ErrorCode::TrustedSetupUnreadable
+        )))
+      }
+      Err(_) => {
+        return Err(Error::from_reason(format!(
+          "{}: trusted setup is malformed",
+          ErrorCode::TrustedSetupMalformed
+        )))
       }
-    } else {
-      UsePrecomp::No
     };
 
-    DASContextJs {
-      inner: Arc::new(DASContext::new(&TrustedSetup::default(), precomp)),
-    }
+    Self::with_trusted_setup(&trusted_setup, options)
   }
 
   #[napi]
@@ -82,9 +118,9 @@ impl DASContextJs {
     let ctx = &self.inner;
     let blob = slice_to_array_ref(blob, "blob")?;
 
-    let commitment = ctx.blob_to_kzg_commitment(blob).map_err(|err| {
-      Error::from_reason(format!("failed to compute blob_to_kzg_commitment: {err:?}",))
-    })?;
+    let commitment = ctx
+      .blob_to_kzg_commitment(blob)
+      .map_err(|err| kzg_error("blob_to_kzg_commitment", &err))?;
     Ok(Uint8Array::from(&commitment))
   }
 
@@ -100,11 +136,9 @@ impl DASContextJs {
 
     let blob = slice_to_array_ref(blob, "blob")?;
 
-    let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(blob).map_err(|err| {
-      Error::from_reason(format!(
-        "failed to compute compute_cells_and_kzg_proofs: {err:?}"
-      ))
-    })?;
+    let (cells, proofs) = ctx
+      .compute_cells_and_kzg_proofs(blob)
+      .map_err(|err| kzg_error("compute_cells_and_kzg_proofs", &err))?;
 
     let cells_uint8array = cells
       .into_iter()
@@ -129,6 +163,10 @@ impl DASContextJs {
     self.compute_cells_and_kzg_proofs(blob)
   }
 
+  /// Extends a blob into its `MAX_NUM_COLUMNS` cells, without computing their proofs.
+  ///
+  /// This is faster than `computeCellsAndKzgProofs` for callers that only need the cells,
+  /// ie to build the columns of a block whose proofs they already have.
   #[napi]
   pub fn compute_cells(&self, blob: Uint8Array) -> Result<Vec<Uint8Array>> {
     let blob = blob.as_ref();
@@ -138,7 +176,7 @@ impl DASContextJs {
 
     let cells = ctx
       .compute_cells(blob)
-      .map_err(|err| Error::from_reason(format!("failed to compute compute_cells: {err:?}")))?;
+      .map_err(|err| kzg_error("compute_cells", &err))?;
 
     let cells_uint8array = cells
       .into_iter()
@@ -148,19 +186,27 @@ impl DASContextJs {
     Ok(cells_uint8array)
   }
 
+  /// Extends a blob into its cells on the libuv thread pool, like `computeCells`.
   #[napi]
   pub async fn async_compute_cells(&self, blob: Uint8Array) -> Result<Vec<Uint8Array>> {
     self.compute_cells(blob)
   }
 
-  #[allow(deprecated)]
+  /// Recovers every cell of a blob and their proofs from at least half of its cells.
+  ///
+  /// `cellIndices[i]` is the index of `cells[i]` in the extended blob, and must be below
+  /// `MAX_NUM_COLUMNS`. The indices need not be sorted, but must not repeat. The cells and
+  /// proofs are returned in the order of their indices, for every index.
   #[napi]
   pub fn recover_cells_and_kzg_proofs(
     &self,
     cell_indices: Vec<Either<u32, BigInt>>,
     cells: Vec<Uint8Array>,
   ) -> Result<CellsAndProofs> {
-    let cell_indices: Vec<_> = cell_indices.into_iter().map(u32_or_bigint_to_u64).collect();
+    let cell_indices: Vec<_> = cell_indices
+      .into_iter()
+      .map(u32_or_bigint_to_u64)
+      .collect::<Result<_, _>>()?;
     let cells: Vec<_> = cells.iter().map(|cell| cell.as_ref()).collect();
 
     let ctx = &self.inner;
@@ -172,11 +218,7 @@ impl DASContextJs {
 
     let (cells, proofs) = ctx
       .recover_cells_and_kzg_proofs(cell_indices, cells)
-      .map_err(|err| {
-        Error::from_reason(format!(
-          "failed to compute recover_cells_and_kzg_proofs: {err:?}"
-        ))
-      })?;
+      .map_err(|err| kzg_error("recover_cells_and_kzg_proofs", &err))?;
 
     let cells_uint8array = cells
       .into_iter()
@@ -193,6 +235,8 @@ impl DASContextJs {
     })
   }
 
+  /// Recovers the cells and proofs of a blob on the libuv thread pool, like
+  /// `recoverCellsAndKzgProofs`.
   #[napi]
   pub async fn async_recover_cells_and_kzg_proofs(
     &self,
@@ -210,7 +254,10 @@ impl DASContextJs {
     cells: Vec<Uint8Array>,
     proofs: Vec<Uint8Array>,
   ) -> Result<bool> {
-    let cell_indices: Vec<_> = cell_indices.into_iter().map(u32_or_bigint_to_u64).collect();
+    let cell_indices: Vec<_> = cell_indices
+      .into_iter()
+      .map(u32_or_bigint_to_u64)
+      .collect::<Result<_, _>>()?;
 
     let commitments: Vec<_> = commitments
       .iter()
@@ -231,9 +278,7 @@ impl DASContextJs {
     match valid {
       Ok(_) => Ok(true),
       Err(x) if x.is_proof_invalid() => Ok(false),
-      Err(err) => Err(Error::from_reason(format!(
-        "failed to compute verify_cell_kzg_proof_batch: {err:?}"
-      ))),
+      Err(err) => Err(kzg_error("verify_cell_kzg_proof_batch", &err)),
     }
   }
 
@@ -259,7 +304,7 @@ impl DASContextJs {
 
     let (proof, y) = ctx
       .compute_kzg_proof(blob, *z)
-      .map_err(|err| Error::from_reason(format!("failed to compute compute_kzg_proof: {err:?}")))?;
+      .map_err(|err| kzg_error("compute_kzg_proof", &err))?;
 
     Ok(vec![Uint8Array::from(&proof), Uint8Array::from(&y)])
   }
@@ -288,9 +333,7 @@ impl DASContextJs {
 
     let proof = ctx
       .compute_blob_kzg_proof(blob, commitment)
-      .map_err(|err| {
-        Error::from_reason(format!("failed to compute compute_blob_kzg_proof: {err:?}"))
-      })?;
+      .map_err(|err| kzg_error("compute_blob_kzg_proof", &err))?;
 
     Ok(Uint8Array::from(&proof))
   }
@@ -327,9 +370,7 @@ impl DASContextJs {
     match valid {
       Ok(_) => Ok(true),
       Err(x) if x.is_proof_invalid() => Ok(false),
-      Err(err) => Err(Error::from_reason(format!(
-        "failed to compute verify_kzg_proof: {err:?}"
-      ))),
+      Err(err) => Err(kzg_error("verify_kzg_proof", &err)),
     }
   }
 
@@ -364,9 +405,7 @@ impl DASContextJs {
     match valid {
       Ok(_) => Ok(true),
       Err(x) if x.is_proof_invalid() => Ok(false),
-      Err(err) => Err(Error::from_reason(format!(
-        "failed to compute verify_blob_kzg_proof: {err:?}"
-      ))),
+      Err(err) => Err(kzg_error("verify_blob_kzg_proof", &err)),
     }
   }
 
@@ -406,9 +445,7 @@ impl DASContextJs {
     match valid {
       Ok(_) => Ok(true),
       Err(x) if x.is_proof_invalid() => Ok(false),
-      Err(err) => Err(Error::from_reason(format!(
-        "failed to compute verify_blob_kzg_proof_batch: {err:?}"
-      ))),
+      Err(err) => Err(kzg_error("verify_blob_kzg_proof_batch", &err)),
     }
   }
 
@@ -424,13 +461,18 @@ impl DASContextJs {
 }
 
 // We use bigint because u64 cannot be used as an argument, see : https://napi.rs/docs/concepts/values.en#bigint
-fn u32_or_bigint_to_u64(value: Either<u32, BigInt>) -> u64 {
+fn u32_or_bigint_to_u64(value: Either<u32, BigInt>) -> Result<u64> {
   match value {
-    Either::A(v) => v as u64,
+    Either::A(v) => Ok(v as u64),
     Either::B(v) => {
-      let (signed, value_u128, _) = v.get_u128();
-      assert!(!signed, "value should be an unsigned integer");
-      value_u128 as u64
+      let (signed, value, lossless) = v.get_u64();
+      if signed || !lossless {
+        return Err(Error::from_reason(format!(
+          "{}: cell index must be an unsigned 64 bit integer",
+          ErrorCode::InvalidArgument
+        )));
+      }
+      Ok(value)
     }
   }
 }
@@ -445,9 +487,62 @@ fn slice_to_array_ref<'a, const N: usize>(
 ) -> Result<&'a [u8; N]> {
   slice.try_into().map_err(|err| {
     Error::from_reason(format!(
-      "{name} must have size {N}, found size {}\n err:{}",
+      "{}: {name} must have size {N}, found size {}\n err:{}",
+      ErrorCode::InvalidArgument,
       slice.len(),
       err
     ))
   })
 }
+
+impl DASContextJs {
+  /// Creates a context from `trusted_setup`, which is shared by the constructors above.
+  fn with_trusted_setup(trusted_setup: &TrustedSetup, options: DASContextOptions) -> Result<Self> {
+    let precomp = if options.use_precomp {
+      let width = options
+        .precomp_width
+        .map_or(RECOMMENDED_PRECOMP_WIDTH, |width| width as usize);
+      let precomp = if options.compressed_precomp.unwrap_or(false) {
+        UsePrecomp::compressed_from_width(width)
+      } else {
+        UsePrecomp::from_width(width)
+      };
+      precomp.ok_or_else(|| {
+        Error::from_reason(format!(
+          "{}: precompWidth must be at most {}, got {width}",
+          ErrorCode::InvalidArgument,
+          UsePrecomp::MAX_WIDTH
+        ))
+      })?
+    } else {
+      UsePrecomp::No
+    };
+
+    let mut ctx = DASContext::try_new(trusted_setup, precomp, false).map_err(|err| {
+      Error::from_reason(format!("{}: failed to create context: {err:?}", err.code()))
+    })?;
+    if let Some(num_threads) = options.num_threads {
+      ctx.set_num_threads(num_threads as usize).map_err(|err| {
+        Error::from_reason(format!(
+          "{}: failed to create thread pool: {err}",
+          ErrorCode::ThreadPoolCreationFailed
+        ))
+      })?;
+    }
+
+    Ok(DASContextJs {
+      inner: Arc::new(ctx),
+    })
+  }
+}
+
+/// Converts an error from the library into a JavaScript error.
+///
+/// The message starts with the stable code of the error, ie `E104 G1PointNotInSubgroup`,
+/// so that errors thrown in JavaScript can be matched with `rust_eth_kzg::ErrorCode`.
+fn kzg_error(operation: &str, err: &rust_eth_kzg::Error) -> Error {
+  Error::from_reason(format!(
+    "{}: failed to compute {operation}: {err:?}",
+    err.code()
+  ))
+}
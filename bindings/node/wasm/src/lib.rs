@@ -0,0 +1,265 @@
+//! The WebAssembly build of the node bindings.
+//!
+//! `index.js` loads this module when there is no native prebuild for the platform, through the
+//! adapter in `npm/wasm32/index.js`, which gives it the same API as the native `DasContextJs`.
+//! Arrays of cells, commitments and proofs cross the boundary as the concatenation of their
+//! elements, and the adapter splits them back into one `Uint8Array` per element.
+//!
+//! Build with:
+//!
+//! ```text
+//! RUSTFLAGS="-C target-feature=+simd128" CC=clang \
+//!   cargo build -p node-eth-kzg-wasm --target wasm32-unknown-unknown --release
+//! wasm-bindgen --target nodejs --out-dir bindings/node/npm/wasm32 --out-name node-eth-kzg.wasm32 \
+//!   target/wasm32-unknown-unknown/release/node_eth_kzg_wasm.wasm
+//! ```
+
+use rust_eth_kzg::{
+  constants::{
+    BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_COMMITMENT, BYTES_PER_FIELD_ELEMENT,
+    RECOMMENDED_PRECOMP_WIDTH,
+  },
+  Cell, DASContext, ErrorCode, KZGProof, TrustedSetup, UsePrecomp,
+};
+use wasm_bindgen::prelude::*;
+
+/// A context created from the embedded mainnet trusted setup.
+///
+/// WebAssembly cannot read files, so `ETH_KZG_TRUSTED_SETUP` is ignored, and it cannot spawn
+/// threads, so every method runs on the calling thread.
+#[wasm_bindgen]
+pub struct WasmContext {
+  inner: DASContext,
+}
+
+#[wasm_bindgen]
+impl WasmContext {
+  #[wasm_bindgen(constructor)]
+  pub fn new(
+    use_precomp: bool,
+    precomp_width: Option<u32>,
+    compressed_precomp: Option<bool>,
+  ) -> Result<WasmContext, JsError> {
+    let precomp = if use_precomp {
+      let width = precomp_width.map_or(RECOMMENDED_PRECOMP_WIDTH, |width| width as usize);
+      let precomp = if compressed_precomp.unwrap_or(false) {
+        UsePrecomp::compressed_from_width(width)
+      } else {
+        UsePrecomp::from_width(width)
+      };
+      precomp.ok_or_else(|| {
+        JsError::new(&format!(
+          "{}: precompWidth must be at most {}, got {width}",
+          ErrorCode::InvalidArgument,
+          UsePrecomp::MAX_WIDTH
+        ))
+      })?
+    } else {
+      UsePrecomp::No
+    };
+    Ok(Self {
+      inner: DASContext::new(&TrustedSetup::default(), precomp),
+    })
+  }
+
+  #[wasm_bindgen(js_name = blobToKzgCommitment)]
+  pub fn blob_to_kzg_commitment(&self, blob: &[u8]) -> Result<Vec<u8>, JsError> {
+    let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+
+    let commitment = self
+      .inner
+      .blob_to_kzg_commitment(blob)
+      .map_err(|err| kzg_error("blob_to_kzg_commitment", &err))?;
+    Ok(commitment.to_vec())
+  }
+
+  /// Returns the cells of the blob followed by their proofs.
+  #[wasm_bindgen(js_name = computeCellsAndKzgProofs)]
+  pub fn compute_cells_and_kzg_proofs(&self, blob: &[u8]) -> Result<Vec<u8>, JsError> {
+    let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+
+    let (cells, proofs) = self
+      .inner
+      .compute_cells_and_kzg_proofs(blob)
+      .map_err(|err| kzg_error("compute_cells_and_kzg_proofs", &err))?;
+    Ok(concat(&cells, &proofs))
+  }
+
+  #[wasm_bindgen(js_name = computeCells)]
+  pub fn compute_cells(&self, blob: &[u8]) -> Result<Vec<u8>, JsError> {
+    let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+
+    let cells = self
+      .inner
+      .compute_cells(blob)
+      .map_err(|err| kzg_error("compute_cells", &err))?;
+    Ok(concat(&cells, &[]))
+  }
+
+  /// Returns every cell of the blob followed by their proofs.
+  #[wasm_bindgen(js_name = recoverCellsAndKzgProofs)]
+  pub fn recover_cells_and_kzg_proofs(
+    &self,
+    cell_indices: Vec<u64>,
+    cells: &[u8],
+  ) -> Result<Vec<u8>, JsError> {
+    let cells = split::<BYTES_PER_CELL>(cells, "cells")?;
+
+    let (cells, proofs) = self
+      .inner
+      .recover_cells_and_kzg_proofs(cell_indices, cells)
+      .map_err(|err| kzg_error("recover_cells_and_kzg_proofs", &err))?;
+    Ok(concat(&cells, &proofs))
+  }
+
+  #[wasm_bindgen(js_name = verifyCellKzgProofBatch)]
+  pub fn verify_cell_kzg_proof_batch(
+    &self,
+    commitments: &[u8],
+    cell_indices: &[u64],
+    cells: &[u8],
+    proofs: &[u8],
+  ) -> Result<bool, JsError> {
+    let commitments = split::<BYTES_PER_COMMITMENT>(commitments, "commitments")?;
+    let cells = split::<BYTES_PER_CELL>(cells, "cells")?;
+    let proofs = split::<BYTES_PER_COMMITMENT>(proofs, "proofs")?;
+
+    let valid = self
+      .inner
+      .verify_cell_kzg_proof_batch(commitments, cell_indices, cells, proofs);
+    into_bool("verify_cell_kzg_proof_batch", valid)
+  }
+
+  /// Returns the proof followed by the evaluation `y`.
+  #[wasm_bindgen(js_name = computeKzgProof)]
+  pub fn compute_kzg_proof(&self, blob: &[u8], z: &[u8]) -> Result<Vec<u8>, JsError> {
+    let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+    let z = exact::<BYTES_PER_FIELD_ELEMENT>(z, "z")?;
+
+    let (proof, y) = self
+      .inner
+      .compute_kzg_proof(blob, *z)
+      .map_err(|err| kzg_error("compute_kzg_proof", &err))?;
+    Ok([proof.as_slice(), y.as_slice()].concat())
+  }
+
+  #[wasm_bindgen(js_name = computeBlobKzgProof)]
+  pub fn compute_blob_kzg_proof(&self, blob: &[u8], commitment: &[u8]) -> Result<Vec<u8>, JsError> {
+    let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+    let commitment = exact::<BYTES_PER_COMMITMENT>(commitment, "commitment")?;
+
+    let proof = self
+      .inner
+      .compute_blob_kzg_proof(blob, commitment)
+      .map_err(|err| kzg_error("compute_blob_kzg_proof", &err))?;
+    Ok(proof.to_vec())
+  }
+
+  #[wasm_bindgen(js_name = verifyKzgProof)]
+  pub fn verify_kzg_proof(
+    &self,
+    commitment: &[u8],
+    z: &[u8],
+    y: &[u8],
+    proof: &[u8],
+  ) -> Result<bool, JsError> {
+    let commitment = exact::<BYTES_PER_COMMITMENT>(commitment, "commitment")?;
+    let z = exact::<BYTES_PER_FIELD_ELEMENT>(z, "z")?;
+    let y = exact::<BYTES_PER_FIELD_ELEMENT>(y, "y")?;
+    let proof = exact::<BYTES_PER_COMMITMENT>(proof, "proof")?;
+
+    let valid = self.inner.verify_kzg_proof(commitment, *z, *y, proof);
+    into_bool("verify_kzg_proof", valid)
+  }
+
+  #[wasm_bindgen(js_name = verifyBlobKzgProof)]
+  pub fn verify_blob_kzg_proof(
+    &self,
+    blob: &[u8],
+    commitment: &[u8],
+    proof: &[u8],
+  ) -> Result<bool, JsError> {
+    let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+    let commitment = exact::<BYTES_PER_COMMITMENT>(commitment, "commitment")?;
+    let proof = exact::<BYTES_PER_COMMITMENT>(proof, "proof")?;
+
+    let valid = self.inner.verify_blob_kzg_proof(blob, commitment, proof);
+    into_bool("verify_blob_kzg_proof", valid)
+  }
+
+  #[wasm_bindgen(js_name = verifyBlobKzgProofBatch)]
+  pub fn verify_blob_kzg_proof_batch(
+    &self,
+    blobs: &[u8],
+    commitments: &[u8],
+    proofs: &[u8],
+  ) -> Result<bool, JsError> {
+    let blobs = split::<BYTES_PER_BLOB>(blobs, "blobs")?;
+    let commitments = split::<BYTES_PER_COMMITMENT>(commitments, "commitments")?;
+    let proofs = split::<BYTES_PER_COMMITMENT>(proofs, "proofs")?;
+
+    let valid = self
+      .inner
+      .verify_blob_kzg_proof_batch(blobs, commitments, proofs);
+    into_bool("verify_blob_kzg_proof_batch", valid)
+  }
+}
+
+/// Concatenates the cells and proofs of a blob.
+fn concat(cells: &[Cell], proofs: &[KZGProof]) -> Vec<u8> {
+  let mut bytes =
+    Vec::with_capacity(cells.len() * BYTES_PER_CELL + proofs.len() * BYTES_PER_COMMITMENT);
+  for cell in cells {
+    bytes.extend_from_slice(&cell[..]);
+  }
+  for proof in proofs {
+    bytes.extend_from_slice(proof);
+  }
+  bytes
+}
+
+/// Splits `bytes` into chunks of `N` bytes, failing if its length is not a multiple of `N`.
+fn split<'a, const N: usize>(bytes: &'a [u8], name: &str) -> Result<Vec<&'a [u8; N]>, JsError> {
+  if bytes.len() % N != 0 {
+    return Err(JsError::new(&format!(
+      "{}: {name} must be a multiple of {N} bytes, found size {}",
+      ErrorCode::InvalidArgument,
+      bytes.len()
+    )));
+  }
+  Ok(
+    bytes
+      .chunks_exact(N)
+      .map(|chunk| chunk.try_into().expect("chunk has N bytes"))
+      .collect(),
+  )
+}
+
+/// Converts `bytes` to an array of `N` bytes, failing if it has a different length.
+fn exact<'a, const N: usize>(bytes: &'a [u8], name: &str) -> Result<&'a [u8; N], JsError> {
+  bytes.try_into().map_err(|_| {
+    JsError::new(&format!(
+      "{}: {name} must have size {N}, found size {}",
+      ErrorCode::InvalidArgument,
+      bytes.len()
+    ))
+  })
+}
+
+/// Maps an invalid proof to false, and any other error to a JavaScript exception.
+fn into_bool(operation: &str, result: Result<(), rust_eth_kzg::Error>) -> Result<bool, JsError> {
+  match result {
+    Ok(()) => Ok(true),
+    Err(err) if err.is_proof_invalid() => Ok(false),
+    Err(err) => Err(kzg_error(operation, &err)),
+  }
+}
+
+/// Converts an error from the library into a JavaScript error, with the same message as the
+/// native build.
+fn kzg_error(operation: &str, err: &rust_eth_kzg::Error) -> JsError {
+  JsError::new(&format!(
+    "{}: failed to compute {operation}: {err:?}",
+    err.code()
+  ))
+}
@@ -0,0 +1,85 @@
+/// The return codes of the c-kzg C library.
+///
+/// Only `C_KZG_BADARGS` is returned by this crate, since every error of rust-eth-kzg is
+/// caused by an invalid input.
+#[allow(non_camel_case_types, clippy::upper_case_acronyms)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+#[repr(u32)]
+pub enum C_KZG_RET {
+    C_KZG_OK = 0,
+    C_KZG_BADARGS = 1,
+    C_KZG_ERROR = 2,
+    C_KZG_MALLOC = 3,
+}
+
+/// Errors that can occur while loading a trusted setup file.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum KzgErrors {
+    /// The file could not be read.
+    FailedCurrentDirectory,
+    /// The path does not exist.
+    PathNotExists,
+    /// The file could not be read.
+    IOError,
+    /// The file is not valid UTF-8 text.
+    NotValidFile,
+    /// The file does not follow the trusted setup format.
+    FileFormatError,
+    /// A number or a point in the file could not be parsed.
+    ParseError,
+    /// The number of points in the file does not match the header.
+    MismatchedNumberOfPoints,
+}
+
+/// The errors returned by this crate, with the same variants as in c-kzg.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum Error {
+    /// The input has the wrong length.
+    InvalidBytesLength(String),
+    /// The input is not valid hex.
+    InvalidHexFormat(String),
+    /// The proof is not a valid point.
+    InvalidKzgProof(String),
+    /// The commitment is not a valid point.
+    InvalidKzgCommitment(String),
+    /// The trusted setup has the wrong number of points, or a point is invalid.
+    InvalidTrustedSetup(String),
+    /// The inputs of a batch have different lengths.
+    MismatchLength(String),
+    /// The trusted setup file could not be loaded.
+    LoadingTrustedSetupFailed(KzgErrors),
+    /// The library rejected the inputs.
+    CError(C_KZG_RET),
+}
+
+impl std::fmt::Display for Error {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            Self::InvalidBytesLength(s)
+            | Self::InvalidHexFormat(s)
+            | Self::InvalidKzgProof(s)
+            | Self::InvalidKzgCommitment(s)
+            | Self::InvalidTrustedSetup(s)
+            | Self::MismatchLength(s) => f.write_str(s),
+            Self::LoadingTrustedSetupFailed(s) => write!(f, "KzgErrors: {s:?}"),
+            Self::CError(s) => write!(f, "{s:?}"),
+        }
+    }
+}
+
+impl std::error::Error for Error {}
+
+impl From<rust_eth_kzg::Error> for Error {
+    fn from(_: rust_eth_kzg::Error) -> Self {
+        Self::CError(C_KZG_RET::C_KZG_BADARGS)
+    }
+}
+
+/// Maps the result of a verification to whether the proof was valid, as c-kzg does.
+pub(crate) fn verified(result: Result<(), rust_eth_kzg::Error>) -> Result<bool, Error> {
+    match result {
+        Ok(()) => Ok(true),
+        Err(err) if err.is_proof_invalid() => Ok(false),
+        Err(err) => Err(err.into()),
+    }
+}
@@ -0,0 +1,41 @@
+//! A drop-in replacement for the Rust API of c-kzg, backed by rust-eth-kzg.
+//!
+//! The types and methods have the same names and signatures as in c-kzg 2.1, so that a
+//! client can switch between the two libraries with a `[patch.crates-io]` entry:
+//!
+//! ```ignore
+//! let settings = c_kzg::ethereum_kzg_settings(0);
+//! let commitment = settings.blob_to_kzg_commitment(&blob)?;
+//! let proof = settings.compute_blob_kzg_proof(&blob, &commitment.to_bytes())?;
+//! assert!(settings.verify_blob_kzg_proof(&blob, &commitment.to_bytes(), &proof.to_bytes())?);
+//! ```
+//!
+//! See the README for the few places where the behaviour differs.
+
+mod errors;
+mod settings;
+mod types;
+
+pub use errors::{Error, KzgErrors, C_KZG_RET};
+pub use rust_eth_kzg::constants::{
+    BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_COMMITMENT, BYTES_PER_FIELD_ELEMENT,
+    CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_BLOB, FIELD_ELEMENTS_PER_CELL,
+    FIELD_ELEMENTS_PER_EXT_BLOB,
+};
+pub use settings::{ethereum_kzg_settings, ethereum_kzg_settings_arc, KzgSettings};
+pub use types::{Blob, Bytes32, Bytes48, Cell, KzgCommitment, KzgProof};
+
+/// The number of bytes in a KZG proof.
+pub const BYTES_PER_PROOF: usize = 48;
+
+/// The number of bytes in a compressed G1 point.
+pub const BYTES_PER_G1_POINT: usize = 48;
+
+/// The number of bytes in a compressed G2 point.
+pub const BYTES_PER_G2_POINT: usize = 96;
+
+/// The number of G1 points in the trusted setup.
+pub const NUM_G1_POINTS: usize = FIELD_ELEMENTS_PER_BLOB;
+
+/// The number of G2 points in the trusted setup.
+pub const NUM_G2_POINTS: usize = 65;
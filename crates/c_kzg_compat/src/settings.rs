@@ -0,0 +1,460 @@
+use std::{
+    path::Path,
+    sync::{Arc, OnceLock},
+};
+
+use rust_eth_kzg::{DASContext, G1Point, G2Point, TrustedSetup, UsePrecomp};
+
+use crate::{
+    errors::verified, Blob, Bytes32, Bytes48, Cell, Error, KzgCommitment, KzgErrors, KzgProof,
+    BYTES_PER_G1_POINT, BYTES_PER_G2_POINT, CELLS_PER_EXT_BLOB, NUM_G1_POINTS, NUM_G2_POINTS,
+};
+
+/// The largest `precompute` value accepted by c-kzg.
+const MAX_PRECOMPUTE: u64 = UsePrecomp::MAX_WIDTH as u64;
+
+/// A trusted setup, and the precomputations made from it.
+///
+/// This wraps a [`DASContext`], which can be used directly through [`KzgSettings::context`]
+/// for the methods that c-kzg does not have.
+pub struct KzgSettings {
+    ctx: DASContext,
+}
+
+/// Returns the settings for the mainnet trusted setup, created once per `precompute` value.
+///
+/// # Panics
+///
+/// Panics if `precompute` is larger than 15, like c-kzg.
+pub fn ethereum_kzg_settings(precompute: u64) -> &'static KzgSettings {
+    cached_ethereum_kzg_settings(precompute)
+}
+
+/// Returns the settings for the mainnet trusted setup, created once per `precompute` value.
+///
+/// # Panics
+///
+/// Panics if `precompute` is larger than 15, like c-kzg.
+pub fn ethereum_kzg_settings_arc(precompute: u64) -> Arc<KzgSettings> {
+    cached_ethereum_kzg_settings(precompute).clone()
+}
+
+fn cached_ethereum_kzg_settings(precompute: u64) -> &'static Arc<KzgSettings> {
+    static SETTINGS: [OnceLock<Arc<KzgSettings>>; MAX_PRECOMPUTE as usize + 1] =
+        [const { OnceLock::new() }; MAX_PRECOMPUTE as usize + 1];
+    assert!(
+        precompute <= MAX_PRECOMPUTE,
+        "precompute must be at most {MAX_PRECOMPUTE}"
+    );
+    SETTINGS[precompute as usize].get_or_init(|| {
+        Arc::new(KzgSettings::with_trusted_setup(
+            &TrustedSetup::default(),
+            precompute,
+        ))
+    })
+}
+
+impl KzgSettings {
+    fn with_trusted_setup(trusted_setup: &TrustedSetup, precompute: u64) -> Self {
+        Self {
+            ctx: DASContext::new(
+                trusted_setup,
+                UsePrecomp::from_width(precompute as usize)
+                    .expect("precompute is at most MAX_PRECOMPUTE"),
+            ),
+        }
+    }
+
+    /// Returns the context that these settings are backed by.
+    pub const fn context(&self) -> &DASContext {
+        &self.ctx
+    }
+
+    /// Loads a trusted setup from its compressed points.
+    ///
+    /// The G1 lagrange points are checked to have the right length, but are not used.
+    pub fn load_trusted_setup(
+        g1_monomial_bytes: &[u8],
+        g1_lagrange_bytes: &[u8],
+        g2_monomial_bytes: &[u8],
+        precompute: u64,
+    ) -> Result<Self, Error> {
+        check_length(
+            "G1 monomial",
+            g1_monomial_bytes,
+            NUM_G1_POINTS * BYTES_PER_G1_POINT,
+        )?;
+        check_length(
+            "G1 lagrange",
+            g1_lagrange_bytes,
+            NUM_G1_POINTS * BYTES_PER_G1_POINT,
+        )?;
+        check_length(
+            "G2 monomial",
+            g2_monomial_bytes,
+            NUM_G2_POINTS * BYTES_PER_G2_POINT,
+        )?;
+        if precompute > MAX_PRECOMPUTE {
+            return Err(Error::InvalidTrustedSetup(format!(
+                "precompute must be at most {MAX_PRECOMPUTE}, got {precompute}"
+            )));
+        }
+
+        let g1_monomial = g1_monomial_bytes
+            .chunks_exact(BYTES_PER_G1_POINT)
+            .map(|bytes| {
+                let bytes = bytes.try_into().expect("chunks have the right length");
+                Option::from(G1Point::from_compressed(bytes))
+            })
+            .collect::<Option<Vec<_>>>()
+            .ok_or_else(|| Error::InvalidTrustedSetup("invalid G1 point".to_string()))?;
+        let g2_monomial = g2_monomial_bytes
+            .chunks_exact(BYTES_PER_G2_POINT)
+            .map(|bytes| {
+                let bytes = bytes.try_into().expect("chunks have the right length");
+                Option::from(G2Point::from_compressed(bytes))
+            })
+            .collect::<Option<Vec<_>>>()
+            .ok_or_else(|| Error::InvalidTrustedSetup("invalid G2 point".to_string()))?;
+
+        let trusted_setup = TrustedSetup {
+            g1_monomial,
+            g2_monomial,
+        };
+        Ok(Self::with_trusted_setup(&trusted_setup, precompute))
+    }
+
+    /// Loads a trusted setup in the `trusted_setup.txt` format of c-kzg.
+    pub fn parse_kzg_trusted_setup(trusted_setup: &str, precompute: u64) -> Result<Self, Error> {
+        let mut lines = trusted_setup
+            .lines()
+            .map(str::trim)
+            .filter(|line| !line.is_empty());
+
+        let mut read_count = || -> Result<usize, Error> {
+            lines
+                .next()
+                .ok_or(Error::LoadingTrustedSetupFailed(KzgErrors::FileFormatError))?
+                .parse()
+                .map_err(|_| Error::LoadingTrustedSetupFailed(KzgErrors::ParseError))
+        };
+        let num_g1_points = read_count()?;
+        let num_g2_points = read_count()?;
+        if num_g1_points != NUM_G1_POINTS || num_g2_points != NUM_G2_POINTS {
+            return Err(Error::LoadingTrustedSetupFailed(
+                KzgErrors::MismatchedNumberOfPoints,
+            ));
+        }
+
+        let mut read_points = |count: usize| -> Result<Vec<u8>, Error> {
+            let mut bytes = Vec::new();
+            for _ in 0..count {
+                let line = lines.next().ok_or(Error::LoadingTrustedSetupFailed(
+                    KzgErrors::MismatchedNumberOfPoints,
+                ))?;
+                bytes.extend(
+                    hex::decode(line)
+                        .map_err(|_| Error::LoadingTrustedSetupFailed(KzgErrors::ParseError))?,
+                );
+            }
+            Ok(bytes)
+        };
+        let g1_lagrange = read_points(num_g1_points)?;
+        let g2_monomial = read_points(num_g2_points)?;
+        let g1_monomial = read_points(num_g1_points)?;
+        if lines.next().is_some() {
+            return Err(Error::LoadingTrustedSetupFailed(
+                KzgErrors::MismatchedNumberOfPoints,
+            ));
+        }
+
+        Self::load_trusted_setup(&g1_monomial, &g1_lagrange, &g2_monomial, precompute)
+    }
+
+    /// Loads a trusted setup file in the `trusted_setup.txt` format of c-kzg.
+    pub fn load_trusted_setup_file(file_path: &Path, precompute: u64) -> Result<Self, Error> {
+        if !file_path.exists() {
+            return Err(Error::LoadingTrustedSetupFailed(KzgErrors::PathNotExists));
+        }
+        let bytes = std::fs::read(file_path)
+            .map_err(|_| Error::LoadingTrustedSetupFailed(KzgErrors::IOError))?;
+        let contents = String::from_utf8(bytes)
+            .map_err(|_| Error::LoadingTrustedSetupFailed(KzgErrors::NotValidFile))?;
+        Self::parse_kzg_trusted_setup(&contents, precompute)
+    }
+
+    /// See [`DASContext::blob_to_kzg_commitment`].
+    pub fn blob_to_kzg_commitment(&self, blob: &Blob) -> Result<KzgCommitment, Error> {
+        Ok(KzgCommitment::new(self.ctx.blob_to_kzg_commitment(blob)?))
+    }
+
+    /// See [`DASContext::compute_kzg_proof`].
+    pub fn compute_kzg_proof(
+        &self,
+        blob: &Blob,
+        z_bytes: &Bytes32,
+    ) -> Result<(KzgProof, Bytes32), Error> {
+        let (proof, y) = self.ctx.compute_kzg_proof(blob, **z_bytes)?;
+        Ok((KzgProof::new(proof), Bytes32::new(y)))
+    }
+
+    /// See [`DASContext::compute_blob_kzg_proof`].
+    pub fn compute_blob_kzg_proof(
+        &self,
+        blob: &Blob,
+        commitment_bytes: &Bytes48,
+    ) -> Result<KzgProof, Error> {
+        Ok(KzgProof::new(
+            self.ctx.compute_blob_kzg_proof(blob, commitment_bytes)?,
+        ))
+    }
+
+    /// See [`DASContext::verify_kzg_proof`].
+    pub fn verify_kzg_proof(
+        &self,
+        commitment_bytes: &Bytes48,
+        z_bytes: &Bytes32,
+        y_bytes: &Bytes32,
+        proof_bytes: &Bytes48,
+    ) -> Result<bool, Error> {
+        verified(
+            self.ctx
+                .verify_kzg_proof(commitment_bytes, **z_bytes, **y_bytes, proof_bytes),
+        )
+    }
+
+    /// See [`DASContext::verify_blob_kzg_proof`].
+    pub fn verify_blob_kzg_proof(
+        &self,
+        blob: &Blob,
+        commitment_bytes: &Bytes48,
+        proof_bytes: &Bytes48,
+    ) -> Result<bool, Error> {
+        verified(
+            self.ctx
+                .verify_blob_kzg_proof(blob, commitment_bytes, proof_bytes),
+        )
+    }
+
+    /// See [`DASContext::verify_blob_kzg_proof_batch`].
+    pub fn verify_blob_kzg_proof_batch(
+        &self,
+        blobs: &[Blob],
+        commitments_bytes: &[Bytes48],
+        proofs_bytes: &[Bytes48],
+    ) -> Result<bool, Error> {
+        if blobs.len() != commitments_bytes.len() {
+            return Err(Error::MismatchLength(format!(
+                "There are {} blobs and {} commitments",
+                blobs.len(),
+                commitments_bytes.len()
+            )));
+        }
+        if blobs.len() != proofs_bytes.len() {
+            return Err(Error::MismatchLength(format!(
+                "There are {} blobs and {} proofs",
+                blobs.len(),
+                proofs_bytes.len()
+            )));
+        }
+        verified(self.ctx.verify_blob_kzg_proof_batch(
+            blobs.iter().map(|blob| &**blob).collect(),
+            commitments_bytes.iter().map(|bytes| &**bytes).collect(),
+            proofs_bytes.iter().map(|bytes| &**bytes).collect(),
+        ))
+    }
+
+    /// See [`DASContext::compute_cells`].
+    pub fn compute_cells(&self, blob: &Blob) -> Result<Box<[Cell; CELLS_PER_EXT_BLOB]>, Error> {
+        let cells = self.ctx.compute_cells(blob)?;
+        Ok(boxed_array(cells.iter().map(|cell| Cell::new(**cell))))
+    }
+
+    /// See [`DASContext::compute_cells_and_kzg_proofs`].
+    pub fn compute_cells_and_kzg_proofs(
+        &self,
+        blob: &Blob,
+    ) -> Result<
+        (
+            Box<[Cell; CELLS_PER_EXT_BLOB]>,
+            Box<[KzgProof; CELLS_PER_EXT_BLOB]>,
+        ),
+        Error,
+    > {
+        let (cells, proofs) = self.ctx.compute_cells_and_kzg_proofs(blob)?;
+        Ok((
+            boxed_array(cells.iter().map(|cell| Cell::new(**cell))),
+            boxed_array(proofs.into_iter().map(KzgProof::new)),
+        ))
+    }
+
+    /// See [`DASContext::recover_cells_and_kzg_proofs`].
+    pub fn recover_cells_and_kzg_proofs(
+        &self,
+        cell_indices: &[u64],
+        cells: &[Cell],
+    ) -> Result<
+        (
+            Box<[Cell; CELLS_PER_EXT_BLOB]>,
+            Box<[KzgProof; CELLS_PER_EXT_BLOB]>,
+        ),
+        Error,
+    > {
+        if cell_indices.len() != cells.len() {
+            return Err(Error::MismatchLength(format!(
+                "There are {} cell indices and {} cells",
+                cell_indices.len(),
+                cells.len()
+            )));
+        }
+        let (cells, proofs) = self.ctx.recover_cells_and_kzg_proofs(
+            cell_indices.to_vec(),
+            cells.iter().map(|cell| &**cell).collect(),
+        )?;
+        Ok((
+            boxed_array(cells.iter().map(|cell| Cell::new(**cell))),
+            boxed_array(proofs.into_iter().map(KzgProof::new)),
+        ))
+    }
+
+    /// See [`DASContext::verify_cell_kzg_proof_batch`].
+    pub fn verify_cell_kzg_proof_batch(
+        &self,
+        commitments: &[Bytes48],
+        cell_indices: &[u64],
+        cells: &[Cell],
+        proofs: &[Bytes48],
+    ) -> Result<bool, Error> {
+        let same_length = commitments.len() == cell_indices.len()
+            && commitments.len() == cells.len()
+            && commitments.len() == proofs.len();
+        if !same_length {
+            return Err(Error::MismatchLength(format!(
+                "There are {} commitments, {} cell indices, {} cells and {} proofs",
+                commitments.len(),
+                cell_indices.len(),
+                cells.len(),
+                proofs.len()
+            )));
+        }
+        verified(self.ctx.verify_cell_kzg_proof_batch(
+            commitments.iter().map(|bytes| &**bytes).collect(),
+            cell_indices,
+            cells.iter().map(|cell| &**cell).collect(),
+            proofs.iter().map(|bytes| &**bytes).collect(),
+        ))
+    }
+}
+
+fn check_length(name: &str, bytes: &[u8], expected: usize) -> Result<(), Error> {
+    if bytes.len() == expected {
+        Ok(())
+    } else {
+        Err(Error::InvalidTrustedSetup(format!(
+            "Invalid {name} bytes length. Expected {expected} got {}",
+            bytes.len()
+        )))
+    }
+}
+
+/// Collects `CELLS_PER_EXT_BLOB` items into a boxed array, without putting it on the stack.
+fn boxed_array<T>(items: impl Iterator<Item = T>) -> Box<[T; CELLS_PER_EXT_BLOB]> {
+    items
+        .collect::<Vec<_>>()
+        .into_boxed_slice()
+        .try_into()
+        .unwrap_or_else(|_| panic!("the library returns {CELLS_PER_EXT_BLOB} items"))
+}
+
+#[cfg(test)]
+mod tests {
+    use rust_eth_kzg::{constants::BYTES_PER_FIELD_ELEMENT, DASContext};
+
+    use crate::{
+        ethereum_kzg_settings, Blob, Bytes48, Error, KzgSettings, BYTES_PER_BLOB,
+        CELLS_PER_EXT_BLOB,
+    };
+
+    fn blob() -> Blob {
+        let mut bytes = [0u8; BYTES_PER_BLOB];
+        for (i, chunk) in bytes.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            chunk[BYTES_PER_FIELD_ELEMENT - 1] = i as u8;
+        }
+        Blob::new(bytes)
+    }
+
+    #[test]
+    fn matches_the_context() {
+        let settings = ethereum_kzg_settings(0);
+        let ctx = DASContext::default();
+        let blob = blob();
+
+        let commitment = settings.blob_to_kzg_commitment(&blob).unwrap();
+        assert_eq!(*commitment, ctx.blob_to_kzg_commitment(&blob).unwrap());
+
+        let (cells, proofs) = settings.compute_cells_and_kzg_proofs(&blob).unwrap();
+        let (expected_cells, expected_proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+        assert!(cells
+            .iter()
+            .zip(&expected_cells)
+            .all(|(cell, expected)| **cell == **expected));
+        assert!(proofs
+            .iter()
+            .zip(&expected_proofs)
+            .all(|(proof, expected)| **proof == *expected));
+
+        // Recover from the second half of the cells.
+        let half = CELLS_PER_EXT_BLOB / 2;
+        let indices: Vec<u64> = (half as u64..CELLS_PER_EXT_BLOB as u64).collect();
+        let (recovered, recovered_proofs) = settings
+            .recover_cells_and_kzg_proofs(&indices, &cells[half..])
+            .unwrap();
+        assert_eq!(recovered, cells);
+        assert_eq!(recovered_proofs, proofs);
+    }
+
+    #[test]
+    fn invalid_proofs_are_false_and_invalid_inputs_are_errors() {
+        let settings = ethereum_kzg_settings(0);
+        let blob = blob();
+        let commitment = settings.blob_to_kzg_commitment(&blob).unwrap().to_bytes();
+        let proof = settings
+            .compute_blob_kzg_proof(&blob, &commitment)
+            .unwrap()
+            .to_bytes();
+
+        assert_eq!(
+            settings.verify_blob_kzg_proof(&blob, &commitment, &proof),
+            Ok(true)
+        );
+        assert_eq!(
+            settings.verify_blob_kzg_proof(&blob, &proof, &commitment),
+            Ok(false)
+        );
+        assert!(matches!(
+            settings.verify_blob_kzg_proof(&blob, &Bytes48::new([0xff; 48]), &proof),
+            Err(Error::CError(_))
+        ));
+        assert!(matches!(
+            settings.verify_blob_kzg_proof_batch(&[blob], &[], &[]),
+            Err(Error::MismatchLength(_))
+        ));
+    }
+
+    #[test]
+    fn parses_the_c_kzg_format() {
+        assert!(matches!(
+            KzgSettings::parse_kzg_trusted_setup("4096\n65\n", 0),
+            Err(Error::LoadingTrustedSetupFailed(_))
+        ));
+        assert!(matches!(
+            KzgSettings::load_trusted_setup(&[], &[], &[], 0),
+            Err(Error::InvalidTrustedSetup(_))
+        ));
+        assert!(Bytes48::from_hex(&format!("0x{}", "c0".repeat(48))).is_ok());
+        assert!(matches!(
+            Bytes48::from_hex("0xc0"),
+            Err(Error::InvalidBytesLength(_))
+        ));
+    }
+}
@@ -0,0 +1,120 @@
+use std::ops::{Deref, DerefMut};
+
+use crate::{
+    Error, BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_COMMITMENT, BYTES_PER_FIELD_ELEMENT,
+    BYTES_PER_PROOF,
+};
+
+/// Defines a wrapper around a byte array, with the constructors and conversions of the
+/// matching c-kzg type.
+macro_rules! define_bytes {
+    ($(#[$doc:meta])* $name:ident, $len:expr) => {
+        $(#[$doc])*
+        #[repr(C)]
+        #[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+        pub struct $name {
+            bytes: [u8; $len],
+        }
+
+        impl $name {
+            pub const fn new(bytes: [u8; $len]) -> Self {
+                Self { bytes }
+            }
+
+            pub fn from_bytes(bytes: &[u8]) -> Result<Self, Error> {
+                let bytes = bytes.try_into().map_err(|_| {
+                    Error::InvalidBytesLength(format!(
+                        "Invalid byte length. Expected {} got {}",
+                        $len,
+                        bytes.len()
+                    ))
+                })?;
+                Ok(Self { bytes })
+            }
+
+            pub fn from_hex(hex_str: &str) -> Result<Self, Error> {
+                let hex_str = hex_str.strip_prefix("0x").unwrap_or(hex_str);
+                let bytes = hex::decode(hex_str)
+                    .map_err(|err| Error::InvalidHexFormat(format!("Failed to decode hex: {err}")))?;
+                Self::from_bytes(&bytes)
+            }
+        }
+
+        impl From<[u8; $len]> for $name {
+            fn from(bytes: [u8; $len]) -> Self {
+                Self { bytes }
+            }
+        }
+
+        impl Deref for $name {
+            type Target = [u8; $len];
+
+            fn deref(&self) -> &Self::Target {
+                &self.bytes
+            }
+        }
+
+        impl DerefMut for $name {
+            fn deref_mut(&mut self) -> &mut Self::Target {
+                &mut self.bytes
+            }
+        }
+    };
+}
+
+define_bytes!(
+    /// A blob of `FIELD_ELEMENTS_PER_BLOB` serialized field elements.
+    Blob,
+    BYTES_PER_BLOB
+);
+define_bytes!(
+    /// A serialized field element.
+    Bytes32,
+    BYTES_PER_FIELD_ELEMENT
+);
+define_bytes!(
+    /// A serialized G1 point that has not been checked, such as a commitment or a proof.
+    Bytes48,
+    48
+);
+define_bytes!(
+    /// A cell of an extended blob.
+    Cell,
+    BYTES_PER_CELL
+);
+define_bytes!(
+    /// A KZG commitment returned by the library.
+    KzgCommitment,
+    BYTES_PER_COMMITMENT
+);
+define_bytes!(
+    /// A KZG proof returned by the library.
+    KzgProof,
+    BYTES_PER_PROOF
+);
+
+impl Cell {
+    pub const fn to_bytes(&self) -> [u8; BYTES_PER_CELL] {
+        self.bytes
+    }
+}
+
+impl KzgCommitment {
+    pub const fn to_bytes(&self) -> Bytes48 {
+        Bytes48::new(self.bytes)
+    }
+
+    pub fn as_hex_string(&self) -> String {
+        hex::encode(self.bytes)
+    }
+}
+
+impl KzgProof {
+    pub const fn to_bytes(&self) -> Bytes48 {
+        Bytes48::new(self.bytes)
+    }
+
+    pub fn as_hex_string(&self) -> String {
+        hex::encode(self.bytes)
+    }
+}
@@ -0,0 +1,232 @@
+//! `eth-kzg bench`: measures the throughput of the library on the host.
+//!
+//! Each thread count is benchmarked in turn on the same context, over a batch of blobs that
+//! are generated from a fixed seed, so that the results of different hosts can be compared.
+
+use std::time::{Duration, Instant};
+
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT, CELLS_PER_EXT_BLOB},
+    Bytes48Ref, CellIndex, CellRef, DASContext,
+};
+use serde::Serialize;
+use serde_json::json;
+
+use crate::{commands::Report, errors::Error};
+
+/// What to benchmark.
+pub struct BenchConfig {
+    /// The thread counts to benchmark, where 0 means one thread per CPU.
+    pub threads: Vec<usize>,
+    /// The number of blobs that each operation is run on.
+    pub batch_size: usize,
+    /// The number of times each operation is run, of which the fastest is reported.
+    pub iterations: usize,
+}
+
+/// The throughput of one operation.
+#[derive(Serialize)]
+struct Throughput {
+    /// The number of blobs or cells processed in each run.
+    items: usize,
+    /// What `items` counts.
+    unit: &'static str,
+    /// The duration of the fastest run, in seconds.
+    seconds: f64,
+    /// `items / seconds`
+    per_second: f64,
+}
+
+impl Throughput {
+    fn new(items: usize, unit: &'static str, elapsed: Duration) -> Self {
+        let seconds = elapsed.as_secs_f64();
+        Self {
+            items,
+            unit,
+            seconds,
+            per_second: items as f64 / seconds,
+        }
+    }
+}
+
+/// Runs `f` `iterations` times and returns the duration of the fastest run.
+fn fastest<T>(
+    iterations: usize,
+    mut f: impl FnMut() -> Result<T, Error>,
+) -> Result<Duration, Error> {
+    let mut best = Duration::MAX;
+    for _ in 0..iterations.max(1) {
+        let start = Instant::now();
+        f()?;
+        best = best.min(start.elapsed());
+    }
+    Ok(best)
+}
+
+/// Returns a blob of pseudorandom field elements.
+///
+/// The first byte of each field element is zero, so that the big-endian value is below
+/// the modulus of the scalar field.
+pub(crate) fn random_blob(seed: u64) -> Box<[u8; BYTES_PER_BLOB]> {
+    let mut state = seed;
+    let mut blob = Box::new([0u8; BYTES_PER_BLOB]);
+    for element in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT) {
+        for chunk in element[1..].chunks_mut(8) {
+            let random = splitmix64(&mut state).to_be_bytes();
+            chunk.copy_from_slice(&random[..chunk.len()]);
+        }
+    }
+    blob
+}
+
+/// The `splitmix64` generator, which is good enough for test inputs.
+fn splitmix64(state: &mut u64) -> u64 {
+    *state = state.wrapping_add(0x9e37_79b9_7f4a_7c15);
+    let mut z = *state;
+    z = (z ^ (z >> 30)).wrapping_mul(0xbf58_476d_1ce4_e5b9);
+    z = (z ^ (z >> 27)).wrapping_mul(0x94d0_49bb_1331_11eb);
+    z ^ (z >> 31)
+}
+
+/// Benchmarks committing to blobs, computing their cells and proofs, verifying the cells
+/// and recovering them from half of the cells.
+pub fn bench(ctx: &mut DASContext, config: &BenchConfig) -> Result<Report, Error> {
+    if config.batch_size == 0 {
+        return Err(Error::InvalidInput(
+            "the batch size must be at least 1".to_string(),
+        ));
+    }
+
+    let blobs: Vec<_> = (0..config.batch_size as u64).map(random_blob).collect();
+    let blob_refs: Vec<_> = blobs.iter().map(|blob| &**blob).collect();
+
+    // Compute the inputs of verification and recovery once, outside of the timed runs.
+    let commitments = blobs
+        .iter()
+        .map(|blob| ctx.blob_to_kzg_commitment(blob))
+        .collect::<Result<Vec<_>, _>>()?;
+    let cells_and_proofs = ctx.compute_cells_and_kzg_proofs_batch(blob_refs.clone())?;
+
+    let mut verify_commitments: Vec<Bytes48Ref> = Vec::new();
+    let mut verify_indices: Vec<CellIndex> = Vec::new();
+    let mut verify_cells: Vec<CellRef> = Vec::new();
+    let mut verify_proofs: Vec<Bytes48Ref> = Vec::new();
+    for (commitment, (cells, proofs)) in commitments.iter().zip(&cells_and_proofs) {
+        for (index, (cell, proof)) in cells.iter().zip(proofs).enumerate() {
+            verify_commitments.push(commitment);
+            verify_indices.push(index as CellIndex);
+            verify_cells.push(cell);
+            verify_proofs.push(proof);
+        }
+    }
+
+    // Recover each blob from every other cell, which is half of them.
+    let recovery_indices: Vec<CellIndex> =
+        (1..CELLS_PER_EXT_BLOB as CellIndex).step_by(2).collect();
+
+    let mut results = Vec::with_capacity(config.threads.len());
+    for &threads in &config.threads {
+        ctx.set_num_threads(threads).map_err(|err| {
+            Error::Setup(format!(
+                "could not create a pool of {threads} threads: {err}"
+            ))
+        })?;
+
+        let commit = fastest(config.iterations, || {
+            for blob in &blobs {
+                ctx.blob_to_kzg_commitment(blob)?;
+            }
+            Ok(())
+        })?;
+        let prove = fastest(config.iterations, || {
+            Ok(ctx.compute_cells_and_kzg_proofs_batch(blob_refs.clone())?)
+        })?;
+        let verify = fastest(config.iterations, || {
+            Ok(ctx.verify_cell_kzg_proof_batch(
+                verify_commitments.clone(),
+                &verify_indices,
+                verify_cells.clone(),
+                verify_proofs.clone(),
+            )?)
+        })?;
+        let recover = fastest(config.iterations, || {
+            for (cells, _) in &cells_and_proofs {
+                ctx.recover_cells_and_kzg_proofs(
+                    recovery_indices.clone(),
+                    recovery_indices
+                        .iter()
+                        .map(|&index| &*cells[index as usize])
+                        .collect(),
+                )?;
+            }
+            Ok(())
+        })?;
+
+        results.push(json!({
+            "threads": if threads == 0 { available_parallelism() } else { threads },
+            "blob_to_kzg_commitment": Throughput::new(blobs.len(), "blobs", commit),
+            "compute_cells_and_kzg_proofs": Throughput::new(blobs.len(), "blobs", prove),
+            "verify_cell_kzg_proof_batch": Throughput::new(verify_cells.len(), "cells", verify),
+            "recover_cells_and_kzg_proofs": Throughput::new(blobs.len(), "blobs", recover),
+        }));
+    }
+
+    Ok(Report {
+        json: json!({
+            "host": {
+                "cpus": available_parallelism(),
+                "os": std::env::consts::OS,
+                "arch": std::env::consts::ARCH,
+            },
+            "batch_size": config.batch_size,
+            "iterations": config.iterations,
+            "results": results,
+        }),
+        success: true,
+    })
+}
+
+fn available_parallelism() -> usize {
+    std::thread::available_parallelism().map_or(1, usize::from)
+}
+
+#[cfg(test)]
+mod tests {
+    use rust_eth_kzg::{constants::BYTES_PER_FIELD_ELEMENT, DASContext, TrustedSetup};
+
+    use super::{bench, random_blob, BenchConfig};
+
+    #[test]
+    fn random_blobs_are_canonical_and_seeded() {
+        assert_eq!(random_blob(1), random_blob(1));
+        assert_ne!(random_blob(0), random_blob(1));
+        assert!(random_blob(2)
+            .chunks_exact(BYTES_PER_FIELD_ELEMENT)
+            .all(|element| element[0] == 0));
+    }
+
+    #[test]
+    fn reports_every_thread_count() {
+        let mut ctx = DASContext::builder()
+            .trusted_setup(&TrustedSetup::default())
+            .build()
+            .unwrap();
+        let config = BenchConfig {
+            threads: vec![1, 2],
+            batch_size: 1,
+            iterations: 1,
+        };
+        let report = bench(&mut ctx, &config).unwrap();
+
+        let results = report.json["results"].as_array().unwrap();
+        assert_eq!(results.len(), 2);
+        assert_eq!(results[1]["threads"], 2);
+        assert_eq!(results[0]["verify_cell_kzg_proof_batch"]["unit"], "cells");
+        assert!(
+            results[0]["blob_to_kzg_commitment"]["per_second"]
+                .as_f64()
+                .unwrap()
+                > 0.0
+        );
+    }
+}
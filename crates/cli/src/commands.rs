@@ -0,0 +1,216 @@
+//! The commands, each of which returns a JSON report.
+
+use std::path::Path;
+
+use rust_eth_kzg::{
+    constants::CELLS_PER_EXT_BLOB, Bytes48Ref, CellRef, DASContext, KZGCommitment, KZGProof,
+    PrefixedHex, SerializedScalar,
+};
+use serde_json::{json, Value};
+
+use crate::{
+    errors::Error,
+    input::{read_blob, read_cells},
+};
+
+/// The outcome of a command.
+pub struct Report {
+    /// What the command prints.
+    pub json: Value,
+    /// False if a proof did not verify or a test vector failed.
+    pub success: bool,
+}
+
+impl Report {
+    fn ok(json: Value) -> Self {
+        Self {
+            json,
+            success: true,
+        }
+    }
+
+    /// Reports the result of a verification, which fails the command if the proof is
+    /// invalid, and returns any other error.
+    fn verification(result: Result<(), rust_eth_kzg::Error>) -> Result<Self, Error> {
+        match result {
+            Ok(()) => Ok(Self {
+                json: json!({ "valid": true }),
+                success: true,
+            }),
+            Err(err) if err.is_proof_invalid() => Ok(Self {
+                json: json!({ "valid": false }),
+                success: false,
+            }),
+            Err(err) => Err(err.into()),
+        }
+    }
+}
+
+/// Computes the commitment to a blob.
+pub fn commit(ctx: &DASContext, blob: &Path) -> Result<Report, Error> {
+    let blob = read_blob(blob)?;
+    let commitment = ctx.blob_to_kzg_commitment(&blob)?;
+    Ok(Report::ok(json!({ "commitment": commitment.to_hex() })))
+}
+
+/// Computes the blob proof of a blob, or the proof of its evaluation at `z`.
+pub fn prove(ctx: &DASContext, blob: &Path, z: Option<SerializedScalar>) -> Result<Report, Error> {
+    let blob = read_blob(blob)?;
+    if let Some(z) = z {
+        let (proof, y) = ctx.compute_kzg_proof(&blob, z)?;
+        return Ok(Report::ok(json!({
+            "z": z.to_hex(),
+            "y": y.to_hex(),
+            "proof": proof.to_hex(),
+        })));
+    }
+
+    let commitment = ctx.blob_to_kzg_commitment(&blob)?;
+    let proof = ctx.compute_blob_kzg_proof(&blob, &commitment)?;
+    Ok(Report::ok(json!({
+        "commitment": commitment.to_hex(),
+        "proof": proof.to_hex(),
+    })))
+}
+
+/// Checks the blob proof of a blob.
+pub fn verify_blob(
+    ctx: &DASContext,
+    blob: &Path,
+    commitment: &KZGCommitment,
+    proof: &KZGProof,
+) -> Result<Report, Error> {
+    let blob = read_blob(blob)?;
+    Report::verification(ctx.verify_blob_kzg_proof(&blob, commitment, proof))
+}
+
+/// Checks the proof that the polynomial committed to evaluates to `y` at `z`.
+pub fn verify_point(
+    ctx: &DASContext,
+    commitment: &KZGCommitment,
+    z: SerializedScalar,
+    y: SerializedScalar,
+    proof: &KZGProof,
+) -> Result<Report, Error> {
+    Report::verification(ctx.verify_kzg_proof(commitment, z, y, proof))
+}
+
+/// Checks the proofs of a file of cells.
+pub fn verify_cells(ctx: &DASContext, file: &Path) -> Result<Report, Error> {
+    let cells = read_cells(file)?;
+    if cells.commitments.is_empty() {
+        return Err(Error::InvalidInput(
+            "`commitment` or `commitments` is required to verify cells".to_string(),
+        ));
+    }
+
+    Report::verification(
+        ctx.verify_cell_kzg_proof_batch(
+            cells.commitments.iter().collect::<Vec<Bytes48Ref>>(),
+            &cells.cell_indices,
+            cells
+                .cells
+                .iter()
+                .map(|cell| &**cell)
+                .collect::<Vec<CellRef>>(),
+            cells.proofs.iter().collect::<Vec<Bytes48Ref>>(),
+        ),
+    )
+}
+
+/// Extends a blob into its cells and computes their proofs.
+pub fn cells(ctx: &DASContext, blob: &Path) -> Result<Report, Error> {
+    let blob = read_blob(blob)?;
+    let commitment = ctx.blob_to_kzg_commitment(&blob)?;
+    let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob)?;
+    Ok(Report::ok(json!({
+        "commitment": commitment.to_hex(),
+        "cell_indices": (0..CELLS_PER_EXT_BLOB as u64).collect::<Vec<_>>(),
+        "cells": cells.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+        "proofs": proofs.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+    })))
+}
+
+/// Recovers every cell of a blob, and their proofs, from a file with at least half of them.
+pub fn recover(ctx: &DASContext, file: &Path) -> Result<Report, Error> {
+    let cells = read_cells(file)?;
+    let (cells, proofs) = ctx.recover_cells_and_kzg_proofs(
+        cells.cell_indices,
+        cells.cells.iter().map(|cell| &**cell).collect(),
+    )?;
+    Ok(Report::ok(json!({
+        "cell_indices": (0..CELLS_PER_EXT_BLOB as u64).collect::<Vec<_>>(),
+        "cells": cells.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+        "proofs": proofs.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+    })))
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use rust_eth_kzg::{constants::BYTES_PER_BLOB, TrustedSetup};
+    use serde_json::Value;
+
+    use super::*;
+
+    struct TempFile(PathBuf);
+
+    impl TempFile {
+        fn new(name: &str, contents: &[u8]) -> Self {
+            let path = std::env::temp_dir().join(format!("eth-kzg-{}-{name}", std::process::id()));
+            std::fs::write(&path, contents).unwrap();
+            Self(path)
+        }
+    }
+
+    impl Drop for TempFile {
+        fn drop(&mut self) {
+            let _ = std::fs::remove_file(&self.0);
+        }
+    }
+
+    fn hex_field<const N: usize>(json: &Value, field: &str) -> [u8; N] {
+        <[u8; N]>::from_hex(json[field].as_str().unwrap()).unwrap()
+    }
+
+    #[test]
+    fn blob_proofs_round_trip() {
+        let ctx = DASContext::builder()
+            .trusted_setup(&TrustedSetup::default())
+            .build()
+            .unwrap();
+        let mut blob = [0u8; BYTES_PER_BLOB];
+        blob[31] = 1;
+        let blob_file = TempFile::new("blob", blob.to_hex().as_bytes());
+
+        let proved = prove(&ctx, &blob_file.0, None).unwrap().json;
+        let commitment = hex_field(&proved, "commitment");
+        let proof = hex_field(&proved, "proof");
+        assert!(
+            verify_blob(&ctx, &blob_file.0, &commitment, &proof)
+                .unwrap()
+                .success
+        );
+        assert!(
+            !verify_blob(&ctx, &blob_file.0, &commitment, &commitment)
+                .unwrap()
+                .success
+        );
+
+        let cells_json = cells(&ctx, &blob_file.0).unwrap().json;
+        let cells_file = TempFile::new("cells", cells_json.to_string().as_bytes());
+        assert!(verify_cells(&ctx, &cells_file.0).unwrap().success);
+
+        // Keep the first half of the cells, and recover the rest.
+        let half = CELLS_PER_EXT_BLOB / 2;
+        let partial = serde_json::json!({
+            "cell_indices": (0..half as u64).collect::<Vec<_>>(),
+            "cells": &cells_json["cells"].as_array().unwrap()[..half],
+        });
+        let partial_file = TempFile::new("partial", partial.to_string().as_bytes());
+        let recovered = recover(&ctx, &partial_file.0).unwrap().json;
+        assert_eq!(recovered["cells"], cells_json["cells"]);
+        assert_eq!(recovered["proofs"], cells_json["proofs"]);
+    }
+}
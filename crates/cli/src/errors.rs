@@ -0,0 +1,60 @@
+use std::{fmt, io, path::PathBuf};
+
+use rust_eth_kzg::{BuildError, HexError};
+
+/// Errors that stop a command from running.
+#[derive(Debug)]
+pub enum Error {
+    /// A file could not be read or written.
+    Io {
+        /// The file, or `-` for stdin and stdout.
+        path: PathBuf,
+        source: io::Error,
+    },
+    /// A JSON input is malformed, or does not have the expected fields.
+    Json(serde_json::Error),
+    /// A hex input is malformed, or encodes the wrong number of bytes.
+    Hex(HexError),
+    /// An input is neither raw bytes, nor hex, nor JSON.
+    InvalidInput(String),
+    /// The trusted setup could not be loaded or the context could not be created.
+    Setup(String),
+    /// The library rejected the inputs.
+    Kzg(rust_eth_kzg::Error),
+}
+
+impl fmt::Display for Error {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Self::Io { path, source } => write!(f, "{}: {source}", path.display()),
+            Self::Json(err) => write!(f, "invalid JSON: {err}"),
+            Self::Hex(err) => write!(f, "invalid hex: {err:?}"),
+            Self::InvalidInput(msg) | Self::Setup(msg) => f.write_str(msg),
+            Self::Kzg(err) => write!(f, "{}: {err:?}", err.code()),
+        }
+    }
+}
+
+impl From<serde_json::Error> for Error {
+    fn from(value: serde_json::Error) -> Self {
+        Self::Json(value)
+    }
+}
+
+impl From<HexError> for Error {
+    fn from(value: HexError) -> Self {
+        Self::Hex(value)
+    }
+}
+
+impl From<rust_eth_kzg::Error> for Error {
+    fn from(value: rust_eth_kzg::Error) -> Self {
+        Self::Kzg(value)
+    }
+}
+
+impl From<BuildError> for Error {
+    fn from(value: BuildError) -> Self {
+        Self::Setup(format!("could not create the context: {value:?}"))
+    }
+}
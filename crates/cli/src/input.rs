@@ -0,0 +1,195 @@
+//! Reading blobs and cells from files.
+//!
+//! A blob file holds either the raw bytes of the blob, a hex string, or JSON: a hex string,
+//! or an object with a `blob` field. Lists of cells are always JSON, in the format written
+//! by `eth-kzg cells`.
+
+use std::{
+    io::{self, Read},
+    path::Path,
+};
+
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
+    Cell, CellIndex, KZGCommitment, KZGProof, PrefixedHex,
+};
+use serde::Deserialize;
+
+use crate::errors::Error;
+
+/// Reads a file, or stdin if the path is `-`.
+pub fn read_file(path: &Path) -> Result<Vec<u8>, Error> {
+    let result = if path == Path::new("-") {
+        let mut bytes = Vec::new();
+        io::stdin().read_to_end(&mut bytes).map(|_| bytes)
+    } else {
+        std::fs::read(path)
+    };
+    result.map_err(|source| Error::Io {
+        path: path.to_path_buf(),
+        source,
+    })
+}
+
+/// Reads a blob from a file with its raw bytes, a hex string or JSON.
+pub fn read_blob(path: &Path) -> Result<Box<[u8; BYTES_PER_BLOB]>, Error> {
+    parse_blob(&read_file(path)?)
+}
+
+pub(crate) fn parse_blob(bytes: &[u8]) -> Result<Box<[u8; BYTES_PER_BLOB]>, Error> {
+    if bytes.len() == BYTES_PER_BLOB {
+        return Ok(bytes
+            .to_vec()
+            .into_boxed_slice()
+            .try_into()
+            .expect("length was checked"));
+    }
+
+    let text = std::str::from_utf8(bytes)
+        .map_err(|_| {
+            Error::InvalidInput(format!(
+                "a blob must be {BYTES_PER_BLOB} raw bytes, a hex string or JSON, but got {} bytes that are not text",
+                bytes.len()
+            ))
+        })?
+        .trim();
+    if text.starts_with('{') || text.starts_with('"') {
+        #[derive(Deserialize)]
+        #[serde(untagged)]
+        enum BlobJson {
+            Hex(String),
+            Object { blob: String },
+        }
+        let (BlobJson::Hex(hex_str) | BlobJson::Object { blob: hex_str }) =
+            serde_json::from_str(text)?;
+        Ok(Box::from_hex(&hex_str)?)
+    } else {
+        Ok(Box::from_hex(text)?)
+    }
+}
+
+/// The cells of a blob and what they are checked against, as written by `eth-kzg cells`.
+///
+/// Every field but `cells` is optional, so that the same format can be used to recover
+/// cells, which only needs the cells and their indices, and to verify them.
+#[derive(Debug, Deserialize)]
+pub struct CellsFile {
+    /// The commitment of the blob, used for every cell if `commitments` is missing.
+    pub commitment: Option<String>,
+    /// The commitment of the blob of each cell.
+    pub commitments: Option<Vec<String>>,
+    /// The index of each cell, which defaults to `0..cells.len()` if every cell is present.
+    pub cell_indices: Option<Vec<CellIndex>>,
+    pub cells: Vec<String>,
+    pub proofs: Option<Vec<String>>,
+}
+
+/// The decoded contents of a [`CellsFile`].
+pub struct Cells {
+    pub commitments: Vec<KZGCommitment>,
+    pub cell_indices: Vec<CellIndex>,
+    pub cells: Vec<Cell>,
+    pub proofs: Vec<KZGProof>,
+}
+
+/// Reads a file in the format of [`CellsFile`].
+pub fn read_cells(path: &Path) -> Result<Cells, Error> {
+    parse_cells(&read_file(path)?)
+}
+
+pub(crate) fn parse_cells(bytes: &[u8]) -> Result<Cells, Error> {
+    let file: CellsFile = serde_json::from_slice(bytes)?;
+
+    let cells = file
+        .cells
+        .iter()
+        .map(|cell| Cell::from_hex(cell))
+        .collect::<Result<Vec<_>, _>>()?;
+    let cell_indices = match file.cell_indices {
+        Some(cell_indices) => cell_indices,
+        None if cells.len() == CELLS_PER_EXT_BLOB => (0..CELLS_PER_EXT_BLOB as u64).collect(),
+        None => {
+            return Err(Error::InvalidInput(format!(
+                "`cell_indices` is required unless all {CELLS_PER_EXT_BLOB} cells are given"
+            )))
+        }
+    };
+    let commitments = match (file.commitments, file.commitment) {
+        (Some(commitments), _) => parse_hex_list(&commitments)?,
+        (None, Some(commitment)) => vec![KZGCommitment::from_hex(&commitment)?; cells.len()],
+        (None, None) => Vec::new(),
+    };
+    let proofs = parse_hex_list(&file.proofs.unwrap_or_default())?;
+
+    Ok(Cells {
+        commitments,
+        cell_indices,
+        cells,
+        proofs,
+    })
+}
+
+pub(crate) fn parse_hex_list<T: PrefixedHex>(items: &[String]) -> Result<Vec<T>, Error> {
+    Ok(items
+        .iter()
+        .map(|item| T::from_hex(item))
+        .collect::<Result<_, _>>()?)
+}
+
+/// Parses a command line argument as a hex string of `N` bytes.
+pub fn parse_hex_arg<const N: usize>(arg: &str) -> Result<[u8; N], String> {
+    <[u8; N]>::from_hex(arg).map_err(|err| format!("{err:?}"))
+}
+
+#[cfg(test)]
+mod tests {
+    use rust_eth_kzg::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_CELL},
+        PrefixedHex,
+    };
+
+    use super::{parse_blob, parse_cells};
+    use crate::errors::Error;
+
+    #[test]
+    fn blobs_can_be_raw_hex_or_json() {
+        let blob = Box::new([7u8; BYTES_PER_BLOB]);
+        let hex_str = blob.to_hex();
+
+        assert_eq!(parse_blob(blob.as_slice()).unwrap(), blob);
+        assert_eq!(parse_blob(format!("{hex_str}\n").as_bytes()).unwrap(), blob);
+        assert_eq!(
+            parse_blob(format!("\"{hex_str}\"").as_bytes()).unwrap(),
+            blob
+        );
+        assert_eq!(
+            parse_blob(format!("{{\"blob\": \"{hex_str}\"}}").as_bytes()).unwrap(),
+            blob
+        );
+        assert!(matches!(parse_blob(b"0x0102"), Err(Error::Hex(_))));
+        assert!(matches!(
+            parse_blob(&[0xff; 3]),
+            Err(Error::InvalidInput(_))
+        ));
+    }
+
+    #[test]
+    fn cells_need_indices_unless_complete() {
+        let cell = Box::new([1u8; BYTES_PER_CELL]).to_hex();
+        let commitment = [0xc0u8; 48].to_hex();
+
+        let json = format!(
+            r#"{{"commitment": "{commitment}", "cell_indices": [3], "cells": ["{cell}"]}}"#
+        );
+        let cells = parse_cells(json.as_bytes()).unwrap();
+        assert_eq!(cells.cell_indices, vec![3]);
+        assert_eq!(cells.commitments, vec![[0xc0; 48]]);
+        assert!(cells.proofs.is_empty());
+
+        let json = format!(r#"{{"cells": ["{cell}"]}}"#);
+        assert!(matches!(
+            parse_cells(json.as_bytes()),
+            Err(Error::InvalidInput(_))
+        ));
+    }
+}
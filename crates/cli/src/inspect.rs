@@ -0,0 +1,439 @@
+//! `eth-kzg inspect`: decodes a blob, a blob sidecar or a data column sidecar, prints its
+//! commitments and their versioned hashes, and checks every proof in it.
+//!
+//! Sidecars can be given as SSZ, or as the JSON returned by the beacon API, on its own, in a
+//! list or in a `data` field. When a check fails, the report says which field is at fault:
+//! for a data column sidecar, the cells are verified in a batch first, and one at a time
+//! only if the batch fails, so that the rows with a bad cell, proof or commitment are listed.
+
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
+    kzg_to_versioned_hash,
+    ssz::DataColumnSidecarCells,
+    Cell, DASContext, KZGCommitment, KZGProof, PrefixedHex,
+};
+use serde::Deserialize;
+use serde_json::{json, Value};
+
+use crate::{
+    commands::Report,
+    errors::Error,
+    input::{parse_blob, parse_hex_list},
+};
+
+/// `KZG_COMMITMENT_INCLUSION_PROOF_DEPTH` from the consensus specs.
+const KZG_COMMITMENT_INCLUSION_PROOF_DEPTH: usize = 17;
+
+/// The length of an SSZ encoded `BlobSidecar`, which only has fixed length fields: the
+/// index, the blob, the commitment, the proof, the signed block header and the inclusion
+/// proof of the commitment.
+const BLOB_SIDECAR_LEN: usize = 8
+    + BYTES_PER_BLOB
+    + 48
+    + 48
+    + (8 + 8 + 3 * 32 + 96)
+    + KZG_COMMITMENT_INCLUSION_PROOF_DEPTH * 32;
+
+/// Something to inspect.
+enum Item {
+    Blob(Box<[u8; BYTES_PER_BLOB]>),
+    BlobSidecar {
+        index: u64,
+        blob: Box<[u8; BYTES_PER_BLOB]>,
+        commitment: KZGCommitment,
+        proof: KZGProof,
+    },
+    DataColumnSidecar(DataColumnSidecarCells),
+}
+
+/// Inspects every blob or sidecar in `bytes`.
+pub fn inspect(ctx: &DASContext, bytes: &[u8]) -> Result<Report, Error> {
+    let items = decode(bytes)?;
+    let reports: Vec<(Value, bool)> = items.iter().map(|item| inspect_item(ctx, item)).collect();
+    let success = reports.iter().all(|(_, success)| *success);
+
+    let json = match <[_; 1]>::try_from(reports) {
+        Ok([(json, _)]) => json,
+        Err(reports) => json!({
+            "items": reports.into_iter().map(|(json, _)| json).collect::<Vec<_>>(),
+        }),
+    };
+    Ok(Report { json, success })
+}
+
+fn decode(bytes: &[u8]) -> Result<Vec<Item>, Error> {
+    if bytes.len() == BYTES_PER_BLOB {
+        return Ok(vec![Item::Blob(parse_blob(bytes)?)]);
+    }
+    if bytes.len() == BLOB_SIDECAR_LEN {
+        return Ok(vec![decode_ssz_blob_sidecar(bytes)]);
+    }
+    if let Ok(value) = serde_json::from_slice::<Value>(bytes) {
+        return decode_json(value);
+    }
+    if std::str::from_utf8(bytes).is_ok_and(|text| text.trim_start().starts_with("0x")) {
+        return Ok(vec![Item::Blob(parse_blob(bytes)?)]);
+    }
+
+    DataColumnSidecarCells::from_sidecar_ssz_bytes(bytes)
+        .map(|sidecar| vec![Item::DataColumnSidecar(sidecar)])
+        .map_err(|err| {
+            Error::InvalidInput(format!(
+                "expected a blob, a blob sidecar or a data column sidecar, as SSZ, hex or JSON, \
+                 but got {} bytes that are not a data column sidecar: {err:?}",
+                bytes.len()
+            ))
+        })
+}
+
+fn decode_ssz_blob_sidecar(bytes: &[u8]) -> Item {
+    let (index, rest) = bytes.split_at(8);
+    let (blob, rest) = rest.split_at(BYTES_PER_BLOB);
+    let (commitment, rest) = rest.split_at(48);
+    Item::BlobSidecar {
+        index: u64::from_le_bytes(index.try_into().expect("length was checked")),
+        blob: blob
+            .to_vec()
+            .into_boxed_slice()
+            .try_into()
+            .expect("length was checked"),
+        commitment: commitment.try_into().expect("length was checked"),
+        proof: rest[..48].try_into().expect("length was checked"),
+    }
+}
+
+/// An index, which the beacon API encodes as a string.
+#[derive(Deserialize)]
+#[serde(untagged)]
+enum Index {
+    Number(u64),
+    String(String),
+}
+
+impl Index {
+    fn parse(&self) -> Result<u64, Error> {
+        match self {
+            Self::Number(index) => Ok(*index),
+            Self::String(index) => index
+                .parse()
+                .map_err(|_| Error::InvalidInput(format!("invalid index `{index}`"))),
+        }
+    }
+}
+
+#[derive(Deserialize)]
+struct BlobJson {
+    index: Option<Index>,
+    blob: String,
+    kzg_commitment: Option<String>,
+    kzg_proof: Option<String>,
+}
+
+#[derive(Deserialize)]
+struct DataColumnSidecarJson {
+    index: Index,
+    column: Vec<String>,
+    kzg_commitments: Vec<String>,
+    kzg_proofs: Vec<String>,
+}
+
+fn decode_json(value: Value) -> Result<Vec<Item>, Error> {
+    match value {
+        Value::Object(mut object) if object.contains_key("data") => {
+            decode_json(object.remove("data").expect("the key exists"))
+        }
+        Value::Array(values) => values
+            .into_iter()
+            .map(decode_json_item)
+            .collect::<Result<_, _>>(),
+        Value::String(blob) => Ok(vec![Item::Blob(Box::from_hex(&blob)?)]),
+        value => Ok(vec![decode_json_item(value)?]),
+    }
+}
+
+fn decode_json_item(value: Value) -> Result<Item, Error> {
+    if value.get("column").is_some() {
+        let sidecar: DataColumnSidecarJson = serde_json::from_value(value)?;
+        return Ok(Item::DataColumnSidecar(DataColumnSidecarCells {
+            index: sidecar.index.parse()?,
+            column: parse_hex_list(&sidecar.column)?,
+            kzg_commitments: parse_hex_list(&sidecar.kzg_commitments)?,
+            kzg_proofs: parse_hex_list(&sidecar.kzg_proofs)?,
+        }));
+    }
+
+    let blob: BlobJson = serde_json::from_value(value)?;
+    let blob_bytes: Box<[u8; BYTES_PER_BLOB]> = Box::from_hex(&blob.blob)?;
+    match (blob.kzg_commitment, blob.kzg_proof) {
+        (Some(commitment), Some(proof)) => Ok(Item::BlobSidecar {
+            index: blob.index.as_ref().map_or(Ok(0), Index::parse)?,
+            blob: blob_bytes,
+            commitment: KZGCommitment::from_hex(&commitment)?,
+            proof: KZGProof::from_hex(&proof)?,
+        }),
+        (None, None) => Ok(Item::Blob(blob_bytes)),
+        _ => Err(Error::InvalidInput(
+            "a blob sidecar needs both `kzg_commitment` and `kzg_proof`".to_string(),
+        )),
+    }
+}
+
+/// Returns the report of `item`, and whether every check passed.
+fn inspect_item(ctx: &DASContext, item: &Item) -> (Value, bool) {
+    let mut failures = Vec::new();
+    let mut json = match item {
+        Item::Blob(blob) => match ctx.blob_to_kzg_commitment(blob) {
+            Ok(commitment) => json!({
+                "kind": "blob",
+                "kzg_commitment": commitment.to_hex(),
+                "versioned_hash": kzg_to_versioned_hash(&commitment).to_hex(),
+            }),
+            Err(err) => {
+                failures.push(format!("blob: {}", err.code()));
+                json!({ "kind": "blob" })
+            }
+        },
+        Item::BlobSidecar {
+            index,
+            blob,
+            commitment,
+            proof,
+        } => {
+            match ctx.blob_to_kzg_commitment(blob) {
+                Ok(computed) if computed != *commitment => failures.push(format!(
+                    "kzg_commitment: the blob commits to {}",
+                    computed.to_hex()
+                )),
+                Ok(_) => {}
+                Err(err) => failures.push(format!("blob: {}", err.code())),
+            }
+            match ctx.verify_blob_kzg_proof(blob, commitment, proof) {
+                Ok(()) => {}
+                Err(err) if err.is_proof_invalid() => failures.push(
+                    "kzg_proof: does not verify against the blob and kzg_commitment".to_string(),
+                ),
+                Err(err) => failures.push(format!("kzg_proof: {}", err.code())),
+            }
+            json!({
+                "kind": "blob_sidecar",
+                "index": index,
+                "kzg_commitment": commitment.to_hex(),
+                "versioned_hash": kzg_to_versioned_hash(commitment).to_hex(),
+                "kzg_proof": proof.to_hex(),
+            })
+        }
+        Item::DataColumnSidecar(sidecar) => inspect_data_column(ctx, sidecar, &mut failures),
+    };
+
+    let success = failures.is_empty();
+    json["valid"] = Value::Bool(success);
+    json["failures"] = json!(failures);
+    (json, success)
+}
+
+fn inspect_data_column(
+    ctx: &DASContext,
+    sidecar: &DataColumnSidecarCells,
+    failures: &mut Vec<String>,
+) -> Value {
+    let DataColumnSidecarCells {
+        index,
+        column,
+        kzg_commitments,
+        kzg_proofs,
+    } = sidecar;
+
+    if *index >= CELLS_PER_EXT_BLOB as u64 {
+        failures.push(format!(
+            "index: {index} is not less than {CELLS_PER_EXT_BLOB}"
+        ));
+    }
+    if column.len() != kzg_commitments.len() || column.len() != kzg_proofs.len() {
+        failures.push(format!(
+            "the sidecar has {} cells, {} kzg_commitments and {} kzg_proofs",
+            column.len(),
+            kzg_commitments.len(),
+            kzg_proofs.len()
+        ));
+    }
+    if column.is_empty() {
+        failures.push("column: the sidecar has no cells".to_string());
+    }
+
+    // Each row is checked on its own only if the batch fails, or cannot be run.
+    let rows = column
+        .len()
+        .min(kzg_commitments.len())
+        .min(kzg_proofs.len());
+    let batch_is_valid = failures.is_empty()
+        && verify_rows(
+            ctx,
+            *index,
+            &column[..rows],
+            &kzg_commitments[..rows],
+            &kzg_proofs[..rows],
+        )
+        .is_ok();
+
+    let cells: Vec<Value> = (0..rows)
+        .map(|row| {
+            let status = if batch_is_valid {
+                Ok(())
+            } else {
+                verify_rows(
+                    ctx,
+                    *index,
+                    &column[row..=row],
+                    &kzg_commitments[row..=row],
+                    &kzg_proofs[row..=row],
+                )
+            };
+            if let Err(reason) = &status {
+                failures.push(format!("row {row}: {reason}"));
+            }
+            json!({
+                "row": row,
+                "kzg_commitment": kzg_commitments[row].to_hex(),
+                "versioned_hash": kzg_to_versioned_hash(&kzg_commitments[row]).to_hex(),
+                "kzg_proof": kzg_proofs[row].to_hex(),
+                "valid": status.is_ok(),
+            })
+        })
+        .collect();
+
+    json!({
+        "kind": "data_column_sidecar",
+        "index": index,
+        "cells": cells,
+    })
+}
+
+/// Verifies the cells of the column at `index` against their commitments and proofs.
+fn verify_rows(
+    ctx: &DASContext,
+    index: u64,
+    cells: &[Cell],
+    commitments: &[KZGCommitment],
+    proofs: &[KZGProof],
+) -> Result<(), String> {
+    ctx.verify_cell_kzg_proof_batch(
+        commitments.iter().collect(),
+        &vec![index; cells.len()],
+        cells.iter().map(|cell| &**cell).collect(),
+        proofs.iter().collect(),
+    )
+    .map_err(|err| {
+        if err.is_proof_invalid() {
+            "the kzg_proof does not verify against the cell and kzg_commitment".to_string()
+        } else {
+            err.code().to_string()
+        }
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use rust_eth_kzg::{
+        constants::BYTES_PER_BLOB, kzg_to_versioned_hash, ssz::DataColumnSidecarCells, DASContext,
+        PrefixedHex,
+    };
+    use serde_json::json;
+
+    use super::{inspect, BLOB_SIDECAR_LEN};
+
+    fn test_blob(seed: u8) -> Box<[u8; BYTES_PER_BLOB]> {
+        let mut blob = Box::new([0u8; BYTES_PER_BLOB]);
+        blob[31] = seed;
+        blob
+    }
+
+    #[test]
+    fn versioned_hashes_match_eip_4844() {
+        // The versioned hash of the commitment to the zero polynomial, ie the point at infinity.
+        let mut commitment = [0u8; 48];
+        commitment[0] = 0xc0;
+        assert_eq!(
+            kzg_to_versioned_hash(&commitment).to_hex(),
+            "0x010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c444014"
+        );
+    }
+
+    #[test]
+    fn blob_sidecars_are_checked() {
+        let ctx = DASContext::default();
+        let blob = test_blob(1);
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let proof = ctx.compute_blob_kzg_proof(&blob, &commitment).unwrap();
+
+        let mut ssz = 5u64.to_le_bytes().to_vec();
+        ssz.extend_from_slice(blob.as_slice());
+        ssz.extend_from_slice(&commitment);
+        ssz.extend_from_slice(&proof);
+        ssz.resize(BLOB_SIDECAR_LEN, 0);
+        let report = inspect(&ctx, &ssz).unwrap();
+        assert!(report.success);
+        assert_eq!(report.json["index"], 5);
+        assert_eq!(report.json["kzg_commitment"], commitment.to_hex());
+
+        // A sidecar from the beacon API, with the proof of another blob.
+        let other_blob = test_blob(2);
+        let other_commitment = ctx.blob_to_kzg_commitment(&other_blob).unwrap();
+        let other_proof = ctx
+            .compute_blob_kzg_proof(&other_blob, &other_commitment)
+            .unwrap();
+        let sidecar = json!({ "data": [{
+            "index": "0",
+            "blob": blob.to_hex(),
+            "kzg_commitment": commitment.to_hex(),
+            "kzg_proof": other_proof.to_hex(),
+        }]});
+        let report = inspect(&ctx, sidecar.to_string().as_bytes()).unwrap();
+        assert!(!report.success);
+        assert_eq!(
+            report.json["failures"][0]
+                .as_str()
+                .unwrap()
+                .split(':')
+                .next(),
+            Some("kzg_proof")
+        );
+    }
+
+    #[test]
+    fn bad_cells_are_pinpointed() {
+        let ctx = DASContext::default();
+        let index = 9;
+        let mut sidecar = DataColumnSidecarCells {
+            index,
+            ..Default::default()
+        };
+        for seed in 1..=3 {
+            let blob = test_blob(seed);
+            let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+            sidecar.column.push(cells[index as usize].clone());
+            sidecar
+                .kzg_commitments
+                .push(ctx.blob_to_kzg_commitment(&blob).unwrap());
+            sidecar.kzg_proofs.push(proofs[index as usize]);
+        }
+
+        let to_json = |sidecar: &DataColumnSidecarCells| {
+            json!({
+                "index": sidecar.index.to_string(),
+                "column": sidecar.column.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+                "kzg_commitments": sidecar.kzg_commitments.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+                "kzg_proofs": sidecar.kzg_proofs.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+            })
+            .to_string()
+        };
+        assert!(inspect(&ctx, to_json(&sidecar).as_bytes()).unwrap().success);
+
+        sidecar.kzg_proofs.swap(1, 2);
+        let report = inspect(&ctx, to_json(&sidecar).as_bytes()).unwrap();
+        assert!(!report.success);
+        let cells = report.json["cells"].as_array().unwrap();
+        let valid: Vec<_> = cells.iter().map(|cell| cell["valid"].as_bool()).collect();
+        assert_eq!(valid, [Some(true), Some(false), Some(false)]);
+        assert_eq!(report.json["failures"].as_array().unwrap().len(), 2);
+    }
+}
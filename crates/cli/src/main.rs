@@ -0,0 +1,296 @@
+//! `eth-kzg`: commits to, proves, verifies and recovers blobs and cells from the command line.
+//!
+//! Every command prints a JSON object, and exits with 0 on success, 1 if a proof does not
+//! verify, a sidecar has an invalid claim or a test vector fails, and 2 if the inputs could
+//! not be read or were rejected.
+
+mod bench;
+mod commands;
+mod errors;
+mod input;
+mod inspect;
+mod vectors;
+
+use std::{
+    io::Write,
+    path::{Path, PathBuf},
+    process::ExitCode,
+};
+
+use clap::{Parser, Subcommand, ValueEnum};
+use rust_eth_kzg::{
+    test_vectors, DASContext, KZGCommitment, KZGProof, SerializedScalar, TrustedSetup,
+};
+
+use crate::{bench::BenchConfig, commands::Report, errors::Error, input::parse_hex_arg};
+
+#[derive(Parser)]
+#[command(name = "eth-kzg", version, about)]
+struct Cli {
+    /// The trusted setup, as JSON or a precomputed cache.
+    ///
+    /// Defaults to the file named by `ETH_KZG_TRUSTED_SETUP`, or else the embedded
+    /// Ethereum trusted setup.
+    #[arg(long, global = true, value_name = "PATH")]
+    trusted_setup: Option<PathBuf>,
+
+    /// Uses the trusted setup without checking that its points are powers of the same secret.
+    ///
+    /// The check takes a fraction of a second, and catches files that are corrupted or were
+    /// tampered with, so this should only be passed for setups that are known to be good.
+    #[arg(long, global = true)]
+    unchecked_setup: bool,
+
+    /// Writes the output to a file instead of stdout.
+    #[arg(long, short, global = true, value_name = "FILE")]
+    output: Option<PathBuf>,
+
+    #[command(subcommand)]
+    command: Command,
+}
+
+/// Blobs are read from files holding their raw bytes, a hex string, or JSON with a hex
+/// string or a `blob` field. `-` reads from stdin.
+#[derive(Subcommand)]
+enum Command {
+    /// Computes the commitment to a blob.
+    Commit { blob: PathBuf },
+    /// Computes the commitment and blob proof of a blob, or the proof of its evaluation at a
+    /// point.
+    Prove {
+        blob: PathBuf,
+        /// The point to open the blob at, as a 32 byte field element.
+        #[arg(long, value_parser = parse_hex_arg::<32>)]
+        z: Option<SerializedScalar>,
+    },
+    /// Checks a proof.
+    #[command(subcommand)]
+    Verify(Verify),
+    /// Extends a blob into its cells and computes their proofs.
+    Cells { blob: PathBuf },
+    /// Recovers every cell and proof of a blob from a JSON file with at least half of its
+    /// cells, in the format written by `cells`.
+    Recover { file: PathBuf },
+    /// Measures the throughput of committing, computing cells and proofs, verifying cells and
+    /// recovering blobs on this host.
+    Bench {
+        /// The comma separated thread counts to measure, where 0 is one thread per CPU.
+        #[arg(long, value_delimiter = ',', default_value = "1,0")]
+        threads: Vec<usize>,
+        /// The number of blobs that each operation is run on.
+        #[arg(long, default_value_t = 8)]
+        batch_size: usize,
+        /// The number of times each operation is run, of which the fastest is reported.
+        #[arg(long, default_value_t = 3)]
+        iterations: usize,
+    },
+    /// Decodes a blob, blob sidecar or data column sidecar, as SSZ or beacon API JSON, prints
+    /// its commitments and versioned hashes, and reports which of its proofs do not verify.
+    Inspect { file: PathBuf },
+    /// Generates and checks test vectors in the format of consensus-spec-tests.
+    #[command(subcommand)]
+    Vectors(Vectors),
+}
+
+#[derive(Subcommand)]
+enum Verify {
+    /// Checks the blob proof of a blob.
+    Blob {
+        blob: PathBuf,
+        #[arg(long, value_parser = parse_hex_arg::<48>)]
+        commitment: KZGCommitment,
+        #[arg(long, value_parser = parse_hex_arg::<48>)]
+        proof: KZGProof,
+    },
+    /// Checks the proof that a committed polynomial evaluates to `y` at `z`.
+    Point {
+        #[arg(long, value_parser = parse_hex_arg::<48>)]
+        commitment: KZGCommitment,
+        #[arg(long, value_parser = parse_hex_arg::<32>)]
+        z: SerializedScalar,
+        #[arg(long, value_parser = parse_hex_arg::<32>)]
+        y: SerializedScalar,
+        #[arg(long, value_parser = parse_hex_arg::<48>)]
+        proof: KZGProof,
+    },
+    /// Checks the proofs of a JSON file of cells, in the format written by `cells`.
+    ///
+    /// The file needs `commitment` or `commitments`, `cells` and `proofs`, and `cell_indices`
+    /// unless every cell is given.
+    Cells { file: PathBuf },
+}
+
+#[derive(Subcommand)]
+enum Vectors {
+    /// Generates valid and invalid cases for every spec function below a directory.
+    Generate {
+        dir: PathBuf,
+        /// The seed of the first set of cases.
+        #[arg(long, default_value_t = 0)]
+        seed: u64,
+        /// The number of sets of cases, each generated from the next seed.
+        #[arg(long, default_value_t = 1)]
+        count: u64,
+        #[arg(long, value_enum, default_value_t = VectorFormat::Yaml)]
+        format: VectorFormat,
+    },
+    /// Checks every `data.yaml` or `data.json` case below a directory against the library.
+    Check {
+        dir: PathBuf,
+        /// Only checks the handlers whose name contains this string.
+        #[arg(long)]
+        filter: Option<String>,
+    },
+}
+
+/// The encoding of the generated cases.
+#[derive(Clone, Copy, ValueEnum)]
+enum VectorFormat {
+    /// `data.yaml` files, like consensus-spec-tests.
+    Yaml,
+    /// `data.json` files.
+    Json,
+}
+
+impl From<VectorFormat> for test_vectors::Format {
+    fn from(value: VectorFormat) -> Self {
+        match value {
+            VectorFormat::Yaml => Self::Yaml,
+            VectorFormat::Json => Self::Json,
+        }
+    }
+}
+
+/// Loads a trusted setup, turning a panic of the parser into an [`Error::Setup`].
+///
+/// The parsers panic on malformed points, since libraries normally load the setup on
+/// startup, but a corrupt file passed to the CLI is an input error like any other.
+fn load_trusted_setup(
+    load: impl FnOnce() -> std::io::Result<TrustedSetup>,
+) -> Result<std::io::Result<TrustedSetup>, Error> {
+    // The default hook would print the panic and a backtrace hint on top of the error.
+    let hook = std::panic::take_hook();
+    std::panic::set_hook(Box::new(|_| {}));
+    let result = std::panic::catch_unwind(std::panic::AssertUnwindSafe(load));
+    std::panic::set_hook(hook);
+
+    result.map_err(|panic| {
+        let reason = panic
+            .downcast_ref::<&str>()
+            .map(|reason| (*reason).to_string())
+            .or_else(|| panic.downcast_ref::<String>().cloned())
+            .unwrap_or_else(|| "malformed points".to_string());
+        Error::Setup(format!("the trusted setup is malformed: {reason}"))
+    })
+}
+
+fn load_context(trusted_setup: Option<&Path>, verify_setup: bool) -> Result<DASContext, Error> {
+    let trusted_setup = match trusted_setup {
+        Some(path) => load_trusted_setup(|| TrustedSetup::from_path(path))?.map_err(|source| {
+            Error::Io {
+                path: path.to_path_buf(),
+                source,
+            }
+        })?,
+        None => load_trusted_setup(TrustedSetup::from_env)?
+            .map_err(|err| Error::Setup(format!("could not load the trusted setup: {err}")))?,
+    };
+    Ok(DASContext::builder()
+        .trusted_setup(&trusted_setup)
+        .verify_setup(verify_setup)
+        .build()?)
+}
+
+fn run(cli: &Cli) -> Result<Report, Error> {
+    let mut ctx = load_context(cli.trusted_setup.as_deref(), !cli.unchecked_setup)?;
+    match &cli.command {
+        Command::Commit { blob } => commands::commit(&ctx, blob),
+        Command::Prove { blob, z } => commands::prove(&ctx, blob, *z),
+        Command::Verify(Verify::Blob {
+            blob,
+            commitment,
+            proof,
+        }) => commands::verify_blob(&ctx, blob, commitment, proof),
+        Command::Verify(Verify::Point {
+            commitment,
+            z,
+            y,
+            proof,
+        }) => commands::verify_point(&ctx, commitment, *z, *y, proof),
+        Command::Verify(Verify::Cells { file }) => commands::verify_cells(&ctx, file),
+        Command::Cells { blob } => commands::cells(&ctx, blob),
+        Command::Recover { file } => commands::recover(&ctx, file),
+        Command::Bench {
+            threads,
+            batch_size,
+            iterations,
+        } => bench::bench(
+            &mut ctx,
+            &BenchConfig {
+                threads: threads.clone(),
+                batch_size: *batch_size,
+                iterations: *iterations,
+            },
+        ),
+        Command::Inspect { file } => inspect::inspect(&ctx, &input::read_file(file)?),
+        Command::Vectors(Vectors::Generate {
+            dir,
+            seed,
+            count,
+            format,
+        }) => vectors::generate(&ctx, dir, *seed, *count, (*format).into()),
+        Command::Vectors(Vectors::Check { dir, filter }) => {
+            vectors::check(&ctx, dir, filter.as_deref())
+        }
+    }
+}
+
+fn write_output(path: Option<&Path>, report: &Report) -> Result<(), Error> {
+    let mut json = serde_json::to_string_pretty(&report.json)?;
+    json.push('\n');
+    let result = match path {
+        Some(path) => std::fs::write(path, json),
+        None => std::io::stdout().write_all(json.as_bytes()),
+    };
+    result.map_err(|source| Error::Io {
+        path: path.unwrap_or(Path::new("-")).to_path_buf(),
+        source,
+    })
+}
+
+fn main() -> ExitCode {
+    let cli = Cli::parse();
+    let result = run(&cli).and_then(|report| {
+        write_output(cli.output.as_deref(), &report)?;
+        Ok(report.success)
+    });
+    match result {
+        Ok(true) => ExitCode::SUCCESS,
+        Ok(false) => ExitCode::from(1),
+        Err(err) => {
+            eprintln!("error: {err}");
+            ExitCode::from(2)
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::load_context;
+    use crate::errors::Error;
+
+    #[test]
+    fn corrupt_trusted_setup_is_an_error() {
+        let path = std::env::temp_dir().join(format!("eth-kzg-setup-{}.json", std::process::id()));
+        std::fs::write(
+            &path,
+            r#"{"g1_monomial": ["0xnot a point"], "g1_lagrange": [], "g2_monomial": []}"#,
+        )
+        .unwrap();
+
+        let result = load_context(Some(&path), true);
+        std::fs::remove_file(&path).unwrap();
+
+        assert!(matches!(result, Err(Error::Setup(msg)) if msg.contains("malformed")));
+    }
+}
@@ -0,0 +1,103 @@
+//! `eth-kzg vectors`: generates test vectors in the format of consensus-spec-tests, and
+//! checks a directory of vectors against the library.
+//!
+//! The vectors are generated by [`rust_eth_kzg::test_vectors`] and checked by the runner of
+//! the spec tests, so that fixtures which pass here also pass in `spec-tests`.
+
+use std::path::Path;
+
+use rust_eth_kzg::{test_vectors, DASContext};
+use serde_json::json;
+use spec_tests::{run_dir, Outcome, Summary};
+
+use crate::{commands::Report, errors::Error};
+
+/// Writes the cases generated from `count` seeds, starting at `first_seed`, below `dir`.
+pub fn generate(
+    ctx: &DASContext,
+    dir: &Path,
+    first_seed: u64,
+    count: u64,
+    format: test_vectors::Format,
+) -> Result<Report, Error> {
+    let seeds = first_seed..first_seed.saturating_add(count);
+    let cases: Vec<_> = seeds
+        .clone()
+        .flat_map(|seed| test_vectors::generate(ctx, seed))
+        .collect();
+    test_vectors::write_to_dir(&cases, dir, format).map_err(|source| Error::Io {
+        path: dir.to_path_buf(),
+        source,
+    })?;
+
+    Ok(Report {
+        json: json!({
+            "dir": dir,
+            "seeds": seeds.collect::<Vec<_>>(),
+            "cases": cases.len(),
+        }),
+        success: true,
+    })
+}
+
+/// Runs every case below `dir` whose handler contains `filter`, and lists the cases that
+/// failed.
+pub fn check(ctx: &DASContext, dir: &Path, filter: Option<&str>) -> Result<Report, Error> {
+    let reports = run_dir(ctx, dir, filter).map_err(|source| Error::Io {
+        path: dir.to_path_buf(),
+        source,
+    })?;
+    let summary = Summary::from_reports(&reports);
+    let failures: Vec<_> = reports
+        .iter()
+        .filter(|report| matches!(report.outcome, Outcome::Failed(_)))
+        .collect();
+
+    Ok(Report {
+        json: json!({
+            "summary": summary,
+            "failures": failures,
+        }),
+        success: summary.failed == 0,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use rust_eth_kzg::{test_vectors::Format, DASContext};
+
+    use super::{check, generate};
+
+    #[test]
+    fn generated_vectors_check() {
+        let ctx = DASContext::default();
+        let dir = std::env::temp_dir().join(format!("eth-kzg-vectors-{}", std::process::id()));
+
+        let generated = generate(&ctx, &dir, 7, 2, Format::Json).unwrap();
+        let checked = check(&ctx, &dir, None).unwrap();
+        let filtered = check(&ctx, &dir, Some("recover")).unwrap();
+
+        // Break a case by flipping its expected output.
+        let case = dir.join(
+            "verify_kzg_proof/kzg-mainnet/verify_kzg_proof_case_valid_0000000000000007/data.json",
+        );
+        let contents = std::fs::read_to_string(&case).unwrap();
+        std::fs::write(
+            &case,
+            contents.replace("\"output\": true", "\"output\": false"),
+        )
+        .unwrap();
+        let broken = check(&ctx, &dir, None).unwrap();
+        std::fs::remove_dir_all(&dir).unwrap();
+
+        assert_eq!(generated.json["seeds"], serde_json::json!([7, 8]));
+        assert!(checked.success);
+        assert_eq!(checked.json["summary"]["passed"], generated.json["cases"]);
+        assert!(
+            filtered.json["summary"]["passed"].as_u64()
+                < checked.json["summary"]["passed"].as_u64()
+        );
+        assert!(!broken.success);
+        assert_eq!(broken.json["failures"][0]["handler"], "verify_kzg_proof");
+    }
+}
@@ -0,0 +1,144 @@
+//! Conversions between the types of this crate and the types of `ark-bls12-381`.
+//!
+//! Both sides are types of other crates, so `From` and `TryFrom` cannot be implemented
+//! between them here. [`ArkworksConversion`] plays their role instead:
+//!
+//! ```ignore
+//! use rust_eth_kzg::arkworks::ArkworksConversion;
+//!
+//! let commitment: ark_bls12_381::G1Affine = commitment_point.to_arkworks();
+//! let point = G1Point::try_from_arkworks(&commitment)?;
+//! ```
+//!
+//! Points are converted through their uncompressed encoding, which `ark-bls12-381` shares
+//! with this crate, so no square roots are computed. Points coming from arkworks are checked
+//! to be on the curve and in the prime-order subgroup, since arkworks allows creating points
+//! that are neither.
+
+use ark_serialize::{CanonicalDeserialize, CanonicalSerialize};
+
+use crate::{G1Point, G2Point, Scalar};
+
+/// The point given to [`ArkworksConversion::try_from_arkworks`] is not on the curve or not
+/// in the prime-order subgroup.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct InvalidArkworksPoint;
+
+/// Converts a type of this crate to and from the matching type of `ark-bls12-381`.
+pub trait ArkworksConversion: Sized {
+    /// The matching type of `ark-bls12-381`.
+    type Ark;
+
+    /// Returns the arkworks value that represents `self`.
+    fn to_arkworks(&self) -> Self::Ark;
+
+    /// Returns the value that represents `value`, if it is valid.
+    fn try_from_arkworks(value: &Self::Ark) -> Result<Self, InvalidArkworksPoint>;
+}
+
+impl ArkworksConversion for G1Point {
+    type Ark = ark_bls12_381::G1Affine;
+
+    fn to_arkworks(&self) -> Self::Ark {
+        // The point was checked when it was created, so it is not checked again.
+        Self::Ark::deserialize_uncompressed_unchecked(self.to_uncompressed().as_slice())
+            .expect("arkworks uses the same uncompressed encoding")
+    }
+
+    fn try_from_arkworks(value: &Self::Ark) -> Result<Self, InvalidArkworksPoint> {
+        let mut bytes = [0u8; 96];
+        value
+            .serialize_uncompressed(bytes.as_mut_slice())
+            .expect("an uncompressed G1 point is 96 bytes");
+        Option::from(Self::from_uncompressed(&bytes)).ok_or(InvalidArkworksPoint)
+    }
+}
+
+impl ArkworksConversion for G2Point {
+    type Ark = ark_bls12_381::G2Affine;
+
+    fn to_arkworks(&self) -> Self::Ark {
+        Self::Ark::deserialize_uncompressed_unchecked(self.to_uncompressed().as_slice())
+            .expect("arkworks uses the same uncompressed encoding")
+    }
+
+    fn try_from_arkworks(value: &Self::Ark) -> Result<Self, InvalidArkworksPoint> {
+        let mut bytes = [0u8; 192];
+        value
+            .serialize_uncompressed(bytes.as_mut_slice())
+            .expect("an uncompressed G2 point is 192 bytes");
+        Option::from(Self::from_uncompressed(&bytes)).ok_or(InvalidArkworksPoint)
+    }
+}
+
+impl ArkworksConversion for Scalar {
+    type Ark = ark_bls12_381::Fr;
+
+    fn to_arkworks(&self) -> Self::Ark {
+        Self::Ark::deserialize_uncompressed(self.to_bytes_le().as_slice())
+            .expect("a scalar is always canonical")
+    }
+
+    /// Never fails, since every arkworks scalar is canonical.
+    fn try_from_arkworks(value: &Self::Ark) -> Result<Self, InvalidArkworksPoint> {
+        let mut bytes = [0u8; 32];
+        value
+            .serialize_uncompressed(bytes.as_mut_slice())
+            .expect("a scalar is 32 bytes");
+        Ok(Option::from(Self::from_bytes_le(&bytes))
+            .expect("an arkworks scalar is always canonical"))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use ark_ec::{short_weierstrass::Affine, AffineRepr, CurveGroup};
+    use rand::rngs::OsRng;
+
+    use super::{ArkworksConversion, InvalidArkworksPoint};
+    use crate::{traits::*, G1Point, G2Point, Scalar};
+
+    #[test]
+    fn generators_and_identities_match() {
+        assert_eq!(
+            G1Point::generator().to_arkworks(),
+            ark_bls12_381::G1Affine::generator()
+        );
+        assert_eq!(
+            G2Point::generator().to_arkworks(),
+            ark_bls12_381::G2Affine::generator()
+        );
+        assert!(G1Point::identity().to_arkworks().is_zero());
+        assert_eq!(
+            G2Point::try_from_arkworks(&ark_bls12_381::G2Affine::zero()),
+            Ok(G2Point::identity())
+        );
+    }
+
+    #[test]
+    fn conversions_commute_with_scalar_multiplication() {
+        let scalar = Scalar::random(OsRng);
+        let ark_scalar = scalar.to_arkworks();
+        assert_eq!(Scalar::try_from_arkworks(&ark_scalar), Ok(scalar));
+
+        let point = (G1Point::generator() * scalar).to_affine();
+        let ark_point = (ark_bls12_381::G1Affine::generator() * ark_scalar).into_affine();
+        assert_eq!(point.to_arkworks(), ark_point);
+        assert_eq!(G1Point::try_from_arkworks(&ark_point), Ok(point));
+
+        let point = (G2Point::generator() * scalar).to_affine();
+        let ark_point = (ark_bls12_381::G2Affine::generator() * ark_scalar).into_affine();
+        assert_eq!(point.to_arkworks(), ark_point);
+        assert_eq!(G2Point::try_from_arkworks(&ark_point), Ok(point));
+    }
+
+    #[test]
+    fn points_off_the_curve_are_rejected() {
+        let one = ark_bls12_381::Fq::from(1u64);
+        let not_on_curve: ark_bls12_381::G1Affine = Affine::new_unchecked(one, one);
+        assert_eq!(
+            G1Point::try_from_arkworks(&not_on_curve),
+            Err(InvalidArkworksPoint)
+        );
+    }
+}
@@ -0,0 +1,95 @@
+//! Reports which field arithmetic blst dispatches to on the current CPU.
+//!
+//! blst is compiled in portable mode (see the `portable` feature on `blstrs`), which means
+//! both the generic and the ADX/BMI2 assembly paths are built into the binary and blst
+//! selects between them at runtime using CPUID. A single released binary is therefore fast
+//! on modern CPUs while still running on older ones.
+//!
+//! This crate has no field arithmetic of its own to switch between, so this module does not
+//! select anything. It only performs the same detection as blst, so that callers can find
+//! out which path blst takes on this machine, for example when logging or benchmarking.
+use std::sync::OnceLock;
+
+/// The implementation of the base field arithmetic that blst selects for this CPU.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum BlstArithmetic {
+    /// Portable implementation that runs on any CPU.
+    Generic,
+    /// Assembly that uses the ADX and BMI2 instructions (`adcx`, `adox`, `mulx`).
+    AdxBmi2,
+}
+
+/// The CPU features that blst checks for when selecting its field arithmetic.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub struct CpuFeatures {
+    /// Multi-precision add-carry instructions.
+    pub adx: bool,
+    /// Bit manipulation instructions, including `mulx`.
+    pub bmi2: bool,
+}
+
+impl CpuFeatures {
+    /// Detects the features of the CPU that the process is currently running on.
+    ///
+    /// Detection only happens once, the result is cached for subsequent calls.
+    pub fn detect() -> Self {
+        static FEATURES: OnceLock<CpuFeatures> = OnceLock::new();
+        *FEATURES.get_or_init(Self::detect_uncached)
+    }
+
+    #[cfg(target_arch = "x86_64")]
+    fn detect_uncached() -> Self {
+        Self {
+            adx: std::is_x86_feature_detected!("adx"),
+            bmi2: std::is_x86_feature_detected!("bmi2"),
+        }
+    }
+
+    #[cfg(not(target_arch = "x86_64"))]
+    fn detect_uncached() -> Self {
+        Self::default()
+    }
+
+    /// Returns the field arithmetic implementation that blst dispatches to on this CPU.
+    pub const fn blst_arithmetic(&self) -> BlstArithmetic {
+        if self.adx && self.bmi2 {
+            BlstArithmetic::AdxBmi2
+        } else {
+            BlstArithmetic::Generic
+        }
+    }
+}
+
+/// Returns the field arithmetic implementation that blst dispatches to on the current CPU.
+pub fn blst_arithmetic() -> BlstArithmetic {
+    CpuFeatures::detect().blst_arithmetic()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn detection_is_stable() {
+        assert_eq!(CpuFeatures::detect(), CpuFeatures::detect());
+        assert_eq!(
+            blst_arithmetic(),
+            CpuFeatures::detect().blst_arithmetic()
+        );
+    }
+
+    #[test]
+    fn assembly_requires_both_adx_and_bmi2() {
+        let only_adx = CpuFeatures {
+            adx: true,
+            bmi2: false,
+        };
+        assert_eq!(only_adx.blst_arithmetic(), BlstArithmetic::Generic);
+
+        let both = CpuFeatures {
+            adx: true,
+            bmi2: true,
+        };
+        assert_eq!(both.blst_arithmetic(), BlstArithmetic::AdxBmi2);
+    }
+}
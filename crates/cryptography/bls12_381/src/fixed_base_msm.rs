@@ -1,9 +1,15 @@
+use std::io::{self, Read, Write};
+
 use blst::blst_p1_affine;
 use blstrs::{Fp, G1Affine};
 
 use crate::{
-    fixed_base_msm_window::FixedBaseMSMPrecompWindow, lincomb::g1_lincomb, traits::*, G1Projective,
-    Scalar,
+    fixed_base_msm_compressed::FixedBaseMSMPrecompWindowCompressed,
+    fixed_base_msm_window::FixedBaseMSMPrecompWindow,
+    lincomb::g1_lincomb,
+    table_io::{invalid_data, read_points, write_points},
+    traits::*,
+    G1Projective, Scalar,
 };
 
 /// A precomputed structure for performing fixed-base multi-scalar multiplication (MSM) in G1 using BLST.
@@ -37,17 +43,82 @@ pub struct FixedBaseMSMPrecompBLST {
 
 /// UsePrecomp indicates whether we should use pre-computations to speed up the MSM
 /// and the level of precomputation to perform.
+///
+/// More ways to precompute may be added, so matches on it need a wildcard arm.
 #[derive(Debug, Copy, Clone)]
+#[non_exhaustive]
 pub enum UsePrecomp {
     /// Enables fixed-base precomputation with a specified window size (in bits).
     Yes {
         /// Window size in bits.
         width: usize,
     },
+    /// Enables fixed-base precomputation with a specified window size (in bits), storing only
+    /// the odd multiples of each point.
+    ///
+    /// The tables are half the size of those of `Yes` with the same width, and MSMs are a bit
+    /// slower, but still much faster than without precomputation. This suits nodes that are
+    /// short on memory: `Compressed { width: 8 }` uses the memory of `Yes { width: 7 }`.
+    Compressed {
+        /// Window size in bits.
+        width: usize,
+    },
     /// Disables fixed-base precomputation.
     No,
 }
 
+impl UsePrecomp {
+    /// The largest window width that [`UsePrecomp::from_width`] accepts, which is also the
+    /// largest `precompute` value of c-kzg-4844.
+    ///
+    /// The tables hold `2^(width - 1)` multiples of every point, so much larger widths would
+    /// overflow the table size or exhaust memory.
+    pub const MAX_WIDTH: usize = 15;
+
+    /// Returns the precomputation policy for a given window width, where a width of zero
+    /// disables precomputation, or `None` if the width is larger than [`UsePrecomp::MAX_WIDTH`].
+    ///
+    /// Each increment of the width roughly doubles the size of the precomputed tables and
+    /// reduces the number of additions done per MSM, so this allows callers (and the FFI,
+    /// where enums with data are awkward) to trade memory for proving speed with a single integer.
+    pub const fn from_width(width: usize) -> Option<Self> {
+        if width > Self::MAX_WIDTH {
+            None
+        } else if width == 0 {
+            Some(Self::No)
+        } else {
+            Some(Self::Yes { width })
+        }
+    }
+
+    /// Returns the compressed precomputation policy for a given window width, where a width
+    /// of zero disables precomputation, see [`UsePrecomp::Compressed`]. Like
+    /// [`UsePrecomp::from_width`], this returns `None` if the width is larger than
+    /// [`UsePrecomp::MAX_WIDTH`].
+    pub const fn compressed_from_width(width: usize) -> Option<Self> {
+        if width > Self::MAX_WIDTH {
+            None
+        } else if width == 0 {
+            Some(Self::No)
+        } else {
+            Some(Self::Compressed { width })
+        }
+    }
+
+    /// Returns the window width, or zero if precomputation is disabled.
+    pub const fn width(&self) -> usize {
+        match self {
+            Self::Yes { width } | Self::Compressed { width } => *width,
+            Self::No => 0,
+        }
+    }
+
+    /// Returns true if only the odd multiples of the points are precomputed.
+    pub const fn is_compressed(&self) -> bool {
+        matches!(self, Self::Compressed { .. })
+    }
+}
+
 /// FixedBaseMSM computes a multi scalar multiplication where the points are known beforehand.
 ///
 /// Since the points are known, one can choose to precompute multiple of the points
@@ -57,6 +128,8 @@ pub enum UsePrecomp {
 pub enum FixedBaseMSM {
     /// Uses a precomputed table for fast fixed-base MSM.
     Precomp(FixedBaseMSMPrecompWindow),
+    /// Uses a precomputed table of the odd multiples of the points, which is half the size.
+    PrecompCompressed(FixedBaseMSMPrecompWindowCompressed),
     /// Falls back to regular scalar multiplication without precomputation.
     NoPrecomp(Vec<G1Affine>),
 }
@@ -65,28 +138,101 @@ impl FixedBaseMSM {
     /// Constructs a `FixedBaseMSM` from a list of fixed generators and a precomputation policy.
     ///
     /// - If `use_precomp` is `Yes`, it builds a precomputed window table for fast fixed-base MSM.
+    /// - If `use_precomp` is `Compressed`, it builds a table of the odd multiples only.
     /// - Otherwise, it stores the generators directly for standard MSM computation.
+    ///
+    /// # Panics
+    /// Panics if the window width is larger than [`UsePrecomp::MAX_WIDTH`].
+    #[cfg_attr(
+        feature = "tracing",
+        tracing::instrument(
+            name = "fixed_base_msm_precompute",
+            skip_all,
+            fields(num_generators = generators.len())
+        )
+    )]
     pub fn new(generators: Vec<G1Affine>, use_precomp: UsePrecomp) -> Self {
+        assert!(
+            use_precomp.width() <= UsePrecomp::MAX_WIDTH,
+            "precomputation width must be at most {}",
+            UsePrecomp::MAX_WIDTH
+        );
         match use_precomp {
             UsePrecomp::Yes { width } => {
                 Self::Precomp(FixedBaseMSMPrecompWindow::new(&generators, width))
             }
+            UsePrecomp::Compressed { width } => Self::PrecompCompressed(
+                FixedBaseMSMPrecompWindowCompressed::new(&generators, width),
+            ),
             UsePrecomp::No => Self::NoPrecomp(generators),
         }
     }
 
+    /// Returns the number of bytes used by the generators, or by the precomputed tables
+    /// if precomputation is enabled.
+    pub fn size_in_bytes(&self) -> usize {
+        match self {
+            Self::Precomp(precomp) => precomp.size_in_bytes(),
+            Self::PrecompCompressed(precomp) => precomp.size_in_bytes(),
+            Self::NoPrecomp(generators) => generators.len() * std::mem::size_of::<G1Affine>(),
+        }
+    }
+
     /// Computes a multi-scalar multiplication (MSM) using the stored generators and given scalars.
     ///
     /// - If precomputation is enabled, it uses the optimized windowed method;
     /// - Otherwise, it falls back to a standard linear combination.
     ///   Panics if the number of scalars doesn't match the number of generators.
+    #[cfg_attr(
+        feature = "tracing",
+        tracing::instrument(name = "fixed_base_msm", skip_all, fields(num_scalars = scalars.len()))
+    )]
     pub fn msm(&self, scalars: &[Scalar]) -> G1Projective {
         match self {
             Self::Precomp(precomp) => precomp.msm(scalars),
+            Self::PrecompCompressed(precomp) => precomp.msm(scalars),
             Self::NoPrecomp(generators) => g1_lincomb(generators, scalars)
                 .expect("number of generators and scalars should be equal"),
         }
     }
+
+    /// Serializes the generators and any precomputed tables.
+    ///
+    /// This allows the (potentially expensive) precomputation to be done once and
+    /// reloaded with [`FixedBaseMSM::read_from`].
+    pub fn write_to<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        match self {
+            Self::Precomp(precomp) => {
+                writer.write_all(&[1])?;
+                precomp.write_to(writer)
+            }
+            Self::PrecompCompressed(precomp) => {
+                writer.write_all(&[2])?;
+                precomp.write_to(writer)
+            }
+            Self::NoPrecomp(generators) => {
+                writer.write_all(&[0])?;
+                write_points(writer, generators)
+            }
+        }
+    }
+
+    /// Deserializes a `FixedBaseMSM` written by [`FixedBaseMSM::write_to`].
+    ///
+    /// The precomputed tables are not recomputed or checked against the generators, so they
+    /// must come from a trusted source, such as a cache written by this library.
+    pub fn read_from<R: Read>(reader: &mut R) -> io::Result<Self> {
+        let mut tag = [0u8; 1];
+        reader.read_exact(&mut tag)?;
+        match tag[0] {
+            0 => Ok(Self::NoPrecomp(read_points(reader)?)),
+            1 => Ok(Self::Precomp(FixedBaseMSMPrecompWindow::read_from(reader)?)),
+            2 => Ok(Self::PrecompCompressed(
+                FixedBaseMSMPrecompWindowCompressed::read_from(reader)?,
+            )),
+            _ => Err(invalid_data("unknown fixed-base MSM variant")),
+        }
+    }
 }
 
 impl FixedBaseMSMPrecompBLST {
@@ -206,6 +352,62 @@ mod tests {
             .collect()
     }
 
+    #[test]
+    fn fixed_base_msm_serialization_roundtrip() {
+        let generators = random_g1_affines(16);
+        let scalars: Vec<_> = (0..16).map(|_| Scalar::random(&mut thread_rng())).collect();
+
+        for use_precomp in [
+            UsePrecomp::No,
+            UsePrecomp::Yes { width: 4 },
+            UsePrecomp::Compressed { width: 4 },
+        ] {
+            let msm = FixedBaseMSM::new(generators.clone(), use_precomp);
+
+            let mut bytes = Vec::new();
+            msm.write_to(&mut bytes).unwrap();
+            let restored = FixedBaseMSM::read_from(&mut bytes.as_slice()).unwrap();
+
+            assert_eq!(msm.msm(&scalars), restored.msm(&scalars));
+        }
+    }
+
+    #[test]
+    fn fixed_base_msm_rejects_truncated_bytes() {
+        let msm = FixedBaseMSM::new(random_g1_affines(4), UsePrecomp::Yes { width: 4 });
+        let mut bytes = Vec::new();
+        msm.write_to(&mut bytes).unwrap();
+        bytes.truncate(bytes.len() - 1);
+
+        assert!(FixedBaseMSM::read_from(&mut bytes.as_slice()).is_err());
+    }
+
+    #[test]
+    fn use_precomp_from_width_roundtrip() {
+        assert!(matches!(UsePrecomp::from_width(0), Some(UsePrecomp::No)));
+        assert!(matches!(
+            UsePrecomp::from_width(8),
+            Some(UsePrecomp::Yes { width: 8 })
+        ));
+        for width in [0, 2, 8, 12, UsePrecomp::MAX_WIDTH] {
+            assert_eq!(UsePrecomp::from_width(width).unwrap().width(), width);
+            assert_eq!(
+                UsePrecomp::compressed_from_width(width).unwrap().width(),
+                width
+            );
+        }
+        assert!(UsePrecomp::from_width(UsePrecomp::MAX_WIDTH + 1).is_none());
+        assert!(UsePrecomp::from_width(64).is_none());
+        assert!(UsePrecomp::compressed_from_width(UsePrecomp::MAX_WIDTH + 1).is_none());
+        assert!(UsePrecomp::compressed_from_width(64).is_none());
+        assert!(UsePrecomp::compressed_from_width(8)
+            .unwrap()
+            .is_compressed());
+        assert!(!UsePrecomp::compressed_from_width(0)
+            .unwrap()
+            .is_compressed());
+    }
+
     fn random_scalars(n: usize) -> Vec<Scalar> {
         let mut rng = StdRng::seed_from_u64(1337);
         (0..n).map(|_| Scalar::random(&mut rng)).collect()
@@ -234,6 +436,7 @@ mod tests {
         test_fixed_base_msm_with_precomp(UsePrecomp::No);
         test_fixed_base_msm_with_precomp(UsePrecomp::Yes { width: 4 });
         test_fixed_base_msm_with_precomp(UsePrecomp::Yes { width: 8 });
+        test_fixed_base_msm_with_precomp(UsePrecomp::Compressed { width: 8 });
     }
 
     #[test]
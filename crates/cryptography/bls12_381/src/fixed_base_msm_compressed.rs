@@ -0,0 +1,279 @@
+use std::io::{self, Read, Write};
+
+use blstrs::G1Affine;
+
+use crate::{
+    batch_addition::multi_batch_addition_binary_tree_stride,
+    booth_encoding::get_booth_index,
+    g1_batch_normalize,
+    table_io::{invalid_data, read_len, read_points, write_points, write_u64},
+    traits::*,
+    G1Projective, Scalar,
+};
+
+/// A precomputed window-based structure for fixed-base multi-scalar multiplication (MSM) in G1,
+/// whose tables are half the size of those of [`FixedBaseMSMPrecompWindow`].
+///
+/// The scalars are split into the same Booth-encoded windows as [`FixedBaseMSMPrecompWindow`],
+/// but only the odd multiples of each point are stored. Negative digits negate the point
+/// that is looked up, and an even digit `d = 2^t * m`, with `m` odd, is turned into `m * P`
+/// counted `t` bits higher. The window sums are therefore accumulated per bit instead of
+/// per window, which costs one addition per bit of the scalars instead of one per window,
+/// and spreads the batched additions over more, smaller buckets.
+///
+/// Precomputation per point, for `wbits >= 2`:
+/// ```text
+///     \text{table}_i = \{P_i, 3P_i, 5P_i, ..., (2^{wbits - 1} - 1)P_i\}
+/// ```
+///
+/// Total memory per point: 2^{wbits - 2} entries, against 2^{wbits - 1} for
+/// [`FixedBaseMSMPrecompWindow`].
+///
+/// [`FixedBaseMSMPrecompWindow`]: crate::fixed_base_msm_window::FixedBaseMSMPrecompWindow
+#[derive(Debug)]
+pub struct FixedBaseMSMPrecompWindowCompressed {
+    /// A 2D table where each row contains the odd multiples of a single G1 base point,
+    /// in affine form.
+    table: Vec<Vec<G1Affine>>,
+    /// Number of bits per window (window size) of the Booth encoding of the scalars.
+    wbits: usize,
+}
+
+impl FixedBaseMSMPrecompWindowCompressed {
+    /// Constructs a new `FixedBaseMSMPrecompWindowCompressed` by precomputing the odd
+    /// multiples of input G1 points.
+    ///
+    /// - `points`: G1 base points to precompute.
+    /// - `wbits`: Number of bits per window in the scalar decomposition.
+    pub fn new(points: &[G1Affine], wbits: usize) -> Self {
+        let table = points
+            .iter()
+            .map(|point| Self::precompute_points(wbits, *point))
+            .collect();
+
+        Self { table, wbits }
+    }
+
+    /// Returns the number of odd multiples stored per point, which are the odd numbers up
+    /// to the largest Booth digit `2^{wbits - 1}`.
+    const fn multiples_per_point(wbits: usize) -> usize {
+        1 << wbits.saturating_sub(2)
+    }
+
+    /// Given a point, we precompute P, 3P, ..., (2^{w-1}-1) * P
+    fn precompute_points(wbits: usize, point: G1Affine) -> Vec<G1Affine> {
+        let num_multiples = Self::multiples_per_point(wbits);
+        let mut lookup_table = Vec::with_capacity(num_multiples);
+
+        let double = G1Projective::from(point).double();
+        let mut current = G1Projective::from(point);
+        for _ in 0..num_multiples {
+            lookup_table.push(current);
+            current += double;
+        }
+
+        g1_batch_normalize(&lookup_table)
+    }
+
+    /// Writes the precomputed table so that it can be reloaded with [`Self::read_from`].
+    pub fn write_to<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        write_u64(writer, self.wbits as u64)?;
+        write_u64(writer, self.table.len() as u64)?;
+        for row in &self.table {
+            write_points(writer, row)?;
+        }
+        Ok(())
+    }
+
+    /// Reads a precomputed table written by [`Self::write_to`].
+    ///
+    /// The points are not checked to be the correct multiples of each other, so the
+    /// table must come from a trusted source.
+    pub fn read_from<R: Read>(reader: &mut R) -> io::Result<Self> {
+        let wbits = read_len(reader)?;
+        if wbits == 0 || wbits >= usize::BITS as usize {
+            return Err(invalid_data("invalid window size in precomputed table"));
+        }
+
+        let num_points = read_len(reader)?;
+        let table = (0..num_points)
+            .map(|_| {
+                let row = read_points(reader)?;
+                if row.len() == Self::multiples_per_point(wbits) {
+                    Ok(row)
+                } else {
+                    Err(invalid_data(
+                        "unexpected number of multiples in precomputed table",
+                    ))
+                }
+            })
+            .collect::<io::Result<_>>()?;
+
+        Ok(Self { table, wbits })
+    }
+
+    /// Returns the number of bytes used by the precomputed tables.
+    pub fn size_in_bytes(&self) -> usize {
+        self.table
+            .iter()
+            .map(|row| row.len() * std::mem::size_of::<G1Affine>())
+            .sum()
+    }
+
+    /// Computes a fixed-base multi-scalar multiplication (MSM) using the precomputed odd
+    /// multiples.
+    ///
+    /// # Panics
+    /// - Panics if `scalars.len()` does not match the number of precomputed base points.
+    pub fn msm(&self, scalars: &[Scalar]) -> G1Projective {
+        assert_eq!(
+            scalars.len(),
+            self.table.len(),
+            "Number of scalars must match number of points"
+        );
+
+        let scalars_bytes: Vec<_> = scalars.iter().map(Scalar::to_bytes_le).collect();
+        let number_of_windows = Scalar::NUM_BITS as usize / self.wbits + 1;
+
+        // One bucket per bit: the bucket at index `b` is multiplied by 2^b. A point contributes
+        // at most once to each bucket, since the digits of its windows land in disjoint ranges
+        // of bits, so the batched additions never add a point to itself.
+        let mut buckets = vec![Vec::new(); number_of_windows * self.wbits];
+
+        for window_idx in 0..number_of_windows {
+            for (scalar_idx, scalar_bytes) in scalars_bytes.iter().enumerate() {
+                let digit = get_booth_index(window_idx, self.wbits, scalar_bytes.as_ref());
+                if digit == 0 {
+                    continue;
+                }
+
+                // digit = ±2^shift * odd
+                let magnitude = digit.unsigned_abs();
+                let shift = magnitude.trailing_zeros() as usize;
+                let odd = (magnitude >> shift) as usize;
+
+                let mut point = self.table[scalar_idx][(odd - 1) / 2];
+                if digit.is_negative() {
+                    point = -point;
+                }
+                buckets[window_idx * self.wbits + shift].push(point);
+            }
+        }
+
+        let accumulated_points = multi_batch_addition_binary_tree_stride(buckets);
+
+        // Horner's rule over the bits, from the most significant one
+        let mut result = G1Projective::identity();
+        for point in accumulated_points.into_iter().rev() {
+            result = result.double();
+            result += point;
+        }
+
+        result
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use proptest::prelude::*;
+    use rand::SeedableRng;
+
+    use super::*;
+    use crate::fixed_base_msm_window::FixedBaseMSMPrecompWindow;
+
+    #[test]
+    fn precomp_lookup_table_has_odd_multiples() {
+        let lookup_table =
+            FixedBaseMSMPrecompWindowCompressed::precompute_points(7, G1Affine::generator());
+
+        assert_eq!(lookup_table.len(), 1 << 5);
+        for (i, l) in lookup_table.iter().enumerate() {
+            let expected = G1Projective::generator() * Scalar::from((2 * i + 1) as u64);
+            assert_eq!(*l, expected.into());
+        }
+    }
+
+    #[test]
+    fn table_is_half_the_size_of_the_window_table() {
+        let generators: Vec<_> = (0..8)
+            .map(|_| G1Projective::random(&mut rand::thread_rng()).into())
+            .collect();
+
+        for wbits in 2..=8 {
+            assert_eq!(
+                2 * FixedBaseMSMPrecompWindowCompressed::new(&generators, wbits).size_in_bytes(),
+                FixedBaseMSMPrecompWindow::new(&generators, wbits).size_in_bytes()
+            );
+        }
+    }
+
+    #[test]
+    fn msm_edge_case_scalars() {
+        let generators: Vec<_> = (0..6)
+            .map(|_| G1Projective::random(&mut rand::thread_rng()).into())
+            .collect();
+        // Zero, one, the largest scalar, and powers of two whose digits are all even
+        let scalars = [
+            Scalar::ZERO,
+            Scalar::ONE,
+            -Scalar::ONE,
+            Scalar::from(1u64 << 7),
+            Scalar::from(u64::MAX),
+            Scalar::from(6u64),
+        ];
+        let expected = crate::lincomb::g1_lincomb(&generators, &scalars).unwrap();
+
+        for wbits in 1..=9 {
+            let msm = FixedBaseMSMPrecompWindowCompressed::new(&generators, wbits);
+            assert_eq!(msm.msm(&scalars), expected, "wbits = {wbits}");
+        }
+    }
+
+    #[test]
+    fn serialization_roundtrip() {
+        let generators: Vec<_> = (0..4)
+            .map(|_| G1Projective::random(&mut rand::thread_rng()).into())
+            .collect();
+        let scalars: Vec<_> = (0..4)
+            .map(|_| Scalar::random(&mut rand::thread_rng()))
+            .collect();
+        let msm = FixedBaseMSMPrecompWindowCompressed::new(&generators, 5);
+
+        let mut bytes = Vec::new();
+        msm.write_to(&mut bytes).unwrap();
+        let restored =
+            FixedBaseMSMPrecompWindowCompressed::read_from(&mut bytes.as_slice()).unwrap();
+        assert_eq!(msm.msm(&scalars), restored.msm(&scalars));
+
+        // A table of the uncompressed layout has twice as many multiples per point
+        let mut bytes = Vec::new();
+        FixedBaseMSMPrecompWindow::new(&generators, 5)
+            .write_to(&mut bytes)
+            .unwrap();
+        assert!(FixedBaseMSMPrecompWindowCompressed::read_from(&mut bytes.as_slice()).is_err());
+    }
+
+    proptest! {
+        #[test]
+        fn prop_msm_compressed_precomp_matches_naive(
+            len in 1usize..32,
+            seed in any::<u64>(),
+            wbits in 2usize..8,
+        ) {
+            let mut rng = rand::rngs::StdRng::seed_from_u64(seed);
+
+            let generators: Vec<G1Affine> = (0..len)
+                .map(|_| G1Projective::random(&mut rng).into())
+                .collect();
+            let scalars: Vec<Scalar> = (0..len).map(|_| Scalar::random(&mut rng)).collect();
+
+            let expected: G1Projective = generators.iter()
+                .zip(&scalars)
+                .map(|(g, s)| G1Projective::from(*g) * s)
+                .sum();
+
+            let msm = FixedBaseMSMPrecompWindowCompressed::new(&generators, wbits);
+            prop_assert_eq!(msm.msm(&scalars), expected);
+        }
+    }
+}
@@ -1,8 +1,14 @@
+use std::io::{self, Read, Write};
+
 use blstrs::G1Affine;
 
 use crate::{
-    batch_addition::multi_batch_addition_binary_tree_stride, booth_encoding::get_booth_index,
-    g1_batch_normalize, traits::*, G1Projective, Scalar,
+    batch_addition::multi_batch_addition_binary_tree_stride,
+    booth_encoding::get_booth_index,
+    g1_batch_normalize,
+    table_io::{invalid_data, read_len, read_points, write_points, write_u64},
+    traits::*,
+    G1Projective, Scalar,
 };
 
 /// A precomputed window-based structure for fast fixed-base multi-scalar multiplication (MSM) in G1.
@@ -81,6 +87,51 @@ impl FixedBaseMSMPrecompWindow {
         g1_batch_normalize(&lookup_table)
     }
 
+    /// Writes the precomputed table so that it can be reloaded with [`Self::read_from`].
+    pub fn write_to<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        write_u64(writer, self.wbits as u64)?;
+        write_u64(writer, self.table.len() as u64)?;
+        for row in &self.table {
+            write_points(writer, row)?;
+        }
+        Ok(())
+    }
+
+    /// Reads a precomputed table written by [`Self::write_to`].
+    ///
+    /// The points are not checked to be the correct multiples of each other, so the
+    /// table must come from a trusted source.
+    pub fn read_from<R: Read>(reader: &mut R) -> io::Result<Self> {
+        let wbits = read_len(reader)?;
+        if wbits == 0 || wbits >= usize::BITS as usize {
+            return Err(invalid_data("invalid window size in precomputed table"));
+        }
+
+        let num_points = read_len(reader)?;
+        let table = (0..num_points)
+            .map(|_| {
+                let row = read_points(reader)?;
+                if row.len() == 1 << (wbits - 1) {
+                    Ok(row)
+                } else {
+                    Err(invalid_data(
+                        "unexpected number of multiples in precomputed table",
+                    ))
+                }
+            })
+            .collect::<io::Result<_>>()?;
+
+        Ok(Self { table, wbits })
+    }
+
+    /// Returns the number of bytes used by the precomputed tables.
+    pub fn size_in_bytes(&self) -> usize {
+        self.table
+            .iter()
+            .map(|row| row.len() * std::mem::size_of::<G1Affine>())
+            .sum()
+    }
+
     /// Computes a fixed-base multi-scalar multiplication (MSM) using precomputed window tables.
     ///
     /// This method uses Booth window encoding to slice each scalar into signed digit windows.
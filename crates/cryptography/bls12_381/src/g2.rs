@@ -0,0 +1,78 @@
+//! Operations on G2 points beyond the group operations of [`Group`](crate::traits::Group)
+//! and [`Curve`](crate::traits::Curve).
+//!
+//! These are what BLS signatures and checks of a trusted setup need on top of this crate:
+//! building points from their coordinates in [`Fp2`], and hashing messages to G2.
+
+use crate::{tower::Fp2, traits::*, G2Point, G2Projective};
+
+/// The hash to curve suite of the BLS signatures of the consensus specs, with a
+/// proof of possession.
+pub const ETH_BLS_SIGNATURE_DST: &[u8] = b"BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_";
+
+/// Returns the point with affine coordinates `x` and `y`, or `None` if it is not on the
+/// curve or not in the prime-order subgroup.
+///
+/// The identity has no affine coordinates, so it is never returned.
+pub fn g2_from_coordinates(x: Fp2, y: Fp2) -> Option<G2Point> {
+    let point = G2Point::from_raw_unchecked(x, y, false);
+    let valid = point.is_on_curve() & point.is_torsion_free();
+    bool::from(valid).then_some(point)
+}
+
+/// Returns the affine coordinates of `point`, or `None` for the identity.
+pub fn g2_coordinates(point: &G2Point) -> Option<(Fp2, Fp2)> {
+    if bool::from(point.is_identity()) {
+        return None;
+    }
+    Some((point.x(), point.y()))
+}
+
+/// Hashes `message` to a G2 point with the `hash_to_curve` of RFC 9380, for the suite
+/// `BLS12381G2_XMD:SHA-256_SSWU_RO_` and the domain separation tag `dst`.
+///
+/// For the BLS signatures of the consensus specs, `dst` is [`ETH_BLS_SIGNATURE_DST`].
+pub fn hash_to_g2(message: &[u8], dst: &[u8]) -> G2Projective {
+    G2Projective::hash_to_curve(message, dst, &[])
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{multi_pairings, G1Point, G2Prepared, Scalar};
+
+    #[test]
+    fn coordinates_roundtrip() {
+        let point = G2Point::from(G2Projective::generator() * Scalar::from(3u64));
+        let (x, y) = g2_coordinates(&point).unwrap();
+        assert_eq!(g2_from_coordinates(x, y), Some(point));
+
+        assert_eq!(g2_coordinates(&G2Point::identity()), None);
+    }
+
+    #[test]
+    fn invalid_coordinates_are_rejected() {
+        let (x, y) = g2_coordinates(&G2Point::generator()).unwrap();
+
+        // Not on the curve
+        assert_eq!(g2_from_coordinates(x, y + Fp2::ONE), None);
+        assert_eq!(g2_from_coordinates(Fp2::ZERO, Fp2::ZERO), None);
+    }
+
+    #[test]
+    fn hash_to_g2_signature_verifies() {
+        let secret = Scalar::from(42u64);
+        let public_key = G1Point::from(G1Point::generator() * secret);
+        let message = hash_to_g2(b"message", ETH_BLS_SIGNATURE_DST);
+        let signature = G2Point::from(message * secret);
+
+        assert!(bool::from(message.to_affine().is_torsion_free()));
+        assert_ne!(message, hash_to_g2(b"message", b"ANOTHER_DST"));
+
+        // e(pk, H(m)) = e(g1, sig)
+        assert!(multi_pairings(&[
+            (&public_key, &G2Prepared::from(message.to_affine())),
+            (&-G1Point::generator(), &G2Prepared::from(signature)),
+        ]));
+    }
+}
@@ -1,12 +1,24 @@
-use pairing::{MillerLoopResult, MultiMillerLoop};
 use traits::*;
 
+#[cfg(feature = "arkworks")]
+pub mod arkworks;
 pub mod batch_addition;
 pub mod batch_inversion;
+pub mod blst_dispatch;
 mod booth_encoding;
 pub mod fixed_base_msm;
+pub mod fixed_base_msm_compressed;
 pub mod fixed_base_msm_window;
+pub mod g2;
 pub mod lincomb;
+pub mod pairing_check;
+pub mod scalar;
+mod table_io;
+#[cfg(feature = "timing-analysis")]
+pub mod timing;
+pub mod tower;
+#[cfg(feature = "zkcrypto")]
+pub mod zkcrypto;
 
 // Re-exporting the blstrs crate
 
@@ -41,37 +53,57 @@ pub type G2Prepared = blstrs::G2Prepared;
 /// Used as exponents in scalar multiplication and other finite field operations.
 pub type Scalar = blstrs::Scalar;
 
+/// Returns the limbs of a scalar in Montgomery form, which is how it is stored in memory.
+///
+/// Together with [`scalar_from_montgomery_limbs`], this allows tables of scalars to be generated
+/// ahead of time, for example by a build script, and loaded without any field arithmetic.
+pub fn scalar_to_montgomery_limbs(scalar: &Scalar) -> [u64; 4] {
+    blst::blst_fr::from(*scalar).l
+}
+
+/// Creates a scalar from its limbs in Montgomery form.
+///
+/// The limbs are not checked to be a valid field element, so they must have been
+/// returned by [`scalar_to_montgomery_limbs`].
+pub fn scalar_from_montgomery_limbs(limbs: [u64; 4]) -> Scalar {
+    Scalar::from(blst::blst_fr { l: limbs })
+}
+
 /// Checks whether the product of pairings over the given G1 × G2 pairs equals the identity.
+///
+/// This is a [`PairingCheck`](pairing_check::PairingCheck) with a single equation.
+#[cfg_attr(feature = "tracing", tracing::instrument(skip_all, fields(num_pairs = pairs.len())))]
 pub fn multi_pairings(pairs: &[(&G1Point, &G2Prepared)]) -> bool {
-    blstrs::Bls12::multi_miller_loop(pairs)
-        .final_exponentiation()
-        .is_identity()
-        .into()
+    let mut check = pairing_check::PairingCheck::new();
+    check.add(pairs);
+    check.verify()
 }
 
-/// Converts Projective points to normalized points efficiently.
+/// Converts G1 projective points to their affine representation using a single
+/// batched field inversion.
 ///
+/// Identity points are allowed anywhere in the input and are mapped to the affine identity.
 // Note: This efficient variation is needed here and not for G2 because it is called
-// multiple times for MSM pre-computations.
-pub fn g1_batch_normalize(projective_points: &[G1Projective]) -> Vec<G1Point> {
+// multiple times for MSM pre-computations and after every FFT over group elements.
+pub fn batch_normalize(projective_points: &[G1Projective]) -> Vec<G1Point> {
     if projective_points.is_empty() {
         return Vec::new();
     }
 
-    // Track which points are identity and create a filtered vec without them
+    // Filter out the identity points, remembering where they were.
     //
     // This is because blst will convert all points into the identity point
     // if even one of them is the identity point.
-    let mut identity_positions = Vec::new();
-    let mut non_identity_points = Vec::new();
-
-    for (idx, point) in projective_points.iter().enumerate() {
-        if point.is_identity().into() {
-            identity_positions.push(idx);
-        } else {
-            non_identity_points.push(*point);
-        }
-    }
+    let is_identity: Vec<bool> = projective_points
+        .iter()
+        .map(|point| point.is_identity().into())
+        .collect();
+    let non_identity_points: Vec<_> = projective_points
+        .iter()
+        .zip(&is_identity)
+        .filter(|(_, is_identity)| !**is_identity)
+        .map(|(point, _)| *point)
+        .collect();
 
     // If all points are identity, return a vector of identity points
     if non_identity_points.is_empty() {
@@ -87,20 +119,87 @@ pub fn g1_batch_normalize(projective_points: &[G1Projective]) -> Vec<G1Point> {
     };
 
     let normalized = blst::p1_affines::from(points);
-
-    // Convert normalized points back to G1Points
-    let mut result: Vec<_> = normalized
+    let mut normalized = normalized
         .as_slice()
         .iter()
-        .map(|p| G1Point::from_raw_unchecked(p.x.into(), p.y.into(), false))
-        .collect();
+        .map(|p| G1Point::from_raw_unchecked(p.x.into(), p.y.into(), false));
 
     // Reinsert identity points at their original positions
-    for pos in identity_positions {
-        result.insert(pos, G1Point::identity());
+    is_identity
+        .into_iter()
+        .map(|is_identity| {
+            if is_identity {
+                G1Point::identity()
+            } else {
+                normalized
+                    .next()
+                    .expect("one normalized point per non-identity input")
+            }
+        })
+        .collect()
+}
+
+/// Converts G1 projective points to their affine representation like [`batch_normalize`],
+/// writing them to `out` instead of allocating.
+///
+/// Each run of points between two identity points is normalized with a single batched
+/// field inversion, so this is as fast as [`batch_normalize`] unless the input has many
+/// identity points.
+///
+/// # Panics
+/// Panics if `out` does not have as many points as `projective_points`.
+pub fn batch_normalize_into(projective_points: &[G1Projective], out: &mut [G1Point]) {
+    // The points are cast to the blst types below, which is only sound if they wrap them.
+    const _: () = assert!(
+        std::mem::size_of::<G1Point>() == std::mem::size_of::<blst::blst_p1_affine>()
+            && std::mem::size_of::<G1Projective>() == std::mem::size_of::<blst::blst_p1>()
+    );
+
+    assert_eq!(
+        projective_points.len(),
+        out.len(),
+        "output must have one point per input point"
+    );
+
+    let mut start = 0;
+    while start < projective_points.len() {
+        // blst converts all points into the identity point if even one of them is the
+        // identity point, so those are written directly and split the input into runs.
+        if bool::from(projective_points[start].is_identity()) {
+            out[start] = G1Point::identity();
+            start += 1;
+            continue;
+        }
+        let end = projective_points[start..]
+            .iter()
+            .position(|point| bool::from(point.is_identity()))
+            .map_or(projective_points.len(), |len| start + len);
+
+        // A null second pointer tells blst that the first one points to all of the points.
+        let points = [
+            projective_points[start..end]
+                .as_ptr()
+                .cast::<blst::blst_p1>(),
+            std::ptr::null(),
+        ];
+        // Safety: `G1Projective` and `G1Point` are transparent wrappers around `blst_p1` and
+        // `blst_p1_affine`, and `out[start..end]` has room for the `end - start` points.
+        unsafe {
+            blst::blst_p1s_to_affine(
+                out[start..end].as_mut_ptr().cast::<blst::blst_p1_affine>(),
+                points.as_ptr(),
+                end - start,
+            );
+        }
+        start = end;
     }
+}
 
-    result
+/// Converts Projective points to normalized points efficiently.
+///
+/// This is equivalent to [`batch_normalize`].
+pub fn g1_batch_normalize(projective_points: &[G1Projective]) -> Vec<G1Point> {
+    batch_normalize(projective_points)
 }
 
 /// Efficiently batch-normalizes a slice of G2 projective points to their affine representation.
@@ -146,6 +245,13 @@ mod tests {
     use super::*;
     use crate::ff::Field;
 
+    #[test]
+    fn montgomery_limbs_roundtrip() {
+        let scalar = Scalar::random(OsRng);
+        let limbs = scalar_to_montgomery_limbs(&scalar);
+        assert_eq!(scalar_from_montgomery_limbs(limbs), scalar);
+    }
+
     /// BLS12-381 scalar field modulus (r)
     const BLS12_381_R: [u8; 32] = [
         0x73, 0xED, 0xA7, 0x53, 0x29, 0x9D, 0x7D, 0x48, 0x33, 0x39, 0xD8, 0x08, 0x09, 0xA1, 0xD8,
@@ -246,6 +352,42 @@ mod tests {
         }
     }
 
+    #[test]
+    fn test_batch_normalize_matches_individual_conversion() {
+        use rand::thread_rng;
+        let mut rng = thread_rng();
+        let mut points: Vec<G1Projective> =
+            (0..32).map(|_| G1Projective::random(&mut rng)).collect();
+        points[0] = G1Projective::identity();
+        points[7] = G1Projective::identity();
+        points[31] = G1Projective::identity();
+
+        let normalized = batch_normalize(&points);
+
+        assert_eq!(normalized.len(), points.len());
+        for (norm, proj) in normalized.iter().zip(points.iter()) {
+            assert_eq!(*norm, G1Point::from(*proj));
+        }
+    }
+
+    #[test]
+    fn test_batch_normalize_into_matches_batch_normalize() {
+        use rand::thread_rng;
+        let mut rng = thread_rng();
+        let mut points: Vec<G1Projective> =
+            (0..32).map(|_| G1Projective::random(&mut rng)).collect();
+        points[0] = G1Projective::identity();
+        points[7] = G1Projective::identity();
+        points[8] = G1Projective::identity();
+        points[31] = G1Projective::identity();
+
+        for points in [&points[..], &points[1..7], &points[..0]] {
+            let mut out = vec![G1Point::generator(); points.len()];
+            batch_normalize_into(points, &mut out);
+            assert_eq!(out, batch_normalize(points));
+        }
+    }
+
     #[test]
     fn test_pairing_with_negation_false() {
         let g1 = G1Point::generator();
@@ -4,6 +4,7 @@ use crate::{traits::*, G1Point, G1Projective, G2Point, G2Projective, Scalar};
 ///
 /// Returns None if the points and the scalars are not the
 /// same length.
+#[cfg_attr(feature = "tracing", tracing::instrument(skip_all, fields(num_points = points.len())))]
 pub fn g1_lincomb(points: &[G1Point], scalars: &[Scalar]) -> Option<G1Projective> {
     if points.len() != scalars.len() {
         return None;
@@ -33,6 +34,7 @@ pub fn g1_lincomb(points: &[G1Point], scalars: &[Scalar]) -> Option<G1Projective
 ///
 /// Returns None if the points and the scalars are not the
 /// same length.
+#[cfg_attr(feature = "tracing", tracing::instrument(skip_all, fields(num_points = points.len())))]
 pub fn g2_lincomb(points: &[G2Point], scalars: &[Scalar]) -> Option<G2Projective> {
     if points.len() != scalars.len() {
         return None;
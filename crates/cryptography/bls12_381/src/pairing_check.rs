@@ -0,0 +1,200 @@
+//! Checks that products of pairings are the identity, with one final exponentiation for all
+//! of them.
+//!
+//! Every KZG verification ends with an equation of the form `∏ e(Pᵢ, Qᵢ) = 1`. A pairing is a
+//! Miller loop followed by a final exponentiation, and the final exponentiation costs about
+//! as much as a Miller loop, so the equation is checked with the Miller loops of all its pairs
+//! multiplied together, followed by a single final exponentiation. [`PairingCheck`] builds on
+//! that:
+//!
+//! - The pairs of an equation whose G2 points are the same [`G2Prepared`] are merged, since
+//!   `e(P, Q) · e(P', Q) = e(P + P', Q)`, so there is one Miller loop per distinct G2 point
+//!   rather than one per pair. KZG verification keys have a handful of G2 points, so any
+//!   number of equations against the same key costs that many Miller loops.
+//! - Several equations are combined into one by scaling the G1 points of each of them by a
+//!   random scalar `rⱼ`, and checking `∏ⱼ (∏ᵢ e(Pⱼᵢ, Qⱼᵢ))^rⱼ = 1`. If one of the equations
+//!   does not hold, the combination holds with probability about `1/r`, provided the `rⱼ`
+//!   could not be predicted by whoever chose the points.
+//!
+//! ```
+//! # use ekzg_bls12_381::{pairing_check::PairingCheck, traits::*, G1Point, G2Point, G2Prepared, Scalar};
+//! let g2 = G2Prepared::from(G2Point::generator());
+//! let p = G1Point::generator();
+//! let minus_p = -p;
+//!
+//! let mut check = PairingCheck::new();
+//! // e(P, G₂) · e(-P, G₂) = 1
+//! check.add(&[(&p, &g2), (&minus_p, &g2)]);
+//! // Another equation against the same G2 point, scaled by a random scalar
+//! check.add_scaled(&[(&minus_p, &g2), (&p, &g2)], Scalar::from(7u64));
+//!
+//! assert_eq!(check.num_miller_loops(), 1);
+//! assert!(check.verify());
+//! ```
+
+use pairing::{MillerLoopResult, MultiMillerLoop};
+
+use crate::{batch_normalize, traits::*, G1Point, G1Projective, G2Prepared, Scalar};
+
+/// An accumulator of pairing equations, that are all checked at once by
+/// [`PairingCheck::verify`]. See the [module documentation](self).
+///
+/// The G2 points are borrowed, and pairs are merged when their G2 points are the same
+/// reference, so the prepared G2 points of a verification key should be reused rather than
+/// prepared again for each equation.
+#[derive(Clone, Default)]
+pub struct PairingCheck<'a> {
+    /// The sum of the G1 points of the pairs added so far, for each distinct G2 point.
+    terms: Vec<(G1Projective, &'a G2Prepared)>,
+}
+
+impl<'a> PairingCheck<'a> {
+    /// Creates a check with no equation, which holds.
+    pub const fn new() -> Self {
+        Self { terms: Vec::new() }
+    }
+
+    /// Adds the equation `∏ e(P, Q) = 1` over the given pairs `(P, Q)`.
+    ///
+    /// The equation is not scaled, so when several equations are added, all but one of them
+    /// must be added with [`PairingCheck::add_scaled`]; otherwise an invalid equation could
+    /// cancel out another one.
+    pub fn add(&mut self, pairs: &[(&G1Point, &'a G2Prepared)]) {
+        for &(g1, g2) in pairs {
+            self.add_projective(g1.to_curve(), g2);
+        }
+    }
+
+    /// Adds the equation `∏ e(P, Q) = 1` over the given pairs `(P, Q)`, scaled by `r`.
+    ///
+    /// `r` must not be predictable by whoever chose the points, for example it is a
+    /// Fiat-Shamir challenge over all the equations, or it is sampled at random. Then when
+    /// this equation or another one does not hold, [`PairingCheck::verify`] fails except
+    /// with negligible probability.
+    pub fn add_scaled(&mut self, pairs: &[(&G1Point, &'a G2Prepared)], r: Scalar) {
+        for &(g1, g2) in pairs {
+            self.add_projective(g1.to_curve() * r, g2);
+        }
+    }
+
+    /// Adds the pair `(g1, g2)` to the equation, without scaling it.
+    ///
+    /// The G1 points are converted to affine form together in [`PairingCheck::verify`], so
+    /// callers that computed `g1` with a linear combination do not need to normalize it.
+    pub fn add_projective(&mut self, g1: G1Projective, g2: &'a G2Prepared) {
+        match self
+            .terms
+            .iter_mut()
+            .find(|(_, other)| std::ptr::eq(*other, g2))
+        {
+            Some((sum, _)) => *sum += g1,
+            None => self.terms.push((g1, g2)),
+        }
+    }
+
+    /// Adds every equation of `other`, scaled by `r`, as [`PairingCheck::add_scaled`] would.
+    ///
+    /// This combines the checks of several verifiers, for example of a batch of blob proofs
+    /// and a batch of cell proofs, into one final exponentiation.
+    pub fn append_scaled(&mut self, other: &Self, r: Scalar) {
+        for &(g1, g2) in &other.terms {
+            self.add_projective(g1 * r, g2);
+        }
+    }
+
+    /// Returns the number of Miller loops that [`PairingCheck::verify`] computes, ie the number
+    /// of distinct G2 points.
+    pub fn num_miller_loops(&self) -> usize {
+        self.terms.len()
+    }
+
+    /// Returns true if every equation that was added holds, except with negligible probability
+    /// when they were scaled by unpredictable scalars.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all, fields(num_miller_loops = self.terms.len())))]
+    pub fn verify(&self) -> bool {
+        let g1s: Vec<_> = self.terms.iter().map(|(g1, _)| *g1).collect();
+        let g1s = batch_normalize(&g1s);
+        let pairs: Vec<_> = g1s
+            .iter()
+            .zip(&self.terms)
+            .map(|(g1, &(_, g2))| (g1, g2))
+            .collect();
+
+        blstrs::Bls12::multi_miller_loop(&pairs)
+            .final_exponentiation()
+            .is_identity()
+            .into()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use rand::thread_rng;
+
+    use super::PairingCheck;
+    use crate::{traits::*, G1Point, G1Projective, G2Prepared, G2Projective, Scalar};
+
+    /// Returns the pairs of the equation `e([a]₁, [b]₂) · e(-[ab]₁, G₂) = 1`, which holds
+    /// unless `valid` is false.
+    fn equation(a: Scalar, b: Scalar, valid: bool) -> ([G1Point; 2], G2Prepared) {
+        let ab = if valid { a * b } else { a * b + Scalar::ONE };
+        let g1 = [
+            (G1Projective::generator() * a).to_affine(),
+            (-(G1Projective::generator() * ab)).to_affine(),
+        ];
+        (
+            g1,
+            G2Prepared::from((G2Projective::generator() * b).to_affine()),
+        )
+    }
+
+    #[test]
+    fn equations_are_checked_together() {
+        let mut rng = thread_rng();
+        let gen_g2 = G2Prepared::from(G2Projective::generator().to_affine());
+        let equations: Vec<_> = (0..4)
+            .map(|i| equation(Scalar::random(&mut rng), Scalar::random(&mut rng), i != 2))
+            .collect();
+
+        let check = |equations: &[([G1Point; 2], G2Prepared)]| {
+            let mut check = PairingCheck::new();
+            for (g1, g2) in equations {
+                check.add_scaled(
+                    &[(&g1[0], g2), (&g1[1], &gen_g2)],
+                    Scalar::random(thread_rng()),
+                );
+            }
+            // One Miller loop per equation, and one for the generator they share
+            assert_eq!(check.num_miller_loops(), equations.len() + 1);
+            check.verify()
+        };
+
+        assert!(PairingCheck::new().verify());
+        assert!(check(&equations[..2]));
+        assert!(!check(&equations));
+        assert!(!check(&equations[2..3]));
+    }
+
+    #[test]
+    fn checks_are_appended() {
+        let mut rng = thread_rng();
+        let gen_g2 = G2Prepared::from(G2Projective::generator().to_affine());
+        let (valid, valid_g2) = equation(Scalar::random(&mut rng), Scalar::random(&mut rng), true);
+        let (invalid, invalid_g2) =
+            equation(Scalar::random(&mut rng), Scalar::random(&mut rng), false);
+
+        let mut first = PairingCheck::new();
+        first.add(&[(&valid[0], &valid_g2), (&valid[1], &gen_g2)]);
+        let mut second = PairingCheck::new();
+        second.add(&[(&invalid[0], &invalid_g2), (&invalid[1], &gen_g2)]);
+        assert!(first.verify());
+        assert!(!second.verify());
+
+        let mut combined = first.clone();
+        combined.append_scaled(&first, Scalar::random(&mut rng));
+        assert!(combined.verify());
+        combined.append_scaled(&second, Scalar::random(&mut rng));
+        assert_eq!(combined.num_miller_loops(), 3);
+        assert!(!combined.verify());
+    }
+}
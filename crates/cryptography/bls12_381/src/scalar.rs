@@ -0,0 +1,217 @@
+//! Serialization, hashing and deterministic sampling of scalars.
+//!
+//! Scalars are encoded as 32 big-endian bytes, as in the consensus specs.
+
+use sha2::{Digest, Sha256};
+
+use crate::{reduce_bytes_to_scalar_bias, traits::*, Scalar};
+
+/// The number of bytes in the encoding of a scalar.
+pub const BYTES_PER_SCALAR: usize = 32;
+
+/// The number of bytes that [`hash_to_scalar`] reduces to a scalar.
+///
+/// This is `L = ceil((ceil(log2(r)) + k) / 8)` of RFC 9380 for the security level `k = 128`,
+/// which makes the bias of the reduction negligible.
+const HASH_TO_SCALAR_BYTES: usize = 48;
+
+/// The domain separation tag that [`SeededScalars`] hashes its seed with.
+const SEEDED_SCALARS_DST: &[u8] = b"ETH_KZG_SEEDED_SCALARS_V1";
+
+/// Returns the scalar that `bytes` encode, or `None` if they are not less than the modulus.
+pub fn from_bytes_be(bytes: &[u8; BYTES_PER_SCALAR]) -> Option<Scalar> {
+    Scalar::from_bytes_be(bytes).into()
+}
+
+/// Returns `bytes` reduced modulo the scalar field modulus.
+///
+/// Since `2^256` is not much larger than the modulus, the result is biased towards small
+/// scalars. Use [`from_bytes_be_wide`] for a uniform scalar from uniform bytes.
+pub fn from_bytes_be_reduced(bytes: [u8; BYTES_PER_SCALAR]) -> Scalar {
+    reduce_bytes_to_scalar_bias(bytes)
+}
+
+/// Returns the 64 big-endian bytes `bytes` reduced modulo the scalar field modulus.
+///
+/// If the bytes are uniform, the bias of the result is negligible.
+pub fn from_bytes_be_wide(bytes: &[u8; 2 * BYTES_PER_SCALAR]) -> Scalar {
+    let (high, low) = bytes.split_at(BYTES_PER_SCALAR);
+    let high = reduce_bytes_to_scalar_bias(high.try_into().expect("the half has 32 bytes"));
+    let low = reduce_bytes_to_scalar_bias(low.try_into().expect("the half has 32 bytes"));
+
+    // 2^256 mod r, as (2^256 - 1) + 1
+    let two_to_256 = reduce_bytes_to_scalar_bias([0xff; BYTES_PER_SCALAR]) + Scalar::ONE;
+    high * two_to_256 + low
+}
+
+/// Returns the encoding of `scalar` as 32 big-endian bytes.
+pub fn to_bytes_be(scalar: &Scalar) -> [u8; BYTES_PER_SCALAR] {
+    scalar.to_bytes_be()
+}
+
+/// Hashes `message` to a scalar, as `hash_to_field` of RFC 9380 does with
+/// `expand_message_xmd` over SHA-256 and the domain separation tag `dst`.
+///
+/// Each protocol should use its own `dst`, so that its scalars are independent from the
+/// scalars of other protocols.
+///
+/// # Panics
+/// Panics if `dst` is longer than 255 bytes.
+pub fn hash_to_scalar(dst: &[u8], message: &[u8]) -> Scalar {
+    let uniform_bytes = expand_message_xmd::<HASH_TO_SCALAR_BYTES>(dst, message);
+
+    let mut wide = [0u8; 2 * BYTES_PER_SCALAR];
+    wide[2 * BYTES_PER_SCALAR - HASH_TO_SCALAR_BYTES..].copy_from_slice(&uniform_bytes);
+    from_bytes_be_wide(&wide)
+}
+
+/// `expand_message_xmd` of RFC 9380 over SHA-256, for outputs of at most 255 blocks.
+fn expand_message_xmd<const LEN: usize>(dst: &[u8], message: &[u8]) -> [u8; LEN] {
+    const BLOCK_BYTES: usize = 64;
+    const DIGEST_BYTES: usize = 32;
+
+    let dst_len = u8::try_from(dst.len()).expect("the domain separation tag has at most 255 bytes");
+    let ell = LEN.div_ceil(DIGEST_BYTES);
+    assert!(ell <= 255, "expand_message_xmd outputs at most 255 blocks");
+    let len_in_bytes = u16::try_from(LEN).expect("LEN is at most 255 blocks");
+
+    let b_0 = Sha256::new()
+        .chain_update([0u8; BLOCK_BYTES])
+        .chain_update(message)
+        .chain_update(len_in_bytes.to_be_bytes())
+        .chain_update([0u8])
+        .chain_update(dst)
+        .chain_update([dst_len])
+        .finalize();
+
+    let mut out = [0u8; LEN];
+    let mut b_i = Sha256::new()
+        .chain_update(b_0)
+        .chain_update([1u8])
+        .chain_update(dst)
+        .chain_update([dst_len])
+        .finalize();
+    for (i, chunk) in out.chunks_mut(DIGEST_BYTES).enumerate() {
+        if i > 0 {
+            let mut xored = b_0;
+            for (byte, previous) in xored.iter_mut().zip(b_i) {
+                *byte ^= previous;
+            }
+            b_i = Sha256::new()
+                .chain_update(xored)
+                .chain_update([(i + 1) as u8])
+                .chain_update(dst)
+                .chain_update([dst_len])
+                .finalize();
+        }
+        chunk.copy_from_slice(&b_i[..chunk.len()]);
+    }
+    out
+}
+
+/// An endless stream of scalars that is determined by a seed.
+///
+/// The `i`th scalar is [`hash_to_scalar`] of the seed and `i`, so the stream is the same on
+/// every platform and with every version of `rand`. This is meant for tests, benchmarks and
+/// reproducible test vectors, not for secrets.
+#[derive(Debug, Clone)]
+pub struct SeededScalars {
+    seed: [u8; 32],
+    counter: u64,
+}
+
+impl SeededScalars {
+    pub const fn new(seed: [u8; 32]) -> Self {
+        Self { seed, counter: 0 }
+    }
+
+    /// Returns a stream whose seed is `seed` as 32 big-endian bytes.
+    pub fn from_u64(seed: u64) -> Self {
+        let mut bytes = [0u8; 32];
+        bytes[24..].copy_from_slice(&seed.to_be_bytes());
+        Self::new(bytes)
+    }
+}
+
+impl Iterator for SeededScalars {
+    type Item = Scalar;
+
+    fn next(&mut self) -> Option<Scalar> {
+        let mut message = [0u8; 40];
+        message[..32].copy_from_slice(&self.seed);
+        message[32..].copy_from_slice(&self.counter.to_be_bytes());
+        self.counter += 1;
+        Some(hash_to_scalar(SEEDED_SCALARS_DST, &message))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn canonical_encodings_roundtrip() {
+        for scalar in SeededScalars::from_u64(1).take(8) {
+            assert_eq!(from_bytes_be(&to_bytes_be(&scalar)), Some(scalar));
+        }
+
+        let mut modulus = (-Scalar::ONE).to_bytes_be();
+        modulus[BYTES_PER_SCALAR - 1] += 1;
+        assert_eq!(from_bytes_be(&modulus), None);
+        assert_eq!(from_bytes_be_reduced(modulus), Scalar::ZERO);
+    }
+
+    #[test]
+    fn wide_reduction_matches_field_arithmetic() {
+        let mut bytes = [0u8; 2 * BYTES_PER_SCALAR];
+        bytes[BYTES_PER_SCALAR - 1] = 1;
+        // 2^256 = (2^256 - 1) + 1
+        assert_eq!(
+            from_bytes_be_wide(&bytes),
+            from_bytes_be_reduced([0xff; BYTES_PER_SCALAR]) + Scalar::ONE
+        );
+
+        let [a, b] = [0, 1].map(|i| SeededScalars::from_u64(2).nth(i).unwrap());
+        let mut bytes = [0u8; 2 * BYTES_PER_SCALAR];
+        bytes[..BYTES_PER_SCALAR].copy_from_slice(&a.to_bytes_be());
+        bytes[BYTES_PER_SCALAR..].copy_from_slice(&b.to_bytes_be());
+        assert_eq!(
+            from_bytes_be_wide(&bytes),
+            a * (from_bytes_be_reduced([0xff; BYTES_PER_SCALAR]) + Scalar::ONE) + b
+        );
+    }
+
+    #[test]
+    fn expand_message_xmd_matches_rfc_9380() {
+        // The first SHA-256 test vector of appendix K.1 of RFC 9380
+        let dst = b"QUUX-V01-CS02-with-expander-SHA256-128";
+        let expected = [
+            0x68, 0xa9, 0x85, 0xb8, 0x7e, 0xb6, 0xb4, 0x69, 0x52, 0x12, 0x89, 0x11, 0xf2, 0xa4,
+            0x41, 0x2b, 0xbc, 0x30, 0x2a, 0x9d, 0x75, 0x96, 0x67, 0xf8, 0x7f, 0x7a, 0x21, 0xd8,
+            0x03, 0xf0, 0x72, 0x35,
+        ];
+        assert_eq!(expand_message_xmd::<32>(dst, b""), expected);
+
+        // The length of the output is hashed, so a longer output does not start with a shorter one
+        let long = expand_message_xmd::<HASH_TO_SCALAR_BYTES>(dst, b"abc");
+        assert_ne!(long[..32], expand_message_xmd::<32>(dst, b"abc"));
+    }
+
+    #[test]
+    fn seeded_scalars_are_deterministic() {
+        let first: Vec<_> = SeededScalars::from_u64(7).take(4).collect();
+        assert_eq!(
+            first,
+            SeededScalars::from_u64(7).take(4).collect::<Vec<_>>()
+        );
+        assert_ne!(
+            first,
+            SeededScalars::from_u64(8).take(4).collect::<Vec<_>>()
+        );
+        assert_ne!(first[0], first[1]);
+        assert_ne!(
+            hash_to_scalar(b"A", b"message"),
+            hash_to_scalar(b"B", b"message")
+        );
+    }
+}
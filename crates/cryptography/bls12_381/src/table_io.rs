@@ -0,0 +1,55 @@
+//! Helpers for reading and writing precomputed tables of points.
+//!
+//! Points are written in uncompressed form so that loading a table does not need a
+//! square root per point, which would defeat the purpose of caching the tables.
+use std::io::{self, Read, Write};
+
+use blstrs::G1Affine;
+
+/// Number of bytes in an uncompressed G1 point.
+const UNCOMPRESSED_G1_SIZE: usize = 96;
+
+pub(crate) fn write_u64<W: Write>(writer: &mut W, value: u64) -> io::Result<()> {
+    writer.write_all(&value.to_le_bytes())
+}
+
+pub(crate) fn read_u64<R: Read>(reader: &mut R) -> io::Result<u64> {
+    let mut bytes = [0u8; 8];
+    reader.read_exact(&mut bytes)?;
+    Ok(u64::from_le_bytes(bytes))
+}
+
+/// Reads a length prefix, converting it to a `usize`.
+pub(crate) fn read_len<R: Read>(reader: &mut R) -> io::Result<usize> {
+    usize::try_from(read_u64(reader)?).map_err(|_| invalid_data("length does not fit in usize"))
+}
+
+/// Writes a length-prefixed list of points.
+pub(crate) fn write_points<W: Write>(writer: &mut W, points: &[G1Affine]) -> io::Result<()> {
+    write_u64(writer, points.len() as u64)?;
+    for point in points {
+        writer.write_all(&point.to_uncompressed())?;
+    }
+    Ok(())
+}
+
+/// Reads a length-prefixed list of points written by [`write_points`].
+///
+/// Points are checked to be on the curve, but are not checked to be in the prime order
+/// subgroup. Tables should therefore only be loaded from a trusted source.
+pub(crate) fn read_points<R: Read>(reader: &mut R) -> io::Result<Vec<G1Affine>> {
+    let len = read_len(reader)?;
+    let mut points = Vec::new();
+    let mut bytes = [0u8; UNCOMPRESSED_G1_SIZE];
+    for _ in 0..len {
+        reader.read_exact(&mut bytes)?;
+        let point = Option::from(G1Affine::from_uncompressed_unchecked(&bytes))
+            .ok_or_else(|| invalid_data("invalid point in precomputed table"))?;
+        points.push(point);
+    }
+    Ok(points)
+}
+
+pub(crate) fn invalid_data(msg: &'static str) -> io::Error {
+    io::Error::new(io::ErrorKind::InvalidData, msg)
+}
@@ -0,0 +1,268 @@
+//! Statistical timing analysis of deserialization, in the style of [dudect].
+//!
+//! Each measurement runs an operation on an input from one of two classes: a fixed input,
+//! or an input drawn at random. The classes are interleaved at random so that drift in the
+//! machine's state affects both equally, and Welch's t-test is then used to decide whether
+//! the two timing distributions differ. A constant-time operation gives a t statistic close
+//! to zero however many samples are taken, while a leak makes it grow with the number of samples.
+//!
+//! Both classes only contain valid inputs. Rejecting an invalid input faster than a valid one
+//! is not a leak, since whether an input is valid is public, so the harness checks that the
+//! time taken does not depend on the value of a valid scalar or point.
+//!
+//! The measurements are only meaningful in release builds on a quiet machine, and because they
+//! use [`Instant`] they are a coarser tool than cycle counters. They catch regressions such as
+//! a variable-time square root or an early exit in a canonicality check, not cache-timing leaks.
+//!
+//! [dudect]: https://eprint.iacr.org/2016/1123.pdf
+
+use std::{hint::black_box, time::Instant};
+
+use crate::{traits::*, G1Point, G1Projective, Scalar};
+
+/// A t statistic above this is taken as evidence that the timing depends on the input.
+///
+/// This is the threshold dudect uses to report that an operation is "definitely not constant time".
+pub const LEAKAGE_THRESHOLD: f64 = 10.0;
+
+/// The number of times an operation is repeated within a single measurement, so that
+/// each measurement is well above the resolution of the clock.
+const REPETITIONS: usize = 16;
+
+/// The percentiles at which measurements are cropped, in addition to the uncropped set.
+///
+/// Cropping removes the long tail caused by interrupts and context switches, which
+/// otherwise hides small differences between the classes.
+const CROP_PERCENTILES: [f64; 5] = [0.5, 0.75, 0.9, 0.95, 0.99];
+
+/// The class that the input of a measurement was drawn from.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Class {
+    Fixed,
+    Random,
+}
+
+/// The result of a timing analysis.
+#[derive(Debug, Clone, Copy)]
+pub struct TimingReport {
+    /// The number of measurements taken for each class.
+    pub samples: usize,
+    /// The largest absolute t statistic over the uncropped and cropped measurements.
+    pub max_t: f64,
+}
+
+impl TimingReport {
+    /// Returns true if the measurements show that the timing depends on the input.
+    pub fn is_leaky(&self) -> bool {
+        self.max_t > LEAKAGE_THRESHOLD
+    }
+}
+
+/// A small PRNG for drawing inputs and classes, so the harness does not depend on `rand`.
+#[derive(Debug, Clone)]
+pub struct InputRng(u64);
+
+impl InputRng {
+    pub const fn new(seed: u64) -> Self {
+        Self(seed)
+    }
+
+    /// Returns the next output of SplitMix64.
+    pub fn next_u64(&mut self) -> u64 {
+        self.0 = self.0.wrapping_add(0x9e37_79b9_7f4a_7c15);
+        let mut z = self.0;
+        z = (z ^ (z >> 30)).wrapping_mul(0xbf58_476d_1ce4_e5b9);
+        z = (z ^ (z >> 27)).wrapping_mul(0x94d0_49bb_1331_11eb);
+        z ^ (z >> 31)
+    }
+
+    pub fn fill_bytes(&mut self, out: &mut [u8]) {
+        for chunk in out.chunks_mut(8) {
+            chunk.copy_from_slice(&self.next_u64().to_le_bytes()[..chunk.len()]);
+        }
+    }
+}
+
+/// Times `operation` on `samples` inputs of each class and compares the two distributions.
+///
+/// The inputs are created with `input` before any measurement is taken, so the time taken
+/// to generate them is not included.
+pub fn measure<T>(
+    samples: usize,
+    seed: u64,
+    mut input: impl FnMut(Class, &mut InputRng) -> T,
+    mut operation: impl FnMut(&T),
+) -> TimingReport {
+    let mut rng = InputRng::new(seed);
+
+    // Draw the same number of measurements for each class, in a random order.
+    let mut classes: Vec<_> = (0..2 * samples)
+        .map(|i| {
+            if i < samples {
+                Class::Fixed
+            } else {
+                Class::Random
+            }
+        })
+        .collect();
+    for i in (1..classes.len()).rev() {
+        let j = (rng.next_u64() % (i as u64 + 1)) as usize;
+        classes.swap(i, j);
+    }
+    let inputs: Vec<_> = classes
+        .iter()
+        .map(|&class| input(class, &mut rng))
+        .collect();
+
+    let measurements: Vec<(Class, f64)> = classes
+        .iter()
+        .zip(&inputs)
+        .map(|(&class, input)| {
+            let start = Instant::now();
+            for _ in 0..REPETITIONS {
+                operation(black_box(input));
+            }
+            (class, start.elapsed().as_nanos() as f64)
+        })
+        .collect();
+
+    let mut sorted: Vec<f64> = measurements.iter().map(|&(_, time)| time).collect();
+    sorted.sort_by(f64::total_cmp);
+    let cutoffs = CROP_PERCENTILES
+        .iter()
+        .map(|&percentile| sorted[((sorted.len() - 1) as f64 * percentile) as usize])
+        .chain(std::iter::once(f64::INFINITY));
+
+    let max_t = cutoffs
+        .map(|cutoff| welch_t(&measurements, cutoff).abs())
+        .fold(0.0, f64::max);
+
+    TimingReport { samples, max_t }
+}
+
+/// Computes Welch's t statistic over the measurements that are below `cutoff`.
+fn welch_t(measurements: &[(Class, f64)], cutoff: f64) -> f64 {
+    let mut fixed = RunningStats::default();
+    let mut random = RunningStats::default();
+    for &(class, time) in measurements.iter().filter(|(_, time)| *time <= cutoff) {
+        match class {
+            Class::Fixed => fixed.push(time),
+            Class::Random => random.push(time),
+        }
+    }
+
+    let variance = fixed.variance() / fixed.n + random.variance() / random.n;
+    if variance > 0.0 {
+        (fixed.mean - random.mean) / variance.sqrt()
+    } else {
+        0.0
+    }
+}
+
+/// The mean and variance of a stream of values, using Welford's algorithm.
+#[derive(Debug, Default)]
+struct RunningStats {
+    n: f64,
+    mean: f64,
+    m2: f64,
+}
+
+impl RunningStats {
+    fn push(&mut self, x: f64) {
+        self.n += 1.0;
+        let delta = x - self.mean;
+        self.mean += delta / self.n;
+        self.m2 += delta * (x - self.mean);
+    }
+
+    fn variance(&self) -> f64 {
+        if self.n < 2.0 {
+            0.0
+        } else {
+            self.m2 / (self.n - 1.0)
+        }
+    }
+}
+
+/// Times the deserialization of canonical big-endian scalars, comparing one against random ones.
+pub fn scalar_deserialization(samples: usize, seed: u64) -> TimingReport {
+    let fixed = Scalar::from(1u64).to_bytes_be();
+    measure(
+        samples,
+        seed,
+        |class, rng| match class {
+            Class::Fixed => fixed,
+            Class::Random => {
+                let mut bytes = [0u8; 32];
+                rng.fill_bytes(&mut bytes);
+                // Clear the top bits, so the scalar is always smaller than the modulus.
+                bytes[0] &= 0x3f;
+                bytes
+            }
+        },
+        |bytes| {
+            black_box(bool::from(Scalar::from_bytes_be(bytes).is_some()));
+        },
+    )
+}
+
+/// Times the decompression of G1 points, including the subgroup check, comparing the
+/// generator against random points.
+pub fn g1_decompression(samples: usize, seed: u64) -> TimingReport {
+    let fixed = G1Point::generator().to_compressed();
+    measure(
+        samples,
+        seed,
+        |class, rng| match class {
+            Class::Fixed => fixed,
+            Class::Random => (G1Projective::generator() * Scalar::from(rng.next_u64()))
+                .to_affine()
+                .to_compressed(),
+        },
+        |bytes| {
+            black_box(bool::from(G1Point::from_compressed(bytes).is_some()));
+        },
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    /// The number of measurements per class; dudect typically needs tens of thousands
+    /// before a small leak becomes significant.
+    const SAMPLES: usize = 20_000;
+
+    #[test]
+    fn detects_a_variable_time_operation() {
+        // The number of iterations depends on the input, so this must be reported.
+        let report = measure(
+            2_000,
+            1,
+            |class, rng| match class {
+                Class::Fixed => 0u64,
+                Class::Random => 64 + rng.next_u64() % 64,
+            },
+            |&n| {
+                let mut acc = 0u64;
+                for i in 0..n {
+                    acc = black_box(acc.wrapping_mul(31).wrapping_add(i));
+                }
+                black_box(acc);
+            },
+        );
+        assert!(report.is_leaky(), "t = {}", report.max_t);
+    }
+
+    #[test]
+    fn scalar_deserialization_is_constant_time() {
+        let report = scalar_deserialization(SAMPLES, 2);
+        assert!(!report.is_leaky(), "t = {}", report.max_t);
+    }
+
+    #[test]
+    fn g1_decompression_is_constant_time() {
+        let report = g1_decompression(SAMPLES / 10, 3);
+        assert!(!report.is_leaky(), "t = {}", report.max_t);
+    }
+}
@@ -0,0 +1,139 @@
+//! The tower of extension fields over the base field, and the pairing target group.
+//!
+//! These are the fields that G2 coordinates and pairings are computed in:
+//!
+//! - [`Fp2`] is `Fp[u] / (u^2 + 1)`, the field of the coordinates of G2 points.
+//! - [`Fp6`] is `Fp2[v] / (v^3 - (u + 1))`.
+//! - [`Fp12`] is `Fp6[w] / (w^2 - v)`, the field that Miller loops are computed in.
+//!
+//! All of them implement [`Field`](crate::traits::Field), so they are used the same way as
+//! [`Scalar`](crate::Scalar). Code that only needs to check that a product of pairings is
+//! the identity should use [`multi_pairings`](crate::multi_pairings), which does a single
+//! final exponentiation for all of them.
+
+use crate::{G1Point, G2Point};
+
+/// An element of the base field, over which G1 is defined.
+pub type Fp = blstrs::Fp;
+
+/// An element of the quadratic extension `Fp[u] / (u^2 + 1)`, over which G2 is defined.
+///
+/// An element is `c0 + c1 * u`, see [`Fp2::new`], [`Fp2::c0`] and [`Fp2::c1`].
+pub type Fp2 = blstrs::Fp2;
+
+/// An element of the cubic extension `Fp2[v] / (v^3 - (u + 1))`.
+pub type Fp6 = blstrs::Fp6;
+
+/// An element of the quadratic extension `Fp6[w] / (w^2 - v)`.
+pub type Fp12 = blstrs::Fp12;
+
+/// An element of the order `r` subgroup of [`Fp12`] that pairings map to.
+///
+/// The group is written additively, as G1 and G2 are, so the product of two pairings is
+/// their sum.
+pub type Gt = blstrs::Gt;
+
+/// The number of bytes in the encoding of a base field element.
+pub const BYTES_PER_FP: usize = 48;
+
+/// The number of bytes in the encoding of an [`Fp2`] element.
+pub const BYTES_PER_FP2: usize = 2 * BYTES_PER_FP;
+
+/// Returns the base field element that `bytes` encode, or `None` if they are not less than
+/// the modulus.
+pub fn fp_from_bytes_be(bytes: &[u8; BYTES_PER_FP]) -> Option<Fp> {
+    Fp::from_bytes_be(bytes).into()
+}
+
+/// Returns the encoding of `fp` as 48 big-endian bytes.
+pub fn fp_to_bytes_be(fp: &Fp) -> [u8; BYTES_PER_FP] {
+    fp.to_bytes_be()
+}
+
+/// Returns the [`Fp2`] element that `bytes` encode, or `None` if either coefficient is not
+/// less than the modulus.
+///
+/// The encoding is `c1` followed by `c0`, as in the encodings of G2 points.
+pub fn fp2_from_bytes_be(bytes: &[u8; BYTES_PER_FP2]) -> Option<Fp2> {
+    let (c1, c0) = bytes.split_at(BYTES_PER_FP);
+    let c1 = fp_from_bytes_be(c1.try_into().expect("the coefficient has 48 bytes"))?;
+    let c0 = fp_from_bytes_be(c0.try_into().expect("the coefficient has 48 bytes"))?;
+    Some(Fp2::new(c0, c1))
+}
+
+/// Returns the encoding of `fp2` as `c1` followed by `c0`, as in the encodings of G2 points.
+pub fn fp2_to_bytes_be(fp2: &Fp2) -> [u8; BYTES_PER_FP2] {
+    let mut bytes = [0u8; BYTES_PER_FP2];
+    bytes[..BYTES_PER_FP].copy_from_slice(&fp_to_bytes_be(&fp2.c1()));
+    bytes[BYTES_PER_FP..].copy_from_slice(&fp_to_bytes_be(&fp2.c0()));
+    bytes
+}
+
+/// Computes the pairing of `g1` and `g2`.
+///
+/// Each call does a whole final exponentiation, so checking that a product of pairings is the
+/// identity is much faster with [`multi_pairings`](crate::multi_pairings).
+pub fn pairing(g1: &G1Point, g2: &G2Point) -> Gt {
+    blstrs::pairing(g1, g2)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{traits::*, G2Prepared, Scalar};
+
+    #[test]
+    fn tower_relations() {
+        let u = Fp2::new(Fp::ZERO, Fp::ONE);
+        assert_eq!(u.square(), -Fp2::ONE);
+
+        // v^3 = u + 1 and w^2 = v
+        let v = Fp6::new(Fp2::ZERO, Fp2::ONE, Fp2::ZERO);
+        assert_eq!(v.square() * v, Fp6::new(u + Fp2::ONE, Fp2::ZERO, Fp2::ZERO));
+        let w = Fp12::new(Fp6::ZERO, Fp6::ONE);
+        assert_eq!(w.square(), Fp12::new(v, Fp6::ZERO));
+    }
+
+    #[test]
+    fn fp2_encoding_matches_g2_encoding() {
+        let point = G2Point::generator();
+        let uncompressed = point.to_uncompressed();
+        assert_eq!(fp2_to_bytes_be(&point.x()), uncompressed[..BYTES_PER_FP2]);
+        assert_eq!(fp2_to_bytes_be(&point.y()), uncompressed[BYTES_PER_FP2..]);
+
+        let x: [u8; BYTES_PER_FP2] = uncompressed[..BYTES_PER_FP2].try_into().unwrap();
+        assert_eq!(fp2_from_bytes_be(&x), Some(point.x()));
+
+        let mut non_canonical = x;
+        non_canonical[..BYTES_PER_FP].fill(0xff);
+        assert_eq!(fp2_from_bytes_be(&non_canonical), None);
+    }
+
+    #[test]
+    fn pairing_is_bilinear() {
+        let a = Scalar::from(5u64);
+        let b = Scalar::from(7u64);
+        let e = pairing(&G1Point::generator(), &G2Point::generator());
+
+        let lhs = pairing(
+            &(G1Point::generator() * a).into(),
+            &(G2Point::generator() * b).into(),
+        );
+        assert_eq!(lhs, e * (a * b));
+        assert!(!bool::from(e.is_identity()));
+
+        // Agrees with the batched check
+        let minus_ab: G1Point = (G1Point::generator() * -(a * b)).into();
+        assert_eq!(
+            lhs + pairing(&minus_ab, &G2Point::generator()),
+            Gt::identity()
+        );
+        assert!(crate::multi_pairings(&[
+            (
+                &(G1Point::generator() * a).into(),
+                &G2Prepared::from(G2Point::from(G2Point::generator() * b))
+            ),
+            (&minus_ab, &G2Prepared::from(G2Point::generator())),
+        ]));
+    }
+}
@@ -0,0 +1,140 @@
+//! Conversions between the types of this crate and the types of the zkcrypto `bls12_381`
+//! crate.
+//!
+//! As with the `arkworks` module, both sides are types of other crates, so
+//! [`ZkcryptoConversion`] plays the role of `From` and `TryFrom`:
+//!
+//! ```ignore
+//! use rust_eth_kzg::zkcrypto::ZkcryptoConversion;
+//!
+//! let commitment: zkcrypto_bls12_381::G1Affine = commitment_point.to_zkcrypto();
+//! let point = G1Point::try_from_zkcrypto(&commitment)?;
+//! ```
+//!
+//! Both crates use the same uncompressed encoding of points, which the conversions go
+//! through. The zkcrypto crate only creates valid points, but it can deserialize points
+//! without checking them, so points coming from it are checked again.
+
+use zkcrypto_bls12_381 as zk;
+
+use crate::{G1Point, G2Point, Scalar};
+
+/// The point given to [`ZkcryptoConversion::try_from_zkcrypto`] is not on the curve or not
+/// in the prime-order subgroup.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct InvalidZkcryptoPoint;
+
+/// Converts a type of this crate to and from the matching type of the zkcrypto `bls12_381` crate.
+pub trait ZkcryptoConversion: Sized {
+    /// The matching type of the zkcrypto crate.
+    type Zkcrypto;
+
+    /// Returns the zkcrypto value that represents `self`.
+    fn to_zkcrypto(&self) -> Self::Zkcrypto;
+
+    /// Returns the value that represents `value`, if it is valid.
+    fn try_from_zkcrypto(value: &Self::Zkcrypto) -> Result<Self, InvalidZkcryptoPoint>;
+}
+
+impl ZkcryptoConversion for G1Point {
+    type Zkcrypto = zk::G1Affine;
+
+    fn to_zkcrypto(&self) -> Self::Zkcrypto {
+        // The point was checked when it was created, so it is not checked again.
+        Option::from(zk::G1Affine::from_uncompressed_unchecked(
+            &self.to_uncompressed(),
+        ))
+        .expect("zkcrypto uses the same uncompressed encoding")
+    }
+
+    fn try_from_zkcrypto(value: &Self::Zkcrypto) -> Result<Self, InvalidZkcryptoPoint> {
+        Option::from(Self::from_uncompressed(&value.to_uncompressed())).ok_or(InvalidZkcryptoPoint)
+    }
+}
+
+impl ZkcryptoConversion for G2Point {
+    type Zkcrypto = zk::G2Affine;
+
+    fn to_zkcrypto(&self) -> Self::Zkcrypto {
+        Option::from(zk::G2Affine::from_uncompressed_unchecked(
+            &self.to_uncompressed(),
+        ))
+        .expect("zkcrypto uses the same uncompressed encoding")
+    }
+
+    fn try_from_zkcrypto(value: &Self::Zkcrypto) -> Result<Self, InvalidZkcryptoPoint> {
+        Option::from(Self::from_uncompressed(&value.to_uncompressed())).ok_or(InvalidZkcryptoPoint)
+    }
+}
+
+impl ZkcryptoConversion for Scalar {
+    type Zkcrypto = zk::Scalar;
+
+    fn to_zkcrypto(&self) -> Self::Zkcrypto {
+        Option::from(zk::Scalar::from_bytes(&self.to_bytes_le()))
+            .expect("a scalar is always canonical")
+    }
+
+    /// Never fails, since every zkcrypto scalar is canonical.
+    fn try_from_zkcrypto(value: &Self::Zkcrypto) -> Result<Self, InvalidZkcryptoPoint> {
+        Ok(Option::from(Self::from_bytes_le(&value.to_bytes()))
+            .expect("a zkcrypto scalar is always canonical"))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use rand::rngs::OsRng;
+    use zkcrypto_bls12_381 as zk;
+
+    use super::{InvalidZkcryptoPoint, ZkcryptoConversion};
+    use crate::{traits::*, G1Point, G2Point, Scalar};
+
+    #[test]
+    fn generators_and_identities_match() {
+        assert_eq!(
+            G1Point::generator().to_zkcrypto(),
+            zk::G1Affine::generator()
+        );
+        assert_eq!(
+            G2Point::generator().to_zkcrypto(),
+            zk::G2Affine::generator()
+        );
+        assert_eq!(G1Point::identity().to_zkcrypto(), zk::G1Affine::identity());
+        assert_eq!(
+            G2Point::try_from_zkcrypto(&zk::G2Affine::identity()),
+            Ok(G2Point::identity())
+        );
+    }
+
+    #[test]
+    fn conversions_commute_with_scalar_multiplication() {
+        let scalar = Scalar::random(OsRng);
+        let zk_scalar = scalar.to_zkcrypto();
+        assert_eq!(Scalar::try_from_zkcrypto(&zk_scalar), Ok(scalar));
+
+        let point = (G1Point::generator() * scalar).to_affine();
+        let zk_point = zk::G1Affine::from(zk::G1Affine::generator() * zk_scalar);
+        assert_eq!(point.to_zkcrypto(), zk_point);
+        assert_eq!(G1Point::try_from_zkcrypto(&zk_point), Ok(point));
+
+        let point = (G2Point::generator() * scalar).to_affine();
+        let zk_point = zk::G2Affine::from(zk::G2Affine::generator() * zk_scalar);
+        assert_eq!(point.to_zkcrypto(), zk_point);
+        assert_eq!(G2Point::try_from_zkcrypto(&zk_point), Ok(point));
+    }
+
+    #[test]
+    fn points_off_the_curve_are_rejected() {
+        // The generator with the last byte of its y coordinate changed.
+        let mut bytes = zk::G1Affine::generator().to_uncompressed();
+        bytes[95] ^= 1;
+        let not_on_curve =
+            Option::<zk::G1Affine>::from(zk::G1Affine::from_uncompressed_unchecked(&bytes))
+                .unwrap();
+        assert_eq!(
+            G1Point::try_from_zkcrypto(&not_on_curve),
+            Err(InvalidZkcryptoPoint)
+        );
+    }
+}
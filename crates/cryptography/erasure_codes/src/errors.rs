@@ -1,5 +1,8 @@
 /// Errors that can occur during Reed-Solomon encoding or erasure recovery.
+///
+/// More errors may be added, so matches on it need a wildcard arm.
 #[derive(Debug)]
+#[non_exhaustive]
 pub enum RSError {
     /// Raised when the input polynomial exceeds the allowed maximum number of coefficients.
     ///
@@ -40,4 +43,10 @@ pub enum RSError {
         /// The size of each block, used as the upper bound.
         block_size: usize,
     },
+
+    /// Raised when a block index appears more than once.
+    RepeatedBlockIndex {
+        /// The block index that was repeated.
+        block_index: usize,
+    },
 }
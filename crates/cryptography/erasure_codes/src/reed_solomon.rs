@@ -3,7 +3,8 @@ use std::ops::Deref;
 use bls12_381::{batch_inversion::batch_inverse, traits::*, Scalar};
 use polynomial::{
     domain::Domain,
-    poly_coeff::{vanishing_poly, PolyCoeff},
+    poly_coeff::PolyCoeff,
+    vanishing::{vanishing_poly_for_block_indices, BlockIndexError},
     CosetFFT,
 };
 
@@ -93,9 +94,6 @@ pub struct ReedSolomon {
     ///
     /// Note: This also denotes the number of synchronized erasures or block propagated erasures that may occur.
     num_blocks: usize,
-    /// The domain that we will use to efficiently compute the vanishing polynomial with, when the erasure pattern
-    /// being used is `BlockSynchronizedErasures`.
-    block_size_domain: Domain,
     /// Coset generator used for coset FFTs when evaluating and interpolating during erasure recovery.
     fft_coset_gen: CosetFFT,
 }
@@ -125,7 +123,6 @@ impl ReedSolomon {
             evaluation_domain: Domain::new(evaluation_size),
             block_size,
             num_blocks: evaluation_size / block_size,
-            block_size_domain: Domain::new(block_size),
             fft_coset_gen: CosetFFT::new(Scalar::MULTIPLICATIVE_GENERATOR),
         }
     }
@@ -153,6 +150,11 @@ impl ReedSolomon {
         self.poly_len * self.expansion_factor
     }
 
+    /// Returns the number of bytes used by the roots of unity and FFT tables of the evaluation domain.
+    pub fn size_in_bytes(&self) -> usize {
+        self.evaluation_domain.size_in_bytes()
+    }
+
     /// Encodes a polynomial in coefficient form by evaluating it at `poly_len * expansion_factor`
     /// points.
     pub fn encode(&self, poly_coefficient_form: PolyCoeff) -> Result<Vec<Scalar>, RSError> {
@@ -203,12 +205,8 @@ impl ReedSolomon {
 
     /// Constructs a polynomial that vanishes on all of the block indices in each block.
     ///
-    /// This method makes the following assumptions:
-    ///  - All of the blocks are not missing.
-    ///  - The block indices are not repeated.
-    ///  - The block indices are valid (ie each index references a block).
-    ///
-    /// WARNING: It is the responsibility of the caller to ensure that these are valid.
+    /// This method assumes that all of the blocks are not missing, and returns an error if
+    /// a block index is repeated or does not reference a block.
     ///
     /// - We note that the algorithm below has an edge case when all of the blocks
     ///   are missing. In that particular case, the vanishing polynomial
@@ -220,45 +218,40 @@ impl ReedSolomon {
     fn construct_vanishing_poly_from_block_erasures(
         &self,
         block_indices: &BlockErasureIndices,
-    ) -> PolyCoeff {
+    ) -> Result<PolyCoeff, RSError> {
         assert!(block_indices.len() != self.block_size, "all of the blocks are missing. This should have been checked by the caller of this method");
 
         let evaluation_domain_size = self.evaluation_domain.roots.len();
 
-        // Compute the polynomial that vanishes on all roots of unity corresponding
-        // to the block_indices.
+        // Compute the polynomial that vanishes on the block indices in every block.
         //
-        // We are essentially calculating the polynomial that vanishes only on the indices
-        // in the first block.
-        let z_x_missing_indices_roots: Vec<_> = block_indices
-            .iter()
-            .map(|index| self.block_size_domain.roots[*index])
-            .collect();
-        let vanish_poly_first_block = vanishing_poly(&z_x_missing_indices_roots);
-
-        // Expand the vanishing polynomial, so that it vanishes on all blocks in the codeword
-        // at the same indices.
-        let mut z_x = vec![Scalar::ZERO; evaluation_domain_size];
-        for (i, coeff) in vanish_poly_first_block.0.into_iter().enumerate() {
-            // Let's compute the bounds for the array access below to argue that it is safe:
-            //
-            //  For all array accesses to be in bound, we have:
-            //  i * self.num_blocks < z_x.len()
-            //  => i * self.num_blocks < poly_len * expansion_factor
-            //  => i < poly_len * expansion_factor / self.num_blocks
-            //  => i < block_size
-            // We know that i \in [0, block_indices.len]
-            // A simple example; when we have one erasure, we get a linear polynomial and i \in [0,1]
-            // => block_indices.len < block_size
-            //
-            // If block_indices.len >= block_size, it means either two things:
-            // - All of the blocks are missing
-            // - There are duplicate block indices.
-            // This function makes the assumption that the caller has checked these conditions.
-            z_x[i * self.num_blocks] = coeff;
-        }
+        // This is the polynomial that vanishes only on the indices in the first block,
+        // with `X` substituted by `X^num_blocks`.
+        let mut z_x = vanishing_poly_for_block_indices(
+            evaluation_domain_size,
+            self.block_size,
+            block_indices,
+        )
+        .map_err(|err| match err {
+            BlockIndexError::OutOfRange {
+                block_index,
+                block_size,
+            } => RSError::InvalidBlockIndex {
+                block_index,
+                block_size,
+            },
+            BlockIndexError::Repeated { block_index } => {
+                RSError::RepeatedBlockIndex { block_index }
+            }
+        })?;
+
+        // Pad the polynomial to the size of the evaluation domain.
+        //
+        // `z_x` has `block_indices.len() * num_blocks + 1` coefficients, which is at most
+        // the size of the evaluation domain since not all of the blocks are missing.
+        z_x.resize(evaluation_domain_size, Scalar::ZERO);
 
-        z_x.into()
+        Ok(z_x)
     }
 
     /// Constructs a vanishing polynomial `Z(X)` that evaluates to zero on all known erasure positions.
@@ -294,7 +287,7 @@ impl ReedSolomon {
                         max_num_block_erasures_accepted: self.acceptable_num_block_erasures(),
                     });
                 }
-                Ok(self.construct_vanishing_poly_from_block_erasures(&indices))
+                self.construct_vanishing_poly_from_block_erasures(&indices)
             }
             #[cfg(test)]
             ErasurePattern::Random { indices } => {
@@ -311,7 +304,7 @@ impl ReedSolomon {
                     .into_iter()
                     .map(|index| self.evaluation_domain.roots[index])
                     .collect();
-                Ok(vanishing_poly(&roots))
+                Ok(polynomial::poly_coeff::vanishing_poly(&roots))
             }
         }
     }
@@ -389,7 +382,7 @@ mod tests {
     use bls12_381::{traits::*, Scalar};
     use polynomial::poly_coeff::PolyCoeff;
 
-    use crate::{reed_solomon::ErasurePattern, BlockErasureIndices, ReedSolomon};
+    use crate::{errors::RSError, reed_solomon::ErasurePattern, BlockErasureIndices, ReedSolomon};
 
     #[test]
     #[should_panic]
@@ -403,7 +396,7 @@ mod tests {
         let rs = ReedSolomon::new(POLY_LEN, EXPANSION_FACTOR, BLOCK_SIZE);
         let block_erasure_indices: Vec<_> = (0..BLOCK_SIZE).collect();
 
-        rs.construct_vanishing_poly_from_block_erasures(&BlockErasureIndices(
+        let _ = rs.construct_vanishing_poly_from_block_erasures(&BlockErasureIndices(
             block_erasure_indices,
         ));
     }
@@ -439,8 +432,9 @@ mod tests {
         let indices = vec![0, 1, 2, 3];
 
         let rs = ReedSolomon::new(POLY_LEN, EXPANSION_FACTOR, BLOCK_SIZE);
-        let z =
-            rs.construct_vanishing_poly_from_block_erasures(&BlockErasureIndices(indices.clone()));
+        let z = rs
+            .construct_vanishing_poly_from_block_erasures(&BlockErasureIndices(indices.clone()))
+            .unwrap();
 
         assert_eq!(z.len(), POLY_LEN * EXPANSION_FACTOR);
 
@@ -471,8 +465,9 @@ mod tests {
         let indices = vec![0, 1];
 
         let rs = ReedSolomon::new(POLY_LEN, EXPANSION_FACTOR, BLOCK_SIZE);
-        let got_z_x =
-            rs.construct_vanishing_poly_from_block_erasures(&BlockErasureIndices(indices.clone()));
+        let got_z_x = rs
+            .construct_vanishing_poly_from_block_erasures(&BlockErasureIndices(indices.clone()))
+            .unwrap();
         let got_z_x_lagrange_form = rs.evaluation_domain.fft_scalars(got_z_x);
 
         let blocks: Vec<_> = got_z_x_lagrange_form.chunks(BLOCK_SIZE).collect();
@@ -576,4 +571,29 @@ mod tests {
             }
         }
     }
+
+    #[test]
+    fn recovery_rejects_invalid_block_indices() {
+        const POLY_LEN: usize = 16;
+        const EXPANSION_FACTOR: usize = 2;
+        const BLOCK_SIZE: usize = 4;
+
+        let rs = ReedSolomon::new(POLY_LEN, EXPANSION_FACTOR, BLOCK_SIZE);
+        let codeword = vec![Scalar::ZERO; rs.codeword_length()];
+
+        let result =
+            rs.recover_polynomial_coefficient(codeword.clone(), BlockErasureIndices(vec![1, 1]));
+        assert!(matches!(
+            result,
+            Err(RSError::RepeatedBlockIndex { block_index: 1 })
+        ));
+        let result = rs.recover_polynomial_coefficient(codeword, BlockErasureIndices(vec![4]));
+        assert!(matches!(
+            result,
+            Err(RSError::InvalidBlockIndex {
+                block_index: 4,
+                block_size: BLOCK_SIZE
+            })
+        ));
+    }
 }
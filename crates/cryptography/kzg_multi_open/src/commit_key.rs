@@ -1,3 +1,5 @@
+use std::sync::Arc;
+
 use bls12_381::{lincomb::g1_lincomb, G1Point, G1Projective, Scalar};
 
 /// The key that is used to commit to polynomials in monomial form
@@ -13,7 +15,10 @@ pub struct CommitKey {
     ///
     /// The length of this vector determines the maximum degree polynomial
     /// that can be safely committed using this key.
-    pub g1s: Vec<G1Point>,
+    ///
+    /// The points are reference counted, so that keys made from the same trusted setup
+    /// can share them.
+    pub g1s: Arc<[G1Point]>,
 }
 
 impl CommitKey {
@@ -25,7 +30,8 @@ impl CommitKey {
     ///
     /// # Panics
     /// Panics if `g1s` is empty.
-    pub fn new(g1s: Vec<G1Point>) -> Self {
+    pub fn new(g1s: impl Into<Arc<[G1Point]>>) -> Self {
+        let g1s = g1s.into();
         assert!(
             !g1s.is_empty(),
             "cannot initialize `CommitKey` with no g1 points"
@@ -34,6 +40,11 @@ impl CommitKey {
         Self { g1s }
     }
 
+    /// Returns the number of bytes used by the G1 points.
+    pub fn size_in_bytes(&self) -> usize {
+        self.g1s.len() * std::mem::size_of::<G1Point>()
+    }
+
     /// Commit to `polynomial` in monomial form using the G1 group elements
     pub fn commit_g1(&self, poly_coeff: &[Scalar]) -> G1Projective {
         // Note: We could use g1_lincomb_unsafe here, because we know that none of the points are the
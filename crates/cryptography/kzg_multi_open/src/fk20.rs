@@ -1,3 +1,4 @@
+mod batch_schedule;
 mod batch_toeplitz;
 mod cosets;
 mod errors;
@@ -7,10 +8,14 @@ mod h_poly;
 mod naive;
 
 mod prover;
+mod scratch;
 mod toeplitz;
 mod verifier;
 
 pub use cosets::recover_evaluations_in_domain_order;
+#[cfg(any(test, feature = "reference-impl"))]
+pub(crate) use cosets::reverse_bit_order;
 pub use errors::VerifierError;
 pub use prover::{FK20Prover as Prover, Input as ProverInput};
+pub use scratch::ProverScratch;
 pub use verifier::{CommitmentIndex, CosetIndex, FK20Verifier as Verifier};
@@ -0,0 +1,156 @@
+use std::collections::HashMap;
+
+use bls12_381::{traits::*, G1Point, Scalar};
+
+use super::verifier::{CommitmentIndex, CosetIndex};
+
+/// The openings of a batch, grouped by commitment, proof and coset before they are combined
+/// for the pairing check.
+///
+/// The batch check weighs opening `i` with the power `r^i` of a challenge. Openings that
+/// share a commitment or a proof contribute to the same base of an MSM, and openings that share
+/// a coset contribute to the same coset IFFT, so their weights are added up first and each
+/// distinct base and coset is only used once. Gossip batches repeat all three a lot: a data
+/// column opens every blob of a block at the same coset, and sampling several columns of a
+/// block opens the same commitments over and over.
+///
+/// The challenge is computed from the openings in the order they were given, so grouping them
+/// does not change the result of the check.
+#[derive(Debug)]
+pub(crate) struct BatchSchedule {
+    /// For each deduplicated commitment, the sum of the weights of its openings.
+    pub(crate) commitment_weights: Vec<Scalar>,
+    /// The distinct proofs of the batch, in the order they first appear.
+    pub(crate) proofs: Vec<G1Point>,
+    /// For each distinct proof, the sum of the weights of its openings.
+    pub(crate) proof_weights: Vec<Scalar>,
+    /// For each distinct proof, the sum of the weights of its openings, each multiplied by
+    /// the generator of its coset to the power of the coset size.
+    pub(crate) weighted_proof_weights: Vec<Scalar>,
+    /// For each distinct coset, in increasing order of index, the sum of the evaluations of
+    /// its openings multiplied by their weights, in bit-reversed order.
+    pub(crate) coset_evals: Vec<(CosetIndex, Vec<Scalar>)>,
+}
+
+impl BatchSchedule {
+    /// Groups the openings of a batch.
+    ///
+    /// The slices describing the openings must have the same length, the commitment indices must
+    /// be less than `num_commitments` and the coset indices less than
+    /// `bit_reversed_coset_gens_pow_n.len()`.
+    pub(crate) fn new(
+        num_commitments: usize,
+        commitment_indices: &[CommitmentIndex],
+        bit_reversed_coset_indices: &[CosetIndex],
+        bit_reversed_coset_evals: &[Vec<Scalar>],
+        bit_reversed_proofs: &[G1Point],
+        r_powers: &[Scalar],
+        bit_reversed_coset_gens_pow_n: &[Scalar],
+    ) -> Self {
+        let mut commitment_weights = vec![Scalar::ZERO; num_commitments];
+        let mut proof_positions = HashMap::new();
+        let mut proofs = Vec::new();
+        let mut proof_weights = Vec::new();
+        let mut weighted_proof_weights = Vec::new();
+        let mut evals_per_coset: Vec<Option<Vec<Scalar>>> =
+            vec![None; bit_reversed_coset_gens_pow_n.len()];
+
+        let openings = commitment_indices
+            .iter()
+            .zip(bit_reversed_coset_indices)
+            .zip(bit_reversed_coset_evals)
+            .zip(bit_reversed_proofs)
+            .zip(r_powers);
+        for ((((&commitment_index, &coset_index), evals), proof), r_power) in openings {
+            commitment_weights[commitment_index as usize] += r_power;
+
+            let position = *proof_positions
+                .entry(proof.to_compressed())
+                .or_insert_with(|| {
+                    proofs.push(*proof);
+                    proof_weights.push(Scalar::ZERO);
+                    weighted_proof_weights.push(Scalar::ZERO);
+                    proofs.len() - 1
+                });
+            proof_weights[position] += r_power;
+            weighted_proof_weights[position] +=
+                r_power * bit_reversed_coset_gens_pow_n[coset_index as usize];
+
+            let coset_sum = evals_per_coset[coset_index as usize]
+                .get_or_insert_with(|| vec![Scalar::ZERO; evals.len()]);
+            for (sum, eval) in coset_sum.iter_mut().zip(evals) {
+                *sum += eval * r_power;
+            }
+        }
+
+        let coset_evals = evals_per_coset
+            .into_iter()
+            .enumerate()
+            .filter_map(|(coset_index, evals)| Some((coset_index as CosetIndex, evals?)))
+            .collect();
+
+        Self {
+            commitment_weights,
+            proofs,
+            proof_weights,
+            weighted_proof_weights,
+            coset_evals,
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use bls12_381::G1Projective;
+
+    use super::*;
+
+    #[test]
+    fn groups_repeated_commitments_proofs_and_cosets() {
+        let point = |i: u64| G1Point::from(G1Projective::generator() * Scalar::from(i));
+        let r = Scalar::from(3u64);
+        let r_powers = [Scalar::ONE, r, r * r, r * r * r];
+        let coset_gens_pow_n = [Scalar::from(5u64), Scalar::from(7u64), Scalar::from(11u64)];
+
+        // Openings 0 and 2 are the same opening, and openings 1 and 3 share a coset
+        let schedule = BatchSchedule::new(
+            2,
+            &[0, 1, 0, 0],
+            &[2, 0, 2, 0],
+            &[
+                vec![Scalar::ONE; 2],
+                vec![Scalar::from(2u64); 2],
+                vec![Scalar::ONE; 2],
+                vec![Scalar::from(4u64); 2],
+            ],
+            &[point(1), point(2), point(1), point(3)],
+            &r_powers,
+            &coset_gens_pow_n,
+        );
+
+        assert_eq!(
+            schedule.commitment_weights,
+            [r_powers[0] + r_powers[2] + r_powers[3], r_powers[1]]
+        );
+        assert_eq!(schedule.proofs, [point(1), point(2), point(3)]);
+        assert_eq!(
+            schedule.proof_weights,
+            [r_powers[0] + r_powers[2], r_powers[1], r_powers[3]]
+        );
+        assert_eq!(
+            schedule.weighted_proof_weights,
+            [
+                (r_powers[0] + r_powers[2]) * coset_gens_pow_n[2],
+                r_powers[1] * coset_gens_pow_n[0],
+                r_powers[3] * coset_gens_pow_n[0],
+            ]
+        );
+
+        let coset_0 = r_powers[1] * Scalar::from(2u64) + r_powers[3] * Scalar::from(4u64);
+        let coset_2 = r_powers[0] + r_powers[2];
+        assert_eq!(
+            schedule.coset_evals,
+            [(0, vec![coset_0; 2]), (2, vec![coset_2; 2])]
+        );
+    }
+}
@@ -1,6 +1,9 @@
+use std::io::{self, Read, Write};
+
 use bls12_381::{
+    batch_normalize,
     fixed_base_msm::{FixedBaseMSM, UsePrecomp},
-    g1_batch_normalize, G1Point, G1Projective,
+    G1Point, G1Projective, Scalar,
 };
 use maybe_rayon::prelude::*;
 use polynomial::domain::Domain;
@@ -53,7 +56,7 @@ impl BatchToeplitzMatrixVecMul {
                     .iter()
                     .map(|point| G1Projective::from(*point))
                     .collect();
-                g1_batch_normalize(&circulant_domain.fft_g1(vector_projective))
+                batch_normalize(&circulant_domain.fft_g1(vector_projective))
             })
             .collect();
         let batch_size = vectors.len();
@@ -77,6 +80,76 @@ impl BatchToeplitzMatrixVecMul {
         }
     }
 
+    /// Returns the number of matrix-vector multiplications that are summed together.
+    pub const fn batch_size(&self) -> usize {
+        self.batch_size
+    }
+
+    /// Returns the length of the fixed vectors.
+    pub const fn size_of_vector(&self) -> usize {
+        self.size_of_vector
+    }
+
+    /// Returns the size of the circulant matrices that the Toeplitz matrices are embedded into.
+    pub(crate) fn circulant_size(&self) -> usize {
+        self.circulant_domain.roots.len()
+    }
+
+    /// Returns the number of bytes used by the precomputed FFT vectors.
+    pub fn precomputations_size_in_bytes(&self) -> usize {
+        self.precomputed_fft_vectors
+            .iter()
+            .map(FixedBaseMSM::size_in_bytes)
+            .sum()
+    }
+
+    /// Returns the number of bytes used by the circulant domain.
+    pub(crate) fn domain_size_in_bytes(&self) -> usize {
+        self.circulant_domain.size_in_bytes()
+    }
+
+    /// Writes the precomputed FFT vectors so that they can be reloaded with [`Self::read_from`].
+    pub fn write_to<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        writer.write_all(&(self.batch_size as u64).to_le_bytes())?;
+        writer.write_all(&(self.size_of_vector as u64).to_le_bytes())?;
+        writer.write_all(&(self.precomputed_fft_vectors.len() as u64).to_le_bytes())?;
+        for msm in &self.precomputed_fft_vectors {
+            msm.write_to(writer)?;
+        }
+        Ok(())
+    }
+
+    /// Reads the precomputed FFT vectors written by [`Self::write_to`].
+    ///
+    /// The tables are trusted as is; only their shape is checked.
+    pub fn read_from<R: Read>(reader: &mut R) -> io::Result<Self> {
+        let batch_size = read_usize(reader)?;
+        let size_of_vector = read_usize(reader)?;
+        let num_msms = read_usize(reader)?;
+
+        // There is one fixed-base MSM per evaluation of the circulant domain.
+        if batch_size == 0
+            || !size_of_vector.is_power_of_two()
+            || size_of_vector.checked_mul(2) != Some(num_msms)
+        {
+            return Err(io::Error::new(
+                io::ErrorKind::InvalidData,
+                "invalid dimensions for batch toeplitz precomputation",
+            ));
+        }
+
+        let precomputed_fft_vectors = (0..num_msms)
+            .map(|_| FixedBaseMSM::read_from(reader))
+            .collect::<io::Result<_>>()?;
+
+        Ok(Self {
+            batch_size,
+            precomputed_fft_vectors,
+            size_of_vector,
+            circulant_domain: Domain::new(size_of_vector * 2),
+        })
+    }
+
     /// Computes the aggregated sum of many Toeplitz matrix-vector multiplications.
     ///
     /// ie this method computes \sum_{i}^{n} A_i* x_i (where x_i is fixed)
@@ -123,6 +196,55 @@ impl BatchToeplitzMatrixVecMul {
         self.circulant_domain
             .ifft_g1_take_n(result, Some(self.size_of_vector))
     }
+
+    /// Computes the same sum as [`Self::sum_matrix_vector_mul`], using caller provided buffers
+    /// instead of allocating.
+    ///
+    /// - `circulant_rows` holds the first row of each of the `batch_size` circulant matrices,
+    ///   one after the other, each with `circulant_size` elements. It is overwritten with their FFTs.
+    /// - `msm_scalars` has the same length as `circulant_rows` and is used to transpose the FFTs.
+    /// - `result` has `circulant_size` elements. On return, its first `size_of_vector` elements
+    ///   hold the result of the matrix-vector multiplications.
+    pub(crate) fn sum_circulant_vector_mul_in_place(
+        &self,
+        circulant_rows: &mut [Scalar],
+        msm_scalars: &mut [Scalar],
+        result: &mut [G1Projective],
+    ) {
+        let circulant_size = self.circulant_size();
+        assert_eq!(circulant_rows.len(), self.batch_size * circulant_size);
+        assert_eq!(msm_scalars.len(), circulant_rows.len());
+        assert_eq!(result.len(), circulant_size);
+
+        circulant_rows
+            .maybe_par_chunks_mut(circulant_size)
+            .for_each(|row| self.circulant_domain.fft_scalars_in_place(row));
+
+        // Transpose, so that the scalars for each fixed-base MSM are contiguous.
+        for (i, row) in circulant_rows.chunks_exact(circulant_size).enumerate() {
+            for (j, value) in row.iter().enumerate() {
+                msm_scalars[j * self.batch_size + i] = *value;
+            }
+        }
+
+        let msm_scalars = &*msm_scalars;
+        result
+            .maybe_par_chunks_mut(1)
+            .enumerate()
+            .for_each(|(j, out)| {
+                let scalars = &msm_scalars[j * self.batch_size..(j + 1) * self.batch_size];
+                out[0] = self.precomputed_fft_vectors[j].msm(scalars);
+            });
+
+        self.circulant_domain.ifft_g1_in_place(result);
+    }
+}
+
+fn read_usize<R: Read>(reader: &mut R) -> io::Result<usize> {
+    let mut bytes = [0u8; 8];
+    reader.read_exact(&mut bytes)?;
+    usize::try_from(u64::from_le_bytes(bytes))
+        .map_err(|_| io::Error::new(io::ErrorKind::InvalidData, "length does not fit in usize"))
 }
 
 /// Transposes a 2D matrix
@@ -218,4 +340,33 @@ mod tests {
 
         assert_eq!(expected_result, got_result);
     }
+
+    #[test]
+    fn serialization_roundtrip() {
+        let vectors: Vec<_> = (0..3u64)
+            .map(|i| {
+                let vector: Vec<_> = (0..4u64)
+                    .map(|j| G1Projective::generator() * Scalar::from(i * 4 + j + 1))
+                    .collect();
+                g1_batch_normalize(&vector)
+            })
+            .collect();
+        let matrices: Vec<_> = (0..3u64)
+            .map(|i| {
+                let row = (0..4u64).map(|j| Scalar::from(i + j + 1)).collect();
+                let col = (0..4u64).map(|j| Scalar::from(i * j + 1)).collect();
+                ToeplitzMatrix::new(row, col)
+            })
+            .collect();
+
+        let bm = BatchToeplitzMatrixVecMul::new(vectors, UsePrecomp::Yes { width: 4 });
+        let mut bytes = Vec::new();
+        bm.write_to(&mut bytes).unwrap();
+        let restored = BatchToeplitzMatrixVecMul::read_from(&mut bytes.as_slice()).unwrap();
+
+        assert_eq!(
+            bm.sum_matrix_vector_mul(matrices.clone()),
+            restored.sum_matrix_vector_mul(matrices)
+        );
+    }
 }
@@ -1,4 +1,31 @@
+/// Errors that can occur while verifying multi-opening proofs.
 #[derive(Debug)]
 pub enum VerifierError {
+    /// The batched pairing check failed.
     InvalidProof,
+    /// The inputs to batch verification did not have the same length.
+    BatchVerificationInputsMustHaveSameLength {
+        /// Number of commitments.
+        commitments_len: usize,
+        /// Number of coset indices.
+        coset_indices_len: usize,
+        /// Number of coset evaluation sets.
+        coset_evals_len: usize,
+        /// Number of proofs.
+        proofs_len: usize,
+    },
+    /// A coset index referred to a coset that does not exist.
+    CosetIndexOutOfRange {
+        /// The invalid coset index.
+        coset_index: u64,
+        /// The number of cosets that the verifier was created for.
+        num_cosets: u64,
+    },
+    /// A set of coset evaluations did not contain one evaluation per point in the coset.
+    InvalidCosetEvaluationsLength {
+        /// The number of evaluations received.
+        num_evaluations: usize,
+        /// The number of points in each coset.
+        coset_size: usize,
+    },
 }
@@ -195,7 +195,7 @@ mod tests {
         let g1s: Vec<_> = (0..size)
             .map(|i| (g * Scalar::from(i as u64)).to_affine())
             .collect();
-        CommitKey { g1s }
+        CommitKey::new(g1s)
     }
 
     #[test]
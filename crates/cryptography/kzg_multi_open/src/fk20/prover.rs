@@ -1,12 +1,22 @@
-use bls12_381::{fixed_base_msm::UsePrecomp, g1_batch_normalize, traits::*, G1Point, Scalar};
-use polynomial::{domain::Domain, poly_coeff::PolyCoeff};
+use std::io::{self, Read, Write};
+
+use bls12_381::{
+    batch_normalize, batch_normalize_into, fixed_base_msm::UsePrecomp, traits::*, G1Point,
+    G1Projective, Scalar,
+};
+use maybe_rayon::prelude::*;
+use polynomial::{coset_fft::CosetFFT, domain::Domain, poly_coeff::PolyCoeff};
+use sha2::{Digest, Sha256};
 
 use super::h_poly::compute_h_poly_commitments;
 use crate::{
     commit_key::CommitKey,
     fk20::{
-        batch_toeplitz::BatchToeplitzMatrixVecMul, cosets::reverse_bit_order,
+        batch_toeplitz::BatchToeplitzMatrixVecMul,
+        cosets::{log2, reverse_bit_order, reverse_bits},
         h_poly::take_every_nth,
+        scratch::ProverScratch,
+        verifier::CosetIndex,
     },
 };
 
@@ -49,6 +59,8 @@ pub struct FK20Prover {
     evaluation_domain: Domain,
     /// Domain used for converting polynomial to monomial form.
     poly_domain: Domain,
+    /// Domain of size `coset_size`, used to evaluate a polynomial over a single coset.
+    coset_domain: Domain,
     /// Commitment key used for committing to the polynomial
     /// in monomial form.
     commit_key: CommitKey,
@@ -68,13 +80,11 @@ impl FK20Prover {
         number_of_points_to_open: usize,
         use_precomp: UsePrecomp,
     ) -> Self {
-        assert!(
-            points_per_proof.is_power_of_two()
-                && number_of_points_to_open.is_power_of_two()
-                && number_of_points_to_open > points_per_proof
-                && polynomial_bound.is_power_of_two()
-                && commit_key.g1s.len() >= polynomial_bound
-                && commit_key.g1s.len() > points_per_proof
+        assert_valid_parameters(
+            &commit_key,
+            polynomial_bound,
+            points_per_proof,
+            number_of_points_to_open,
         );
 
         // 1. Compute the SRS vectors that we will multiply the toeplitz matrices by.
@@ -107,11 +117,107 @@ impl FK20Prover {
         // vector multiplication, where the vector is fixed.
         let batch_toeplitz = BatchToeplitzMatrixVecMul::new(srs_vectors, use_precomp);
 
+        Self::from_batch_toeplitz(
+            commit_key,
+            polynomial_bound,
+            points_per_proof,
+            number_of_points_to_open,
+            batch_toeplitz,
+        )
+    }
+
+    /// Initialize a FK20 struct from precomputations written by [`Self::write_precomputations`].
+    ///
+    /// This skips computing the FFTs of the SRS vectors and the fixed-base MSM tables,
+    /// which dominate the time taken by [`Self::new`].
+    /// The parameters must match the ones used to create the precomputations, and the commit key
+    /// must be the same one, otherwise an `InvalidData` error is returned.
+    ///
+    /// Note: The precomputed tables are not recomputed, so they should
+    /// only be loaded from a trusted location.
+    pub fn from_precomputations<R: Read>(
+        commit_key: CommitKey,
+        polynomial_bound: usize,
+        points_per_proof: usize,
+        number_of_points_to_open: usize,
+        reader: &mut R,
+    ) -> io::Result<Self> {
+        assert_valid_parameters(
+            &commit_key,
+            polynomial_bound,
+            points_per_proof,
+            number_of_points_to_open,
+        );
+
+        let expected_header = PrecomputationsHeader::new(
+            &commit_key,
+            polynomial_bound,
+            points_per_proof,
+            number_of_points_to_open,
+        );
+        let mut header = [0u8; PrecomputationsHeader::SIZE];
+        reader.read_exact(&mut header)?;
+        if header != expected_header.to_bytes() {
+            return Err(io::Error::new(
+                io::ErrorKind::InvalidData,
+                "FK20 precomputations were created for a different version, setup or parameters",
+            ));
+        }
+
+        let batch_toeplitz = BatchToeplitzMatrixVecMul::read_from(reader)?;
+
+        // The SRS vectors are formed by taking every `points_per_proof`-th element of the
+        // truncated SRS, padded to a power of two. See `Self::new`.
+        let expected_vector_size = (commit_key.g1s.len() - points_per_proof)
+            .div_ceil(points_per_proof)
+            .next_power_of_two();
+        if batch_toeplitz.batch_size() != points_per_proof
+            || batch_toeplitz.size_of_vector() != expected_vector_size
+        {
+            return Err(io::Error::new(
+                io::ErrorKind::InvalidData,
+                "FK20 precomputations have unexpected dimensions",
+            ));
+        }
+
+        Ok(Self::from_batch_toeplitz(
+            commit_key,
+            polynomial_bound,
+            points_per_proof,
+            number_of_points_to_open,
+            batch_toeplitz,
+        ))
+    }
+
+    /// Writes the FK20 precomputations, so that they can be reloaded with
+    /// [`Self::from_precomputations`].
+    ///
+    /// The output starts with a versioned header that binds it to the parameters
+    /// and the commit key used by this prover.
+    pub fn write_precomputations<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        let header = PrecomputationsHeader::new(
+            &self.commit_key,
+            self.poly_domain.roots.len(),
+            self.coset_size,
+            self.number_of_points_to_open,
+        );
+        writer.write_all(&header.to_bytes())?;
+        self.batch_toeplitz.write_to(writer)
+    }
+
+    fn from_batch_toeplitz(
+        commit_key: CommitKey,
+        polynomial_bound: usize,
+        points_per_proof: usize,
+        number_of_points_to_open: usize,
+        batch_toeplitz: BatchToeplitzMatrixVecMul,
+    ) -> Self {
         // 2. Compute the domains needed to produce the proofs and the evaluations
         let num_proofs = number_of_points_to_open / points_per_proof;
         let proof_domain = Domain::new(num_proofs);
         let evaluation_domain = Domain::new(number_of_points_to_open);
         let poly_domain = Domain::new(polynomial_bound);
+        let coset_domain = Domain::new(points_per_proof);
 
         Self {
             batch_toeplitz,
@@ -120,6 +226,7 @@ impl FK20Prover {
             proof_domain,
             evaluation_domain,
             poly_domain,
+            coset_domain,
             commit_key,
         }
     }
@@ -144,6 +251,32 @@ impl FK20Prover {
         self.commit_key.commit_g1(&poly_coeff).into()
     }
 
+    /// Returns the number of bytes used by the commit key.
+    pub fn commit_key_size_in_bytes(&self) -> usize {
+        self.commit_key.size_in_bytes()
+    }
+
+    /// Returns the key that polynomials are committed with.
+    pub const fn commit_key(&self) -> &CommitKey {
+        &self.commit_key
+    }
+
+    /// Returns the number of bytes used by the FK20 precomputations.
+    ///
+    /// This grows exponentially with the precomputation width chosen with `UsePrecomp`.
+    pub fn precomputations_size_in_bytes(&self) -> usize {
+        self.batch_toeplitz.precomputations_size_in_bytes()
+    }
+
+    /// Returns the number of bytes used by the roots of unity and FFT tables of the domains.
+    pub fn domains_size_in_bytes(&self) -> usize {
+        self.proof_domain.size_in_bytes()
+            + self.evaluation_domain.size_in_bytes()
+            + self.poly_domain.size_in_bytes()
+            + self.coset_domain.size_in_bytes()
+            + self.batch_toeplitz.domain_size_in_bytes()
+    }
+
     /// The number of proofs that will be produced.
     pub const fn num_proofs(&self) -> usize {
         self.number_of_points_to_open / self.coset_size
@@ -183,6 +316,60 @@ impl FK20Prover {
         self.compute_multi_opening_proofs_poly_coeff(poly_coeff)
     }
 
+    /// Computes the opening proof and the evaluations for a single coset.
+    ///
+    /// This returns the same proof and evaluations as the entry at `coset_index` in
+    /// the output of [`Self::compute_multi_opening_proofs`], but only does the work needed
+    /// for that coset: a division by the coset's vanishing polynomial and a single commitment.
+    /// This is useful when only one cell needs to be proven again, but is slower than
+    /// [`Self::compute_multi_opening_proofs`] when all of the proofs are needed.
+    ///
+    /// Panics if `coset_index` is not less than [`Self::num_proofs`].
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn compute_single_opening_proof(
+        &self,
+        input: Input,
+        coset_index: CosetIndex,
+    ) -> (G1Point, Vec<Scalar>) {
+        let num_proofs = self.num_proofs();
+        assert!(
+            (coset_index as usize) < num_proofs,
+            "coset index {coset_index} is out of range, there are only {num_proofs} cosets"
+        );
+
+        let poly_coeff = match input {
+            Input::PolyCoeff(polynomial) => polynomial,
+            Input::Data(mut data) => {
+                reverse_bit_order(&mut data);
+                self.poly_domain.ifft_scalars(data)
+            }
+        };
+
+        // The proofs are in bit-reversed order, so the coset at `coset_index` is
+        // generated by `w^{reverse_bits(coset_index)}`, where `w` generates the evaluation domain.
+        let exponent = reverse_bits(coset_index as usize, log2(num_proofs as u32));
+        let coset_gen = self
+            .evaluation_domain
+            .generator
+            .pow_vartime([exponent as u64]);
+
+        // The coset is the set of roots of `X^coset_size - coset_gen^coset_size`.
+        let vanishing_constant = coset_gen.pow_vartime([self.coset_size as u64]);
+        let (quotient, remainder) =
+            divide_by_binomial(&poly_coeff, self.coset_size, vanishing_constant);
+        let proof = self.commit_key.commit_g1(&quotient).into();
+
+        // The remainder agrees with the polynomial on the coset, so we only need to
+        // evaluate a polynomial of degree less than `coset_size`.
+        let mut evaluations = self
+            .coset_domain
+            .coset_fft_scalars(PolyCoeff(remainder), &CosetFFT::new(coset_gen));
+        // Match the ordering of the evaluations in `Self::compute_coset_evaluations`.
+        reverse_bit_order(&mut evaluations);
+
+        (proof, evaluations)
+    }
+
     /// Extends the polynomial by computing its coset evaluations
     pub fn extend_polynomial(&self, input: Input) -> Vec<Vec<Scalar>> {
         // Convert data to polynomial coefficients
@@ -207,10 +394,253 @@ impl FK20Prover {
         &self,
         polynomial: PolyCoeff,
     ) -> (Vec<G1Point>, Vec<Vec<Scalar>>) {
-        // Compute opening proofs for the polynomial
+        let proofs = self.compute_proofs_projective(polynomial.clone());
+
+        (
+            batch_normalize(&proofs),
+            self.compute_coset_evaluations(polynomial),
+        )
+    }
+
+    /// Creates the scratch buffers needed by [`Self::compute_multi_opening_proofs_with_scratch`].
+    pub fn new_scratch(&self) -> ProverScratch {
+        ProverScratch::new(
+            self.poly_domain.roots.len(),
+            self.batch_toeplitz.batch_size(),
+            self.batch_toeplitz.circulant_size(),
+            self.proof_domain.roots.len(),
+            self.number_of_points_to_open,
+        )
+    }
+
+    /// Computes multi-opening proofs over the given `Input`, using `scratch` for all
+    /// intermediate values.
+    ///
+    /// This computes the same proofs and evaluations as [`Self::compute_multi_opening_proofs`],
+    /// but the results are written into `scratch` and borrowed from it: the evaluations are
+    /// returned flattened, with each coset occupying `coset_size` consecutive elements.
+    /// Reusing the same scratch for a stream of inputs bounds the memory used by the prover,
+    /// regardless of how many inputs are processed.
+    ///
+    /// Panics if `scratch` was not created by this prover, or if the input has more elements
+    /// than the polynomial bound (or, for `Input::Data`, not exactly as many).
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn compute_multi_opening_proofs_with_scratch<'a>(
+        &self,
+        input: &Input,
+        scratch: &'a mut ProverScratch,
+    ) -> (&'a [G1Point], &'a [Scalar]) {
+        let polynomial_bound = self.poly_domain.roots.len();
+        let circulant_size = self.batch_toeplitz.circulant_size();
+        assert!(
+            scratch.coefficients.len() == polynomial_bound
+                && scratch.circulant_rows.len()
+                    == self.batch_toeplitz.batch_size() * circulant_size
+                && scratch.proofs.len() == self.num_proofs()
+                && scratch.evaluations.len() == self.number_of_points_to_open,
+            "scratch was created for a prover with different parameters"
+        );
+
+        // 1. Convert the input to monomial form
+        self.write_coefficients(input, scratch);
+
+        // 2. Embed the Toeplitz matrices into circulant matrices.
         //
+        // This mirrors `compute_h_poly_commitments`: the i'th Toeplitz matrix has the
+        // every `coset_size`'th coefficient of the reversed polynomial, starting at `i`, as its
+        // first row and zeroes below the diagonal. See `CirculantMatrix::from_toeplitz`.
+        let row_len = polynomial_bound / self.coset_size;
+        let coefficients = &scratch.coefficients;
+        for (i, circulant_row) in scratch
+            .circulant_rows
+            .chunks_exact_mut(circulant_size)
+            .enumerate()
+        {
+            let toeplitz_row =
+                |j: usize| coefficients[polynomial_bound - 1 - (i + j * self.coset_size)];
+
+            circulant_row.fill(Scalar::ZERO);
+            circulant_row[0] = toeplitz_row(0);
+            for k in 1..row_len {
+                circulant_row[row_len + k] = toeplitz_row(row_len - k);
+            }
+        }
+
+        // 3. Compute the commitments to the `h` polynomials
+        self.batch_toeplitz.sum_circulant_vector_mul_in_place(
+            &mut scratch.circulant_rows,
+            &mut scratch.msm_scalars,
+            &mut scratch.h_poly_commitments,
+        );
+
+        // 4. Compute the proofs from the `h` polynomial commitments
+        let size_of_vector = self.batch_toeplitz.size_of_vector();
+        scratch.proofs[..size_of_vector]
+            .copy_from_slice(&scratch.h_poly_commitments[..size_of_vector]);
+        scratch.proofs[size_of_vector..].fill(G1Projective::identity());
+        self.proof_domain.fft_g1_in_place(&mut scratch.proofs);
+        reverse_bit_order(&mut scratch.proofs);
+        batch_normalize_into(&scratch.proofs, &mut scratch.proofs_affine);
+
+        // 5. Evaluate the polynomial over all of the cosets
+        self.write_coset_evaluations(scratch);
+
+        (&scratch.proofs_affine, &scratch.evaluations)
+    }
+
+    /// Extends the polynomial by computing its coset evaluations, using `scratch` for all
+    /// intermediate values.
+    ///
+    /// This computes the same evaluations as [`Self::extend_polynomial`], flattened like those
+    /// of [`Self::compute_multi_opening_proofs_with_scratch`], without computing any proofs.
+    ///
+    /// Panics under the same conditions as [`Self::compute_multi_opening_proofs_with_scratch`].
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn extend_polynomial_with_scratch<'a>(
+        &self,
+        input: &Input,
+        scratch: &'a mut ProverScratch,
+    ) -> &'a [Scalar] {
+        assert!(
+            scratch.coefficients.len() == self.poly_domain.roots.len()
+                && scratch.evaluations.len() == self.number_of_points_to_open,
+            "scratch was created for a prover with different parameters"
+        );
+
+        self.write_coefficients(input, scratch);
+        self.write_coset_evaluations(scratch);
+
+        &scratch.evaluations
+    }
+
+    /// Writes the monomial form of `input` to `scratch.coefficients`.
+    fn write_coefficients(&self, input: &Input, scratch: &mut ProverScratch) {
+        let polynomial_bound = self.poly_domain.roots.len();
+        match input {
+            Input::PolyCoeff(polynomial) => {
+                assert!(
+                    polynomial.len() <= polynomial_bound,
+                    "polynomial has more than {polynomial_bound} coefficients"
+                );
+                scratch.coefficients[..polynomial.len()].copy_from_slice(polynomial);
+                scratch.coefficients[polynomial.len()..].fill(Scalar::ZERO);
+            }
+            Input::Data(data) => {
+                scratch.coefficients.copy_from_slice(data);
+                reverse_bit_order(&mut scratch.coefficients);
+                self.poly_domain
+                    .ifft_scalars_in_place(&mut scratch.coefficients);
+            }
+        }
+    }
+
+    /// Evaluates the polynomial in `scratch.coefficients` over all of the cosets, in the
+    /// order of [`Self::compute_coset_evaluations`], and writes the evaluations to
+    /// `scratch.evaluations`.
+    fn write_coset_evaluations(&self, scratch: &mut ProverScratch) {
+        let polynomial_bound = self.poly_domain.roots.len();
+        scratch.evaluations[..polynomial_bound].copy_from_slice(&scratch.coefficients);
+        scratch.evaluations[polynomial_bound..].fill(Scalar::ZERO);
+        self.evaluation_domain
+            .fft_scalars_in_place(&mut scratch.evaluations);
+        reverse_bit_order(&mut scratch.evaluations);
+    }
+
+    /// Computes multi-opening proofs for a batch of `Input`s.
+    ///
+    /// This returns the same result as calling [`Self::compute_multi_opening_proofs`] on each
+    /// input, but schedules the inputs across the thread pool together and converts all of the
+    /// proofs to affine form with a single batch inversion, which gives a higher throughput
+    /// than calling it in a loop.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn compute_multi_opening_proofs_batch(
+        &self,
+        inputs: Vec<Input>,
+    ) -> Vec<(Vec<G1Point>, Vec<Vec<Scalar>>)> {
+        let proofs_and_evaluations: Vec<_> = inputs
+            .maybe_into_par_iter()
+            .map(|input| {
+                let poly_coeff = match input {
+                    Input::PolyCoeff(polynomial) => polynomial,
+                    Input::Data(mut data) => {
+                        reverse_bit_order(&mut data);
+                        self.poly_domain.ifft_scalars(data)
+                    }
+                };
+                (
+                    self.compute_proofs_projective(poly_coeff.clone()),
+                    self.compute_coset_evaluations(poly_coeff),
+                )
+            })
+            .collect();
+
+        // Normalize the proofs for all of the inputs at once, so that we only
+        // pay for one field inversion.
+        let all_proofs: Vec<_> = proofs_and_evaluations
+            .iter()
+            .flat_map(|(proofs, _)| proofs.iter().copied())
+            .collect();
+        let all_proofs = batch_normalize(&all_proofs);
+
+        let num_proofs = self.num_proofs();
+        all_proofs
+            .chunks_exact(num_proofs)
+            .zip(proofs_and_evaluations)
+            .map(|(proofs, (_, evaluations))| (proofs.to_vec(), evaluations))
+            .collect()
+    }
+
+    /// Commits to and computes multi-opening proofs for a batch of `Input`s.
+    ///
+    /// This returns the same result as calling [`Self::commit`] and [`Self::compute_multi_opening_proofs`]
+    /// on each input, but every input is only interpolated once, all of the inputs are scheduled
+    /// across the thread pool together, and the commitments and proofs are converted to affine form
+    /// with a single batch inversion.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn commit_and_compute_multi_opening_proofs_batch(
+        &self,
+        inputs: Vec<Input>,
+    ) -> Vec<(G1Point, Vec<G1Point>, Vec<Vec<Scalar>>)> {
+        let points_and_evaluations: Vec<_> = inputs
+            .maybe_into_par_iter()
+            .map(|input| {
+                let poly_coeff = match input {
+                    Input::PolyCoeff(polynomial) => polynomial,
+                    Input::Data(mut data) => {
+                        reverse_bit_order(&mut data);
+                        self.poly_domain.ifft_scalars(data)
+                    }
+                };
+
+                // The commitment is placed in front of the proofs, so that they
+                // can be normalized together.
+                let mut points = Vec::with_capacity(self.num_proofs() + 1);
+                points.push(self.commit_key.commit_g1(&poly_coeff));
+                points.extend(self.compute_proofs_projective(poly_coeff.clone()));
+                (points, self.compute_coset_evaluations(poly_coeff))
+            })
+            .collect();
+
+        let all_points: Vec<_> = points_and_evaluations
+            .iter()
+            .flat_map(|(points, _)| points.iter().copied())
+            .collect();
+        let all_points = batch_normalize(&all_points);
+
+        all_points
+            .chunks_exact(self.num_proofs() + 1)
+            .zip(points_and_evaluations)
+            .map(|(points, (_, evaluations))| (points[0], points[1..].to_vec(), evaluations))
+            .collect()
+    }
+
+    /// Computes the opening proofs for a polynomial in coefficient form, in projective form.
+    ///
+    /// The proofs are returned in bit-reversed order, so that they line up with the
+    /// coset evaluations.
+    fn compute_proofs_projective(&self, polynomial: PolyCoeff) -> Vec<G1Projective> {
         let h_poly_commitments =
-            compute_h_poly_commitments(&self.batch_toeplitz, polynomial.clone(), self.coset_size);
+            compute_h_poly_commitments(&self.batch_toeplitz, polynomial, self.coset_size);
         let mut proofs = {
             #[cfg(feature = "tracing")]
             let _span = tracing::info_span!("compute proof from h_poly_commitments").entered();
@@ -221,10 +651,7 @@ impl FK20Prover {
         // coset evaluations.
         reverse_bit_order(&mut proofs);
 
-        (
-            g1_batch_normalize(&proofs),
-            self.compute_coset_evaluations(polynomial),
-        )
+        proofs
     }
 
     #[cfg(test)]
@@ -233,6 +660,94 @@ impl FK20Prover {
     }
 }
 
+/// Divides `polynomial` by `X^degree - constant`, returning the quotient and the remainder.
+///
+/// The remainder is always returned with `degree` coefficients.
+fn divide_by_binomial(
+    polynomial: &[Scalar],
+    degree: usize,
+    constant: Scalar,
+) -> (Vec<Scalar>, Vec<Scalar>) {
+    let mut remainder = polynomial.to_vec();
+    remainder.resize(remainder.len().max(degree), Scalar::ZERO);
+
+    let mut quotient = vec![Scalar::ZERO; remainder.len() - degree];
+    // Using X^i = X^{i - degree} * (X^degree - constant) + constant * X^{i - degree},
+    // eliminate the coefficients from the highest degree down.
+    for i in (degree..remainder.len()).rev() {
+        let coeff = remainder[i];
+        quotient[i - degree] = coeff;
+        remainder[i - degree] += coeff * constant;
+    }
+    remainder.truncate(degree);
+
+    (quotient, remainder)
+}
+
+fn assert_valid_parameters(
+    commit_key: &CommitKey,
+    polynomial_bound: usize,
+    points_per_proof: usize,
+    number_of_points_to_open: usize,
+) {
+    assert!(
+        points_per_proof.is_power_of_two()
+            && number_of_points_to_open.is_power_of_two()
+            && number_of_points_to_open > points_per_proof
+            && polynomial_bound.is_power_of_two()
+            && commit_key.g1s.len() >= polynomial_bound
+            && commit_key.g1s.len() > points_per_proof
+    );
+}
+
+/// Header written in front of serialized FK20 precomputations.
+///
+/// Layout: magic (8 bytes) || version (u32 LE) || polynomial_bound (u64 LE)
+/// || points_per_proof (u64 LE) || number_of_points_to_open (u64 LE) || sha256(commit_key)
+struct PrecomputationsHeader {
+    polynomial_bound: u64,
+    points_per_proof: u64,
+    number_of_points_to_open: u64,
+    commit_key_digest: [u8; 32],
+}
+
+impl PrecomputationsHeader {
+    const MAGIC: &'static [u8; 8] = b"EKZGFK20";
+    /// Bumped whenever the serialization format changes.
+    const VERSION: u32 = 1;
+    const SIZE: usize = 8 + 4 + 3 * 8 + 32;
+
+    fn new(
+        commit_key: &CommitKey,
+        polynomial_bound: usize,
+        points_per_proof: usize,
+        number_of_points_to_open: usize,
+    ) -> Self {
+        let mut hasher = Sha256::new();
+        for point in commit_key.g1s.iter() {
+            hasher.update(point.to_compressed());
+        }
+
+        Self {
+            polynomial_bound: polynomial_bound as u64,
+            points_per_proof: points_per_proof as u64,
+            number_of_points_to_open: number_of_points_to_open as u64,
+            commit_key_digest: hasher.finalize().into(),
+        }
+    }
+
+    fn to_bytes(&self) -> [u8; Self::SIZE] {
+        let mut bytes = [0u8; Self::SIZE];
+        bytes[..8].copy_from_slice(Self::MAGIC);
+        bytes[8..12].copy_from_slice(&Self::VERSION.to_le_bytes());
+        bytes[12..20].copy_from_slice(&self.polynomial_bound.to_le_bytes());
+        bytes[20..28].copy_from_slice(&self.points_per_proof.to_le_bytes());
+        bytes[28..36].copy_from_slice(&self.number_of_points_to_open.to_le_bytes());
+        bytes[36..].copy_from_slice(&self.commit_key_digest);
+        bytes
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use std::collections::HashSet;
@@ -377,6 +892,183 @@ mod tests {
         }
     }
 
+    #[test]
+    fn single_opening_proof_matches_multi_opening_proofs() {
+        let (commit_key, _) = create_insecure_commit_verification_keys();
+
+        let poly_len = 4096;
+        let num_points_to_open = 2 * poly_len;
+        let coset_size = 64;
+
+        let fk20 = FK20Prover::new(
+            commit_key,
+            poly_len,
+            coset_size,
+            num_points_to_open,
+            UsePrecomp::No,
+        );
+
+        let data: Vec<_> = (0..poly_len).map(|i| Scalar::from(i as u64 + 7)).collect();
+        let (proofs, cells) = fk20.compute_multi_opening_proofs(Input::Data(data.clone()));
+
+        for coset_index in [0, 1, 5, fk20.num_proofs() - 1] {
+            let (proof, cell) =
+                fk20.compute_single_opening_proof(Input::Data(data.clone()), coset_index as u64);
+            assert_eq!(proof, proofs[coset_index]);
+            assert_eq!(cell, cells[coset_index]);
+        }
+    }
+
+    #[test]
+    fn batch_proofs_match_individual_proofs() {
+        let (commit_key, _) = create_insecure_commit_verification_keys();
+
+        let poly_len = 4096;
+        let fk20 = FK20Prover::new(commit_key, poly_len, 64, 2 * poly_len, UsePrecomp::No);
+
+        let inputs: Vec<Vec<Scalar>> = (0..3u64)
+            .map(|k| {
+                (0..poly_len as u64)
+                    .map(|i| Scalar::from(i * k + 1))
+                    .collect()
+            })
+            .collect();
+
+        let got = fk20
+            .compute_multi_opening_proofs_batch(inputs.iter().cloned().map(Input::Data).collect());
+        let expected: Vec<_> = inputs
+            .into_iter()
+            .map(|data| fk20.compute_multi_opening_proofs(Input::Data(data)))
+            .collect();
+
+        assert_eq!(got, expected);
+        assert!(fk20
+            .compute_multi_opening_proofs_batch(Vec::new())
+            .is_empty());
+    }
+
+    #[test]
+    fn batch_commitments_and_proofs_match_individual_calls() {
+        let (commit_key, _) = create_insecure_commit_verification_keys();
+
+        let poly_len = 4096;
+        let fk20 = FK20Prover::new(commit_key, poly_len, 64, 2 * poly_len, UsePrecomp::No);
+
+        let inputs: Vec<Vec<Scalar>> = (0..3u64)
+            .map(|k| {
+                (0..poly_len as u64)
+                    .map(|i| Scalar::from(i * k + 2))
+                    .collect()
+            })
+            .collect();
+
+        let got = fk20.commit_and_compute_multi_opening_proofs_batch(
+            inputs.iter().cloned().map(Input::Data).collect(),
+        );
+        let expected: Vec<_> = inputs
+            .into_iter()
+            .map(|data| {
+                let commitment = fk20.commit(Input::Data(data.clone()));
+                let (proofs, evaluations) = fk20.compute_multi_opening_proofs(Input::Data(data));
+                (commitment, proofs, evaluations)
+            })
+            .collect();
+
+        assert_eq!(got, expected);
+    }
+
+    #[test]
+    fn scratch_proofs_match_allocating_proofs() {
+        let (commit_key, _) = create_insecure_commit_verification_keys();
+
+        let poly_len = 4096;
+        let coset_size = 64;
+        let fk20 = FK20Prover::new(
+            commit_key,
+            poly_len,
+            coset_size,
+            2 * poly_len,
+            UsePrecomp::No,
+        );
+        let mut scratch = fk20.new_scratch();
+
+        // Reuse the same scratch for multiple inputs
+        for k in 0..2u64 {
+            let data: Vec<_> = (0..poly_len as u64)
+                .map(|i| Scalar::from(i * (k + 3) + k))
+                .collect();
+            let poly = PolyCoeff(data.iter().rev().copied().collect());
+
+            for input in [Input::Data(data.clone()), Input::PolyCoeff(poly)] {
+                let expected = match &input {
+                    Input::Data(data) => {
+                        fk20.compute_multi_opening_proofs(Input::Data(data.clone()))
+                    }
+                    Input::PolyCoeff(poly) => {
+                        fk20.compute_multi_opening_proofs(Input::PolyCoeff(poly.clone()))
+                    }
+                };
+
+                let (proofs, evaluations) =
+                    fk20.compute_multi_opening_proofs_with_scratch(&input, &mut scratch);
+                let cells: Vec<_> = evaluations
+                    .chunks_exact(coset_size)
+                    .map(<[Scalar]>::to_vec)
+                    .collect();
+
+                assert_eq!(proofs, &expected.0[..]);
+                assert_eq!(cells, expected.1);
+
+                let evaluations = fk20.extend_polynomial_with_scratch(&input, &mut scratch);
+                assert_eq!(evaluations, expected.1.concat());
+            }
+        }
+    }
+
+    #[test]
+    fn precomputations_roundtrip() {
+        let (commit_key, _) = create_insecure_commit_verification_keys();
+
+        let poly_len = 4096;
+        let num_points_to_open = 2 * poly_len;
+        let coset_size = 64;
+
+        let fk20 = FK20Prover::new(
+            commit_key.clone(),
+            poly_len,
+            coset_size,
+            num_points_to_open,
+            UsePrecomp::Yes { width: 4 },
+        );
+        let mut bytes = Vec::new();
+        fk20.write_precomputations(&mut bytes).unwrap();
+
+        let restored = FK20Prover::from_precomputations(
+            commit_key.clone(),
+            poly_len,
+            coset_size,
+            num_points_to_open,
+            &mut bytes.as_slice(),
+        )
+        .unwrap();
+
+        let data: Vec<_> = (0..poly_len).map(|i| Scalar::from(i as u64)).collect();
+        assert_eq!(
+            fk20.compute_multi_opening_proofs(Input::Data(data.clone())),
+            restored.compute_multi_opening_proofs(Input::Data(data))
+        );
+
+        // Loading with different parameters should fail
+        let result = FK20Prover::from_precomputations(
+            commit_key,
+            poly_len,
+            2 * coset_size,
+            num_points_to_open,
+            &mut bytes.as_slice(),
+        );
+        assert!(result.is_err());
+    }
+
     fn set_equality_scalar(lhs: &[Scalar], rhs: &[Scalar]) -> bool {
         if lhs.len() != rhs.len() {
             return false;
@@ -0,0 +1,62 @@
+use bls12_381::{traits::*, G1Point, G1Projective, Scalar};
+
+/// Reusable buffers for computing FK20 proofs without allocating per input.
+///
+/// A `ProverScratch` is created for a particular prover with
+/// [`Prover::new_scratch`](crate::Prover::new_scratch) and can be reused for any number of
+/// inputs with [`Prover::compute_multi_opening_proofs_with_scratch`](crate::Prover::compute_multi_opening_proofs_with_scratch).
+///
+/// Its size only depends on the prover's parameters, so the peak memory used when proving
+/// a stream of inputs is bounded by the number of scratch buffers in use, rather than by the
+/// number of inputs. Only the fixed-base MSMs still allocate, and only small buffers the
+/// size of a single MSM's scalars.
+#[derive(Debug, Clone)]
+pub struct ProverScratch {
+    /// The polynomial in monomial form.
+    pub(crate) coefficients: Vec<Scalar>,
+    /// The first rows of the circulant matrices, and subsequently their FFTs.
+    pub(crate) circulant_rows: Vec<Scalar>,
+    /// The transposed FFTs of the circulant matrices.
+    pub(crate) msm_scalars: Vec<Scalar>,
+    /// The result of the circulant matrix-vector multiplications.
+    pub(crate) h_poly_commitments: Vec<G1Projective>,
+    /// The proofs in projective form.
+    pub(crate) proofs: Vec<G1Projective>,
+    /// The proofs in affine form, in bit-reversed order.
+    pub(crate) proofs_affine: Vec<G1Point>,
+    /// The evaluations over all of the cosets, in bit-reversed order.
+    pub(crate) evaluations: Vec<Scalar>,
+}
+
+impl ProverScratch {
+    pub(crate) fn new(
+        polynomial_bound: usize,
+        batch_size: usize,
+        circulant_size: usize,
+        num_proofs: usize,
+        number_of_points_to_open: usize,
+    ) -> Self {
+        Self {
+            coefficients: vec![Scalar::ZERO; polynomial_bound],
+            circulant_rows: vec![Scalar::ZERO; batch_size * circulant_size],
+            msm_scalars: vec![Scalar::ZERO; batch_size * circulant_size],
+            h_poly_commitments: vec![G1Projective::identity(); circulant_size],
+            proofs: vec![G1Projective::identity(); num_proofs],
+            proofs_affine: vec![G1Point::identity(); num_proofs],
+            evaluations: vec![Scalar::ZERO; number_of_points_to_open],
+        }
+    }
+
+    /// Returns the number of bytes used by the buffers.
+    pub fn size_in_bytes(&self) -> usize {
+        use std::mem::size_of;
+
+        (self.coefficients.len()
+            + self.circulant_rows.len()
+            + self.msm_scalars.len()
+            + self.evaluations.len())
+            * size_of::<Scalar>()
+            + (self.h_poly_commitments.len() + self.proofs.len()) * size_of::<G1Projective>()
+            + self.proofs_affine.len() * size_of::<G1Point>()
+    }
+}
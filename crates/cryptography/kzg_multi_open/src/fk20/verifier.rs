@@ -1,13 +1,13 @@
-use std::mem::size_of;
+use std::{collections::HashMap, mem::size_of};
 
 use bls12_381::{
-    g1_batch_normalize, lincomb::g1_lincomb, multi_pairings, reduce_bytes_to_scalar_bias,
-    traits::*, G1Point, G2Prepared, Scalar,
+    lincomb::g1_lincomb, pairing_check::PairingCheck, reduce_bytes_to_scalar_bias, traits::*,
+    G1Point, G2Prepared, Scalar,
 };
 use polynomial::{domain::Domain, poly_coeff::PolyCoeff, CosetFFT};
 use sha2::{Digest, Sha256};
 
-use super::errors::VerifierError;
+use super::{batch_schedule::BatchSchedule, errors::VerifierError};
 use crate::{
     fk20::cosets::{coset_gens, reverse_bit_order},
     verification_key::VerificationKey,
@@ -107,6 +107,88 @@ impl FK20Verifier {
         }
     }
 
+    /// Returns the number of bytes used by the verification key.
+    pub fn verification_key_size_in_bytes(&self) -> usize {
+        self.verification_key.size_in_bytes()
+    }
+
+    /// Returns the number of bytes used by the coset domain.
+    pub fn domains_size_in_bytes(&self) -> usize {
+        self.coset_domain.size_in_bytes()
+    }
+
+    /// Verify a batch of multi-opening proofs.
+    ///
+    /// Each opening `i` claims that the polynomial committed to in `commitments[i]` evaluates to
+    /// `coset_evals[i]` over the coset referenced by `coset_indices[i]`, with `proofs[i]` attesting
+    /// to this. All of the openings are checked together using a single pairing check.
+    ///
+    /// The cosets and the evaluations within them are in the same (bit-reversed) order as the
+    /// output of [`crate::Prover::compute_multi_opening_proofs`], so the output of the prover can
+    /// be passed in directly. Commitments may be repeated; they are deduplicated before verifying.
+    ///
+    /// Unlike [`Self::verify_multi_opening`], this method does not panic on malformed inputs and
+    /// returns an error instead, so it can be called with untrusted data.
+    pub fn verify_openings(
+        &self,
+        commitments: &[G1Point],
+        coset_indices: &[CosetIndex],
+        coset_evals: &[Vec<Scalar>],
+        proofs: &[G1Point],
+    ) -> Result<(), VerifierError> {
+        let same_length = commitments.len() == proofs.len()
+            && coset_indices.len() == proofs.len()
+            && coset_evals.len() == proofs.len();
+        if !same_length {
+            return Err(VerifierError::BatchVerificationInputsMustHaveSameLength {
+                commitments_len: commitments.len(),
+                coset_indices_len: coset_indices.len(),
+                coset_evals_len: coset_evals.len(),
+                proofs_len: proofs.len(),
+            });
+        }
+
+        let num_cosets = self.coset_gens_bit_reversed.len() as u64;
+        if let Some(&coset_index) = coset_indices.iter().find(|&&index| index >= num_cosets) {
+            return Err(VerifierError::CosetIndexOutOfRange {
+                coset_index,
+                num_cosets,
+            });
+        }
+
+        let coset_size = self.verification_key.coset_size;
+        if let Some(evals) = coset_evals.iter().find(|evals| evals.len() != coset_size) {
+            return Err(VerifierError::InvalidCosetEvaluationsLength {
+                num_evaluations: evals.len(),
+                coset_size,
+            });
+        }
+
+        // Deduplicate the commitments, so that each distinct commitment is only
+        // used once in the multi-scalar multiplication.
+        let mut positions = HashMap::new();
+        let mut deduplicated_commitments = Vec::new();
+        let commitment_indices: Vec<CommitmentIndex> = commitments
+            .iter()
+            .map(|commitment| {
+                *positions
+                    .entry(commitment.to_compressed())
+                    .or_insert_with(|| {
+                        deduplicated_commitments.push(*commitment);
+                        deduplicated_commitments.len() - 1
+                    }) as CommitmentIndex
+            })
+            .collect();
+
+        self.verify_multi_opening(
+            &deduplicated_commitments,
+            &commitment_indices,
+            coset_indices,
+            coset_evals,
+            proofs,
+        )
+    }
+
     /// Verify multiple multi-opening proofs.
     ///
     /// Panics if the following slices do not have the same length:
@@ -126,6 +208,10 @@ impl FK20Verifier {
     /// On the API level, we however export this as Verifier.
     ///
     /// The matching function in the spec is: https://github.com/ethereum/consensus-specs/blob/13ac373a2c284dc66b48ddd2ef0a10537e4e0de6/specs/_features/eip7594/polynomial-commitments-sampling.md#verify_cell_kzg_proof_batch_impl
+    #[cfg_attr(
+        feature = "tracing",
+        tracing::instrument(skip_all, fields(num_openings = bit_reversed_proofs.len()))
+    )]
     pub fn verify_multi_opening(
         &self,
 
@@ -177,60 +263,54 @@ impl FK20Verifier {
             bit_reversed_proofs,
         );
         let r_powers = compute_powers(r, batch_size);
-        let num_unique_commitments = deduplicated_commitments.len();
 
-        // 2. Compute a random linear combination of the proofs
+        // 2. Group the openings by commitment, proof and coset
         //
-        // Safety: This unwrap can never trigger because `r_powers.len()` is `batch_size`
-        // and `bit_reversed_proofs.len()` will equal `batch_size` since we must have a proof for each item in the batch.
-        let comm_random_sum_proofs = g1_lincomb(bit_reversed_proofs, &r_powers)
-            .expect("number of proofs and number of r_powers should be the same");
+        // Repeated commitments and proofs are then a single base of the MSMs below, and
+        // repeated cosets a single coset IFFT, see `BatchSchedule`.
+        let schedule = BatchSchedule::new(
+            deduplicated_commitments.len(),
+            commitment_indices,
+            bit_reversed_coset_indices,
+            bit_reversed_coset_evals,
+            bit_reversed_proofs,
+            &r_powers,
+            &self.bit_reversed_coset_gens_pow_n,
+        );
 
-        // 3. Compute a weighted random linear combination of the proofs
+        // 3. Compute a random linear combination of the proofs
         //
-        // Where the `weight` refers to the coset_generators to the power of `n`
-        let mut weighted_r_powers = Vec::with_capacity(batch_size);
-        for (bit_reversed_coset_index, r_power) in bit_reversed_coset_indices.iter().zip(&r_powers)
-        {
-            let coset_gen_pow_n =
-                self.bit_reversed_coset_gens_pow_n[*bit_reversed_coset_index as usize];
-            weighted_r_powers.push(r_power * coset_gen_pow_n);
-        }
-        // Safety: This should never panic since `bit_reversed_proofs.len()` is equal to the batch_size.
-        let random_weighted_sum_proofs = g1_lincomb(bit_reversed_proofs, &weighted_r_powers)
-            .expect("number of proofs and number of weighted_r_powers should be the same");
+        // Safety: This unwrap can never trigger because the schedule has one weight per distinct proof.
+        let comm_random_sum_proofs = g1_lincomb(&schedule.proofs, &schedule.proof_weights)
+            .expect("number of proofs and number of proof weights should be the same");
 
-        // 4. Compute a random linear combination of the commitments
-        //
-        // For each commitment_index/commitment, we add its contribution of `r` to
-        // the associated weight for that commitment.
+        // 4. Compute a random linear combination of the commitments, plus a weighted random
+        // linear combination of the proofs
         //
-        // This is essentially taking advantage of the fact that commitments may be
-        // duplicated., so instead of calculating C = r_0 * C_0 + r_1 * C_0 naively
-        // which would require a size 2 MSM, we compute C = (r_0 + r_1) * C_0
-        // which would require a size 1 MSM and some extra field additions.
+        // Where the `weight` refers to the coset_generators to the power of `n`.
         //
-        // One can view this as trading a scalar multiplication for a field addition.
-        //
-        // The extra field additions are being calculated in the for loop below.
-        let mut weights = vec![Scalar::ZERO; num_unique_commitments];
-        for (commitment_index, r_power) in commitment_indices.iter().zip(&r_powers) {
-            weights[*commitment_index as usize] += r_power;
-        }
-        // Safety: This unwrap will never trigger because the length of `weights` has been initialized
-        // to be `deduplicated_commitments.len()`.
-        //
-        // This only panics, if `deduplicated_commitments.len()` != `weights.len()`
-        let random_sum_commitments = g1_lincomb(deduplicated_commitments, &weights)
-            .expect("number of row_commitments and number of weights should be the same");
+        // Both are computed with a single MSM, which is cheaper than two MSMs of half the size.
+        let bases: Vec<G1Point> = deduplicated_commitments
+            .iter()
+            .chain(&schedule.proofs)
+            .copied()
+            .collect();
+        let scalars: Vec<Scalar> = schedule
+            .commitment_weights
+            .iter()
+            .chain(&schedule.weighted_proof_weights)
+            .copied()
+            .collect();
+        // Safety: This unwrap will never trigger because the schedule has one weight per
+        // deduplicated commitment and one weighted weight per distinct proof.
+        let random_sum_commitments_and_weighted_proofs = g1_lincomb(&bases, &scalars)
+            .expect("number of bases and number of scalars should be the same");
 
         // 5. Compute random linear combination of the interpolation polynomials
         let random_sum_interpolation_poly = compute_sum_interpolation_poly(
             &self.coset_domain,
             &self.bit_reversed_coset_fft_gens,
-            bit_reversed_coset_evals,
-            bit_reversed_coset_indices,
-            &r_powers,
+            schedule.coset_evals,
         );
         let comm_random_sum_interpolation_poly = self
             .verification_key
@@ -239,20 +319,14 @@ impl FK20Verifier {
         // 6. Compute pairing check
         //
         // Note: This variable is `rl` in the specs.
-        let pairing_input_g1 = (random_sum_commitments - comm_random_sum_interpolation_poly)
-            + random_weighted_sum_proofs;
-
-        // The pairings function requires elements in affine representation, so we must batch normalize the
-        // pairing inputs.
-        let normalized_vectors = g1_batch_normalize(&[comm_random_sum_proofs, pairing_input_g1]);
-        let random_sum_proofs = normalized_vectors[0];
-        let pairing_input_g1 = normalized_vectors[1];
-
-        let proof_valid = multi_pairings(&[
-            (&random_sum_proofs, &self.tau_pow_n),
-            (&pairing_input_g1, &self.neg_g2_gen),
-        ]);
-        if proof_valid {
+        let pairing_input_g1 =
+            random_sum_commitments_and_weighted_proofs - comm_random_sum_interpolation_poly;
+
+        // The pairing check normalizes both G1 inputs together.
+        let mut check = PairingCheck::new();
+        check.add_projective(comm_random_sum_proofs, &self.tau_pow_n);
+        check.add_projective(pairing_input_g1, &self.neg_g2_gen);
+        if check.verify() {
             Ok(())
         } else {
             Err(VerifierError::InvalidProof)
@@ -342,42 +416,28 @@ fn compute_powers(value: Scalar, num_elements: usize) -> Vec<Scalar> {
     powers
 }
 
-/// Computes `k` Interpolation polynomials and then combines
-/// them linearly using `k` values from `r_powers`.
-/// The computed value is I(X) = I_0(x) + r * I_1(x) + ... + r^{n-1} * I_{n-1}(x)
+/// Computes the interpolation polynomial of each of the scaled coset evaluations, and sums them.
+///
+/// The evaluations of the openings of each coset have already been combined with `r_powers` by
+/// [`BatchSchedule`], and an interpolation is linear in the evaluations, so the computed value
+/// is I(X) = I_0(x) + r * I_1(x) + ... + r^{n-1} * I_{n-1}(x) with one coset IFFT per distinct coset.
 fn compute_sum_interpolation_poly(
     coset_domain: &Domain,
     bit_reversed_coset_fft_gens: &[CosetFFT],
-    bit_reversed_coset_evals: &[Vec<Scalar>],
-    bit_reversed_coset_indices: &[CosetIndex],
-    r_powers: &[Scalar],
+    scaled_bit_reversed_coset_evals: Vec<(CosetIndex, Vec<Scalar>)>,
 ) -> PolyCoeff {
     let mut random_sum_interpolation_poly = PolyCoeff::default();
 
-    for ((mut bit_reversed_coset_eval, bit_reversed_coset_index), scale_factor) in
-        bit_reversed_coset_evals
-            .iter()
-            .cloned()
-            .zip(bit_reversed_coset_indices)
-            .zip(r_powers)
-    {
+    for (bit_reversed_coset_index, mut bit_reversed_coset_eval) in scaled_bit_reversed_coset_evals {
         // Reverse the order, so it matches the fft domain
         reverse_bit_order(&mut bit_reversed_coset_eval);
         let coset_eval = bit_reversed_coset_eval; // variable rename since we un-bit reversed the vector
 
         // Compute the interpolation polynomial using a coset fft
-        let coset_gen = &bit_reversed_coset_fft_gens[*bit_reversed_coset_index as usize];
-        let ifft_scalars = coset_domain.coset_ifft_scalars(coset_eval, coset_gen);
-
-        // Scale the interpolation polynomial by the challenge
-        let scaled_interpolation_poly = ifft_scalars
-            .0
-            .into_iter()
-            .map(|coeff| coeff * scale_factor)
-            .collect::<Vec<_>>();
-
-        random_sum_interpolation_poly =
-            random_sum_interpolation_poly.add(&scaled_interpolation_poly.into());
+        let coset_gen = &bit_reversed_coset_fft_gens[bit_reversed_coset_index as usize];
+        let interpolation_poly = coset_domain.coset_ifft_scalars(coset_eval, coset_gen);
+
+        random_sum_interpolation_poly = random_sum_interpolation_poly.add(&interpolation_poly);
     }
 
     random_sum_interpolation_poly
@@ -389,6 +449,144 @@ mod tests {
 
     use super::*;
 
+    #[test]
+    fn verify_openings_accepts_prover_output() {
+        use bls12_381::fixed_base_msm::UsePrecomp;
+
+        use crate::{
+            create_insecure_commit_verification_keys, fk20::prover::FK20Prover, ProverInput,
+        };
+
+        let (commit_key, verification_key) = create_insecure_commit_verification_keys();
+        let poly_len = 4096;
+        let num_points_to_open = 2 * poly_len;
+        let coset_size = 64;
+        let num_cosets = num_points_to_open / coset_size;
+
+        let prover = FK20Prover::new(
+            commit_key,
+            poly_len,
+            coset_size,
+            num_points_to_open,
+            UsePrecomp::No,
+        );
+        let verifier = FK20Verifier::new(verification_key, num_points_to_open, num_cosets);
+
+        let data: Vec<_> = (0..poly_len).map(|i| Scalar::from(i as u64)).collect();
+        let commitment = prover.commit(ProverInput::Data(data.clone()));
+        let (proofs, cells) = prover.compute_multi_opening_proofs(ProverInput::Data(data));
+
+        let coset_indices = [3u64, 0, 100];
+        let commitments = vec![commitment; coset_indices.len()];
+        let evals: Vec<_> = coset_indices
+            .iter()
+            .map(|&i| cells[i as usize].clone())
+            .collect();
+        let selected_proofs: Vec<_> = coset_indices.iter().map(|&i| proofs[i as usize]).collect();
+
+        assert!(verifier
+            .verify_openings(&commitments, &coset_indices, &evals, &selected_proofs)
+            .is_ok());
+
+        // Swapping the proofs should fail verification
+        let mut swapped_proofs = selected_proofs.clone();
+        swapped_proofs.swap(0, 1);
+        assert!(matches!(
+            verifier.verify_openings(&commitments, &coset_indices, &evals, &swapped_proofs),
+            Err(VerifierError::InvalidProof)
+        ));
+
+        // Malformed inputs should return an error instead of panicking
+        assert!(matches!(
+            verifier.verify_openings(&commitments[1..], &coset_indices, &evals, &selected_proofs),
+            Err(VerifierError::BatchVerificationInputsMustHaveSameLength { .. })
+        ));
+        assert!(matches!(
+            verifier.verify_openings(
+                &commitments,
+                &[3, 0, num_cosets as u64],
+                &evals,
+                &selected_proofs
+            ),
+            Err(VerifierError::CosetIndexOutOfRange { .. })
+        ));
+        let mut short_evals = evals;
+        short_evals[2].pop();
+        assert!(matches!(
+            verifier.verify_openings(&commitments, &coset_indices, &short_evals, &selected_proofs),
+            Err(VerifierError::InvalidCosetEvaluationsLength { .. })
+        ));
+    }
+
+    #[test]
+    fn verify_openings_with_repeated_commitments_and_cosets() {
+        use bls12_381::fixed_base_msm::UsePrecomp;
+
+        use crate::{
+            create_insecure_commit_verification_keys, fk20::prover::FK20Prover, ProverInput,
+        };
+
+        let (commit_key, verification_key) = create_insecure_commit_verification_keys();
+        let poly_len = 4096;
+        let num_points_to_open = 2 * poly_len;
+        let coset_size = 64;
+        let num_cosets = num_points_to_open / coset_size;
+
+        let prover = FK20Prover::new(
+            commit_key,
+            poly_len,
+            coset_size,
+            num_points_to_open,
+            UsePrecomp::No,
+        );
+        let verifier = FK20Verifier::new(verification_key, num_points_to_open, num_cosets);
+
+        // Two polynomials opened at the same cosets, as in a data column, with one opening repeated
+        let polys: Vec<Vec<Scalar>> = (1..=2u64)
+            .map(|k| {
+                (0..poly_len as u64)
+                    .map(|i| Scalar::from(k * i + 1))
+                    .collect()
+            })
+            .collect();
+        let mut commitments = Vec::new();
+        let mut coset_indices = Vec::new();
+        let mut evals = Vec::new();
+        let mut proofs = Vec::new();
+        for poly in &polys {
+            let commitment = prover.commit(ProverInput::Data(poly.clone()));
+            let (poly_proofs, cells) =
+                prover.compute_multi_opening_proofs(ProverInput::Data(poly.clone()));
+            for coset_index in [5u64, 9, 5] {
+                commitments.push(commitment);
+                coset_indices.push(coset_index);
+                evals.push(cells[coset_index as usize].clone());
+                proofs.push(poly_proofs[coset_index as usize]);
+            }
+        }
+
+        assert!(verifier
+            .verify_openings(&commitments, &coset_indices, &evals, &proofs)
+            .is_ok());
+
+        // Corrupting one of the openings that share a coset must fail, even though the
+        // evaluations of the coset are combined before the IFFT
+        let mut corrupted = evals.clone();
+        corrupted[3][0] += Scalar::ONE;
+        assert!(matches!(
+            verifier.verify_openings(&commitments, &coset_indices, &corrupted, &proofs),
+            Err(VerifierError::InvalidProof)
+        ));
+
+        // As must a repeated opening that disagrees with its first occurrence
+        let mut corrupted = evals;
+        corrupted[2][1] += Scalar::ONE;
+        assert!(matches!(
+            verifier.verify_openings(&commitments, &coset_indices, &corrupted, &proofs),
+            Err(VerifierError::InvalidProof)
+        ));
+    }
+
     #[test]
     fn test_compute_powers() {
         let base = Scalar::from(2u64);
@@ -1,14 +1,36 @@
+//! KZG multi-opening proofs over cosets of roots of unity.
+//!
+//! The [`Prover`] computes opening proofs for every coset of an evaluation domain at once,
+//! using the FK20 method, and the [`Verifier`] checks a batch of these proofs, possibly for
+//! different polynomials, with a single pairing check.
+//!
+//! A typical flow is:
+//!
+//! ```text
+//! let prover = Prover::new(commit_key, poly_len, coset_size, num_points, UsePrecomp::No);
+//! let verifier = Verifier::new(verification_key, num_points, num_points / coset_size);
+//!
+//! let commitment = prover.commit(ProverInput::Data(data.clone()));
+//! let (proofs, coset_evals) = prover.compute_multi_opening_proofs(ProverInput::Data(data));
+//!
+//! let i = coset_index as usize;
+//! verifier.verify_openings(&[commitment], &[coset_index], &[coset_evals[i].clone()], &[proofs[i]])?;
+//! ```
+//!
+//! See [`Verifier::verify_openings`] for the ordering that the cosets and evaluations use.
 pub mod commit_key;
 mod fk20;
 pub mod verification_key;
 
 pub use fk20::{
     recover_evaluations_in_domain_order, CommitmentIndex, CosetIndex, Prover, ProverInput,
-    Verifier, VerifierError,
+    ProverScratch, Verifier, VerifierError,
 };
 
-#[cfg(test)]
+#[cfg(any(test, feature = "reference-impl"))]
 mod naive;
+#[cfg(any(test, feature = "reference-impl"))]
+pub mod reference;
 
 #[cfg(test)]
 pub(crate) fn create_insecure_commit_verification_keys(
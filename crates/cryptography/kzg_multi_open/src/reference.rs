@@ -0,0 +1,200 @@
+//! A slow, straightforward implementation of coset openings.
+//!
+//! Each proof is computed on its own by dividing the polynomial by the vanishing polynomial
+//! of its coset, one linear factor at a time, and committing to the quotient. Nothing is shared
+//! between cosets, so this is orders of magnitude slower than the FK20 [`Prover`](crate::Prover),
+//! but it is simple enough to be checked by inspection.
+//!
+//! It is only compiled with the `reference-impl` feature and is intended for differential
+//! testing of the optimized prover; it should not be used in production.
+//!
+//! The cosets, and the evaluations within each coset, use the same bit-reversed order as
+//! the output of [`Prover::compute_multi_opening_proofs`](crate::Prover::compute_multi_opening_proofs).
+use bls12_381::{G1Point, Scalar};
+use polynomial::{domain::Domain, poly_coeff::PolyCoeff};
+
+use crate::{
+    commit_key::CommitKey, fk20::reverse_bit_order, naive, verification_key::VerificationKey,
+    CosetIndex,
+};
+
+/// Returns the points in the coset at `coset_index`, when `num_points_to_open` points are
+/// split into cosets of size `coset_size`.
+///
+/// Panics if `coset_index` does not refer to a coset.
+pub fn coset_points(
+    num_points_to_open: usize,
+    coset_size: usize,
+    coset_index: CosetIndex,
+) -> Vec<Scalar> {
+    let num_cosets = num_points_to_open / coset_size;
+    assert!(
+        (coset_index as usize) < num_cosets,
+        "coset index {coset_index} is out of range, there are only {num_cosets} cosets"
+    );
+
+    let mut points = Domain::new(num_points_to_open).roots;
+    reverse_bit_order(&mut points);
+
+    let start = coset_index as usize * coset_size;
+    points[start..start + coset_size].to_vec()
+}
+
+/// Computes the opening proof and the evaluations of `polynomial` over the coset at `coset_index`.
+pub fn compute_coset_opening(
+    commit_key: &CommitKey,
+    polynomial: &PolyCoeff,
+    coset_size: usize,
+    num_points_to_open: usize,
+    coset_index: CosetIndex,
+) -> (G1Point, Vec<Scalar>) {
+    let points = coset_points(num_points_to_open, coset_size, coset_index);
+    naive::compute_multi_opening(commit_key, polynomial, &points)
+}
+
+/// Computes the opening proofs and the evaluations of `polynomial` over every coset.
+pub fn compute_multi_opening_proofs(
+    commit_key: &CommitKey,
+    polynomial: &PolyCoeff,
+    coset_size: usize,
+    num_points_to_open: usize,
+) -> (Vec<G1Point>, Vec<Vec<Scalar>>) {
+    let num_cosets = num_points_to_open / coset_size;
+    (0..num_cosets as CosetIndex)
+        .map(|coset_index| {
+            compute_coset_opening(
+                commit_key,
+                polynomial,
+                coset_size,
+                num_points_to_open,
+                coset_index,
+            )
+        })
+        .unzip()
+}
+
+/// Verifies a single coset opening, using a pairing check against the vanishing polynomial of the coset.
+pub fn verify_coset_opening(
+    verification_key: &VerificationKey,
+    commitment: G1Point,
+    num_points_to_open: usize,
+    coset_index: CosetIndex,
+    coset_evals: &[Scalar],
+    proof: G1Point,
+) -> bool {
+    let points = coset_points(num_points_to_open, verification_key.coset_size, coset_index);
+    coset_evals.len() == points.len()
+        && naive::verify_multi_opening(proof, verification_key, commitment, &points, coset_evals)
+}
+
+#[cfg(test)]
+mod tests {
+    use bls12_381::{fixed_base_msm::UsePrecomp, traits::*};
+    use rand::thread_rng;
+
+    use super::*;
+    use crate::{create_insecure_commit_verification_keys, Prover, ProverInput, Verifier};
+
+    const POLY_LEN: usize = 4096;
+    const COSET_SIZE: usize = 64;
+    const NUM_POINTS_TO_OPEN: usize = 2 * POLY_LEN;
+    const NUM_COSETS: usize = NUM_POINTS_TO_OPEN / COSET_SIZE;
+
+    fn random_polynomial() -> PolyCoeff {
+        PolyCoeff(
+            (0..POLY_LEN)
+                .map(|_| Scalar::random(&mut thread_rng()))
+                .collect(),
+        )
+    }
+
+    #[test]
+    fn fk20_matches_reference_implementation() {
+        let (commit_key, verification_key) = create_insecure_commit_verification_keys();
+
+        for use_precomp in [UsePrecomp::No, UsePrecomp::Yes { width: 4 }] {
+            let prover = Prover::new(
+                commit_key.clone(),
+                POLY_LEN,
+                COSET_SIZE,
+                NUM_POINTS_TO_OPEN,
+                use_precomp,
+            );
+            let verifier = Verifier::new(verification_key.clone(), NUM_POINTS_TO_OPEN, NUM_COSETS);
+
+            let polynomial = random_polynomial();
+            let commitment = prover.commit(ProverInput::PolyCoeff(polynomial.clone()));
+            let (proofs, coset_evals) =
+                prover.compute_multi_opening_proofs(ProverInput::PolyCoeff(polynomial.clone()));
+
+            // The naive opening is slow, so we only check a spread of cosets.
+            let coset_indices = [0, 1, NUM_COSETS / 2, NUM_COSETS - 1];
+            for coset_index in coset_indices {
+                let (expected_proof, expected_evals) = compute_coset_opening(
+                    &commit_key,
+                    &polynomial,
+                    COSET_SIZE,
+                    NUM_POINTS_TO_OPEN,
+                    coset_index as u64,
+                );
+                assert_eq!(proofs[coset_index], expected_proof);
+                assert_eq!(coset_evals[coset_index], expected_evals);
+
+                assert!(verify_coset_opening(
+                    &verification_key,
+                    commitment,
+                    NUM_POINTS_TO_OPEN,
+                    coset_index as u64,
+                    &coset_evals[coset_index],
+                    proofs[coset_index],
+                ));
+            }
+
+            // The batched verifier should accept the same openings.
+            let coset_indices: Vec<_> = coset_indices.iter().map(|&i| i as u64).collect();
+            let evals: Vec<_> = coset_indices
+                .iter()
+                .map(|&i| coset_evals[i as usize].clone())
+                .collect();
+            let selected_proofs: Vec<_> =
+                coset_indices.iter().map(|&i| proofs[i as usize]).collect();
+            assert!(verifier
+                .verify_openings(
+                    &vec![commitment; coset_indices.len()],
+                    &coset_indices,
+                    &evals,
+                    &selected_proofs
+                )
+                .is_ok());
+        }
+    }
+
+    #[test]
+    fn reference_verifier_rejects_wrong_evaluations() {
+        let (commit_key, verification_key) = create_insecure_commit_verification_keys();
+
+        let polynomial = random_polynomial();
+        let commitment = commit_key.commit_g1(&polynomial).into();
+        let (proof, mut evals) =
+            compute_coset_opening(&commit_key, &polynomial, COSET_SIZE, NUM_POINTS_TO_OPEN, 3);
+
+        assert!(verify_coset_opening(
+            &verification_key,
+            commitment,
+            NUM_POINTS_TO_OPEN,
+            3,
+            &evals,
+            proof
+        ));
+
+        evals[0] += Scalar::ONE;
+        assert!(!verify_coset_opening(
+            &verification_key,
+            commitment,
+            NUM_POINTS_TO_OPEN,
+            3,
+            &evals,
+            proof
+        ));
+    }
+}
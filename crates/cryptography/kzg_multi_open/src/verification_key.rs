@@ -55,6 +55,12 @@ impl VerificationKey {
         }
     }
 
+    /// Returns the number of bytes used by the G1 and G2 points.
+    pub fn size_in_bytes(&self) -> usize {
+        self.g1s.len() * std::mem::size_of::<G1Point>()
+            + (self.g2s.len() + 1) * std::mem::size_of::<G2Point>()
+    }
+
     /// Commit to a polynomial in monomial form using the G2 group elements
     pub fn commit_g2(&self, polynomial: &[Scalar]) -> G2Projective {
         assert!(self.g2s.len() >= polynomial.len());
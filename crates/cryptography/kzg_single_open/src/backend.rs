@@ -0,0 +1,280 @@
+//! The curve operations that commitments and single-point openings are written against.
+//!
+//! [`Backend`] holds the three expensive operations of KZG: multi-scalar multiplications,
+//! FFTs over the scalar field and pairing checks. Everything else is done with the `ff` and
+//! `group` traits, which most Rust curve libraries implement. Another curve library can be
+//! slotted in by implementing the trait, and compared against [`Blst`], which is the
+//! backend used by [`crate::prover::Prover`] and [`crate::verifier::Verifier`].
+//!
+//! The associated types and method names follow the `EcBackend` trait of
+//! [rust-kzg](https://github.com/grandinetech/rust-kzg), so that its backends can be wrapped
+//! by forwarding each method, and [`crate::rust_kzg`] exposes its function names.
+
+use bls12_381::{
+    ff::{Field, PrimeField},
+    group::{prime::PrimeCurveAffine, Curve},
+    lincomb::g1_lincomb,
+    multi_pairings, G1Point, G2Point, G2Prepared, Scalar,
+};
+use polynomial::domain::Domain;
+
+use crate::VerifierError;
+
+/// The projective form of a point, in which points are added.
+pub type Projective<G> = <G as PrimeCurveAffine>::Curve;
+
+/// A curve library that can compute KZG commitments and check opening proofs.
+pub trait Backend {
+    /// An element of the scalar field.
+    type Fr: PrimeField;
+    /// A point of G1 in affine form.
+    type G1: PrimeCurveAffine<Scalar = Self::Fr>;
+    /// A point of G2 in affine form.
+    type G2: PrimeCurveAffine<Scalar = Self::Fr>;
+    /// The precomputed roots of unity for FFTs of a given size.
+    type FFTSettings;
+
+    /// Computes `sum points[i] * scalars[i]`.
+    ///
+    /// Panics if `points` and `scalars` have different lengths.
+    fn g1_lincomb(points: &[Self::G1], scalars: &[Self::Fr]) -> Projective<Self::G1>;
+
+    /// Creates the FFT settings for a domain of `size` elements, which must be a power of two.
+    fn new_fft_settings(size: usize) -> Self::FFTSettings;
+
+    /// Evaluates the polynomial with coefficients `values` over the domain, or interpolates
+    /// the evaluations `values` over the domain when `inverse` is true.
+    ///
+    /// `values` is padded with zeroes to the size of the domain.
+    fn fft_fr(settings: &Self::FFTSettings, values: &[Self::Fr], inverse: bool) -> Vec<Self::Fr>;
+
+    /// Returns whether `e(a1, a2) == e(b1, b2)`.
+    fn pairings_verify(a1: &Self::G1, a2: &Self::G2, b1: &Self::G1, b2: &Self::G2) -> bool;
+}
+
+/// The backend built on blst, which the rest of the library uses.
+#[derive(Debug, Clone, Copy)]
+pub struct Blst;
+
+impl Backend for Blst {
+    type Fr = Scalar;
+    type G1 = G1Point;
+    type G2 = G2Point;
+    type FFTSettings = Domain;
+
+    fn g1_lincomb(points: &[G1Point], scalars: &[Scalar]) -> Projective<G1Point> {
+        g1_lincomb(points, scalars).expect("points and scalars must have the same length")
+    }
+
+    fn new_fft_settings(size: usize) -> Domain {
+        Domain::new(size)
+    }
+
+    fn fft_fr(settings: &Domain, values: &[Scalar], inverse: bool) -> Vec<Scalar> {
+        if inverse {
+            settings.ifft_scalars(values.to_vec()).0
+        } else {
+            settings.fft_scalars(values.to_vec().into())
+        }
+    }
+
+    fn pairings_verify(a1: &G1Point, a2: &G2Point, b1: &G1Point, b2: &G2Point) -> bool {
+        // e(a1, a2) == e(b1, b2) <=> e(a1, a2) * e(-b1, b2) == 1
+        let neg_b1 = -*b1;
+        multi_pairings(&[
+            (a1, &G2Prepared::from(*a2)),
+            (&neg_b1, &G2Prepared::from(*b2)),
+        ])
+    }
+}
+
+/// The points of the trusted setup that opening proofs are checked against.
+pub struct OpeningKey<B: Backend> {
+    /// The generator of G1.
+    pub gen_g1: B::G1,
+    /// The generator of G2.
+    pub gen_g2: B::G2,
+    /// `[τ]G₂`
+    pub tau_g2: B::G2,
+}
+
+/// Commits to the polynomial with the given coefficients.
+///
+/// Panics if there are more coefficients than points in `commit_key`.
+pub fn commit<B: Backend>(commit_key: &[B::G1], polynomial: &[B::Fr]) -> B::G1 {
+    B::g1_lincomb(&commit_key[..polynomial.len()], polynomial).to_affine()
+}
+
+/// Commits to the polynomial with the given evaluations over the domain of `settings`,
+/// in the natural order of the domain.
+pub fn commit_to_evaluations<B: Backend>(
+    settings: &B::FFTSettings,
+    commit_key: &[B::G1],
+    evaluations: &[B::Fr],
+) -> B::G1 {
+    commit::<B>(commit_key, &B::fft_fr(settings, evaluations, true))
+}
+
+/// Computes the proof that the polynomial with the given coefficients evaluates to `y`
+/// at `z`, and returns the proof and `y`.
+pub fn compute_kzg_proof<B: Backend>(
+    commit_key: &[B::G1],
+    polynomial: &[B::Fr],
+    z: B::Fr,
+) -> (B::G1, B::Fr) {
+    let (quotient, y) = divide_by_linear(polynomial, z);
+    (commit::<B>(commit_key, &quotient), y)
+}
+
+/// Checks that `proof` shows that the polynomial committed to by `commitment` evaluates
+/// to `y` at `z`.
+pub fn verify_kzg_proof<B: Backend>(
+    opening_key: &OpeningKey<B>,
+    commitment: B::G1,
+    z: B::Fr,
+    y: B::Fr,
+    proof: B::G1,
+) -> Result<(), VerifierError> {
+    // [f(τ) - f(z)]G₁
+    let lhs_g1 = (commitment.to_curve() - opening_key.gen_g1 * y).to_affine();
+
+    // [τ - z]G₂
+    let rhs_g2 = (opening_key.tau_g2.to_curve() - opening_key.gen_g2 * z).to_affine();
+
+    // Check whether `f(τ) - f(z) == q(τ) * (τ - z)`
+    B::pairings_verify(&lhs_g1, &opening_key.gen_g2, &proof, &rhs_g2)
+        .then_some(())
+        .ok_or(VerifierError::InvalidProof)
+}
+
+/// Divides the polynomial by `X - z` using ruffini's rule, and returns the quotient and
+/// the remainder.
+pub(crate) fn divide_by_linear<F: Field>(poly: &[F], z: F) -> (Vec<F>, F) {
+    let mut quotient: Vec<F> = Vec::with_capacity(poly.len());
+    let mut k = F::ZERO;
+
+    for coeff in poly.iter().rev() {
+        let t = *coeff + k;
+        quotient.push(t);
+        k = z * t;
+    }
+
+    // Pop off the remainder term
+    let remainder = quotient.pop().expect("!quotient.is_empty()");
+
+    // Reverse the results as monomial form stores coefficients starting with lowest degree
+    quotient.reverse();
+
+    (quotient, remainder)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    /// A backend with the same types as [`Blst`], but textbook algorithms, to check that
+    /// the functions above only rely on the trait.
+    struct Naive;
+
+    impl Backend for Naive {
+        type Fr = Scalar;
+        type G1 = G1Point;
+        type G2 = G2Point;
+        type FFTSettings = Domain;
+
+        fn g1_lincomb(points: &[G1Point], scalars: &[Scalar]) -> Projective<G1Point> {
+            assert_eq!(points.len(), scalars.len());
+            points
+                .iter()
+                .zip(scalars)
+                .map(|(point, scalar)| *point * scalar)
+                .sum()
+        }
+
+        fn new_fft_settings(size: usize) -> Domain {
+            Domain::new(size)
+        }
+
+        fn fft_fr(settings: &Domain, values: &[Scalar], inverse: bool) -> Vec<Scalar> {
+            // Evaluate or interpolate one point at a time.
+            let size = settings.roots.len();
+            let (root, scale) = if inverse {
+                (settings.generator_inv, settings.domain_size_inv)
+            } else {
+                (settings.generator, Scalar::ONE)
+            };
+            (0..size as u64)
+                .map(|i| {
+                    let x = root.pow_vartime([i]);
+                    let mut power = Scalar::ONE;
+                    let mut sum = Scalar::ZERO;
+                    for value in values {
+                        sum += *value * power;
+                        power *= x;
+                    }
+                    sum * scale
+                })
+                .collect()
+        }
+
+        fn pairings_verify(a1: &G1Point, a2: &G2Point, b1: &G1Point, b2: &G2Point) -> bool {
+            Blst::pairings_verify(a1, a2, b1, b2)
+        }
+    }
+
+    fn setup() -> (Vec<G1Point>, OpeningKey<Blst>) {
+        let tau = Scalar::from(1234u64);
+        let commit_key = (0..8u64)
+            .map(|i| (G1Point::generator() * tau.pow_vartime([i])).into())
+            .collect();
+        let opening_key = OpeningKey {
+            gen_g1: G1Point::generator(),
+            gen_g2: G2Point::generator(),
+            tau_g2: (G2Point::generator() * tau).into(),
+        };
+        (commit_key, opening_key)
+    }
+
+    #[test]
+    fn backends_agree() {
+        let (commit_key, opening_key) = setup();
+        let naive_key = OpeningKey::<Naive> {
+            gen_g1: opening_key.gen_g1,
+            gen_g2: opening_key.gen_g2,
+            tau_g2: opening_key.tau_g2,
+        };
+        let evaluations: Vec<Scalar> = (1..=8u64).map(Scalar::from).collect();
+
+        let blst_settings = Blst::new_fft_settings(8);
+        let naive_settings = Naive::new_fft_settings(8);
+        let polynomial = Blst::fft_fr(&blst_settings, &evaluations, true);
+        assert_eq!(
+            polynomial,
+            Naive::fft_fr(&naive_settings, &evaluations, true)
+        );
+        assert_eq!(
+            Blst::fft_fr(&blst_settings, &polynomial, false),
+            evaluations
+        );
+
+        let commitment = commit_to_evaluations::<Blst>(&blst_settings, &commit_key, &evaluations);
+        assert_eq!(
+            commitment,
+            commit_to_evaluations::<Naive>(&naive_settings, &commit_key, &evaluations)
+        );
+
+        let z = Scalar::from(5u64);
+        let (proof, y) = compute_kzg_proof::<Blst>(&commit_key, &polynomial, z);
+        assert_eq!(
+            (proof, y),
+            compute_kzg_proof::<Naive>(&commit_key, &polynomial, z)
+        );
+
+        verify_kzg_proof(&opening_key, commitment, z, y, proof).unwrap();
+        verify_kzg_proof(&naive_key, commitment, z, y, proof).unwrap();
+        assert!(matches!(
+            verify_kzg_proof(&naive_key, commitment, z, y + Scalar::ONE, proof),
+            Err(VerifierError::InvalidProof)
+        ));
+    }
+}
@@ -1,7 +1,9 @@
 mod errors;
 pub use errors::VerifierError;
 
+pub mod backend;
 pub mod prover;
+pub mod rust_kzg;
 pub mod verifier;
 
 fn bitreverse(mut n: u32, l: u32) -> u32 {
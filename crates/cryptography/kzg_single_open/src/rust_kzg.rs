@@ -0,0 +1,138 @@
+//! The EIP-4844 functions of [rust-kzg](https://github.com/grandinetech/rust-kzg), with
+//! the same names and signatures, over any [`Backend`].
+//!
+//! rust-kzg compares its curve backends by running the same generic functions over each
+//! of them. These functions can be dropped into that harness, so that this library, and
+//! any backend implemented for it, can be benchmarked alongside the rust-kzg backends.
+//!
+//! As in rust-kzg, a blob is given as its evaluations over the domain in bit-reversed order,
+//! and errors are returned as strings.
+
+use bls12_381::group::prime::PrimeCurveAffine;
+
+use crate::{
+    backend::{self, Backend, OpeningKey},
+    bitreverse_slice,
+};
+
+/// The trusted setup and the FFT settings, with the field names used by rust-kzg.
+pub struct KZGSettings<B: Backend> {
+    /// The FFT settings for the domain of a blob.
+    pub fs: B::FFTSettings,
+    /// The G1 points of the trusted setup, in monomial form.
+    pub g1_values_monomial: Vec<B::G1>,
+    /// The G2 points of the trusted setup, in monomial form.
+    pub g2_values_monomial: Vec<B::G2>,
+}
+
+impl<B: Backend> KZGSettings<B> {
+    /// Creates the settings for blobs with as many field elements as there are G1 points.
+    ///
+    /// Panics if the number of G1 points is not a power of two, or if there are fewer than
+    /// two G2 points.
+    pub fn new(g1_values_monomial: Vec<B::G1>, g2_values_monomial: Vec<B::G2>) -> Self {
+        assert!(
+            g2_values_monomial.len() >= 2,
+            "the G2 points must include [τ]G₂"
+        );
+        Self {
+            fs: B::new_fft_settings(g1_values_monomial.len()),
+            g1_values_monomial,
+            g2_values_monomial,
+        }
+    }
+
+    fn opening_key(&self) -> OpeningKey<B> {
+        OpeningKey {
+            gen_g1: B::G1::generator(),
+            gen_g2: self.g2_values_monomial[0],
+            tau_g2: self.g2_values_monomial[1],
+        }
+    }
+
+    /// Returns the coefficients of the polynomial of a blob.
+    fn blob_to_polynomial(&self, blob: &[B::Fr]) -> Result<Vec<B::Fr>, String> {
+        if blob.len() != self.g1_values_monomial.len() {
+            return Err(format!(
+                "Invalid blob length: expected {}, got {}",
+                self.g1_values_monomial.len(),
+                blob.len()
+            ));
+        }
+        let mut evaluations = blob.to_vec();
+        bitreverse_slice(&mut evaluations);
+        Ok(B::fft_fr(&self.fs, &evaluations, true))
+    }
+}
+
+/// Computes the KZG commitment to a blob.
+pub fn blob_to_kzg_commitment_rust<B: Backend>(
+    blob: &[B::Fr],
+    settings: &KZGSettings<B>,
+) -> Result<B::G1, String> {
+    let polynomial = settings.blob_to_polynomial(blob)?;
+    Ok(backend::commit::<B>(
+        &settings.g1_values_monomial,
+        &polynomial,
+    ))
+}
+
+/// Computes the KZG proof of the evaluation of a blob at `z`, and returns the proof and
+/// the evaluation.
+pub fn compute_kzg_proof_rust<B: Backend>(
+    blob: &[B::Fr],
+    z: &B::Fr,
+    settings: &KZGSettings<B>,
+) -> Result<(B::G1, B::Fr), String> {
+    let polynomial = settings.blob_to_polynomial(blob)?;
+    Ok(backend::compute_kzg_proof::<B>(
+        &settings.g1_values_monomial,
+        &polynomial,
+        *z,
+    ))
+}
+
+/// Checks the KZG proof that the polynomial committed to by `commitment` evaluates to `y`
+/// at `z`.
+pub fn verify_kzg_proof_rust<B: Backend>(
+    commitment: &B::G1,
+    z: &B::Fr,
+    y: &B::Fr,
+    proof: &B::G1,
+    settings: &KZGSettings<B>,
+) -> Result<bool, String> {
+    Ok(backend::verify_kzg_proof(&settings.opening_key(), *commitment, *z, *y, *proof).is_ok())
+}
+
+#[cfg(test)]
+mod tests {
+    use bls12_381::{ff::Field, G1Point, G2Point, Scalar};
+
+    use super::*;
+    use crate::backend::Blst;
+
+    #[test]
+    fn proofs_verify() {
+        let tau = Scalar::from(42u64);
+        let g1s = (0..16u64)
+            .map(|i| (G1Point::generator() * tau.pow_vartime([i])).into())
+            .collect();
+        let g2s = vec![G2Point::generator(), (G2Point::generator() * tau).into()];
+        let settings = KZGSettings::<Blst>::new(g1s, g2s);
+
+        let blob: Vec<Scalar> = (0..16u64).map(|i| Scalar::from(i * i)).collect();
+        let commitment = blob_to_kzg_commitment_rust(&blob, &settings).unwrap();
+        let z = Scalar::from(7u64);
+        let (proof, y) = compute_kzg_proof_rust(&blob, &z, &settings).unwrap();
+
+        assert_eq!(
+            verify_kzg_proof_rust(&commitment, &z, &y, &proof, &settings),
+            Ok(true)
+        );
+        assert_eq!(
+            verify_kzg_proof_rust(&commitment, &z, &(y + Scalar::ONE), &proof, &settings),
+            Ok(false)
+        );
+        assert!(blob_to_kzg_commitment_rust(&blob[1..], &settings).is_err());
+    }
+}
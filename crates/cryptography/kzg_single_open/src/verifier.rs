@@ -1,10 +1,15 @@
+use std::sync::Arc;
+
 use bls12_381::{
-    lincomb::g1_lincomb, multi_pairings, traits::*, G1Point, G2Point, G2Prepared, Scalar,
+    lincomb::g1_lincomb, pairing_check::PairingCheck, G1Point, G2Point, G2Prepared, Scalar,
 };
 use itertools::{chain, cloned, izip, Itertools};
 use polynomial::domain::Domain;
 
-use crate::VerifierError;
+use crate::{
+    backend::{self, Blst, OpeningKey},
+    VerifierError,
+};
 
 /// The key that is used to verify KZG single-point opening proofs.
 #[derive(Debug)]
@@ -17,19 +22,25 @@ pub struct VerificationKey {
 #[derive(Debug)]
 pub struct Verifier {
     /// Domain used to create the opening proofs.
-    pub domain: Domain,
+    pub domain: Arc<Domain>,
     /// Verification key used to verify KZG single-point opening proofs.
     pub verification_key: VerificationKey,
 }
 
 impl Verifier {
     pub fn new(domain_size: usize, verification_key: VerificationKey) -> Self {
+        Self::with_domain(Arc::new(Domain::new(domain_size)), verification_key)
+    }
+
+    /// Creates a verifier that uses `domain`, which can be shared with a [`crate::prover::Prover`].
+    pub const fn with_domain(domain: Arc<Domain>, verification_key: VerificationKey) -> Self {
         Self {
-            domain: Domain::new(domain_size),
+            domain,
             verification_key,
         }
     }
 
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     pub fn verify_kzg_proof(
         &self,
         commitment: G1Point,
@@ -38,25 +49,18 @@ impl Verifier {
         proof: G1Point,
     ) -> Result<(), VerifierError> {
         let vk = &self.verification_key;
-
-        // [f(τ) - f(z)]G₁
-        let lhs_g1 = (commitment - vk.gen_g1 * y).to_affine();
-
-        // [-1]G₂
-        let lhs_g2 = G2Prepared::from(-vk.gen_g2);
-
-        // [q(τ)]G₁
-        let rhs_g1 = proof;
-
-        // [τ - z]G₂
-        let rhs_g2 = G2Prepared::from((vk.tau_g2 - vk.gen_g2 * z).to_affine());
-
-        // Check whether `f(τ) - f(z) == q(τ) * (τ - z)`
-        multi_pairings(&[(&lhs_g1, &lhs_g2), (&rhs_g1, &rhs_g2)])
-            .then_some(())
-            .ok_or(VerifierError::InvalidProof)
+        let opening_key = OpeningKey::<Blst> {
+            gen_g1: vk.gen_g1,
+            gen_g2: vk.gen_g2,
+            tau_g2: vk.tau_g2,
+        };
+        backend::verify_kzg_proof(&opening_key, commitment, z, y, proof)
     }
 
+    #[cfg_attr(
+        feature = "tracing",
+        tracing::instrument(skip_all, fields(batch_size = commitments.len()))
+    )]
     pub fn verify_kzg_proof_batch(
         &self,
         commitments: &[G1Point],
@@ -85,15 +89,11 @@ impl Verifier {
                 let r_z = r_powers.iter().zip(zs).map(|(r_i, z_i)| r_i * z_i);
                 chain![cloned(r_powers), [-y_lincomb], r_z].collect_vec()
             };
-            g1_lincomb(&points, &scalars)
-                .expect("points.len() == scalars.len()")
-                .to_affine()
+            g1_lincomb(&points, &scalars).expect("points.len() == scalars.len()")
         };
 
         // \sum r^i * [q(τ)]G₁
-        let rhs_g1 = g1_lincomb(proofs, r_powers)
-            .expect("proofs.len() == r_powers.len()")
-            .to_affine();
+        let rhs_g1 = g1_lincomb(proofs, r_powers).expect("proofs.len() == r_powers.len()");
 
         // [-1]G₂
         let lhs_g2 = G2Prepared::from(-vk.gen_g2);
@@ -102,7 +102,11 @@ impl Verifier {
         let rhs_g2 = G2Prepared::from(vk.tau_g2);
 
         // Check whether `\sum (r^i * (f_i(τ) - y_i)) + \sum (r^i * z_i * q(τ)) == \sum (r^i * τ * q(τ))`
-        multi_pairings(&[(&lhs_g1, &lhs_g2), (&rhs_g1, &rhs_g2)])
+        let mut check = PairingCheck::new();
+        check.add_projective(lhs_g1, &lhs_g2);
+        check.add_projective(rhs_g1, &rhs_g2);
+        check
+            .verify()
             .then_some(())
             .ok_or(VerifierError::InvalidProof)
     }
@@ -48,5 +48,33 @@ fn random_g1_points(size: usize) -> Vec<G1Projective> {
     points
 }
 
-criterion_group!(benches, bench_polynomial_evaluation, bench_fft,);
+/// FFTs at the sizes used per cell and by FK20, on both sides of the parallel thresholds.
+pub fn bench_small_fft(c: &mut Criterion) {
+    for num_elements in [64, 1024] {
+        let polynomial = PolyCoeff(random_scalars(num_elements));
+        let domain = Domain::new(num_elements);
+        c.bench_function(&format!("fft_scalars of size {num_elements}"), |b| {
+            b.iter(|| {
+                domain.fft_scalars(polynomial.clone());
+            });
+        });
+    }
+
+    for num_elements in [8, 128] {
+        let points = random_g1_points(num_elements);
+        let domain = Domain::new(num_elements);
+        c.bench_function(&format!("fft_group_elements of size {num_elements}"), |b| {
+            b.iter(|| {
+                domain.fft_g1(points.clone());
+            });
+        });
+    }
+}
+
+criterion_group!(
+    benches,
+    bench_polynomial_evaluation,
+    bench_fft,
+    bench_small_fft,
+);
 criterion_main!(benches);
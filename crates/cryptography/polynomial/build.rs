@@ -0,0 +1,40 @@
+use std::{env, fmt::Write, fs, path::PathBuf};
+
+use bls12_381::{scalar_to_montgomery_limbs, traits::*, Scalar};
+
+/// log2 of the largest domain whose roots of unity are generated here.
+///
+/// This covers the extended blob used by EIP-7594, along with every smaller domain.
+/// It must match `PRECOMPUTED_LOG_SIZE` in `src/precomputed.rs`.
+const PRECOMPUTED_LOG_SIZE: u32 = 13;
+
+/// Writes the powers of a primitive `2^PRECOMPUTED_LOG_SIZE`'th root of unity to
+/// `$OUT_DIR/roots_of_unity.rs`, as scalars in Montgomery form.
+///
+/// The roots of every smaller power-of-two domain are a subset of these, so domains
+/// no longer need to compute their roots of unity and twiddle factors when they are created.
+fn main() {
+    println!("cargo:rerun-if-changed=build.rs");
+
+    let size = 1usize << PRECOMPUTED_LOG_SIZE;
+
+    // Square the largest root of unity until it has order `size`.
+    // This matches `Domain::compute_generator_for_size`.
+    let mut generator = Scalar::ROOT_OF_UNITY;
+    for _ in PRECOMPUTED_LOG_SIZE..Scalar::S {
+        generator = generator.square();
+    }
+
+    let mut table = String::from("[\n");
+    let mut root = Scalar::ONE;
+    for _ in 0..size {
+        let [a, b, c, d] = scalar_to_montgomery_limbs(&root);
+        writeln!(table, "    [{a:#018x}, {b:#018x}, {c:#018x}, {d:#018x}],").unwrap();
+        root *= generator;
+    }
+    assert_eq!(root, Scalar::ONE, "generator does not have order {size}");
+    table.push_str("]\n");
+
+    let out_dir = PathBuf::from(env::var("OUT_DIR").expect("OUT_DIR env not set"));
+    fs::write(out_dir.join("roots_of_unity.rs"), table).expect("could not write roots of unity");
+}
@@ -0,0 +1,336 @@
+//! FFT-based polynomial arithmetic.
+//!
+//! [`PolyCoeff::mul`] is the schoolbook `O(n·m)` algorithm, which is fine for the small
+//! polynomials used in tests but too slow for blob-sized inputs. The functions here use
+//! power-of-two [`Domain`]s to multiply in `O(n log n)`, and build division on top of that
+//! using Newton iteration for the power series inverse.
+use bls12_381::{traits::*, Scalar};
+
+use crate::{domain::Domain, poly_coeff::PolyCoeff};
+
+/// Multiplies two polynomials using FFTs.
+///
+/// Returns the same result as [`PolyCoeff::mul`]. This creates a domain for the product on
+/// every call; callers that multiply polynomials of the same size repeatedly should create
+/// it once and use [`fft_mul_with_domain`] instead.
+pub fn fft_mul(a: &PolyCoeff, b: &PolyCoeff) -> PolyCoeff {
+    if a.is_empty() || b.is_empty() {
+        return PolyCoeff::default();
+    }
+
+    let domain = Domain::new(a.len() + b.len() - 1);
+    fft_mul_with_domain(&domain, a, b)
+}
+
+/// Multiplies two polynomials using FFTs over `domain`, which is reused across calls
+/// instead of recomputing its roots and twiddle factors.
+///
+/// Returns the same result as [`PolyCoeff::mul`].
+///
+/// # Panics
+/// Panics if the domain has fewer elements than the product has coefficients.
+pub fn fft_mul_with_domain(domain: &Domain, a: &PolyCoeff, b: &PolyCoeff) -> PolyCoeff {
+    if a.is_empty() || b.is_empty() {
+        return PolyCoeff::default();
+    }
+
+    let result_len = a.len() + b.len() - 1;
+    assert!(
+        domain.size() >= result_len,
+        "domain of size {} is too small for a product with {result_len} coefficients",
+        domain.size()
+    );
+
+    let a_evals = domain.fft_scalars(a.clone());
+    let b_evals = domain.fft_scalars(b.clone());
+    let product_evals = a_evals
+        .into_iter()
+        .zip(b_evals)
+        .map(|(a, b)| a * b)
+        .collect();
+
+    let mut product = domain.ifft_scalars(product_evals);
+    product.truncate(result_len);
+    product
+}
+
+/// Divides `numerator` by `denominator`, returning the quotient and remainder.
+///
+/// The quotient has `numerator.len() - denominator.len() + 1` coefficients (or is empty if
+/// the numerator has lower degree), and the remainder has `denominator.len() - 1` coefficients,
+/// where `denominator.len()` does not count the zero coefficients of highest degree.
+///
+/// Returns `None` if the denominator is the zero polynomial.
+pub fn div_rem(numerator: &PolyCoeff, denominator: &PolyCoeff) -> Option<(PolyCoeff, PolyCoeff)> {
+    let denominator = trim_leading_zeros(denominator);
+    if denominator.is_empty() {
+        return None;
+    }
+
+    if numerator.len() < denominator.len() {
+        let mut remainder = numerator.clone();
+        remainder.resize(denominator.len() - 1, Scalar::ZERO);
+        return Some((PolyCoeff::default(), remainder));
+    }
+
+    // With rev(f) = X^deg(f) · f(1/X), the quotient satisfies
+    // rev(q) = rev(numerator) · rev(denominator)^-1 mod X^k
+    let quotient_len = numerator.len() - denominator.len() + 1;
+    let mut domains = Domains::default();
+
+    let reversed_numerator: PolyCoeff = numerator
+        .iter()
+        .rev()
+        .take(quotient_len)
+        .copied()
+        .collect::<Vec<_>>()
+        .into();
+    let reversed_denominator: PolyCoeff =
+        denominator.iter().rev().copied().collect::<Vec<_>>().into();
+
+    let denominator_inv = inverse_mod_x_pow(&reversed_denominator, quotient_len, &mut domains)
+        .expect("leading coefficient of the denominator is non-zero");
+
+    let mut quotient = domains.mul(&reversed_numerator, &denominator_inv);
+    quotient.truncate(quotient_len);
+    quotient.resize(quotient_len, Scalar::ZERO);
+    quotient.reverse();
+
+    let mut remainder = numerator.sub(&domains.mul(&denominator, &quotient));
+    remainder.truncate(denominator.len() - 1);
+
+    Some((quotient, remainder))
+}
+
+/// Returns the remainder of `numerator` divided by `denominator`.
+///
+/// Returns `None` if the denominator is the zero polynomial.
+pub fn rem(numerator: &PolyCoeff, denominator: &PolyCoeff) -> Option<PolyCoeff> {
+    div_rem(numerator, denominator).map(|(_, remainder)| remainder)
+}
+
+/// Divides `polynomial` by the vanishing polynomial `X^n - 1` of a domain of size `n`,
+/// returning the quotient and remainder.
+///
+/// Like [`div_rem`], the remainder always has `n` coefficients, even when `polynomial`
+/// has fewer.
+///
+/// This does not need any FFTs: since `X^n ≡ 1`, every coefficient of degree `i ≥ n`
+/// is folded into the coefficient of degree `i - n`.
+///
+/// # Panics
+/// Panics if `n` is zero.
+pub fn divide_by_domain_vanishing_poly(polynomial: &PolyCoeff, n: usize) -> (PolyCoeff, PolyCoeff) {
+    assert!(n > 0, "vanishing polynomial of an empty domain");
+
+    if polynomial.len() <= n {
+        let mut remainder = polynomial.clone();
+        remainder.resize(n, Scalar::ZERO);
+        return (PolyCoeff::default(), remainder);
+    }
+
+    // Synthetic division, from the highest degree down:
+    // q_{i - n} = a_i + q_i
+    let quotient_len = polynomial.len() - n;
+    let mut quotient = vec![Scalar::ZERO; quotient_len];
+    for i in (0..quotient_len).rev() {
+        let carry = quotient.get(i + n).copied().unwrap_or(Scalar::ZERO);
+        quotient[i] = polynomial[i + n] + carry;
+    }
+
+    let mut remainder = polynomial[..n].to_vec();
+    for (r, q) in remainder.iter_mut().zip(&quotient) {
+        *r += q;
+    }
+
+    (quotient.into(), remainder.into())
+}
+
+/// Computes `g` such that `f · g ≡ 1 mod X^k` using Newton iteration.
+///
+/// Returns `None` if the constant term of `f` is zero, in which case no inverse exists.
+fn inverse_mod_x_pow(f: &PolyCoeff, k: usize, domains: &mut Domains) -> Option<PolyCoeff> {
+    let constant_inv = Option::<Scalar>::from(f.first()?.invert())?;
+
+    // Each iteration doubles the number of correct coefficients:
+    // g ← g · (2 - f · g) mod X^len
+    let mut inverse = PolyCoeff(vec![constant_inv]);
+    let mut len = 1;
+    while len < k {
+        len = (2 * len).min(k);
+
+        let f_truncated = PolyCoeff(f.iter().take(len).copied().collect());
+        let mut correction = domains.mul(&f_truncated, &inverse).neg();
+        correction.truncate(len);
+        correction.resize(len, Scalar::ZERO);
+        correction[0] += Scalar::from(2u64);
+
+        inverse = domains.mul(&inverse, &correction);
+        inverse.truncate(len);
+    }
+
+    Some(inverse)
+}
+
+/// The domains used by a single division, indexed by the log of their size, so that each
+/// size is only created once even though the Newton iteration multiplies at it twice.
+#[derive(Default)]
+struct Domains(Vec<Option<Domain>>);
+
+impl Domains {
+    /// Multiplies `a` and `b` like [`fft_mul`], reusing the domain of the product's size.
+    fn mul(&mut self, a: &PolyCoeff, b: &PolyCoeff) -> PolyCoeff {
+        if a.is_empty() || b.is_empty() {
+            return PolyCoeff::default();
+        }
+
+        let size = (a.len() + b.len() - 1).next_power_of_two();
+        let log_size = size.trailing_zeros() as usize;
+        if self.0.len() <= log_size {
+            self.0.resize_with(log_size + 1, || None);
+        }
+        let domain = self.0[log_size].get_or_insert_with(|| Domain::new(size));
+        fft_mul_with_domain(domain, a, b)
+    }
+}
+
+/// Removes the zero coefficients of highest degree.
+fn trim_leading_zeros(polynomial: &PolyCoeff) -> PolyCoeff {
+    let len = polynomial
+        .iter()
+        .rposition(|coeff| !coeff.is_zero_vartime())
+        .map_or(0, |index| index + 1);
+    PolyCoeff(polynomial[..len].to_vec())
+}
+
+#[cfg(test)]
+mod tests {
+    use proptest::prelude::*;
+
+    use super::*;
+
+    fn arb_poly(min_len: usize, max_len: usize) -> impl Strategy<Value = PolyCoeff> {
+        prop::collection::vec(any::<u64>().prop_map(Scalar::from), min_len..=max_len)
+            .prop_map(PolyCoeff)
+    }
+
+    #[test]
+    fn fft_mul_of_empty_is_empty() {
+        let a = PolyCoeff(vec![Scalar::ONE]);
+        assert!(fft_mul(&a, &PolyCoeff::default()).is_empty());
+    }
+
+    #[test]
+    #[should_panic]
+    fn fft_mul_with_domain_rejects_small_domains() {
+        let a = PolyCoeff(vec![Scalar::ONE; 3]);
+        fft_mul_with_domain(&Domain::new(4), &a, &a);
+    }
+
+    #[test]
+    fn div_rem_pads_remainder_of_low_degree_numerator() {
+        // (X + 1) = 0 · (X^3 + X + 2) + (X + 1)
+        let numerator = PolyCoeff(vec![Scalar::ONE, Scalar::ONE]);
+        let denominator = PolyCoeff(vec![
+            Scalar::from(2u64),
+            Scalar::ONE,
+            Scalar::ZERO,
+            Scalar::ONE,
+            Scalar::ZERO,
+        ]);
+        let (quotient, remainder) = div_rem(&numerator, &denominator).unwrap();
+        assert!(quotient.is_empty());
+        assert_eq!(
+            remainder,
+            PolyCoeff(vec![Scalar::ONE, Scalar::ONE, Scalar::ZERO])
+        );
+    }
+
+    #[test]
+    fn div_rem_by_zero_is_none() {
+        let a = PolyCoeff(vec![Scalar::ONE, Scalar::ONE]);
+        assert!(div_rem(&a, &PolyCoeff(vec![Scalar::ZERO, Scalar::ZERO])).is_none());
+    }
+
+    #[test]
+    fn divide_by_domain_vanishing_poly_smoke_test() {
+        // X^5 + 2X^4 + 3 = (X + 2) · (X^4 - 1) + (X + 5)
+        let polynomial = PolyCoeff(vec![
+            Scalar::from(3u64),
+            Scalar::ZERO,
+            Scalar::ZERO,
+            Scalar::ZERO,
+            Scalar::from(2u64),
+            Scalar::ONE,
+        ]);
+        let (quotient, remainder) = divide_by_domain_vanishing_poly(&polynomial, 4);
+        assert_eq!(quotient, PolyCoeff(vec![Scalar::from(2u64), Scalar::ONE]));
+        assert_eq!(
+            remainder,
+            PolyCoeff(vec![
+                Scalar::from(5u64),
+                Scalar::ONE,
+                Scalar::ZERO,
+                Scalar::ZERO
+            ])
+        );
+    }
+
+    #[test]
+    fn divide_by_domain_vanishing_poly_pads_remainder_of_low_degree_polynomial() {
+        // 2X + 3 = 0 · (X^4 - 1) + (2X + 3)
+        let polynomial = PolyCoeff(vec![Scalar::from(3u64), Scalar::from(2u64)]);
+        let (quotient, remainder) = divide_by_domain_vanishing_poly(&polynomial, 4);
+        assert!(quotient.is_empty());
+        assert_eq!(
+            remainder,
+            PolyCoeff(vec![
+                Scalar::from(3u64),
+                Scalar::from(2u64),
+                Scalar::ZERO,
+                Scalar::ZERO
+            ])
+        );
+    }
+
+    proptest! {
+        #[test]
+        fn prop_fft_mul_matches_naive(a in arb_poly(1, 40), b in arb_poly(1, 40)) {
+            prop_assert_eq!(fft_mul(&a, &b), a.mul(&b));
+        }
+
+        #[test]
+        fn prop_div_rem_reconstructs_numerator(
+            numerator in arb_poly(0, 48),
+            denominator in arb_poly(1, 16),
+        ) {
+            prop_assume!(denominator.last().is_some_and(|c| !c.is_zero_vartime()));
+
+            let (quotient, remainder) = div_rem(&numerator, &denominator).unwrap();
+            prop_assert_eq!(remainder.len(), denominator.len() - 1);
+
+            let x = Scalar::from(0x1234_5678u64);
+            prop_assert_eq!(
+                denominator.eval(&x) * quotient.eval(&x) + remainder.eval(&x),
+                numerator.eval(&x)
+            );
+        }
+
+        #[test]
+        fn prop_vanishing_division_matches_general_division(
+            polynomial in arb_poly(0, 40),
+            log_n in 0u32..4,
+        ) {
+            let n = 1usize << log_n;
+            let (quotient, remainder) = divide_by_domain_vanishing_poly(&polynomial, n);
+            prop_assert_eq!(remainder.len(), n);
+
+            let x = Scalar::from(987_654_321u64);
+            let vanishing_at_x = x.pow_vartime([n as u64]) - Scalar::ONE;
+            prop_assert_eq!(
+                vanishing_at_x * quotient.eval(&x) + remainder.eval(&x),
+                polynomial.eval(&x)
+            );
+        }
+    }
+}
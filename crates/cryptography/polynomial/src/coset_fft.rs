@@ -1,5 +1,13 @@
+//! FFTs over multiplicative cosets of a domain of roots of unity.
+//!
+//! For a domain `H` of size `n` and a non-zero coset generator `g`, the coset `g·H`
+//! is disjoint from `H` whenever `g` is not itself in `H`. Evaluating over `g·H` is
+//! exactly what is needed to extend data for erasure coding (EIP-7594) without
+//! dividing by zero when working with the vanishing polynomial of `H`.
 use bls12_381::{traits::*, Scalar};
 
+use crate::{domain::Domain, poly_coeff::PolyCoeff};
+
 /// Represents a coset FFT configuration over a finite field.
 ///
 /// This struct stores a coset generator and its inverse,
@@ -30,6 +38,21 @@ impl CosetFFT {
     }
 }
 
+/// Evaluates `polynomial` over the coset `g·H`, where `H` is `domain` and `g` is the
+/// generator of `coset`.
+///
+/// Returns `[f(g·ω^0), f(g·ω^1), ..., f(g·ω^(n-1))]`, where `ω` is the generator of `domain`.
+pub fn coset_fft(domain: &Domain, polynomial: PolyCoeff, coset: &CosetFFT) -> Vec<Scalar> {
+    domain.coset_fft_scalars(polynomial, coset)
+}
+
+/// Interpolates the evaluations of a polynomial over the coset `g·H` back into monomial form.
+///
+/// This is the inverse of [`coset_fft`].
+pub fn coset_ifft(domain: &Domain, evaluations: Vec<Scalar>, coset: &CosetFFT) -> PolyCoeff {
+    domain.coset_ifft_scalars(evaluations, coset)
+}
+
 #[cfg(test)]
 mod tests {
     use bls12_381::Scalar;
@@ -43,4 +66,15 @@ mod tests {
         // This should panic because zero has no multiplicative inverse
         let _ = CosetFFT::new(zero);
     }
+
+    #[test]
+    fn test_coset_fft_roundtrip() {
+        let domain = Domain::new(8);
+        let coset = CosetFFT::new(Scalar::MULTIPLICATIVE_GENERATOR);
+        let polynomial = PolyCoeff((1..=8).map(Scalar::from).collect());
+
+        let evaluations = coset_fft(&domain, polynomial.clone(), &coset);
+        assert_eq!(evaluations.len(), 8);
+        assert_eq!(coset_ifft(&domain, evaluations, &coset), polynomial);
+    }
 }
@@ -1,9 +1,10 @@
-use bls12_381::{traits::*, G1Projective, Scalar};
+use bls12_381::{batch_inversion::batch_inverse, traits::*, G1Projective, Scalar};
 
 use crate::{
     coset_fft::CosetFFT,
     fft::{fft_inplace, log2_pow2, precompute_omegas, precompute_twiddle_factors_bo},
     poly_coeff::PolyCoeff,
+    precomputed::PrecomputedRoots,
 };
 
 /// A struct representing a set of points that are roots of unity,
@@ -54,18 +55,29 @@ impl Domain {
         let domain_size = Scalar::from(size as u64);
         let domain_size_inv = domain_size.invert().expect("size should not be zero");
 
-        let mut roots = Vec::with_capacity(size);
-        roots.push(Scalar::ONE);
-
-        for i in 1..size {
-            let prev_root = roots[i - 1];
-            roots.push(prev_root * generator);
-        }
-
-        let omegas = precompute_omegas(&generator, size);
-        let twiddle_factors_bo = precompute_twiddle_factors_bo(&generator, size);
-        let omegas_inv = precompute_omegas(&generator_inv, size);
-        let twiddle_factors_inv_bo = precompute_twiddle_factors_bo(&generator_inv, size);
+        // Domains that fit in the table generated by the build script copy their
+        // roots of unity and twiddle factors out of it, instead of computing them.
+        let (roots, omegas, twiddle_factors_bo, omegas_inv, twiddle_factors_inv_bo) =
+            PrecomputedRoots::new(size).map_or_else(
+                || {
+                    (
+                        compute_roots(&generator, size),
+                        precompute_omegas(&generator, size),
+                        precompute_twiddle_factors_bo(&generator, size),
+                        precompute_omegas(&generator_inv, size),
+                        precompute_twiddle_factors_bo(&generator_inv, size),
+                    )
+                },
+                |precomputed| {
+                    (
+                        precomputed.roots(),
+                        precomputed.omegas(false),
+                        precomputed.twiddle_factors_bo(false),
+                        precomputed.omegas(true),
+                        precomputed.twiddle_factors_bo(true),
+                    )
+                },
+            );
 
         Self {
             roots,
@@ -80,6 +92,18 @@ impl Domain {
         }
     }
 
+    /// Creates a new domain, returning `None` if the (padded) size exceeds [`Domain::MAX_SIZE`].
+    pub fn try_new(size: usize) -> Option<Self> {
+        let padded_size = size.checked_next_power_of_two()?;
+        (log2_pow2(padded_size) <= Self::two_adicity()).then(|| Self::new(padded_size))
+    }
+
+    /// The largest domain size that the scalar field supports.
+    ///
+    /// The multiplicative group of the scalar field has order `2^32 * t` for odd `t`,
+    /// so there are no roots of unity of order `2^33` or higher.
+    pub const MAX_SIZE: u64 = 1 << 32;
+
     /// Computes an n'th root of unity for a given `n`
     fn compute_generator_for_size(size: usize) -> Scalar {
         assert!(size.is_power_of_two());
@@ -87,7 +111,8 @@ impl Domain {
         let log_size_of_group = log2_pow2(size);
         assert!(
             log_size_of_group <= Self::two_adicity(),
-            "two adicity is 32 but group size needed is 2^{log_size_of_group}"
+            "two adicity is {} but group size needed is 2^{log_size_of_group}",
+            Self::two_adicity()
         );
 
         // We now want to compute the generator which has order `size`
@@ -103,7 +128,17 @@ impl Domain {
 
     /// The largest power of two that we can use for the domain
     const fn two_adicity() -> u32 {
-        32
+        Scalar::S
+    }
+
+    /// Returns the number of bytes used by the roots of unity and the FFT tables.
+    pub fn size_in_bytes(&self) -> usize {
+        (self.roots.len()
+            + self.omegas.len()
+            + self.twiddle_factors_bo.len()
+            + self.omegas_inv.len()
+            + self.twiddle_factors_inv_bo.len())
+            * std::mem::size_of::<Scalar>()
     }
 
     /// The size of the domain
@@ -113,32 +148,109 @@ impl Domain {
         self.roots.len()
     }
 
+    /// Checks that a buffer passed to one of the in-place transforms matches the domain size.
+    fn assert_in_place_len(&self, len: usize) {
+        assert_eq!(
+            len,
+            self.size(),
+            "in-place FFTs require the input to have exactly as many elements as the domain"
+        );
+    }
+
     /// Evaluates a polynomial at the points in the domain
     pub fn fft_scalars(&self, mut polynomial: PolyCoeff) -> Vec<Scalar> {
         // Pad the polynomial with zeroes, so that it is the same size as the
         // domain.
         polynomial.resize(self.size(), Scalar::ZERO);
 
-        fft_inplace(&self.omegas, &self.twiddle_factors_bo, &mut polynomial);
+        self.fft_scalars_in_place(&mut polynomial);
 
         polynomial.0
     }
 
+    /// Evaluates a polynomial at the points in the domain, overwriting the
+    /// coefficients with the evaluations.
+    ///
+    /// Unlike [`Domain::fft_scalars`], no padding is performed and no memory is allocated.
+    ///
+    /// # Panics
+    /// Panics if `values` does not have exactly as many elements as the domain.
+    pub fn fft_scalars_in_place(&self, values: &mut [Scalar]) {
+        self.assert_in_place_len(values.len());
+        fft_inplace(&self.omegas, &self.twiddle_factors_bo, values);
+    }
+
     /// Evaluates a polynomial at the points in the domain multiplied by a coset
     /// generator `g`.
-    pub fn coset_fft_scalars(&self, mut points: PolyCoeff, coset: &CosetFFT) -> Vec<Scalar> {
+    ///
+    /// Given a polynomial `f(X)` in monomial form and a domain `H = {ω^0, ..., ω^(n-1)}`,
+    /// this returns `[f(g·ω^0), f(g·ω^1), ..., f(g·ω^(n-1))]`.
+    ///
+    /// This is computed by scaling the i'th coefficient by `g^i`, which gives the
+    /// coefficients of `f(g·X)`, and then running a regular FFT over `H`.
+    ///
+    /// The polynomial is padded with zeroes to the size of the domain.
+    pub fn coset_fft_scalars(&self, mut polynomial: PolyCoeff, coset: &CosetFFT) -> Vec<Scalar> {
         // Pad the polynomial with zeroes, so that it is the same size as the
         // domain.
-        points.resize(self.size(), Scalar::ZERO);
+        polynomial.resize(self.size(), Scalar::ZERO);
 
         let mut coset_scale = Scalar::ONE;
-        for point in &mut points.0 {
-            *point *= coset_scale;
+        for coeff in &mut polynomial.0 {
+            *coeff *= coset_scale;
             coset_scale *= coset.generator;
         }
-        fft_inplace(&self.omegas, &self.twiddle_factors_bo, &mut points);
+        fft_inplace(&self.omegas, &self.twiddle_factors_bo, &mut polynomial);
+
+        polynomial.0
+    }
+
+    /// Evaluates a polynomial given in evaluation form over this domain at an arbitrary point `z`.
+    ///
+    /// `evaluations` are the values `f(ω^i)` in normal (not bit-reversed) order. The
+    /// polynomial is evaluated using the barycentric formula:
+    ///
+    /// ```text
+    /// f(z) = (z^n - 1) / n * Σ_i f(ω^i) * ω^i / (z - ω^i)
+    /// ```
+    ///
+    /// For a domain of roots of unity the barycentric weights `ω^i / n` are the domain
+    /// elements scaled by the inverse of the domain size, both of which are precomputed,
+    /// so this costs a single batch inversion and a linear number of multiplications.
+    ///
+    /// If `z` is an element of the domain, the corresponding evaluation is returned.
+    ///
+    /// # Panics
+    /// Panics if the number of evaluations does not match the size of the domain.
+    pub fn evaluate_lagrange_poly_at(&self, evaluations: &[Scalar], z: Scalar) -> Scalar {
+        assert_eq!(
+            evaluations.len(),
+            self.size(),
+            "number of evaluations must match the domain size"
+        );
+
+        // Compute `z - ω^i`, returning early if `z` is in the domain
+        // since the formula would divide by zero.
+        let mut denominators = Vec::with_capacity(self.size());
+        for (root, evaluation) in self.roots.iter().zip(evaluations) {
+            let denominator = z - root;
+            if denominator.is_zero_vartime() {
+                return *evaluation;
+            }
+            denominators.push(denominator);
+        }
+        batch_inverse(&mut denominators);
+
+        let sum: Scalar = evaluations
+            .iter()
+            .zip(&self.roots)
+            .zip(denominators)
+            .map(|((evaluation, root), inv_denominator)| evaluation * root * inv_denominator)
+            .sum();
 
-        points.0
+        let z_pow_n_minus_one = z.pow_vartime([self.size() as u64]) - Scalar::ONE;
+
+        sum * z_pow_n_minus_one * self.domain_size_inv
     }
 
     /// Computes a FFT for the group elements(elliptic curve points) using the roots in the domain.
@@ -151,11 +263,35 @@ impl Domain {
         // domain.
         points.resize(self.size(), G1Projective::identity());
 
-        fft_inplace(&self.omegas, &self.twiddle_factors_bo, &mut points);
+        self.fft_g1_in_place(&mut points);
 
         points
     }
 
+    /// Computes a FFT for the group elements(elliptic curve points) in place.
+    ///
+    /// # Panics
+    /// Panics if `points` does not have exactly as many elements as the domain.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn fft_g1_in_place(&self, points: &mut [G1Projective]) {
+        self.assert_in_place_len(points.len());
+        fft_inplace(&self.omegas, &self.twiddle_factors_bo, points);
+    }
+
+    /// Computes an IFFT for the group elements(elliptic curve points) in place.
+    ///
+    /// # Panics
+    /// Panics if `points` does not have exactly as many elements as the domain.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn ifft_g1_in_place(&self, points: &mut [G1Projective]) {
+        self.assert_in_place_len(points.len());
+        fft_inplace(&self.omegas_inv, &self.twiddle_factors_inv_bo, points);
+
+        for element in points.iter_mut() {
+            *element *= self.domain_size_inv;
+        }
+    }
+
     /// Computes an IFFT for the group elements(elliptic curve points) using the roots in the domain.
     pub fn ifft_g1(&self, points: Vec<G1Projective>) -> Vec<G1Projective> {
         self.ifft_g1_take_n(points, None)
@@ -201,16 +337,34 @@ impl Domain {
         // domain.
         points.resize(self.size(), Scalar::ZERO);
 
-        fft_inplace(&self.omegas_inv, &self.twiddle_factors_inv_bo, &mut points);
+        self.ifft_scalars_in_place(&mut points);
 
-        for element in &mut points {
+        points.into()
+    }
+
+    /// Interpolates the points over the domain, overwriting the evaluations
+    /// with the coefficients of the polynomial in monomial form.
+    ///
+    /// Unlike [`Domain::ifft_scalars`], no padding is performed and no memory is allocated.
+    ///
+    /// # Panics
+    /// Panics if `values` does not have exactly as many elements as the domain.
+    pub fn ifft_scalars_in_place(&self, values: &mut [Scalar]) {
+        self.assert_in_place_len(values.len());
+        fft_inplace(&self.omegas_inv, &self.twiddle_factors_inv_bo, values);
+
+        for element in values.iter_mut() {
             *element *= self.domain_size_inv;
         }
-
-        points.into()
     }
 
-    /// Interpolates a polynomial over the coset of a domain
+    /// Interpolates a polynomial over the coset of a domain.
+    ///
+    /// This is the inverse of [`Domain::coset_fft_scalars`]: given the evaluations
+    /// `[f(g·ω^0), ..., f(g·ω^(n-1))]`, it returns the coefficients of `f(X)`.
+    ///
+    /// A regular IFFT over `H` recovers the coefficients of `f(g·X)`, which are then
+    /// scaled by `g^(-i)` to undo the coset shift.
     pub fn coset_ifft_scalars(&self, points: Vec<Scalar>, coset: &CosetFFT) -> PolyCoeff {
         let mut coset_coeffs = self.ifft_scalars(points);
 
@@ -223,9 +377,175 @@ impl Domain {
     }
 }
 
+/// Magic bytes identifying a serialized [`Domain`], including the format version.
+const DOMAIN_CACHE_MAGIC: &[u8; 8] = b"EKZGDOM1";
+
+/// Number of bytes used to serialize a scalar in the domain cache.
+const SCALAR_SIZE: usize = 32;
+
+/// Errors that can occur when loading a [`Domain`] from its serialized form.
+#[derive(Debug, PartialEq, Eq)]
+pub enum DomainCacheError {
+    /// The bytes do not start with the expected magic value, or were written by
+    /// an incompatible version of this crate.
+    InvalidHeader,
+    /// The serialized domain size is zero, not a power of two or too large for the field.
+    InvalidDomainSize {
+        /// The domain size that was read from the header.
+        size: u64,
+    },
+    /// The number of bytes does not match the size implied by the header.
+    InvalidLength {
+        /// The number of bytes that were expected.
+        expected: usize,
+        /// The number of bytes that were provided.
+        got: usize,
+    },
+    /// One of the serialized values is not a canonical scalar.
+    InvalidScalar,
+    /// The tables are well-formed but do not describe the domain of the given size.
+    InconsistentTables,
+}
+
+impl Domain {
+    /// Serializes the precomputed tables of the domain.
+    ///
+    /// The result can be passed to [`Domain::from_bytes`] to skip recomputing the roots of unity
+    /// and twiddle factors, which is useful for short-lived processes.
+    ///
+    /// Layout: magic bytes, the domain size as a little-endian `u64`, then the roots,
+    /// the omegas, the bit-reversed twiddle factors and their inverse counterparts, each
+    /// scalar encoded as 32 little-endian bytes.
+    pub fn to_bytes(&self) -> Vec<u8> {
+        let tables = [
+            &self.roots,
+            &self.omegas,
+            &self.twiddle_factors_bo,
+            &self.omegas_inv,
+            &self.twiddle_factors_inv_bo,
+        ];
+        let num_scalars: usize = tables.iter().map(|table| table.len()).sum();
+
+        let mut bytes =
+            Vec::with_capacity(DOMAIN_CACHE_MAGIC.len() + 8 + num_scalars * SCALAR_SIZE);
+        bytes.extend_from_slice(DOMAIN_CACHE_MAGIC);
+        bytes.extend_from_slice(&(self.size() as u64).to_le_bytes());
+        for scalar in tables.into_iter().flatten() {
+            bytes.extend_from_slice(&scalar.to_bytes_le());
+        }
+        bytes
+    }
+
+    /// Deserializes a domain that was serialized with [`Domain::to_bytes`].
+    ///
+    /// Only cheap consistency checks are performed: the generator must have the expected
+    /// order and must match the first omega. The remaining tables are trusted, so the
+    /// bytes should come from a trusted source such as a cache written by this process.
+    pub fn from_bytes(bytes: &[u8]) -> Result<Self, DomainCacheError> {
+        let header_len = DOMAIN_CACHE_MAGIC.len() + 8;
+        if bytes.len() < header_len || &bytes[..DOMAIN_CACHE_MAGIC.len()] != DOMAIN_CACHE_MAGIC {
+            return Err(DomainCacheError::InvalidHeader);
+        }
+
+        let size = u64::from_le_bytes(
+            bytes[DOMAIN_CACHE_MAGIC.len()..header_len]
+                .try_into()
+                .expect("slice has 8 bytes"),
+        );
+        let log_size = size.trailing_zeros();
+        if !size.is_power_of_two() || log_size > Self::two_adicity() {
+            return Err(DomainCacheError::InvalidDomainSize { size });
+        }
+        let size =
+            usize::try_from(size).map_err(|_| DomainCacheError::InvalidDomainSize { size })?;
+        let log_size = log_size as usize;
+
+        let table_lens = [size, log_size, size / 2, log_size, size / 2];
+        let expected = header_len + table_lens.iter().sum::<usize>() * SCALAR_SIZE;
+        if bytes.len() != expected {
+            return Err(DomainCacheError::InvalidLength {
+                expected,
+                got: bytes.len(),
+            });
+        }
+
+        let mut scalars = bytes[header_len..].chunks_exact(SCALAR_SIZE).map(|chunk| {
+            let chunk: &[u8; SCALAR_SIZE] = chunk.try_into().expect("chunk has 32 bytes");
+            Option::<Scalar>::from(Scalar::from_bytes_le(chunk))
+                .ok_or(DomainCacheError::InvalidScalar)
+        });
+        let mut read_table = |len: usize| scalars.by_ref().take(len).collect::<Result<Vec<_>, _>>();
+
+        let roots = read_table(size)?;
+        let omegas = read_table(log_size)?;
+        let twiddle_factors_bo = read_table(size / 2)?;
+        let omegas_inv = read_table(log_size)?;
+        let twiddle_factors_inv_bo = read_table(size / 2)?;
+
+        let generator = roots.get(1).copied().unwrap_or(Scalar::ONE);
+        let has_expected_order = generator == Self::compute_generator_for_size(size)
+            && roots.first() == Some(&Scalar::ONE)
+            && omegas.last().is_none_or(|omega| *omega == generator);
+        if !has_expected_order {
+            return Err(DomainCacheError::InconsistentTables);
+        }
+
+        let generator_inv = generator.invert().expect("generator should not be zero");
+        let domain_size = Scalar::from(size as u64);
+        let domain_size_inv = domain_size.invert().expect("size should not be zero");
+
+        Ok(Self {
+            roots,
+            domain_size,
+            domain_size_inv,
+            generator,
+            generator_inv,
+            omegas,
+            twiddle_factors_bo,
+            omegas_inv,
+            twiddle_factors_inv_bo,
+        })
+    }
+}
+
+/// Returns `[ω^0, ω^1, ..., ω^{n-1}]`.
+fn compute_roots(generator: &Scalar, size: usize) -> Vec<Scalar> {
+    let mut roots = Vec::with_capacity(size);
+    roots.push(Scalar::ONE);
+
+    for i in 1..size {
+        let prev_root = roots[i - 1];
+        roots.push(prev_root * generator);
+    }
+    roots
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
+    use crate::precomputed::PRECOMPUTED_LOG_SIZE;
+
+    #[test]
+    fn precomputed_tables_match_computed_tables() {
+        for log_size in 0..=PRECOMPUTED_LOG_SIZE {
+            let size = 1 << log_size;
+            let domain = Domain::new(size);
+
+            let generator = Domain::compute_generator_for_size(size);
+            let generator_inv = generator.invert().unwrap();
+            assert_eq!(domain.roots, compute_roots(&generator, size));
+            assert_eq!(domain.omegas, precompute_omegas(&generator, size));
+            assert_eq!(
+                domain.twiddle_factors_bo,
+                precompute_twiddle_factors_bo(&generator, size)
+            );
+            assert_eq!(domain.omegas_inv, precompute_omegas(&generator_inv, size));
+            assert_eq!(
+                domain.twiddle_factors_inv_bo,
+                precompute_twiddle_factors_bo(&generator_inv, size)
+            );
+        }
+    }
 
     #[test]
     fn largest_root_of_unity_has_correct_order() {
@@ -240,6 +560,14 @@ mod tests {
         }
     }
 
+    #[test]
+    fn try_new_rejects_domains_that_are_too_large() {
+        assert!(Domain::try_new(16).is_some());
+        assert_eq!(Domain::try_new(15).map(|domain| domain.size()), Some(16));
+        #[cfg(target_pointer_width = "64")]
+        assert!(Domain::try_new((Domain::MAX_SIZE + 1) as usize).is_none());
+    }
+
     #[test]
     fn fft_test_polynomial() {
         let evaluations = vec![Scalar::from(2u64), Scalar::from(4u64)];
@@ -271,6 +599,124 @@ mod tests {
         assert_eq!(got_poly, polynomial);
     }
 
+    #[test]
+    fn test_coset_fft_matches_naive_evaluation() {
+        let polynomial = PolyCoeff((0..16).map(|i| Scalar::from(i * i + 1)).collect());
+
+        let domain = Domain::new(16);
+        let coset_fft = CosetFFT::new(Scalar::from(7u64));
+        let coset_evals = domain.coset_fft_scalars(polynomial.clone(), &coset_fft);
+
+        for (root, got) in domain.roots.iter().zip(coset_evals) {
+            assert_eq!(polynomial.eval(&(coset_fft.generator * root)), got);
+        }
+    }
+
+    #[test]
+    fn in_place_fft_matches_allocating_fft() {
+        let domain = Domain::new(16);
+        let polynomial = PolyCoeff((0..16).map(|i| Scalar::from(i + 3)).collect());
+
+        let mut values = polynomial.0.clone();
+        domain.fft_scalars_in_place(&mut values);
+        assert_eq!(values, domain.fft_scalars(polynomial.clone()));
+
+        domain.ifft_scalars_in_place(&mut values);
+        assert_eq!(values, polynomial.0);
+
+        let points: Vec<_> = (0..16)
+            .map(|_| G1Projective::random(&mut rand::thread_rng()))
+            .collect();
+        let mut points_in_place = points.clone();
+        domain.fft_g1_in_place(&mut points_in_place);
+        assert_eq!(points_in_place, domain.fft_g1(points.clone()));
+
+        domain.ifft_g1_in_place(&mut points_in_place);
+        assert_eq!(points_in_place, points);
+    }
+
+    #[test]
+    #[should_panic(expected = "in-place FFTs require the input")]
+    fn in_place_fft_rejects_wrong_length() {
+        let domain = Domain::new(8);
+        let mut values = vec![Scalar::ONE; 4];
+        domain.fft_scalars_in_place(&mut values);
+    }
+
+    #[test]
+    fn barycentric_evaluation_matches_monomial_evaluation() {
+        let domain = Domain::new(32);
+        let polynomial = PolyCoeff((0..32).map(|i| Scalar::from(i * 7 + 1)).collect());
+        let evaluations = domain.fft_scalars(polynomial.clone());
+
+        let z = Scalar::from(123_456_789u64);
+        assert_eq!(
+            domain.evaluate_lagrange_poly_at(&evaluations, z),
+            polynomial.eval(&z)
+        );
+
+        // Points inside of the domain return the evaluation directly
+        for (root, evaluation) in domain.roots.iter().zip(&evaluations) {
+            assert_eq!(
+                domain.evaluate_lagrange_poly_at(&evaluations, *root),
+                *evaluation
+            );
+        }
+    }
+
+    #[test]
+    fn domain_cache_roundtrip() {
+        for size in [1, 2, 64] {
+            let domain = Domain::new(size);
+            let restored = Domain::from_bytes(&domain.to_bytes()).unwrap();
+
+            assert_eq!(restored.roots, domain.roots);
+            assert_eq!(restored.generator, domain.generator);
+            assert_eq!(restored.generator_inv, domain.generator_inv);
+            assert_eq!(restored.domain_size_inv, domain.domain_size_inv);
+            assert_eq!(restored.to_bytes(), domain.to_bytes());
+
+            let polynomial = PolyCoeff((0..size as u64).map(Scalar::from).collect());
+            assert_eq!(
+                restored.fft_scalars(polynomial.clone()),
+                domain.fft_scalars(polynomial)
+            );
+        }
+    }
+
+    #[test]
+    fn domain_cache_rejects_malformed_bytes() {
+        let bytes = Domain::new(8).to_bytes();
+
+        assert_eq!(
+            Domain::from_bytes(&bytes[..4]).unwrap_err(),
+            DomainCacheError::InvalidHeader
+        );
+        assert_eq!(
+            Domain::from_bytes(&bytes[..bytes.len() - 1]).unwrap_err(),
+            DomainCacheError::InvalidLength {
+                expected: bytes.len(),
+                got: bytes.len() - 1
+            }
+        );
+
+        let mut wrong_size = bytes.clone();
+        wrong_size[8] = 3;
+        assert_eq!(
+            Domain::from_bytes(&wrong_size).unwrap_err(),
+            DomainCacheError::InvalidDomainSize { size: 3 }
+        );
+
+        // Swap the first two roots so that the generator is no longer at index 1
+        let mut inconsistent = bytes;
+        let (first, second) = inconsistent[16..80].split_at_mut(32);
+        first.swap_with_slice(second);
+        assert_eq!(
+            Domain::from_bytes(&inconsistent).unwrap_err(),
+            DomainCacheError::InconsistentTables
+        );
+    }
+
     #[test]
     fn fft_g1_smoke_test() {
         fn naive_msm(points: &[G1Projective], scalars: &[Scalar]) -> G1Projective {
@@ -0,0 +1,206 @@
+//! Polynomials in evaluation form.
+//!
+//! [`PolyCoeff`] stores a polynomial by its coefficients. Much of the code in this
+//! workspace instead works with the evaluations of a polynomial over a [`Domain`], and
+//! plain `Vec<Scalar>`s make it easy to accidentally mix the two representations, or to
+//! combine evaluations over different domains. [`EvalPoly`] ties the evaluations to the
+//! domain they were computed over.
+use bls12_381::Scalar;
+
+use crate::{domain::Domain, poly_coeff::PolyCoeff};
+
+/// A polynomial represented by its evaluations over the elements of a [`Domain`],
+/// in normal (not bit-reversed) order.
+#[derive(Debug, Clone)]
+pub struct EvalPoly<'a> {
+    /// The domain that the polynomial was evaluated over.
+    domain: &'a Domain,
+    /// `evaluations[i]` is the evaluation of the polynomial at `domain.roots[i]`.
+    evaluations: Vec<Scalar>,
+}
+
+impl<'a> EvalPoly<'a> {
+    /// Creates a polynomial from its evaluations over `domain`.
+    ///
+    /// # Panics
+    /// Panics if the number of evaluations does not match the size of the domain.
+    pub fn new(domain: &'a Domain, evaluations: Vec<Scalar>) -> Self {
+        assert_eq!(
+            evaluations.len(),
+            domain.roots.len(),
+            "number of evaluations must match the domain size"
+        );
+        Self {
+            domain,
+            evaluations,
+        }
+    }
+
+    /// Evaluates a polynomial in coefficient form over `domain`.
+    ///
+    /// # Panics
+    /// Panics if the polynomial has more coefficients than there are elements in the domain,
+    /// since it could not be represented uniquely by its evaluations.
+    pub fn from_coeff(domain: &'a Domain, polynomial: PolyCoeff) -> Self {
+        assert!(
+            polynomial.len() <= domain.roots.len(),
+            "polynomial has more coefficients than the domain size"
+        );
+        Self {
+            domain,
+            evaluations: domain.fft_scalars(polynomial),
+        }
+    }
+
+    /// Interpolates the polynomial, returning it in coefficient form.
+    pub fn to_coeff(&self) -> PolyCoeff {
+        self.domain.ifft_scalars(self.evaluations.clone())
+    }
+
+    /// Returns the domain that the polynomial is evaluated over.
+    pub const fn domain(&self) -> &'a Domain {
+        self.domain
+    }
+
+    /// Returns the evaluations of the polynomial.
+    pub fn evaluations(&self) -> &[Scalar] {
+        &self.evaluations
+    }
+
+    /// Consumes the polynomial, returning its evaluations.
+    pub fn into_evaluations(self) -> Vec<Scalar> {
+        self.evaluations
+    }
+
+    /// Evaluates the polynomial at an arbitrary point using the barycentric formula.
+    pub fn eval(&self, z: Scalar) -> Scalar {
+        self.domain.evaluate_lagrange_poly_at(&self.evaluations, z)
+    }
+
+    /// Adds two polynomials evaluated over the same domain.
+    ///
+    /// # Panics
+    /// Panics if the polynomials are evaluated over different domains.
+    #[must_use]
+    pub fn add(&self, other: &Self) -> Self {
+        self.zip_with(other, |a, b| a + b)
+    }
+
+    /// Subtracts `other` from `self`.
+    ///
+    /// # Panics
+    /// Panics if the polynomials are evaluated over different domains.
+    #[must_use]
+    pub fn sub(&self, other: &Self) -> Self {
+        self.zip_with(other, |a, b| a - b)
+    }
+
+    /// Multiplies two polynomials evaluated over the same domain.
+    ///
+    /// The product is only correct as a polynomial if its degree is less than the size of the
+    /// domain; otherwise the result is the product reduced modulo the vanishing polynomial.
+    ///
+    /// # Panics
+    /// Panics if the polynomials are evaluated over different domains.
+    #[must_use]
+    pub fn mul(&self, other: &Self) -> Self {
+        self.zip_with(other, |a, b| a * b)
+    }
+
+    /// Multiplies the polynomial by a constant.
+    #[must_use]
+    pub fn scale(&self, factor: Scalar) -> Self {
+        Self {
+            domain: self.domain,
+            evaluations: self.evaluations.iter().map(|eval| eval * factor).collect(),
+        }
+    }
+
+    /// Applies `op` pointwise to the evaluations of `self` and `other`.
+    fn zip_with(&self, other: &Self, op: impl Fn(&Scalar, &Scalar) -> Scalar) -> Self {
+        assert!(
+            same_domain(self.domain, other.domain),
+            "polynomials are evaluated over different domains"
+        );
+        Self {
+            domain: self.domain,
+            evaluations: self
+                .evaluations
+                .iter()
+                .zip(&other.evaluations)
+                .map(|(a, b)| op(a, b))
+                .collect(),
+        }
+    }
+}
+
+impl PartialEq for EvalPoly<'_> {
+    fn eq(&self, other: &Self) -> bool {
+        same_domain(self.domain, other.domain) && self.evaluations == other.evaluations
+    }
+}
+
+impl Eq for EvalPoly<'_> {}
+
+/// Two domains are the same if they have the same size and generator.
+fn same_domain(a: &Domain, b: &Domain) -> bool {
+    std::ptr::eq(a, b) || (a.roots.len() == b.roots.len() && a.generator == b.generator)
+}
+
+#[cfg(test)]
+mod tests {
+    use bls12_381::traits::*;
+
+    use super::*;
+
+    fn poly(coeffs: &[u64]) -> PolyCoeff {
+        PolyCoeff(coeffs.iter().copied().map(Scalar::from).collect())
+    }
+
+    #[test]
+    fn coefficient_form_roundtrip() {
+        let domain = Domain::new(8);
+        let polynomial = poly(&[1, 2, 3, 4, 5, 6, 7, 8]);
+
+        let eval_poly = EvalPoly::from_coeff(&domain, polynomial.clone());
+        assert_eq!(eval_poly.to_coeff(), polynomial);
+
+        let z = Scalar::from(42u64);
+        assert_eq!(eval_poly.eval(z), polynomial.eval(&z));
+    }
+
+    #[test]
+    fn arithmetic_matches_coefficient_form() {
+        let domain = Domain::new(8);
+        let a = poly(&[1, 2, 3]);
+        let b = poly(&[4, 5]);
+
+        let a_eval = EvalPoly::from_coeff(&domain, a.clone());
+        let b_eval = EvalPoly::from_coeff(&domain, b.clone());
+
+        let mut sum = a_eval.add(&b_eval).to_coeff();
+        sum.truncate(3);
+        assert_eq!(sum, a.add(&b));
+
+        let mut difference = a_eval.sub(&b_eval).to_coeff();
+        difference.truncate(3);
+        assert_eq!(difference, a.sub(&b));
+
+        let mut product = a_eval.mul(&b_eval).to_coeff();
+        product.truncate(4);
+        assert_eq!(product, a.mul(&b));
+
+        let scaled = a_eval.scale(-Scalar::ONE);
+        assert_eq!(scaled.add(&a_eval).evaluations(), &[Scalar::ZERO; 8]);
+    }
+
+    #[test]
+    #[should_panic(expected = "different domains")]
+    fn rejects_mixing_domains() {
+        let small = Domain::new(4);
+        let large = Domain::new(8);
+        let a = EvalPoly::from_coeff(&small, poly(&[1]));
+        let b = EvalPoly::from_coeff(&large, poly(&[1]));
+        let _ = a.add(&b);
+    }
+}
@@ -1,4 +1,5 @@
 use std::{
+    cell::Cell,
     iter::successors,
     ops::{Add, AddAssign, Mul, Neg, Sub, SubAssign},
 };
@@ -19,16 +20,43 @@ pub(crate) trait FFTElement:
     + Mul<Scalar, Output = Self>
     + Neg<Output = Self>
 {
+    /// Minimum number of elements for which the FFT layers are split across threads.
+    ///
+    /// Below this size, the cost of scheduling work on the thread pool outweighs the
+    /// cost of the butterflies themselves. It depends on the element type, since a
+    /// butterfly over group elements is orders of magnitude more expensive than one
+    /// over field elements, and was chosen for each type with the FFT benchmarks of
+    /// this crate. When the `multithreaded` feature is disabled this has no effect.
+    const PARALLEL_THRESHOLD: usize;
+
+    /// Returns the threshold used by [`fft_inplace`], which is [`Self::PARALLEL_THRESHOLD`]
+    /// unless it can be tuned for the host.
+    fn parallel_threshold() -> usize {
+        Self::PARALLEL_THRESHOLD
+    }
+
     fn zero() -> Self;
 }
 
 impl FFTElement for Scalar {
+    // The 64-element FFTs used per cell are faster on the current thread, while the
+    // blob-sized ones are split across threads.
+    const PARALLEL_THRESHOLD: usize = 1 << 10;
+
+    fn parallel_threshold() -> usize {
+        parallel_fft_threshold()
+    }
+
     fn zero() -> Self {
         Self::ZERO
     }
 }
 
 impl FFTElement for G1Projective {
+    // Each butterfly is a scalar multiplication, so even the 128-element
+    // FFTs used in FK20 benefit from being split across threads.
+    const PARALLEL_THRESHOLD: usize = 1 << 4;
+
     fn zero() -> Self {
         Self::identity()
     }
@@ -47,37 +75,125 @@ pub(crate) fn fft_inplace<T: FFTElement>(
     omegas: &[Scalar],
     twiddle_factors_bo: &[Scalar],
     values: &mut [T],
+) {
+    let parallel = values.len() >= T::parallel_threshold();
+    fft_inplace_with(omegas, twiddle_factors_bo, values, parallel);
+}
+
+/// Computes the in-place FFT like [`fft_inplace`], splitting the layers across
+/// threads if and only if `parallel` is true.
+fn fft_inplace_with<T: FFTElement>(
+    omegas: &[Scalar],
+    twiddle_factors_bo: &[Scalar],
+    values: &mut [T],
+    parallel: bool,
 ) {
     let log_n = log2_pow2(values.len()) as usize;
     let mid = log_n.div_ceil(2);
 
     // The first half looks like a normal DIT.
     reverse_bit_order(values);
-    first_half(values, mid, omegas);
+    first_half(values, mid, omegas, parallel);
 
     // For the second half, we flip the DIT, working in bit-reversed order,
     // so the max block size will be at most `1 << (log_n - mid)`.
     reverse_bit_order(values);
-    second_half(values, mid, twiddle_factors_bo);
+    second_half(values, mid, twiddle_factors_bo, parallel);
 
     reverse_bit_order(values);
 }
 
+thread_local! {
+    /// The threshold used for field element FFTs on this thread, if it was tuned for the
+    /// host with [`set_thread_parallel_fft_threshold`].
+    static PARALLEL_FFT_THRESHOLD_OVERRIDE: Cell<Option<usize>> = const { Cell::new(None) };
+}
+
+/// Returns the minimum number of field elements for which the FFT layers are split across
+/// threads, for FFTs run on the current thread.
+pub fn parallel_fft_threshold() -> usize {
+    PARALLEL_FFT_THRESHOLD_OVERRIDE
+        .with(Cell::get)
+        .unwrap_or(<Scalar as FFTElement>::PARALLEL_THRESHOLD)
+}
+
+/// Sets the minimum number of field elements for which the FFT layers are split across
+/// threads, for FFTs run on the current thread, or restores the default with `None`.
+///
+/// This is meant to be called from the start handler of a thread pool, so that the FFTs
+/// run on that pool use a value from [`calibrate_parallel_fft_threshold`], while the rest
+/// of the process keeps the default, which was chosen on a desktop machine.
+pub fn set_thread_parallel_fft_threshold(threshold: Option<usize>) {
+    PARALLEL_FFT_THRESHOLD_OVERRIDE.with(|cell| cell.set(threshold));
+}
+
+/// Returns the smallest of `sizes` for which splitting the FFT layers across threads is
+/// faster than running them on the current thread, on this host and thread pool.
+///
+/// Each size is timed a few times, which takes a few milliseconds for blob-sized FFTs. If
+/// splitting is never faster, for example when the thread pool has a single thread,
+/// `usize::MAX` is returned.
+///
+/// This is not available on wasm32, where there is no clock to time the FFTs with.
+///
+/// # Panics
+/// Panics if a size is not a power of two.
+#[cfg(not(target_arch = "wasm32"))]
+pub fn calibrate_parallel_fft_threshold(sizes: &[usize]) -> usize {
+    use std::time::{Duration, Instant};
+
+    const RUNS: usize = 5;
+
+    let time = |omegas: &[Scalar], twiddles_bo: &[Scalar], values: &mut [Scalar], parallel| {
+        (0..RUNS)
+            .map(|_| {
+                let start = Instant::now();
+                fft_inplace_with(omegas, twiddles_bo, values, parallel);
+                start.elapsed()
+            })
+            .min()
+            .unwrap_or(Duration::MAX)
+    };
+
+    let mut sizes = sizes.to_vec();
+    sizes.sort_unstable();
+    for n in sizes {
+        assert!(n.is_power_of_two(), "FFT size {n} is not a power of two");
+        let omega = crate::domain::Domain::new(n).generator;
+        let omegas = precompute_omegas(&omega, n);
+        let twiddles_bo = precompute_twiddle_factors_bo(&omega, n);
+        let mut values: Vec<_> = (0..n as u64).map(Scalar::from).collect();
+
+        let sequential = time(&omegas, &twiddles_bo, &mut values, false);
+        let parallel = time(&omegas, &twiddles_bo, &mut values, true);
+        if parallel < sequential {
+            return n;
+        }
+    }
+    usize::MAX
+}
+
 /// Applies the first half of the FFT layers to `values` in-place.
 ///
 /// This step performs standard Radix-2 DIT layers up to `mid`.
-/// Each chunk of size `2^mid` is processed independently and potentially in parallel.
-#[allow(clippy::needless_range_loop)]
-fn first_half<T: FFTElement>(values: &mut [T], mid: usize, omegas: &[Scalar]) {
-    values.maybe_par_chunks_mut(1 << mid).for_each(|chunk| {
+/// Each chunk of size `2^mid` is processed independently and, if `parallel` is set, in parallel.
+fn first_half<T: FFTElement>(values: &mut [T], mid: usize, omegas: &[Scalar], parallel: bool) {
+    let process_chunk = |chunk: &mut [T]| {
         let mut backwards = false;
-        for layer in 0..mid {
+        for (layer, &omega) in omegas.iter().enumerate().take(mid) {
             let half_block_size = 1 << layer;
-            let omega = omegas[layer];
             dit_layer(chunk, half_block_size, omega, backwards);
             backwards = !backwards;
         }
-    });
+    };
+
+    if parallel {
+        values
+            .maybe_par_chunks_mut(1 << mid)
+            .for_each(process_chunk);
+    } else {
+        values.chunks_mut(1 << mid).for_each(process_chunk);
+    }
 }
 
 /// Applies a single DIT butterfly layer using multiplicative powers of `omega`.
@@ -113,20 +229,35 @@ fn dit_layer<T: FFTElement>(
 ///
 /// This step handles the layers from `mid` to `log_n`.
 /// Each chunk of `2^{log_n - mid}` elements uses a slice of `twiddles_bo` for butterflies.
-fn second_half<T: FFTElement>(values: &mut [T], mid: usize, twiddles_bo: &[Scalar]) {
+/// Chunks are processed in parallel if `parallel` is set.
+fn second_half<T: FFTElement>(
+    values: &mut [T],
+    mid: usize,
+    twiddles_bo: &[Scalar],
+    parallel: bool,
+) {
     let log_n = log2_pow2(values.len()) as usize;
-    values
-        .maybe_par_chunks_mut(1 << (log_n - mid))
-        .enumerate()
-        .for_each(|(chunk_idx, chunk)| {
-            let mut backwards = false;
-            for layer in mid..log_n {
-                let half_block_size = 1 << (log_n - 1 - layer);
-                let twiddles_bo = &twiddles_bo[chunk_idx << (layer - mid)..];
-                dit_layer_bo(chunk, half_block_size, twiddles_bo, backwards);
-                backwards = !backwards;
-            }
-        });
+    let process_chunk = |(chunk_idx, chunk): (usize, &mut [T])| {
+        let mut backwards = false;
+        for layer in mid..log_n {
+            let half_block_size = 1 << (log_n - 1 - layer);
+            let twiddles_bo = &twiddles_bo[chunk_idx << (layer - mid)..];
+            dit_layer_bo(chunk, half_block_size, twiddles_bo, backwards);
+            backwards = !backwards;
+        }
+    };
+
+    if parallel {
+        values
+            .maybe_par_chunks_mut(1 << (log_n - mid))
+            .enumerate()
+            .for_each(process_chunk);
+    } else {
+        values
+            .chunks_mut(1 << (log_n - mid))
+            .enumerate()
+            .for_each(process_chunk);
+    }
 }
 
 /// Applies a single DIT butterfly layer using externally provided twiddle factors.
@@ -393,6 +524,90 @@ mod tests {
         }
     }
 
+    #[test]
+    fn test_fft_agrees_above_and_below_parallel_threshold() {
+        // Check the FFT against a naive evaluation on both sides of the threshold
+        // so that the sequential and parallel code paths are exercised.
+        let threshold = <Scalar as FFTElement>::PARALLEL_THRESHOLD;
+        for n in [threshold / 2, threshold * 2] {
+            let omega = crate::domain::Domain::new(n).generator;
+            let coeffs: Vec<_> = (0..n as u64).map(Scalar::from).collect();
+
+            let mut values = coeffs.clone();
+            fft_inplace(
+                &precompute_omegas(&omega, n),
+                &precompute_twiddle_factors_bo(&omega, n),
+                &mut values,
+            );
+
+            for index in [0, 1, n / 3, n - 1] {
+                let point = omega.pow_vartime([index as u64]);
+                let expected = coeffs
+                    .iter()
+                    .rev()
+                    .fold(Scalar::ZERO, |acc, coeff| acc * point + coeff);
+                assert_eq!(values[index], expected, "mismatch at n={n}, index={index}");
+            }
+        }
+    }
+
+    #[test]
+    #[cfg(not(target_arch = "wasm32"))]
+    fn calibrated_threshold_is_one_of_the_sizes() {
+        let sizes = [1 << 10, 1 << 6];
+        let threshold = calibrate_parallel_fft_threshold(&sizes);
+        assert!(threshold == usize::MAX || sizes.contains(&threshold));
+    }
+
+    #[test]
+    #[cfg(not(target_arch = "wasm32"))]
+    fn thread_threshold_only_applies_to_the_current_thread() {
+        let default = <Scalar as FFTElement>::PARALLEL_THRESHOLD;
+        set_thread_parallel_fft_threshold(Some(1 << 6));
+        assert_eq!(parallel_fft_threshold(), 1 << 6);
+        assert_eq!(
+            std::thread::spawn(parallel_fft_threshold).join().unwrap(),
+            default
+        );
+
+        set_thread_parallel_fft_threshold(None);
+        assert_eq!(parallel_fft_threshold(), default);
+    }
+
+    #[test]
+    fn test_g1_fft_agrees_above_and_below_parallel_threshold() {
+        let threshold = <G1Projective as FFTElement>::PARALLEL_THRESHOLD;
+        for n in [threshold / 2, threshold * 2] {
+            let omega = crate::domain::Domain::new(n).generator;
+            let coeffs: Vec<_> = (1..=n as u64).map(Scalar::from).collect();
+
+            let mut points: Vec<_> = coeffs
+                .iter()
+                .map(|coeff| G1Projective::generator() * coeff)
+                .collect();
+            fft_inplace(
+                &precompute_omegas(&omega, n),
+                &precompute_twiddle_factors_bo(&omega, n),
+                &mut points,
+            );
+
+            let mut scalars = coeffs;
+            fft_inplace(
+                &precompute_omegas(&omega, n),
+                &precompute_twiddle_factors_bo(&omega, n),
+                &mut scalars,
+            );
+
+            for (point, scalar) in points.iter().zip(&scalars) {
+                assert_eq!(
+                    *point,
+                    G1Projective::generator() * scalar,
+                    "mismatch at n={n}"
+                );
+            }
+        }
+    }
+
     #[test]
     fn test_reverse_bit_order_empty_slice() {
         let mut arr: [u32; 0] = [];
@@ -1,6 +1,15 @@
-mod coset_fft;
+pub mod arithmetic;
+pub mod coset_fft;
 pub mod domain;
+pub mod eval_poly;
 mod fft;
+pub mod mixed_radix;
 pub mod poly_coeff;
+mod precomputed;
+pub mod roots_of_unity;
+pub mod vanishing;
 
-pub use coset_fft::CosetFFT;
+pub use coset_fft::{coset_fft, coset_ifft, CosetFFT};
+#[cfg(not(target_arch = "wasm32"))]
+pub use fft::calibrate_parallel_fft_threshold;
+pub use fft::{parallel_fft_threshold, set_thread_parallel_fft_threshold};
@@ -0,0 +1,262 @@
+//! FFTs over multiplicative subgroups whose order is not a power of two.
+//!
+//! [`Domain`](crate::domain::Domain) only supports power-of-two sizes. Some data-availability
+//! constructions (for example 3/4-rate codes) need evaluation domains of other sizes, which
+//! this module supports through a mixed-radix Cooley-Tukey FFT.
+//!
+//! A multiplicative subgroup of order `n` exists if and only if `n` divides `r - 1`, where `r`
+//! is the scalar field modulus. For BLS12-381:
+//!
+//! ```text
+//! r - 1 = 2^32 · 3 · 11 · 19 · 10177 · 125527 · 859267 · 906349^2 · 2508409 · 2529403 · 52437899 · 254760293^2
+//! ```
+//!
+//! so the supported radices are 2, 3, 11 and 19. In particular there is no radix-5 domain in
+//! this field.
+use bls12_381::{traits::*, Scalar};
+
+use crate::poly_coeff::PolyCoeff;
+
+/// `r - 1` as little-endian 64-bit limbs, where `r` is the scalar field modulus.
+const MODULUS_MINUS_ONE: [u64; 4] = [
+    0xffff_ffff_0000_0000,
+    0x53bd_a402_fffe_5bfe,
+    0x3339_d808_09a1_d805,
+    0x73ed_a753_299d_7d48,
+];
+
+/// The primes that we allow as a radix, the prime factors of `r - 1` up to 19.
+///
+/// Each stage of the FFT performs a naive DFT of size `radix`, so allowing the larger
+/// prime factors of `r - 1` would make the transform quadratic in practice. A size with any
+/// other factor, such as 5, has no subgroup of that order and is rejected.
+const RADICES: [u64; 4] = [2, 3, 11, 19];
+
+/// An evaluation domain of arbitrary (supported) size.
+#[derive(Debug, Clone)]
+pub struct MixedRadixDomain {
+    /// The number of elements in the domain.
+    size: usize,
+    /// The prime factors of the size, in the order in which the FFT peels them off.
+    radices: Vec<usize>,
+    /// Generator of the subgroup, an element of order `size`.
+    generator: Scalar,
+    /// Inverse of the generator, used for the inverse FFT.
+    generator_inv: Scalar,
+    /// Inverse of the size of the domain, used for the inverse FFT.
+    size_inv: Scalar,
+}
+
+impl MixedRadixDomain {
+    /// Creates a domain of exactly `size` elements.
+    ///
+    /// Returns `None` if there is no subgroup of that order, or if its order has a prime
+    /// factor larger than the largest supported radix.
+    pub fn new(size: usize) -> Option<Self> {
+        if size == 0 {
+            return None;
+        }
+
+        let radices = factor_into_radices(size as u64)?
+            .into_iter()
+            .map(|radix| radix as usize)
+            .collect();
+
+        let (cofactor, remainder) = div_rem_limbs(MODULUS_MINUS_ONE, size as u64);
+        if remainder != 0 {
+            return None;
+        }
+        let generator = Scalar::MULTIPLICATIVE_GENERATOR.pow_vartime(cofactor);
+        let generator_inv = generator.invert().expect("generator should not be zero");
+        let size_inv = Scalar::from(size as u64)
+            .invert()
+            .expect("size should not be zero");
+
+        Some(Self {
+            size,
+            radices,
+            generator,
+            generator_inv,
+            size_inv,
+        })
+    }
+
+    /// Returns the number of elements in the domain.
+    pub const fn size(&self) -> usize {
+        self.size
+    }
+
+    /// Returns the generator of the domain, an element of order [`MixedRadixDomain::size`].
+    pub const fn generator(&self) -> Scalar {
+        self.generator
+    }
+
+    /// Returns the elements of the domain, `[ω^0, ω^1, ..., ω^(n-1)]`.
+    pub fn roots(&self) -> Vec<Scalar> {
+        std::iter::successors(Some(Scalar::ONE), |root| Some(root * self.generator))
+            .take(self.size)
+            .collect()
+    }
+
+    /// Evaluates a polynomial at the points in the domain.
+    ///
+    /// # Panics
+    /// Panics if the polynomial has more coefficients than there are elements in the domain.
+    pub fn fft_scalars(&self, mut polynomial: PolyCoeff) -> Vec<Scalar> {
+        assert!(
+            polynomial.len() <= self.size,
+            "polynomial has more coefficients than the domain size"
+        );
+        polynomial.resize(self.size, Scalar::ZERO);
+
+        mixed_radix_fft(&polynomial, self.generator, &self.radices)
+    }
+
+    /// Interpolates the points over the domain to get a polynomial in monomial form.
+    ///
+    /// # Panics
+    /// Panics if there are more evaluations than there are elements in the domain.
+    pub fn ifft_scalars(&self, mut evaluations: Vec<Scalar>) -> PolyCoeff {
+        assert!(
+            evaluations.len() <= self.size,
+            "more evaluations than the domain size"
+        );
+        evaluations.resize(self.size, Scalar::ZERO);
+
+        let mut coefficients = mixed_radix_fft(&evaluations, self.generator_inv, &self.radices);
+        for coeff in &mut coefficients {
+            *coeff *= self.size_inv;
+        }
+        coefficients.into()
+    }
+}
+
+/// Recursive decimation-in-time FFT.
+///
+/// Splits `values` into `p` interleaved subsequences, where `p = radices[0]`, transforms
+/// each one over the subgroup generated by `omega^p`, and recombines them with a naive
+/// `p`-point DFT.
+fn mixed_radix_fft(values: &[Scalar], omega: Scalar, radices: &[usize]) -> Vec<Scalar> {
+    let n = values.len();
+    let Some((&radix, rest)) = radices.split_first() else {
+        debug_assert_eq!(n, 1);
+        return values.to_vec();
+    };
+    let sub_size = n / radix;
+
+    let omega_pow_radix = omega.pow_vartime([radix as u64]);
+    let sub_ffts: Vec<Vec<Scalar>> = (0..radix)
+        .map(|offset| {
+            let subsequence: Vec<_> = values.iter().skip(offset).step_by(radix).copied().collect();
+            mixed_radix_fft(&subsequence, omega_pow_radix, rest)
+        })
+        .collect();
+
+    // X[k] = Σ_r ω^(r·k) · Y_r[k mod sub_size]
+    let mut result = Vec::with_capacity(n);
+    let mut omega_pow_k = Scalar::ONE;
+    for k in 0..n {
+        let mut acc = Scalar::ZERO;
+        let mut twiddle = Scalar::ONE;
+        for sub_fft in &sub_ffts {
+            acc += twiddle * sub_fft[k % sub_size];
+            twiddle *= omega_pow_k;
+        }
+        result.push(acc);
+        omega_pow_k *= omega;
+    }
+    result
+}
+
+/// Factors `n` into primes from [`RADICES`], smallest first.
+///
+/// Returns `None` if `n` has any other prime factor.
+fn factor_into_radices(mut n: u64) -> Option<Vec<u64>> {
+    let mut radices = Vec::new();
+    for prime in RADICES {
+        while n % prime == 0 {
+            radices.push(prime);
+            n /= prime;
+        }
+    }
+    (n == 1).then_some(radices)
+}
+
+/// Divides a little-endian multi-limb integer by a `u64`, returning the quotient and remainder.
+fn div_rem_limbs(limbs: [u64; 4], divisor: u64) -> ([u64; 4], u64) {
+    let mut quotient = [0u64; 4];
+    let mut remainder = 0u128;
+    for i in (0..4).rev() {
+        let current = (remainder << 64) | u128::from(limbs[i]);
+        quotient[i] = (current / u128::from(divisor)) as u64;
+        remainder = current % u128::from(divisor);
+    }
+    (quotient, remainder as u64)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::domain::Domain;
+
+    #[test]
+    fn rejects_unsupported_sizes() {
+        assert!(MixedRadixDomain::new(0).is_none());
+        // 5 and 7 do not divide r - 1
+        assert!(MixedRadixDomain::new(5).is_none());
+        assert!(MixedRadixDomain::new(7).is_none());
+        // 3 divides r - 1 only once
+        assert!(MixedRadixDomain::new(9).is_none());
+    }
+
+    #[test]
+    fn radices_divide_the_group_order() {
+        for radix in RADICES {
+            let (_, remainder) = div_rem_limbs(MODULUS_MINUS_ONE, radix);
+            assert_eq!(remainder, 0, "{radix} does not divide r - 1");
+        }
+    }
+
+    #[test]
+    fn generator_has_exact_order() {
+        for size in [3, 6, 12, 33, 57, 96] {
+            let domain = MixedRadixDomain::new(size).unwrap();
+            let generator = domain.generator();
+            assert_eq!(generator.pow_vartime([size as u64]), Scalar::ONE);
+            for prime in RADICES {
+                if size as u64 % prime == 0 {
+                    assert_ne!(generator.pow_vartime([size as u64 / prime]), Scalar::ONE);
+                }
+            }
+        }
+    }
+
+    #[test]
+    fn fft_matches_naive_evaluation() {
+        for size in [1, 3, 6, 11, 24, 38, 66] {
+            let domain = MixedRadixDomain::new(size).unwrap();
+            let polynomial = PolyCoeff((0..size as u64).map(|i| Scalar::from(i + 1)).collect());
+
+            let evaluations = domain.fft_scalars(polynomial.clone());
+            for (root, evaluation) in domain.roots().iter().zip(&evaluations) {
+                assert_eq!(polynomial.eval(root), *evaluation);
+            }
+
+            assert_eq!(domain.ifft_scalars(evaluations), polynomial);
+        }
+    }
+
+    #[test]
+    fn matches_power_of_two_domain() {
+        let size = 32;
+        let domain = MixedRadixDomain::new(size).unwrap();
+        let radix_2_domain = Domain::new(size);
+        let polynomial = PolyCoeff((0..size as u64).map(Scalar::from).collect());
+
+        assert_eq!(domain.generator(), radix_2_domain.generator);
+        assert_eq!(
+            domain.fft_scalars(polynomial.clone()),
+            radix_2_domain.fft_scalars(polynomial)
+        );
+    }
+}
@@ -0,0 +1,72 @@
+//! Roots of unity generated at build time.
+//!
+//! `build.rs` writes the powers of a primitive `2^PRECOMPUTED_LOG_SIZE`'th root of unity,
+//! in Montgomery form. The roots of a domain of size `n` are every `2^PRECOMPUTED_LOG_SIZE / n`'th
+//! entry of this table, and the inverse roots are the same entries in reverse order, so
+//! every table that a [`Domain`](crate::domain::Domain) needs can be copied out of it.
+use bls12_381::{scalar_from_montgomery_limbs, Scalar};
+
+use crate::fft::reverse_bit_order;
+
+/// log2 of the largest domain that can be created from the precomputed roots.
+///
+/// This must match `PRECOMPUTED_LOG_SIZE` in `build.rs`.
+pub(crate) const PRECOMPUTED_LOG_SIZE: u32 = 13;
+
+/// The powers of a primitive `2^PRECOMPUTED_LOG_SIZE`'th root of unity, in Montgomery form.
+static ROOTS_OF_UNITY: [[u64; 4]; 1 << PRECOMPUTED_LOG_SIZE] =
+    include!(concat!(env!("OUT_DIR"), "/roots_of_unity.rs"));
+
+/// The roots of unity for a power-of-two domain, taken from the precomputed table.
+pub(crate) struct PrecomputedRoots {
+    /// The number of roots.
+    size: usize,
+    /// The distance between consecutive roots of this domain in the table.
+    stride: usize,
+}
+
+impl PrecomputedRoots {
+    /// Returns the precomputed roots for a domain of `size` elements, or `None` if the
+    /// domain is larger than the table.
+    pub(crate) fn new(size: usize) -> Option<Self> {
+        debug_assert!(size.is_power_of_two());
+        let max_size = ROOTS_OF_UNITY.len();
+        (size <= max_size).then(|| Self {
+            size,
+            stride: max_size / size,
+        })
+    }
+
+    /// Returns `ω^exponent`, where `ω` is the primitive root of unity of this domain,
+    /// or `ω^-exponent` if `inverse` is set.
+    fn power(&self, exponent: usize, inverse: bool) -> Scalar {
+        let exponent = exponent % self.size;
+        let exponent = if inverse {
+            (self.size - exponent) % self.size
+        } else {
+            exponent
+        };
+        scalar_from_montgomery_limbs(ROOTS_OF_UNITY[exponent * self.stride])
+    }
+
+    /// Returns every root of unity of the domain, ie `[ω^0, ω^1, ..., ω^{n-1}]`.
+    pub(crate) fn roots(&self) -> Vec<Scalar> {
+        (0..self.size).map(|i| self.power(i, false)).collect()
+    }
+
+    /// Returns the same values as `precompute_omegas`, for `ω` or `ω^-1` if `inverse` is set.
+    pub(crate) fn omegas(&self, inverse: bool) -> Vec<Scalar> {
+        let log_n = self.size.trailing_zeros();
+        (0..log_n)
+            .map(|s| self.power(self.size >> (s + 1), inverse))
+            .collect()
+    }
+
+    /// Returns the same values as `precompute_twiddle_factors_bo`, for `ω` or `ω^-1` if `inverse` is set.
+    pub(crate) fn twiddle_factors_bo(&self, inverse: bool) -> Vec<Scalar> {
+        let mut twiddle_factors: Vec<_> =
+            (0..self.size / 2).map(|i| self.power(i, inverse)).collect();
+        reverse_bit_order(&mut twiddle_factors);
+        twiddle_factors
+    }
+}
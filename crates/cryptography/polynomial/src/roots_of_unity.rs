@@ -0,0 +1,159 @@
+//! Lazily computed roots of unity.
+//!
+//! [`Domain`](crate::domain::Domain) eagerly materializes every root together with the
+//! FFT twiddle tables, which is the right trade-off for the fixed sizes used by EIP-4844
+//! and EIP-7594. For experiments with larger blobs or deeper extensions, allocating all
+//! of that up front quickly becomes the bottleneck, so this module exposes the roots of
+//! unity for any supported size and only computes the powers that are actually requested.
+use bls12_381::{traits::*, Scalar};
+
+/// Returns a primitive `2^log_size`'th root of unity.
+///
+/// # Panics
+/// Panics if `log_size` is larger than the two-adicity of the scalar field.
+pub fn generator_for_log_size(log_size: u32) -> Scalar {
+    assert!(
+        log_size <= Scalar::S,
+        "two adicity is {} but group size needed is 2^{log_size}",
+        Scalar::S
+    );
+
+    // Squaring the largest root of unity halves its order, so we square
+    // `S - log_size` times to get an element of order `2^log_size`.
+    let mut generator = Scalar::ROOT_OF_UNITY;
+    for _ in log_size..Scalar::S {
+        generator = generator.square();
+    }
+    generator
+}
+
+/// The roots of unity of a given power-of-two order, computed on demand.
+///
+/// Powers are cached as they are computed, so sequential access is a single multiplication
+/// per new root, while random access into the uncached region falls back to exponentiation.
+#[derive(Debug, Clone)]
+pub struct RootsOfUnity {
+    /// The number of roots, always a power of two.
+    size: u64,
+    /// Primitive root of unity of order `size`.
+    generator: Scalar,
+    /// `generator^0, generator^1, ...` for every index computed so far.
+    cache: Vec<Scalar>,
+}
+
+impl RootsOfUnity {
+    /// Creates the roots of unity of order `size`, padded to the next power of two.
+    ///
+    /// Returns `None` if the padded size is larger than the scalar field supports.
+    /// No roots are computed until they are requested.
+    pub fn new(size: u64) -> Option<Self> {
+        let size = size.max(1).checked_next_power_of_two()?;
+        let log_size = size.trailing_zeros();
+        if log_size > Scalar::S {
+            return None;
+        }
+
+        Some(Self {
+            size,
+            generator: generator_for_log_size(log_size),
+            cache: vec![Scalar::ONE],
+        })
+    }
+
+    /// Returns the number of roots of unity.
+    pub const fn size(&self) -> u64 {
+        self.size
+    }
+
+    /// Returns the primitive root of unity that generates the group.
+    pub const fn generator(&self) -> Scalar {
+        self.generator
+    }
+
+    /// Returns the `index`'th root of unity without extending the cache.
+    ///
+    /// Indices are taken modulo the size of the group.
+    pub fn get(&self, index: u64) -> Scalar {
+        let index = index % self.size;
+        usize::try_from(index)
+            .ok()
+            .and_then(|i| self.cache.get(i).copied())
+            .unwrap_or_else(|| self.generator.pow_vartime([index]))
+    }
+
+    /// Returns the first `n` roots of unity, extending the cache as needed.
+    ///
+    /// # Panics
+    /// Panics if `n` is larger than the size of the group.
+    pub fn first_n(&mut self, n: usize) -> &[Scalar] {
+        assert!(
+            n as u64 <= self.size,
+            "requested {n} roots but the group only has {}",
+            self.size
+        );
+
+        self.cache.reserve(n.saturating_sub(self.cache.len()));
+        while self.cache.len() < n {
+            let last = *self
+                .cache
+                .last()
+                .expect("cache always contains the first root");
+            self.cache.push(last * self.generator);
+        }
+        &self.cache[..n]
+    }
+
+    /// Returns an iterator over all of the roots of unity in order.
+    ///
+    /// The iterator computes each root from the previous one and does not touch the cache.
+    pub fn iter(&self) -> impl Iterator<Item = Scalar> + '_ {
+        std::iter::successors(Some(Scalar::ONE), |root| Some(root * self.generator))
+            .take(usize::try_from(self.size).unwrap_or(usize::MAX))
+    }
+
+    /// Returns the base two logarithm of the size of the group.
+    pub const fn log_size(&self) -> u32 {
+        self.size.trailing_zeros()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::domain::Domain;
+
+    #[test]
+    fn matches_eager_domain_roots() {
+        let domain = Domain::new(64);
+        let mut roots = RootsOfUnity::new(64).unwrap();
+
+        assert_eq!(roots.generator(), domain.generator);
+        assert_eq!(roots.first_n(64), domain.roots.as_slice());
+        for (i, root) in domain.roots.iter().enumerate() {
+            assert_eq!(roots.get(i as u64), *root);
+        }
+        assert_eq!(roots.iter().collect::<Vec<_>>(), domain.roots);
+    }
+
+    #[test]
+    fn uncached_access_matches_cached_access() {
+        let mut cached = RootsOfUnity::new(1 << 10).unwrap();
+        let uncached = cached.clone();
+
+        let expected = cached.first_n(1 << 10).to_vec();
+        for (i, root) in expected.iter().enumerate().step_by(37) {
+            assert_eq!(uncached.get(i as u64), *root);
+        }
+        assert_eq!(uncached.get(1 << 10), Scalar::ONE);
+    }
+
+    #[test]
+    fn supports_largest_domain_without_allocating_it() {
+        let roots = RootsOfUnity::new(1 << Scalar::S).unwrap();
+        assert_eq!(roots.log_size(), Scalar::S);
+        assert_eq!(roots.generator(), Scalar::ROOT_OF_UNITY);
+        assert_eq!(roots.get(1 << 31), -Scalar::ONE);
+
+        assert!(RootsOfUnity::new((1 << Scalar::S) + 1).is_none());
+    }
+}
@@ -0,0 +1,196 @@
+//! Vanishing polynomials over structured sets of missing indices.
+//!
+//! Recovery in EIP-7594 has to divide out a polynomial `Z(X)` that is zero on every
+//! missing evaluation. Missing data comes in whole cells, which correspond to the same
+//! position in every block of the evaluation domain, so `Z(X)` can be built from a
+//! polynomial over the (much smaller) block domain instead of multiplying one linear
+//! factor per missing evaluation.
+use bls12_381::{traits::*, Scalar};
+
+use crate::{
+    coset_fft::CosetFFT,
+    domain::Domain,
+    poly_coeff::{vanishing_poly, PolyCoeff},
+    roots_of_unity::generator_for_log_size,
+};
+
+/// Errors that can occur when the block indices of a vanishing polynomial are invalid.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum BlockIndexError {
+    /// A block index is not less than the block size.
+    OutOfRange {
+        /// The offending block index.
+        block_index: usize,
+        /// The size of each block, used as the upper bound.
+        block_size: usize,
+    },
+    /// A block index appears more than once.
+    Repeated {
+        /// The block index that was repeated.
+        block_index: usize,
+    },
+}
+
+/// Computes the polynomial that vanishes on every element of a domain of size `domain_size`
+/// whose index `i` satisfies `i % block_size ∈ block_indices`.
+///
+/// Viewing the evaluations over the domain as `domain_size / block_size` contiguous blocks of
+/// `block_size` elements, the result is zero at the positions `block_indices` of every block.
+///
+/// Let `ω_B` be the generator of the domain of size `block_size` and `m = domain_size / block_size`.
+/// The result is `Z(X) = Π_b (X^m - ω_B^b)`, which has `block_indices.len() * m + 1` coefficients.
+///
+/// Returns an error if any block index is out of range or repeated.
+///
+/// # Panics
+/// Panics if `domain_size` or `block_size` is not a power of two, or if `block_size > domain_size`.
+pub fn vanishing_poly_for_block_indices(
+    domain_size: usize,
+    block_size: usize,
+    block_indices: &[usize],
+) -> Result<PolyCoeff, BlockIndexError> {
+    assert!(
+        domain_size.is_power_of_two() && block_size.is_power_of_two(),
+        "domain and block sizes must be powers of two"
+    );
+    assert!(
+        block_size <= domain_size,
+        "block size must not exceed the domain size"
+    );
+
+    let mut seen = vec![false; block_size];
+    for &block_index in block_indices {
+        if block_index >= block_size {
+            return Err(BlockIndexError::OutOfRange {
+                block_index,
+                block_size,
+            });
+        }
+        if seen[block_index] {
+            return Err(BlockIndexError::Repeated { block_index });
+        }
+        seen[block_index] = true;
+    }
+
+    // Compute the polynomial that vanishes on the block indices in the first block,
+    // ie over the domain of size `block_size`.
+    let block_generator = generator_for_log_size(block_size.trailing_zeros());
+    let roots: Vec<_> = block_indices
+        .iter()
+        .map(|&index| block_generator.pow_vartime([index as u64]))
+        .collect();
+    let first_block = vanishing_poly(&roots);
+
+    // Substitute `X -> X^m`, so that it vanishes at the same indices in every block.
+    let num_blocks = domain_size / block_size;
+    let mut z_x = vec![Scalar::ZERO; (first_block.len() - 1) * num_blocks + 1];
+    for (i, coeff) in first_block.0.into_iter().enumerate() {
+        z_x[i * num_blocks] = coeff;
+    }
+
+    Ok(z_x.into())
+}
+
+/// Evaluates the vanishing polynomial returned by [`vanishing_poly_for_block_indices`]
+/// over the coset `g·H` of `domain`.
+///
+/// Since the coset does not intersect the domain, none of the evaluations are zero, which
+/// makes them suitable for dividing by `Z(X)` pointwise.
+///
+/// Returns an error for the same reasons as [`vanishing_poly_for_block_indices`].
+///
+/// # Panics
+/// Panics for the same reasons as [`vanishing_poly_for_block_indices`], or if every index
+/// in a block is missing (the polynomial would then not fit in the domain).
+pub fn vanishing_poly_coset_evaluations(
+    domain: &Domain,
+    block_size: usize,
+    block_indices: &[usize],
+    coset: &CosetFFT,
+) -> Result<Vec<Scalar>, BlockIndexError> {
+    let domain_size = domain.roots.len();
+    assert!(
+        block_indices.len() < block_size,
+        "cannot evaluate the vanishing polynomial when all of the block indices are missing"
+    );
+
+    let z_x = vanishing_poly_for_block_indices(domain_size, block_size, block_indices)?;
+    Ok(domain.coset_fft_scalars(z_x, coset))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn vanishes_on_block_indices() {
+        const DOMAIN_SIZE: usize = 64;
+        const BLOCK_SIZE: usize = 8;
+        let block_indices = [1, 4, 7];
+
+        let domain = Domain::new(DOMAIN_SIZE);
+        let z_x =
+            vanishing_poly_for_block_indices(DOMAIN_SIZE, BLOCK_SIZE, &block_indices).unwrap();
+        assert_eq!(
+            z_x.len(),
+            block_indices.len() * DOMAIN_SIZE / BLOCK_SIZE + 1
+        );
+
+        let evaluations = domain.fft_scalars(z_x);
+        for (i, evaluation) in evaluations.iter().enumerate() {
+            let is_missing = block_indices.contains(&(i % BLOCK_SIZE));
+            assert_eq!(bool::from(evaluation.is_zero()), is_missing, "index {i}");
+        }
+    }
+
+    #[test]
+    fn matches_product_of_linear_factors() {
+        const DOMAIN_SIZE: usize = 16;
+        const BLOCK_SIZE: usize = 4;
+        let block_indices = [0, 3];
+
+        let domain = Domain::new(DOMAIN_SIZE);
+        let roots: Vec<_> = (0..DOMAIN_SIZE)
+            .filter(|i| block_indices.contains(&(i % BLOCK_SIZE)))
+            .map(|i| domain.roots[i])
+            .collect();
+
+        assert_eq!(
+            vanishing_poly_for_block_indices(DOMAIN_SIZE, BLOCK_SIZE, &block_indices),
+            Ok(vanishing_poly(&roots))
+        );
+    }
+
+    #[test]
+    fn no_missing_indices_is_constant_one() {
+        assert_eq!(
+            vanishing_poly_for_block_indices(32, 4, &[]),
+            Ok(PolyCoeff(vec![Scalar::ONE]))
+        );
+    }
+
+    #[test]
+    fn coset_evaluations_are_non_zero() {
+        let domain = Domain::new(32);
+        let coset = CosetFFT::new(Scalar::MULTIPLICATIVE_GENERATOR);
+        let evaluations = vanishing_poly_coset_evaluations(&domain, 4, &[0, 2, 3], &coset).unwrap();
+
+        assert_eq!(evaluations.len(), 32);
+        assert!(evaluations.iter().all(|eval| !bool::from(eval.is_zero())));
+    }
+
+    #[test]
+    fn rejects_invalid_indices() {
+        assert_eq!(
+            vanishing_poly_for_block_indices(32, 4, &[1, 1]),
+            Err(BlockIndexError::Repeated { block_index: 1 })
+        );
+        assert_eq!(
+            vanishing_poly_for_block_indices(32, 4, &[0, 4]),
+            Err(BlockIndexError::OutOfRange {
+                block_index: 4,
+                block_size: 4
+            })
+        );
+    }
+}
@@ -0,0 +1,127 @@
+use bls12_381::{lincomb::g1_lincomb, traits::*};
+use serialization::{deserialize_blob_to_scalars, serialize_g1_compressed};
+
+use crate::{
+    verifier::{blob_scalar_to_polynomial, compute_fiat_shamir_challenge},
+    BlobRef, Bytes48Ref, Context, Error, KZGCommitment, KZGProof,
+};
+
+/// What [`Context::consistency_check`] found when it compared a commitment and a blob proof
+/// with the ones computed from their blob.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct ConsistencyReport {
+    /// The commitment to the blob.
+    pub expected_commitment: KZGCommitment,
+    /// The blob proof for the blob and `expected_commitment`.
+    pub expected_proof: KZGProof,
+    /// Whether the given commitment is `expected_commitment`.
+    pub commitment_matches: bool,
+    /// Whether the given proof is `expected_proof`.
+    pub proof_matches: bool,
+}
+
+impl ConsistencyReport {
+    /// Returns true if both the commitment and the proof match.
+    pub const fn is_consistent(&self) -> bool {
+        self.commitment_matches && self.proof_matches
+    }
+}
+
+impl Context {
+    /// Recomputes the commitment and the blob proof of `blob`, and reports which of
+    /// `commitment` and `proof` differ from them.
+    ///
+    /// This is meant for block builders that check the blobs of a third-party bundle before
+    /// signing it: unlike [`Context::verify_blob_kzg_proof`], which only says that the proof
+    /// does not verify, it says which artifact is wrong and what it should be.
+    ///
+    /// The artifacts are compared by their bytes, so they do not need to be valid points.
+    /// The proof is compared with the proof for the expected commitment, so when the
+    /// commitment is wrong the proof is usually reported as wrong too, even if it verifies
+    /// against the wrong commitment.
+    ///
+    /// Only returns an error if the blob is not made of canonical field elements.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn consistency_check(
+        &self,
+        blob: BlobRef,
+        commitment: Bytes48Ref,
+        proof: Bytes48Ref,
+    ) -> Result<ConsistencyReport, Error> {
+        // Deserialize the blob into scalars.
+        let blob_scalar = deserialize_blob_to_scalars(blob)?;
+
+        // Convert blob into monomial form, once for both the commitment and the proof.
+        let polynomial = blob_scalar_to_polynomial(&self.prover.domain, &blob_scalar);
+
+        let expected_commitment = serialize_g1_compressed(
+            &g1_lincomb(&self.prover.commit_key.g1s, &polynomial)
+                .expect("commit_key.g1s.len() == polynomial.len()")
+                .to_affine(),
+        );
+
+        let z = compute_fiat_shamir_challenge(blob, expected_commitment);
+        let (expected_proof, _) = self.prover.compute_kzg_proof(&polynomial, z);
+        let expected_proof = serialize_g1_compressed(&expected_proof);
+
+        Ok(ConsistencyReport {
+            expected_commitment,
+            expected_proof,
+            commitment_matches: *commitment == expected_commitment,
+            proof_matches: *proof == expected_proof,
+        })
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use serialization::constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT};
+
+    use crate::Context;
+
+    fn blob() -> Box<[u8; BYTES_PER_BLOB]> {
+        let mut blob = Box::new([0u8; BYTES_PER_BLOB]);
+        for (i, element) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            element[BYTES_PER_FIELD_ELEMENT - 8..].copy_from_slice(&(i as u64).to_be_bytes());
+        }
+        blob
+    }
+
+    #[test]
+    fn mismatched_artifacts_are_reported() {
+        let ctx = Context::default();
+        let blob = blob();
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let proof = ctx.compute_blob_kzg_proof(&blob, &commitment).unwrap();
+
+        let report = ctx.consistency_check(&blob, &commitment, &proof).unwrap();
+        assert!(report.is_consistent());
+        assert_eq!(report.expected_commitment, commitment);
+        assert_eq!(report.expected_proof, proof);
+
+        // The proof of another blob
+        let mut other = blob.clone();
+        other[BYTES_PER_FIELD_ELEMENT - 1] ^= 1;
+        let other_commitment = ctx.blob_to_kzg_commitment(&other).unwrap();
+        let other_proof = ctx
+            .compute_blob_kzg_proof(&other, &other_commitment)
+            .unwrap();
+        let report = ctx
+            .consistency_check(&blob, &commitment, &other_proof)
+            .unwrap();
+        assert!(report.commitment_matches);
+        assert!(!report.proof_matches);
+
+        // The commitment is not even a point
+        let report = ctx.consistency_check(&blob, &[0xff; 48], &proof).unwrap();
+        assert!(!report.commitment_matches);
+        assert!(report.proof_matches);
+        assert!(!report.is_consistent());
+
+        let mut non_canonical = blob;
+        non_canonical[..BYTES_PER_FIELD_ELEMENT].fill(0xff);
+        assert!(ctx
+            .consistency_check(&non_canonical, &commitment, &proof)
+            .is_err());
+    }
+}
@@ -1,17 +1,39 @@
+mod consistency;
 mod errors;
+mod payload;
+mod precompile;
 mod prover;
 mod trusted_setup;
 mod verifier;
 
 /// Re-exported types
+pub use consistency::ConsistencyReport;
 pub use errors::{Error, SerializationError, VerifierError};
+pub use payload::{
+    blobs_needed, decode_payload, encode_payload, kzg_to_versioned_hash, PayloadBlobs,
+    PayloadError, BYTES_OF_DATA_PER_BLOB, BYTES_OF_DATA_PER_FIELD_ELEMENT,
+    VERSIONED_HASH_VERSION_KZG,
+};
+pub use precompile::{
+    point_evaluation_input, point_evaluation_output, PrecompileError, BLS_MODULUS,
+    POINT_EVALUATION_INPUT_BYTES, POINT_EVALUATION_OUTPUT_BYTES,
+    PRECOMPILE_FIELD_ELEMENTS_PER_BLOB,
+};
 pub use serialization::{constants, types::*};
 pub use trusted_setup::TrustedSetup;
 
 #[rustfmt::skip]
 // Note: adding rustfmt::skip so that `cargo fmt` does not mix the
 // public re-exported types with the following private imports.
-use kzg_single_open::{prover::Prover, verifier::Verifier};
+use std::sync::{Arc, OnceLock};
+
+use bls12_381::{g1_batch_normalize, G1Point, G1Projective, G2Point};
+use kzg_single_open::{
+    bitreverse_slice,
+    prover::{CommitKey, Prover},
+    verifier::Verifier,
+};
+use polynomial::domain::Domain;
 use serialization::constants::FIELD_ELEMENTS_PER_BLOB;
 use trusted_setup::{commit_key_from_setup, verification_key_from_setup};
 
@@ -19,8 +41,11 @@ use trusted_setup::{commit_key_from_setup, verification_key_from_setup};
 pub struct Context {
     prover: Prover,
     verifier: Verifier,
+    /// The commitments to the lagrange basis polynomials, computed on first use.
+    g1_lagrange: OnceLock<Vec<G1Point>>,
 }
 
+#[cfg(not(feature = "no-embedded-setup"))]
 impl Default for Context {
     fn default() -> Self {
         let trusted_setup = TrustedSetup::default();
@@ -30,16 +55,119 @@ impl Default for Context {
 }
 
 impl Context {
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     pub fn new(trusted_setup: &TrustedSetup) -> Self {
+        Self::from_commit_key(trusted_setup, commit_key_from_setup(trusted_setup))
+    }
+
+    /// Creates a context that commits with `g1_monomial`, instead of copying the G1 points
+    /// of the setup.
+    ///
+    /// This lets a context that also proves cells share the points with this one. The points
+    /// must be the first `FIELD_ELEMENTS_PER_BLOB` G1 points of `trusted_setup`.
+    ///
+    /// # Panics
+    /// Panics if there are not `FIELD_ELEMENTS_PER_BLOB` points.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn with_g1_monomial_points(
+        trusted_setup: &TrustedSetup,
+        g1_monomial: Arc<[G1Point]>,
+    ) -> Self {
+        assert_eq!(
+            g1_monomial.len(),
+            FIELD_ELEMENTS_PER_BLOB,
+            "a blob is committed to with one G1 point per field element"
+        );
+        Self::from_commit_key(trusted_setup, CommitKey::new(g1_monomial))
+    }
+
+    fn from_commit_key(trusted_setup: &TrustedSetup, commit_key: CommitKey) -> Self {
+        // The prover and verifier evaluate blobs over the same domain.
+        let domain = Arc::new(Domain::new(FIELD_ELEMENTS_PER_BLOB));
         Self {
-            prover: Prover::new(
-                FIELD_ELEMENTS_PER_BLOB,
-                commit_key_from_setup(trusted_setup),
-            ),
-            verifier: Verifier::new(
-                FIELD_ELEMENTS_PER_BLOB,
-                verification_key_from_setup(trusted_setup),
-            ),
+            prover: Prover::with_domain(domain.clone(), commit_key),
+            verifier: Verifier::with_domain(domain, verification_key_from_setup(trusted_setup)),
+            g1_lagrange: OnceLock::new(),
+        }
+    }
+
+    /// Returns the G1 points that blobs are committed with, which may be shared with other contexts.
+    pub const fn shared_g1_monomial_points(&self) -> &Arc<[G1Point]> {
+        &self.prover.commit_key.g1s
+    }
+
+    /// Returns the G1 points of the trusted setup in monomial form, ie `[tau^i]_1`.
+    pub fn g1_monomial_points(&self) -> &[G1Point] {
+        &self.prover.commit_key.g1s
+    }
+
+    /// Returns the G1 points of the trusted setup in lagrange form, ie `[L_i(tau)]_1`.
+    ///
+    /// The points are in bit-reversed order, matching the `g1_lagrange` points of the
+    /// trusted setup file, so committing to a blob is a linear combination of its field
+    /// elements with these points. They are computed with an IFFT on first use.
+    pub fn g1_lagrange_points(&self) -> &[G1Point] {
+        self.g1_lagrange.get_or_init(|| {
+            let monomial: Vec<_> = self
+                .g1_monomial_points()
+                .iter()
+                .map(G1Projective::from)
+                .collect();
+            let mut lagrange = self.prover.domain.ifft_g1(monomial);
+            bitreverse_slice(&mut lagrange);
+            g1_batch_normalize(&lagrange)
+        })
+    }
+
+    /// Returns the G2 points of the trusted setup used by the verifier, ie `[1]_2` and `[tau]_2`.
+    pub fn g2_monomial_points(&self) -> [G2Point; 2] {
+        let verification_key = &self.verifier.verification_key;
+        [verification_key.gen_g2, verification_key.tau_g2]
+    }
+
+    /// Returns the number of bytes used by the points of the trusted setup.
+    ///
+    /// This includes the lagrange points, if they have been computed.
+    pub fn srs_size_in_bytes(&self) -> usize {
+        use std::mem::size_of;
+
+        let lagrange = self.g1_lagrange.get().map_or(0, Vec::len);
+        (self.prover.commit_key.g1s.len() + lagrange + 1) * size_of::<G1Point>()
+            + 2 * size_of::<G2Point>()
+    }
+
+    /// Returns the number of bytes used by the roots of unity and FFT tables of the domain,
+    /// which the prover and verifier share.
+    pub fn domains_size_in_bytes(&self) -> usize {
+        self.prover.domain.size_in_bytes()
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use bls12_381::{lincomb::g1_lincomb, traits::*, Scalar};
+    use serialization::{constants::BYTES_PER_BLOB, serialize_g1_compressed};
+
+    use crate::Context;
+
+    #[test]
+    fn lagrange_points_commit_to_blobs() {
+        let ctx = Context::default();
+        let lagrange = ctx.g1_lagrange_points();
+        assert_eq!(lagrange.len(), ctx.g1_monomial_points().len());
+
+        let scalars: Vec<_> = (0..lagrange.len())
+            .map(|i| Scalar::from(i as u64 + 7))
+            .collect();
+        let mut blob = vec![0u8; BYTES_PER_BLOB];
+        for (chunk, scalar) in blob.chunks_exact_mut(32).zip(&scalars) {
+            chunk.copy_from_slice(&scalar.to_bytes_be());
         }
+
+        let expected = ctx
+            .blob_to_kzg_commitment(blob.as_slice().try_into().unwrap())
+            .unwrap();
+        let commitment = g1_lincomb(lagrange, &scalars).unwrap().to_affine();
+        assert_eq!(serialize_g1_compressed(&commitment), expected);
     }
 }
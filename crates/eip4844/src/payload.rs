@@ -0,0 +1,279 @@
+//! Encoding of arbitrary data into blobs, for rollups that post their batches in blobs.
+//!
+//! Every field element of a blob must be less than the BLS12-381 scalar modulus, so arbitrary
+//! bytes cannot be copied into a blob as they are. Here, each field element holds a zero byte
+//! followed by 31 bytes of data, which keeps it canonical. The data is prefixed with its length
+//! as 8 big-endian bytes, and the last blob is padded with zeros, so that decoding recovers
+//! exactly the bytes that were encoded.
+
+use serialization::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT, FIELD_ELEMENTS_PER_BLOB},
+    types::{BlobRef, KZGCommitment, KZGProof},
+};
+use sha2::{Digest, Sha256};
+
+use crate::Context;
+
+/// `VERSIONED_HASH_VERSION_KZG` from EIP-4844.
+pub const VERSIONED_HASH_VERSION_KZG: u8 = 0x01;
+
+/// The number of bytes of data in each field element of an encoded blob.
+pub const BYTES_OF_DATA_PER_FIELD_ELEMENT: usize = BYTES_PER_FIELD_ELEMENT - 1;
+
+/// The number of bytes of data in an encoded blob, including the length prefix in the first blob.
+pub const BYTES_OF_DATA_PER_BLOB: usize = BYTES_OF_DATA_PER_FIELD_ELEMENT * FIELD_ELEMENTS_PER_BLOB;
+
+/// The number of bytes of the length prefix.
+const LENGTH_PREFIX_BYTES: usize = 8;
+
+/// Returns the versioned hash of a commitment, as `kzg_to_versioned_hash` does.
+pub fn kzg_to_versioned_hash(commitment: &KZGCommitment) -> [u8; 32] {
+    let mut hash: [u8; 32] = Sha256::digest(commitment).into();
+    hash[0] = VERSIONED_HASH_VERSION_KZG;
+    hash
+}
+
+/// Returns the number of blobs that [`encode_payload`] encodes `data_len` bytes into.
+///
+/// Rollups that are limited to a number of blobs per transaction can use this to split their
+/// batches before encoding them.
+pub const fn blobs_needed(data_len: usize) -> usize {
+    (LENGTH_PREFIX_BYTES + data_len).div_ceil(BYTES_OF_DATA_PER_BLOB)
+}
+
+/// Encodes `data` into [`blobs_needed`] blobs, whose field elements are all canonical.
+///
+/// Empty data is encoded into one blob.
+pub fn encode_payload(data: &[u8]) -> Vec<Box<[u8; BYTES_PER_BLOB]>> {
+    let mut stream = Vec::with_capacity(LENGTH_PREFIX_BYTES + data.len());
+    stream.extend_from_slice(&(data.len() as u64).to_be_bytes());
+    stream.extend_from_slice(data);
+
+    // The blobs are allocated on the heap directly, as they are too large for the stack.
+    let mut blobs: Vec<Box<[u8; BYTES_PER_BLOB]>> = (0..blobs_needed(data.len()))
+        .map(|_| {
+            vec![0u8; BYTES_PER_BLOB]
+                .into_boxed_slice()
+                .try_into()
+                .expect("the vector has BYTES_PER_BLOB bytes")
+        })
+        .collect();
+
+    let field_elements = blobs
+        .iter_mut()
+        .flat_map(|blob| blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT));
+    for (chunk, field_element) in stream
+        .chunks(BYTES_OF_DATA_PER_FIELD_ELEMENT)
+        .zip(field_elements)
+    {
+        field_element[1..=chunk.len()].copy_from_slice(chunk);
+    }
+
+    blobs
+}
+
+/// Errors returned by [`decode_payload`] for blobs that [`encode_payload`] did not produce.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum PayloadError {
+    /// There were no blobs to decode.
+    NoBlobs,
+    /// The first byte of a field element was not zero.
+    InvalidFieldElement { blob: usize, field_element: usize },
+    /// The length prefix is larger than the data that the blobs hold.
+    InvalidLength { length: u64, capacity: usize },
+    /// The data fits in a different number of blobs than were given.
+    BlobCount { expected: usize, found: usize },
+    /// The bytes after the end of the data were not zero.
+    NonZeroPadding,
+}
+
+/// Decodes the data that [`encode_payload`] encoded into `blobs`.
+///
+/// Only the output of [`encode_payload`] is accepted, so each payload has a single encoding.
+pub fn decode_payload(blobs: &[BlobRef]) -> Result<Vec<u8>, PayloadError> {
+    if blobs.is_empty() {
+        return Err(PayloadError::NoBlobs);
+    }
+
+    let mut stream = Vec::with_capacity(blobs.len() * BYTES_OF_DATA_PER_BLOB);
+    for (blob_index, blob) in blobs.iter().enumerate() {
+        for (index, field_element) in blob.chunks_exact(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            if field_element[0] != 0 {
+                return Err(PayloadError::InvalidFieldElement {
+                    blob: blob_index,
+                    field_element: index,
+                });
+            }
+            stream.extend_from_slice(&field_element[1..]);
+        }
+    }
+
+    let (prefix, data) = stream.split_at(LENGTH_PREFIX_BYTES);
+    let length = u64::from_be_bytes(prefix.try_into().expect("the prefix has 8 bytes"));
+    let capacity = data.len();
+    if length > capacity as u64 {
+        return Err(PayloadError::InvalidLength { length, capacity });
+    }
+    let length = length as usize;
+
+    let expected = blobs_needed(length);
+    if expected != blobs.len() {
+        return Err(PayloadError::BlobCount {
+            expected,
+            found: blobs.len(),
+        });
+    }
+    if data[length..].iter().any(|&byte| byte != 0) {
+        return Err(PayloadError::NonZeroPadding);
+    }
+
+    stream.drain(..LENGTH_PREFIX_BYTES);
+    stream.truncate(length);
+    Ok(stream)
+}
+
+/// The blobs that a payload was encoded into, with everything needed to post them in a
+/// blob transaction.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct PayloadBlobs {
+    pub blobs: Vec<Box<[u8; BYTES_PER_BLOB]>>,
+    pub commitments: Vec<KZGCommitment>,
+    /// The blob proof of each blob, as in the sidecars of EIP-4844.
+    pub proofs: Vec<KZGProof>,
+    /// The versioned hashes of the commitments, for the `blob_versioned_hashes` of the transaction.
+    pub versioned_hashes: Vec<[u8; 32]>,
+}
+
+impl Context {
+    /// Encodes `data` into blobs with [`encode_payload`], and computes the commitment, blob
+    /// proof and versioned hash of each blob.
+    ///
+    /// This cannot fail, since the encoded blobs are always valid.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn commit_to_payload(&self, data: &[u8]) -> PayloadBlobs {
+        let blobs = encode_payload(data);
+
+        let mut commitments = Vec::with_capacity(blobs.len());
+        let mut proofs = Vec::with_capacity(blobs.len());
+        for blob in &blobs {
+            let commitment = self
+                .blob_to_kzg_commitment(blob)
+                .expect("encoded blobs only have canonical field elements");
+            let proof = self
+                .compute_blob_kzg_proof(blob, &commitment)
+                .expect("the commitment was computed from the blob");
+            commitments.push(commitment);
+            proofs.push(proof);
+        }
+        let versioned_hashes = commitments.iter().map(kzg_to_versioned_hash).collect();
+
+        PayloadBlobs {
+            blobs,
+            commitments,
+            proofs,
+            versioned_hashes,
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use serialization::constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT};
+
+    use super::{
+        blobs_needed, decode_payload, encode_payload, PayloadError, BYTES_OF_DATA_PER_BLOB,
+    };
+
+    fn data(len: usize) -> Vec<u8> {
+        (0..len).map(|i| (i * 7 + 3) as u8).collect()
+    }
+
+    #[test]
+    fn payloads_round_trip() {
+        for len in [
+            0,
+            1,
+            BYTES_OF_DATA_PER_BLOB - 8,
+            BYTES_OF_DATA_PER_BLOB - 7,
+            2 * BYTES_OF_DATA_PER_BLOB + 5,
+        ] {
+            let data = data(len);
+            let blobs = encode_payload(&data);
+            assert_eq!(blobs.len(), blobs_needed(len));
+
+            let refs: Vec<_> = blobs.iter().map(|blob| &**blob).collect();
+            assert_eq!(decode_payload(&refs).unwrap(), data);
+        }
+        assert_eq!(blobs_needed(BYTES_OF_DATA_PER_BLOB - 8), 1);
+        assert_eq!(blobs_needed(BYTES_OF_DATA_PER_BLOB - 7), 2);
+    }
+
+    #[test]
+    fn field_elements_are_canonical() {
+        let blobs = encode_payload(&vec![0xff; 3 * BYTES_PER_BLOB / 2]);
+        for blob in &blobs {
+            assert!(blob
+                .chunks_exact(BYTES_PER_FIELD_ELEMENT)
+                .all(|field_element| field_element[0] == 0));
+        }
+    }
+
+    #[test]
+    fn only_encoded_blobs_are_decoded() {
+        assert_eq!(decode_payload(&[]), Err(PayloadError::NoBlobs));
+
+        let blobs = encode_payload(&data(100));
+        let mut blob = blobs[0].clone();
+        blob[BYTES_PER_FIELD_ELEMENT] = 1;
+        assert_eq!(
+            decode_payload(&[&*blob]),
+            Err(PayloadError::InvalidFieldElement {
+                blob: 0,
+                field_element: 1
+            })
+        );
+
+        let mut blob = blobs[0].clone();
+        blob[BYTES_PER_BLOB - 1] = 1;
+        assert_eq!(decode_payload(&[&*blob]), Err(PayloadError::NonZeroPadding));
+
+        let mut blob = blobs[0].clone();
+        blob[1] = 0xff;
+        assert!(matches!(
+            decode_payload(&[&*blob]),
+            Err(PayloadError::InvalidLength { .. })
+        ));
+
+        let empty = encode_payload(&[]);
+        assert_eq!(
+            decode_payload(&[&*blobs[0], &*empty[0]]),
+            Err(PayloadError::BlobCount {
+                expected: 1,
+                found: 2
+            })
+        );
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn payload_commitments_and_proofs_verify() {
+        use super::kzg_to_versioned_hash;
+        use crate::Context;
+
+        let ctx = Context::default();
+        let data = data(BYTES_OF_DATA_PER_BLOB + 1);
+        let payload = ctx.commit_to_payload(&data);
+        assert_eq!(payload.blobs.len(), 2);
+
+        let blobs: Vec<_> = payload.blobs.iter().map(|blob| &**blob).collect();
+        let commitments: Vec<_> = payload.commitments.iter().collect();
+        let proofs: Vec<_> = payload.proofs.iter().collect();
+        ctx.verify_blob_kzg_proof_batch(blobs.clone(), commitments, proofs)
+            .unwrap();
+        assert_eq!(
+            payload.versioned_hashes[1],
+            kzg_to_versioned_hash(&payload.commitments[1])
+        );
+        assert_eq!(decode_payload(&blobs).unwrap(), data);
+    }
+}
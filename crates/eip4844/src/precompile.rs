@@ -0,0 +1,191 @@
+//! The input and output of the point evaluation precompile of EIP-4844.
+//!
+//! The precompile takes `versioned_hash || z || y || commitment || proof`, and returns
+//! `PRECOMPILE_FIELD_ELEMENTS_PER_BLOB || BLS_MODULUS` as two 32 byte big-endian integers if the
+//! commitment has the versioned hash and the proof shows that the committed polynomial
+//! evaluates to `y` at `z`.
+
+use serialization::{
+    constants::{BYTES_PER_COMMITMENT, BYTES_PER_FIELD_ELEMENT},
+    types::{BlobRef, KZGCommitment, KZGProof, SerializedScalar},
+};
+
+use crate::{kzg_to_versioned_hash, Context, Error};
+
+/// The number of bytes of the input of the precompile.
+pub const POINT_EVALUATION_INPUT_BYTES: usize =
+    32 + 2 * BYTES_PER_FIELD_ELEMENT + 2 * BYTES_PER_COMMITMENT;
+
+/// The number of bytes of the output of the precompile.
+pub const POINT_EVALUATION_OUTPUT_BYTES: usize = 64;
+
+/// The number of field elements per blob that the precompile returns.
+///
+/// EIP-4844 fixes this at 4096, so it is not the compiled `FIELD_ELEMENTS_PER_BLOB`, which is
+/// smaller with the reduced `testing` parameters.
+pub const PRECOMPILE_FIELD_ELEMENTS_PER_BLOB: u64 = 4096;
+
+/// The order of the BLS12-381 scalar field, as a 32 byte big-endian integer.
+pub const BLS_MODULUS: [u8; 32] = [
+    0x73, 0xed, 0xa7, 0x53, 0x29, 0x9d, 0x7d, 0x48, 0x33, 0x39, 0xd8, 0x08, 0x09, 0xa1, 0xd8, 0x05,
+    0x53, 0xbd, 0xa4, 0x02, 0xff, 0xfe, 0x5b, 0xfe, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01,
+];
+
+/// Errors returned by [`Context::verify_point_evaluation_input`], for which the precompile fails.
+#[derive(Debug)]
+pub enum PrecompileError {
+    /// The input did not have [`POINT_EVALUATION_INPUT_BYTES`] bytes.
+    InvalidInputLength(usize),
+    /// The versioned hash was not the versioned hash of the commitment.
+    VersionedHashMismatch,
+    /// The commitment, point, evaluation or proof could not be deserialized, or the proof did
+    /// not verify.
+    Kzg(Error),
+}
+
+impl From<Error> for PrecompileError {
+    fn from(value: Error) -> Self {
+        Self::Kzg(value)
+    }
+}
+
+/// Returns the output of the precompile when the proof verifies.
+pub fn point_evaluation_output() -> [u8; POINT_EVALUATION_OUTPUT_BYTES] {
+    let mut output = [0u8; POINT_EVALUATION_OUTPUT_BYTES];
+    output[24..32].copy_from_slice(&PRECOMPILE_FIELD_ELEMENTS_PER_BLOB.to_be_bytes());
+    output[32..].copy_from_slice(&BLS_MODULUS);
+    output
+}
+
+/// Lays out the input of the precompile for a proof that the polynomial committed to by
+/// `commitment` evaluates to `y` at `z`.
+pub fn point_evaluation_input(
+    commitment: &KZGCommitment,
+    z: &SerializedScalar,
+    y: &SerializedScalar,
+    proof: &KZGProof,
+) -> [u8; POINT_EVALUATION_INPUT_BYTES] {
+    let mut input = [0u8; POINT_EVALUATION_INPUT_BYTES];
+    let (versioned_hash, rest) = input.split_at_mut(32);
+    let (z_bytes, rest) = rest.split_at_mut(BYTES_PER_FIELD_ELEMENT);
+    let (y_bytes, rest) = rest.split_at_mut(BYTES_PER_FIELD_ELEMENT);
+    let (commitment_bytes, proof_bytes) = rest.split_at_mut(BYTES_PER_COMMITMENT);
+
+    versioned_hash.copy_from_slice(&kzg_to_versioned_hash(commitment));
+    z_bytes.copy_from_slice(z);
+    y_bytes.copy_from_slice(y);
+    commitment_bytes.copy_from_slice(commitment);
+    proof_bytes.copy_from_slice(proof);
+    input
+}
+
+impl Context {
+    /// Computes the commitment to `blob`, and the evaluation and proof at `z`, and lays them
+    /// out as the input of the precompile.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn point_evaluation_input_for_blob(
+        &self,
+        blob: BlobRef,
+        z: SerializedScalar,
+    ) -> Result<[u8; POINT_EVALUATION_INPUT_BYTES], Error> {
+        let commitment = self.blob_to_kzg_commitment(blob)?;
+        let (proof, y) = self.compute_kzg_proof(blob, z)?;
+        Ok(point_evaluation_input(&commitment, &z, &y, &proof))
+    }
+
+    /// Runs the point evaluation precompile on `input`, returning its output if it succeeds.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn verify_point_evaluation_input(
+        &self,
+        input: &[u8],
+    ) -> Result<[u8; POINT_EVALUATION_OUTPUT_BYTES], PrecompileError> {
+        let input: &[u8; POINT_EVALUATION_INPUT_BYTES] = input
+            .try_into()
+            .map_err(|_| PrecompileError::InvalidInputLength(input.len()))?;
+
+        let (versioned_hash, rest) = input.split_at(32);
+        let (z, rest) = rest.split_at(BYTES_PER_FIELD_ELEMENT);
+        let (y, rest) = rest.split_at(BYTES_PER_FIELD_ELEMENT);
+        let (commitment, proof) = rest.split_at(BYTES_PER_COMMITMENT);
+
+        let commitment: &KZGCommitment = commitment.try_into().expect("commitment has 48 bytes");
+        if kzg_to_versioned_hash(commitment) != versioned_hash {
+            return Err(PrecompileError::VersionedHashMismatch);
+        }
+
+        self.verify_kzg_proof(
+            commitment,
+            z.try_into().expect("z has 32 bytes"),
+            y.try_into().expect("y has 32 bytes"),
+            proof.try_into().expect("proof has 48 bytes"),
+        )?;
+
+        Ok(point_evaluation_output())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use bls12_381::{traits::*, Scalar};
+
+    use super::{point_evaluation_output, BLS_MODULUS};
+
+    #[test]
+    fn bls_modulus_is_the_order_of_the_scalar_field() {
+        let mut modulus_minus_one = BLS_MODULUS;
+        modulus_minus_one[31] -= 1;
+        assert_eq!((-Scalar::ONE).to_bytes_be(), modulus_minus_one);
+    }
+
+    #[test]
+    fn output_matches_eip_4844() {
+        let output = point_evaluation_output();
+        assert_eq!(output[..30], [0u8; 30]);
+        assert_eq!(output[30..32], [0x10, 0x00]);
+        assert_eq!(output[32..], BLS_MODULUS);
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn precompile_accepts_the_inputs_it_builds() {
+        use serialization::constants::BYTES_PER_BLOB;
+
+        use super::{PrecompileError, POINT_EVALUATION_INPUT_BYTES};
+        use crate::Context;
+
+        let ctx = Context::default();
+        let mut blob = vec![0u8; BYTES_PER_BLOB];
+        for (i, chunk) in blob.chunks_exact_mut(32).enumerate() {
+            chunk.copy_from_slice(&Scalar::from(i as u64 + 1).to_bytes_be());
+        }
+        let z = Scalar::from(123_456u64).to_bytes_be();
+
+        let input = ctx
+            .point_evaluation_input_for_blob(blob.as_slice().try_into().unwrap(), z)
+            .unwrap();
+        assert_eq!(
+            ctx.verify_point_evaluation_input(&input).unwrap(),
+            point_evaluation_output()
+        );
+
+        assert!(matches!(
+            ctx.verify_point_evaluation_input(&input[1..]),
+            Err(PrecompileError::InvalidInputLength(len)) if len == POINT_EVALUATION_INPUT_BYTES - 1
+        ));
+
+        let mut wrong_hash = input;
+        wrong_hash[1] ^= 1;
+        assert!(matches!(
+            ctx.verify_point_evaluation_input(&wrong_hash),
+            Err(PrecompileError::VersionedHashMismatch)
+        ));
+
+        // A different evaluation at z
+        let mut wrong_y = input;
+        wrong_y[32 + 31] ^= 1;
+        assert!(matches!(
+            ctx.verify_point_evaluation_input(&wrong_y),
+            Err(PrecompileError::Kzg(_))
+        ));
+    }
+}
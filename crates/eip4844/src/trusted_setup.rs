@@ -1,8 +1,18 @@
 use kzg_single_open::{prover::CommitKey, verifier::VerificationKey};
+use serialization::constants::FIELD_ELEMENTS_PER_BLOB;
 pub use trusted_setup::TrustedSetup;
 
+/// Creates the commit key from the first `FIELD_ELEMENTS_PER_BLOB` G1 points of the setup.
+///
+/// # Panics
+/// Panics if the setup has fewer than `FIELD_ELEMENTS_PER_BLOB` G1 points.
 pub fn commit_key_from_setup(setup: &TrustedSetup) -> CommitKey {
-    CommitKey::new(setup.g1_monomial.clone())
+    assert!(
+        setup.g1_monomial.len() >= FIELD_ELEMENTS_PER_BLOB,
+        "trusted setup has {} G1 points, but at least {FIELD_ELEMENTS_PER_BLOB} are needed",
+        setup.g1_monomial.len()
+    );
+    CommitKey::new(setup.g1_monomial[..FIELD_ELEMENTS_PER_BLOB].to_vec())
 }
 
 pub fn verification_key_from_setup(setup: &TrustedSetup) -> VerificationKey {
@@ -16,6 +16,7 @@ impl Context {
     /// Verify the KZG proof to the commitment.
     ///
     /// The matching function in the specs is: https://github.com/ethereum/consensus-specs/blob/017a8495f7671f5fff2075a9bfc9238c1a0982f8/specs/deneb/polynomial-commitments.md#verify_kzg_proof
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     pub fn verify_kzg_proof(
         &self,
         commitment: Bytes48Ref,
@@ -46,6 +47,7 @@ impl Context {
     /// Verify the KZG proof to the commitment of a blob.
     ///
     /// The matching function in the specs is: https://github.com/ethereum/consensus-specs/blob/017a8495f7671f5fff2075a9bfc9238c1a0982f8/specs/deneb/polynomial-commitments.md#verify_blob_kzg_proof
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     pub fn verify_blob_kzg_proof(
         &self,
         blob: BlobRef,
@@ -77,6 +79,7 @@ impl Context {
     ///
     /// The matching function in the specs is: https://github.com/ethereum/consensus-specs/blob/017a8495f7671f5fff2075a9bfc9238c1a0982f8/specs/deneb/polynomial-commitments.md#verify_blob_kzg_proof_batch
     #[allow(clippy::needless_pass_by_value)]
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all, fields(num_blobs = blobs.len())))]
     pub fn verify_blob_kzg_proof_batch(
         &self,
         blobs: Vec<BlobRef>,
@@ -1,3 +1,6 @@
+// The consensus spec test vectors are only valid for the mainnet parameters.
+#![cfg(not(feature = "testing"))]
+
 use std::fs;
 
 use common::collect_test_files;
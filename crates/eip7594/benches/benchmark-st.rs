@@ -1,8 +1,8 @@
-use bls12_381::Scalar;
 use criterion::{criterion_group, criterion_main, Criterion};
 use rust_eth_kzg::{
     constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
-    Bytes48Ref, Cell, CellIndex, CellRef, DASContext, KZGCommitment, KZGProof, TrustedSetup,
+    Bytes48Ref, Cell, CellIndex, CellRef, DASContext, KZGCommitment, KZGProof, Scalar,
+    TrustedSetup, UsePrecomp,
 };
 
 const POLYNOMIAL_LEN: usize = 4096;
@@ -38,10 +38,7 @@ pub fn bench_compute_cells_and_kzg_proofs(c: &mut Criterion) {
 
     let blob = dummy_blob();
 
-    let ctx = DASContext::new(
-        &trusted_setup,
-        bls12_381::fixed_base_msm::UsePrecomp::Yes { width: 8 },
-    );
+    let ctx = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 8 });
     c.bench_function("computing cells_and_kzg_proofs", |b| {
         b.iter(|| ctx.compute_cells_and_kzg_proofs(&blob));
     });
@@ -61,10 +58,7 @@ pub fn bench_recover_cells_and_compute_kzg_proofs(c: &mut Criterion) {
         .map(std::convert::AsRef::as_ref)
         .collect::<Vec<_>>();
 
-    let ctx = DASContext::new(
-        &trusted_setup,
-        bls12_381::fixed_base_msm::UsePrecomp::Yes { width: 8 },
-    );
+    let ctx = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 8 });
     c.bench_function("worse-case recover_cells_and_kzg_proofs", |b| {
         b.iter(|| ctx.recover_cells_and_kzg_proofs(half_cell_indices.to_vec(), half_cells.clone()));
     });
@@ -80,10 +74,7 @@ pub fn bench_verify_cell_kzg_proof_batch(c: &mut Criterion) {
     let cell_refs: Vec<CellRef> = cells.iter().map(std::convert::AsRef::as_ref).collect();
     let proof_refs: Vec<Bytes48Ref> = proofs.iter().collect();
 
-    let ctx = DASContext::new(
-        &trusted_setup,
-        bls12_381::fixed_base_msm::UsePrecomp::Yes { width: 8 },
-    );
+    let ctx = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 8 });
     c.bench_function("verify_cell_kzg_proof_batch", |b| {
         b.iter(|| {
             ctx.verify_cell_kzg_proof_batch(
@@ -100,10 +91,7 @@ pub fn bench_init_context(c: &mut Criterion) {
     c.bench_function("Initialize context", |b| {
         b.iter(|| {
             let trusted_setup = TrustedSetup::default();
-            DASContext::new(
-                &trusted_setup,
-                bls12_381::fixed_base_msm::UsePrecomp::Yes { width: 8 },
-            )
+            DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 8 })
         });
     });
 }
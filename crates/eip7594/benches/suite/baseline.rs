@@ -0,0 +1,198 @@
+//! Comparison of the results of a run against a JSON baseline, to catch regressions between
+//! releases on the same machine.
+//!
+//! Criterion keeps its own baselines in its output directory, which are tied to the checkout
+//! that produced them. This saves the median time of each benchmark of a run to a small JSON
+//! file instead, which can be kept with a release or shared by a packager:
+//!
+//! - `EKZG_BENCH_SAVE_BASELINE=<path>` writes the results of the run to `path`.
+//! - `EKZG_BENCH_BASELINE=<path>` compares the results of the run against the baseline at
+//!   `path`, and makes the run fail if a benchmark is slower than the baseline by more than
+//!   `EKZG_BENCH_THRESHOLD` percent, which defaults to 10.
+//!
+//! The results are read back from the output directory of criterion, which is
+//! `CRITERION_HOME`, or the `criterion` directory of the cargo target directory. Only the
+//! benchmarks that were measured by this run are saved or compared, so a filtered run only
+//! checks the benchmarks that it selected.
+
+use std::{
+    collections::BTreeMap,
+    fs,
+    path::{Path, PathBuf},
+    time::SystemTime,
+};
+
+use serde::{Deserialize, Serialize};
+
+/// The regression threshold, in percent, when `EKZG_BENCH_THRESHOLD` is not set.
+const DEFAULT_THRESHOLD_PERCENT: f64 = 10.0;
+
+/// The median times of a run, in nanoseconds, by criterion benchmark id.
+#[derive(Debug, Default, Serialize, Deserialize)]
+struct Baseline {
+    benchmarks: BTreeMap<String, f64>,
+}
+
+/// The parts of the `benchmark.json` files of criterion that are needed.
+#[derive(Deserialize)]
+struct BenchmarkInfo {
+    full_id: String,
+}
+
+/// The parts of the `estimates.json` files of criterion that are needed.
+#[derive(Deserialize)]
+struct Estimates {
+    median: Estimate,
+}
+
+#[derive(Deserialize)]
+struct Estimate {
+    point_estimate: f64,
+}
+
+/// Saves and compares the results of the run that started at `started`, as configured by the
+/// environment.
+///
+/// Returns `false` if a benchmark regressed against the baseline.
+pub fn run(started: SystemTime) -> bool {
+    let save_path = std::env::var_os("EKZG_BENCH_SAVE_BASELINE");
+    let baseline_path = std::env::var_os("EKZG_BENCH_BASELINE");
+    if save_path.is_none() && baseline_path.is_none() {
+        return true;
+    }
+
+    let results = collect_results(&criterion_directory(), started);
+    if results.benchmarks.is_empty() {
+        eprintln!("no benchmark results were written by this run, nothing to save or compare");
+        return true;
+    }
+
+    if let Some(path) = save_path {
+        let json = serde_json::to_string_pretty(&results).expect("the results serialize to json");
+        fs::write(&path, json).expect("failed to write the baseline");
+        println!(
+            "saved {} results to {}",
+            results.benchmarks.len(),
+            Path::new(&path).display()
+        );
+    }
+
+    let Some(path) = baseline_path else {
+        return true;
+    };
+    let json = fs::read_to_string(&path).expect("failed to read the baseline");
+    let baseline: Baseline = serde_json::from_str(&json).expect("the baseline is not valid json");
+    let threshold = match std::env::var("EKZG_BENCH_THRESHOLD") {
+        Ok(threshold) => threshold
+            .parse()
+            .expect("EKZG_BENCH_THRESHOLD must be a percentage"),
+        Err(_) => DEFAULT_THRESHOLD_PERCENT,
+    };
+
+    compare(&baseline, &results, threshold)
+}
+
+/// Returns the output directory of criterion.
+fn criterion_directory() -> PathBuf {
+    if let Some(home) = std::env::var_os("CRITERION_HOME") {
+        return PathBuf::from(home);
+    }
+    if let Some(target) = std::env::var_os("CARGO_TARGET_DIR") {
+        return Path::new(&target).join("criterion");
+    }
+    // Bench binaries are built to `<target>/<profile>/deps`
+    std::env::current_exe()
+        .ok()
+        .and_then(|exe| {
+            exe.ancestors()
+                .nth(3)
+                .map(|target| target.join("criterion"))
+        })
+        .filter(|directory| directory.is_dir())
+        .unwrap_or_else(|| PathBuf::from("target/criterion"))
+}
+
+/// Returns the results of the benchmarks under `directory` that were measured since `started`.
+fn collect_results(directory: &Path, started: SystemTime) -> Baseline {
+    let mut results = Baseline::default();
+    let mut directories = vec![directory.to_path_buf()];
+    while let Some(directory) = directories.pop() {
+        let Ok(entries) = fs::read_dir(&directory) else {
+            continue;
+        };
+        for entry in entries.filter_map(Result::ok) {
+            let path = entry.path();
+            if !path.is_dir() {
+                continue;
+            }
+            // Each benchmark has a `new` directory with its latest results, next to the
+            // baselines of criterion, which are skipped.
+            if entry.file_name() == "new" {
+                if let Some((id, median)) = read_result(&path, started) {
+                    results.benchmarks.insert(id, median);
+                }
+            } else if entry.file_name() != "base" && entry.file_name() != "report" {
+                directories.push(path);
+            }
+        }
+    }
+    results
+}
+
+/// Reads the id and median time of the result in `directory`, if it was written since `started`.
+fn read_result(directory: &Path, started: SystemTime) -> Option<(String, f64)> {
+    let estimates_path = directory.join("estimates.json");
+    let modified = fs::metadata(&estimates_path).ok()?.modified().ok()?;
+    if modified < started {
+        return None;
+    }
+
+    let info: BenchmarkInfo =
+        serde_json::from_str(&fs::read_to_string(directory.join("benchmark.json")).ok()?).ok()?;
+    let estimates: Estimates =
+        serde_json::from_str(&fs::read_to_string(estimates_path).ok()?).ok()?;
+    Some((info.full_id, estimates.median.point_estimate))
+}
+
+/// Prints how each result compares to the baseline, and returns `false` if one of them is
+/// slower by more than `threshold` percent.
+fn compare(baseline: &Baseline, results: &Baseline, threshold: f64) -> bool {
+    let mut passed = true;
+
+    println!("\ncomparison against the baseline, threshold {threshold}%:");
+    for (id, &median) in &results.benchmarks {
+        let Some(&base) = baseline.benchmarks.get(id) else {
+            println!("  {id}: {} (not in the baseline)", format_time(median));
+            continue;
+        };
+
+        let change = (median / base - 1.0) * 100.0;
+        let verdict = if change > threshold {
+            passed = false;
+            "REGRESSION"
+        } else if change < -threshold {
+            "improvement"
+        } else {
+            "ok"
+        };
+        println!(
+            "  {id}: {} -> {} ({change:+.1}%) {verdict}",
+            format_time(base),
+            format_time(median)
+        );
+    }
+
+    if !passed {
+        println!("some benchmarks are more than {threshold}% slower than the baseline");
+    }
+    passed
+}
+
+fn format_time(nanos: f64) -> String {
+    match nanos {
+        n if n >= 1e9 => format!("{:.3} s", n / 1e9),
+        n if n >= 1e6 => format!("{:.3} ms", n / 1e6),
+        n if n >= 1e3 => format!("{:.3} µs", n / 1e3),
+        n => format!("{n:.1} ns"),
+    }
+}
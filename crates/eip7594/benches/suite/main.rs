@@ -0,0 +1,358 @@
+//! Benchmarks of every public operation of `DASContext`, across batch sizes and thread counts.
+//!
+//! Run with `cargo bench -p rust_eth_kzg --bench suite --features multithreaded`. Criterion
+//! arguments such as a filter can be passed after `--`.
+//!
+//! The thread counts default to 1, 4 and one thread per CPU, and can be changed with
+//! `EKZG_BENCH_THREADS`, a comma-separated list. See [`baseline`] for comparing the results
+//! against a JSON baseline saved by a previous run.
+
+mod baseline;
+
+use std::{num::NonZeroUsize, time::Duration};
+
+use criterion::{criterion_group, BenchmarkId, Criterion, SamplingMode, Throughput};
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
+    Bytes48Ref, Cell, CellIndex, CellRef, DASContext, KZGCommitment, KZGProof, Scalar,
+    SerializedScalar, TrustedSetup, UsePrecomp,
+};
+
+const POLYNOMIAL_LEN: usize = 4096;
+
+/// The numbers of blobs of the batched operations: a single blob, the blob target of a block
+/// and a larger block.
+const BLOB_COUNTS: [usize; 3] = [1, 6, 16];
+
+/// The shapes of the batches of `verify_cell_kzg_proof_batch`, as a number of blobs and a
+/// number of cells per blob: data columns, which open one cell of every blob, and whole blobs.
+const CELL_BATCHES: [(usize, usize); 5] = [
+    (1, 1),
+    (6, 1),
+    (16, 1),
+    (1, CELLS_PER_EXT_BLOB),
+    (6, CELLS_PER_EXT_BLOB),
+];
+
+/// The numbers of cells given to `recover_cells_and_kzg_proofs`: the worst case, where half
+/// of the cells are missing, and a case where a quarter of them are.
+const AVAILABLE_CELLS: [usize; 2] = [CELLS_PER_EXT_BLOB / 2, 3 * CELLS_PER_EXT_BLOB / 4];
+
+fn dummy_blob(seed: usize) -> [u8; BYTES_PER_BLOB] {
+    let polynomial = (0..POLYNOMIAL_LEN).map(|i| -Scalar::from((seed * POLYNOMIAL_LEN + i) as u64));
+    let blob: Vec<_> = polynomial
+        .into_iter()
+        .flat_map(|scalar| scalar.to_bytes_be())
+        .collect();
+    blob.try_into().expect("blob conversion failed")
+}
+
+/// Blobs, and everything computed from them that the verification methods take as input.
+struct Fixtures {
+    blobs: Vec<[u8; BYTES_PER_BLOB]>,
+    commitments: Vec<KZGCommitment>,
+    cells_and_proofs: Vec<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB])>,
+    blob_proofs: Vec<KZGProof>,
+    z: SerializedScalar,
+    y: SerializedScalar,
+    point_proof: KZGProof,
+}
+
+impl Fixtures {
+    fn new(ctx: &DASContext) -> Self {
+        let num_blobs = *BLOB_COUNTS.iter().max().expect("there are blob counts");
+        let blobs: Vec<_> = (0..num_blobs).map(dummy_blob).collect();
+
+        let commitments: Vec<_> = blobs
+            .iter()
+            .map(|blob| {
+                ctx.blob_to_kzg_commitment(blob)
+                    .expect("blob to commitment failed")
+            })
+            .collect();
+        let cells_and_proofs = ctx
+            .compute_cells_and_kzg_proofs_batch(blobs.iter().collect())
+            .expect("failed to compute cells and kzg proofs");
+        let blob_proofs = blobs
+            .iter()
+            .zip(&commitments)
+            .map(|(blob, commitment)| {
+                ctx.compute_blob_kzg_proof(blob, commitment)
+                    .expect("failed to compute blob kzg proof")
+            })
+            .collect();
+
+        let z = Scalar::from(42u64).to_bytes_be();
+        let (point_proof, y) = ctx
+            .compute_kzg_proof(&blobs[0], z)
+            .expect("failed to compute kzg proof");
+
+        Self {
+            blobs,
+            commitments,
+            cells_and_proofs,
+            blob_proofs,
+            z,
+            y,
+            point_proof,
+        }
+    }
+}
+
+/// Returns the thread counts to benchmark, from `EKZG_BENCH_THREADS` or the defaults.
+fn thread_counts() -> Vec<usize> {
+    let num_cpus = std::thread::available_parallelism().map_or(1, NonZeroUsize::get);
+
+    let mut counts: Vec<usize> = match std::env::var("EKZG_BENCH_THREADS") {
+        Ok(list) => {
+            list.split(',')
+                .map(|count| {
+                    count.trim().parse().expect(
+                        "EKZG_BENCH_THREADS must be a comma-separated list of thread counts",
+                    )
+                })
+                .collect()
+        }
+        Err(_) => [1, 4, num_cpus]
+            .into_iter()
+            .filter(|&count| count <= num_cpus)
+            .collect(),
+    };
+    counts.sort_unstable();
+    counts.dedup();
+    counts
+}
+
+/// Returns one context per thread count to benchmark, sharing the same precomputed tables.
+fn contexts() -> Vec<(usize, DASContext)> {
+    let ctx = DASContext::new(&TrustedSetup::default(), UsePrecomp::Yes { width: 8 });
+    thread_counts()
+        .into_iter()
+        .map(|num_threads| {
+            let ctx = ctx
+                .clone()
+                .with_num_threads(num_threads)
+                .expect("failed to build the thread pool");
+            (num_threads, ctx)
+        })
+        .collect()
+}
+
+fn threads_id(num_threads: usize, parameter: impl std::fmt::Display) -> BenchmarkId {
+    BenchmarkId::new(format!("threads={num_threads}"), parameter)
+}
+
+pub fn bench_init_context(c: &mut Criterion) {
+    let trusted_setup = TrustedSetup::default();
+
+    let mut group = c.benchmark_group("init_context");
+    group.sample_size(10);
+    group.bench_function("precomp=none", |b| {
+        b.iter(|| DASContext::new(&trusted_setup, UsePrecomp::No));
+    });
+    group.bench_function("precomp=width8", |b| {
+        b.iter(|| DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 8 }));
+    });
+    group.finish();
+}
+
+pub fn bench_prover(c: &mut Criterion) {
+    let contexts = contexts();
+    let fixtures = Fixtures::new(&contexts[0].1);
+    let blob = &fixtures.blobs[0];
+
+    let mut group = c.benchmark_group("blob_to_kzg_commitment");
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| ctx.blob_to_kzg_commitment(blob));
+        });
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("compute_cells");
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| ctx.compute_cells(blob));
+        });
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("compute_cells_and_kzg_proofs");
+    group.sample_size(10);
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| ctx.compute_cells_and_kzg_proofs(blob));
+        });
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("compute_cells_and_kzg_proofs_batch");
+    group.sample_size(10).sampling_mode(SamplingMode::Flat);
+    for (num_threads, ctx) in &contexts {
+        for num_blobs in BLOB_COUNTS {
+            let blobs: Vec<_> = fixtures.blobs[..num_blobs].iter().collect();
+            group.throughput(Throughput::Elements(num_blobs as u64));
+            group.bench_function(
+                threads_id(*num_threads, format!("blobs={num_blobs}")),
+                |b| {
+                    b.iter(|| ctx.compute_cells_and_kzg_proofs_batch(blobs.clone()));
+                },
+            );
+        }
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("recover_cells_and_kzg_proofs");
+    group.sample_size(10);
+    let (cells, _) = &fixtures.cells_and_proofs[0];
+    for (num_threads, ctx) in &contexts {
+        for num_available in AVAILABLE_CELLS {
+            let cell_indices: Vec<CellIndex> = (0..num_available as CellIndex).collect();
+            let cell_refs: Vec<CellRef> = cell_indices
+                .iter()
+                .map(|&index| cells[index as usize].as_ref())
+                .collect();
+            group.bench_function(
+                threads_id(*num_threads, format!("available={num_available}")),
+                |b| {
+                    b.iter(|| {
+                        ctx.recover_cells_and_kzg_proofs(cell_indices.clone(), cell_refs.clone())
+                    });
+                },
+            );
+        }
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("compute_kzg_proof");
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| ctx.compute_kzg_proof(blob, fixtures.z));
+        });
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("compute_blob_kzg_proof");
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| ctx.compute_blob_kzg_proof(blob, &fixtures.commitments[0]));
+        });
+    }
+    group.finish();
+}
+
+pub fn bench_verifier(c: &mut Criterion) {
+    let contexts = contexts();
+    let fixtures = Fixtures::new(&contexts[0].1);
+
+    let mut group = c.benchmark_group("verify_cell_kzg_proof_batch");
+    for (num_threads, ctx) in &contexts {
+        for (num_blobs, cells_per_blob) in CELL_BATCHES {
+            let mut commitments: Vec<Bytes48Ref> = Vec::new();
+            let mut cell_indices: Vec<CellIndex> = Vec::new();
+            let mut cell_refs: Vec<CellRef> = Vec::new();
+            let mut proof_refs: Vec<Bytes48Ref> = Vec::new();
+            for (commitment, (cells, proofs)) in fixtures
+                .commitments
+                .iter()
+                .zip(&fixtures.cells_and_proofs)
+                .take(num_blobs)
+            {
+                for cell_index in 0..cells_per_blob {
+                    commitments.push(commitment);
+                    cell_indices.push(cell_index as CellIndex);
+                    cell_refs.push(cells[cell_index].as_ref());
+                    proof_refs.push(&proofs[cell_index]);
+                }
+            }
+
+            group.throughput(Throughput::Elements(cell_refs.len() as u64));
+            group.bench_function(
+                threads_id(
+                    *num_threads,
+                    format!("blobs={num_blobs},cells={cells_per_blob}"),
+                ),
+                |b| {
+                    b.iter(|| {
+                        ctx.verify_cell_kzg_proof_batch(
+                            commitments.clone(),
+                            &cell_indices,
+                            cell_refs.clone(),
+                            proof_refs.clone(),
+                        )
+                    });
+                },
+            );
+        }
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("verify_kzg_proof");
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| {
+                ctx.verify_kzg_proof(
+                    &fixtures.commitments[0],
+                    fixtures.z,
+                    fixtures.y,
+                    &fixtures.point_proof,
+                )
+            });
+        });
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("verify_blob_kzg_proof");
+    for (num_threads, ctx) in &contexts {
+        group.bench_function(threads_id(*num_threads, "blobs=1"), |b| {
+            b.iter(|| {
+                ctx.verify_blob_kzg_proof(
+                    &fixtures.blobs[0],
+                    &fixtures.commitments[0],
+                    &fixtures.blob_proofs[0],
+                )
+            });
+        });
+    }
+    group.finish();
+
+    let mut group = c.benchmark_group("verify_blob_kzg_proof_batch");
+    for (num_threads, ctx) in &contexts {
+        for num_blobs in BLOB_COUNTS {
+            let blobs: Vec<_> = fixtures.blobs[..num_blobs].iter().collect();
+            let commitments: Vec<_> = fixtures.commitments[..num_blobs].iter().collect();
+            let proofs: Vec<_> = fixtures.blob_proofs[..num_blobs].iter().collect();
+            group.throughput(Throughput::Elements(num_blobs as u64));
+            group.bench_function(
+                threads_id(*num_threads, format!("blobs={num_blobs}")),
+                |b| {
+                    b.iter(|| {
+                        ctx.verify_blob_kzg_proof_batch(
+                            blobs.clone(),
+                            commitments.clone(),
+                            proofs.clone(),
+                        )
+                    });
+                },
+            );
+        }
+    }
+    group.finish();
+}
+
+criterion_group! {
+    name = benches;
+    config = Criterion::default().warm_up_time(Duration::from_secs(1));
+    targets = bench_init_context, bench_prover, bench_verifier
+}
+
+fn main() {
+    let started = std::time::SystemTime::now();
+
+    benches();
+    Criterion::default().configure_from_args().final_summary();
+
+    if !baseline::run(started) {
+        std::process::exit(1);
+    }
+}
@@ -21,8 +21,7 @@
 
 use std::time::Instant;
 
-use bls12_381::Scalar;
-use rust_eth_kzg::{constants::BYTES_PER_BLOB, DASContext, TrustedSetup};
+use rust_eth_kzg::prelude::*;
 use tracing_forest::{util::LevelFilter, ForestLayer};
 use tracing_subscriber::{layer::SubscriberExt, util::SubscriberInitExt, EnvFilter, Registry};
 
@@ -52,10 +51,7 @@ fn main() {
     let blob = dummy_blob();
 
     // Initialize the data availability sampling context with precomputed fixed-base MSM
-    let ctx = DASContext::new(
-        &trusted_setup,
-        bls12_381::fixed_base_msm::UsePrecomp::Yes { width: 8 },
-    );
+    let ctx = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 8 });
 
     // Warm-up phase
     println!("Warming up for 3 seconds...");
@@ -0,0 +1,228 @@
+//! Limits on the number of operations that a context runs at the same time, see
+//! [`DASContext::with_admission_limits`](crate::DASContext::with_admission_limits).
+
+use std::{
+    sync::{
+        atomic::{AtomicU64, Ordering},
+        Condvar, Mutex,
+    },
+    time::{Duration, Instant},
+};
+
+use crate::errors::{AdmissionError, Error};
+
+/// How many operations a context runs at the same time, and how many wait for their turn.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct AdmissionLimits {
+    /// The number of operations that run at the same time. Must not be 0.
+    pub max_in_flight: usize,
+    /// The number of operations that wait for one of the running operations to finish.
+    /// An operation that arrives when the queue is full fails with
+    /// [`AdmissionError::QueueFull`].
+    pub max_queued: usize,
+    /// How long an operation waits in the queue before it fails with
+    /// [`AdmissionError::TimedOut`], or `None` to wait until it runs.
+    pub queue_timeout: Option<Duration>,
+}
+
+impl AdmissionLimits {
+    /// Runs `max_in_flight` operations at the same time, and rejects the others instead of
+    /// queuing them.
+    pub const fn new(max_in_flight: usize) -> Self {
+        Self {
+            max_in_flight,
+            max_queued: 0,
+            queue_timeout: None,
+        }
+    }
+
+    /// Queues up to `max_queued` operations when `max_in_flight` are running, each for at most
+    /// `queue_timeout`.
+    #[must_use]
+    pub const fn with_queue(mut self, max_queued: usize, queue_timeout: Option<Duration>) -> Self {
+        self.max_queued = max_queued;
+        self.queue_timeout = queue_timeout;
+        self
+    }
+}
+
+/// The operations that a context is running and queuing, and those it has turned away since
+/// the limits were set.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub struct AdmissionStats {
+    /// The number of operations running.
+    pub in_flight: usize,
+    /// The number of operations waiting in the queue.
+    pub queued: usize,
+    /// The number of operations that were rejected because the queue was full.
+    pub rejected: u64,
+    /// The number of operations that waited in the queue for longer than the timeout.
+    pub timed_out: u64,
+}
+
+#[derive(Debug, Default)]
+struct Slots {
+    in_flight: usize,
+    queued: usize,
+}
+
+#[derive(Debug)]
+pub(crate) struct AdmissionControl {
+    limits: AdmissionLimits,
+    slots: Mutex<Slots>,
+    /// Notified when a running operation finishes.
+    released: Condvar,
+    rejected: AtomicU64,
+    timed_out: AtomicU64,
+}
+
+/// A slot of an operation that is running, which is freed when it is dropped.
+pub(crate) struct Permit<'a> {
+    control: &'a AdmissionControl,
+}
+
+impl Drop for Permit<'_> {
+    fn drop(&mut self) {
+        self.control.lock().in_flight -= 1;
+        self.control.released.notify_one();
+    }
+}
+
+impl AdmissionControl {
+    pub(crate) fn new(limits: AdmissionLimits) -> Self {
+        assert!(limits.max_in_flight != 0, "max_in_flight must not be 0");
+        Self {
+            limits,
+            slots: Mutex::default(),
+            released: Condvar::new(),
+            rejected: AtomicU64::new(0),
+            timed_out: AtomicU64::new(0),
+        }
+    }
+
+    fn lock(&self) -> std::sync::MutexGuard<'_, Slots> {
+        self.slots.lock().expect("not poisoned")
+    }
+
+    /// Waits for a slot to run an operation in, in the queue if all of them are taken.
+    pub(crate) fn acquire(&self) -> Result<Permit<'_>, Error> {
+        let mut slots = self.lock();
+        if slots.in_flight < self.limits.max_in_flight {
+            slots.in_flight += 1;
+            return Ok(Permit { control: self });
+        }
+        if slots.queued >= self.limits.max_queued {
+            drop(slots);
+            self.rejected.fetch_add(1, Ordering::Relaxed);
+            return Err(Error::Admission(AdmissionError::QueueFull {
+                max_queued: self.limits.max_queued,
+            }));
+        }
+
+        slots.queued += 1;
+        let deadline = self
+            .limits
+            .queue_timeout
+            .map(|timeout| Instant::now() + timeout);
+        while slots.in_flight >= self.limits.max_in_flight {
+            slots = match deadline {
+                None => self.released.wait(slots).expect("not poisoned"),
+                Some(deadline) => {
+                    let now = Instant::now();
+                    if now >= deadline {
+                        slots.queued -= 1;
+                        drop(slots);
+                        self.timed_out.fetch_add(1, Ordering::Relaxed);
+                        return Err(Error::Admission(AdmissionError::TimedOut {
+                            timeout: self.limits.queue_timeout.unwrap_or_default(),
+                        }));
+                    }
+                    self.released
+                        .wait_timeout(slots, deadline - now)
+                        .expect("not poisoned")
+                        .0
+                }
+            };
+        }
+        slots.queued -= 1;
+        slots.in_flight += 1;
+        Ok(Permit { control: self })
+    }
+
+    pub(crate) fn stats(&self) -> AdmissionStats {
+        let slots = self.lock();
+        AdmissionStats {
+            in_flight: slots.in_flight,
+            queued: slots.queued,
+            rejected: self.rejected.load(Ordering::Relaxed),
+            timed_out: self.timed_out.load(Ordering::Relaxed),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::time::Duration;
+
+    use super::{AdmissionControl, AdmissionLimits};
+    use crate::{errors::AdmissionError, Error, ErrorCode};
+
+    #[test]
+    fn operations_over_the_limits_are_turned_away() {
+        let control = AdmissionControl::new(AdmissionLimits::new(2));
+        let first = control.acquire().unwrap();
+        let _second = control.acquire().unwrap();
+        let err = control.acquire().err().unwrap();
+        assert!(matches!(
+            err,
+            Error::Admission(AdmissionError::QueueFull { max_queued: 0 })
+        ));
+        assert_eq!(err.code(), ErrorCode::Overloaded);
+
+        drop(first);
+        let _third = control.acquire().unwrap();
+        let stats = control.stats();
+        assert_eq!((stats.in_flight, stats.queued, stats.rejected), (2, 0, 1));
+    }
+
+    #[test]
+    fn queued_operations_wait_for_a_slot() {
+        let timeout = Duration::from_millis(20);
+        let control = AdmissionControl::new(AdmissionLimits::new(1).with_queue(1, Some(timeout)));
+        let _running = control.acquire().unwrap();
+
+        // Nothing finishes, so the queued operation times out
+        let err = control.acquire().err().unwrap();
+        assert_eq!(err.code(), ErrorCode::AdmissionTimedOut);
+        assert_eq!(control.stats().timed_out, 1);
+
+        let control = AdmissionControl::new(AdmissionLimits::new(1).with_queue(1, None));
+        let running = control.acquire().unwrap();
+        std::thread::scope(|scope| {
+            let queued = scope.spawn(|| control.acquire().map(drop));
+            while control.stats().queued == 0 {
+                std::thread::yield_now();
+            }
+            // The queue is full
+            let err = control.acquire().err().unwrap();
+            assert_eq!(err.code(), ErrorCode::Overloaded);
+            drop(running);
+            queued.join().unwrap().unwrap();
+        });
+        let stats = control.stats();
+        assert_eq!((stats.in_flight, stats.queued, stats.rejected), (0, 0, 1));
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn context_frees_the_slots_of_its_operations() {
+        use crate::{constants::BYTES_PER_BLOB, DASContext};
+
+        let ctx = DASContext::default().with_admission_limits(AdmissionLimits::new(1));
+        let blob = [0u8; BYTES_PER_BLOB];
+        ctx.blob_to_kzg_commitment(&blob).unwrap();
+        ctx.clone().blob_to_kzg_commitment(&blob).unwrap();
+        assert_eq!(ctx.admission_stats(), Some(Default::default()));
+        assert_eq!(DASContext::default().admission_stats(), None);
+    }
+}
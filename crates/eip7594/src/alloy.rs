@@ -0,0 +1,180 @@
+//! Conversions between blobs, commitments, proofs and versioned hashes and the types of
+//! `alloy-primitives`.
+//!
+//! The inputs and outputs of the library are byte arrays, and the alloy types wrap byte
+//! arrays, so most conversions are already free:
+//!
+//! - A reference to an alloy value is a reference to its byte array through `Deref`, so a
+//!   `&Blob` can be passed wherever a [`BlobRef`](crate::BlobRef) is expected, and a
+//!   `&Bytes48` wherever a [`Bytes48Ref`](crate::Bytes48Ref) is.
+//! - The batch methods take vectors of references, which [`as_refs`] returns for slices of
+//!   alloy values.
+//! - [`ToAlloy`] converts the outputs of the library to alloy types.
+//!
+//! ```ignore
+//! use rust_eth_kzg::alloy::{as_refs, ToAlloy};
+//!
+//! let commitment: Bytes48 = ctx.blob_to_kzg_commitment(&blob)?.to_alloy();
+//! ctx.verify_blob_kzg_proof_batch(as_refs(&blobs), as_refs(&commitments), as_refs(&proofs))?;
+//! ```
+//!
+//! The sidecars of blob transactions can be borrowed from their alloy fields with
+//! [`BlobTransactionSidecar::from_alloy_blob_proofs`] and
+//! [`BlobTransactionSidecar::from_alloy_cell_proofs`], and checked against the versioned hashes
+//! of a transaction with [`DASContext::validate_alloy_blob_transaction`].
+
+pub use alloy_primitives::{FixedBytes, B256};
+
+use crate::{
+    blob_tx::{BlobTransactionReport, BlobTransactionSidecar, SidecarProofs},
+    constants::{BYTES_PER_BLOB, BYTES_PER_COMMITMENT},
+    kzg_to_versioned_hash, DASContext,
+};
+
+/// A blob, as in the sidecars of alloy transactions.
+pub type Blob = FixedBytes<BYTES_PER_BLOB>;
+
+/// A commitment or a proof, as in the sidecars of alloy transactions.
+pub type Bytes48 = FixedBytes<BYTES_PER_COMMITMENT>;
+
+/// Converts an output of the library to the matching alloy type.
+pub trait ToAlloy {
+    /// The matching alloy type.
+    type Alloy;
+
+    /// Returns the alloy value that represents `self`.
+    fn to_alloy(&self) -> Self::Alloy;
+}
+
+/// Commitments, proofs, versioned hashes and serialized scalars are converted to
+/// [`FixedBytes`] of the same length, ie to [`Bytes48`] and [`B256`].
+impl<const N: usize> ToAlloy for [u8; N] {
+    type Alloy = FixedBytes<N>;
+
+    fn to_alloy(&self) -> Self::Alloy {
+        FixedBytes(*self)
+    }
+}
+
+/// Cells are converted to [`FixedBytes`] of `BYTES_PER_CELL` bytes.
+impl<const N: usize> ToAlloy for Box<[u8; N]> {
+    type Alloy = FixedBytes<N>;
+
+    fn to_alloy(&self) -> Self::Alloy {
+        FixedBytes(**self)
+    }
+}
+
+/// The cells or proofs of a blob, or the commitments of a block, are converted one by one.
+impl<T: ToAlloy> ToAlloy for [T] {
+    type Alloy = Vec<T::Alloy>;
+
+    fn to_alloy(&self) -> Self::Alloy {
+        self.iter().map(ToAlloy::to_alloy).collect()
+    }
+}
+
+/// Returns references to the byte arrays of `values`, as the batch methods take them.
+pub fn as_refs<const N: usize>(values: &[FixedBytes<N>]) -> Vec<&[u8; N]> {
+    values.iter().map(|value| &value.0).collect()
+}
+
+/// Returns the versioned hash of `commitment`, see [`kzg_to_versioned_hash`].
+pub fn versioned_hash(commitment: &Bytes48) -> B256 {
+    FixedBytes(kzg_to_versioned_hash(commitment))
+}
+
+impl<'a> BlobTransactionSidecar<'a> {
+    /// Borrows a sidecar with one blob proof per blob, as in the sidecars of EIP-4844.
+    pub fn from_alloy_blob_proofs(
+        blobs: &'a [Blob],
+        commitments: &'a [Bytes48],
+        proofs: &'a [Bytes48],
+    ) -> Self {
+        Self {
+            blobs: as_refs(blobs),
+            commitments: as_refs(commitments),
+            proofs: SidecarProofs::Blob(as_refs(proofs)),
+        }
+    }
+
+    /// Borrows a sidecar with `CELLS_PER_EXT_BLOB` cell proofs per blob, as in the version 1
+    /// sidecars of EIP-7594.
+    pub fn from_alloy_cell_proofs(
+        blobs: &'a [Blob],
+        commitments: &'a [Bytes48],
+        cell_proofs: &'a [Bytes48],
+    ) -> Self {
+        Self {
+            blobs: as_refs(blobs),
+            commitments: as_refs(commitments),
+            proofs: SidecarProofs::Cell(as_refs(cell_proofs)),
+        }
+    }
+}
+
+impl DASContext {
+    /// Checks the sidecar of a blob transaction against the versioned hashes of the
+    /// transaction, as they are stored in alloy transactions.
+    ///
+    /// See [`DASContext::validate_blob_transaction`].
+    pub fn validate_alloy_blob_transaction(
+        &self,
+        versioned_hashes: &[B256],
+        sidecar: &BlobTransactionSidecar,
+    ) -> BlobTransactionReport {
+        let versioned_hashes: Vec<_> = versioned_hashes.iter().map(|hash| hash.0).collect();
+        self.validate_blob_transaction(&versioned_hashes, sidecar)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn conversions_keep_the_bytes() {
+        let mut commitment = [0u8; BYTES_PER_COMMITMENT];
+        commitment[0] = 0xc0;
+
+        let alloy = commitment.to_alloy();
+        assert_eq!(alloy.0, commitment);
+        assert_eq!(as_refs(&[alloy, alloy]), vec![&commitment, &commitment]);
+        assert_eq!(versioned_hash(&alloy).0, kzg_to_versioned_hash(&commitment));
+
+        let proofs = [commitment; 3];
+        assert_eq!(proofs.to_alloy(), vec![alloy; 3]);
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn alloy_values_are_accepted() {
+        use crate::generators;
+
+        let ctx = DASContext::default();
+        let blobs: Vec<Blob> = (0..2)
+            .map(|seed| FixedBytes(*generators::blob(seed)))
+            .collect();
+        let commitments: Vec<Bytes48> = blobs
+            .iter()
+            .map(|blob| ctx.blob_to_kzg_commitment(blob).unwrap().to_alloy())
+            .collect();
+        let proofs: Vec<Bytes48> = blobs
+            .iter()
+            .zip(&commitments)
+            .map(|(blob, commitment)| {
+                ctx.compute_blob_kzg_proof(blob, commitment)
+                    .unwrap()
+                    .to_alloy()
+            })
+            .collect();
+        ctx.verify_blob_kzg_proof_batch(as_refs(&blobs), as_refs(&commitments), as_refs(&proofs))
+            .unwrap();
+
+        let hashes: Vec<B256> = commitments.iter().map(versioned_hash).collect();
+        let sidecar = BlobTransactionSidecar::from_alloy_blob_proofs(&blobs, &commitments, &proofs);
+        assert!(ctx
+            .validate_alloy_blob_transaction(&hashes, &sidecar)
+            .is_valid());
+    }
+}
@@ -0,0 +1,240 @@
+//! Async wrappers around [`DASContext`], enabled with the `tokio` feature.
+use std::sync::{
+    atomic::{AtomicBool, Ordering},
+    Arc,
+};
+
+use crate::{
+    constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
+    Cell, CellIndex, DASContext, Error, KZGCommitment, KZGProof, SerializedScalar,
+};
+
+/// An owned blob, used by the async methods since their inputs must outlive the caller.
+pub type OwnedBlob = Box<[u8; BYTES_PER_BLOB]>;
+
+/// An owned commitment or proof, see [`Bytes48Ref`](crate::Bytes48Ref).
+pub type OwnedBytes48 = [u8; 48];
+
+/// A [`DASContext`] whose methods can be awaited from async code.
+///
+/// Each method runs the computation with [`tokio::task::spawn_blocking`], so it never blocks
+/// the async executor. The parallel work inside of each call still runs on the thread pool
+/// of the context, which can be set with `DASContext::with_thread_pool`.
+///
+/// Dropping the returned future before the computation has started cancels it. Computations
+/// that have already started always run to completion, and their result is discarded.
+#[derive(Debug, Clone)]
+pub struct AsyncDASContext {
+    inner: Arc<DASContext>,
+}
+
+impl From<DASContext> for AsyncDASContext {
+    fn from(ctx: DASContext) -> Self {
+        Self::new(Arc::new(ctx))
+    }
+}
+
+/// Sets the cancellation flag when the future awaiting a computation is dropped.
+struct CancelOnDrop(Arc<AtomicBool>);
+
+impl Drop for CancelOnDrop {
+    fn drop(&mut self) {
+        self.0.store(true, Ordering::Release);
+    }
+}
+
+impl AsyncDASContext {
+    /// Wraps a shared context.
+    pub const fn new(inner: Arc<DASContext>) -> Self {
+        Self { inner }
+    }
+
+    /// Returns the underlying context, for calling its methods synchronously.
+    pub fn inner(&self) -> &Arc<DASContext> {
+        &self.inner
+    }
+
+    /// Runs `op` on the blocking thread pool of the runtime.
+    async fn run<R: Send + 'static>(
+        &self,
+        op: impl FnOnce(&DASContext) -> Result<R, Error> + Send + 'static,
+    ) -> Result<R, Error> {
+        let ctx = Arc::clone(&self.inner);
+        let cancelled = Arc::new(AtomicBool::new(false));
+        let _cancel_on_drop = CancelOnDrop(Arc::clone(&cancelled));
+
+        let handle = tokio::task::spawn_blocking(move || {
+            if cancelled.load(Ordering::Acquire) {
+                return Err(Error::Cancelled);
+            }
+            op(ctx.as_ref())
+        });
+
+        match handle.await {
+            Ok(result) => result,
+            Err(err) if err.is_panic() => std::panic::resume_unwind(err.into_panic()),
+            Err(_) => Err(Error::Cancelled),
+        }
+    }
+
+    /// See [`DASContext::blob_to_kzg_commitment`].
+    pub async fn blob_to_kzg_commitment(&self, blob: OwnedBlob) -> Result<KZGCommitment, Error> {
+        self.run(move |ctx| ctx.blob_to_kzg_commitment(&blob)).await
+    }
+
+    /// See [`DASContext::compute_cells_and_kzg_proofs`].
+    pub async fn compute_cells_and_kzg_proofs(
+        &self,
+        blob: OwnedBlob,
+    ) -> Result<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB]), Error> {
+        self.run(move |ctx| ctx.compute_cells_and_kzg_proofs(&blob))
+            .await
+    }
+
+    /// See [`DASContext::compute_cells`].
+    pub async fn compute_cells(
+        &self,
+        blob: OwnedBlob,
+    ) -> Result<[Cell; CELLS_PER_EXT_BLOB], Error> {
+        self.run(move |ctx| ctx.compute_cells(&blob)).await
+    }
+
+    /// See [`DASContext::process_slot`].
+    #[allow(clippy::type_complexity)]
+    pub async fn process_slot(
+        &self,
+        blobs: Vec<OwnedBlob>,
+    ) -> Result<
+        Vec<(
+            KZGCommitment,
+            [Cell; CELLS_PER_EXT_BLOB],
+            [KZGProof; CELLS_PER_EXT_BLOB],
+        )>,
+        Error,
+    > {
+        self.run(move |ctx| {
+            let blobs: Vec<_> = blobs.iter().map(AsRef::as_ref).collect();
+            ctx.process_slot(&blobs)
+        })
+        .await
+    }
+
+    /// See [`DASContext::recover_cells_and_kzg_proofs`].
+    pub async fn recover_cells_and_kzg_proofs(
+        &self,
+        cell_indices: Vec<CellIndex>,
+        cells: Vec<Cell>,
+    ) -> Result<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB]), Error> {
+        self.run(move |ctx| {
+            ctx.recover_cells_and_kzg_proofs(
+                cell_indices,
+                cells.iter().map(AsRef::as_ref).collect(),
+            )
+        })
+        .await
+    }
+
+    /// See [`DASContext::verify_cell_kzg_proof_batch`].
+    pub async fn verify_cell_kzg_proof_batch(
+        &self,
+        commitments: Vec<OwnedBytes48>,
+        cell_indices: Vec<CellIndex>,
+        cells: Vec<Cell>,
+        proofs: Vec<OwnedBytes48>,
+    ) -> Result<(), Error> {
+        self.run(move |ctx| {
+            ctx.verify_cell_kzg_proof_batch(
+                commitments.iter().collect(),
+                &cell_indices,
+                cells.iter().map(AsRef::as_ref).collect(),
+                proofs.iter().collect(),
+            )
+        })
+        .await
+    }
+
+    /// See [`DASContext::compute_kzg_proof`].
+    pub async fn compute_kzg_proof(
+        &self,
+        blob: OwnedBlob,
+        z: SerializedScalar,
+    ) -> Result<(KZGProof, SerializedScalar), Error> {
+        self.run(move |ctx| ctx.compute_kzg_proof(&blob, z)).await
+    }
+
+    /// See [`DASContext::compute_blob_kzg_proof`].
+    pub async fn compute_blob_kzg_proof(
+        &self,
+        blob: OwnedBlob,
+        commitment: OwnedBytes48,
+    ) -> Result<KZGProof, Error> {
+        self.run(move |ctx| ctx.compute_blob_kzg_proof(&blob, &commitment))
+            .await
+    }
+
+    /// See [`DASContext::verify_kzg_proof`].
+    pub async fn verify_kzg_proof(
+        &self,
+        commitment: OwnedBytes48,
+        z: SerializedScalar,
+        y: SerializedScalar,
+        proof: OwnedBytes48,
+    ) -> Result<(), Error> {
+        self.run(move |ctx| ctx.verify_kzg_proof(&commitment, z, y, &proof))
+            .await
+    }
+
+    /// See [`DASContext::verify_blob_kzg_proof`].
+    pub async fn verify_blob_kzg_proof(
+        &self,
+        blob: OwnedBlob,
+        commitment: OwnedBytes48,
+        proof: OwnedBytes48,
+    ) -> Result<(), Error> {
+        self.run(move |ctx| ctx.verify_blob_kzg_proof(&blob, &commitment, &proof))
+            .await
+    }
+
+    /// See [`DASContext::verify_blob_kzg_proof_batch`].
+    pub async fn verify_blob_kzg_proof_batch(
+        &self,
+        blobs: Vec<OwnedBlob>,
+        commitments: Vec<OwnedBytes48>,
+        proofs: Vec<OwnedBytes48>,
+    ) -> Result<(), Error> {
+        self.run(move |ctx| {
+            ctx.verify_blob_kzg_proof_batch(
+                blobs.iter().map(AsRef::as_ref).collect(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            )
+        })
+        .await
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use super::AsyncDASContext;
+    use crate::{constants::BYTES_PER_BLOB, DASContext};
+
+    #[tokio::test]
+    async fn async_methods_match_sync_methods() {
+        let ctx = AsyncDASContext::from(DASContext::default());
+        let blob = Box::new([0u8; BYTES_PER_BLOB]);
+
+        let commitment = ctx.blob_to_kzg_commitment(blob.clone()).await.unwrap();
+        assert_eq!(
+            commitment,
+            ctx.inner().blob_to_kzg_commitment(&blob).unwrap()
+        );
+
+        let proof = ctx
+            .compute_blob_kzg_proof(blob.clone(), commitment)
+            .await
+            .unwrap();
+        ctx.verify_blob_kzg_proof(blob, commitment, proof)
+            .await
+            .unwrap();
+    }
+}
@@ -0,0 +1,164 @@
+use std::time::{Duration, Instant};
+
+use bls12_381::{
+    fixed_base_msm::{FixedBaseMSM, UsePrecomp},
+    g1_batch_normalize,
+    traits::*,
+    G1Point, G1Projective, Scalar,
+};
+
+use crate::{
+    constants::{
+        CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_BLOB, FIELD_ELEMENTS_PER_CELL,
+        FIELD_ELEMENTS_PER_EXT_BLOB,
+    },
+    DASContext, TrustedSetup,
+};
+
+/// The precomputation widths that are tried by [`HostProfile::measure`].
+///
+/// The tables double in size with each increment of the width, so only even widths
+/// up to the recommended one are considered.
+const CANDIDATE_PRECOMP_WIDTHS: [usize; 3] = [4, 6, 8];
+
+/// The number of generators used to time each MSM.
+///
+/// The FK20 prover does one fixed-base MSM per cell of the extended blob, each over one
+/// generator per field element of a cell, so this times MSMs of exactly that size.
+const NUM_GENERATORS: usize = FIELD_ELEMENTS_PER_CELL;
+
+/// The number of MSMs that are timed together, as many as the FK20 prover does per blob.
+///
+/// They all use the same table, so that building the tables for the largest width stays
+/// in the tens of milliseconds, while the prover has a table per MSM.
+const NUM_MSMS: usize = CELLS_PER_EXT_BLOB;
+
+/// The sizes of the field element FFTs done per cell and per blob, which are the sizes
+/// timed to choose the FFT threshold.
+const FFT_SIZES: [usize; 3] = [
+    FIELD_ELEMENTS_PER_CELL,
+    FIELD_ELEMENTS_PER_BLOB,
+    FIELD_ELEMENTS_PER_EXT_BLOB,
+];
+
+/// A larger width is only chosen if it makes the MSMs at least this much faster,
+/// expressed as a percentage of the time taken with the smaller width.
+const MIN_SPEEDUP_PERCENT: u128 = 10;
+
+/// The parameters chosen for this host by timing MSMs and FFTs on it.
+///
+/// The default constants of the library were tuned on a single desktop machine. Hosts
+/// with smaller caches or fewer cores may be faster with a smaller precomputation width
+/// or with FFTs that are split across threads later, which this measures instead of guessing.
+#[derive(Debug, Clone, Copy)]
+pub struct HostProfile {
+    /// The precomputation width for the FK20 prover.
+    pub precomp: UsePrecomp,
+    /// The minimum number of field elements for which the FFT layers are split across threads.
+    pub parallel_fft_threshold: usize,
+}
+
+impl HostProfile {
+    /// Times MSMs for each candidate precomputation width and FFTs of the sizes used by
+    /// the prover on the host, using the global thread pool.
+    ///
+    /// This takes in the order of a hundred milliseconds, which is small compared to
+    /// building the precomputations it chooses the width of.
+    pub fn measure() -> Self {
+        Self {
+            precomp: measure_precomp(),
+            parallel_fft_threshold: polynomial::calibrate_parallel_fft_threshold(&FFT_SIZES),
+        }
+    }
+}
+
+/// Returns the smallest width whose MSMs are not beaten by a larger width by more than
+/// [`MIN_SPEEDUP_PERCENT`], since the memory used by the tables doubles with each increment.
+fn measure_precomp() -> UsePrecomp {
+    let generators: Vec<_> = (1..=NUM_GENERATORS as u64)
+        .map(|i| G1Projective::generator() * Scalar::from(i))
+        .collect();
+    let generators = g1_batch_normalize(&generators);
+    let scalars: Vec<Vec<_>> = (0..NUM_MSMS as u64)
+        .map(|msm| {
+            (1..=NUM_GENERATORS as u64)
+                .map(|i| Scalar::ROOT_OF_UNITY.pow_vartime([msm]) * Scalar::from(i))
+                .collect()
+        })
+        .collect();
+
+    let mut best = (
+        UsePrecomp::No,
+        time_msms(&generators, &scalars, UsePrecomp::No),
+    );
+    for width in CANDIDATE_PRECOMP_WIDTHS {
+        let precomp = UsePrecomp::Yes { width };
+        let elapsed = time_msms(&generators, &scalars, precomp);
+        if elapsed.as_nanos() * 100 < best.1.as_nanos() * (100 - MIN_SPEEDUP_PERCENT) {
+            best = (precomp, elapsed);
+        }
+    }
+    best.0
+}
+
+/// Returns the fastest of a few runs of the MSMs of `scalars` with a table built with
+/// `use_precomp`.
+fn time_msms(generators: &[G1Point], scalars: &[Vec<Scalar>], use_precomp: UsePrecomp) -> Duration {
+    const RUNS: usize = 3;
+
+    let msm = FixedBaseMSM::new(generators.to_vec(), use_precomp);
+    (0..RUNS)
+        .map(|_| {
+            let start = Instant::now();
+            for scalars in scalars {
+                std::hint::black_box(msm.msm(scalars));
+            }
+            start.elapsed()
+        })
+        .min()
+        .unwrap_or(Duration::MAX)
+}
+
+impl DASContext {
+    /// Creates a new DASContext with the precomputation width and FFT threshold chosen
+    /// by timing a few operations on this host, see [`HostProfile::measure`].
+    ///
+    /// The FFT threshold is set on the threads of a dedicated pool with one thread per CPU,
+    /// see [`crate::DASContextBuilder::parallel_fft_threshold`], so it only applies to the
+    /// returned context, and is dropped if its thread pool is replaced. The chosen
+    /// parameters are returned, so that they can be logged or passed to the builder on the
+    /// next start without measuring again.
+    ///
+    /// # Panics
+    ///
+    /// Panics if the thread pool could not be created, or if the trusted setup has fewer
+    /// points than a blob.
+    pub fn new_autotuned(trusted_setup: &TrustedSetup) -> (Self, HostProfile) {
+        let profile = HostProfile::measure();
+        let builder = Self::builder()
+            .trusted_setup(trusted_setup)
+            .precompute(profile.precomp);
+        #[cfg(feature = "multithreaded")]
+        let builder = builder.parallel_fft_threshold(profile.parallel_fft_threshold);
+        let ctx = builder
+            .build()
+            .expect("trusted setup should have enough points and the pool should start");
+        (ctx, profile)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::{HostProfile, CANDIDATE_PRECOMP_WIDTHS, FFT_SIZES};
+
+    #[test]
+    fn measured_profile_uses_candidate_values() {
+        let profile = HostProfile::measure();
+        let width = profile.precomp.width();
+        assert!(width == 0 || CANDIDATE_PRECOMP_WIDTHS.contains(&width));
+        assert!(
+            profile.parallel_fft_threshold == usize::MAX
+                || FFT_SIZES.contains(&profile.parallel_fft_threshold)
+        );
+    }
+}
@@ -0,0 +1,450 @@
+//! The parts of the beacon API and engine API payloads that carry blobs, cells and proofs,
+//! with serde support and methods that check their proofs.
+//!
+//! An indexer can deserialize a response and check it in two lines:
+//!
+//! ```ignore
+//! let response: BlobSidecarsResponse = serde_json::from_str(&body)?;
+//! response.verify(&ctx)?;
+//! ```
+//!
+//! Only the fields that the proofs are checked with are kept. The other fields, such as the
+//! signed block header and the inclusion proofs, are ignored when deserializing, so checking
+//! that the commitments are those of a block is left to the caller. As in the APIs, bytes are
+//! encoded as `0x`-prefixed hex strings and the indices as decimal strings.
+
+use serde::{Deserialize, Deserializer, Serialize, Serializer};
+use serde_json::Value;
+
+use crate::{
+    constants::BYTES_PER_BLOB, Bytes48Ref, Cell, CellIndex, DASContext, Error, KZGCommitment,
+    KZGProof, PrefixedHex,
+};
+
+/// The envelope of the responses of the beacon API, ie `{ "version": ..., "data": ... }`.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BeaconResponse<T> {
+    /// The fork of the data, if the endpoint reports it.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub version: Option<String>,
+    pub data: T,
+}
+
+/// The response of `/eth/v1/beacon/blob_sidecars/{block_id}`.
+pub type BlobSidecarsResponse = BeaconResponse<Vec<BlobSidecar>>;
+
+/// The response of `/eth/v1/debug/beacon/data_column_sidecars/{block_id}`.
+pub type DataColumnSidecarsResponse = BeaconResponse<Vec<DataColumnSidecar>>;
+
+/// A `BlobSidecar` of the beacon API, without its header and inclusion proof.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BlobSidecar {
+    #[serde(with = "quoted_u64")]
+    pub index: u64,
+    #[serde(with = "serialization::serde::blob")]
+    pub blob: Box<[u8; BYTES_PER_BLOB]>,
+    #[serde(with = "serialization::serde::bytes48")]
+    pub kzg_commitment: KZGCommitment,
+    #[serde(with = "serialization::serde::bytes48")]
+    pub kzg_proof: KZGProof,
+}
+
+impl BlobSidecar {
+    /// Checks the proof of the blob against its commitment.
+    pub fn verify(&self, ctx: &DASContext) -> Result<(), Error> {
+        ctx.verify_blob_kzg_proof(&self.blob, &self.kzg_commitment, &self.kzg_proof)
+    }
+}
+
+impl BlobSidecarsResponse {
+    /// Checks the proofs of every blob sidecar of the response in a single batch.
+    pub fn verify(&self, ctx: &DASContext) -> Result<(), Error> {
+        ctx.verify_blob_kzg_proof_batch(
+            self.data.iter().map(|sidecar| &*sidecar.blob).collect(),
+            self.data
+                .iter()
+                .map(|sidecar| &sidecar.kzg_commitment)
+                .collect(),
+            self.data.iter().map(|sidecar| &sidecar.kzg_proof).collect(),
+        )
+    }
+}
+
+/// A `DataColumnSidecar` of the beacon API, without its header and inclusion proof.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct DataColumnSidecar {
+    #[serde(with = "quoted_u64")]
+    pub index: CellIndex,
+    /// The cell at `index` of every blob of the block.
+    #[serde(with = "cells")]
+    pub column: Vec<Cell>,
+    #[serde(with = "bytes48_list")]
+    pub kzg_commitments: Vec<KZGCommitment>,
+    #[serde(with = "bytes48_list")]
+    pub kzg_proofs: Vec<KZGProof>,
+}
+
+impl DataColumnSidecar {
+    /// Checks the proof of every cell of the column against the commitment of its blob.
+    pub fn verify(&self, ctx: &DASContext) -> Result<(), Error> {
+        ctx.verify_cell_kzg_proof_batch(
+            self.kzg_commitments.iter().collect(),
+            &vec![self.index; self.column.len()],
+            self.column.iter().map(|cell| &**cell).collect(),
+            self.kzg_proofs.iter().collect(),
+        )
+    }
+}
+
+impl DataColumnSidecarsResponse {
+    /// Checks the proofs of every cell of every data column sidecar of the response in a
+    /// single batch.
+    pub fn verify(&self, ctx: &DASContext) -> Result<(), Error> {
+        let mut commitments = Vec::new();
+        let mut cell_indices = Vec::new();
+        let mut cells = Vec::new();
+        let mut proofs = Vec::new();
+        for sidecar in &self.data {
+            // A sidecar whose lists have different lengths is checked on its own, so that the
+            // error is reported for it, instead of shifting the cells of the next sidecars.
+            if sidecar.kzg_commitments.len() != sidecar.column.len()
+                || sidecar.kzg_proofs.len() != sidecar.column.len()
+            {
+                return sidecar.verify(ctx);
+            }
+            commitments.extend(&sidecar.kzg_commitments);
+            cell_indices.extend(std::iter::repeat_n(sidecar.index, sidecar.column.len()));
+            cells.extend(sidecar.column.iter().map(|cell| &**cell));
+            proofs.extend(&sidecar.kzg_proofs);
+        }
+        ctx.verify_cell_kzg_proof_batch(commitments, &cell_indices, cells, proofs)
+    }
+}
+
+/// A `BlobAndProofV1` returned by `engine_getBlobsV1`.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BlobAndProofV1 {
+    #[serde(with = "serialization::serde::blob")]
+    pub blob: Box<[u8; BYTES_PER_BLOB]>,
+    #[serde(with = "serialization::serde::bytes48")]
+    pub proof: KZGProof,
+}
+
+impl BlobAndProofV1 {
+    /// Checks the blob proof against `commitment`, the commitment whose versioned hash the
+    /// blob was requested with.
+    pub fn verify(&self, ctx: &DASContext, commitment: Bytes48Ref) -> Result<(), Error> {
+        ctx.verify_blob_kzg_proof(&self.blob, commitment, &self.proof)
+    }
+}
+
+/// A `BlobAndProofV2` returned by `engine_getBlobsV2`.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BlobAndProofV2 {
+    #[serde(with = "serialization::serde::blob")]
+    pub blob: Box<[u8; BYTES_PER_BLOB]>,
+    /// The `CELLS_PER_EXT_BLOB` cell proofs of the blob.
+    #[serde(with = "bytes48_list")]
+    pub proofs: Vec<KZGProof>,
+}
+
+impl BlobAndProofV2 {
+    /// Checks the cell proofs against `commitment`, the commitment whose versioned hash the
+    /// blob was requested with.
+    ///
+    /// The cells of the blob are computed to check the proofs, which needs the prover tables,
+    /// see [`DASContext::compute_columns_from_blobs_and_cell_proofs`].
+    pub fn verify(&self, ctx: &DASContext, commitment: Bytes48Ref) -> Result<(), Error> {
+        let proofs: Vec<_> = self.proofs.iter().collect();
+        ctx.compute_and_verify_cells(vec![&*self.blob], &[commitment], &proofs)
+            .map(drop)
+    }
+}
+
+/// A JSON-RPC request, as sent to the engine API.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct JsonRpcRequest {
+    pub jsonrpc: String,
+    pub id: Value,
+    pub method: String,
+    pub params: Value,
+}
+
+impl JsonRpcRequest {
+    /// Returns an `engine_getBlobsV1` request for the blobs with `versioned_hashes`.
+    ///
+    /// The result is a [`JsonRpcResponse<Vec<Option<BlobAndProofV1>>>`], with `null` for
+    /// each blob that the execution client does not have.
+    pub fn get_blobs_v1(id: impl Into<Value>, versioned_hashes: &[[u8; 32]]) -> Self {
+        Self::get_blobs("engine_getBlobsV1", id.into(), versioned_hashes)
+    }
+
+    /// Returns an `engine_getBlobsV2` request for the blobs with `versioned_hashes`.
+    ///
+    /// The result is a [`JsonRpcResponse<Vec<BlobAndProofV2>>`], and is `null` if the
+    /// execution client does not have all of the blobs.
+    pub fn get_blobs_v2(id: impl Into<Value>, versioned_hashes: &[[u8; 32]]) -> Self {
+        Self::get_blobs("engine_getBlobsV2", id.into(), versioned_hashes)
+    }
+
+    fn get_blobs(method: &str, id: Value, versioned_hashes: &[[u8; 32]]) -> Self {
+        let hashes: Vec<_> = versioned_hashes.iter().map(PrefixedHex::to_hex).collect();
+        Self {
+            jsonrpc: "2.0".to_owned(),
+            id,
+            method: method.to_owned(),
+            params: Value::Array(vec![hashes.into()]),
+        }
+    }
+}
+
+/// A JSON-RPC response, as returned by the engine API.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct JsonRpcResponse<T> {
+    pub jsonrpc: String,
+    pub id: Value,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub result: Option<T>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub error: Option<JsonRpcError>,
+}
+
+impl<T> JsonRpcResponse<T> {
+    /// Returns the result of the call, which is `None` if it was `null`, or its error.
+    pub fn into_result(self) -> Result<Option<T>, JsonRpcError> {
+        match self.error {
+            Some(error) => Err(error),
+            None => Ok(self.result),
+        }
+    }
+}
+
+/// The error of a failed JSON-RPC call.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct JsonRpcError {
+    pub code: i64,
+    pub message: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub data: Option<Value>,
+}
+
+/// Serde support for a `u64` that is encoded as a decimal string, as the beacon API does.
+///
+/// Plain numbers are accepted too.
+mod quoted_u64 {
+    use std::fmt;
+
+    use serde::de::{self, Visitor};
+
+    use super::*;
+
+    // Serde passes the fields to `serialize` by reference
+    #[allow(clippy::trivially_copy_pass_by_ref)]
+    pub fn serialize<S: Serializer>(value: &u64, serializer: S) -> Result<S::Ok, S::Error> {
+        serializer.collect_str(value)
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(deserializer: D) -> Result<u64, D::Error> {
+        deserializer.deserialize_any(QuotedU64Visitor)
+    }
+
+    struct QuotedU64Visitor;
+
+    impl Visitor<'_> for QuotedU64Visitor {
+        type Value = u64;
+
+        fn expecting(&self, formatter: &mut fmt::Formatter<'_>) -> fmt::Result {
+            formatter.write_str("an unsigned integer, or a string that holds one")
+        }
+
+        fn visit_u64<E: de::Error>(self, value: u64) -> Result<u64, E> {
+            Ok(value)
+        }
+
+        fn visit_str<E: de::Error>(self, value: &str) -> Result<u64, E> {
+            value.parse().map_err(E::custom)
+        }
+    }
+}
+
+/// Serde support for a list of cells.
+mod cells {
+    use super::*;
+
+    struct CellRef<'a>(&'a Cell);
+
+    impl Serialize for CellRef<'_> {
+        fn serialize<S: Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+            serialization::serde::cell::serialize(self.0, serializer)
+        }
+    }
+
+    struct OwnedCell(Cell);
+
+    impl<'de> Deserialize<'de> for OwnedCell {
+        fn deserialize<D: Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+            serialization::serde::cell::deserialize(deserializer).map(Self)
+        }
+    }
+
+    pub fn serialize<S: Serializer>(cells: &[Cell], serializer: S) -> Result<S::Ok, S::Error> {
+        serializer.collect_seq(cells.iter().map(CellRef))
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(deserializer: D) -> Result<Vec<Cell>, D::Error> {
+        let cells = Vec::<OwnedCell>::deserialize(deserializer)?;
+        Ok(cells.into_iter().map(|cell| cell.0).collect())
+    }
+}
+
+/// Serde support for a list of commitments or proofs.
+mod bytes48_list {
+    use super::*;
+
+    struct Bytes48<'a>(&'a [u8; 48]);
+
+    impl Serialize for Bytes48<'_> {
+        fn serialize<S: Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+            serialization::serde::bytes48::serialize(self.0, serializer)
+        }
+    }
+
+    struct OwnedBytes48([u8; 48]);
+
+    impl<'de> Deserialize<'de> for OwnedBytes48 {
+        fn deserialize<D: Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+            serialization::serde::bytes48::deserialize(deserializer).map(Self)
+        }
+    }
+
+    pub fn serialize<S: Serializer>(values: &[[u8; 48]], serializer: S) -> Result<S::Ok, S::Error> {
+        serializer.collect_seq(values.iter().map(Bytes48))
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(
+        deserializer: D,
+    ) -> Result<Vec<[u8; 48]>, D::Error> {
+        let values = Vec::<OwnedBytes48>::deserialize(deserializer)?;
+        Ok(values.into_iter().map(|value| value.0).collect())
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use serde_json::json;
+
+    use super::*;
+    use crate::{constants::CELLS_PER_EXT_BLOB, generators, kzg_to_versioned_hash};
+
+    #[test]
+    fn blob_sidecars_are_deserialized_and_verified() {
+        let ctx = DASContext::default();
+        let blob = generators::blob(1);
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let proof = ctx.compute_blob_kzg_proof(&blob, &commitment).unwrap();
+
+        let body = json!({
+            "version": "deneb",
+            "execution_optimistic": false,
+            "data": [{
+                "index": "3",
+                "blob": blob.to_hex(),
+                "kzg_commitment": commitment.to_hex(),
+                "kzg_proof": proof.to_hex(),
+                "signed_block_header": { "signature": "0x00" },
+                "kzg_commitment_inclusion_proof": [],
+            }],
+        })
+        .to_string();
+        let mut response: BlobSidecarsResponse = serde_json::from_str(&body).unwrap();
+        assert_eq!(response.data[0].index, 3);
+        response.verify(&ctx).unwrap();
+
+        let json = serde_json::to_value(&response).unwrap();
+        assert_eq!(json["data"][0]["index"], "3");
+
+        response.data[0].kzg_proof = commitment;
+        assert!(response.verify(&ctx).unwrap_err().is_proof_invalid());
+    }
+
+    #[test]
+    fn data_column_sidecars_are_verified() {
+        let ctx = DASContext::default();
+        let blobs: Vec<_> = (0..2).map(generators::blob).collect();
+        let commitments: Vec<_> = blobs
+            .iter()
+            .map(|blob| ctx.blob_to_kzg_commitment(blob).unwrap())
+            .collect();
+        let (cells, proofs): (Vec<_>, Vec<_>) = blobs
+            .iter()
+            .map(|blob| ctx.compute_cells_and_kzg_proofs(blob).unwrap())
+            .unzip();
+        let sidecar = |index: usize| DataColumnSidecar {
+            index: index as CellIndex,
+            column: cells.iter().map(|row| row[index].clone()).collect(),
+            kzg_commitments: commitments.clone(),
+            kzg_proofs: proofs.iter().map(|row| row[index]).collect(),
+        };
+
+        let response = DataColumnSidecarsResponse {
+            version: Some("fulu".to_owned()),
+            data: vec![sidecar(5), sidecar(CELLS_PER_EXT_BLOB - 1)],
+        };
+        let json = serde_json::to_string(&response).unwrap();
+        let mut response: DataColumnSidecarsResponse = serde_json::from_str(&json).unwrap();
+        response.verify(&ctx).unwrap();
+        response.data[1].verify(&ctx).unwrap();
+
+        response.data[1].index = 6;
+        assert!(response.verify(&ctx).unwrap_err().is_proof_invalid());
+        response.data[1].column.pop();
+        assert!(!response.verify(&ctx).unwrap_err().is_proof_invalid());
+    }
+
+    #[test]
+    fn engine_api_blobs_are_verified() {
+        let ctx = DASContext::default();
+        let blob = generators::blob(2);
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let hash = kzg_to_versioned_hash(&commitment);
+
+        let request = JsonRpcRequest::get_blobs_v2(1, &[hash]);
+        assert_eq!(
+            serde_json::to_value(&request).unwrap(),
+            json!({
+                "jsonrpc": "2.0",
+                "id": 1,
+                "method": "engine_getBlobsV2",
+                "params": [[hash.to_hex()]],
+            })
+        );
+
+        let (_, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+        let body = json!({
+            "jsonrpc": "2.0",
+            "id": 1,
+            "result": [{
+                "blob": blob.to_hex(),
+                "proofs": proofs.iter().map(PrefixedHex::to_hex).collect::<Vec<_>>(),
+            }],
+        })
+        .to_string();
+        let response: JsonRpcResponse<Vec<BlobAndProofV2>> = serde_json::from_str(&body).unwrap();
+        let blobs = response.into_result().unwrap().unwrap();
+        blobs[0].verify(&ctx, &commitment).unwrap();
+
+        let proof = ctx.compute_blob_kzg_proof(&blob, &commitment).unwrap();
+        let v1 = BlobAndProofV1 { blob, proof };
+        v1.verify(&ctx, &commitment).unwrap();
+
+        // The execution client does not have every blob
+        let body = r#"{"jsonrpc":"2.0","id":1,"result":null}"#;
+        let response: JsonRpcResponse<Vec<BlobAndProofV2>> = serde_json::from_str(body).unwrap();
+        assert_eq!(response.into_result().unwrap(), None);
+
+        let body =
+            r#"{"jsonrpc":"2.0","id":1,"error":{"code":-38001,"message":"Unknown payload"}}"#;
+        let response: JsonRpcResponse<Vec<BlobAndProofV2>> = serde_json::from_str(body).unwrap();
+        assert_eq!(response.into_result().unwrap_err().code, -38001);
+    }
+}
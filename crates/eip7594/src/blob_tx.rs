@@ -0,0 +1,198 @@
+//! Validation of the sidecar of a blob transaction, as an execution client does before
+//! admitting the transaction to its mempool.
+
+pub use eip4844::{kzg_to_versioned_hash, VERSIONED_HASH_VERSION_KZG};
+
+use crate::{constants::CELLS_PER_EXT_BLOB, BlobRef, Bytes48Ref, DASContext, ErrorCode};
+
+/// The proofs of a blob transaction sidecar, whose kind depends on the fork.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum SidecarProofs<'a> {
+    /// One blob proof per blob, as in the sidecars of EIP-4844.
+    Blob(Vec<Bytes48Ref<'a>>),
+    /// `CELLS_PER_EXT_BLOB` cell proofs per blob, one blob after the other, as in the
+    /// version 1 sidecars of EIP-7594.
+    Cell(Vec<Bytes48Ref<'a>>),
+}
+
+impl<'a> SidecarProofs<'a> {
+    /// Returns the number of proofs that a sidecar with `num_blobs` blobs needs.
+    const fn expected_len(&self, num_blobs: usize) -> usize {
+        match self {
+            Self::Blob(_) => num_blobs,
+            Self::Cell(_) => num_blobs * CELLS_PER_EXT_BLOB,
+        }
+    }
+
+    /// Returns the proofs, whatever their kind.
+    fn proofs(&self) -> &[Bytes48Ref<'a>] {
+        match self {
+            Self::Blob(proofs) | Self::Cell(proofs) => proofs,
+        }
+    }
+}
+
+/// The network form of the sidecar of a blob transaction.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct BlobTransactionSidecar<'a> {
+    pub blobs: Vec<BlobRef<'a>>,
+    pub commitments: Vec<Bytes48Ref<'a>>,
+    pub proofs: SidecarProofs<'a>,
+}
+
+/// The outcome of checking the proofs of a sidecar.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+pub enum ProofCheck {
+    /// The proofs were not checked, because the sidecar failed a cheaper check first.
+    Skipped,
+    /// Every proof verified.
+    Valid,
+    /// The proofs were rejected, for the given reason.
+    Invalid(ErrorCode),
+}
+
+/// What [`DASContext::validate_blob_transaction`] found wrong with a sidecar, if anything.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct BlobTransactionReport {
+    /// Whether the transaction has at least one blob, one blob and commitment per versioned
+    /// hash, and as many proofs as its kind of sidecar needs.
+    pub lengths_match: bool,
+    /// The indices of the versioned hashes that are not the versioned hash of the
+    /// commitment at the same index.
+    pub mismatched_versioned_hashes: Vec<usize>,
+    pub proofs: ProofCheck,
+}
+
+impl BlobTransactionReport {
+    /// Returns true if the sidecar passed every check.
+    pub fn is_valid(&self) -> bool {
+        self.lengths_match
+            && self.mismatched_versioned_hashes.is_empty()
+            && self.proofs == ProofCheck::Valid
+    }
+}
+
+impl DASContext {
+    /// Checks the sidecar of a blob transaction against the versioned hashes of the
+    /// transaction.
+    ///
+    /// The checks run from the cheapest to the most expensive, and each one only runs if the
+    /// ones before it passed: the lengths, then the versioned hashes, then the proofs. Blob
+    /// proofs are checked in a single batch. For cell proofs, the cells of every blob are
+    /// computed and checked in a single batch, which needs the prover tables, so a context
+    /// built with [`crate::DASContextBuilder::verifier_only`] reports
+    /// [`ErrorCode::VerifierOnlyContext`].
+    pub fn validate_blob_transaction(
+        &self,
+        versioned_hashes: &[[u8; 32]],
+        sidecar: &BlobTransactionSidecar,
+    ) -> BlobTransactionReport {
+        let num_blobs = versioned_hashes.len();
+        let lengths_match = num_blobs > 0
+            && sidecar.blobs.len() == num_blobs
+            && sidecar.commitments.len() == num_blobs
+            && sidecar.proofs.proofs().len() == sidecar.proofs.expected_len(num_blobs);
+        let mut report = BlobTransactionReport {
+            lengths_match,
+            mismatched_versioned_hashes: Vec::new(),
+            proofs: ProofCheck::Skipped,
+        };
+        if !lengths_match {
+            return report;
+        }
+
+        report.mismatched_versioned_hashes = versioned_hashes
+            .iter()
+            .zip(&sidecar.commitments)
+            .enumerate()
+            .filter(|(_, (hash, commitment))| kzg_to_versioned_hash(commitment) != **hash)
+            .map(|(index, _)| index)
+            .collect();
+        if !report.mismatched_versioned_hashes.is_empty() {
+            return report;
+        }
+
+        let result = match &sidecar.proofs {
+            SidecarProofs::Blob(proofs) => self.verify_blob_kzg_proof_batch(
+                sidecar.blobs.clone(),
+                sidecar.commitments.clone(),
+                proofs.clone(),
+            ),
+            SidecarProofs::Cell(proofs) => self
+                .compute_and_verify_cells(sidecar.blobs.clone(), &sidecar.commitments, proofs)
+                .map(drop),
+        };
+        report.proofs = match result {
+            Ok(()) => ProofCheck::Valid,
+            Err(err) => ProofCheck::Invalid(err.code()),
+        };
+        report
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::kzg_to_versioned_hash;
+    use crate::PrefixedHex;
+
+    #[test]
+    fn versioned_hashes_match_eip_4844() {
+        // The versioned hash of the commitment to the zero polynomial, ie the point at infinity.
+        let mut commitment = [0u8; 48];
+        commitment[0] = 0xc0;
+        assert_eq!(
+            kzg_to_versioned_hash(&commitment).to_hex(),
+            "0x010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c444014"
+        );
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn blob_transactions_are_validated() {
+        use super::{BlobTransactionSidecar, ProofCheck, SidecarProofs};
+        use crate::{generators, DASContext, ErrorCode};
+
+        let ctx = DASContext::default();
+        let blobs: Vec<_> = (0..2).map(generators::blob).collect();
+        let commitments: Vec<_> = blobs
+            .iter()
+            .map(|blob| ctx.blob_to_kzg_commitment(blob).unwrap())
+            .collect();
+        let blob_proofs: Vec<_> = blobs
+            .iter()
+            .zip(&commitments)
+            .map(|(blob, commitment)| ctx.compute_blob_kzg_proof(blob, commitment).unwrap())
+            .collect();
+        let cell_proofs: Vec<_> = blobs
+            .iter()
+            .flat_map(|blob| ctx.compute_cells_and_kzg_proofs(blob).unwrap().1)
+            .collect();
+        let mut hashes: Vec<_> = commitments.iter().map(kzg_to_versioned_hash).collect();
+        let sidecar = |proofs| BlobTransactionSidecar {
+            blobs: blobs.iter().map(|blob| &**blob).collect(),
+            commitments: commitments.iter().collect(),
+            proofs,
+        };
+
+        let deneb = sidecar(SidecarProofs::Blob(blob_proofs.iter().collect()));
+        let fulu = sidecar(SidecarProofs::Cell(cell_proofs.iter().collect()));
+        assert!(ctx.validate_blob_transaction(&hashes, &deneb).is_valid());
+        assert!(ctx.validate_blob_transaction(&hashes, &fulu).is_valid());
+
+        // Blob proofs in a sidecar that needs cell proofs have the wrong length
+        let wrong_kind = sidecar(SidecarProofs::Cell(blob_proofs.iter().collect()));
+        let report = ctx.validate_blob_transaction(&hashes, &wrong_kind);
+        assert!(!report.lengths_match);
+        assert_eq!(report.proofs, ProofCheck::Skipped);
+
+        let swapped = sidecar(SidecarProofs::Blob(blob_proofs.iter().rev().collect()));
+        let report = ctx.validate_blob_transaction(&hashes, &swapped);
+        assert_eq!(report.proofs, ProofCheck::Invalid(ErrorCode::InvalidProof));
+
+        hashes.swap(0, 1);
+        let report = ctx.validate_blob_transaction(&hashes, &deneb);
+        assert_eq!(report.mismatched_versioned_hashes, vec![0, 1]);
+        assert_eq!(report.proofs, ProofCheck::Skipped);
+        assert!(!report.is_valid());
+    }
+}
@@ -0,0 +1,520 @@
+use std::{
+    io,
+    path::{Path, PathBuf},
+    sync::Arc,
+};
+
+use bls12_381::fixed_base_msm::UsePrecomp;
+
+use crate::{
+    constants::{FIELD_ELEMENTS_PER_BLOB, FIELD_ELEMENTS_PER_CELL},
+    metrics::MetricsHook,
+    prover::ProverContext,
+    thread_pool::ThreadPool,
+    verifier::VerifierContext,
+    DASContext, Metrics, TrustedSetup, TrustedSetupError,
+};
+
+/// Errors returned by [`DASContextBuilder::build`].
+#[derive(Debug)]
+pub enum BuildError {
+    /// No trusted setup was given, and the embedded mainnet setup was dropped with the
+    /// `no-embedded-setup` feature.
+    MissingTrustedSetup,
+    /// The trusted setup has fewer points than the contexts are created from, or it failed
+    /// the check enabled with [`DASContextBuilder::verify_setup`].
+    TrustedSetup(TrustedSetupError),
+    /// The thread pool requested with [`DASContextBuilder::threads`] could not be created.
+    #[cfg(feature = "multithreaded")]
+    ThreadPool(rayon::ThreadPoolBuildError),
+    /// The file given to [`DASContextBuilder::precomputations_file`] could not be read.
+    Io(io::Error),
+    /// The width given to [`DASContextBuilder::precompute`] is larger than
+    /// [`UsePrecomp::MAX_WIDTH`].
+    PrecompWidth(usize),
+}
+
+impl From<TrustedSetupError> for BuildError {
+    fn from(value: TrustedSetupError) -> Self {
+        Self::TrustedSetup(value)
+    }
+}
+
+#[cfg(feature = "multithreaded")]
+impl From<rayon::ThreadPoolBuildError> for BuildError {
+    fn from(value: rayon::ThreadPoolBuildError) -> Self {
+        Self::ThreadPool(value)
+    }
+}
+
+impl From<io::Error> for BuildError {
+    fn from(value: io::Error) -> Self {
+        Self::Io(value)
+    }
+}
+
+/// Configures and creates a [`DASContext`].
+///
+/// Every option has a default, so `DASContext::builder().build()` creates the
+/// same context as [`DASContext::default`]:
+///
+/// ```ignore
+/// let ctx = DASContext::builder()
+///     .trusted_setup(&trusted_setup)
+///     .precompute(UsePrecomp::Yes { width: 8 })
+///     .threads(4)
+///     .build()?;
+/// ```
+///
+/// New options are added as methods on the builder, so code that uses it keeps
+/// compiling as the configuration grows.
+#[must_use]
+pub struct DASContextBuilder<'a> {
+    trusted_setup: Option<&'a TrustedSetup>,
+    precompute: UsePrecomp,
+    precomputations_file: Option<PathBuf>,
+    verify_setup: bool,
+    verifier_only: bool,
+    deterministic: bool,
+    #[cfg(feature = "multithreaded")]
+    threads: Option<usize>,
+    #[cfg(feature = "multithreaded")]
+    thread_pool: Option<Arc<rayon::ThreadPool>>,
+    #[cfg(feature = "multithreaded")]
+    parallel_fft_threshold: Option<usize>,
+    #[cfg(feature = "numa")]
+    numa_aware: bool,
+    metrics: Option<Arc<dyn Metrics>>,
+}
+
+impl Default for DASContextBuilder<'_> {
+    fn default() -> Self {
+        Self {
+            trusted_setup: None,
+            precompute: UsePrecomp::No,
+            precomputations_file: None,
+            verify_setup: false,
+            verifier_only: false,
+            deterministic: false,
+            #[cfg(feature = "multithreaded")]
+            threads: None,
+            #[cfg(feature = "multithreaded")]
+            thread_pool: None,
+            #[cfg(feature = "multithreaded")]
+            parallel_fft_threshold: None,
+            #[cfg(feature = "numa")]
+            numa_aware: false,
+            metrics: None,
+        }
+    }
+}
+
+impl<'a> DASContextBuilder<'a> {
+    /// Uses the given trusted setup, instead of the embedded mainnet setup.
+    ///
+    /// A setup must be given when the `no-embedded-setup` feature is enabled.
+    pub fn trusted_setup(mut self, trusted_setup: &'a TrustedSetup) -> Self {
+        self.trusted_setup = Some(trusted_setup);
+        self
+    }
+
+    /// Sets the prover-side precomputations, see [`DASContext::new`]. Defaults to `UsePrecomp::No`.
+    ///
+    /// [`DASContextBuilder::build`] returns an error if the width is larger than
+    /// [`UsePrecomp::MAX_WIDTH`].
+    pub fn precompute(mut self, use_precomp: UsePrecomp) -> Self {
+        self.precompute = use_precomp;
+        self
+    }
+
+    /// Loads the prover-side precomputations from the file at `path`, instead of computing
+    /// them, see [`DASContext::from_precomputations_file`].
+    ///
+    /// The width is the one the file was written with, so [`DASContextBuilder::precompute`]
+    /// is ignored when a file is given. With the `mmap` feature, the file must not be
+    /// modified while the context is built.
+    pub fn precomputations_file(mut self, path: impl AsRef<Path>) -> Self {
+        self.precomputations_file = Some(path.as_ref().to_path_buf());
+        self
+    }
+
+    /// Checks the trusted setup with [`TrustedSetup::verify`] before creating the context,
+    /// see [`DASContext::try_new`]. Defaults to false.
+    pub fn verify_setup(mut self, verify_setup: bool) -> Self {
+        self.verify_setup = verify_setup;
+        self
+    }
+
+    /// Skips creating the prover tables, which are most of the memory used by a context.
+    /// Defaults to false.
+    ///
+    /// Nodes that only verify cells, and never compute or recover them, can use this, as can
+    /// nodes that only need the EIP-4844 methods. The cell prover methods and
+    /// [`DASContext::recover_cells_and_kzg_proofs`] of a verifier-only context return
+    /// [`crate::ErrorCode::VerifierOnlyContext`], while the EIP-4844 methods, including
+    /// [`DASContext::blob_to_kzg_commitment`], keep working.
+    /// [`DASContextBuilder::precompute`] and [`DASContextBuilder::precomputations_file`]
+    /// are ignored.
+    pub fn verifier_only(mut self, verifier_only: bool) -> Self {
+        self.verifier_only = verifier_only;
+        self
+    }
+
+    /// Runs the methods of the context on a single thread, see [`DASContext::new_deterministic`].
+    /// Defaults to false.
+    ///
+    /// This takes precedence over [`DASContextBuilder::threads`] and
+    /// [`DASContextBuilder::thread_pool`].
+    pub fn deterministic(mut self, deterministic: bool) -> Self {
+        self.deterministic = deterministic;
+        self
+    }
+
+    /// Runs the methods of the context on a dedicated thread pool with `num_threads` threads,
+    /// see [`DASContext::with_num_threads`].
+    ///
+    /// By default, the global rayon thread pool is used.
+    #[cfg(feature = "multithreaded")]
+    pub fn threads(mut self, num_threads: usize) -> Self {
+        self.threads = Some(num_threads);
+        self
+    }
+
+    /// Runs the methods of the context on the given thread pool, see [`DASContext::with_thread_pool`].
+    ///
+    /// This takes precedence over [`DASContextBuilder::threads`].
+    #[cfg(feature = "multithreaded")]
+    pub fn thread_pool(mut self, thread_pool: Arc<rayon::ThreadPool>) -> Self {
+        self.thread_pool = Some(thread_pool);
+        self
+    }
+
+    /// Splits the layers of field element FFTs across threads from `threshold` elements,
+    /// instead of the library default, for example with the value measured by
+    /// [`crate::HostProfile::measure`].
+    ///
+    /// The threshold is set on the threads of a dedicated pool, so the context then runs on
+    /// a pool with [`DASContextBuilder::threads`] threads, or one per CPU if that is not set,
+    /// and other contexts are not affected. It is ignored when
+    /// [`DASContextBuilder::deterministic`] or [`DASContextBuilder::thread_pool`] are set,
+    /// and when the context runs on the pools of its NUMA nodes.
+    #[cfg(feature = "multithreaded")]
+    pub fn parallel_fft_threshold(mut self, threshold: usize) -> Self {
+        self.parallel_fft_threshold = Some(threshold);
+        self
+    }
+
+    /// Places the threads and prover tables of the context on the NUMA nodes of the machine,
+    /// see [`crate::numa`]. Defaults to false.
+    ///
+    /// The context then has one thread pool per node, with one thread per CPU of the node, and
+    /// one copy of the prover tables per node, so the memory used by the prover tables is
+    /// multiplied by the number of nodes. On a machine with a single node, or whose nodes
+    /// cannot be read, this has no effect.
+    ///
+    /// [`DASContextBuilder::deterministic`] and [`DASContextBuilder::thread_pool`] take
+    /// precedence over this, and this takes precedence over [`DASContextBuilder::threads`].
+    #[cfg(feature = "numa")]
+    pub fn numa_aware(mut self, numa_aware: bool) -> Self {
+        self.numa_aware = numa_aware;
+        self
+    }
+
+    /// Reports every operation of the context to `metrics`, see [`DASContext::with_metrics`].
+    pub fn metrics(mut self, metrics: Arc<dyn Metrics>) -> Self {
+        self.metrics = Some(metrics);
+        self
+    }
+
+    /// Creates the context.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn build(self) -> Result<DASContext, BuildError> {
+        // The tables of larger widths cannot be allocated, and building them would panic.
+        if self.precompute.width() > UsePrecomp::MAX_WIDTH {
+            return Err(BuildError::PrecompWidth(self.precompute.width()));
+        }
+
+        #[cfg(not(feature = "no-embedded-setup"))]
+        let embedded_setup;
+        let trusted_setup = match self.trusted_setup {
+            Some(trusted_setup) => trusted_setup,
+            #[cfg(not(feature = "no-embedded-setup"))]
+            None => {
+                embedded_setup = TrustedSetup::default();
+                &embedded_setup
+            }
+            #[cfg(feature = "no-embedded-setup")]
+            None => return Err(BuildError::MissingTrustedSetup),
+        };
+
+        check_setup_size(trusted_setup)?;
+        if self.verify_setup {
+            trusted_setup.verify()?;
+        }
+
+        // The thread pool is created first, since it is cheap and can fail.
+        let thread_pool = self.build_thread_pool()?;
+
+        let (precomputations_file, precompute) = (&self.precomputations_file, self.precompute);
+        let new_prover_ctx = || match precomputations_file {
+            Some(path) => load_precomputations(trusted_setup, path),
+            None => Ok(ProverContext::new(trusted_setup, precompute)),
+        };
+
+        // Each NUMA node gets its own copy of the prover tables, created on the threads of the
+        // node so that its memory is allocated there. The first copy is also the one used
+        // outside of the pools of the nodes.
+        #[cfg(feature = "numa")]
+        let numa_prover_ctxs: Vec<Arc<ProverContext>> = match thread_pool.numa_pools() {
+            Some(pools) if !self.verifier_only => (0..pools.len())
+                .map(|index| pools.install_on(index, new_prover_ctx).map(Arc::new))
+                .collect::<io::Result<_>>()?,
+            _ => Vec::new(),
+        };
+        #[cfg(feature = "numa")]
+        let prover_ctx = match numa_prover_ctxs.first() {
+            Some(prover_ctx) => Some(prover_ctx.clone()),
+            None if self.verifier_only => None,
+            None => Some(Arc::new(new_prover_ctx()?)),
+        };
+        #[cfg(not(feature = "numa"))]
+        let prover_ctx = if self.verifier_only {
+            None
+        } else {
+            Some(Arc::new(new_prover_ctx()?))
+        };
+
+        // Blobs are committed to with the same points in both forks, so the EIP-4844 context
+        // shares the commit key of the cell prover instead of copying the setup again.
+        let eip4844_ctx = match &prover_ctx {
+            Some(prover_ctx) => eip4844::Context::with_g1_monomial_points(
+                trusted_setup,
+                prover_ctx.g1_monomial_points().clone(),
+            ),
+            None => eip4844::Context::new(trusted_setup),
+        };
+
+        Ok(DASContext {
+            prover_ctx,
+            #[cfg(feature = "numa")]
+            numa_prover_ctxs: numa_prover_ctxs.into(),
+            verifier_ctx: Arc::new(VerifierContext::new(trusted_setup)),
+            eip4844_ctx: Arc::new(eip4844_ctx),
+            thread_pool,
+            metrics: MetricsHook::new(self.metrics),
+            admission: None,
+            verification_cache: None,
+            #[cfg(feature = "fault-injection")]
+            faults: None,
+        })
+    }
+
+    #[cfg(feature = "multithreaded")]
+    fn build_thread_pool(&self) -> Result<ThreadPool, BuildError> {
+        if self.deterministic {
+            return Ok(ThreadPool::single_threaded());
+        }
+        #[cfg(feature = "numa")]
+        if self.numa_aware && self.thread_pool.is_none() {
+            if let Some(pools) = crate::numa::NumaPools::new(&crate::numa::numa_nodes())? {
+                return Ok(ThreadPool::numa(Arc::new(pools)));
+            }
+        }
+        match (&self.thread_pool, self.threads, self.parallel_fft_threshold) {
+            (Some(thread_pool), _, _) => Ok(ThreadPool::new(thread_pool.clone())),
+            (None, num_threads, Some(threshold)) => Ok(ThreadPool::with_parallel_fft_threshold(
+                num_threads.unwrap_or(0),
+                threshold,
+            )?),
+            (None, Some(num_threads), None) => Ok(ThreadPool::with_num_threads(num_threads)?),
+            (None, None, None) => Ok(ThreadPool::default()),
+        }
+    }
+
+    #[cfg(not(feature = "multithreaded"))]
+    #[allow(clippy::unnecessary_wraps)]
+    fn build_thread_pool(&self) -> Result<ThreadPool, BuildError> {
+        if self.deterministic {
+            return Ok(ThreadPool::single_threaded());
+        }
+        Ok(ThreadPool::default())
+    }
+}
+
+/// Checks that the setup has every point that the contexts are created from, since they are
+/// copied out of it without checking the lengths again.
+fn check_setup_size(trusted_setup: &TrustedSetup) -> Result<(), TrustedSetupError> {
+    // Blobs are committed to with one G1 point per field element, and the cell verifier
+    // needs `FIELD_ELEMENTS_PER_CELL + 1` points of each group. The blob verifier uses the
+    // second G2 point.
+    let num_cell_points = FIELD_ELEMENTS_PER_CELL + 1;
+    let num_g1_points = trusted_setup.g1_monomial.len();
+    let num_g2_points = trusted_setup.g2_monomial.len();
+    if num_g1_points < FIELD_ELEMENTS_PER_BLOB.max(num_cell_points)
+        || num_g2_points < num_cell_points.max(2)
+    {
+        return Err(TrustedSetupError::NotEnoughPoints {
+            num_g1_points,
+            num_g2_points,
+        });
+    }
+    Ok(())
+}
+
+/// Reads the prover-side precomputations from the file at `path`.
+fn load_precomputations(trusted_setup: &TrustedSetup, path: &Path) -> io::Result<ProverContext> {
+    let file = std::fs::File::open(path)?;
+
+    #[cfg(feature = "mmap")]
+    {
+        // SAFETY: The mapped bytes are borrowed as a slice, so they must not change while
+        // they are decoded: if another process writes to the file, the slice changes under
+        // us, and if it truncates the file, reading past the new end raises SIGBUS. Neither
+        // can be ruled out here, so the documentation of `precomputations_file` and
+        // `DASContext::from_precomputations_file` requires that the file is not modified
+        // while the context is loaded. The map is dropped as soon as the tables have been
+        // decoded into the context, so the file can be replaced afterwards.
+        let map = unsafe { memmap2::Mmap::map(&file)? };
+        ProverContext::from_precomputations(trusted_setup, &mut &map[..])
+    }
+    #[cfg(not(feature = "mmap"))]
+    ProverContext::from_precomputations(trusted_setup, &mut io::BufReader::new(file))
+}
+
+impl DASContext {
+    /// Returns a builder for a context, which exposes every option of the constructors below.
+    pub fn builder<'a>() -> DASContextBuilder<'a> {
+        DASContextBuilder::default()
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT},
+        BuildError, DASContext, ErrorCode, TrustedSetup, TrustedSetupError, UsePrecomp,
+    };
+
+    fn blob() -> [u8; BYTES_PER_BLOB] {
+        let mut blob = [0u8; BYTES_PER_BLOB];
+        blob[BYTES_PER_FIELD_ELEMENT - 1] = 1;
+        blob
+    }
+
+    #[test]
+    fn builder_matches_new() {
+        let trusted_setup = TrustedSetup::default();
+        let ctx = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 4 });
+        let built = DASContext::builder()
+            .trusted_setup(&trusted_setup)
+            .precompute(UsePrecomp::Yes { width: 4 })
+            .verify_setup(true)
+            .deterministic(true)
+            .build()
+            .unwrap();
+
+        assert_eq!(
+            ctx.compute_cells_and_kzg_proofs(&blob()).unwrap(),
+            built.compute_cells_and_kzg_proofs(&blob()).unwrap()
+        );
+        assert_eq!(ctx.memory_usage(), built.memory_usage());
+    }
+
+    #[cfg(feature = "numa")]
+    #[test]
+    fn numa_aware_context_matches_default() {
+        let ctx = DASContext::default();
+        let numa = DASContext::builder().numa_aware(true).build().unwrap();
+
+        assert_eq!(
+            ctx.compute_cells_and_kzg_proofs(&blob()).unwrap(),
+            numa.compute_cells_and_kzg_proofs(&blob()).unwrap()
+        );
+
+        // One copy of the prover tables per node, if there are several
+        let copies = crate::numa::numa_nodes().len().max(1);
+        assert_eq!(
+            numa.memory_usage().domains - ctx.memory_usage().domains,
+            (copies - 1) * ctx.prover().unwrap().domains_size_in_bytes()
+        );
+    }
+
+    #[test]
+    fn verifier_only_context_verifies_but_does_not_prove() {
+        let prover = DASContext::default();
+        let verifier = DASContext::builder().verifier_only(true).build().unwrap();
+
+        let commitment = prover.blob_to_kzg_commitment(&blob()).unwrap();
+        let (cells, proofs) = prover.compute_cells_and_kzg_proofs(&blob()).unwrap();
+        verifier
+            .verify_cell_kzg_proof_batch(vec![&commitment], &[0], vec![&cells[0]], vec![&proofs[0]])
+            .unwrap();
+
+        let err = verifier.compute_cells_and_kzg_proofs(&blob()).unwrap_err();
+        assert_eq!(err.code(), ErrorCode::VerifierOnlyContext);
+        assert_eq!(
+            verifier.blob_to_kzg_commitment(&blob()).unwrap(),
+            commitment
+        );
+
+        let usage = verifier.memory_usage();
+        assert_eq!(usage.fk20_precomputations, 0);
+        assert!(usage.total() < prover.memory_usage().total());
+    }
+
+    #[test]
+    fn rejects_precomputation_widths_that_are_too_large() {
+        let width = UsePrecomp::MAX_WIDTH + 1;
+        for use_precomp in [UsePrecomp::Yes { width }, UsePrecomp::Compressed { width }] {
+            let err = DASContext::builder()
+                .precompute(use_precomp)
+                .build()
+                .err()
+                .unwrap();
+            assert!(matches!(err, BuildError::PrecompWidth(w) if w == width));
+        }
+    }
+
+    #[test]
+    fn precomputations_file_round_trips() {
+        // With the `mmap` feature, the file is read through a memory map
+        let path = std::env::temp_dir().join(format!(
+            "eth-kzg-precomputations-{}.bin",
+            std::process::id()
+        ));
+        let trusted_setup = TrustedSetup::default();
+        let ctx = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 4 });
+        ctx.write_precomputations_file(&path).unwrap();
+
+        let loaded = DASContext::from_precomputations_file(&trusted_setup, &path);
+        std::fs::remove_file(&path).unwrap();
+        let loaded = loaded.unwrap();
+
+        assert_eq!(
+            ctx.compute_cells_and_kzg_proofs(&blob()).unwrap(),
+            loaded.compute_cells_and_kzg_proofs(&blob()).unwrap()
+        );
+        assert_eq!(ctx.memory_usage(), loaded.memory_usage());
+    }
+
+    #[test]
+    fn rejects_setups_that_are_too_short() {
+        let mut trusted_setup = TrustedSetup::default();
+        trusted_setup.g1_monomial.truncate(16);
+        for verifier_only in [false, true] {
+            let err = DASContext::builder()
+                .trusted_setup(&trusted_setup)
+                .verifier_only(verifier_only)
+                .build()
+                .err()
+                .unwrap();
+            assert!(matches!(
+                err,
+                BuildError::TrustedSetup(TrustedSetupError::NotEnoughPoints {
+                    num_g1_points: 16,
+                    ..
+                })
+            ));
+        }
+    }
+}
@@ -0,0 +1,355 @@
+//! Custody of data columns, as specified in the fulu `das-core` of consensus-specs, and
+//! challenges that a node answers with the cells it custodies.
+
+use sha2::{Digest, Sha256};
+
+use crate::{
+    constants::CELLS_PER_EXT_BLOB, errors::Error, get_blobs::DataColumn, Bytes48Ref, Cell,
+    CellIndex, CellRef, DASContext, KZGProof,
+};
+
+/// The number of groups that the columns are divided into for custody.
+///
+/// This is 128 on mainnet, where every custody group has a single column.
+pub const NUMBER_OF_CUSTODY_GROUPS: u64 = CELLS_PER_EXT_BLOB as u64;
+
+/// The number of columns in each custody group.
+const COLUMNS_PER_GROUP: u64 = CELLS_PER_EXT_BLOB as u64 / NUMBER_OF_CUSTODY_GROUPS;
+
+/// The index of a custody group.
+pub type CustodyIndex = u64;
+
+/// The 32 byte ID of a node, as the big-endian encoding of the `uint256` of the specs.
+pub type NodeId = [u8; 32];
+
+/// Error returned by the custody functions for invalid inputs.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum CustodyError {
+    /// A node was asked to custody more groups than there are.
+    TooManyCustodyGroups {
+        custody_group_count: u64,
+        max_custody_groups: u64,
+    },
+    /// A custody group index was not below `NUMBER_OF_CUSTODY_GROUPS`.
+    CustodyGroupOutOfRange { custody_group: CustodyIndex },
+    /// A challenged column is not among the columns that the node holds.
+    ColumnNotHeld { column_index: CellIndex },
+    /// A held column has no cell for a challenged blob.
+    CellNotHeld {
+        column_index: CellIndex,
+        blob_index: usize,
+    },
+}
+
+/// Returns the sorted custody groups of a node, as `get_custody_groups` does.
+pub fn custody_groups(
+    node_id: NodeId,
+    custody_group_count: u64,
+) -> Result<Vec<CustodyIndex>, CustodyError> {
+    if custody_group_count > NUMBER_OF_CUSTODY_GROUPS {
+        return Err(CustodyError::TooManyCustodyGroups {
+            custody_group_count,
+            max_custody_groups: NUMBER_OF_CUSTODY_GROUPS,
+        });
+    }
+    // Skip the hashing if every group is custodied
+    if custody_group_count == NUMBER_OF_CUSTODY_GROUPS {
+        return Ok((0..NUMBER_OF_CUSTODY_GROUPS).collect());
+    }
+
+    let mut current_id = node_id;
+    let mut groups = Vec::with_capacity(custody_group_count as usize);
+    while (groups.len() as u64) < custody_group_count {
+        // The specs hash the little-endian encoding of the node ID
+        let mut bytes = current_id;
+        bytes.reverse();
+        let digest = Sha256::digest(bytes);
+        let group = u64::from_le_bytes(digest[..8].try_into().unwrap()) % NUMBER_OF_CUSTODY_GROUPS;
+        if !groups.contains(&group) {
+            groups.push(group);
+        }
+        increment_wrapping(&mut current_id);
+    }
+    groups.sort_unstable();
+    Ok(groups)
+}
+
+/// Returns the columns of a custody group, as `compute_columns_for_custody_group` does.
+pub fn columns_for_custody_group(
+    custody_group: CustodyIndex,
+) -> Result<Vec<CellIndex>, CustodyError> {
+    if custody_group >= NUMBER_OF_CUSTODY_GROUPS {
+        return Err(CustodyError::CustodyGroupOutOfRange { custody_group });
+    }
+    Ok((0..COLUMNS_PER_GROUP)
+        .map(|i| NUMBER_OF_CUSTODY_GROUPS * i + custody_group)
+        .collect())
+}
+
+/// Returns the sorted columns that a node custodies.
+pub fn custody_columns(
+    node_id: NodeId,
+    custody_group_count: u64,
+) -> Result<Vec<CellIndex>, CustodyError> {
+    let mut columns = Vec::with_capacity((custody_group_count * COLUMNS_PER_GROUP) as usize);
+    for group in custody_groups(node_id, custody_group_count)? {
+        columns.extend(columns_for_custody_group(group)?);
+    }
+    columns.sort_unstable();
+    Ok(columns)
+}
+
+/// Adds one to a big-endian `uint256`, wrapping `UINT256_MAX` around to zero.
+fn increment_wrapping(id: &mut NodeId) {
+    for byte in id.iter_mut().rev() {
+        let (value, overflow) = byte.overflowing_add(1);
+        *byte = value;
+        if !overflow {
+            return;
+        }
+    }
+}
+
+/// A challenge for a node to show that it holds its custody columns of a block.
+///
+/// The challenge picks `num_samples` cells among the custody columns of every blob of the
+/// block from `seed`, so that the node cannot know which cells it will be asked for before
+/// the seed is revealed. The cells are sampled with replacement.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+pub struct CustodyChallenge {
+    pub seed: [u8; 32],
+    pub num_samples: usize,
+}
+
+/// The challenged cells and their proofs, in the order of [`CustodyChallenge::challenged_cells`].
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct CustodyResponse {
+    pub cells: Vec<Cell>,
+    pub proofs: Vec<KZGProof>,
+}
+
+impl CustodyChallenge {
+    /// Returns the blob index and column index of each challenged cell.
+    ///
+    /// Sample `i` is taken from `sha256(seed || uint64_le(i))`, whose first and second eight
+    /// bytes pick the blob and the column. The modulo bias is negligible for the sizes of a
+    /// block. Nothing is challenged if the block has no blobs or the node no columns.
+    pub fn challenged_cells(
+        &self,
+        num_blobs: usize,
+        custody_columns: &[CellIndex],
+    ) -> Vec<(usize, CellIndex)> {
+        if num_blobs == 0 || custody_columns.is_empty() {
+            return Vec::new();
+        }
+        (0..self.num_samples as u64)
+            .map(|sample| {
+                let digest = Sha256::new()
+                    .chain_update(self.seed)
+                    .chain_update(sample.to_le_bytes())
+                    .finalize();
+                let blob = u64::from_le_bytes(digest[..8].try_into().unwrap());
+                let column = u64::from_le_bytes(digest[8..16].try_into().unwrap());
+                (
+                    (blob % num_blobs as u64) as usize,
+                    custody_columns[(column % custody_columns.len() as u64) as usize],
+                )
+            })
+            .collect()
+    }
+
+    /// Answers the challenge with the cells and proofs of the `held` columns.
+    pub fn respond(
+        &self,
+        num_blobs: usize,
+        custody_columns: &[CellIndex],
+        held: &[DataColumn],
+    ) -> Result<CustodyResponse, CustodyError> {
+        let challenged = self.challenged_cells(num_blobs, custody_columns);
+        let mut response = CustodyResponse {
+            cells: Vec::with_capacity(challenged.len()),
+            proofs: Vec::with_capacity(challenged.len()),
+        };
+        for (blob_index, column_index) in challenged {
+            let column = held
+                .iter()
+                .find(|column| column.index == column_index)
+                .ok_or(CustodyError::ColumnNotHeld { column_index })?;
+            let (cell, proof) = column
+                .cells
+                .get(blob_index)
+                .zip(column.proofs.get(blob_index))
+                .ok_or(CustodyError::CellNotHeld {
+                    column_index,
+                    blob_index,
+                })?;
+            response.cells.push(cell.clone());
+            response.proofs.push(*proof);
+        }
+        Ok(response)
+    }
+}
+
+impl DASContext {
+    /// Checks a response to a custody challenge against the commitments of the block.
+    ///
+    /// Every challenged cell is checked in a single batch, and a response with the wrong
+    /// number of cells or proofs returns [`crate::ErrorCode::BatchLengthMismatch`].
+    pub fn verify_custody_response(
+        &self,
+        challenge: &CustodyChallenge,
+        custody_columns: &[CellIndex],
+        commitments: &[Bytes48Ref],
+        response: &CustodyResponse,
+    ) -> Result<(), Error> {
+        let (blob_indices, cell_indices): (Vec<_>, Vec<_>) = challenge
+            .challenged_cells(commitments.len(), custody_columns)
+            .into_iter()
+            .unzip();
+        let cell_commitments = blob_indices.iter().map(|&blob| commitments[blob]).collect();
+        let cells: Vec<CellRef> = response.cells.iter().map(|cell| &**cell).collect();
+        self.verify_cell_kzg_proof_batch(
+            cell_commitments,
+            &cell_indices,
+            cells,
+            response.proofs.iter().collect(),
+        )
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::{
+        columns_for_custody_group, custody_columns, custody_groups, increment_wrapping,
+        CustodyError, NodeId, NUMBER_OF_CUSTODY_GROUPS,
+    };
+    use crate::constants::CELLS_PER_EXT_BLOB;
+
+    #[test]
+    fn custody_groups_are_sorted_and_distinct() {
+        let node_id: NodeId = [7; 32];
+        for count in [
+            0,
+            1,
+            4,
+            NUMBER_OF_CUSTODY_GROUPS - 1,
+            NUMBER_OF_CUSTODY_GROUPS,
+        ] {
+            let groups = custody_groups(node_id, count).unwrap();
+            assert_eq!(groups.len() as u64, count);
+            assert!(groups.windows(2).all(|pair| pair[0] < pair[1]));
+            assert!(groups.iter().all(|&group| group < NUMBER_OF_CUSTODY_GROUPS));
+        }
+        // A node custodies a superset of its groups when its count grows
+        let few = custody_groups(node_id, 4).unwrap();
+        let more = custody_groups(node_id, 8).unwrap();
+        assert!(few.iter().all(|group| more.contains(group)));
+
+        assert_eq!(
+            custody_groups(node_id, NUMBER_OF_CUSTODY_GROUPS + 1),
+            Err(CustodyError::TooManyCustodyGroups {
+                custody_group_count: NUMBER_OF_CUSTODY_GROUPS + 1,
+                max_custody_groups: NUMBER_OF_CUSTODY_GROUPS,
+            })
+        );
+        // The node ID wraps around at UINT256_MAX
+        assert_eq!(custody_groups([0xff; 32], 2).unwrap().len(), 2);
+    }
+
+    #[test]
+    fn custody_groups_partition_the_columns() {
+        let mut columns: Vec<_> = (0..NUMBER_OF_CUSTODY_GROUPS)
+            .flat_map(|group| columns_for_custody_group(group).unwrap())
+            .collect();
+        columns.sort_unstable();
+        assert_eq!(columns, (0..CELLS_PER_EXT_BLOB as u64).collect::<Vec<_>>());
+        assert_eq!(
+            columns_for_custody_group(NUMBER_OF_CUSTODY_GROUPS),
+            Err(CustodyError::CustodyGroupOutOfRange {
+                custody_group: NUMBER_OF_CUSTODY_GROUPS
+            })
+        );
+        assert_eq!(
+            custody_columns([1; 32], NUMBER_OF_CUSTODY_GROUPS).unwrap(),
+            columns
+        );
+    }
+
+    #[test]
+    fn node_id_increments_as_a_big_endian_integer() {
+        let mut id = [0; 32];
+        id[31] = 0xff;
+        increment_wrapping(&mut id);
+        assert_eq!((id[30], id[31]), (1, 0));
+
+        let mut id = [0xff; 32];
+        increment_wrapping(&mut id);
+        assert_eq!(id, [0; 32]);
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn custody_response_round_trip() {
+        use super::CustodyChallenge;
+        use crate::{generators, DASContext, ErrorCode};
+
+        let ctx = DASContext::default();
+        let blobs: Vec<_> = (0..3).map(generators::blob).collect();
+        let commitments: Vec<_> = blobs
+            .iter()
+            .map(|blob| ctx.blob_to_kzg_commitment(blob).unwrap())
+            .collect();
+        let proofs: Vec<_> = blobs
+            .iter()
+            .flat_map(|blob| ctx.compute_cells_and_kzg_proofs(blob).unwrap().1)
+            .collect();
+        let columns = ctx
+            .compute_columns_from_blobs_and_cell_proofs(
+                blobs.iter().map(|blob| &**blob).collect(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            )
+            .unwrap();
+
+        let custody = custody_columns([3; 32], 4).unwrap();
+        let held: Vec<_> = columns
+            .into_iter()
+            .filter(|column| custody.contains(&column.index))
+            .collect();
+        let challenge = CustodyChallenge {
+            seed: [9; 32],
+            num_samples: 8,
+        };
+        let commitment_refs: Vec<_> = commitments.iter().collect();
+
+        let mut response = challenge.respond(blobs.len(), &custody, &held).unwrap();
+        ctx.verify_custody_response(&challenge, &custody, &commitment_refs, &response)
+            .unwrap();
+
+        // A node that only holds some of its columns cannot answer every challenge
+        let missing = (0..64)
+            .map(|seed| CustodyChallenge {
+                seed: [seed; 32],
+                num_samples: 8,
+            })
+            .find_map(|challenge| challenge.respond(blobs.len(), &custody, &held[1..]).err());
+        assert_eq!(
+            missing,
+            Some(CustodyError::ColumnNotHeld {
+                column_index: held[0].index
+            })
+        );
+
+        response.proofs[0] = commitments[0];
+        let err = ctx
+            .verify_custody_response(&challenge, &custody, &commitment_refs, &response)
+            .unwrap_err();
+        assert!(err.is_proof_invalid());
+
+        response.proofs.pop();
+        let err = ctx
+            .verify_custody_response(&challenge, &custody, &commitment_refs, &response)
+            .unwrap_err();
+        assert_eq!(err.code(), ErrorCode::BatchLengthMismatch);
+    }
+}
@@ -1,6 +1,9 @@
-use eip4844::{BlobRef, KZGProof, SerializedScalar};
+use eip4844::{
+    BlobRef, ConsistencyReport, KZGProof, PayloadBlobs, PrecompileError, SerializedScalar,
+    POINT_EVALUATION_INPUT_BYTES, POINT_EVALUATION_OUTPUT_BYTES,
+};
 
-use crate::{Bytes48Ref, DASContext, Error};
+use crate::{Bytes48Ref, DASContext, Error, Operation};
 
 // EIP-4844 methods re-exported
 //
@@ -15,9 +18,11 @@ impl DASContext {
         blob: BlobRef,
         z: SerializedScalar,
     ) -> Result<(KZGProof, SerializedScalar), Error> {
-        self.eip4844_ctx
-            .compute_kzg_proof(blob, z)
-            .map_err(Error::EIP4844)
+        self.run(Operation::ComputeKzgProof, 1, || {
+            self.eip4844_ctx
+                .compute_kzg_proof(blob, z)
+                .map_err(Error::EIP4844)
+        })
     }
 
     /// Compute the KZG proof given a blob and its corresponding commitment.
@@ -28,9 +33,11 @@ impl DASContext {
         blob: BlobRef,
         commitment: Bytes48Ref,
     ) -> Result<KZGProof, Error> {
-        self.eip4844_ctx
-            .compute_blob_kzg_proof(blob, commitment)
-            .map_err(Error::EIP4844)
+        self.run(Operation::ComputeBlobKzgProof, 1, || {
+            self.eip4844_ctx
+                .compute_blob_kzg_proof(blob, commitment)
+                .map_err(Error::EIP4844)
+        })
     }
 
     /// Verify the KZG proof to the commitment.
@@ -43,9 +50,11 @@ impl DASContext {
         y: SerializedScalar,
         proof: Bytes48Ref,
     ) -> Result<(), Error> {
-        self.eip4844_ctx
-            .verify_kzg_proof(commitment, z, y, proof)
-            .map_err(Error::EIP4844)
+        self.run(Operation::VerifyKzgProof, 1, || {
+            self.eip4844_ctx
+                .verify_kzg_proof(commitment, z, y, proof)
+                .map_err(Error::EIP4844)
+        })
     }
 
     /// Verify the KZG proof to the commitment of a blob.
@@ -57,9 +66,11 @@ impl DASContext {
         commitment: Bytes48Ref,
         proof: Bytes48Ref,
     ) -> Result<(), Error> {
-        self.eip4844_ctx
-            .verify_blob_kzg_proof(blob, commitment, proof)
-            .map_err(Error::EIP4844)
+        self.run(Operation::VerifyBlobKzgProof, 1, || {
+            self.eip4844_ctx
+                .verify_blob_kzg_proof(blob, commitment, proof)
+                .map_err(Error::EIP4844)
+        })
     }
 
     /// Verify a batch of KZG proof to a the commitment of a blob.
@@ -71,8 +82,65 @@ impl DASContext {
         commitments: Vec<Bytes48Ref>,
         proofs: Vec<Bytes48Ref>,
     ) -> Result<(), Error> {
+        self.run(Operation::VerifyBlobKzgProofBatch, blobs.len(), || {
+            self.eip4844_ctx
+                .verify_blob_kzg_proof_batch(blobs, commitments, proofs)
+                .map_err(Error::EIP4844)
+        })
+    }
+
+    /// Recomputes the commitment and the blob proof of `blob`, and reports which of
+    /// `commitment` and `proof` differ from them, for instance to check the blobs of a
+    /// third-party bundle before signing it.
+    ///
+    /// Only returns an error if the blob is not made of canonical field elements.
+    ///
+    /// Note: This method has been re-exported from the eip4844 crate.
+    pub fn consistency_check(
+        &self,
+        blob: BlobRef,
+        commitment: Bytes48Ref,
+        proof: Bytes48Ref,
+    ) -> Result<ConsistencyReport, Error> {
         self.eip4844_ctx
-            .verify_blob_kzg_proof_batch(blobs, commitments, proofs)
+            .consistency_check(blob, commitment, proof)
             .map_err(Error::EIP4844)
     }
+
+    /// Encodes `data` into blobs, and computes the commitment, blob proof and versioned hash
+    /// of each blob. See [`crate::encode_payload`] for the encoding.
+    ///
+    /// The proofs are the blob proofs of EIP-4844 sidecars. For the sidecars of EIP-7594, pass
+    /// each blob to [`DASContext::compute_cells_and_kzg_proofs`] instead.
+    ///
+    /// Note: This method has been re-exported from the eip4844 crate.
+    pub fn commit_to_payload(&self, data: &[u8]) -> PayloadBlobs {
+        self.eip4844_ctx.commit_to_payload(data)
+    }
+
+    /// Computes the commitment to `blob`, and the evaluation and proof at `z`, laid out as the
+    /// input of the point evaluation precompile.
+    ///
+    /// Note: This method has been re-exported from the eip4844 crate.
+    pub fn point_evaluation_input_for_blob(
+        &self,
+        blob: BlobRef,
+        z: SerializedScalar,
+    ) -> Result<[u8; POINT_EVALUATION_INPUT_BYTES], Error> {
+        self.run(Operation::ComputeKzgProof, 1, || {
+            self.eip4844_ctx
+                .point_evaluation_input_for_blob(blob, z)
+                .map_err(Error::EIP4844)
+        })
+    }
+
+    /// Runs the point evaluation precompile on `input`, returning its output if it succeeds.
+    ///
+    /// Note: This method has been re-exported from the eip4844 crate.
+    pub fn verify_point_evaluation_input(
+        &self,
+        input: &[u8],
+    ) -> Result<[u8; POINT_EVALUATION_OUTPUT_BYTES], PrecompileError> {
+        self.eip4844_ctx.verify_point_evaluation_input(input)
+    }
 }
@@ -0,0 +1,424 @@
+//! Stable numeric codes for every error that the library and its bindings can return.
+//!
+//! The errors of the underlying crates are nested inside [`Error`], so the same failure can
+//! reach a caller through several paths; an invalid proof, for example, is reported by
+//! both the EIP-4844 and the EIP-7594 verifiers. [`Error::code`] flattens the nested errors
+//! into a single [`ErrorCode`], which is what the C ABI returns and what each binding exposes
+//! on its exceptions, so that an error in the logs of a client in another language can be
+//! looked up here.
+//!
+//! Codes are grouped by the kind of failure, in ranges of a hundred. Once released, a code
+//! is never renumbered or reused; new failures get new codes at the end of their range.
+
+use erasure_codes::errors::RSError;
+use serialization::errors::Error as SerializationError;
+use trusted_setup::TrustedSetupError;
+
+use crate::{
+    errors::{AdmissionError, ProverError, RecoveryError, VerifierError},
+    Error,
+};
+
+/// A stable identifier for a kind of error.
+///
+/// The discriminants are part of the public API, and are returned by the C ABI.
+/// Zero is reserved to mean that no error occurred.
+#[repr(u32)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+pub enum ErrorCode {
+    // Malformed inputs
+    //
+    /// A field element is not less than the scalar field modulus.
+    NonCanonicalScalar = 101,
+    /// A G1 point is not a valid compressed encoding.
+    InvalidG1PointEncoding = 102,
+    /// A G1 point is not on the curve.
+    G1PointNotOnCurve = 103,
+    /// A G1 point is on the curve, but not in the G1 subgroup.
+    G1PointNotInSubgroup = 104,
+    /// A field element does not have the right number of bytes.
+    ScalarHasInvalidLength = 105,
+    /// A blob does not have the right number of bytes.
+    BlobHasInvalidLength = 106,
+    /// A G1 point does not have the right number of bytes.
+    G1PointHasInvalidLength = 107,
+
+    // Invalid arguments
+    //
+    /// A cell index is not less than the number of cells in an extended blob.
+    CellIndexOutOfRange = 201,
+    /// A commitment index does not refer to one of the commitments.
+    CommitmentIndexOutOfRange = 202,
+    /// The inputs to a batch operation do not have the same length.
+    BatchLengthMismatch = 203,
+    /// The number of cell indices differs from the number of cells.
+    NumCellIndicesNotEqualToNumCells = 204,
+    /// Fewer than half of the cells were given to recovery.
+    NotEnoughCellsToReconstruct = 205,
+    /// More cells than there are in an extended blob were given to recovery.
+    TooManyCellsReceived = 206,
+    /// The cell indices given to recovery are not sorted and unique.
+    CellIndicesNotUniquelyOrdered = 207,
+    /// A cell does not contain one evaluation per point in its coset.
+    InvalidCellLength = 208,
+
+    // Verification failures
+    //
+    /// A proof failed verification.
+    InvalidProof = 301,
+
+    // Internal failures
+    //
+    /// A polynomial had an unexpected number of coefficients.
+    PolynomialHasInvalidLength = 401,
+    /// A polynomial had more coefficients than the code allows.
+    PolynomialHasTooManyCoefficients = 402,
+    /// More blocks were erased than the code can correct.
+    TooManyBlockErasures = 403,
+    /// A block index is not less than the block size.
+    BlockIndexOutOfRange = 404,
+
+    // Trusted setup failures
+    //
+    /// The trusted setup has too few points to be checked.
+    TrustedSetupNotEnoughPoints = 501,
+    /// The first point of the trusted setup is not the generator.
+    TrustedSetupFirstPointIsNotGenerator = 502,
+    /// A G1 point of the trusted setup is not in the G1 subgroup.
+    TrustedSetupG1PointNotInSubgroup = 503,
+    /// A G2 point of the trusted setup is not in the G2 subgroup.
+    TrustedSetupG2PointNotInSubgroup = 504,
+    /// The G1 points of the trusted setup are not powers of the same secret.
+    TrustedSetupInconsistentG1Powers = 505,
+    /// The G2 points of the trusted setup are not powers of the same secret as the G1 points.
+    TrustedSetupInconsistentG2Powers = 506,
+
+    // Runtime failures
+    //
+    /// The operation was cancelled because the async runtime is shutting down.
+    Cancelled = 601,
+    /// A prover method was called on a context that was built without a prover.
+    VerifierOnlyContext = 602,
+    /// The operation was rejected because the context was running and queuing as many
+    /// operations as its admission limits allow.
+    Overloaded = 603,
+    /// The operation waited for longer than the queue timeout of the admission limits of
+    /// the context.
+    AdmissionTimedOut = 604,
+
+    // Failures that are only raised by the bindings
+    //
+    /// An argument could not be passed to the library, for example because a pointer
+    /// was null or a buffer had the wrong length.
+    InvalidArgument = 901,
+    /// The trusted setup could not be read.
+    TrustedSetupUnreadable = 902,
+    /// The trusted setup could not be parsed.
+    TrustedSetupMalformed = 903,
+    /// A thread pool could not be created.
+    ThreadPoolCreationFailed = 904,
+    /// [`crate::DASContext::self_test`] failed.
+    SelfTestFailed = 905,
+    /// The library does not implement the version of the C ABI that a binding was built against.
+    AbiVersionMismatch = 906,
+}
+
+impl ErrorCode {
+    /// Every error code, in increasing order.
+    pub const ALL: [Self; 36] = [
+        Self::NonCanonicalScalar,
+        Self::InvalidG1PointEncoding,
+        Self::G1PointNotOnCurve,
+        Self::G1PointNotInSubgroup,
+        Self::ScalarHasInvalidLength,
+        Self::BlobHasInvalidLength,
+        Self::G1PointHasInvalidLength,
+        Self::CellIndexOutOfRange,
+        Self::CommitmentIndexOutOfRange,
+        Self::BatchLengthMismatch,
+        Self::NumCellIndicesNotEqualToNumCells,
+        Self::NotEnoughCellsToReconstruct,
+        Self::TooManyCellsReceived,
+        Self::CellIndicesNotUniquelyOrdered,
+        Self::InvalidCellLength,
+        Self::InvalidProof,
+        Self::PolynomialHasInvalidLength,
+        Self::PolynomialHasTooManyCoefficients,
+        Self::TooManyBlockErasures,
+        Self::BlockIndexOutOfRange,
+        Self::TrustedSetupNotEnoughPoints,
+        Self::TrustedSetupFirstPointIsNotGenerator,
+        Self::TrustedSetupG1PointNotInSubgroup,
+        Self::TrustedSetupG2PointNotInSubgroup,
+        Self::TrustedSetupInconsistentG1Powers,
+        Self::TrustedSetupInconsistentG2Powers,
+        Self::Cancelled,
+        Self::VerifierOnlyContext,
+        Self::Overloaded,
+        Self::AdmissionTimedOut,
+        Self::InvalidArgument,
+        Self::TrustedSetupUnreadable,
+        Self::TrustedSetupMalformed,
+        Self::ThreadPoolCreationFailed,
+        Self::SelfTestFailed,
+        Self::AbiVersionMismatch,
+    ];
+
+    /// Returns the numeric value of the code.
+    pub const fn as_u32(self) -> u32 {
+        self as u32
+    }
+
+    /// Returns the code with the given numeric value, if there is one.
+    pub fn from_u32(code: u32) -> Option<Self> {
+        Self::ALL.into_iter().find(|c| c.as_u32() == code)
+    }
+
+    /// Returns the name of the code, ie `G1PointNotInSubgroup`.
+    pub const fn name(self) -> &'static str {
+        match self {
+            Self::NonCanonicalScalar => "NonCanonicalScalar",
+            Self::InvalidG1PointEncoding => "InvalidG1PointEncoding",
+            Self::G1PointNotOnCurve => "G1PointNotOnCurve",
+            Self::G1PointNotInSubgroup => "G1PointNotInSubgroup",
+            Self::ScalarHasInvalidLength => "ScalarHasInvalidLength",
+            Self::BlobHasInvalidLength => "BlobHasInvalidLength",
+            Self::G1PointHasInvalidLength => "G1PointHasInvalidLength",
+            Self::CellIndexOutOfRange => "CellIndexOutOfRange",
+            Self::CommitmentIndexOutOfRange => "CommitmentIndexOutOfRange",
+            Self::BatchLengthMismatch => "BatchLengthMismatch",
+            Self::NumCellIndicesNotEqualToNumCells => "NumCellIndicesNotEqualToNumCells",
+            Self::NotEnoughCellsToReconstruct => "NotEnoughCellsToReconstruct",
+            Self::TooManyCellsReceived => "TooManyCellsReceived",
+            Self::CellIndicesNotUniquelyOrdered => "CellIndicesNotUniquelyOrdered",
+            Self::InvalidCellLength => "InvalidCellLength",
+            Self::InvalidProof => "InvalidProof",
+            Self::PolynomialHasInvalidLength => "PolynomialHasInvalidLength",
+            Self::PolynomialHasTooManyCoefficients => "PolynomialHasTooManyCoefficients",
+            Self::TooManyBlockErasures => "TooManyBlockErasures",
+            Self::BlockIndexOutOfRange => "BlockIndexOutOfRange",
+            Self::TrustedSetupNotEnoughPoints => "TrustedSetupNotEnoughPoints",
+            Self::TrustedSetupFirstPointIsNotGenerator => "TrustedSetupFirstPointIsNotGenerator",
+            Self::TrustedSetupG1PointNotInSubgroup => "TrustedSetupG1PointNotInSubgroup",
+            Self::TrustedSetupG2PointNotInSubgroup => "TrustedSetupG2PointNotInSubgroup",
+            Self::TrustedSetupInconsistentG1Powers => "TrustedSetupInconsistentG1Powers",
+            Self::TrustedSetupInconsistentG2Powers => "TrustedSetupInconsistentG2Powers",
+            Self::Cancelled => "Cancelled",
+            Self::VerifierOnlyContext => "VerifierOnlyContext",
+            Self::Overloaded => "Overloaded",
+            Self::AdmissionTimedOut => "AdmissionTimedOut",
+            Self::InvalidArgument => "InvalidArgument",
+            Self::TrustedSetupUnreadable => "TrustedSetupUnreadable",
+            Self::TrustedSetupMalformed => "TrustedSetupMalformed",
+            Self::ThreadPoolCreationFailed => "ThreadPoolCreationFailed",
+            Self::SelfTestFailed => "SelfTestFailed",
+            Self::AbiVersionMismatch => "AbiVersionMismatch",
+        }
+    }
+}
+
+impl std::fmt::Display for ErrorCode {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        write!(f, "E{} {}", self.as_u32(), self.name())
+    }
+}
+
+impl Error {
+    /// Returns the stable code for this error.
+    ///
+    /// The matches below are exhaustive on purpose, so that adding a variant to any of
+    /// the nested errors does not compile until it has been given a code.
+    pub const fn code(&self) -> ErrorCode {
+        match self {
+            Self::Prover(err) => prover_code(err),
+            Self::Verifier(err) => verifier_code(err),
+            Self::Recovery(err) => recovery_code(err),
+            Self::Serialization(err) => serialization_code(err),
+            Self::EIP4844(eip4844::Error::Serialization(err)) => serialization_code(err),
+            Self::EIP4844(eip4844::Error::Verifier(err)) => match err {
+                eip4844::VerifierError::InvalidProof => ErrorCode::InvalidProof,
+                eip4844::VerifierError::BatchVerificationInputsMustHaveSameLength { .. } => {
+                    ErrorCode::BatchLengthMismatch
+                }
+            },
+            Self::TrustedSetup(err) => trusted_setup_code(err),
+            Self::Admission(AdmissionError::QueueFull { .. }) => ErrorCode::Overloaded,
+            Self::Admission(AdmissionError::TimedOut { .. }) => ErrorCode::AdmissionTimedOut,
+            Self::Cancelled => ErrorCode::Cancelled,
+            Self::Injected(code) => *code,
+        }
+    }
+}
+
+const fn serialization_code(err: &SerializationError) -> ErrorCode {
+    match err {
+        SerializationError::CouldNotDeserializeScalar { .. } => ErrorCode::NonCanonicalScalar,
+        SerializationError::CouldNotDeserializeG1Point { .. } => ErrorCode::InvalidG1PointEncoding,
+        SerializationError::G1PointNotOnCurve { .. } => ErrorCode::G1PointNotOnCurve,
+        SerializationError::G1PointNotInSubgroup { .. } => ErrorCode::G1PointNotInSubgroup,
+        SerializationError::ScalarHasInvalidLength { .. } => ErrorCode::ScalarHasInvalidLength,
+        SerializationError::BlobHasInvalidLength { .. } => ErrorCode::BlobHasInvalidLength,
+        SerializationError::G1PointHasInvalidLength { .. } => ErrorCode::G1PointHasInvalidLength,
+    }
+}
+
+const fn prover_code(err: &ProverError) -> ErrorCode {
+    match err {
+        ProverError::RecoveryFailure(err) => recovery_code(err),
+        ProverError::CellIndexOutOfRange { .. } => ErrorCode::CellIndexOutOfRange,
+        ProverError::VerifierOnlyContext => ErrorCode::VerifierOnlyContext,
+    }
+}
+
+const fn verifier_code(err: &VerifierError) -> ErrorCode {
+    match err {
+        VerifierError::CellIndexOutOfRange { .. } => ErrorCode::CellIndexOutOfRange,
+        VerifierError::InvalidCommitmentIndex { .. } => ErrorCode::CommitmentIndexOutOfRange,
+        VerifierError::InvalidProof => ErrorCode::InvalidProof,
+        VerifierError::BatchVerificationInputsMustHaveSameLength { .. }
+        | VerifierError::BlobsCommitmentsAndCellProofsMustHaveMatchingLengths { .. } => {
+            ErrorCode::BatchLengthMismatch
+        }
+        VerifierError::FK20(err) => match err {
+            kzg_multi_open::VerifierError::InvalidProof => ErrorCode::InvalidProof,
+            kzg_multi_open::VerifierError::BatchVerificationInputsMustHaveSameLength { .. } => {
+                ErrorCode::BatchLengthMismatch
+            }
+            kzg_multi_open::VerifierError::CosetIndexOutOfRange { .. } => {
+                ErrorCode::CellIndexOutOfRange
+            }
+            kzg_multi_open::VerifierError::InvalidCosetEvaluationsLength { .. } => {
+                ErrorCode::InvalidCellLength
+            }
+        },
+        VerifierError::PolynomialHasInvalidLength { .. } => ErrorCode::PolynomialHasInvalidLength,
+    }
+}
+
+const fn recovery_code(err: &RecoveryError) -> ErrorCode {
+    match err {
+        RecoveryError::NotEnoughCellsToReconstruct { .. } => ErrorCode::NotEnoughCellsToReconstruct,
+        RecoveryError::NumCellIndicesNotEqualToNumCells { .. } => {
+            ErrorCode::NumCellIndicesNotEqualToNumCells
+        }
+        RecoveryError::TooManyCellsReceived { .. } => ErrorCode::TooManyCellsReceived,
+        RecoveryError::CellIndexOutOfRange { .. } => ErrorCode::CellIndexOutOfRange,
+        RecoveryError::CellIndicesNotUniquelyOrdered => ErrorCode::CellIndicesNotUniquelyOrdered,
+        RecoveryError::ColumnHasWrongNumberOfCells { .. } => ErrorCode::BatchLengthMismatch,
+        RecoveryError::ReedSolomon(err) => match err {
+            RSError::PolynomialHasTooManyCoefficients { .. } => {
+                ErrorCode::PolynomialHasTooManyCoefficients
+            }
+            RSError::PolynomialHasInvalidLength { .. } => ErrorCode::PolynomialHasInvalidLength,
+            RSError::TooManyBlockErasures { .. } => ErrorCode::TooManyBlockErasures,
+            RSError::InvalidBlockIndex { .. } => ErrorCode::BlockIndexOutOfRange,
+            // The block indices are the cell indices, so a repeated one is reported as such
+            RSError::RepeatedBlockIndex { .. } => ErrorCode::CellIndicesNotUniquelyOrdered,
+            _ => ErrorCode::InvalidArgument,
+        },
+    }
+}
+
+const fn trusted_setup_code(err: &TrustedSetupError) -> ErrorCode {
+    match err {
+        TrustedSetupError::NotEnoughPoints { .. } => ErrorCode::TrustedSetupNotEnoughPoints,
+        TrustedSetupError::FirstPointIsNotGenerator => {
+            ErrorCode::TrustedSetupFirstPointIsNotGenerator
+        }
+        TrustedSetupError::G1PointNotInSubgroup { .. } => {
+            ErrorCode::TrustedSetupG1PointNotInSubgroup
+        }
+        TrustedSetupError::G2PointNotInSubgroup { .. } => {
+            ErrorCode::TrustedSetupG2PointNotInSubgroup
+        }
+        TrustedSetupError::InconsistentG1Powers => ErrorCode::TrustedSetupInconsistentG1Powers,
+        TrustedSetupError::InconsistentG2Powers => ErrorCode::TrustedSetupInconsistentG2Powers,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn codes_are_sorted_and_round_trip() {
+        for pair in ErrorCode::ALL.windows(2) {
+            assert!(pair[0].as_u32() < pair[1].as_u32(), "{pair:?}");
+        }
+        for code in ErrorCode::ALL {
+            assert_ne!(code.as_u32(), 0, "zero is reserved for success");
+            assert_eq!(ErrorCode::from_u32(code.as_u32()), Some(code));
+        }
+        assert_eq!(ErrorCode::from_u32(0), None);
+    }
+
+    #[test]
+    fn codes_are_stable() {
+        // These values are returned across the C ABI and matched on by the bindings,
+        // so this test must only ever be extended.
+        let expected = [
+            (ErrorCode::NonCanonicalScalar, 101),
+            (ErrorCode::InvalidG1PointEncoding, 102),
+            (ErrorCode::G1PointNotOnCurve, 103),
+            (ErrorCode::G1PointNotInSubgroup, 104),
+            (ErrorCode::ScalarHasInvalidLength, 105),
+            (ErrorCode::BlobHasInvalidLength, 106),
+            (ErrorCode::G1PointHasInvalidLength, 107),
+            (ErrorCode::CellIndexOutOfRange, 201),
+            (ErrorCode::CommitmentIndexOutOfRange, 202),
+            (ErrorCode::BatchLengthMismatch, 203),
+            (ErrorCode::NumCellIndicesNotEqualToNumCells, 204),
+            (ErrorCode::NotEnoughCellsToReconstruct, 205),
+            (ErrorCode::TooManyCellsReceived, 206),
+            (ErrorCode::CellIndicesNotUniquelyOrdered, 207),
+            (ErrorCode::InvalidCellLength, 208),
+            (ErrorCode::InvalidProof, 301),
+            (ErrorCode::PolynomialHasInvalidLength, 401),
+            (ErrorCode::PolynomialHasTooManyCoefficients, 402),
+            (ErrorCode::TooManyBlockErasures, 403),
+            (ErrorCode::BlockIndexOutOfRange, 404),
+            (ErrorCode::TrustedSetupNotEnoughPoints, 501),
+            (ErrorCode::TrustedSetupFirstPointIsNotGenerator, 502),
+            (ErrorCode::TrustedSetupG1PointNotInSubgroup, 503),
+            (ErrorCode::TrustedSetupG2PointNotInSubgroup, 504),
+            (ErrorCode::TrustedSetupInconsistentG1Powers, 505),
+            (ErrorCode::TrustedSetupInconsistentG2Powers, 506),
+            (ErrorCode::Cancelled, 601),
+            (ErrorCode::VerifierOnlyContext, 602),
+            (ErrorCode::Overloaded, 603),
+            (ErrorCode::AdmissionTimedOut, 604),
+            (ErrorCode::InvalidArgument, 901),
+            (ErrorCode::TrustedSetupUnreadable, 902),
+            (ErrorCode::TrustedSetupMalformed, 903),
+            (ErrorCode::ThreadPoolCreationFailed, 904),
+            (ErrorCode::SelfTestFailed, 905),
+            (ErrorCode::AbiVersionMismatch, 906),
+        ];
+        assert_eq!(expected.len(), ErrorCode::ALL.len());
+        for (code, value) in expected {
+            assert_eq!(code.as_u32(), value, "{code:?} was renumbered");
+        }
+    }
+
+    #[test]
+    fn invalid_proofs_have_the_same_code_in_both_verifiers() {
+        let fk20 = Error::Verifier(VerifierError::FK20(
+            kzg_multi_open::VerifierError::InvalidProof,
+        ));
+        let eip4844 = Error::EIP4844(eip4844::Error::Verifier(
+            eip4844::VerifierError::InvalidProof,
+        ));
+        assert!(fk20.is_proof_invalid() && eip4844.is_proof_invalid());
+        assert_eq!(fk20.code(), ErrorCode::InvalidProof);
+        assert_eq!(eip4844.code(), ErrorCode::InvalidProof);
+
+        let not_in_subgroup = Error::EIP4844(eip4844::Error::Serialization(
+            SerializationError::G1PointNotInSubgroup { bytes: Vec::new() },
+        ));
+        assert_eq!(not_in_subgroup.code(), ErrorCode::G1PointNotInSubgroup);
+        assert_eq!(
+            not_in_subgroup.code().to_string(),
+            "E104 G1PointNotInSubgroup"
+        );
+    }
+}
@@ -1,5 +1,8 @@
+use std::time::Duration;
+
 use erasure_codes::errors::RSError;
 use serialization::errors::Error as SerializationError;
+use trusted_setup::TrustedSetupError;
 
 use crate::CellIndex;
 
@@ -16,6 +19,20 @@ pub enum Error {
     Serialization(SerializationError),
     /// Error that occurred from the EIP-4844 implementation.
     EIP4844(eip4844::Error),
+    /// The trusted setup failed validation.
+    TrustedSetup(TrustedSetupError),
+    /// The operation was not run, because the context was already running as many operations
+    /// as its admission limits allow.
+    Admission(AdmissionError),
+    /// The operation was cancelled before it completed, because the async runtime is shutting down.
+    ///
+    /// This is only returned by the async context of the `tokio` feature, but the variant
+    /// exists either way, so that enabling a feature does not break exhaustive matches.
+    Cancelled,
+    /// An error with this code was injected by a `FaultInjector`.
+    ///
+    /// This is only returned when the `fault-injection` feature is enabled.
+    Injected(crate::ErrorCode),
 }
 
 impl Error {
@@ -24,12 +41,16 @@ impl Error {
     /// Note: This distinction in practice, is not meaningful for the caller and is mainly
     /// here due to the specs and spec tests making this distinction.
     pub const fn is_proof_invalid(&self) -> bool {
+        if let Self::Injected(code) = self {
+            return matches!(code, crate::ErrorCode::InvalidProof);
+        }
         matches!(
             self,
-            Self::Verifier(VerifierError::FK20(_))
-                | Self::EIP4844(eip4844::Error::Verifier(
-                    eip4844::VerifierError::InvalidProof
-                ))
+            Self::Verifier(VerifierError::FK20(
+                kzg_multi_open::VerifierError::InvalidProof
+            )) | Self::EIP4844(eip4844::Error::Verifier(
+                eip4844::VerifierError::InvalidProof
+            ))
         )
     }
 }
@@ -70,11 +91,49 @@ impl From<eip4844::Error> for Error {
     }
 }
 
+impl From<TrustedSetupError> for Error {
+    fn from(value: TrustedSetupError) -> Self {
+        Self::TrustedSetup(value)
+    }
+}
+
+impl From<AdmissionError> for Error {
+    fn from(value: AdmissionError) -> Self {
+        Self::Admission(value)
+    }
+}
+
+/// Errors returned when the admission limits of a context turn an operation away, see
+/// [`crate::DASContext::with_admission_limits`].
+#[derive(Debug)]
+pub enum AdmissionError {
+    /// The queue of operations that wait for a slot was full.
+    QueueFull {
+        /// Maximum number of operations in the queue.
+        max_queued: usize,
+    },
+    /// The operation waited in the queue for longer than the timeout.
+    TimedOut {
+        /// How long the operation waited.
+        timeout: Duration,
+    },
+}
+
 /// Errors that can occur while calling a method in the Prover API
 #[derive(Debug)]
 pub enum ProverError {
     /// Underlying recovery failure encountered during proving.
     RecoveryFailure(RecoveryError),
+    /// A cell index was out of the valid range for the extended blob.
+    CellIndexOutOfRange {
+        /// Invalid cell index requested.
+        cell_index: CellIndex,
+        /// Maximum allowed number of cells.
+        max_number_of_cells: u64,
+    },
+    /// A prover method was called on a context that was built with
+    /// [`crate::DASContextBuilder::verifier_only`], which has no prover tables.
+    VerifierOnlyContext,
 }
 
 impl From<RecoveryError> for ProverError {
@@ -116,6 +175,18 @@ pub enum RecoveryError {
     },
     /// Cell indices provided for reconstruction are not in ascending order and unique.
     CellIndicesNotUniquelyOrdered,
+    /// A column given to [`crate::DASContext::recover_columns`] did not have a cell and
+    /// proof for every blob.
+    ColumnHasWrongNumberOfCells {
+        /// Index of the column.
+        column_index: CellIndex,
+        /// Number of cells in the column.
+        num_cells: usize,
+        /// Number of proofs in the column.
+        num_proofs: usize,
+        /// Number of blobs in the block.
+        num_blobs: usize,
+    },
     /// Failure in the underlying Reed-Solomon decoding.
     ReedSolomon(RSError),
 }
@@ -156,6 +227,15 @@ pub enum VerifierError {
         /// Length of proofs input.
         proofs_len: usize,
     },
+    /// The blobs, commitments and cell proofs of a block did not have consistent lengths.
+    BlobsCommitmentsAndCellProofsMustHaveMatchingLengths {
+        /// Number of blobs.
+        blobs_len: usize,
+        /// Number of commitments, which must equal the number of blobs.
+        commitments_len: usize,
+        /// Number of cell proofs, which must be `CELLS_PER_EXT_BLOB` per blob.
+        cell_proofs_len: usize,
+    },
     /// Failure in FK20 batch proof verification.
     FK20(kzg_multi_open::VerifierError),
     /// The polynomial had an unexpected length.
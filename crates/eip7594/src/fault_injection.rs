@@ -0,0 +1,328 @@
+//! Deterministic faults for testing how an application handles the failures of a context.
+//!
+//! Consensus clients have error handling and peer scoring paths that only run when a proof
+//! does not verify or a blob cannot be proven, which real data rarely triggers. A
+//! [`FaultInjector`] makes chosen calls of a context fail or return corrupted outputs, so that
+//! those paths can be tested deterministically:
+//!
+//! ```
+//! # use std::sync::Arc;
+//! # use rust_eth_kzg::{fault_injection::{Fault, FaultInjector}, DASContext, ErrorCode, Operation};
+//! let faults = Arc::new(
+//!     FaultInjector::new()
+//!         // The second verification of a batch of cells says that a proof is invalid
+//!         .inject(Operation::VerifyCellKzgProofBatch, 2, Fault::Error(ErrorCode::InvalidProof))
+//!         // Every third commitment is wrong
+//!         .inject_every(Operation::BlobToKzgCommitment, 3, Fault::CorruptOutput),
+//! );
+//! let ctx = DASContext::default().with_fault_injector(faults.clone());
+//! ```
+//!
+//! This is only meant for tests: the `fault-injection` feature must never be enabled in a
+//! production build.
+
+use std::sync::atomic::{AtomicU64, Ordering};
+
+use crate::{constants::CELLS_PER_EXT_BLOB, Cell, Error, ErrorCode, KZGProof, Operation};
+
+/// What happens to a call that a fault is injected into.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Fault {
+    /// The call returns an error with this code, without doing any work.
+    ///
+    /// [`Error::is_proof_invalid`] is true for [`ErrorCode::InvalidProof`], so an injected
+    /// invalid proof is handled like a real one.
+    Error(ErrorCode),
+    /// The call does its work, then a bit of its output is flipped.
+    ///
+    /// The last byte of a commitment, proof, cell or evaluation is changed, and for methods
+    /// that return the cells and proofs of a blob, the last proof is. Verification methods
+    /// have no output to corrupt, so they return an invalid proof error instead, as
+    /// if the proof that was checked had been corrupted.
+    CorruptOutput,
+}
+
+/// The calls of an operation that a fault is injected into.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Calls {
+    /// The call with this number, counting from 1.
+    At(u64),
+    /// Every call whose number is a multiple of this period.
+    Every(u64),
+}
+
+#[derive(Debug, Clone, Copy)]
+struct Rule {
+    operation: Operation,
+    calls: Calls,
+    fault: Fault,
+}
+
+/// Injects faults into chosen calls of the contexts that it is registered with, see
+/// [`DASContext::with_fault_injector`](crate::DASContext::with_fault_injector).
+///
+/// The calls of each operation are numbered from 1, in the order in which they are made, and
+/// clones of a context share the numbering. When several faults are configured for the same
+/// call, the first one that was configured is injected.
+#[derive(Debug)]
+pub struct FaultInjector {
+    rules: Vec<Rule>,
+    /// The number of calls of each operation so far, indexed like `Operation::ALL`.
+    calls: [AtomicU64; Operation::ALL.len()],
+    injected: AtomicU64,
+}
+
+impl Default for FaultInjector {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+impl FaultInjector {
+    /// Creates an injector that does not inject any fault yet.
+    pub fn new() -> Self {
+        Self {
+            rules: Vec::new(),
+            calls: std::array::from_fn(|_| AtomicU64::new(0)),
+            injected: AtomicU64::new(0),
+        }
+    }
+
+    /// Injects `fault` into the call of `operation` with number `call`, counting from 1.
+    #[must_use]
+    pub fn inject(mut self, operation: Operation, call: u64, fault: Fault) -> Self {
+        self.rules.push(Rule {
+            operation,
+            calls: Calls::At(call),
+            fault,
+        });
+        self
+    }
+
+    /// Injects `fault` into every call of `operation` whose number is a multiple of `period`,
+    /// ie into one call out of `period`.
+    ///
+    /// A period of 0 does not inject anything.
+    #[must_use]
+    pub fn inject_every(mut self, operation: Operation, period: u64, fault: Fault) -> Self {
+        self.rules.push(Rule {
+            operation,
+            calls: Calls::Every(period),
+            fault,
+        });
+        self
+    }
+
+    /// Restarts the numbering of the calls, so that the configured faults are injected again.
+    pub fn reset(&self) {
+        for calls in &self.calls {
+            calls.store(0, Ordering::Relaxed);
+        }
+        self.injected.store(0, Ordering::Relaxed);
+    }
+
+    /// Returns the number of calls of `operation` so far, including the failed ones.
+    pub fn calls(&self, operation: Operation) -> u64 {
+        self.calls[Self::index(operation)].load(Ordering::Relaxed)
+    }
+
+    /// Returns the number of faults injected so far.
+    pub fn injected(&self) -> u64 {
+        self.injected.load(Ordering::Relaxed)
+    }
+
+    fn index(operation: Operation) -> usize {
+        operation.as_u32() as usize - 1
+    }
+
+    /// Counts a call of `operation`, and returns the fault to inject into it, if any.
+    pub(crate) fn next_fault(&self, operation: Operation) -> Option<Fault> {
+        let call = self.calls[Self::index(operation)].fetch_add(1, Ordering::Relaxed) + 1;
+        let fault = self
+            .rules
+            .iter()
+            .filter(|rule| rule.operation == operation)
+            .find(|rule| match rule.calls {
+                Calls::At(at) => call == at,
+                Calls::Every(period) => period != 0 && call % period == 0,
+            })
+            .map(|rule| rule.fault);
+        if fault.is_some() {
+            self.injected.fetch_add(1, Ordering::Relaxed);
+        }
+        fault
+    }
+}
+
+/// An output of a method of a context, that [`Fault::CorruptOutput`] can corrupt.
+pub(crate) trait Corruptible: Sized {
+    /// Returns a corrupted copy of the output, or the error that replaces it.
+    fn corrupt(self) -> Result<Self, Error>;
+}
+
+impl Corruptible for () {
+    fn corrupt(self) -> Result<Self, Error> {
+        Err(Error::Injected(ErrorCode::InvalidProof))
+    }
+}
+
+impl<const N: usize> Corruptible for [u8; N] {
+    fn corrupt(mut self) -> Result<Self, Error> {
+        if let Some(last) = self.last_mut() {
+            *last ^= 1;
+        }
+        Ok(self)
+    }
+}
+
+impl<const N: usize> Corruptible for Box<[u8; N]> {
+    fn corrupt(mut self) -> Result<Self, Error> {
+        if let Some(last) = self.last_mut() {
+            *last ^= 1;
+        }
+        Ok(self)
+    }
+}
+
+impl Corruptible for [Cell; CELLS_PER_EXT_BLOB] {
+    fn corrupt(mut self) -> Result<Self, Error> {
+        if let Some(last) = self.last_mut().and_then(|cell| cell.last_mut()) {
+            *last ^= 1;
+        }
+        Ok(self)
+    }
+}
+
+impl Corruptible for [KZGProof; CELLS_PER_EXT_BLOB] {
+    fn corrupt(mut self) -> Result<Self, Error> {
+        let last = self.len() - 1;
+        self[last] = self[last].corrupt()?;
+        Ok(self)
+    }
+}
+
+/// The cells that were written into a [`Scratch`](crate::Scratch) are corrupted in place.
+impl Corruptible for &mut [Cell; CELLS_PER_EXT_BLOB] {
+    fn corrupt(self) -> Result<Self, Error> {
+        if let Some(last) = self.last_mut().and_then(|cell| cell.last_mut()) {
+            *last ^= 1;
+        }
+        Ok(self)
+    }
+}
+
+/// The proofs that were written into a [`Scratch`](crate::Scratch) are corrupted in place.
+impl Corruptible for &mut [KZGProof; CELLS_PER_EXT_BLOB] {
+    fn corrupt(self) -> Result<Self, Error> {
+        let last = self.len() - 1;
+        self[last] = self[last].corrupt()?;
+        Ok(self)
+    }
+}
+
+impl<A, B: Corruptible> Corruptible for (A, B) {
+    fn corrupt(self) -> Result<Self, Error> {
+        Ok((self.0, self.1.corrupt()?))
+    }
+}
+
+impl<A, B, C: Corruptible> Corruptible for (A, B, C) {
+    fn corrupt(self) -> Result<Self, Error> {
+        Ok((self.0, self.1, self.2.corrupt()?))
+    }
+}
+
+/// Only the output for the last blob of a batch is corrupted.
+impl<T: Corruptible> Corruptible for Vec<T> {
+    fn corrupt(mut self) -> Result<Self, Error> {
+        if let Some(last) = self.pop() {
+            self.push(last.corrupt()?);
+        }
+        Ok(self)
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use std::sync::Arc;
+
+    use super::*;
+    use crate::{constants::BYTES_PER_BLOB, DASContext};
+
+    #[test]
+    fn faults_are_injected_at_the_chosen_calls() {
+        let faults = FaultInjector::new()
+            .inject(Operation::ComputeKzgProof, 2, Fault::CorruptOutput)
+            .inject_every(
+                Operation::ComputeKzgProof,
+                3,
+                Fault::Error(ErrorCode::InvalidArgument),
+            );
+
+        let injected: Vec<_> = (0..6)
+            .map(|_| faults.next_fault(Operation::ComputeKzgProof))
+            .collect();
+        assert_eq!(
+            injected,
+            [
+                None,
+                Some(Fault::CorruptOutput),
+                Some(Fault::Error(ErrorCode::InvalidArgument)),
+                None,
+                None,
+                Some(Fault::Error(ErrorCode::InvalidArgument)),
+            ]
+        );
+        assert_eq!(faults.calls(Operation::ComputeKzgProof), 6);
+        assert_eq!(faults.calls(Operation::VerifyKzgProof), 0);
+        assert_eq!(faults.injected(), 3);
+
+        faults.reset();
+        assert_eq!(faults.next_fault(Operation::ComputeKzgProof), None);
+        assert_eq!(
+            faults.next_fault(Operation::ComputeKzgProof),
+            Some(Fault::CorruptOutput)
+        );
+        assert_eq!(faults.calls(Operation::ComputeKzgProof), 2);
+    }
+
+    #[test]
+    fn context_returns_injected_faults() {
+        let faults = Arc::new(
+            FaultInjector::new()
+                .inject(
+                    Operation::BlobToKzgCommitment,
+                    1,
+                    Fault::Error(ErrorCode::InvalidProof),
+                )
+                .inject(Operation::BlobToKzgCommitment, 2, Fault::CorruptOutput)
+                .inject(Operation::VerifyBlobKzgProof, 1, Fault::CorruptOutput),
+        );
+        let ctx = DASContext::default();
+        let faulty = ctx.clone().with_fault_injector(faults.clone());
+        let blob = [0u8; BYTES_PER_BLOB];
+
+        let err = faulty.blob_to_kzg_commitment(&blob).unwrap_err();
+        assert_eq!(err.code(), ErrorCode::InvalidProof);
+        assert!(err.is_proof_invalid());
+
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let corrupted = faulty.blob_to_kzg_commitment(&blob).unwrap();
+        assert_ne!(corrupted, commitment);
+        assert_eq!(faulty.blob_to_kzg_commitment(&blob).unwrap(), commitment);
+
+        // A valid proof is reported as invalid
+        let proof = ctx.compute_blob_kzg_proof(&blob, &commitment).unwrap();
+        let err = faulty
+            .verify_blob_kzg_proof(&blob, &commitment, &proof)
+            .unwrap_err();
+        assert!(err.is_proof_invalid());
+        faulty
+            .verify_blob_kzg_proof(&blob, &commitment, &proof)
+            .unwrap();
+
+        assert_eq!(faults.injected(), 3);
+        // The context the injector was not registered with is not affected
+        assert_eq!(faults.calls(Operation::BlobToKzgCommitment), 3);
+    }
+}
@@ -0,0 +1,430 @@
+//! Generators of blobs, cells, cell indices and G1 points for property testing.
+//!
+//! Each input has a valid generator and an "almost valid" one, which produces inputs that
+//! are well formed except for one detail, such as a single field element that is not
+//! reduced modulo the scalar field, or a point that is on the curve but not in the G1
+//! subgroup. Downstream clients can use these to check that their integration code
+//! forwards inputs unchanged and handles the errors of the library.
+//!
+//! The generators are exposed as [`arbitrary::Arbitrary`] implementations on newtypes
+//! when the `arbitrary` feature is enabled, and as proptest strategies in [`strategies`]
+//! when the `proptest` feature is enabled. The inputs are expanded from a seed, so that
+//! fuzzers do not need to produce a full 128KiB blob.
+
+use bls12_381::{traits::*, G1Projective, Scalar};
+use serialization::{
+    constants::{
+        BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_FIELD_ELEMENT, BYTES_PER_G1_POINT,
+        CELLS_PER_EXT_BLOB,
+    },
+    serialize_g1_compressed,
+    types::Cell,
+};
+
+use crate::CellIndex;
+
+/// A compressed G1 point that is on the curve but not in the G1 subgroup.
+///
+/// This is `P1_NOT_IN_G1` from the consensus specs tests.
+const P1_NOT_IN_G1: [u8; BYTES_PER_G1_POINT] = [
+    0x81, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+    0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+    0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+];
+
+/// A compressed G1 point whose x coordinate is not on the curve.
+///
+/// This is `P1_NOT_ON_CURVE` from the consensus specs tests.
+const P1_NOT_ON_CURVE: [u8; BYTES_PER_G1_POINT] = [
+    0x81, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+    0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+    0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xe0,
+];
+
+/// The base field modulus in big-endian, which is not a canonical x coordinate.
+const FIELD_MODULUS: [u8; BYTES_PER_G1_POINT] = [
+    0x1a, 0x01, 0x11, 0xea, 0x39, 0x7f, 0xe6, 0x9a, 0x4b, 0x1b, 0xa7, 0xb6, 0x43, 0x4b, 0xac, 0xd7,
+    0x64, 0x77, 0x4b, 0x84, 0xf3, 0x85, 0x12, 0xbf, 0x67, 0x30, 0xd2, 0xa0, 0xf6, 0xb0, 0xf6, 0x24,
+    0x1e, 0xab, 0xff, 0xfe, 0xb1, 0x53, 0xff, 0xff, 0xb9, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xaa, 0xab,
+];
+
+/// The most significant byte of the scalar field modulus, `0x73eda753...`.
+///
+/// A big-endian field element whose first byte is smaller is always canonical,
+/// and one whose first byte is larger never is.
+const SCALAR_MODULUS_TOP_BYTE: u8 = 0x73;
+
+/// The ways in which [`invalid_g1_point`] makes a point encoding invalid.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+pub enum InvalidPoint {
+    /// The point is on the curve, but not in the G1 subgroup.
+    NotInSubgroup,
+    /// The x coordinate does not correspond to a point on the curve.
+    NotOnCurve,
+    /// The x coordinate is not reduced modulo the base field.
+    NonCanonicalX,
+    /// The point is valid, but its compression flag is not set.
+    MissingCompressionFlag,
+    /// The infinity flag is set, but the remaining bits are not all zero.
+    InfinityWithData,
+}
+
+impl InvalidPoint {
+    /// Every kind of invalid point.
+    pub const ALL: [Self; 5] = [
+        Self::NotInSubgroup,
+        Self::NotOnCurve,
+        Self::NonCanonicalX,
+        Self::MissingCompressionFlag,
+        Self::InfinityWithData,
+    ];
+}
+
+/// Expands a seed into a stream of pseudo-random bytes, using SplitMix64.
+struct Expander(u64);
+
+impl Expander {
+    fn next_u64(&mut self) -> u64 {
+        self.0 = self.0.wrapping_add(0x9e37_79b9_7f4a_7c15);
+        let mut z = self.0;
+        z = (z ^ (z >> 30)).wrapping_mul(0xbf58_476d_1ce4_e5b9);
+        z = (z ^ (z >> 27)).wrapping_mul(0x94d0_49bb_1331_11eb);
+        z ^ (z >> 31)
+    }
+
+    fn fill(&mut self, out: &mut [u8]) {
+        for chunk in out.chunks_mut(8) {
+            chunk.copy_from_slice(&self.next_u64().to_le_bytes()[..chunk.len()]);
+        }
+    }
+
+    /// Fills `out` with canonical big-endian field elements.
+    fn fill_field_elements(&mut self, out: &mut [u8]) {
+        self.fill(out);
+        for element in out.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT) {
+            element[0] %= SCALAR_MODULUS_TOP_BYTE;
+        }
+    }
+}
+
+/// Replaces the field element at `index` of `out` with one that is larger than the modulus.
+fn make_non_canonical(out: &mut [u8], index: usize, seed: u64) {
+    let num_elements = out.len() / BYTES_PER_FIELD_ELEMENT;
+    let start = (index % num_elements) * BYTES_PER_FIELD_ELEMENT;
+    let element = &mut out[start..start + BYTES_PER_FIELD_ELEMENT];
+    element[0] =
+        SCALAR_MODULUS_TOP_BYTE + 1 + (seed % u64::from(u8::MAX - SCALAR_MODULUS_TOP_BYTE)) as u8;
+}
+
+/// Returns a canonical field element, to be used as an evaluation point or value.
+pub fn field_element(seed: u64) -> [u8; BYTES_PER_FIELD_ELEMENT] {
+    let mut element = [0u8; BYTES_PER_FIELD_ELEMENT];
+    Expander(seed).fill_field_elements(&mut element);
+    element
+}
+
+/// Returns a field element that is larger than the modulus.
+pub fn almost_valid_field_element(seed: u64) -> [u8; BYTES_PER_FIELD_ELEMENT] {
+    let mut element = field_element(seed);
+    make_non_canonical(&mut element, 0, seed);
+    element
+}
+
+/// Returns a blob whose field elements are all canonical.
+pub fn blob(seed: u64) -> Box<[u8; BYTES_PER_BLOB]> {
+    let mut blob = Box::new([0u8; BYTES_PER_BLOB]);
+    Expander(seed).fill_field_elements(blob.as_mut_slice());
+    blob
+}
+
+/// Returns a blob whose field element at `index` is not canonical.
+pub fn almost_valid_blob(seed: u64, index: usize) -> Box<[u8; BYTES_PER_BLOB]> {
+    let mut blob = blob(seed);
+    make_non_canonical(blob.as_mut_slice(), index, seed);
+    blob
+}
+
+/// Returns a cell whose field elements are all canonical.
+///
+/// The cell is not the evaluation of a blob, so it only verifies against a proof made for it.
+pub fn cell(seed: u64) -> Cell {
+    let mut cell = Box::new([0u8; BYTES_PER_CELL]);
+    Expander(seed).fill_field_elements(cell.as_mut_slice());
+    cell
+}
+
+/// Returns a cell whose field element at `index` is not canonical.
+pub fn almost_valid_cell(seed: u64, index: usize) -> Cell {
+    let mut cell = cell(seed);
+    make_non_canonical(cell.as_mut_slice(), index, seed);
+    cell
+}
+
+/// Returns a sorted set of distinct cell indices, each index being included with probability 1/2.
+pub fn cell_indices(seed: u64) -> Vec<CellIndex> {
+    let mut expander = Expander(seed);
+    (0..CELLS_PER_EXT_BLOB as CellIndex)
+        .filter(|_| expander.next_u64() & 1 == 1)
+        .collect()
+}
+
+/// Returns a set of cell indices that contains either a duplicate or an index that is out of range.
+pub fn almost_valid_cell_indices(seed: u64) -> Vec<CellIndex> {
+    let mut indices = cell_indices(seed);
+    match indices.first() {
+        Some(&first) if seed & 1 == 0 => indices.push(first),
+        _ => indices.push(CELLS_PER_EXT_BLOB as CellIndex + (seed >> 1) % 1024),
+    }
+    indices
+}
+
+/// Returns a valid compressed G1 point, to be used as a commitment or a proof.
+pub fn g1_point(seed: u64) -> [u8; BYTES_PER_G1_POINT] {
+    let point = G1Projective::generator() * Scalar::from(seed);
+    serialize_g1_compressed(&point.to_affine())
+}
+
+/// Returns a G1 point encoding that is invalid in the way given by `kind`.
+pub fn invalid_g1_point(kind: InvalidPoint, seed: u64) -> [u8; BYTES_PER_G1_POINT] {
+    // The sort flag selects the larger of the two y coordinates, so flipping it
+    // gives the negation of the point, which is just as invalid.
+    let sort_flag = if seed & 1 == 0 { 0 } else { 0x20 };
+    match kind {
+        InvalidPoint::NotInSubgroup => {
+            let mut point = P1_NOT_IN_G1;
+            point[0] ^= sort_flag;
+            point
+        }
+        InvalidPoint::NotOnCurve => P1_NOT_ON_CURVE,
+        InvalidPoint::NonCanonicalX => {
+            let mut point = FIELD_MODULUS;
+            point[0] |= 0x80 | sort_flag;
+            point
+        }
+        InvalidPoint::MissingCompressionFlag => {
+            let mut point = g1_point(seed);
+            point[0] &= 0x7f;
+            point
+        }
+        InvalidPoint::InfinityWithData => {
+            let mut point = [0u8; BYTES_PER_G1_POINT];
+            point[0] = 0xc0;
+            point[1 + (seed as usize >> 1) % (BYTES_PER_G1_POINT - 1)] = 1;
+            point
+        }
+    }
+}
+
+/// `Arbitrary` newtypes over the generators.
+#[cfg(feature = "arbitrary")]
+mod arbitrary_impls {
+    use arbitrary::{Arbitrary, Result, Unstructured};
+    use serialization::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_G1_POINT},
+        types::Cell,
+    };
+
+    use super::InvalidPoint;
+    use crate::CellIndex;
+
+    /// A blob whose field elements are all canonical.
+    #[derive(Debug, Clone)]
+    pub struct ArbitraryBlob(pub Box<[u8; BYTES_PER_BLOB]>);
+
+    /// A blob with a single field element that is not canonical.
+    #[derive(Debug, Clone)]
+    pub struct AlmostValidBlob(pub Box<[u8; BYTES_PER_BLOB]>);
+
+    /// A cell whose field elements are all canonical.
+    #[derive(Debug, Clone)]
+    pub struct ArbitraryCell(pub Cell);
+
+    /// A cell with a single field element that is not canonical.
+    #[derive(Debug, Clone)]
+    pub struct AlmostValidCell(pub Cell);
+
+    /// A sorted set of distinct cell indices.
+    #[derive(Debug, Clone)]
+    pub struct ArbitraryCellIndices(pub Vec<CellIndex>);
+
+    /// Cell indices containing a duplicate or an out of range index.
+    #[derive(Debug, Clone)]
+    pub struct AlmostValidCellIndices(pub Vec<CellIndex>);
+
+    /// A valid commitment or proof.
+    #[derive(Debug, Clone, Copy)]
+    pub struct ArbitraryG1Point(pub [u8; BYTES_PER_G1_POINT]);
+
+    /// An invalid commitment or proof, such as a point outside of the G1 subgroup.
+    #[derive(Debug, Clone, Copy)]
+    pub struct AlmostValidG1Point(pub [u8; BYTES_PER_G1_POINT]);
+
+    impl<'a> Arbitrary<'a> for ArbitraryBlob {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::blob(u.arbitrary()?)))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for AlmostValidBlob {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::almost_valid_blob(
+                u.arbitrary()?,
+                u.arbitrary()?,
+            )))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for ArbitraryCell {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::cell(u.arbitrary()?)))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for AlmostValidCell {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::almost_valid_cell(
+                u.arbitrary()?,
+                u.arbitrary()?,
+            )))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for ArbitraryCellIndices {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::cell_indices(u.arbitrary()?)))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for AlmostValidCellIndices {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::almost_valid_cell_indices(u.arbitrary()?)))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for ArbitraryG1Point {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            Ok(Self(super::g1_point(u.arbitrary()?)))
+        }
+    }
+
+    impl<'a> Arbitrary<'a> for AlmostValidG1Point {
+        fn arbitrary(u: &mut Unstructured<'a>) -> Result<Self> {
+            let kind: InvalidPoint = u.arbitrary()?;
+            Ok(Self(super::invalid_g1_point(kind, u.arbitrary()?)))
+        }
+    }
+}
+
+#[cfg(feature = "arbitrary")]
+pub use arbitrary_impls::*;
+
+/// Proptest strategies over the generators.
+#[cfg(feature = "proptest")]
+pub mod strategies {
+    use proptest::prelude::*;
+    use serialization::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_G1_POINT},
+        types::Cell,
+    };
+
+    use super::InvalidPoint;
+    use crate::CellIndex;
+
+    /// A blob whose field elements are all canonical.
+    pub fn blob() -> impl Strategy<Value = Box<[u8; BYTES_PER_BLOB]>> {
+        any::<u64>().prop_map(super::blob)
+    }
+
+    /// A blob with a single field element that is not canonical.
+    pub fn almost_valid_blob() -> impl Strategy<Value = Box<[u8; BYTES_PER_BLOB]>> {
+        (any::<u64>(), any::<usize>())
+            .prop_map(|(seed, index)| super::almost_valid_blob(seed, index))
+    }
+
+    /// A cell whose field elements are all canonical.
+    pub fn cell() -> impl Strategy<Value = Cell> {
+        any::<u64>().prop_map(super::cell)
+    }
+
+    /// A cell with a single field element that is not canonical.
+    pub fn almost_valid_cell() -> impl Strategy<Value = Cell> {
+        (any::<u64>(), any::<usize>())
+            .prop_map(|(seed, index)| super::almost_valid_cell(seed, index))
+    }
+
+    /// A sorted set of distinct cell indices.
+    pub fn cell_indices() -> impl Strategy<Value = Vec<CellIndex>> {
+        any::<u64>().prop_map(super::cell_indices)
+    }
+
+    /// Cell indices containing a duplicate or an out of range index.
+    pub fn almost_valid_cell_indices() -> impl Strategy<Value = Vec<CellIndex>> {
+        any::<u64>().prop_map(super::almost_valid_cell_indices)
+    }
+
+    /// A valid commitment or proof.
+    pub fn g1_point() -> impl Strategy<Value = [u8; BYTES_PER_G1_POINT]> {
+        any::<u64>().prop_map(super::g1_point)
+    }
+
+    /// An invalid commitment or proof, such as a point outside of the G1 subgroup.
+    pub fn almost_valid_g1_point() -> impl Strategy<Value = [u8; BYTES_PER_G1_POINT]> {
+        (
+            prop::sample::select(InvalidPoint::ALL.to_vec()),
+            any::<u64>(),
+        )
+            .prop_map(|(kind, seed)| super::invalid_g1_point(kind, seed))
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use serialization::{deserialize_cells, deserialize_compressed_g1};
+
+    use super::*;
+    use crate::DASContext;
+
+    #[test]
+    fn valid_inputs_are_accepted() {
+        let ctx = DASContext::default();
+        for seed in 0..4 {
+            assert!(ctx.blob_to_kzg_commitment(&blob(seed)).is_ok());
+            assert!(deserialize_cells(vec![&*cell(seed)]).is_ok());
+            assert!(deserialize_compressed_g1(&g1_point(seed)).is_ok());
+
+            let indices = cell_indices(seed);
+            assert!(indices.windows(2).all(|pair| pair[0] < pair[1]));
+            assert!(indices
+                .iter()
+                .all(|&index| index < CELLS_PER_EXT_BLOB as u64));
+        }
+    }
+
+    #[test]
+    fn almost_valid_inputs_are_rejected() {
+        let ctx = DASContext::default();
+        for seed in 0..4 {
+            assert!(ctx
+                .blob_to_kzg_commitment(&almost_valid_blob(seed, seed as usize * 7))
+                .is_err());
+            assert!(deserialize_cells(vec![&*almost_valid_cell(seed, 3)]).is_err());
+            for kind in InvalidPoint::ALL {
+                assert!(
+                    deserialize_compressed_g1(&invalid_g1_point(kind, seed)).is_err(),
+                    "{kind:?} with seed {seed} was accepted"
+                );
+            }
+
+            let indices = almost_valid_cell_indices(seed);
+            let mut deduplicated = indices.clone();
+            deduplicated.dedup();
+            assert!(
+                deduplicated.len() < indices.len()
+                    || indices
+                        .iter()
+                        .any(|&index| index >= CELLS_PER_EXT_BLOB as u64)
+            );
+        }
+    }
+}
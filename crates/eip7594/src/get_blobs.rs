@@ -0,0 +1,195 @@
+use crate::{
+    constants::CELLS_PER_EXT_BLOB,
+    errors::{Error, VerifierError},
+    BlobRef, Bytes48Ref, Cell, CellIndex, CellRef, DASContext, KZGProof,
+};
+
+/// The cell at `index` of every blob of a block, with their proofs.
+///
+/// These are the `column` and `kzg_proofs` of the `DataColumnSidecar` at `index`, in the
+/// order of the blobs of the block.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct DataColumn {
+    pub index: CellIndex,
+    pub cells: Vec<Cell>,
+    pub proofs: Vec<KZGProof>,
+}
+
+impl DASContext {
+    /// Checks the blobs and cell proofs returned by `engine_getBlobsV2` against the
+    /// commitments of a block, and returns the data columns of the block.
+    ///
+    /// `blobs` and `commitments` are in the order of the block, and `cell_proofs` holds the
+    /// `CELLS_PER_EXT_BLOB` proofs of each blob, one blob after the other, which is the
+    /// concatenation of the `proofs` of each `BlobAndProofV2`. The cells of every blob are
+    /// computed and checked against its commitment and proofs in a single batch, so the
+    /// columns are only returned if every cell of the block is valid. The column at position
+    /// `i` of the result has index `i`.
+    ///
+    /// Computing the cells needs the prover tables, so a context built with
+    /// [`crate::DASContextBuilder::verifier_only`] returns
+    /// [`crate::ErrorCode::VerifierOnlyContext`].
+    pub fn compute_columns_from_blobs_and_cell_proofs(
+        &self,
+        blobs: Vec<BlobRef>,
+        commitments: Vec<Bytes48Ref>,
+        cell_proofs: Vec<Bytes48Ref>,
+    ) -> Result<Vec<DataColumn>, Error> {
+        let cells = self.compute_and_verify_cells(blobs, &commitments, &cell_proofs)?;
+
+        // Transpose the rows of cells into columns
+        let mut columns: Vec<_> = (0..CELLS_PER_EXT_BLOB)
+            .map(|index| DataColumn {
+                index: index as CellIndex,
+                cells: Vec::with_capacity(cells.len()),
+                proofs: Vec::with_capacity(cells.len()),
+            })
+            .collect();
+        for (row, proofs) in cells
+            .into_iter()
+            .zip(cell_proofs.chunks_exact(CELLS_PER_EXT_BLOB))
+        {
+            for ((column, cell), proof) in columns.iter_mut().zip(row).zip(proofs) {
+                column.cells.push(cell);
+                column.proofs.push(**proof);
+            }
+        }
+
+        Ok(columns)
+    }
+
+    /// Computes the cells of each blob, and checks all of them against the commitments and
+    /// cell proofs of the blobs in a single batch.
+    pub(crate) fn compute_and_verify_cells(
+        &self,
+        blobs: Vec<BlobRef>,
+        commitments: &[Bytes48Ref],
+        cell_proofs: &[Bytes48Ref],
+    ) -> Result<Vec<[Cell; CELLS_PER_EXT_BLOB]>, Error> {
+        // Validation
+        if commitments.len() != blobs.len() || cell_proofs.len() != blobs.len() * CELLS_PER_EXT_BLOB
+        {
+            return Err(
+                VerifierError::BlobsCommitmentsAndCellProofsMustHaveMatchingLengths {
+                    blobs_len: blobs.len(),
+                    commitments_len: commitments.len(),
+                    cell_proofs_len: cell_proofs.len(),
+                }
+                .into(),
+            );
+        }
+
+        // Computation
+        let cells = blobs
+            .into_iter()
+            .map(|blob| self.compute_cells(blob))
+            .collect::<Result<Vec<_>, _>>()?;
+
+        // Check every cell against the commitment of its blob, so that a blob which does
+        // not match its commitment is rejected along with a proof which does not verify.
+        let cell_commitments = commitments
+            .iter()
+            .flat_map(|&commitment| std::iter::repeat_n(commitment, CELLS_PER_EXT_BLOB))
+            .collect();
+        let cell_indices: Vec<CellIndex> = (0..cells.len())
+            .flat_map(|_| 0..CELLS_PER_EXT_BLOB as CellIndex)
+            .collect();
+        let cell_refs: Vec<CellRef> = cells.iter().flatten().map(|cell| &**cell).collect();
+        self.verify_cell_kzg_proof_batch(
+            cell_commitments,
+            &cell_indices,
+            cell_refs,
+            cell_proofs.to_vec(),
+        )?;
+
+        Ok(cells)
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::{
+        constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
+        generators, DASContext, ErrorCode, KZGCommitment, KZGProof,
+    };
+
+    /// Returns the blobs, commitments and cell proofs of a block, as `engine_getBlobsV2` does.
+    fn engine_blobs(
+        ctx: &DASContext,
+        num_blobs: u64,
+    ) -> (
+        Vec<Box<[u8; BYTES_PER_BLOB]>>,
+        Vec<KZGCommitment>,
+        Vec<KZGProof>,
+    ) {
+        let blobs: Vec<_> = (0..num_blobs).map(generators::blob).collect();
+        let commitments = blobs
+            .iter()
+            .map(|blob| ctx.blob_to_kzg_commitment(blob).unwrap())
+            .collect();
+        let proofs = blobs
+            .iter()
+            .flat_map(|blob| ctx.compute_cells_and_kzg_proofs(blob).unwrap().1)
+            .collect();
+        (blobs, commitments, proofs)
+    }
+
+    #[test]
+    fn columns_match_the_cells_of_each_blob() {
+        let ctx = DASContext::default();
+        let (blobs, commitments, proofs) = engine_blobs(&ctx, 2);
+
+        let columns = ctx
+            .compute_columns_from_blobs_and_cell_proofs(
+                blobs.iter().map(|blob| &**blob).collect(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            )
+            .unwrap();
+
+        assert_eq!(columns.len(), CELLS_PER_EXT_BLOB);
+        let (cells, blob_proofs) = ctx.compute_cells_and_kzg_proofs(&blobs[1]).unwrap();
+        for (index, column) in columns.iter().enumerate() {
+            assert_eq!(column.index, index as u64);
+            assert_eq!(column.cells[1], cells[index]);
+            assert_eq!(column.proofs[1], blob_proofs[index]);
+        }
+    }
+
+    #[test]
+    fn rejects_mismatched_blobs_and_proofs() {
+        let ctx = DASContext::default();
+        let (blobs, mut commitments, mut proofs) = engine_blobs(&ctx, 2);
+        let blob_refs = || blobs.iter().map(|blob| &**blob).collect();
+
+        let err = ctx
+            .compute_columns_from_blobs_and_cell_proofs(
+                blob_refs(),
+                commitments.iter().collect(),
+                proofs[1..].iter().collect(),
+            )
+            .unwrap_err();
+        assert_eq!(err.code(), ErrorCode::BatchLengthMismatch);
+
+        proofs.swap(0, 1);
+        let err = ctx
+            .compute_columns_from_blobs_and_cell_proofs(
+                blob_refs(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            )
+            .unwrap_err();
+        assert!(err.is_proof_invalid());
+
+        proofs.swap(0, 1);
+        commitments.swap(0, 1);
+        let err = ctx
+            .compute_columns_from_blobs_and_cell_proofs(
+                blob_refs(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            )
+            .unwrap_err();
+        assert!(err.is_proof_invalid());
+    }
+}
@@ -0,0 +1,240 @@
+//! Recovery of the columns of a block from a mix of blobs and columns.
+//!
+//! Midway through a slot, a supernode usually holds some of the blobs of a block, from the
+//! mempool of its execution client, and some of the columns, from gossip. Each blob is a full
+//! row of cells, and each column is one cell of every row. [`DASContext::recover_columns`]
+//! rebuilds every column from whatever mix it is given, picking the cheapest way to rebuild
+//! each row.
+
+use crate::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_CELL, CELLS_PER_EXT_BLOB, EXPANSION_FACTOR},
+    errors::{Error, RecoveryError},
+    get_blobs::DataColumn,
+    BlobRef, Cell, CellIndex, CellRef, DASContext, KZGProof,
+};
+
+/// How a row of cells is rebuilt.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum RowPath {
+    /// The blob is held, so its cells are computed from it.
+    Blob,
+    /// The first half of the columns are held, and their cells are the blob, since the
+    /// extension keeps the blob as the first half of the cells.
+    FirstHalfOfColumns,
+    /// The blob is erasure decoded from the cells of the columns that are held.
+    ErasureDecoding,
+}
+
+/// Returns how the row of each of `blobs` is rebuilt from `blobs` and the columns with
+/// `column_indices`, which are sorted.
+fn row_paths(blobs: &[Option<BlobRef>], column_indices: &[CellIndex]) -> Vec<RowPath> {
+    let half = CELLS_PER_EXT_BLOB / EXPANSION_FACTOR;
+    let holds_first_half = column_indices
+        .get(half - 1)
+        .is_some_and(|&index| index == (half - 1) as CellIndex);
+
+    blobs
+        .iter()
+        .map(|blob| match blob {
+            Some(_) => RowPath::Blob,
+            None if holds_first_half => RowPath::FirstHalfOfColumns,
+            None => RowPath::ErasureDecoding,
+        })
+        .collect()
+}
+
+/// Checks that the columns are sorted and unique, and have a cell and proof for each blob.
+fn validate_columns(columns: &[DataColumn], num_blobs: usize) -> Result<(), RecoveryError> {
+    for column in columns {
+        if column.index >= CELLS_PER_EXT_BLOB as u64 {
+            return Err(RecoveryError::CellIndexOutOfRange {
+                cell_index: column.index,
+                max_number_of_cells: CELLS_PER_EXT_BLOB as u64,
+            });
+        }
+        if column.cells.len() != num_blobs || column.proofs.len() != num_blobs {
+            return Err(RecoveryError::ColumnHasWrongNumberOfCells {
+                column_index: column.index,
+                num_cells: column.cells.len(),
+                num_proofs: column.proofs.len(),
+                num_blobs,
+            });
+        }
+    }
+    if !columns.is_sorted_by(|a, b| a.index < b.index) {
+        return Err(RecoveryError::CellIndicesNotUniquelyOrdered);
+    }
+    Ok(())
+}
+
+impl DASContext {
+    /// Rebuilds every column of a block, and their proofs, from the blobs and columns of the
+    /// block that are held.
+    ///
+    /// `blobs` has an entry for every blob of the block, in the order of the block, which is
+    /// `None` for the blobs that are not held. `columns` are the columns that are held, sorted
+    /// by index, with a cell and proof for every blob of the block. The column at position `i`
+    /// of the result has index `i`.
+    ///
+    /// Each row is rebuilt in the cheapest way that the data allows: from the blob if it is
+    /// held, from the first half of the columns if they are all held, since those cells are
+    /// the blob, and otherwise by erasure decoding the cells of the held columns, which needs
+    /// at least half of the columns. The rows that are rebuilt from blobs are proven together.
+    ///
+    /// The blobs and columns are not checked against each other or against the commitments
+    /// of the block, so they should come from verified sidecars, or from an execution client
+    /// that checked them.
+    pub fn recover_columns(
+        &self,
+        blobs: &[Option<BlobRef>],
+        columns: &[DataColumn],
+    ) -> Result<Vec<DataColumn>, Error> {
+        // Validation
+        validate_columns(columns, blobs.len())?;
+        let column_indices: Vec<CellIndex> = columns.iter().map(|column| column.index).collect();
+        let paths = row_paths(blobs, &column_indices);
+        if paths.contains(&RowPath::ErasureDecoding)
+            && columns.len() < CELLS_PER_EXT_BLOB / EXPANSION_FACTOR
+        {
+            return Err(RecoveryError::NotEnoughCellsToReconstruct {
+                num_cells_received: columns.len(),
+                min_cells_needed: CELLS_PER_EXT_BLOB / EXPANSION_FACTOR,
+            }
+            .into());
+        }
+
+        // The blobs of the rows whose first half of cells are held
+        let assembled_blobs: Vec<Box<[u8; BYTES_PER_BLOB]>> = paths
+            .iter()
+            .enumerate()
+            .filter(|(_, path)| **path == RowPath::FirstHalfOfColumns)
+            .map(|(row, _)| {
+                let mut blob: Box<[u8; BYTES_PER_BLOB]> = vec![0u8; BYTES_PER_BLOB]
+                    .into_boxed_slice()
+                    .try_into()
+                    .expect("the vector has BYTES_PER_BLOB bytes");
+                for (chunk, column) in blob.chunks_exact_mut(BYTES_PER_CELL).zip(columns) {
+                    chunk.copy_from_slice(&*column.cells[row]);
+                }
+                blob
+            })
+            .collect();
+
+        // Computation
+        //
+        // The rows that have a blob are proven in one batch
+        let mut assembled = assembled_blobs.iter();
+        let batch_blobs: Vec<BlobRef> = blobs
+            .iter()
+            .zip(&paths)
+            .filter_map(|(blob, path)| match path {
+                RowPath::Blob => *blob,
+                RowPath::FirstHalfOfColumns => assembled.next().map(|blob| &**blob),
+                RowPath::ErasureDecoding => None,
+            })
+            .collect();
+        let mut from_blobs = self
+            .compute_cells_and_kzg_proofs_batch(batch_blobs)?
+            .into_iter();
+
+        let mut rows: Vec<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB])> =
+            Vec::with_capacity(blobs.len());
+        for (row, path) in paths.iter().enumerate() {
+            let cells_and_proofs = if *path == RowPath::ErasureDecoding {
+                let cells: Vec<CellRef> =
+                    columns.iter().map(|column| &*column.cells[row]).collect();
+                self.recover_cells_and_kzg_proofs(column_indices.clone(), cells)?
+            } else {
+                from_blobs
+                    .next()
+                    .expect("a row was proven for every row with a blob")
+            };
+            rows.push(cells_and_proofs);
+        }
+
+        // Transpose the rows of cells into columns
+        let mut recovered: Vec<_> = (0..CELLS_PER_EXT_BLOB)
+            .map(|index| DataColumn {
+                index: index as CellIndex,
+                cells: Vec::with_capacity(rows.len()),
+                proofs: Vec::with_capacity(rows.len()),
+            })
+            .collect();
+        for (cells, proofs) in rows {
+            for ((column, cell), proof) in recovered.iter_mut().zip(cells).zip(proofs) {
+                column.cells.push(cell);
+                column.proofs.push(proof);
+            }
+        }
+
+        Ok(recovered)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::{row_paths, RowPath};
+    use crate::constants::{CELLS_PER_EXT_BLOB, EXPANSION_FACTOR};
+
+    #[test]
+    fn rows_take_the_cheapest_path() {
+        let blob = [0u8; crate::constants::BYTES_PER_BLOB];
+        let blobs = [Some(&blob), None];
+        let half = (CELLS_PER_EXT_BLOB / EXPANSION_FACTOR) as u64;
+
+        let first_half: Vec<u64> = (0..half).collect();
+        assert_eq!(
+            row_paths(&blobs, &first_half),
+            [RowPath::Blob, RowPath::FirstHalfOfColumns]
+        );
+
+        let second_half: Vec<u64> = (half..2 * half).collect();
+        assert_eq!(
+            row_paths(&blobs, &second_half),
+            [RowPath::Blob, RowPath::ErasureDecoding]
+        );
+        assert_eq!(
+            row_paths(&blobs, &[]),
+            [RowPath::Blob, RowPath::ErasureDecoding]
+        );
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn recovers_columns_from_blobs_and_columns() {
+        use crate::{generators, DASContext, ErrorCode};
+
+        let ctx = DASContext::default();
+        let blobs: Vec<_> = (0..3).map(generators::blob).collect();
+        // When every blob is held, no columns are needed
+        let expected = ctx
+            .recover_columns(
+                &blobs.iter().map(|blob| Some(&**blob)).collect::<Vec<_>>(),
+                &[],
+            )
+            .unwrap();
+        let half = CELLS_PER_EXT_BLOB / EXPANSION_FACTOR;
+
+        // The first blob is held, and the other rows are rebuilt from the columns
+        let held_blobs = [Some(&*blobs[0]), None, None];
+        for held in [0..half, half..CELLS_PER_EXT_BLOB, 1..half + 1] {
+            let columns = &expected[held];
+            assert_eq!(ctx.recover_columns(&held_blobs, columns).unwrap(), expected);
+        }
+
+        let err = ctx
+            .recover_columns(&held_blobs, &expected[..half - 1])
+            .unwrap_err();
+        assert_eq!(err.code(), ErrorCode::NotEnoughCellsToReconstruct);
+
+        let mut columns = expected[..half].to_vec();
+        columns.swap(0, 1);
+        let err = ctx.recover_columns(&held_blobs, &columns).unwrap_err();
+        assert_eq!(err.code(), ErrorCode::CellIndicesNotUniquelyOrdered);
+
+        let err = ctx
+            .recover_columns(&held_blobs[..2], &expected[..half])
+            .unwrap_err();
+        assert_eq!(err.code(), ErrorCode::BatchLengthMismatch);
+    }
+}
@@ -1,18 +1,124 @@
+mod admission;
+#[cfg(feature = "alloy")]
+pub mod alloy;
+#[cfg(feature = "tokio")]
+mod async_context;
+// There is no clock on wasm32-unknown-unknown to time the host with.
+#[cfg(not(target_arch = "wasm32"))]
+mod autotune;
+#[cfg(feature = "beacon-api")]
+pub mod beacon_api;
+mod blob_tx;
+mod builder;
+mod custody;
 mod eip4844_methods;
+mod error_code;
 mod errors;
+#[cfg(feature = "fault-injection")]
+pub mod fault_injection;
+#[cfg(any(
+    test,
+    feature = "arbitrary",
+    feature = "proptest",
+    feature = "test-vectors"
+))]
+pub mod generators;
+mod get_blobs;
+mod hybrid_recovery;
+mod memory;
+mod metrics;
+#[cfg(feature = "numa")]
+pub mod numa;
+#[cfg(feature = "paranoid-checks")]
+mod paranoid;
+pub mod prelude;
 mod prover;
 mod recovery;
+mod sampling;
+mod scratch;
+// The known answers are for the mainnet parameters.
+#[cfg(not(feature = "testing"))]
+mod self_test;
+#[cfg(feature = "test-vectors")]
+pub mod test_vectors;
+mod thread_pool;
 mod trusted_setup;
+mod verification_cache;
 mod verifier;
 
 // Exported types
 //
-pub use bls12_381::fixed_base_msm::UsePrecomp;
-pub use errors::Error;
-pub use serialization::{constants, types::*};
+pub use admission::{AdmissionLimits, AdmissionStats};
+#[cfg(feature = "tokio")]
+pub use async_context::{AsyncDASContext, OwnedBlob, OwnedBytes48};
+#[cfg(not(target_arch = "wasm32"))]
+pub use autotune::HostProfile;
+pub use blob_tx::{
+    kzg_to_versioned_hash, BlobTransactionReport, BlobTransactionSidecar, ProofCheck,
+    SidecarProofs, VERSIONED_HASH_VERSION_KZG,
+};
+/// Conversions between [`G1Point`], [`G2Point`], [`Scalar`] and their `ark-bls12-381` types.
+#[cfg(feature = "arkworks")]
+pub use bls12_381::arkworks;
+/// Conversions between [`G1Point`], [`G2Point`], [`Scalar`] and their zkcrypto `bls12_381` types.
+#[cfg(feature = "zkcrypto")]
+pub use bls12_381::zkcrypto;
+/// The `ff` and `group` crates that [`Scalar`], [`G1Point`] and [`G2Point`] implement the traits of.
+pub use bls12_381::{ff, group};
+pub use bls12_381::{fixed_base_msm::UsePrecomp, traits, G1Point, G2Point, Scalar};
+pub use builder::{BuildError, DASContextBuilder};
+pub use custody::{
+    columns_for_custody_group, custody_columns, custody_groups, CustodyChallenge, CustodyError,
+    CustodyIndex, CustodyResponse, NodeId, NUMBER_OF_CUSTODY_GROUPS,
+};
+/// The outcome of [`DASContext::consistency_check`].
+pub use eip4844::ConsistencyReport;
+/// Encoding of arbitrary data into blobs, for [`DASContext::commit_to_payload`].
+pub use eip4844::{
+    blobs_needed, decode_payload, encode_payload, PayloadBlobs, PayloadError,
+    BYTES_OF_DATA_PER_BLOB, BYTES_OF_DATA_PER_FIELD_ELEMENT,
+};
+/// The input and output of the point evaluation precompile, for
+/// [`DASContext::verify_point_evaluation_input`].
+pub use eip4844::{
+    point_evaluation_input, point_evaluation_output, PrecompileError, BLS_MODULUS,
+    POINT_EVALUATION_INPUT_BYTES, POINT_EVALUATION_OUTPUT_BYTES,
+    PRECOMPILE_FIELD_ELEMENTS_PER_BLOB,
+};
+pub use error_code::ErrorCode;
+pub use errors::{AdmissionError, Error};
+pub use get_blobs::DataColumn;
+pub use memory::MemoryBreakdown;
+pub use metrics::{Measurement, Metrics, Operation};
+/// Error returned when a thread pool could not be created.
+#[cfg(feature = "multithreaded")]
+pub use rayon::ThreadPoolBuildError;
+pub use sampling::{BlockRoot, Sample, SampleBatchReport, SampleFailure, SampleVerifier};
+pub use scratch::Scratch;
+#[cfg(not(feature = "testing"))]
+pub use self_test::SelfTestError;
+/// `#[serde(with = "...")]` modules for blobs, cells, commitments, proofs and field elements.
+#[cfg(feature = "serde")]
+pub use serialization::serde;
+/// SSZ support for cells, commitments and proofs, and the [`ssz::DataColumnSidecarCells`] helper.
+#[cfg(feature = "ssz")]
+pub use serialization::ssz;
+pub use serialization::{
+    constants,
+    prefixed_hex::{HexError, PrefixedHex},
+    types::*,
+};
+/// A TrustedSetup whose points are decoded on first use.
+pub use trusted_setup::LazyTrustedSetup;
 /// TrustedSetup contains the Structured Reference String(SRS)
 /// needed to make and verify proofs.
 pub use trusted_setup::TrustedSetup;
+/// Error returned when a trusted setup fails validation.
+pub use trusted_setup::TrustedSetupError;
+/// The environment variable read by [`TrustedSetup::from_env`].
+pub use trusted_setup::TRUSTED_SETUP_ENV_VAR;
+pub use verification_cache::VerificationCacheStats;
+pub use verifier::prevalidate_cell_kzg_proof_batch;
 
 /// `CellIndex` is reference to the coset/set of points that were used to create that Cell,
 /// on a particular polynomial, f(x).
@@ -22,7 +128,13 @@ pub use trusted_setup::TrustedSetup;
 /// only requires an index to reference them.
 pub type CellIndex = kzg_multi_open::CosetIndex;
 
+use std::sync::Arc;
+
+use admission::AdmissionControl;
+use metrics::MetricsHook;
 use prover::ProverContext;
+use thread_pool::ThreadPool;
+use verification_cache::VerificationCache;
 use verifier::VerifierContext;
 
 /// DASContext manages the shared environment for creating and
@@ -33,25 +145,55 @@ use verifier::VerifierContext;
 /// - The EIP-7594 verifier context (for checking proofs)
 /// - The EIP-4844 context (for basic KZG operations). This is re-exported for convenience.
 ///
-/// All initialized from the same trusted setup (SRS).
+/// All initialized from the same trusted setup (SRS). The EIP-4844 context commits with
+/// the G1 points of the cell prover, so one context serves both the Deneb blob proofs and the
+/// Fulu cell proofs, for clients on either side of the fork, without holding the setup twice.
 ///
 /// The EIP-7594 context is required for sampling and validating data
 /// availability across blobs and cells without downloading all of the data.
-#[derive(Debug)]
+///
+/// The SRS tables and prover-side precomputations are reference counted, so cloning a
+/// DASContext is cheap and the clones share them. Services that need one context per
+/// chain or fork should clone a single context, instead of creating a new one each time.
+#[derive(Debug, Clone)]
 pub struct DASContext {
     /// Prover-side context:
     /// prepares and generates KZG cell proofs for blobs and cells.
-    pub prover_ctx: ProverContext,
+    ///
+    /// This is `None` for a context built with [`DASContextBuilder::verifier_only`].
+    pub prover_ctx: Option<Arc<ProverContext>>,
+
+    /// One copy of the prover context per NUMA node, allocated on that node, for a context
+    /// built with [`DASContextBuilder::numa_aware`]. The first one is `prover_ctx`.
+    #[cfg(feature = "numa")]
+    numa_prover_ctxs: Arc<[Arc<ProverContext>]>,
 
     /// Verifier-side context:
     /// verifies KZG cell proofs and ensures data integrity in PeerDAS.
-    pub verifier_ctx: VerifierContext,
+    pub verifier_ctx: Arc<VerifierContext>,
 
     /// EIP-4844 context:
     /// provides core KZG commitment operations for blob verification (proto-danksharding variant)
-    eip4844_ctx: eip4844::Context,
+    eip4844_ctx: Arc<eip4844::Context>,
+
+    /// Thread pool that the prover and verifier methods are run on.
+    thread_pool: ThreadPool,
+
+    /// Hook that every operation is reported to, if one was registered.
+    metrics: MetricsHook,
+
+    /// Limits on the operations that run at the same time, if they were set.
+    admission: Option<Arc<AdmissionControl>>,
+
+    /// Openings that verified recently, if the verification cache is enabled.
+    verification_cache: Option<Arc<VerificationCache>>,
+
+    /// Faults injected into the operations of this context, if an injector was registered.
+    #[cfg(feature = "fault-injection")]
+    faults: Option<Arc<fault_injection::FaultInjector>>,
 }
 
+#[cfg(not(feature = "no-embedded-setup"))]
 impl Default for DASContext {
     fn default() -> Self {
         Self::new(&TrustedSetup::default(), UsePrecomp::No)
@@ -78,11 +220,453 @@ impl DASContext {
     /// * `use_precomp` — Whether to enable prover-side precomputations
     ///   for faster proof creation at the cost of extra memory. The cost in
     ///   memory is exponential in the `width`.
+    ///
+    /// This is a shorthand for [`DASContext::builder`], which has the other options.
+    ///
+    /// # Panics
+    ///
+    /// Panics if the trusted setup has fewer points than a blob, see [`DASContext::try_new`]
+    /// for a constructor that returns an error instead, or if the precomputation width is
+    /// larger than [`UsePrecomp::MAX_WIDTH`].
     pub fn new(trusted_setup: &TrustedSetup, use_precomp: UsePrecomp) -> Self {
-        Self {
-            prover_ctx: ProverContext::new(trusted_setup, use_precomp),
-            verifier_ctx: VerifierContext::new(trusted_setup),
-            eip4844_ctx: eip4844::Context::new(trusted_setup),
+        Self::builder()
+            .trusted_setup(trusted_setup)
+            .precompute(use_precomp)
+            .build()
+            .expect("trusted setup should have enough points and the width should be supported")
+    }
+
+    /// Creates a new DASContext like [`DASContext::new`], optionally checking the trusted setup first.
+    ///
+    /// When `verify_setup` is true, [`TrustedSetup::verify`] is called before any of the
+    /// contexts are created and its error is returned if the setup is malformed. This should be
+    /// enabled when the setup was loaded from a third party file, since a corrupted or tampered
+    /// setup would otherwise silently produce proofs that do not verify elsewhere. A setup with
+    /// fewer points than a blob is rejected either way.
+    ///
+    /// # Panics
+    ///
+    /// Panics if the precomputation width is larger than [`UsePrecomp::MAX_WIDTH`].
+    pub fn try_new(
+        trusted_setup: &TrustedSetup,
+        use_precomp: UsePrecomp,
+        verify_setup: bool,
+    ) -> Result<Self, Error> {
+        Self::builder()
+            .trusted_setup(trusted_setup)
+            .precompute(use_precomp)
+            .verify_setup(verify_setup)
+            .build()
+            .map_err(|err| match err {
+                BuildError::TrustedSetup(err) => Error::TrustedSetup(err),
+                BuildError::PrecompWidth(width) => panic!(
+                    "precomputation width must be at most {}, got {width}",
+                    UsePrecomp::MAX_WIDTH
+                ),
+                err => unreachable!("only the setup checks can fail: {err:?}"),
+            })
+    }
+
+    /// Creates a new DASContext like [`DASContext::new`], whose methods run on a single thread.
+    ///
+    /// Parallel work is then always scheduled in the same order, which makes benchmarks
+    /// reproducible from run to run and across versions of the library. The outputs do not
+    /// depend on the mode: the random scalars used for batch verification are derived from
+    /// the inputs with Fiat-Shamir, so there is no RNG to seed.
+    ///
+    /// # Panics
+    ///
+    /// Panics if the thread could not be spawned, if the trusted setup has fewer points
+    /// than a blob, or if the precomputation width is larger than [`UsePrecomp::MAX_WIDTH`].
+    pub fn new_deterministic(trusted_setup: &TrustedSetup, use_precomp: UsePrecomp) -> Self {
+        Self::builder()
+            .trusted_setup(trusted_setup)
+            .precompute(use_precomp)
+            .deterministic(true)
+            .build()
+            .expect("trusted setup should have enough points and the width should be supported")
+    }
+
+    /// Runs the methods of this context on the given rayon thread pool, instead of the global one.
+    ///
+    /// This allows applications that already manage their own thread pool to stop the
+    /// library from spawning a thread per CPU.
+    #[cfg(feature = "multithreaded")]
+    #[must_use]
+    pub fn with_thread_pool(mut self, thread_pool: Arc<rayon::ThreadPool>) -> Self {
+        self.thread_pool = ThreadPool::new(thread_pool);
+        self
+    }
+
+    /// Runs the methods of this context on a dedicated thread pool with `num_threads` threads.
+    ///
+    /// Setting `num_threads` to 1 makes the context fully single-threaded. Setting it
+    /// to 0 lets rayon choose the number of threads, which is one per CPU.
+    #[cfg(feature = "multithreaded")]
+    pub fn with_num_threads(mut self, num_threads: usize) -> Result<Self, ThreadPoolBuildError> {
+        self.set_num_threads(num_threads)?;
+        Ok(self)
+    }
+
+    /// Replaces the thread pool of this context with one that has `num_threads` threads.
+    ///
+    /// See [`DASContext::with_num_threads`].
+    #[cfg(feature = "multithreaded")]
+    pub fn set_num_threads(&mut self, num_threads: usize) -> Result<(), ThreadPoolBuildError> {
+        self.thread_pool = ThreadPool::with_num_threads(num_threads)?;
+        Ok(())
+    }
+
+    /// Reports every operation of this context to `metrics`, so that they can be exported
+    /// to a metrics system such as Prometheus.
+    ///
+    /// Clones of the context share the hook. See [`Metrics`].
+    #[must_use]
+    pub fn with_metrics(mut self, metrics: Arc<dyn Metrics>) -> Self {
+        self.set_metrics(Some(metrics));
+        self
+    }
+
+    /// Replaces the metrics hook of this context, or removes it if `metrics` is `None`.
+    ///
+    /// See [`DASContext::with_metrics`].
+    pub fn set_metrics(&mut self, metrics: Option<Arc<dyn Metrics>>) {
+        self.metrics = MetricsHook::new(metrics);
+    }
+
+    /// Runs at most `limits.max_in_flight` operations of this context at the same time,
+    /// across all the threads that call it, and queues or rejects the others.
+    ///
+    /// Without limits, a burst of verification requests runs every request on the thread
+    /// pool at once, so each of them takes as long as the burst, including the operations
+    /// that block processing waits for. With limits, the operations over the limit wait in a
+    /// bounded queue for at most `limits.queue_timeout`, and fail fast with an
+    /// [`Error::Admission`] when the queue is full or the wait is too long, so that the
+    /// caller can drop or retry them.
+    ///
+    /// Clones of the context share the limits. See [`DASContext::admission_stats`].
+    ///
+    /// # Panics
+    ///
+    /// Panics if `limits.max_in_flight` is 0.
+    #[must_use]
+    pub fn with_admission_limits(mut self, limits: AdmissionLimits) -> Self {
+        self.set_admission_limits(Some(limits));
+        self
+    }
+
+    /// Replaces the admission limits of this context, or removes them if `limits` is `None`.
+    ///
+    /// The operations that are running or queued under the previous limits are not affected.
+    /// See [`DASContext::with_admission_limits`].
+    ///
+    /// # Panics
+    ///
+    /// Panics if `limits.max_in_flight` is 0.
+    pub fn set_admission_limits(&mut self, limits: Option<AdmissionLimits>) {
+        self.admission = limits.map(|limits| Arc::new(AdmissionControl::new(limits)));
+    }
+
+    /// Returns the operations that this context is running and queuing under its admission
+    /// limits, or `None` if it has no limits.
+    pub fn admission_stats(&self) -> Option<AdmissionStats> {
+        self.admission.as_ref().map(|admission| admission.stats())
+    }
+
+    /// Remembers the last `capacity` cell openings that [`DASContext::verify_cell_kzg_proof_batch`]
+    /// verified, so that verifying them again returns without any pairing.
+    ///
+    /// Consensus clients receive the same data column sidecars several times, from several
+    /// peers and on several topics. An opening is identified by its commitment, cell index,
+    /// cell and proof, and only openings that verified are remembered, so a batch that
+    /// contains an opening that did not verify before is still rejected. The openings of a
+    /// batch that are not in the cache are verified together, and when the cache is full
+    /// the oldest openings are forgotten first.
+    ///
+    /// Clones of the context share the cache. See [`DASContext::verification_cache_stats`].
+    #[must_use]
+    pub fn with_verification_cache(mut self, capacity: usize) -> Self {
+        self.set_verification_cache(Some(capacity));
+        self
+    }
+
+    /// Replaces the verification cache of this context with an empty one that holds
+    /// `capacity` openings, or disables it if `capacity` is `None` or 0.
+    ///
+    /// See [`DASContext::with_verification_cache`].
+    pub fn set_verification_cache(&mut self, capacity: Option<usize>) {
+        self.verification_cache = capacity
+            .filter(|&capacity| capacity != 0)
+            .map(|capacity| Arc::new(VerificationCache::new(capacity)));
+    }
+
+    /// Returns how often the verification cache was hit since it was enabled, or `None` if
+    /// it is disabled.
+    pub fn verification_cache_stats(&self) -> Option<VerificationCacheStats> {
+        self.verification_cache.as_ref().map(|cache| cache.stats())
+    }
+
+    /// Injects the faults configured in `faults` into the operations of this context.
+    ///
+    /// Clones of the context share the injector. See [`fault_injection`].
+    #[cfg(feature = "fault-injection")]
+    #[must_use]
+    pub fn with_fault_injector(mut self, faults: Arc<fault_injection::FaultInjector>) -> Self {
+        self.set_fault_injector(Some(faults));
+        self
+    }
+
+    /// Replaces the fault injector of this context, or removes it if `faults` is `None`.
+    ///
+    /// See [`DASContext::with_fault_injector`].
+    #[cfg(feature = "fault-injection")]
+    pub fn set_fault_injector(&mut self, faults: Option<Arc<fault_injection::FaultInjector>>) {
+        self.faults = faults;
+    }
+
+    /// Runs `op` on the thread pool of this context once the admission limits let it, and
+    /// reports it to the metrics hook.
+    #[cfg(not(feature = "fault-injection"))]
+    fn run<T: Send>(
+        &self,
+        operation: Operation,
+        batch_size: usize,
+        op: impl FnOnce() -> Result<T, Error> + Send,
+    ) -> Result<T, Error> {
+        self.metrics.measure(operation, batch_size, || {
+            let _permit = self.admit()?;
+            self.thread_pool.install(op)
+        })
+    }
+
+    /// Runs `op` on the thread pool of this context once the admission limits let it, and
+    /// reports it to the metrics hook, injecting the fault configured for this call, if any.
+    #[cfg(feature = "fault-injection")]
+    fn run<T: Send + fault_injection::Corruptible>(
+        &self,
+        operation: Operation,
+        batch_size: usize,
+        op: impl FnOnce() -> Result<T, Error> + Send,
+    ) -> Result<T, Error> {
+        use fault_injection::Fault;
+
+        let fault = self
+            .faults
+            .as_ref()
+            .and_then(|faults| faults.next_fault(operation));
+        self.metrics.measure(operation, batch_size, || {
+            let _permit = self.admit()?;
+            match fault {
+                None => self.thread_pool.install(op),
+                Some(Fault::Error(code)) => Err(Error::Injected(code)),
+                Some(Fault::CorruptOutput) => self.thread_pool.install(op)?.corrupt(),
+            }
+        })
+    }
+
+    /// Waits for the admission limits of this context to let an operation run, if it has
+    /// limits. The slot of the operation is freed when the permit is dropped.
+    fn admit(&self) -> Result<Option<admission::Permit<'_>>, Error> {
+        self.admission
+            .as_deref()
+            .map(AdmissionControl::acquire)
+            .transpose()
+    }
+
+    /// Returns true if this context shares its SRS tables and precomputations with `other`,
+    /// ie one of them was cloned from the other.
+    pub fn shares_tables_with(&self, other: &Self) -> bool {
+        let shares_prover = match (&self.prover_ctx, &other.prover_ctx) {
+            (Some(prover_ctx), Some(other)) => Arc::ptr_eq(prover_ctx, other),
+            (None, None) => true,
+            _ => false,
+        };
+        shares_prover
+            && Arc::ptr_eq(&self.verifier_ctx, &other.verifier_ctx)
+            && Arc::ptr_eq(&self.eip4844_ctx, &other.eip4844_ctx)
+    }
+
+    /// Returns the G1 points of the trusted setup in monomial form, ie `[tau^i]_1`.
+    ///
+    /// This allows downstream protocols to reuse the already loaded setup, instead of
+    /// parsing the trusted setup file a second time.
+    pub fn g1_monomial_points(&self) -> &[G1Point] {
+        self.eip4844_ctx.g1_monomial_points()
+    }
+
+    /// Returns the G1 points of the trusted setup in lagrange form, in bit-reversed order.
+    ///
+    /// These are computed from the monomial points the first time they are requested.
+    pub fn g1_lagrange_points(&self) -> &[G1Point] {
+        self.eip4844_ctx.g1_lagrange_points()
+    }
+
+    /// Returns the G2 points of the trusted setup in monomial form, ie `[tau^i]_2`.
+    ///
+    /// Only the first `FIELD_ELEMENTS_PER_CELL + 1` points are kept by the context.
+    pub fn g2_monomial_points(&self) -> &[G2Point] {
+        self.verifier_ctx.g2_monomial_points()
+    }
+
+    /// Creates a new DASContext, loading the prover-side precomputations from the file at `path`.
+    ///
+    /// Computing the precomputations can take multiple seconds, so short-lived processes
+    /// can write them once with [`DASContext::write_precomputations_file`] and reload them
+    /// with this method. When the `mmap` feature is enabled, the tables are decoded straight
+    /// from a memory map of the file instead of through a buffer. The file must then not be
+    /// written to or truncated until this method returns, since the mapped bytes would
+    /// change while they are read.
+    ///
+    /// The file must have been created with the same trusted setup. The tables it contains
+    /// are not recomputed, so it should only be loaded from a trusted location.
+    pub fn from_precomputations_file(
+        trusted_setup: &TrustedSetup,
+        path: impl AsRef<std::path::Path>,
+    ) -> std::io::Result<Self> {
+        Self::builder()
+            .trusted_setup(trusted_setup)
+            .precomputations_file(path)
+            .build()
+            .map_err(|err| match err {
+                BuildError::Io(err) => err,
+                BuildError::TrustedSetup(err) => {
+                    std::io::Error::new(std::io::ErrorKind::InvalidInput, format!("{err:?}"))
+                }
+                err => unreachable!("only reading the file and the setup size can fail: {err:?}"),
+            })
+    }
+
+    /// Writes the prover-side precomputations to the file at `path`, so that they
+    /// can be reloaded with [`DASContext::from_precomputations_file`].
+    ///
+    /// A verifier-only context has no precomputations, so an `Unsupported` error is returned.
+    pub fn write_precomputations_file(
+        &self,
+        path: impl AsRef<std::path::Path>,
+    ) -> std::io::Result<()> {
+        use std::io::Write;
+
+        let prover_ctx = self.prover().map_err(|_| {
+            std::io::Error::new(
+                std::io::ErrorKind::Unsupported,
+                "a verifier-only context has no precomputations",
+            )
+        })?;
+        let mut writer = std::io::BufWriter::new(std::fs::File::create(path)?);
+        prover_ctx.write_precomputations(&mut writer)?;
+        writer.flush()
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use bls12_381::Scalar;
+
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT},
+        DASContext, TrustedSetup, UsePrecomp,
+    };
+
+    #[test]
+    fn clones_share_tables() {
+        let ctx = DASContext::new(&TrustedSetup::default(), UsePrecomp::No);
+        let clone = ctx.clone();
+        assert!(ctx.shares_tables_with(&clone));
+
+        let other = DASContext::new(&TrustedSetup::default(), UsePrecomp::No);
+        assert!(!ctx.shares_tables_with(&other));
+    }
+
+    #[test]
+    fn deterministic_context_matches_default() {
+        let trusted_setup = TrustedSetup::default();
+        let ctx = DASContext::new(&trusted_setup, UsePrecomp::No);
+        let deterministic = DASContext::new_deterministic(&trusted_setup, UsePrecomp::No);
+
+        let blob = [0u8; BYTES_PER_BLOB];
+        assert_eq!(
+            ctx.compute_cells_and_kzg_proofs(&blob).unwrap(),
+            deterministic.compute_cells_and_kzg_proofs(&blob).unwrap()
+        );
+        #[cfg(feature = "multithreaded")]
+        assert_eq!(
+            deterministic
+                .thread_pool
+                .install(rayon::current_num_threads),
+            1
+        );
+    }
+
+    #[test]
+    fn process_slot_matches_per_blob_calls() {
+        let ctx = DASContext::default();
+
+        let blobs: Vec<_> = (0..3u64)
+            .map(|k| {
+                let mut blob = [0u8; BYTES_PER_BLOB];
+                for (i, chunk) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+                    chunk.copy_from_slice(&Scalar::from(i as u64 * k + 1).to_bytes_be());
+                }
+                blob
+            })
+            .collect();
+        let blob_refs: Vec<_> = blobs.iter().collect();
+
+        let results = ctx.process_slot(&blob_refs).unwrap();
+        assert_eq!(results.len(), blobs.len());
+        for (blob, (commitment, cells, proofs)) in blobs.iter().zip(results) {
+            assert_eq!(commitment, ctx.blob_to_kzg_commitment(blob).unwrap());
+            assert_eq!(
+                (cells, proofs),
+                ctx.compute_cells_and_kzg_proofs(blob).unwrap()
+            );
+        }
+    }
+}
+
+#[cfg(all(test, feature = "testing", not(feature = "no-embedded-setup")))]
+mod testing_params_tests {
+    use bls12_381::Scalar;
+
+    use crate::{
+        constants::{
+            BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT, CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_BLOB,
+        },
+        CellIndex, DASContext, TrustedSetup, UsePrecomp,
+    };
+
+    #[test]
+    fn reduced_parameters_roundtrip() {
+        let trusted_setup = TrustedSetup::default();
+        assert_eq!(trusted_setup.g1_monomial.len(), FIELD_ELEMENTS_PER_BLOB);
+        let ctx = DASContext::new(&trusted_setup, UsePrecomp::No);
+
+        let mut blob = [0u8; BYTES_PER_BLOB];
+        for (i, chunk) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            chunk.copy_from_slice(&Scalar::from(i as u64 + 1).to_bytes_be());
         }
+
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+
+        let cell_indices: Vec<CellIndex> = (0..CELLS_PER_EXT_BLOB as u64).collect();
+        ctx.verify_cell_kzg_proof_batch(
+            vec![&commitment; CELLS_PER_EXT_BLOB],
+            &cell_indices,
+            cells.iter().map(AsRef::as_ref).collect(),
+            proofs.iter().collect(),
+        )
+        .unwrap();
+
+        // Recover from the second half of the cells
+        let half = CELLS_PER_EXT_BLOB / 2;
+        let (recovered_cells, recovered_proofs) = ctx
+            .recover_cells_and_kzg_proofs(
+                cell_indices[half..].to_vec(),
+                cells[half..].iter().map(AsRef::as_ref).collect(),
+            )
+            .unwrap();
+        assert_eq!(recovered_cells, cells);
+        assert_eq!(recovered_proofs, proofs);
     }
 }
@@ -0,0 +1,109 @@
+use std::sync::Arc;
+
+use crate::{prover::ProverContext, DASContext};
+
+/// The number of bytes held by a [`DASContext`], split by what they are used for.
+///
+/// The tables are shared between a context and its clones, so the memory used by
+/// a set of cloned contexts is the breakdown of any one of them. Buffers owned by
+/// the caller, such as a [`crate::Scratch`], are not included; their size is
+/// reported by [`crate::Scratch::size_in_bytes`].
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct MemoryBreakdown {
+    /// Points of the trusted setup kept by the prover, verifier and EIP-4844 contexts.
+    pub srs: usize,
+    /// Tables precomputed by the FK20 prover to speed up proof generation.
+    ///
+    /// This is the part that grows with the width chosen with `UsePrecomp`.
+    pub fk20_precomputations: usize,
+    /// Roots of unity and FFT tables of the evaluation domains.
+    pub domains: usize,
+}
+
+impl MemoryBreakdown {
+    /// Returns the total number of bytes.
+    pub const fn total(&self) -> usize {
+        self.srs + self.fk20_precomputations + self.domains
+    }
+}
+
+impl DASContext {
+    /// Returns the number of bytes held by the SRS tables, FK20 precomputations and domains of this context.
+    ///
+    /// This is an estimate that counts the elements of each table, ignoring allocator overhead.
+    #[cfg_attr(not(feature = "numa"), allow(clippy::let_and_return))]
+    pub fn memory_usage(&self) -> MemoryBreakdown {
+        // A verifier-only context has no prover tables.
+        let prover_ctx = self.prover_ctx.as_deref();
+        // The EIP-4844 context usually shares the commit key of the prover, which is then
+        // only counted once.
+        let unshared_prover_ctx = prover_ctx.filter(|prover_ctx| {
+            !Arc::ptr_eq(
+                prover_ctx.g1_monomial_points(),
+                self.eip4844_ctx.shared_g1_monomial_points(),
+            )
+        });
+        let breakdown = MemoryBreakdown {
+            srs: unshared_prover_ctx.map_or(0, ProverContext::srs_size_in_bytes)
+                + self.verifier_ctx.srs_size_in_bytes()
+                + self.eip4844_ctx.srs_size_in_bytes(),
+            fk20_precomputations: prover_ctx
+                .map_or(0, ProverContext::precomputations_size_in_bytes),
+            domains: prover_ctx.map_or(0, ProverContext::domains_size_in_bytes)
+                + self.verifier_ctx.domains_size_in_bytes()
+                + self.eip4844_ctx.domains_size_in_bytes(),
+        };
+
+        // The copies of the prover context made for the other NUMA nodes share nothing.
+        #[cfg(feature = "numa")]
+        let breakdown =
+            self.numa_prover_ctxs
+                .iter()
+                .skip(1)
+                .fold(breakdown, |breakdown, prover_ctx| MemoryBreakdown {
+                    srs: breakdown.srs + prover_ctx.srs_size_in_bytes(),
+                    fk20_precomputations: breakdown.fk20_precomputations
+                        + prover_ctx.precomputations_size_in_bytes(),
+                    domains: breakdown.domains + prover_ctx.domains_size_in_bytes(),
+                });
+        breakdown
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::{DASContext, TrustedSetup, UsePrecomp};
+
+    #[test]
+    fn precomputations_increase_memory_usage() {
+        let trusted_setup = TrustedSetup::default();
+        let without = DASContext::new(&trusted_setup, UsePrecomp::No).memory_usage();
+        let with = DASContext::new(&trusted_setup, UsePrecomp::Yes { width: 4 }).memory_usage();
+
+        assert_eq!(without.srs, with.srs);
+        assert_eq!(without.domains, with.domains);
+        assert!(with.fk20_precomputations > without.fk20_precomputations);
+        assert_eq!(
+            with.total(),
+            with.srs + with.fk20_precomputations + with.domains
+        );
+    }
+
+    #[test]
+    fn eip4844_context_shares_the_commit_key() {
+        let prover = DASContext::default();
+        let verifier = DASContext::builder().verifier_only(true).build().unwrap();
+
+        // Both hold one copy of the G1 points, which only the prover shares between forks.
+        assert_eq!(prover.memory_usage().srs, verifier.memory_usage().srs);
+        assert_eq!(
+            prover.g1_monomial_points().as_ptr(),
+            prover
+                .prover_ctx
+                .as_ref()
+                .unwrap()
+                .g1_monomial_points()
+                .as_ptr()
+        );
+    }
+}
@@ -0,0 +1,232 @@
+//! Hooks that report the operations performed by a context, so that they can be exported
+//! to a metrics system such as Prometheus.
+
+use std::{
+    fmt,
+    sync::Arc,
+    time::{Duration, Instant},
+};
+
+use crate::{Error, ErrorCode};
+
+/// An operation of a [`crate::DASContext`] that is reported to [`Metrics`].
+///
+/// The numeric values are stable, so that they can be passed across the FFI.
+#[repr(u32)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]
+pub enum Operation {
+    BlobToKzgCommitment = 1,
+    ComputeCells = 2,
+    /// Includes [`crate::DASContext::compute_cells_and_kzg_proofs_with_scratch`].
+    ComputeCellsAndKzgProofs = 3,
+    ComputeCellsAndKzgProofsBatch = 4,
+    ProcessSlot = 5,
+    ComputeCellAndKzgProof = 6,
+    RecoverCellsAndKzgProofs = 7,
+    VerifyCellKzgProofBatch = 8,
+    ComputeKzgProof = 9,
+    ComputeBlobKzgProof = 10,
+    VerifyKzgProof = 11,
+    VerifyBlobKzgProof = 12,
+    VerifyBlobKzgProofBatch = 13,
+}
+
+impl Operation {
+    /// Every operation, in the order of their numeric values.
+    pub const ALL: [Self; 13] = [
+        Self::BlobToKzgCommitment,
+        Self::ComputeCells,
+        Self::ComputeCellsAndKzgProofs,
+        Self::ComputeCellsAndKzgProofsBatch,
+        Self::ProcessSlot,
+        Self::ComputeCellAndKzgProof,
+        Self::RecoverCellsAndKzgProofs,
+        Self::VerifyCellKzgProofBatch,
+        Self::ComputeKzgProof,
+        Self::ComputeBlobKzgProof,
+        Self::VerifyKzgProof,
+        Self::VerifyBlobKzgProof,
+        Self::VerifyBlobKzgProofBatch,
+    ];
+
+    /// Returns the numeric value of the operation.
+    pub const fn as_u32(self) -> u32 {
+        self as u32
+    }
+
+    /// Returns the name of the method that performs the operation, which is suitable as a
+    /// metric label, ie `verify_cell_kzg_proof_batch`.
+    pub const fn name(self) -> &'static str {
+        match self {
+            Self::BlobToKzgCommitment => "blob_to_kzg_commitment",
+            Self::ComputeCells => "compute_cells",
+            Self::ComputeCellsAndKzgProofs => "compute_cells_and_kzg_proofs",
+            Self::ComputeCellsAndKzgProofsBatch => "compute_cells_and_kzg_proofs_batch",
+            Self::ProcessSlot => "process_slot",
+            Self::ComputeCellAndKzgProof => "compute_cell_and_kzg_proof",
+            Self::RecoverCellsAndKzgProofs => "recover_cells_and_kzg_proofs",
+            Self::VerifyCellKzgProofBatch => "verify_cell_kzg_proof_batch",
+            Self::ComputeKzgProof => "compute_kzg_proof",
+            Self::ComputeBlobKzgProof => "compute_blob_kzg_proof",
+            Self::VerifyKzgProof => "verify_kzg_proof",
+            Self::VerifyBlobKzgProof => "verify_blob_kzg_proof",
+            Self::VerifyBlobKzgProofBatch => "verify_blob_kzg_proof_batch",
+        }
+    }
+}
+
+impl fmt::Display for Operation {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(self.name())
+    }
+}
+
+/// An operation that has completed, as reported to [`Metrics::record`].
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Measurement {
+    pub operation: Operation,
+    /// The number of blobs or cells in the input, or 1 for the operations on a single blob.
+    pub batch_size: usize,
+    /// The time the operation took, including the time spent waiting for the thread pool.
+    ///
+    /// This is zero on wasm32, where there is no clock.
+    pub duration: Duration,
+    /// The code of the error that the operation returned, or `None` if it succeeded.
+    ///
+    /// A proof that does not verify is reported as [`ErrorCode::InvalidProof`].
+    pub error: Option<ErrorCode>,
+}
+
+/// Receives a [`Measurement`] for every operation that a context completes.
+///
+/// A typical implementation increments a counter labelled with the operation and whether
+/// it failed, and observes the batch size and the duration in two histograms.
+///
+/// `record` is called on the thread that called the operation, once the operation has
+/// finished, so it should be cheap and must not block.
+pub trait Metrics: Send + Sync {
+    fn record(&self, measurement: &Measurement);
+}
+
+/// The metrics hook of a context, if one was registered.
+#[derive(Clone, Default)]
+pub(crate) struct MetricsHook(Option<Arc<dyn Metrics>>);
+
+impl fmt::Debug for MetricsHook {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.debug_struct("MetricsHook")
+            .field("registered", &self.0.is_some())
+            .finish()
+    }
+}
+
+impl MetricsHook {
+    pub(crate) fn new(metrics: Option<Arc<dyn Metrics>>) -> Self {
+        Self(metrics)
+    }
+
+    /// Runs `op` and reports it to the registered hook, if there is one.
+    pub(crate) fn measure<T>(
+        &self,
+        operation: Operation,
+        batch_size: usize,
+        op: impl FnOnce() -> Result<T, Error>,
+    ) -> Result<T, Error> {
+        let Some(metrics) = &self.0 else {
+            return op();
+        };
+
+        // `Instant::now` panics on wasm32-unknown-unknown.
+        let start = (!cfg!(target_arch = "wasm32")).then(Instant::now);
+        let result = op();
+        metrics.record(&Measurement {
+            operation,
+            batch_size,
+            duration: start.map_or(Duration::ZERO, |start| start.elapsed()),
+            error: result.as_ref().err().map(Error::code),
+        });
+        result
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use std::sync::{Arc, Mutex};
+
+    use super::{Measurement, Metrics, Operation};
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT},
+        DASContext, ErrorCode,
+    };
+
+    #[derive(Default)]
+    struct Recorder(Mutex<Vec<Measurement>>);
+
+    impl Metrics for Recorder {
+        fn record(&self, measurement: &Measurement) {
+            self.0.lock().unwrap().push(*measurement);
+        }
+    }
+
+    #[test]
+    fn operations_are_numbered_in_order() {
+        for (i, operation) in Operation::ALL.iter().enumerate() {
+            assert_eq!(operation.as_u32(), i as u32 + 1);
+        }
+    }
+
+    #[test]
+    fn operations_are_recorded_with_their_outcome() {
+        let recorder = Arc::new(Recorder::default());
+        let ctx = DASContext::default().with_metrics(recorder.clone());
+        let mut blob = [0u8; BYTES_PER_BLOB];
+        blob[BYTES_PER_FIELD_ELEMENT - 1] = 1;
+
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        // The proofs are swapped, so the batch does not verify.
+        ctx.verify_cell_kzg_proof_batch(
+            vec![&commitment; 2],
+            &[0, 1],
+            vec![&cells[0], &cells[1]],
+            vec![&proofs[1], &proofs[0]],
+        )
+        .unwrap_err();
+
+        let summary: Vec<_> = recorder
+            .0
+            .lock()
+            .unwrap()
+            .iter()
+            .map(|m| (m.operation, m.batch_size, m.error))
+            .collect();
+        assert_eq!(
+            summary,
+            [
+                (Operation::ComputeCellsAndKzgProofs, 1, None),
+                (Operation::BlobToKzgCommitment, 1, None),
+                (
+                    Operation::VerifyCellKzgProofBatch,
+                    2,
+                    Some(ErrorCode::InvalidProof)
+                ),
+            ]
+        );
+    }
+
+    #[test]
+    fn clones_report_to_the_same_hook() {
+        let recorder = Arc::new(Recorder::default());
+        let ctx = DASContext::default().with_metrics(recorder.clone());
+        let clone = ctx.clone();
+        clone
+            .blob_to_kzg_commitment(&[0u8; BYTES_PER_BLOB])
+            .unwrap();
+        assert_eq!(recorder.0.lock().unwrap().len(), 1);
+
+        let mut ctx = ctx;
+        ctx.set_metrics(None);
+        ctx.blob_to_kzg_commitment(&[0u8; BYTES_PER_BLOB]).unwrap();
+        assert_eq!(recorder.0.lock().unwrap().len(), 1);
+    }
+}
@@ -0,0 +1,231 @@
+//! NUMA-aware placement of the worker threads and prover tables of a context, see
+//! [`DASContextBuilder::numa_aware`](crate::DASContextBuilder::numa_aware).
+//!
+//! On a machine with several NUMA nodes, such as a dual-socket server, memory is attached to
+//! one of the nodes and reading it from the CPUs of another node goes through the
+//! interconnect. The MSMs and FFTs of the prover read the precomputed tables over and over, so
+//! they slow down noticeably when their threads and tables end up on different nodes.
+//!
+//! A NUMA-aware context has one thread pool per node, whose threads are pinned to the CPUs of
+//! that node, and one copy of the prover tables per node. Each copy is created by the threads
+//! of its node, so that Linux allocates its pages on that node, which is the default
+//! first-touch policy. Each call to the context then runs on a single node, the nodes being
+//! used in turn, and only reads the tables of that node.
+
+use std::{
+    cell::Cell,
+    io,
+    sync::atomic::{AtomicUsize, Ordering},
+};
+
+/// A NUMA node of the machine, and the CPUs attached to it.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct NumaNode {
+    /// The number of the node, as numbered by the operating system.
+    pub id: usize,
+    /// The CPUs attached to the node.
+    pub cpus: Vec<usize>,
+}
+
+/// Returns the NUMA nodes of the machine that have CPUs attached, in the order of their ids.
+///
+/// The nodes are read from sysfs, so this is empty on platforms other than Linux and when
+/// sysfs is not mounted, such as in some containers.
+pub fn numa_nodes() -> Vec<NumaNode> {
+    #[cfg(target_os = "linux")]
+    {
+        let Ok(entries) = std::fs::read_dir("/sys/devices/system/node") else {
+            return Vec::new();
+        };
+        let mut nodes: Vec<NumaNode> = entries
+            .filter_map(Result::ok)
+            .filter_map(|entry| {
+                let id = entry
+                    .file_name()
+                    .to_str()?
+                    .strip_prefix("node")?
+                    .parse()
+                    .ok()?;
+                let cpu_list = std::fs::read_to_string(entry.path().join("cpulist")).ok()?;
+                let cpus = parse_cpu_list(&cpu_list)?;
+                (!cpus.is_empty()).then_some(NumaNode { id, cpus })
+            })
+            .collect();
+        nodes.sort_by_key(|node| node.id);
+        nodes
+    }
+    #[cfg(not(target_os = "linux"))]
+    Vec::new()
+}
+
+/// Parses a list of CPUs in the format of sysfs, such as `0-3,8-11`.
+///
+/// Returns `None` if the list is malformed.
+fn parse_cpu_list(cpu_list: &str) -> Option<Vec<usize>> {
+    let cpu_list = cpu_list.trim();
+    if cpu_list.is_empty() {
+        return Some(Vec::new());
+    }
+
+    let mut cpus = Vec::new();
+    for range in cpu_list.split(',') {
+        match range.split_once('-') {
+            Some((first, last)) => {
+                let (first, last): (usize, usize) = (first.parse().ok()?, last.parse().ok()?);
+                if first > last {
+                    return None;
+                }
+                cpus.extend(first..=last);
+            }
+            None => cpus.push(range.parse().ok()?),
+        }
+    }
+    Some(cpus)
+}
+
+/// Restricts the calling thread to run on `cpus`.
+#[cfg(target_os = "linux")]
+fn pin_current_thread(cpus: &[usize]) -> io::Result<()> {
+    #[allow(clippy::cast_sign_loss)]
+    const MAX_CPUS: usize = libc::CPU_SETSIZE as usize;
+
+    // Safety: `cpu_set_t` is a plain bit set, for which all zeros is the empty set, and only
+    // CPUs that fit in it are added.
+    unsafe {
+        let mut set: libc::cpu_set_t = std::mem::zeroed();
+        for &cpu in cpus.iter().filter(|&&cpu| cpu < MAX_CPUS) {
+            libc::CPU_SET(cpu, &mut set);
+        }
+        if libc::sched_setaffinity(0, std::mem::size_of::<libc::cpu_set_t>(), &set) != 0 {
+            return Err(io::Error::last_os_error());
+        }
+    }
+    Ok(())
+}
+
+#[cfg(not(target_os = "linux"))]
+#[allow(clippy::unnecessary_wraps)]
+fn pin_current_thread(_cpus: &[usize]) -> io::Result<()> {
+    Ok(())
+}
+
+thread_local! {
+    /// The index of the node whose pool this thread belongs to, if it is a thread of a
+    /// [`NumaPools`].
+    static NODE_INDEX: Cell<Option<usize>> = const { Cell::new(None) };
+}
+
+/// Returns the index of the node whose pool the calling thread belongs to, if it is a thread
+/// of a [`NumaPools`].
+///
+/// This is the index of the node in the list that the pools were created from, which is also
+/// the index of the copy of the prover tables allocated on that node.
+pub(crate) fn current_node_index() -> Option<usize> {
+    NODE_INDEX.with(Cell::get)
+}
+
+/// One thread pool per NUMA node, whose threads are pinned to the CPUs of their node.
+#[derive(Debug)]
+pub(crate) struct NumaPools {
+    pools: Vec<rayon::ThreadPool>,
+    /// The node that the next call from outside of the pools runs on, modulo their number.
+    next: AtomicUsize,
+}
+
+impl NumaPools {
+    /// Creates one pool per node of `nodes`, with one thread per CPU of the node.
+    ///
+    /// Returns `None` if there are fewer than two nodes, since there is then nothing to place.
+    pub(crate) fn new(nodes: &[NumaNode]) -> Result<Option<Self>, rayon::ThreadPoolBuildError> {
+        if nodes.len() < 2 {
+            return Ok(None);
+        }
+
+        let pools = nodes
+            .iter()
+            .enumerate()
+            .map(|(index, node)| {
+                let id = node.id;
+                let cpus = node.cpus.clone();
+                rayon::ThreadPoolBuilder::new()
+                    .num_threads(node.cpus.len())
+                    .thread_name(move |thread| format!("eth-kzg-node{id}-{thread}"))
+                    .start_handler(move |_| {
+                        NODE_INDEX.with(|node_index| node_index.set(Some(index)));
+                        // Placement only affects performance, so a thread that cannot be
+                        // pinned, for example because of a cgroup, still does its work.
+                        let _ = pin_current_thread(&cpus);
+                    })
+                    .build()
+            })
+            .collect::<Result<_, _>>()?;
+
+        Ok(Some(Self {
+            pools,
+            next: AtomicUsize::new(0),
+        }))
+    }
+
+    /// Returns the number of nodes.
+    pub(crate) fn len(&self) -> usize {
+        self.pools.len()
+    }
+
+    /// Runs `op` on the pool of the node at `index`.
+    pub(crate) fn install_on<R: Send>(&self, index: usize, op: impl FnOnce() -> R + Send) -> R {
+        self.pools[index].install(op)
+    }
+
+    /// Runs `op` on the pool of one of the nodes.
+    ///
+    /// Calls made from one of the pools stay on its node, and the other calls are spread over
+    /// the nodes in turn.
+    pub(crate) fn install<R: Send>(&self, op: impl FnOnce() -> R + Send) -> R {
+        let index = current_node_index()
+            .filter(|&index| index < self.len())
+            .unwrap_or_else(|| self.next.fetch_add(1, Ordering::Relaxed) % self.len());
+        self.install_on(index, op)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn parses_cpu_lists() {
+        assert_eq!(parse_cpu_list("0-3,8-9\n"), Some(vec![0, 1, 2, 3, 8, 9]));
+        assert_eq!(parse_cpu_list("5"), Some(vec![5]));
+        assert_eq!(parse_cpu_list("\n"), Some(vec![]));
+        assert_eq!(parse_cpu_list("3-1"), None);
+        assert_eq!(parse_cpu_list("0-"), None);
+        assert_eq!(parse_cpu_list("a"), None);
+    }
+
+    #[test]
+    fn nodes_have_cpus() {
+        // Most test machines have a single node, or none visible
+        let nodes = numa_nodes();
+        assert!(nodes.iter().all(|node| !node.cpus.is_empty()));
+        assert!(nodes.windows(2).all(|pair| pair[0].id < pair[1].id));
+    }
+
+    #[test]
+    fn calls_are_spread_over_the_nodes() {
+        // Two nodes on the first CPU, which every machine has
+        let node = |id| NumaNode { id, cpus: vec![0] };
+        assert!(NumaPools::new(&[node(0)]).unwrap().is_none());
+        let pools = NumaPools::new(&[node(0), node(1)]).unwrap().unwrap();
+
+        assert_eq!(current_node_index(), None);
+        assert_eq!(pools.install_on(1, current_node_index), Some(1));
+        let indices: Vec<_> = (0..4).map(|_| pools.install(current_node_index)).collect();
+        assert_eq!(indices, [Some(0), Some(1), Some(0), Some(1)]);
+
+        // Nested calls stay on the node that they were made from
+        assert_eq!(
+            pools.install_on(1, || pools.install(current_node_index)),
+            Some(1)
+        );
+    }
+}
@@ -0,0 +1,120 @@
+//! Cross-checks of the optimized prover and recovery against straightforward implementations.
+//!
+//! With the `paranoid-checks` feature, every set of FK20 proofs is verified again with
+//! a pairing check per cell, without the batching and the random linear combination of the
+//! optimized verifier. Every recovery is also checked by extending the recovered polynomial
+//! again and comparing the result with the cells that were passed in.
+//!
+//! A divergence means that the library returned an incorrect result, which a caller can
+//! not recover from, so it panics. This is meant for canary nodes and long-running soak
+//! tests; the checks make proof computation and recovery several times slower.
+
+use bls12_381::{G1Point, Scalar};
+use kzg_multi_open::{reference, verification_key::VerificationKey, Prover, ProverInput};
+use serialization::serialize_cell;
+
+use crate::{constants::FIELD_ELEMENTS_PER_EXT_BLOB, CellIndex, CellRef};
+
+/// Verifies each proof on its own against `commitment`, panicking if any of them is rejected.
+///
+/// The proofs and evaluations are in the order returned by the prover, so the proof at
+/// index `i` attests to the evaluations of the cell at index `i`.
+pub(crate) fn check_proofs(
+    verification_key: &VerificationKey,
+    commitment: G1Point,
+    proofs: &[G1Point],
+    coset_evaluations: &[Vec<Scalar>],
+) {
+    assert_eq!(
+        proofs.len(),
+        coset_evaluations.len(),
+        "paranoid check failed: the prover returned {} proofs for {} cells",
+        proofs.len(),
+        coset_evaluations.len()
+    );
+
+    for (coset_index, (proof, evaluations)) in proofs.iter().zip(coset_evaluations).enumerate() {
+        assert!(
+            reference::verify_coset_opening(
+                verification_key,
+                commitment,
+                FIELD_ELEMENTS_PER_EXT_BLOB,
+                coset_index as u64,
+                evaluations,
+                *proof,
+            ),
+            "paranoid check failed: the proof for cell {coset_index} does not verify with the reference verifier"
+        );
+    }
+}
+
+/// Extends `poly_coeff` again and checks that it agrees with the cells that it was recovered
+/// from and with the cells that were returned, panicking otherwise.
+pub(crate) fn check_recovery(
+    prover: &Prover,
+    cell_indices: &[CellIndex],
+    cells: &[CellRef],
+    poly_coeff: Vec<Scalar>,
+    recovered_coset_evaluations: &[Vec<Scalar>],
+) {
+    let extended = prover.extend_polynomial(ProverInput::PolyCoeff(poly_coeff.into()));
+    assert!(
+        extended == recovered_coset_evaluations,
+        "paranoid check failed: the recovered cells differ from the extension of the recovered polynomial"
+    );
+
+    for (&cell_index, cell) in cell_indices.iter().zip(cells) {
+        assert!(
+            *serialize_cell(&extended[cell_index as usize]) == **cell,
+            "paranoid check failed: the recovered polynomial does not match the input cell at index {cell_index}"
+        );
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use serialization::{
+        deserialize_cells, deserialize_compressed_g1, deserialize_compressed_g1_points,
+    };
+
+    use super::*;
+    use crate::{constants::CELLS_PER_EXT_BLOB, generators, DASContext};
+
+    #[test]
+    fn checked_operations_succeed() {
+        let ctx = DASContext::default();
+        let blob = generators::blob(7);
+
+        let (cells, _) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+        let half = CELLS_PER_EXT_BLOB / 2;
+        let cell_indices: Vec<_> = (half as u64..CELLS_PER_EXT_BLOB as u64).collect();
+        let cell_refs = cells[half..].iter().map(AsRef::as_ref).collect();
+        let (recovered, _) = ctx
+            .recover_cells_and_kzg_proofs(cell_indices, cell_refs)
+            .unwrap();
+        assert_eq!(recovered, cells);
+    }
+
+    #[test]
+    #[should_panic(expected = "paranoid check failed")]
+    fn swapped_proofs_panic() {
+        let ctx = DASContext::default();
+        let blob = generators::blob(7);
+
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+
+        let commitment = deserialize_compressed_g1(&commitment).unwrap();
+        let coset_evaluations =
+            deserialize_cells(cells.iter().map(AsRef::as_ref).collect()).unwrap();
+        let mut proofs = deserialize_compressed_g1_points(proofs.iter().collect()).unwrap();
+        proofs.swap(0, 1);
+
+        check_proofs(
+            ctx.verifier_ctx.verification_key(),
+            commitment,
+            &proofs,
+            &coset_evaluations,
+        );
+    }
+}
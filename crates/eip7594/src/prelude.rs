@@ -0,0 +1,62 @@
+//! The types and traits that most users of the crate need, in a single import:
+//!
+//! ```ignore
+//! use rust_eth_kzg::prelude::*;
+//!
+//! let ctx = DASContext::builder().precompute(UsePrecomp::Yes { width: 8 }).build()?;
+//! let blob: Vec<u8> = (0..FIELD_ELEMENTS_PER_BLOB as u64)
+//!     .flat_map(|i| Scalar::from(i).to_bytes_be())
+//!     .collect();
+//! let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(blob.as_slice().try_into()?)?;
+//! ```
+//!
+//! The field and group traits are re-exported from the versions of `ff` and `group` that the
+//! library was built with, so that callers do not need to depend on them and keep their
+//! versions in sync. They are needed to do arithmetic on a [`Scalar`] or a [`G1Point`], for
+//! example to build test blobs.
+
+#[cfg(feature = "tokio")]
+pub use crate::AsyncDASContext;
+pub use crate::{
+    constants::{
+        BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_COMMITMENT, BYTES_PER_FIELD_ELEMENT,
+        CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_BLOB, FIELD_ELEMENTS_PER_CELL,
+        RECOMMENDED_PRECOMP_WIDTH,
+    },
+    traits::*,
+    BlobRef, BuildError, Bytes48Ref, Cell, CellIndex, CellRef, DASContext, DASContextBuilder,
+    Error, ErrorCode, G1Point, G2Point, KZGCommitment, KZGProof, PrefixedHex, Scalar,
+    SerializedScalar, TrustedSetup, TrustedSetupError, UsePrecomp,
+};
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    // Only the prelude is imported, to check that it is enough to prove and verify.
+    use super::*;
+
+    #[test]
+    fn prelude_is_enough_to_prove_and_verify() {
+        let ctx = DASContext::builder().build().unwrap();
+        let blob: Vec<u8> = (0..FIELD_ELEMENTS_PER_BLOB as u64)
+            .flat_map(|i| (Scalar::ONE.double() * Scalar::from(i)).to_bytes_be())
+            .collect();
+        let blob: BlobRef = blob.as_slice().try_into().unwrap();
+
+        let commitment: KZGCommitment = ctx.blob_to_kzg_commitment(blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(blob).unwrap();
+        let indices: Vec<CellIndex> = (0..CELLS_PER_EXT_BLOB as u64).collect();
+        ctx.verify_cell_kzg_proof_batch(
+            vec![&commitment; CELLS_PER_EXT_BLOB],
+            &indices,
+            cells.iter().map(AsRef::as_ref).collect(),
+            proofs.iter().collect(),
+        )
+        .unwrap();
+
+        assert!(G1Point::generator().is_on_curve().into());
+        assert_eq!(
+            KZGCommitment::from_hex(&commitment.to_hex()),
+            Ok(commitment)
+        );
+    }
+}
@@ -1,9 +1,14 @@
-use bls12_381::fixed_base_msm::UsePrecomp;
+use std::{
+    io::{self, Read, Write},
+    sync::Arc,
+};
+
+use bls12_381::{fixed_base_msm::UsePrecomp, G1Point, Scalar};
 use erasure_codes::ReedSolomon;
 use kzg_multi_open::{Prover, ProverInput};
 use serialization::{
-    deserialize_blob_to_scalars, serialize_cells, serialize_cells_and_proofs,
-    serialize_g1_compressed,
+    deserialize_blob_to_scalars, deserialize_blob_to_scalars_into, serialize_cell,
+    serialize_cell_into, serialize_cells, serialize_cells_and_proofs, serialize_g1_compressed,
 };
 
 use crate::{
@@ -11,10 +16,10 @@ use crate::{
         CELLS_PER_EXT_BLOB, EXPANSION_FACTOR, FIELD_ELEMENTS_PER_BLOB, FIELD_ELEMENTS_PER_CELL,
         FIELD_ELEMENTS_PER_EXT_BLOB,
     },
-    errors::Error,
+    errors::{Error, ProverError},
     recovery::recover_polynomial_coeff,
     trusted_setup::{commit_key_from_setup, TrustedSetup},
-    BlobRef, Cell, CellIndex, CellRef, DASContext, KZGCommitment, KZGProof,
+    BlobRef, Cell, CellIndex, CellRef, DASContext, KZGCommitment, KZGProof, Operation, Scratch,
 };
 
 /// `ProverContext` manages the prover-side setup.
@@ -39,6 +44,7 @@ pub struct ProverContext {
     rs: ReedSolomon,
 }
 
+#[cfg(not(feature = "no-embedded-setup"))]
 impl Default for ProverContext {
     fn default() -> Self {
         Self::new(&TrustedSetup::default(), UsePrecomp::No)
@@ -59,6 +65,7 @@ impl ProverContext {
     ///   providing the cryptographic material for KZG operations.
     /// * `use_precomp` — Whether to enable prover-side precomputations
     ///   for faster proof generation (at the cost of extra memory).
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     pub fn new(trusted_setup: &TrustedSetup, use_precomp: UsePrecomp) -> Self {
         let commit_key = commit_key_from_setup(trusted_setup);
 
@@ -80,35 +87,120 @@ impl ProverContext {
             use_precomp,
         );
 
-        let rs = ReedSolomon::new(
-            FIELD_ELEMENTS_PER_BLOB,
-            EXPANSION_FACTOR,
-            CELLS_PER_EXT_BLOB,
-        );
-
         Self {
             kzg_multipoint_prover,
-            rs,
+            rs: reed_solomon(),
         }
     }
+
+    /// Creates a new `ProverContext`, loading the prover-side precomputations
+    /// from `reader` instead of computing them.
+    ///
+    /// The precomputations must have been written by [`ProverContext::write_precomputations`]
+    /// using the same trusted setup, otherwise an error is returned.
+    /// They are not recomputed, so they should only be read from a trusted location.
+    pub fn from_precomputations<R: Read>(
+        trusted_setup: &TrustedSetup,
+        reader: &mut R,
+    ) -> io::Result<Self> {
+        let commit_key = commit_key_from_setup(trusted_setup);
+
+        let kzg_multipoint_prover = Prover::from_precomputations(
+            commit_key,
+            FIELD_ELEMENTS_PER_BLOB,
+            FIELD_ELEMENTS_PER_CELL,
+            FIELD_ELEMENTS_PER_EXT_BLOB,
+            reader,
+        )?;
+
+        Ok(Self {
+            kzg_multipoint_prover,
+            rs: reed_solomon(),
+        })
+    }
+
+    /// Writes the prover-side precomputations, so that they can be reloaded with
+    /// [`ProverContext::from_precomputations`].
+    pub fn write_precomputations<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        self.kzg_multipoint_prover.write_precomputations(writer)
+    }
+
+    /// Returns the G1 points that blobs are committed with, so that the EIP-4844 context can share them.
+    pub(crate) const fn g1_monomial_points(&self) -> &Arc<[G1Point]> {
+        &self.kzg_multipoint_prover.commit_key().g1s
+    }
+
+    /// Returns the number of bytes used by the commit key.
+    pub(crate) fn srs_size_in_bytes(&self) -> usize {
+        self.kzg_multipoint_prover.commit_key_size_in_bytes()
+    }
+
+    /// Returns the number of bytes used by the FK20 precomputations.
+    pub(crate) fn precomputations_size_in_bytes(&self) -> usize {
+        self.kzg_multipoint_prover.precomputations_size_in_bytes()
+    }
+
+    /// Returns the number of bytes used by the domains of the prover and the Reed-Solomon encoder.
+    pub(crate) fn domains_size_in_bytes(&self) -> usize {
+        self.kzg_multipoint_prover.domains_size_in_bytes() + self.rs.size_in_bytes()
+    }
+}
+
+fn reed_solomon() -> ReedSolomon {
+    ReedSolomon::new(
+        FIELD_ELEMENTS_PER_BLOB,
+        EXPANSION_FACTOR,
+        CELLS_PER_EXT_BLOB,
+    )
 }
 
 impl DASContext {
+    /// Returns the prover context, or an error if this context was built with
+    /// [`crate::DASContextBuilder::verifier_only`].
+    pub(crate) fn prover(&self) -> Result<&ProverContext, Error> {
+        self.local_prover()
+            .ok_or(Error::Prover(ProverError::VerifierOnlyContext))
+    }
+
+    /// Returns the prover context, if there is one.
+    ///
+    /// On a thread of one of the NUMA nodes of a context built with
+    /// [`crate::DASContextBuilder::numa_aware`], this is the copy allocated on that node.
+    fn local_prover(&self) -> Option<&ProverContext> {
+        #[cfg(feature = "numa")]
+        if let Some(prover_ctx) =
+            crate::numa::current_node_index().and_then(|index| self.numa_prover_ctxs.get(index))
+        {
+            return Some(prover_ctx);
+        }
+        self.prover_ctx.as_deref()
+    }
+
     /// Computes the KZG commitment to the polynomial represented by the blob.
     ///
     /// The matching function in the specs is: https://github.com/ethereum/consensus-specs/blob/13ac373a2c284dc66b48ddd2ef0a10537e4e0de6/specs/deneb/polynomial-commitments.md#blob_to_kzg_commitment
     pub fn blob_to_kzg_commitment(&self, blob: BlobRef) -> Result<KZGCommitment, Error> {
-        // Deserialize the blob into scalars.
-        let scalars = deserialize_blob_to_scalars(blob)?;
+        self.run(Operation::BlobToKzgCommitment, 1, || {
+            // Committing to a blob is also part of the EIP-4844 API, which a verifier-only
+            // context still serves.
+            let Some(prover_ctx) = self.local_prover() else {
+                return self
+                    .eip4844_ctx
+                    .blob_to_kzg_commitment(blob)
+                    .map_err(Error::EIP4844);
+            };
+
+            // Deserialize the blob into scalars.
+            let scalars = deserialize_blob_to_scalars(blob)?;
 
-        // Compute commitment
-        let commitment = self
-            .prover_ctx
-            .kzg_multipoint_prover
-            .commit(ProverInput::Data(scalars));
+            // Compute commitment
+            let commitment = prover_ctx
+                .kzg_multipoint_prover
+                .commit(ProverInput::Data(scalars));
 
-        // Serialize the commitment.
-        Ok(serialize_g1_compressed(&commitment))
+            // Serialize the commitment.
+            Ok(serialize_g1_compressed(&commitment))
+        })
     }
 
     /// Computes the cells and the KZG proofs for the given blob.
@@ -118,33 +210,304 @@ impl DASContext {
         &self,
         blob: BlobRef,
     ) -> Result<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB]), Error> {
-        #[cfg(feature = "tracing")]
-        let _span = tracing::info_span!("compute_cells_and_kzg_proofs").entered();
+        self.run(Operation::ComputeCellsAndKzgProofs, 1, || {
+            let prover_ctx = self.prover()?;
+
+            #[cfg(feature = "tracing")]
+            let _span = tracing::info_span!("compute_cells_and_kzg_proofs").entered();
+
+            // Deserialization
+            let scalars = deserialize_blob_to_scalars(blob)?;
 
-        // Deserialization
-        let scalars = deserialize_blob_to_scalars(blob)?;
+            #[cfg(feature = "paranoid-checks")]
+            let commitment = prover_ctx
+                .kzg_multipoint_prover
+                .commit(ProverInput::Data(scalars.clone()));
+
+            // Computation
+            let (proofs, cells) = prover_ctx
+                .kzg_multipoint_prover
+                .compute_multi_opening_proofs(ProverInput::Data(scalars));
+
+            #[cfg(feature = "paranoid-checks")]
+            self.paranoid_check_proofs(commitment, &proofs, &cells);
+
+            Ok(serialize_cells_and_proofs(&cells, &proofs))
+        })
+    }
 
-        // Computation
-        let (proofs, cells) = self
-            .prover_ctx
-            .kzg_multipoint_prover
-            .compute_multi_opening_proofs(ProverInput::Data(scalars));
+    /// Creates the scratch buffers needed by [`DASContext::compute_cells_and_kzg_proofs_with_scratch`].
+    ///
+    /// # Panics
+    ///
+    /// Panics if this context was built with [`crate::DASContextBuilder::verifier_only`].
+    pub fn new_scratch(&self) -> Scratch {
+        self.try_new_scratch()
+            .expect("a verifier-only context cannot create prover scratch buffers")
+    }
 
-        Ok(serialize_cells_and_proofs(&cells, &proofs))
+    /// Creates the scratch buffers needed by [`DASContext::compute_cells_and_kzg_proofs_with_scratch`],
+    /// or returns an error if this context was built with [`crate::DASContextBuilder::verifier_only`].
+    pub fn try_new_scratch(&self) -> Result<Scratch, Error> {
+        let prover_ctx = self.prover()?;
+        Ok(Scratch::new(prover_ctx.kzg_multipoint_prover.new_scratch()))
+    }
+
+    /// Computes the cells and the KZG proofs for the given blob, using `scratch` for all
+    /// intermediate values.
+    ///
+    /// This returns the same cells and proofs as [`DASContext::compute_cells_and_kzg_proofs`],
+    /// but they are written into `scratch` and borrowed from it, so no large allocations are
+    /// made once the scratch has been created. The results are overwritten by the next call.
+    pub fn compute_cells_and_kzg_proofs_with_scratch<'a>(
+        &self,
+        blob: BlobRef,
+        scratch: &'a mut Scratch,
+    ) -> Result<
+        (
+            &'a [Cell; CELLS_PER_EXT_BLOB],
+            &'a [KZGProof; CELLS_PER_EXT_BLOB],
+        ),
+        Error,
+    > {
+        self.run(Operation::ComputeCellsAndKzgProofs, 1, move || {
+            let prover_ctx = self.prover()?;
+
+            #[cfg(feature = "tracing")]
+            let _span = tracing::info_span!("compute_cells_and_kzg_proofs_with_scratch").entered();
+
+            // Deserialization
+            deserialize_blob_to_scalars_into(blob, &mut scratch.blob_scalars)?;
+
+            // Computation
+            //
+            // The scalars are moved into the input and back, so that their buffer is reused.
+            let input = ProverInput::Data(std::mem::take(&mut scratch.blob_scalars));
+            let (proofs, evaluations) = prover_ctx
+                .kzg_multipoint_prover
+                .compute_multi_opening_proofs_with_scratch(&input, &mut scratch.prover);
+
+            #[cfg(feature = "paranoid-checks")]
+            {
+                let ProverInput::Data(scalars) = &input else {
+                    unreachable!("the input is always created from the blob")
+                };
+                let commitment = prover_ctx
+                    .kzg_multipoint_prover
+                    .commit(ProverInput::Data(scalars.clone()));
+                let evaluations: Vec<_> = evaluations
+                    .chunks_exact(FIELD_ELEMENTS_PER_CELL)
+                    .map(<[_]>::to_vec)
+                    .collect();
+                self.paranoid_check_proofs(commitment, proofs, &evaluations);
+            }
+
+            // Serialization
+            serialize_cells_into(evaluations, &mut scratch.cells);
+            serialize_proofs_into(proofs, &mut scratch.proofs);
+
+            if let ProverInput::Data(scalars) = input {
+                scratch.blob_scalars = scalars;
+            }
+
+            Ok((&mut scratch.cells, &mut scratch.proofs))
+        })
+        .map(|(cells, proofs)| (&*cells, &*proofs))
+    }
+
+    /// Computes the cells and the KZG proofs for each of the given blobs.
+    ///
+    /// This returns the same result as calling [`DASContext::compute_cells_and_kzg_proofs`]
+    /// on each blob, but processes the blobs together, which is faster than calling it in a loop.
+    /// If any of the blobs is invalid, an error is returned.
+    pub fn compute_cells_and_kzg_proofs_batch(
+        &self,
+        blobs: Vec<BlobRef>,
+    ) -> Result<Vec<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB])>, Error> {
+        self.run(
+            Operation::ComputeCellsAndKzgProofsBatch,
+            blobs.len(),
+            || {
+                let prover_ctx = self.prover()?;
+
+                #[cfg(feature = "tracing")]
+                let _span = tracing::info_span!("compute_cells_and_kzg_proofs_batch").entered();
+
+                // Deserialization
+                let inputs = blobs
+                    .into_iter()
+                    .map(|blob| deserialize_blob_to_scalars(blob).map(ProverInput::Data))
+                    .collect::<Result<Vec<_>, _>>()?;
+
+                #[cfg(feature = "paranoid-checks")]
+                let commitments: Vec<_> = inputs
+                    .iter()
+                    .map(|input| match input {
+                        ProverInput::Data(scalars) => prover_ctx
+                            .kzg_multipoint_prover
+                            .commit(ProverInput::Data(scalars.clone())),
+                        ProverInput::PolyCoeff(_) => {
+                            unreachable!("the inputs are always created from the blobs")
+                        }
+                    })
+                    .collect();
+
+                // Computation
+                let proofs_and_cells = prover_ctx
+                    .kzg_multipoint_prover
+                    .compute_multi_opening_proofs_batch(inputs);
+
+                #[cfg(feature = "paranoid-checks")]
+                for (commitment, (proofs, cells)) in commitments.into_iter().zip(&proofs_and_cells)
+                {
+                    self.paranoid_check_proofs(commitment, proofs, cells);
+                }
+
+                Ok(proofs_and_cells
+                    .iter()
+                    .map(|(proofs, cells)| serialize_cells_and_proofs(cells, proofs))
+                    .collect())
+            },
+        )
+    }
+
+    /// Computes the commitment, the cells and the KZG proofs for every blob in a slot.
+    ///
+    /// This returns the same result as calling [`DASContext::blob_to_kzg_commitment`] and
+    /// [`DASContext::compute_cells_and_kzg_proofs`] on each blob, but each blob is only
+    /// interpolated once and the commitments, extensions and proofs for all of the blobs
+    /// are scheduled on the thread pool together. Block builders handling a full slot
+    /// of blobs should prefer this over per-blob calls.
+    /// If any of the blobs is invalid, an error is returned.
+    #[allow(clippy::type_complexity)]
+    pub fn process_slot(
+        &self,
+        blobs: &[BlobRef],
+    ) -> Result<
+        Vec<(
+            KZGCommitment,
+            [Cell; CELLS_PER_EXT_BLOB],
+            [KZGProof; CELLS_PER_EXT_BLOB],
+        )>,
+        Error,
+    > {
+        self.run(Operation::ProcessSlot, blobs.len(), || {
+            let prover_ctx = self.prover()?;
+
+            #[cfg(feature = "tracing")]
+            let _span = tracing::info_span!("process_slot", num_blobs = blobs.len()).entered();
+
+            // Deserialization
+            let inputs = blobs
+                .iter()
+                .map(|blob| deserialize_blob_to_scalars(*blob).map(ProverInput::Data))
+                .collect::<Result<Vec<_>, _>>()?;
+
+            // Computation
+            let results = prover_ctx
+                .kzg_multipoint_prover
+                .commit_and_compute_multi_opening_proofs_batch(inputs);
+
+            #[cfg(feature = "paranoid-checks")]
+            for (commitment, proofs, cells) in &results {
+                self.paranoid_check_proofs(*commitment, proofs, cells);
+            }
+
+            Ok(results
+                .iter()
+                .map(|(commitment, proofs, cells)| {
+                    let (cells, proofs) = serialize_cells_and_proofs(cells, proofs);
+                    (serialize_g1_compressed(commitment), cells, proofs)
+                })
+                .collect())
+        })
+    }
+
+    /// Computes a single cell and its KZG proof for the given blob.
+    ///
+    /// This returns the same cell and proof as the entry at `cell_index` in the output of
+    /// [`DASContext::compute_cells_and_kzg_proofs`], without computing the proofs for the
+    /// other cells. Use it when a single cell needs to be proven again, for example after it
+    /// has been repaired; when many cells are needed, computing all of them at once is faster.
+    pub fn compute_cell_and_kzg_proof(
+        &self,
+        blob: BlobRef,
+        cell_index: CellIndex,
+    ) -> Result<(Cell, KZGProof), Error> {
+        self.run(Operation::ComputeCellAndKzgProof, 1, || {
+            let prover_ctx = self.prover()?;
+
+            if cell_index >= CELLS_PER_EXT_BLOB as u64 {
+                return Err(ProverError::CellIndexOutOfRange {
+                    cell_index,
+                    max_number_of_cells: CELLS_PER_EXT_BLOB as u64,
+                }
+                .into());
+            }
+
+            // Deserialization
+            let scalars = deserialize_blob_to_scalars(blob)?;
+
+            // Computation
+            let (proof, cell) = prover_ctx
+                .kzg_multipoint_prover
+                .compute_single_opening_proof(ProverInput::Data(scalars), cell_index);
+
+            Ok((serialize_cell(&cell), serialize_g1_compressed(&proof)))
+        })
     }
 
     /// Computes the cells for the given blob.
     pub fn compute_cells(&self, blob: BlobRef) -> Result<[Cell; CELLS_PER_EXT_BLOB], Error> {
-        // Deserialization
-        let scalars = deserialize_blob_to_scalars(blob)?;
+        self.run(Operation::ComputeCells, 1, || {
+            let prover_ctx = self.prover()?;
+
+            // Deserialization
+            let scalars = deserialize_blob_to_scalars(blob)?;
+
+            // Computation
+            let extended_blob = prover_ctx
+                .kzg_multipoint_prover
+                .extend_polynomial(ProverInput::Data(scalars));
+
+            Ok(serialize_cells(&extended_blob))
+        })
+    }
+
+    /// Computes the cells for the given blob, using `scratch` for all intermediate values.
+    ///
+    /// This returns the same cells as [`DASContext::compute_cells`], but like
+    /// [`DASContext::compute_cells_and_kzg_proofs_with_scratch`], they are written into
+    /// `scratch` and borrowed from it, and are overwritten by the next call.
+    pub fn compute_cells_with_scratch<'a>(
+        &self,
+        blob: BlobRef,
+        scratch: &'a mut Scratch,
+    ) -> Result<&'a [Cell; CELLS_PER_EXT_BLOB], Error> {
+        self.run(Operation::ComputeCells, 1, move || {
+            let prover_ctx = self.prover()?;
+
+            // Deserialization
+            deserialize_blob_to_scalars_into(blob, &mut scratch.blob_scalars)?;
+
+            // Computation
+            //
+            // The scalars are moved into the input and back, so that their buffer is reused.
+            let input = ProverInput::Data(std::mem::take(&mut scratch.blob_scalars));
+            let evaluations = prover_ctx
+                .kzg_multipoint_prover
+                .extend_polynomial_with_scratch(&input, &mut scratch.prover);
+
+            // Serialization
+            serialize_cells_into(evaluations, &mut scratch.cells);
 
-        // Computation
-        let extended_blob = self
-            .prover_ctx
-            .kzg_multipoint_prover
-            .extend_polynomial(ProverInput::Data(scalars));
+            if let ProverInput::Data(scalars) = input {
+                scratch.blob_scalars = scalars;
+            }
 
-        Ok(serialize_cells(&extended_blob))
+            Ok(&mut scratch.cells)
+        })
+        .map(|cells| &*cells)
     }
 
     /// Recovers the cells and computes the KZG proofs, given a subset of cells.
@@ -158,15 +521,155 @@ impl DASContext {
         cell_indices: Vec<CellIndex>,
         cells: Vec<CellRef>,
     ) -> Result<([Cell; CELLS_PER_EXT_BLOB], [KZGProof; CELLS_PER_EXT_BLOB]), Error> {
-        // Recover polynomial
-        let poly_coeff = recover_polynomial_coeff(&self.prover_ctx.rs, cell_indices, cells)?;
+        self.run(Operation::RecoverCellsAndKzgProofs, cells.len(), || {
+            let prover_ctx = self.prover()?;
 
-        // Compute proofs and evaluation sets
-        let (proofs, coset_evaluations) = self
-            .prover_ctx
-            .kzg_multipoint_prover
-            .compute_multi_opening_proofs(ProverInput::PolyCoeff(poly_coeff.into()));
+            #[cfg(feature = "tracing")]
+            let _span =
+                tracing::info_span!("recover_cells_and_kzg_proofs", num_cells = cells.len())
+                    .entered();
 
-        Ok(serialize_cells_and_proofs(&coset_evaluations, &proofs))
+            #[cfg(feature = "paranoid-checks")]
+            let (input_cell_indices, input_cells) = (cell_indices.clone(), cells.clone());
+
+            // Recover polynomial
+            let poly_coeff = recover_polynomial_coeff(&prover_ctx.rs, cell_indices, cells)?;
+
+            #[cfg(feature = "paranoid-checks")]
+            let checked_poly_coeff = poly_coeff.clone();
+
+            // Compute proofs and evaluation sets
+            let (proofs, coset_evaluations) = prover_ctx
+                .kzg_multipoint_prover
+                .compute_multi_opening_proofs(ProverInput::PolyCoeff(poly_coeff.into()));
+
+            #[cfg(feature = "paranoid-checks")]
+            {
+                let commitment = prover_ctx
+                    .kzg_multipoint_prover
+                    .commit(ProverInput::PolyCoeff(checked_poly_coeff.clone().into()));
+                crate::paranoid::check_recovery(
+                    &prover_ctx.kzg_multipoint_prover,
+                    &input_cell_indices,
+                    &input_cells,
+                    checked_poly_coeff,
+                    &coset_evaluations,
+                );
+                self.paranoid_check_proofs(commitment, &proofs, &coset_evaluations);
+            }
+
+            Ok(serialize_cells_and_proofs(&coset_evaluations, &proofs))
+        })
+    }
+
+    /// Recovers the cells and computes the KZG proofs, given a subset of cells, using
+    /// `scratch` for the intermediate values of the proofs.
+    ///
+    /// This returns the same cells and proofs as [`DASContext::recover_cells_and_kzg_proofs`],
+    /// but like [`DASContext::compute_cells_and_kzg_proofs_with_scratch`], they are written
+    /// into `scratch` and borrowed from it, and are overwritten by the next call. The erasure
+    /// decoding still allocates its own buffers, which are about as large as an extended blob.
+    pub fn recover_cells_and_kzg_proofs_with_scratch<'a>(
+        &self,
+        cell_indices: Vec<CellIndex>,
+        cells: Vec<CellRef>,
+        scratch: &'a mut Scratch,
+    ) -> Result<
+        (
+            &'a [Cell; CELLS_PER_EXT_BLOB],
+            &'a [KZGProof; CELLS_PER_EXT_BLOB],
+        ),
+        Error,
+    > {
+        self.run(
+            Operation::RecoverCellsAndKzgProofs,
+            cells.len(),
+            move || {
+                let prover_ctx = self.prover()?;
+
+                #[cfg(feature = "tracing")]
+                let _span = tracing::info_span!(
+                    "recover_cells_and_kzg_proofs_with_scratch",
+                    num_cells = cells.len()
+                )
+                .entered();
+
+                #[cfg(feature = "paranoid-checks")]
+                let (input_cell_indices, input_cells) = (cell_indices.clone(), cells.clone());
+
+                // Recover polynomial
+                let poly_coeff = recover_polynomial_coeff(&prover_ctx.rs, cell_indices, cells)?;
+
+                // Compute proofs and evaluation sets
+                let input = ProverInput::PolyCoeff(poly_coeff.into());
+                let (proofs, evaluations) = prover_ctx
+                    .kzg_multipoint_prover
+                    .compute_multi_opening_proofs_with_scratch(&input, &mut scratch.prover);
+
+                #[cfg(feature = "paranoid-checks")]
+                {
+                    let ProverInput::PolyCoeff(poly_coeff) = input else {
+                        unreachable!("the input is always the recovered polynomial")
+                    };
+                    let commitment = prover_ctx
+                        .kzg_multipoint_prover
+                        .commit(ProverInput::PolyCoeff(poly_coeff.clone()));
+                    let evaluations: Vec<_> = evaluations
+                        .chunks_exact(FIELD_ELEMENTS_PER_CELL)
+                        .map(<[_]>::to_vec)
+                        .collect();
+                    crate::paranoid::check_recovery(
+                        &prover_ctx.kzg_multipoint_prover,
+                        &input_cell_indices,
+                        &input_cells,
+                        poly_coeff.0,
+                        &evaluations,
+                    );
+                    self.paranoid_check_proofs(commitment, proofs, &evaluations);
+                }
+
+                // Serialization
+                serialize_cells_into(evaluations, &mut scratch.cells);
+                serialize_proofs_into(proofs, &mut scratch.proofs);
+
+                Ok((&mut scratch.cells, &mut scratch.proofs))
+            },
+        )
+        .map(|(cells, proofs)| (&*cells, &*proofs))
+    }
+}
+
+/// Serializes the flattened coset evaluations computed with a scratch into its cells.
+fn serialize_cells_into(evaluations: &[Scalar], cells: &mut [Cell; CELLS_PER_EXT_BLOB]) {
+    for (cell, evaluation) in cells
+        .iter_mut()
+        .zip(evaluations.chunks_exact(FIELD_ELEMENTS_PER_CELL))
+    {
+        serialize_cell_into(evaluation, cell);
+    }
+}
+
+/// Serializes the proofs computed with a scratch into its proofs.
+fn serialize_proofs_into(proofs: &[G1Point], out: &mut [KZGProof; CELLS_PER_EXT_BLOB]) {
+    for (out, proof) in out.iter_mut().zip(proofs) {
+        *out = proof.to_compressed();
+    }
+}
+
+#[cfg(feature = "paranoid-checks")]
+impl DASContext {
+    /// Verifies `proofs` with the reference verifier, see [`crate::paranoid::check_proofs`].
+    fn paranoid_check_proofs(
+        &self,
+        commitment: bls12_381::G1Point,
+        proofs: &[bls12_381::G1Point],
+        coset_evaluations: &[Vec<bls12_381::Scalar>],
+    ) {
+        crate::paranoid::check_proofs(
+            self.verifier_ctx.verification_key(),
+            commitment,
+            proofs,
+            coset_evaluations,
+        );
     }
 }
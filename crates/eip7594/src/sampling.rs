@@ -0,0 +1,256 @@
+//! Verification of the cells that a node samples from its peers.
+//!
+//! A sampling scheduler requests cells from many peers, and the cells arrive one response at a
+//! time. [`SampleVerifier`] collects them and verifies them in batches, and when a batch fails,
+//! it finds the peers that sent the invalid cells, so that the scheduler can penalise them and
+//! request the cells again from other peers.
+
+use std::collections::HashMap;
+use std::hash::Hash;
+
+use crate::{errors::Error, Cell, CellIndex, DASContext, KZGCommitment, KZGProof};
+
+/// The root of the block that a sample belongs to.
+pub type BlockRoot = [u8; 32];
+
+/// A cell of a block, with its proof and the commitment to its blob.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Sample {
+    pub block_root: BlockRoot,
+    /// The index of the column that the cell is in.
+    pub column: CellIndex,
+    pub cell: Cell,
+    pub proof: KZGProof,
+    pub commitment: KZGCommitment,
+}
+
+/// A sample that failed verification, and the source that it came from.
+#[derive(Debug)]
+pub struct SampleFailure<S> {
+    pub source: S,
+    pub block_root: BlockRoot,
+    pub column: CellIndex,
+    pub error: Error,
+}
+
+/// The result of verifying a batch of samples.
+#[derive(Debug)]
+pub struct SampleBatchReport<S> {
+    /// The number of samples that were valid.
+    pub verified: usize,
+    /// The samples that were invalid, in the order that they were added.
+    pub failures: Vec<SampleFailure<S>>,
+}
+
+impl<S: PartialEq> SampleBatchReport<S> {
+    /// Returns true if every sample of the batch was valid.
+    pub fn is_valid(&self) -> bool {
+        self.failures.is_empty()
+    }
+
+    /// Returns the sources that sent at least one invalid sample, in the order of their first
+    /// failure.
+    pub fn failed_sources(&self) -> Vec<&S> {
+        let mut sources: Vec<&S> = Vec::new();
+        for failure in &self.failures {
+            if !sources.contains(&&failure.source) {
+                sources.push(&failure.source);
+            }
+        }
+        sources
+    }
+}
+
+/// Collects samples from several sources, and verifies them in batches.
+///
+/// The source is whatever the caller uses to identify where a sample came from, such as a
+/// peer ID. Samples are verified together in a single batch, which is much cheaper than
+/// verifying them one at a time. If the batch fails, the samples of each source are verified
+/// on their own, and only the samples of the sources whose batch fails are verified one at a
+/// time, so a single bad peer does not make the samples of the other peers fail.
+#[derive(Debug)]
+pub struct SampleVerifier<'a, S> {
+    ctx: &'a DASContext,
+    max_batch_size: usize,
+    samples: Vec<(S, Sample)>,
+}
+
+impl DASContext {
+    /// Returns a [`SampleVerifier`] that verifies a batch once `max_batch_size` samples have
+    /// been added.
+    ///
+    /// # Panics
+    /// Panics if `max_batch_size` is zero.
+    pub fn sample_verifier<S>(&self, max_batch_size: usize) -> SampleVerifier<'_, S> {
+        assert!(max_batch_size > 0, "a batch must hold at least one sample");
+        SampleVerifier {
+            ctx: self,
+            max_batch_size,
+            samples: Vec::new(),
+        }
+    }
+}
+
+impl<S: Clone + Eq + Hash> SampleVerifier<'_, S> {
+    /// Adds a sample from `source`, and verifies the batch if it is full.
+    pub fn push(&mut self, source: S, sample: Sample) -> Option<SampleBatchReport<S>> {
+        self.samples.push((source, sample));
+        (self.samples.len() >= self.max_batch_size).then(|| self.flush())
+    }
+
+    /// Returns the number of samples that have been added since the last batch was verified.
+    pub fn len(&self) -> usize {
+        self.samples.len()
+    }
+
+    /// Returns true if no samples have been added since the last batch was verified.
+    pub fn is_empty(&self) -> bool {
+        self.samples.is_empty()
+    }
+
+    /// Verifies the samples that have been added since the last batch was verified.
+    pub fn flush(&mut self) -> SampleBatchReport<S> {
+        let samples = std::mem::take(&mut self.samples);
+        let mut report = SampleBatchReport {
+            verified: 0,
+            failures: Vec::new(),
+        };
+
+        let all: Vec<usize> = (0..samples.len()).collect();
+        if self.verify(&samples, &all).is_ok() {
+            report.verified = samples.len();
+            return report;
+        }
+
+        // Group the samples by source, keeping the order that the sources were first seen
+        let mut positions = HashMap::new();
+        let mut by_source: Vec<Vec<usize>> = Vec::new();
+        for (index, (source, _)) in samples.iter().enumerate() {
+            let position = *positions.entry(source).or_insert_with(|| {
+                by_source.push(Vec::new());
+                by_source.len() - 1
+            });
+            by_source[position].push(index);
+        }
+
+        let mut failures = Vec::new();
+        for group in by_source {
+            if self.verify(&samples, &group).is_ok() {
+                report.verified += group.len();
+                continue;
+            }
+            for index in group {
+                match self.verify(&samples, &[index]) {
+                    Ok(()) => report.verified += 1,
+                    Err(error) => failures.push((index, error)),
+                }
+            }
+        }
+
+        // Report the failures in the order that the samples were added
+        failures.sort_unstable_by_key(|(index, _)| *index);
+        report.failures = failures
+            .into_iter()
+            .map(|(index, error)| {
+                let (source, sample) = &samples[index];
+                SampleFailure {
+                    source: source.clone(),
+                    block_root: sample.block_root,
+                    column: sample.column,
+                    error,
+                }
+            })
+            .collect();
+
+        report
+    }
+
+    /// Verifies the samples at `indices` in a single batch.
+    fn verify(&self, samples: &[(S, Sample)], indices: &[usize]) -> Result<(), Error> {
+        let samples: Vec<&Sample> = indices.iter().map(|&index| &samples[index].1).collect();
+        let commitments = samples.iter().map(|sample| &sample.commitment).collect();
+        let columns: Vec<_> = samples.iter().map(|sample| sample.column).collect();
+        let cells = samples.iter().map(|sample| &*sample.cell).collect();
+        let proofs = samples.iter().map(|sample| &sample.proof).collect();
+        self.ctx
+            .verify_cell_kzg_proof_batch(commitments, &columns, cells, proofs)
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use bls12_381::Scalar;
+
+    use super::Sample;
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT},
+        CellIndex, DASContext,
+    };
+
+    fn samples(ctx: &DASContext, block_root: [u8; 32], columns: &[CellIndex]) -> Vec<Sample> {
+        let mut blob = [0u8; BYTES_PER_BLOB];
+        for (i, chunk) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            chunk.copy_from_slice(&Scalar::from(i as u64 + u64::from(block_root[0])).to_bytes_be());
+        }
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+
+        columns
+            .iter()
+            .map(|&column| Sample {
+                block_root,
+                column,
+                cell: cells[column as usize].clone(),
+                proof: proofs[column as usize],
+                commitment,
+            })
+            .collect()
+    }
+
+    #[test]
+    fn valid_samples_are_verified_in_batches() {
+        let ctx = DASContext::default();
+        let mut verifier = ctx.sample_verifier(3);
+
+        let mut reports = Vec::new();
+        for (i, sample) in samples(&ctx, [1; 32], &[0, 5, 9, 17])
+            .into_iter()
+            .enumerate()
+        {
+            reports.extend(verifier.push(i % 2, sample));
+        }
+        assert_eq!(reports.len(), 1);
+        assert_eq!(reports[0].verified, 3);
+        assert!(reports[0].is_valid());
+
+        assert_eq!(verifier.len(), 1);
+        let report = verifier.flush();
+        assert_eq!(report.verified, 1);
+        assert!(verifier.is_empty());
+        assert_eq!(verifier.flush().verified, 0);
+    }
+
+    #[test]
+    fn failures_are_attributed_to_their_source() {
+        let ctx = DASContext::default();
+        let mut verifier = ctx.sample_verifier(usize::MAX);
+
+        let good = samples(&ctx, [1; 32], &[0, 1, 2]);
+        let mut bad = samples(&ctx, [2; 32], &[3, 4]);
+        bad[1].proof = bad[0].proof;
+
+        verifier.push("honest", good[0].clone());
+        verifier.push("faulty", bad[0].clone());
+        verifier.push("honest", good[1].clone());
+        verifier.push("faulty", bad[1].clone());
+        verifier.push("honest", good[2].clone());
+
+        let report = verifier.flush();
+        assert_eq!(report.verified, 4);
+        assert_eq!(report.failed_sources(), vec![&"faulty"]);
+        assert_eq!(report.failures.len(), 1);
+        assert_eq!(report.failures[0].block_root, [2; 32]);
+        assert_eq!(report.failures[0].column, 4);
+        assert!(report.failures[0].error.is_proof_invalid());
+    }
+}
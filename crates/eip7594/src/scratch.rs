@@ -0,0 +1,150 @@
+use bls12_381::{traits::*, Scalar};
+use kzg_multi_open::ProverScratch;
+
+use crate::{
+    constants::{
+        BYTES_PER_CELL, BYTES_PER_COMMITMENT, CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_BLOB,
+    },
+    Cell, KZGProof,
+};
+
+/// Reusable buffers for computing cells and proofs without allocating per call.
+///
+/// A `Scratch` is created once with [`DASContext::new_scratch`](crate::DASContext::new_scratch)
+/// and passed to [`DASContext::compute_cells_and_kzg_proofs_with_scratch`](crate::DASContext::compute_cells_and_kzg_proofs_with_scratch),
+/// [`DASContext::compute_cells_with_scratch`](crate::DASContext::compute_cells_with_scratch) or
+/// [`DASContext::recover_cells_and_kzg_proofs_with_scratch`](crate::DASContext::recover_cells_and_kzg_proofs_with_scratch)
+/// for every blob. It holds the FFT and MSM buffers used by the prover, along with the
+/// serialized cells and proofs, so steady-state proving does not perform any large allocations.
+///
+/// A `Scratch` can only be used by one thread at a time; services proving on several threads
+/// should keep one per thread.
+#[derive(Debug, Clone)]
+pub struct Scratch {
+    /// The intermediate values used by the FK20 prover.
+    pub(crate) prover: ProverScratch,
+    /// The blob, deserialized into scalars.
+    pub(crate) blob_scalars: Vec<Scalar>,
+    /// The cells computed by the last call.
+    pub(crate) cells: [Cell; CELLS_PER_EXT_BLOB],
+    /// The proofs computed by the last call.
+    pub(crate) proofs: [KZGProof; CELLS_PER_EXT_BLOB],
+}
+
+impl Scratch {
+    pub(crate) fn new(prover: ProverScratch) -> Self {
+        Self {
+            prover,
+            blob_scalars: vec![Scalar::ZERO; FIELD_ELEMENTS_PER_BLOB],
+            cells: std::array::from_fn(|_| Box::new([0u8; BYTES_PER_CELL])),
+            proofs: [[0u8; BYTES_PER_COMMITMENT]; CELLS_PER_EXT_BLOB],
+        }
+    }
+
+    /// Returns the number of bytes used by the buffers.
+    pub fn size_in_bytes(&self) -> usize {
+        use std::mem::size_of;
+
+        self.prover.size_in_bytes()
+            + self.blob_scalars.len() * size_of::<Scalar>()
+            + CELLS_PER_EXT_BLOB * (BYTES_PER_CELL + size_of::<KZGProof>())
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use bls12_381::Scalar;
+
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT, CELLS_PER_EXT_BLOB},
+        DASContext, ErrorCode,
+    };
+
+    fn blob(seed: u64) -> Vec<u8> {
+        let mut blob = vec![0u8; BYTES_PER_BLOB];
+        for (i, chunk) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            chunk.copy_from_slice(&Scalar::from(seed + i as u64).to_bytes_be());
+        }
+        blob
+    }
+
+    #[test]
+    fn scratch_matches_allocating_api() {
+        let ctx = DASContext::default();
+        let mut scratch = ctx.new_scratch();
+
+        // Reuse the same scratch for several blobs
+        for seed in [1, 1000] {
+            let blob = blob(seed);
+            let blob = blob.as_slice().try_into().unwrap();
+
+            let (expected_cells, expected_proofs) = ctx.compute_cells_and_kzg_proofs(blob).unwrap();
+            let (cells, proofs) = ctx
+                .compute_cells_and_kzg_proofs_with_scratch(blob, &mut scratch)
+                .unwrap();
+            assert_eq!(cells, &expected_cells);
+            assert_eq!(proofs, &expected_proofs);
+
+            let cells = ctx.compute_cells_with_scratch(blob, &mut scratch).unwrap();
+            assert_eq!(cells, &expected_cells);
+
+            // Recover from the second half of the cells
+            let cell_indices: Vec<_> =
+                (CELLS_PER_EXT_BLOB as u64 / 2..CELLS_PER_EXT_BLOB as u64).collect();
+            let half: Vec<_> = cell_indices
+                .iter()
+                .map(|&index| &*expected_cells[index as usize])
+                .collect();
+            let (cells, proofs) = ctx
+                .recover_cells_and_kzg_proofs_with_scratch(cell_indices, half, &mut scratch)
+                .unwrap();
+            assert_eq!(cells, &expected_cells);
+            assert_eq!(proofs, &expected_proofs);
+        }
+    }
+
+    #[cfg(feature = "fault-injection")]
+    #[test]
+    fn scratch_outputs_are_corrupted_in_place() {
+        use std::sync::Arc;
+
+        use crate::{
+            fault_injection::{Fault, FaultInjector},
+            Operation,
+        };
+
+        let faults = Arc::new(
+            FaultInjector::new()
+                .inject(Operation::ComputeCells, 1, Fault::CorruptOutput)
+                .inject(Operation::ComputeCellsAndKzgProofs, 1, Fault::CorruptOutput),
+        );
+        let ctx = DASContext::default();
+        let faulty = ctx.clone().with_fault_injector(faults.clone());
+        let mut scratch = faulty.new_scratch();
+
+        let blob = blob(1);
+        let blob = blob.as_slice().try_into().unwrap();
+        let (expected_cells, expected_proofs) = ctx.compute_cells_and_kzg_proofs(blob).unwrap();
+
+        let cells = faulty.compute_cells_with_scratch(blob, &mut scratch).unwrap();
+        assert_ne!(cells, &expected_cells);
+
+        let (cells, proofs) = faulty
+            .compute_cells_and_kzg_proofs_with_scratch(blob, &mut scratch)
+            .unwrap();
+        assert_eq!(cells, &expected_cells);
+        assert_ne!(proofs, &expected_proofs);
+
+        // Later calls are not affected
+        let cells = faulty.compute_cells_with_scratch(blob, &mut scratch).unwrap();
+        assert_eq!(cells, &expected_cells);
+        assert_eq!(faults.injected(), 2);
+    }
+
+    #[test]
+    fn verifier_only_context_cannot_create_scratch() {
+        let verifier = DASContext::builder().verifier_only(true).build().unwrap();
+        let err = verifier.try_new_scratch().unwrap_err();
+        assert_eq!(err.code(), ErrorCode::VerifierOnlyContext);
+    }
+}
@@ -0,0 +1,178 @@
+//! Known-answer tests that can be run against a context at runtime.
+//!
+//! The expected outputs are taken from the consensus-spec-tests vectors for the mainnet
+//! trusted setup, so a context created from any other setup fails the self test.
+
+use crate::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT, CELLS_PER_EXT_BLOB},
+    DASContext, Error,
+};
+
+/// The index of the only non-zero field element of the known-answer blob.
+const KNOWN_BLOB_NONZERO_INDEX: usize = 3211;
+
+/// The commitment to the known-answer blob.
+const KNOWN_COMMITMENT: &str = "93efc82d2017e9c57834a1246463e64774e56183bb247c8fc9dd98c56817e878d97b05f5c8d900acf1fbbbca6f146556";
+
+/// The point at which the known-answer blob is opened.
+const KNOWN_Z: u8 = 2;
+
+/// The proof and the evaluation of the known-answer blob at [`KNOWN_Z`].
+const KNOWN_PROOF_AT_Z: &str = "893acd46552b81cc9e5ff6ca03dad873588f2c61031781367cfea2a2be4ef3090035623338711b3cf7eff4b4524df742";
+const KNOWN_Y: &str = "64d3b6baf69395bde2abd1d43f99be66bc64581234fd363e2ae3a0d419cfc3fc";
+
+/// The proofs of the first and last cells of the known-answer blob.
+const KNOWN_FIRST_CELL_PROOF: &str = "85f3852ff567e132e5ab282391419692a41829528549e712bc612398751eb6676a1a8e286fba329f4f3952f9a6bbc52a";
+const KNOWN_LAST_CELL_PROOF: &str = "a864d5e42be9adf15847801f80d0d34aa1d46fa5148d05d74c16298107b3e0a636862f97fc19359d9a1b40d3ba0f6717";
+
+/// Errors returned by [`DASContext::self_test`].
+#[derive(Debug)]
+pub enum SelfTestError {
+    /// An operation returned an error on a known-answer input.
+    Failed {
+        /// The name of the check that failed, ie `compute_cells_and_kzg_proofs`.
+        check: &'static str,
+        error: Box<Error>,
+    },
+    /// An operation returned an output that differs from the known answer.
+    WrongAnswer {
+        /// The name of the check that failed, ie `compute_cells_and_kzg_proofs`.
+        check: &'static str,
+    },
+}
+
+impl SelfTestError {
+    /// Returns the name of the check that failed.
+    pub const fn check(&self) -> &'static str {
+        match self {
+            Self::Failed { check, .. } | Self::WrongAnswer { check } => check,
+        }
+    }
+}
+
+impl DASContext {
+    /// Runs a handful of known-answer tests against the loaded setup and precomputed tables.
+    ///
+    /// This commits to, opens, extends, verifies and recovers a blob with known outputs, so that
+    /// a corrupted setup or precomputation file, or a miscompiled build, is detected before the
+    /// context is used. It takes about as long as a few calls to
+    /// [`DASContext::compute_cells_and_kzg_proofs`], so it is meant to be run once at startup.
+    ///
+    /// The known answers are for the mainnet trusted setup.
+    pub fn self_test(&self) -> Result<(), SelfTestError> {
+        let mut blob = vec![0u8; BYTES_PER_BLOB];
+        blob[KNOWN_BLOB_NONZERO_INDEX * BYTES_PER_FIELD_ELEMENT + BYTES_PER_FIELD_ELEMENT - 1] = 1;
+        let blob: &[u8; BYTES_PER_BLOB] = blob
+            .as_slice()
+            .try_into()
+            .expect("the blob has the right length");
+
+        let commitment = run("blob_to_kzg_commitment", self.blob_to_kzg_commitment(blob))?;
+        expect("blob_to_kzg_commitment", &commitment, KNOWN_COMMITMENT)?;
+
+        let mut z = [0u8; BYTES_PER_FIELD_ELEMENT];
+        z[BYTES_PER_FIELD_ELEMENT - 1] = KNOWN_Z;
+        let (proof, y) = run("compute_kzg_proof", self.compute_kzg_proof(blob, z))?;
+        expect("compute_kzg_proof", &proof, KNOWN_PROOF_AT_Z)?;
+        expect("compute_kzg_proof", &y, KNOWN_Y)?;
+        run(
+            "verify_kzg_proof",
+            self.verify_kzg_proof(&commitment, z, y, &proof),
+        )?;
+
+        let blob_proof = run(
+            "compute_blob_kzg_proof",
+            self.compute_blob_kzg_proof(blob, &commitment),
+        )?;
+        run(
+            "verify_blob_kzg_proof",
+            self.verify_blob_kzg_proof(blob, &commitment, &blob_proof),
+        )?;
+
+        // The FK20 proofs depend on every precomputed table, so a corrupted table
+        // changes at least one of them.
+        let (cells, proofs) = run(
+            "compute_cells_and_kzg_proofs",
+            self.compute_cells_and_kzg_proofs(blob),
+        )?;
+        expect(
+            "compute_cells_and_kzg_proofs",
+            &proofs[0],
+            KNOWN_FIRST_CELL_PROOF,
+        )?;
+        expect(
+            "compute_cells_and_kzg_proofs",
+            &proofs[CELLS_PER_EXT_BLOB - 1],
+            KNOWN_LAST_CELL_PROOF,
+        )?;
+
+        let cell_indices: Vec<_> = (0..CELLS_PER_EXT_BLOB as u64).collect();
+        run(
+            "verify_cell_kzg_proof_batch",
+            self.verify_cell_kzg_proof_batch(
+                vec![&commitment; CELLS_PER_EXT_BLOB],
+                &cell_indices,
+                cells.iter().map(AsRef::as_ref).collect(),
+                proofs.iter().collect(),
+            ),
+        )?;
+
+        // Recover from the second half of the cells, so that the first half has to be recomputed.
+        let half = CELLS_PER_EXT_BLOB / 2;
+        let (recovered_cells, recovered_proofs) = run(
+            "recover_cells_and_kzg_proofs",
+            self.recover_cells_and_kzg_proofs(
+                cell_indices[half..].to_vec(),
+                cells[half..].iter().map(AsRef::as_ref).collect(),
+            ),
+        )?;
+        if recovered_cells != cells || recovered_proofs != proofs {
+            return Err(SelfTestError::WrongAnswer {
+                check: "recover_cells_and_kzg_proofs",
+            });
+        }
+
+        Ok(())
+    }
+}
+
+fn run<T>(check: &'static str, result: Result<T, Error>) -> Result<T, SelfTestError> {
+    result.map_err(|error| SelfTestError::Failed {
+        check,
+        error: Box::new(error),
+    })
+}
+
+fn expect(check: &'static str, actual: &[u8], expected_hex: &str) -> Result<(), SelfTestError> {
+    let expected = hex::decode(expected_hex).expect("known answers are valid hex");
+    if actual == expected.as_slice() {
+        Ok(())
+    } else {
+        Err(SelfTestError::WrongAnswer { check })
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use super::SelfTestError;
+    use crate::{DASContext, TrustedSetup, UsePrecomp};
+
+    #[test]
+    fn self_test_passes_with_the_mainnet_setup() {
+        DASContext::default().self_test().unwrap();
+
+        let ctx = DASContext::new(&TrustedSetup::default(), UsePrecomp::Yes { width: 8 });
+        ctx.self_test().unwrap();
+    }
+
+    #[test]
+    fn self_test_detects_a_corrupted_setup() {
+        let mut trusted_setup = TrustedSetup::default();
+        trusted_setup.g1_monomial.swap(1, 2);
+
+        let ctx = DASContext::new(&trusted_setup, UsePrecomp::No);
+        let err = ctx.self_test().unwrap_err();
+        assert!(matches!(err, SelfTestError::WrongAnswer { .. }));
+        assert_eq!(err.check(), "blob_to_kzg_commitment");
+    }
+}
@@ -0,0 +1,571 @@
+//! Generation of test vectors in the format of [consensus-spec-tests].
+//!
+//! Each case is a `data.yaml` (or `data.json`) file with an `input` and an `output`, stored at
+//! `<handler>/kzg-mainnet/<case>/`, so the generated vectors can be consumed by the same runners
+//! as the official ones. The output is computed by this library; it is `null` when the input is
+//! invalid and the function is expected to return an error.
+//!
+//! For every handler, [`generate`] returns a valid case and a few systematically invalid ones,
+//! such as a blob with a non-canonical field element, a point that is not in the G1 subgroup,
+//! an incorrect proof, or cell indices that are out of range. The inputs are derived from the
+//! seed, so the same seed always gives the same vectors.
+//!
+//! [consensus-spec-tests]: https://github.com/ethereum/consensus-spec-tests
+
+use std::{
+    fs, io,
+    path::{Path, PathBuf},
+};
+
+use serde::Serialize;
+use serde_json::{json, Value};
+
+use crate::{
+    constants::{BYTES_PER_BLOB, CELLS_PER_EXT_BLOB},
+    generators::{self, InvalidPoint},
+    Cell, CellIndex, DASContext, Error, KZGProof,
+};
+
+/// The name of the suite in the path of every case, as used by the mainnet vectors.
+const SUITE: &str = "kzg-mainnet";
+
+/// The encoding of the files written by [`write_to_dir`].
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Format {
+    Yaml,
+    Json,
+}
+
+impl Format {
+    const fn file_name(self) -> &'static str {
+        match self {
+            Self::Yaml => "data.yaml",
+            Self::Json => "data.json",
+        }
+    }
+}
+
+/// A single test case, with the input and the output hex encoded like the spec vectors.
+#[derive(Debug, Clone, Serialize)]
+pub struct TestCase {
+    /// The spec function that the case tests, ie `verify_cell_kzg_proof_batch`.
+    #[serde(skip)]
+    pub handler: &'static str,
+    /// The name of the case directory, which includes the kind of case and the seed.
+    #[serde(skip)]
+    pub name: String,
+    pub input: Value,
+    /// `None` if the input is invalid and the function is expected to fail.
+    pub output: Option<Value>,
+}
+
+impl TestCase {
+    /// Returns the path of the case, relative to the root of the vectors.
+    pub fn path(&self, format: Format) -> PathBuf {
+        [self.handler, SUITE, self.name.as_str(), format.file_name()]
+            .iter()
+            .collect()
+    }
+
+    /// Encodes the case in `format`.
+    pub fn encode(&self, format: Format) -> String {
+        match format {
+            Format::Yaml => {
+                serde_yaml::to_string(self).expect("a json value can always be encoded")
+            }
+            Format::Json => {
+                serde_json::to_string_pretty(self).expect("a json value can always be encoded")
+            }
+        }
+    }
+}
+
+/// Generates the cases of every handler from `seed`, computing their outputs with `ctx`.
+pub fn generate(ctx: &DASContext, seed: u64) -> Vec<TestCase> {
+    let mut cases = Cases {
+        ctx,
+        seed,
+        cases: Vec::new(),
+    };
+
+    cases.blob_to_kzg_commitment();
+    cases.compute_kzg_proof();
+    cases.compute_blob_kzg_proof();
+    cases.verify_kzg_proof();
+    cases.verify_blob_kzg_proof();
+    cases.verify_blob_kzg_proof_batch();
+    cases.compute_cells();
+    cases.compute_cells_and_kzg_proofs();
+    cases.verify_cell_kzg_proof_batch();
+    cases.recover_cells_and_kzg_proofs();
+
+    cases.cases
+}
+
+/// Writes each case to its path below `dir`, creating the directories as needed.
+pub fn write_to_dir(cases: &[TestCase], dir: &Path, format: Format) -> io::Result<()> {
+    for case in cases {
+        let path = dir.join(case.path(format));
+        if let Some(parent) = path.parent() {
+            fs::create_dir_all(parent)?;
+        }
+        fs::write(path, case.encode(format))?;
+    }
+    Ok(())
+}
+
+/// The cases generated so far for a seed.
+struct Cases<'a> {
+    ctx: &'a DASContext,
+    seed: u64,
+    cases: Vec<TestCase>,
+}
+
+impl Cases<'_> {
+    fn push(&mut self, handler: &'static str, kind: &str, input: Value, output: Option<Value>) {
+        self.cases.push(TestCase {
+            handler,
+            name: format!("{handler}_case_{kind}_{:016x}", self.seed),
+            input,
+            output,
+        });
+    }
+
+    /// Returns an invalid commitment or proof, with the kind of invalidity chosen by the seed.
+    fn invalid_point(&self) -> [u8; 48] {
+        let kind = InvalidPoint::ALL[(self.seed % InvalidPoint::ALL.len() as u64) as usize];
+        generators::invalid_g1_point(kind, self.seed)
+    }
+
+    fn blob_to_kzg_commitment(&mut self) {
+        let blobs = [
+            ("valid_blob", generators::blob(self.seed)),
+            (
+                "invalid_blob",
+                generators::almost_valid_blob(self.seed, self.seed as usize),
+            ),
+        ];
+        for (kind, blob) in blobs {
+            let output = self.ctx.blob_to_kzg_commitment(&blob).ok();
+            self.push(
+                "blob_to_kzg_commitment",
+                kind,
+                json!({ "blob": hex(blob.as_slice()) }),
+                output.map(|commitment| hex(&commitment)),
+            );
+        }
+    }
+
+    fn compute_kzg_proof(&mut self) {
+        let blob = generators::blob(self.seed);
+        let z = generators::field_element(!self.seed);
+        let inputs = [
+            ("valid", blob.clone(), z),
+            (
+                "invalid_blob",
+                generators::almost_valid_blob(self.seed, self.seed as usize),
+                z,
+            ),
+            (
+                "invalid_z",
+                blob,
+                generators::almost_valid_field_element(self.seed),
+            ),
+        ];
+        for (kind, blob, z) in inputs {
+            let output = self.ctx.compute_kzg_proof(&blob, z).ok();
+            self.push(
+                "compute_kzg_proof",
+                kind,
+                json!({ "blob": hex(blob.as_slice()), "z": hex(&z) }),
+                output.map(|(proof, y)| json!([hex(&proof), hex(&y)])),
+            );
+        }
+    }
+
+    fn compute_blob_kzg_proof(&mut self) {
+        let blob = generators::blob(self.seed);
+        let commitment = self.commitment(&blob);
+        let inputs = [
+            ("valid", blob.clone(), commitment),
+            (
+                "invalid_blob",
+                generators::almost_valid_blob(self.seed, self.seed as usize),
+                commitment,
+            ),
+            ("invalid_commitment", blob, self.invalid_point()),
+        ];
+        for (kind, blob, commitment) in inputs {
+            let output = self.ctx.compute_blob_kzg_proof(&blob, &commitment).ok();
+            self.push(
+                "compute_blob_kzg_proof",
+                kind,
+                json!({ "blob": hex(blob.as_slice()), "commitment": hex(&commitment) }),
+                output.map(|proof| hex(&proof)),
+            );
+        }
+    }
+
+    fn verify_kzg_proof(&mut self) {
+        let blob = generators::blob(self.seed);
+        let commitment = self.commitment(&blob);
+        let z = generators::field_element(!self.seed);
+        let (proof, y) = self
+            .ctx
+            .compute_kzg_proof(&blob, z)
+            .expect("the blob and the point are valid");
+
+        let inputs = [
+            ("valid", commitment, y, proof),
+            (
+                "incorrect_proof",
+                commitment,
+                y,
+                generators::g1_point(self.seed),
+            ),
+            (
+                "invalid_y",
+                commitment,
+                generators::almost_valid_field_element(self.seed),
+                proof,
+            ),
+            ("invalid_proof", commitment, y, self.invalid_point()),
+        ];
+        for (kind, commitment, y, proof) in inputs {
+            let output = verified(self.ctx.verify_kzg_proof(&commitment, z, y, &proof));
+            self.push(
+                "verify_kzg_proof",
+                kind,
+                json!({
+                    "commitment": hex(&commitment),
+                    "z": hex(&z),
+                    "y": hex(&y),
+                    "proof": hex(&proof),
+                }),
+                output,
+            );
+        }
+    }
+
+    fn verify_blob_kzg_proof(&mut self) {
+        let blob = generators::blob(self.seed);
+        let commitment = self.commitment(&blob);
+        let proof = self
+            .ctx
+            .compute_blob_kzg_proof(&blob, &commitment)
+            .expect("the blob and the commitment are valid");
+
+        let inputs = [
+            ("valid", commitment, proof),
+            (
+                "incorrect_proof",
+                commitment,
+                generators::g1_point(self.seed),
+            ),
+            ("invalid_commitment", self.invalid_point(), proof),
+        ];
+        for (kind, commitment, proof) in inputs {
+            let output = verified(self.ctx.verify_blob_kzg_proof(&blob, &commitment, &proof));
+            self.push(
+                "verify_blob_kzg_proof",
+                kind,
+                json!({
+                    "blob": hex(blob.as_slice()),
+                    "commitment": hex(&commitment),
+                    "proof": hex(&proof),
+                }),
+                output,
+            );
+        }
+    }
+
+    fn verify_blob_kzg_proof_batch(&mut self) {
+        let blobs = [
+            generators::blob(self.seed),
+            generators::blob(self.seed.wrapping_add(1)),
+        ];
+        let commitments: Vec<_> = blobs.iter().map(|blob| self.commitment(blob)).collect();
+        let proofs: Vec<_> = blobs
+            .iter()
+            .zip(&commitments)
+            .map(|(blob, commitment)| {
+                self.ctx
+                    .compute_blob_kzg_proof(blob, commitment)
+                    .expect("the blob and the commitment are valid")
+            })
+            .collect();
+
+        let mut swapped_proofs = proofs.clone();
+        swapped_proofs.swap(0, 1);
+        let mut invalid_commitments = commitments.clone();
+        invalid_commitments[1] = self.invalid_point();
+
+        let inputs = [
+            ("valid", commitments.clone(), proofs.clone()),
+            ("incorrect_proof", commitments.clone(), swapped_proofs),
+            ("invalid_commitment", invalid_commitments, proofs.clone()),
+            ("invalid_length", commitments, proofs[..1].to_vec()),
+        ];
+        for (kind, commitments, proofs) in inputs {
+            let output = verified(self.ctx.verify_blob_kzg_proof_batch(
+                blobs.iter().map(AsRef::as_ref).collect(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            ));
+            self.push(
+                "verify_blob_kzg_proof_batch",
+                kind,
+                json!({
+                    "blobs": hex_all(blobs.iter().map(|blob| blob.as_slice())),
+                    "commitments": hex_all(commitments.iter().map(|c| c.as_slice())),
+                    "proofs": hex_all(proofs.iter().map(|proof| proof.as_slice())),
+                }),
+                output,
+            );
+        }
+    }
+
+    fn compute_cells(&mut self) {
+        let blobs = [
+            ("valid", generators::blob(self.seed)),
+            (
+                "invalid_blob",
+                generators::almost_valid_blob(self.seed, self.seed as usize),
+            ),
+        ];
+        for (kind, blob) in blobs {
+            let output = self.ctx.compute_cells(&blob).ok();
+            self.push(
+                "compute_cells",
+                kind,
+                json!({ "blob": hex(blob.as_slice()) }),
+                output.map(|cells| hex_all(cells.iter().map(|cell| cell.as_slice()))),
+            );
+        }
+    }
+
+    fn compute_cells_and_kzg_proofs(&mut self) {
+        let blobs = [
+            ("valid", generators::blob(self.seed)),
+            (
+                "invalid_blob",
+                generators::almost_valid_blob(self.seed, self.seed as usize),
+            ),
+        ];
+        for (kind, blob) in blobs {
+            let output = self.ctx.compute_cells_and_kzg_proofs(&blob).ok();
+            self.push(
+                "compute_cells_and_kzg_proofs",
+                kind,
+                json!({ "blob": hex(blob.as_slice()) }),
+                output.map(|(cells, proofs)| cells_and_proofs(&cells, &proofs)),
+            );
+        }
+    }
+
+    fn verify_cell_kzg_proof_batch(&mut self) {
+        let blob = generators::blob(self.seed);
+        let commitment = self.commitment(&blob);
+        let (cells, proofs) = self
+            .ctx
+            .compute_cells_and_kzg_proofs(&blob)
+            .expect("the blob is valid");
+
+        // Four cells spread over the extended blob, starting at an offset given by the seed.
+        let stride = CELLS_PER_EXT_BLOB / 4;
+        let offset = self.seed as usize % stride;
+        let cell_indices: Vec<CellIndex> =
+            (0..4).map(|i| (offset + i * stride) as CellIndex).collect();
+        let cells: Vec<Cell> = cell_indices
+            .iter()
+            .map(|&i| cells[i as usize].clone())
+            .collect();
+        let proofs: Vec<KZGProof> = cell_indices.iter().map(|&i| proofs[i as usize]).collect();
+
+        let mut swapped_proofs = proofs.clone();
+        swapped_proofs.swap(0, 1);
+        let mut invalid_cells = cells.clone();
+        invalid_cells[0] = generators::almost_valid_cell(self.seed, self.seed as usize);
+        let mut invalid_cell_indices = cell_indices.clone();
+        invalid_cell_indices[0] = CELLS_PER_EXT_BLOB as CellIndex;
+        let mut invalid_proofs = proofs.clone();
+        invalid_proofs[0] = self.invalid_point();
+
+        let inputs = [
+            ("valid", cell_indices.clone(), cells.clone(), proofs.clone()),
+            (
+                "incorrect_proof",
+                cell_indices.clone(),
+                cells.clone(),
+                swapped_proofs,
+            ),
+            (
+                "invalid_cell",
+                cell_indices.clone(),
+                invalid_cells,
+                proofs.clone(),
+            ),
+            (
+                "invalid_cell_index",
+                invalid_cell_indices,
+                cells.clone(),
+                proofs.clone(),
+            ),
+            ("invalid_proof", cell_indices, cells, invalid_proofs),
+        ];
+        for (kind, cell_indices, cells, proofs) in inputs {
+            let commitments = vec![commitment; cell_indices.len()];
+            let output = verified(self.ctx.verify_cell_kzg_proof_batch(
+                commitments.iter().collect(),
+                &cell_indices,
+                cells.iter().map(AsRef::as_ref).collect(),
+                proofs.iter().collect(),
+            ));
+            self.push(
+                "verify_cell_kzg_proof_batch",
+                kind,
+                json!({
+                    "commitments": hex_all(commitments.iter().map(|c| c.as_slice())),
+                    "cell_indices": cell_indices,
+                    "cells": hex_all(cells.iter().map(|cell| cell.as_slice())),
+                    "proofs": hex_all(proofs.iter().map(|proof| proof.as_slice())),
+                }),
+                output,
+            );
+        }
+    }
+
+    fn recover_cells_and_kzg_proofs(&mut self) {
+        let blob = generators::blob(self.seed);
+        let (cells, _) = self
+            .ctx
+            .compute_cells_and_kzg_proofs(&blob)
+            .expect("the blob is valid");
+
+        // Every other cell, which is the least that recovery needs.
+        let parity = self.seed as usize % 2;
+        let cell_indices: Vec<CellIndex> = (0..CELLS_PER_EXT_BLOB)
+            .filter(|i| i % 2 == parity)
+            .map(|i| i as CellIndex)
+            .collect();
+        let cells: Vec<Cell> = cell_indices
+            .iter()
+            .map(|&i| cells[i as usize].clone())
+            .collect();
+
+        let mut duplicate_cell_indices = cell_indices.clone();
+        duplicate_cell_indices[1] = duplicate_cell_indices[0];
+        let mut invalid_cells = cells.clone();
+        invalid_cells[0] = generators::almost_valid_cell(self.seed, self.seed as usize);
+
+        let inputs = [
+            ("valid_half_missing", cell_indices.clone(), cells.clone()),
+            (
+                "invalid_duplicate_cell_index",
+                duplicate_cell_indices,
+                cells.clone(),
+            ),
+            ("invalid_cell", cell_indices.clone(), invalid_cells),
+            (
+                "invalid_not_enough_cells",
+                cell_indices[1..].to_vec(),
+                cells[1..].to_vec(),
+            ),
+        ];
+        for (kind, cell_indices, cells) in inputs {
+            let output = self
+                .ctx
+                .recover_cells_and_kzg_proofs(
+                    cell_indices.clone(),
+                    cells.iter().map(AsRef::as_ref).collect(),
+                )
+                .ok();
+            self.push(
+                "recover_cells_and_kzg_proofs",
+                kind,
+                json!({
+                    "cell_indices": cell_indices,
+                    "cells": hex_all(cells.iter().map(|cell| cell.as_slice())),
+                }),
+                output.map(|(cells, proofs)| cells_and_proofs(&cells, &proofs)),
+            );
+        }
+    }
+
+    fn commitment(&self, blob: &[u8; BYTES_PER_BLOB]) -> [u8; 48] {
+        self.ctx
+            .blob_to_kzg_commitment(blob)
+            .expect("generated blobs are valid")
+    }
+}
+
+/// Maps the result of a verification to the expected output, ie `null` for invalid inputs.
+fn verified(result: Result<(), Error>) -> Option<Value> {
+    match result {
+        Ok(()) => Some(Value::Bool(true)),
+        Err(err) if err.is_proof_invalid() => Some(Value::Bool(false)),
+        Err(_) => None,
+    }
+}
+
+fn cells_and_proofs(cells: &[Cell], proofs: &[KZGProof]) -> Value {
+    json!([
+        hex_all(cells.iter().map(|cell| cell.as_slice())),
+        hex_all(proofs.iter().map(|proof| proof.as_slice())),
+    ])
+}
+
+/// Encodes `bytes` as a `0x` prefixed hex string.
+fn hex(bytes: &[u8]) -> Value {
+    Value::String(format!("0x{}", hex::encode(bytes)))
+}
+
+fn hex_all<'a>(items: impl Iterator<Item = &'a [u8]>) -> Value {
+    Value::Array(items.map(hex).collect())
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use std::collections::HashSet;
+
+    use super::*;
+
+    #[test]
+    fn outputs_match_the_kind_of_case() {
+        let ctx = DASContext::default();
+        let cases = generate(&ctx, 3);
+
+        let names: HashSet<_> = cases.iter().map(|case| &case.name).collect();
+        assert_eq!(names.len(), cases.len(), "case names must be unique");
+
+        for case in &cases {
+            if case.name.contains("_case_valid") {
+                assert!(case.output.is_some(), "{} has no output", case.name);
+                assert_ne!(case.output, Some(Value::Bool(false)), "{}", case.name);
+            } else if case.name.contains("_case_incorrect") {
+                assert_eq!(case.output, Some(Value::Bool(false)), "{}", case.name);
+            } else {
+                assert_eq!(case.output, None, "{} has an output", case.name);
+            }
+        }
+    }
+
+    #[test]
+    fn generation_is_deterministic() {
+        let ctx = DASContext::default();
+        let first = generate(&ctx, 5);
+        let second = generate(&ctx, 5);
+        for (a, b) in first.iter().zip(&second) {
+            assert_eq!(a.encode(Format::Json), b.encode(Format::Json));
+        }
+
+        let yaml = first[0].encode(Format::Yaml);
+        assert!(yaml.starts_with("input:"));
+        assert_eq!(
+            first[0].path(Format::Yaml),
+            Path::new("blob_to_kzg_commitment/kzg-mainnet")
+                .join(&first[0].name)
+                .join("data.yaml")
+        );
+    }
+}
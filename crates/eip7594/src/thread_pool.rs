@@ -0,0 +1,144 @@
+/// The thread pool that a context runs its parallel work on.
+///
+/// By default, work runs on the global rayon thread pool, which spawns one thread per
+/// CPU. Applications that manage their own threads can instead provide a pool with
+/// `DASContext::with_thread_pool` or cap the number of threads with
+/// `DASContext::with_num_threads`, or with the equivalent methods of `DASContextBuilder`.
+/// With the `numa` feature, the work can instead be spread over one pool per NUMA node.
+#[derive(Debug, Clone, Default)]
+pub(crate) struct ThreadPool {
+    #[cfg(feature = "multithreaded")]
+    pool: Option<std::sync::Arc<rayon::ThreadPool>>,
+    #[cfg(feature = "numa")]
+    numa: Option<std::sync::Arc<crate::numa::NumaPools>>,
+}
+
+impl ThreadPool {
+    #[cfg(feature = "multithreaded")]
+    pub(crate) const fn new(pool: std::sync::Arc<rayon::ThreadPool>) -> Self {
+        Self {
+            pool: Some(pool),
+            #[cfg(feature = "numa")]
+            numa: None,
+        }
+    }
+
+    /// Returns a thread pool that runs each call on one of the NUMA nodes of `pools`.
+    #[cfg(feature = "numa")]
+    pub(crate) const fn numa(pools: std::sync::Arc<crate::numa::NumaPools>) -> Self {
+        Self {
+            pool: None,
+            numa: Some(pools),
+        }
+    }
+
+    /// Returns the pools of the NUMA nodes, if this runs work on them.
+    #[cfg(feature = "numa")]
+    pub(crate) fn numa_pools(&self) -> Option<&crate::numa::NumaPools> {
+        self.numa.as_deref()
+    }
+
+    /// Returns a dedicated thread pool with `num_threads` threads, or one per CPU if it is 0.
+    #[cfg(feature = "multithreaded")]
+    pub(crate) fn with_num_threads(
+        num_threads: usize,
+    ) -> Result<Self, rayon::ThreadPoolBuildError> {
+        let pool = rayon::ThreadPoolBuilder::new()
+            .num_threads(num_threads)
+            .thread_name(|index| format!("eth-kzg-{index}"))
+            .build()?;
+        Ok(Self::new(std::sync::Arc::new(pool)))
+    }
+
+    /// Returns a dedicated thread pool like [`ThreadPool::with_num_threads`], whose threads
+    /// split field element FFTs across threads from `parallel_fft_threshold` elements.
+    ///
+    /// The threshold is set on each thread of the pool as it starts, so it applies to every
+    /// FFT run by `install`, without changing the threshold of any other thread.
+    #[cfg(feature = "multithreaded")]
+    pub(crate) fn with_parallel_fft_threshold(
+        num_threads: usize,
+        parallel_fft_threshold: usize,
+    ) -> Result<Self, rayon::ThreadPoolBuildError> {
+        let pool = rayon::ThreadPoolBuilder::new()
+            .num_threads(num_threads)
+            .thread_name(|index| format!("eth-kzg-{index}"))
+            .start_handler(move |_| {
+                polynomial::set_thread_parallel_fft_threshold(Some(parallel_fft_threshold));
+            })
+            .build()?;
+        Ok(Self::new(std::sync::Arc::new(pool)))
+    }
+
+    /// Returns a thread pool with a single thread, so that parallel work is always
+    /// scheduled in the same order.
+    ///
+    /// Without the `multithreaded` feature, work already runs on the calling thread.
+    pub(crate) fn single_threaded() -> Self {
+        #[cfg(feature = "multithreaded")]
+        {
+            let pool = rayon::ThreadPoolBuilder::new()
+                .num_threads(1)
+                .thread_name(|_| "eth-kzg-deterministic".to_owned())
+                .build()
+                .expect("failed to spawn the thread of a single-threaded pool");
+            Self::new(std::sync::Arc::new(pool))
+        }
+        #[cfg(not(feature = "multithreaded"))]
+        Self::default()
+    }
+
+    /// Runs `op` on the thread pool, returning its result.
+    ///
+    /// Parallel iterators used by `op` are run on this pool rather than on the global one.
+    pub(crate) fn install<R: Send>(&self, op: impl FnOnce() -> R + Send) -> R {
+        #[cfg(feature = "numa")]
+        if let Some(numa) = &self.numa {
+            return numa.install(op);
+        }
+        #[cfg(feature = "multithreaded")]
+        if let Some(pool) = &self.pool {
+            return pool.install(op);
+        }
+        op()
+    }
+}
+
+#[cfg(all(test, feature = "multithreaded"))]
+mod tests {
+    use std::sync::Arc;
+
+    use super::ThreadPool;
+
+    #[test]
+    fn install_runs_on_the_given_pool() {
+        let pool = rayon::ThreadPoolBuilder::new()
+            .num_threads(1)
+            .build()
+            .unwrap();
+        let thread_pool = ThreadPool::new(Arc::new(pool));
+        assert_eq!(thread_pool.install(rayon::current_num_threads), 1);
+    }
+
+    #[test]
+    fn single_threaded_has_one_thread() {
+        let thread_pool = ThreadPool::single_threaded();
+        assert_eq!(thread_pool.install(rayon::current_num_threads), 1);
+    }
+
+    #[test]
+    fn parallel_fft_threshold_is_set_on_the_threads_of_the_pool() {
+        let thread_pool = ThreadPool::with_parallel_fft_threshold(2, 1 << 6).unwrap();
+        assert_eq!(thread_pool.install(polynomial::parallel_fft_threshold), 1 << 6);
+        assert_ne!(polynomial::parallel_fft_threshold(), 1 << 6);
+    }
+
+    #[test]
+    fn default_runs_on_the_global_pool() {
+        let thread_pool = ThreadPool::default();
+        assert_eq!(
+            thread_pool.install(rayon::current_num_threads),
+            rayon::current_num_threads()
+        );
+    }
+}
@@ -1,18 +1,42 @@
 use kzg_multi_open::{commit_key::CommitKey, verification_key::VerificationKey};
-pub use trusted_setup::TrustedSetup;
+pub use trusted_setup::{LazyTrustedSetup, TrustedSetup, TrustedSetupError, TRUSTED_SETUP_ENV_VAR};
 
 use crate::constants::{FIELD_ELEMENTS_PER_BLOB, FIELD_ELEMENTS_PER_CELL};
 
+/// Creates the commit key from the first `FIELD_ELEMENTS_PER_BLOB` G1 points of the setup.
+///
+/// Larger setups are accepted, which allows setups with more points than a blob, such as
+/// the mainnet setup when the reduced `testing` parameters are used.
+///
+/// # Panics
+/// Panics if the setup has fewer than `FIELD_ELEMENTS_PER_BLOB` G1 points.
 pub fn commit_key_from_setup(setup: &TrustedSetup) -> CommitKey {
-    CommitKey::new(setup.g1_monomial.clone())
+    assert!(
+        setup.g1_monomial.len() >= FIELD_ELEMENTS_PER_BLOB,
+        "trusted setup has {} G1 points, but at least {FIELD_ELEMENTS_PER_BLOB} are needed",
+        setup.g1_monomial.len()
+    );
+    CommitKey::new(setup.g1_monomial[..FIELD_ELEMENTS_PER_BLOB].to_vec())
 }
 
+/// Creates the verification key from the first `FIELD_ELEMENTS_PER_CELL + 1` G1 and G2 points of the setup.
+///
+/// # Panics
+/// Panics if the setup has fewer than `FIELD_ELEMENTS_PER_CELL + 1` G1 or G2 points.
 pub fn verification_key_from_setup(setup: &TrustedSetup) -> VerificationKey {
-    let g2_points = setup.g2_monomial.clone();
-    let num_g2_points = g2_points.len();
+    // The verifier commits to the interpolation polynomial of a cell in G1 and to
+    // the vanishing polynomial of a coset in G2, which has one more coefficient.
+    let num_points = FIELD_ELEMENTS_PER_CELL + 1;
+    assert!(
+        setup.g1_monomial.len() >= num_points && setup.g2_monomial.len() >= num_points,
+        "trusted setup has {} G1 points and {} G2 points, but at least {num_points} of each are needed",
+        setup.g1_monomial.len(),
+        setup.g2_monomial.len()
+    );
+    let g2_points = setup.g2_monomial[..num_points].to_vec();
     // The setup needs as many g1 elements for the verification key as g2 elements, in order
     // to commit to the remainder/interpolation polynomial.
-    let g1_points = setup.g1_monomial[..num_g2_points].to_vec();
+    let g1_points = setup.g1_monomial[..num_points].to_vec();
 
     VerificationKey::new(
         g1_points,
@@ -0,0 +1,231 @@
+//! A bounded cache of the cell openings that a context has verified, see
+//! [`DASContext::with_verification_cache`](crate::DASContext::with_verification_cache).
+
+use std::{
+    collections::{HashSet, VecDeque},
+    sync::{
+        atomic::{AtomicU64, Ordering},
+        Mutex,
+    },
+};
+
+use sha2::{Digest, Sha256};
+
+use crate::{Bytes48Ref, CellIndex, CellRef};
+
+/// Identifies an opening by the hash of its commitment, cell index, cell and proof.
+///
+/// The cell is part of the key, since an opening with the same commitment, index and proof
+/// but another cell does not verify.
+type OpeningKey = [u8; 32];
+
+/// The hits and misses of the verification cache of a context, since it was enabled.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub struct VerificationCacheStats {
+    /// The number of openings that were found in the cache, and so were not verified again.
+    pub hits: u64,
+    /// The number of openings that were not in the cache.
+    pub misses: u64,
+    /// The number of openings in the cache.
+    pub len: usize,
+    /// The maximum number of openings in the cache.
+    pub capacity: usize,
+}
+
+impl VerificationCacheStats {
+    /// Returns the share of the openings that were found in the cache, between 0 and 1.
+    pub fn hit_rate(&self) -> f64 {
+        let lookups = self.hits + self.misses;
+        if lookups == 0 {
+            return 0.0;
+        }
+        self.hits as f64 / lookups as f64
+    }
+}
+
+/// The openings of a batch that are not in the cache.
+pub(crate) struct Uncached<'a> {
+    pub(crate) commitments: Vec<Bytes48Ref<'a>>,
+    pub(crate) cell_indices: Vec<CellIndex>,
+    pub(crate) cells: Vec<CellRef<'a>>,
+    pub(crate) proofs: Vec<Bytes48Ref<'a>>,
+    /// The keys of the openings, to insert them once they have been verified.
+    pub(crate) keys: Vec<OpeningKey>,
+}
+
+#[derive(Debug, Default)]
+struct Entries {
+    keys: HashSet<OpeningKey>,
+    /// The keys in the order in which they were inserted, so that the oldest are evicted first.
+    order: VecDeque<OpeningKey>,
+}
+
+#[derive(Debug)]
+pub(crate) struct VerificationCache {
+    capacity: usize,
+    entries: Mutex<Entries>,
+    hits: AtomicU64,
+    misses: AtomicU64,
+}
+
+impl VerificationCache {
+    pub(crate) fn new(capacity: usize) -> Self {
+        Self {
+            capacity,
+            entries: Mutex::default(),
+            hits: AtomicU64::new(0),
+            misses: AtomicU64::new(0),
+        }
+    }
+
+    fn key(
+        commitment: Bytes48Ref,
+        cell_index: CellIndex,
+        cell: CellRef,
+        proof: Bytes48Ref,
+    ) -> OpeningKey {
+        Sha256::new()
+            .chain_update(commitment)
+            .chain_update(cell_index.to_le_bytes())
+            .chain_update(cell)
+            .chain_update(proof)
+            .finalize()
+            .into()
+    }
+
+    /// Returns the openings of a batch that are not in the cache.
+    ///
+    /// The inputs must have the same length.
+    pub(crate) fn uncached<'a>(
+        &self,
+        commitments: &[Bytes48Ref<'a>],
+        cell_indices: &[CellIndex],
+        cells: &[CellRef<'a>],
+        proofs: &[Bytes48Ref<'a>],
+    ) -> Uncached<'a> {
+        let mut uncached = Uncached {
+            commitments: Vec::new(),
+            cell_indices: Vec::new(),
+            cells: Vec::new(),
+            proofs: Vec::new(),
+            keys: Vec::new(),
+        };
+
+        let entries = self.entries.lock().expect("not poisoned");
+        for (((&commitment, &cell_index), &cell), &proof) in
+            commitments.iter().zip(cell_indices).zip(cells).zip(proofs)
+        {
+            let key = Self::key(commitment, cell_index, cell, proof);
+            if entries.keys.contains(&key) {
+                continue;
+            }
+            uncached.commitments.push(commitment);
+            uncached.cell_indices.push(cell_index);
+            uncached.cells.push(cell);
+            uncached.proofs.push(proof);
+            uncached.keys.push(key);
+        }
+        drop(entries);
+
+        let misses = uncached.keys.len();
+        self.hits
+            .fetch_add((cells.len() - misses) as u64, Ordering::Relaxed);
+        self.misses.fetch_add(misses as u64, Ordering::Relaxed);
+        uncached
+    }
+
+    /// Inserts openings that were verified, evicting the oldest openings if the cache is full.
+    pub(crate) fn insert(&self, keys: Vec<OpeningKey>) {
+        let mut entries = self.entries.lock().expect("not poisoned");
+        for key in keys {
+            if entries.keys.insert(key) {
+                entries.order.push_back(key);
+            }
+        }
+        while entries.order.len() > self.capacity {
+            let oldest = entries.order.pop_front().expect("the cache is not empty");
+            entries.keys.remove(&oldest);
+        }
+    }
+
+    pub(crate) fn stats(&self) -> VerificationCacheStats {
+        VerificationCacheStats {
+            hits: self.hits.load(Ordering::Relaxed),
+            misses: self.misses.load(Ordering::Relaxed),
+            len: self.entries.lock().expect("not poisoned").order.len(),
+            capacity: self.capacity,
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::VerificationCache;
+    use crate::constants::BYTES_PER_CELL;
+
+    #[test]
+    fn oldest_openings_are_evicted() {
+        let cache = VerificationCache::new(2);
+        let cells: Vec<_> = (0..3u8).map(|i| [i; BYTES_PER_CELL]).collect();
+        let commitment = [1u8; 48];
+        let proof = [2u8; 48];
+        let batch = |indices: &[usize]| {
+            cache.uncached(
+                &vec![&commitment; indices.len()],
+                &vec![0; indices.len()],
+                &indices.iter().map(|&i| &cells[i]).collect::<Vec<_>>(),
+                &vec![&proof; indices.len()],
+            )
+        };
+
+        let uncached = batch(&[0, 1]);
+        assert_eq!(uncached.keys.len(), 2);
+        cache.insert(uncached.keys);
+        assert!(batch(&[0, 1]).keys.is_empty());
+
+        // Another cell with the same commitment, index and proof is not cached
+        let uncached = batch(&[2]);
+        assert_eq!(uncached.cells, vec![&cells[2]]);
+        cache.insert(uncached.keys);
+        assert_eq!(batch(&[0, 1, 2]).cells, vec![&cells[0]]);
+
+        let stats = cache.stats();
+        assert_eq!((stats.hits, stats.misses), (4, 4));
+        assert_eq!((stats.len, stats.capacity), (2, 2));
+        assert!((stats.hit_rate() - 0.5).abs() < f64::EPSILON);
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn repeated_openings_are_not_verified_again() {
+        use crate::{generators, DASContext};
+
+        let ctx = DASContext::default();
+        let blob = generators::blob(0);
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+        let cached = ctx.clone().with_verification_cache(16);
+        let verify = |indices: &[u64], proofs: Vec<&[u8; 48]>| {
+            cached.verify_cell_kzg_proof_batch(
+                vec![&commitment; indices.len()],
+                indices,
+                indices.iter().map(|&i| &*cells[i as usize]).collect(),
+                proofs,
+            )
+        };
+
+        verify(&[0, 1], vec![&proofs[0], &proofs[1]]).unwrap();
+        verify(&[0, 1], vec![&proofs[0], &proofs[1]]).unwrap();
+        // A cached opening does not hide an opening that does not verify
+        let err = verify(&[0, 2], vec![&proofs[0], &proofs[3]]).unwrap_err();
+        assert!(err.is_proof_invalid());
+
+        let stats = cached.verification_cache_stats().unwrap();
+        assert_eq!((stats.hits, stats.misses), (3, 3));
+        assert_eq!(stats.len, 2);
+        assert_eq!(ctx.verification_cache_stats(), None);
+
+        // The lengths are still checked when every opening is cached
+        assert!(verify(&[0, 1], vec![&proofs[0]]).is_err());
+    }
+}
@@ -1,14 +1,19 @@
 use std::collections::HashMap;
 
+use bls12_381::G2Point;
+#[cfg(feature = "paranoid-checks")]
+use kzg_multi_open::verification_key::VerificationKey;
 use kzg_multi_open::Verifier;
-use serialization::{deserialize_cells, deserialize_compressed_g1_points};
+use serialization::{
+    deserialize_cells, deserialize_compressed_g1, deserialize_compressed_g1_points,
+};
 
 pub use crate::errors::VerifierError;
 use crate::{
     constants::{CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_EXT_BLOB},
     errors::Error,
     trusted_setup::{verification_key_from_setup, TrustedSetup},
-    Bytes48Ref, CellIndex, CellRef, DASContext,
+    Bytes48Ref, CellIndex, CellRef, DASContext, Operation,
 };
 
 /// The context object that is used to call functions in the verifier API.
@@ -17,6 +22,7 @@ pub struct VerifierContext {
     kzg_multipoint_verifier: Verifier,
 }
 
+#[cfg(not(feature = "no-embedded-setup"))]
 impl Default for VerifierContext {
     fn default() -> Self {
         let trusted_setup = TrustedSetup::default();
@@ -25,6 +31,7 @@ impl Default for VerifierContext {
 }
 
 impl VerifierContext {
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     pub fn new(trusted_setup: &TrustedSetup) -> Self {
         let verification_key = verification_key_from_setup(trusted_setup);
 
@@ -38,6 +45,30 @@ impl VerifierContext {
             kzg_multipoint_verifier: multipoint_verifier,
         }
     }
+
+    /// Returns the G2 points of the trusted setup in monomial form, ie `[tau^i]_2`.
+    ///
+    /// Only the first `FIELD_ELEMENTS_PER_CELL + 1` points are kept by the verifier.
+    pub fn g2_monomial_points(&self) -> &[G2Point] {
+        &self.kzg_multipoint_verifier.verification_key.g2s
+    }
+
+    /// Returns the verification key, for the reference verifier used by the paranoid checks.
+    #[cfg(feature = "paranoid-checks")]
+    pub(crate) const fn verification_key(&self) -> &VerificationKey {
+        &self.kzg_multipoint_verifier.verification_key
+    }
+
+    /// Returns the number of bytes used by the verification key.
+    pub(crate) fn srs_size_in_bytes(&self) -> usize {
+        self.kzg_multipoint_verifier
+            .verification_key_size_in_bytes()
+    }
+
+    /// Returns the number of bytes used by the coset domain.
+    pub(crate) fn domains_size_in_bytes(&self) -> usize {
+        self.kzg_multipoint_verifier.domains_size_in_bytes()
+    }
 }
 
 /// Deduplicates a vector and creates a mapping of original indices to deduplicated indices.
@@ -64,6 +95,65 @@ fn deduplicate_with_indices<T: Eq + std::hash::Hash + Clone>(input: Vec<T>) -> (
     (unique, indices)
 }
 
+/// The openings of a batch that are left once repeated openings have been removed.
+struct UniqueOpenings<'a> {
+    commitment_indices: Vec<u64>,
+    cell_indices: Vec<CellIndex>,
+    cells: Vec<CellRef<'a>>,
+    proofs: Vec<Bytes48Ref<'a>>,
+    /// Whether two openings of the same cell of the same commitment had different cells or
+    /// proofs.
+    conflicting: bool,
+}
+
+/// Removes the openings that repeat an earlier opening of the same cell of the same commitment.
+///
+/// The cell and proof of an opening are determined by the commitment and the cell index, so an
+/// opening that repeats an earlier one with the same cell and proof adds nothing to the batch.
+/// If the cell or proof differs, at most one of the two can be valid, so the batch must fail.
+/// Such openings are kept, so that they are still deserialized, and the batch fails with the
+/// same error as when the proofs are checked.
+///
+/// This happens when a node verifies the sidecars that it received from several peers
+/// together, and it shrinks the sums over the openings in the batch verification.
+fn deduplicate_openings<'a>(
+    commitment_indices: &[u64],
+    cell_indices: &[CellIndex],
+    cells: Vec<CellRef<'a>>,
+    proofs: Vec<Bytes48Ref<'a>>,
+) -> UniqueOpenings<'a> {
+    let mut unique = UniqueOpenings {
+        commitment_indices: Vec::with_capacity(cells.len()),
+        cell_indices: Vec::with_capacity(cells.len()),
+        cells: Vec::with_capacity(cells.len()),
+        proofs: Vec::with_capacity(cells.len()),
+        conflicting: false,
+    };
+    let mut first_opening = HashMap::with_capacity(cells.len());
+
+    for (((&commitment_index, &cell_index), cell), proof) in commitment_indices
+        .iter()
+        .zip(cell_indices)
+        .zip(cells)
+        .zip(proofs)
+    {
+        if let Some(&position) = first_opening.get(&(commitment_index, cell_index)) {
+            if unique.cells[position] == cell && unique.proofs[position] == proof {
+                continue;
+            }
+            unique.conflicting = true;
+        } else {
+            first_opening.insert((commitment_index, cell_index), unique.cells.len());
+        }
+        unique.commitment_indices.push(commitment_index);
+        unique.cell_indices.push(cell_index);
+        unique.cells.push(cell);
+        unique.proofs.push(proof);
+    }
+
+    unique
+}
+
 impl DASContext {
     /// Given a collection of commitments, cells and proofs, this functions verifies that
     /// the cells are consistent with the commitments using their respective KZG proofs.
@@ -75,6 +165,43 @@ impl DASContext {
         cell_indices: &[CellIndex],
         cells: Vec<CellRef>,
         proofs_bytes: Vec<Bytes48Ref>,
+    ) -> Result<(), Error> {
+        self.run(Operation::VerifyCellKzgProofBatch, cells.len(), || {
+            #[cfg(feature = "tracing")]
+            let _span = tracing::info_span!("verify_cell_kzg_proof_batch", num_cells = cells.len())
+                .entered();
+
+            // Openings that were already verified are skipped. When the lengths differ, the
+            // batch is verified as a whole so that the error is the same as without a cache.
+            let same_length = cell_indices.len() == commitments.len()
+                && cells.len() == commitments.len()
+                && proofs_bytes.len() == commitments.len();
+            let Some(cache) = self.verification_cache.as_deref().filter(|_| same_length) else {
+                return self.verify_openings(commitments, cell_indices, cells, proofs_bytes);
+            };
+
+            let uncached = cache.uncached(&commitments, cell_indices, &cells, &proofs_bytes);
+            if uncached.keys.is_empty() {
+                return Ok(());
+            }
+            self.verify_openings(
+                uncached.commitments,
+                &uncached.cell_indices,
+                uncached.cells,
+                uncached.proofs,
+            )?;
+            cache.insert(uncached.keys);
+            Ok(())
+        })
+    }
+
+    /// Verifies a batch of openings, without looking them up in the verification cache.
+    fn verify_openings(
+        &self,
+        commitments: Vec<Bytes48Ref>,
+        cell_indices: &[CellIndex],
+        cells: Vec<CellRef>,
+        proofs_bytes: Vec<Bytes48Ref>,
     ) -> Result<(), Error> {
         let (deduplicated_commitments, row_indices) = deduplicate_with_indices(commitments);
 
@@ -92,18 +219,24 @@ impl DASContext {
             return Ok(());
         }
 
+        let openings = deduplicate_openings(&row_indices, cell_indices, cells, proofs_bytes);
+
         // Deserialization
         let row_commitments_ = deserialize_compressed_g1_points(deduplicated_commitments)?;
-        let proofs_ = deserialize_compressed_g1_points(proofs_bytes)?;
-        let coset_evals = deserialize_cells(cells)?;
+        let proofs_ = deserialize_compressed_g1_points(openings.proofs)?;
+        let coset_evals = deserialize_cells(openings.cells)?;
+
+        if openings.conflicting {
+            return Err(VerifierError::FK20(kzg_multi_open::VerifierError::InvalidProof).into());
+        }
 
         // Computation
         self.verifier_ctx
             .kzg_multipoint_verifier
             .verify_multi_opening(
                 &row_commitments_,
-                &row_indices,
-                cell_indices,
+                &openings.commitment_indices,
+                &openings.cell_indices,
                 &coset_evals,
                 &proofs_,
             )
@@ -112,6 +245,47 @@ impl DASContext {
     }
 }
 
+/// Checks the structure of the inputs of [`DASContext::verify_cell_kzg_proof_batch`],
+/// without checking the proofs.
+///
+/// This checks the lengths and the cell indices, that the cells are made of canonical field
+/// elements, and that the commitments and proofs decompress to points of the G1 subgroup. It
+/// does not need the trusted setup and does no pairing, so it is much cheaper than the batch
+/// verification: a gossip layer can reject garbage as soon as it is received, and defer the
+/// pairing check to a later batch.
+///
+/// If this succeeds, [`DASContext::verify_cell_kzg_proof_batch`] will not reject the inputs as
+/// malformed: it fails either because a proof is invalid, ie with an error for which
+/// [`Error::is_proof_invalid`] is true, or without checking the proofs, with an
+/// [`Error::Admission`] error, whose code is [`crate::ErrorCode::Overloaded`] or
+/// [`crate::ErrorCode::AdmissionTimedOut`], if the context has admission limits and is busy.
+pub fn prevalidate_cell_kzg_proof_batch(
+    commitments: &[Bytes48Ref],
+    cell_indices: &[CellIndex],
+    cells: &[CellRef],
+    proofs_bytes: &[Bytes48Ref],
+) -> Result<(), Error> {
+    let (deduplicated_commitments, row_indices) = deduplicate_with_indices(commitments.to_vec());
+
+    validation::verify_cell_kzg_proof_batch(
+        &deduplicated_commitments,
+        &row_indices,
+        cell_indices,
+        cells,
+        proofs_bytes,
+    )?;
+
+    for point in deduplicated_commitments
+        .into_iter()
+        .chain(proofs_bytes.iter().copied())
+    {
+        deserialize_compressed_g1(point)?;
+    }
+    deserialize_cells(cells.to_vec())?;
+
+    Ok(())
+}
+
 mod validation {
     use kzg_multi_open::CommitmentIndex;
 
@@ -180,3 +354,128 @@ mod validation {
         }
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::deduplicate_openings;
+    use crate::constants::BYTES_PER_CELL;
+    #[cfg(not(feature = "no-embedded-setup"))]
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT},
+        generators, prevalidate_cell_kzg_proof_batch, DASContext, Scalar,
+    };
+
+    #[test]
+    fn test_deduplicate_openings() {
+        let cell_a = [1u8; BYTES_PER_CELL];
+        let cell_b = [2u8; BYTES_PER_CELL];
+        let proof_a = [3u8; 48];
+        let proof_b = [4u8; 48];
+
+        let openings = deduplicate_openings(
+            &[0, 1, 0, 0],
+            &[5, 5, 5, 6],
+            vec![&cell_a, &cell_a, &cell_a, &cell_b],
+            vec![&proof_a, &proof_a, &proof_a, &proof_b],
+        );
+        assert_eq!(openings.commitment_indices, vec![0, 1, 0]);
+        assert_eq!(openings.cell_indices, vec![5, 5, 6]);
+        assert_eq!(openings.proofs, vec![&proof_a, &proof_a, &proof_b]);
+        assert!(!openings.conflicting);
+
+        // The same cell of the same commitment with a different proof
+        let openings = deduplicate_openings(
+            &[0, 0],
+            &[5, 5],
+            vec![&cell_a, &cell_a],
+            vec![&proof_a, &proof_b],
+        );
+        assert_eq!(openings.cell_indices, vec![5, 5]);
+        assert!(openings.conflicting);
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn repeated_openings_in_a_batch() {
+        let ctx = DASContext::default();
+        let mut blob = [0u8; BYTES_PER_BLOB];
+        for (i, chunk) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+            chunk.copy_from_slice(&Scalar::from(i as u64 + 1).to_bytes_be());
+        }
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+
+        // The same openings, as received from two peers
+        ctx.verify_cell_kzg_proof_batch(
+            vec![&commitment; 4],
+            &[0, 1, 0, 1],
+            vec![&cells[0], &cells[1], &cells[0], &cells[1]],
+            vec![&proofs[0], &proofs[1], &proofs[0], &proofs[1]],
+        )
+        .unwrap();
+
+        // A peer sent the proof of another cell
+        let err = ctx
+            .verify_cell_kzg_proof_batch(
+                vec![&commitment; 2],
+                &[0, 0],
+                vec![&cells[0], &cells[0]],
+                vec![&proofs[0], &proofs[1]],
+            )
+            .unwrap_err();
+        assert!(err.is_proof_invalid());
+    }
+
+    #[cfg(not(feature = "no-embedded-setup"))]
+    #[test]
+    fn prevalidation_rejects_malformed_inputs() {
+        let ctx = DASContext::default();
+        let blob = generators::blob(3);
+        let commitment = ctx.blob_to_kzg_commitment(&blob).unwrap();
+        let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).unwrap();
+
+        prevalidate_cell_kzg_proof_batch(
+            &[&commitment, &commitment],
+            &[0, 1],
+            &[&cells[0], &cells[1]],
+            &[&proofs[0], &proofs[1]],
+        )
+        .unwrap();
+
+        // Swapped proofs are well formed, only the pairing check rejects them
+        prevalidate_cell_kzg_proof_batch(
+            &[&commitment, &commitment],
+            &[0, 1],
+            &[&cells[0], &cells[1]],
+            &[&proofs[1], &proofs[0]],
+        )
+        .unwrap();
+
+        let not_a_point = [0xffu8; 48];
+        let err = prevalidate_cell_kzg_proof_batch(
+            &[&commitment],
+            &[0],
+            &[&cells[0]],
+            &[&not_a_point],
+        )
+        .unwrap_err();
+        assert!(!err.is_proof_invalid());
+
+        let non_canonical_cell = [0xffu8; BYTES_PER_CELL];
+        assert!(prevalidate_cell_kzg_proof_batch(
+            &[&commitment],
+            &[0],
+            &[&non_canonical_cell],
+            &[&proofs[0]],
+        )
+        .is_err());
+        assert!(prevalidate_cell_kzg_proof_batch(
+            &[&commitment],
+            &[u64::MAX],
+            &[&cells[0]],
+            &[&proofs[0]],
+        )
+        .is_err());
+        assert!(prevalidate_cell_kzg_proof_batch(&[&commitment], &[0], &[], &[]).is_err());
+    }
+}
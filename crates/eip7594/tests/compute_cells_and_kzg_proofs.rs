@@ -1,3 +1,6 @@
+// The consensus spec test vectors are only valid for the mainnet parameters.
+#![cfg(not(feature = "testing"))]
+
 use std::fs;
 
 use common::collect_test_files;
@@ -114,6 +117,24 @@ fn test_compute_cells_and_kzg_proofs() {
                     assert_eq!(&got_cell[..], expected_cell);
                     assert_eq!(&got_proof[..], expected_proof);
                 }
+
+                // Check that the batch API agrees with the single blob API
+                let batch = ctx
+                    .compute_cells_and_kzg_proofs_batch(vec![&blob, &blob])
+                    .expect("batch should have been computed");
+                for (batch_cells, batch_proofs) in batch {
+                    assert_eq!(batch_cells, cells);
+                    assert_eq!(batch_proofs, proofs);
+                }
+
+                // Check that proving a single cell agrees with proving all of them
+                for k in [0, expected_proofs.len() - 1] {
+                    let (cell, proof) = ctx
+                        .compute_cell_and_kzg_proof(&blob, k as u64)
+                        .expect("cell and proof should have been computed");
+                    assert_eq!(&cell[..], &expected_cells[k]);
+                    assert_eq!(&proof[..], &expected_proofs[k]);
+                }
             }
             Err(_) => {
                 // On an error, we expect the output to be null
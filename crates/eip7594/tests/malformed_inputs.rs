@@ -0,0 +1,302 @@
+//! Checks that every public entry point rejects each kind of malformed input with the
+//! error variant for that kind, rather than with a generic error or a verification failure.
+#![cfg(not(feature = "no-embedded-setup"))]
+
+use rust_eth_kzg::{
+    constants::{
+        BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_FIELD_ELEMENT, BYTES_PER_G1_POINT,
+        CELLS_PER_EXT_BLOB,
+    },
+    DASContext, Error,
+};
+use serialization::SerializationError;
+
+/// `P1_NOT_IN_G1` from the consensus specs tests.
+const P1_NOT_IN_G1: &str = "8123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef";
+
+/// `P1_NOT_ON_CURVE` from the consensus specs tests.
+const P1_NOT_ON_CURVE: &str = "8123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcde0";
+
+/// The point at infinity, which is a valid commitment and proof.
+const IDENTITY: &str = "c00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000";
+
+/// The check that a malformed input is expected to fail.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Malformed {
+    NonCanonicalScalar,
+    InvalidPointEncoding,
+    PointNotOnCurve,
+    PointNotInSubgroup,
+}
+
+impl Malformed {
+    const POINTS: [Self; 3] = [
+        Self::InvalidPointEncoding,
+        Self::PointNotOnCurve,
+        Self::PointNotInSubgroup,
+    ];
+
+    /// Returns the kind of malformed input that `err` reports, if it is a deserialization error.
+    fn of(err: &Error) -> Option<Self> {
+        let err = match err {
+            Error::Serialization(err) | Error::EIP4844(eip4844::Error::Serialization(err)) => err,
+            _ => return None,
+        };
+        match err {
+            SerializationError::CouldNotDeserializeScalar { .. } => Some(Self::NonCanonicalScalar),
+            SerializationError::CouldNotDeserializeG1Point { .. } => {
+                Some(Self::InvalidPointEncoding)
+            }
+            SerializationError::G1PointNotOnCurve { .. } => Some(Self::PointNotOnCurve),
+            SerializationError::G1PointNotInSubgroup { .. } => Some(Self::PointNotInSubgroup),
+            _ => None,
+        }
+    }
+
+    /// Returns a point that is malformed in this way.
+    fn point(self) -> [u8; BYTES_PER_G1_POINT] {
+        match self {
+            Self::NonCanonicalScalar => unreachable!("a scalar is not a point"),
+            Self::InvalidPointEncoding => {
+                // The identity without its compression flag.
+                let mut point = hex_point(IDENTITY);
+                point[0] = 0x40;
+                point
+            }
+            Self::PointNotOnCurve => hex_point(P1_NOT_ON_CURVE),
+            Self::PointNotInSubgroup => hex_point(P1_NOT_IN_G1),
+        }
+    }
+}
+
+fn hex_point(hex_str: &str) -> [u8; BYTES_PER_G1_POINT] {
+    hex::decode(hex_str).unwrap().try_into().unwrap()
+}
+
+fn non_canonical_scalar() -> [u8; BYTES_PER_FIELD_ELEMENT] {
+    [0xff; BYTES_PER_FIELD_ELEMENT]
+}
+
+/// Returns a blob of zeroes, with the last field element replaced by a non-canonical one.
+fn non_canonical_blob() -> Box<[u8; BYTES_PER_BLOB]> {
+    let mut blob = Box::new([0u8; BYTES_PER_BLOB]);
+    blob[BYTES_PER_BLOB - BYTES_PER_FIELD_ELEMENT..].copy_from_slice(&non_canonical_scalar());
+    blob
+}
+
+/// Returns a cell of zeroes, with the last field element replaced by a non-canonical one.
+fn non_canonical_cell() -> Box<[u8; BYTES_PER_CELL]> {
+    let mut cell = Box::new([0u8; BYTES_PER_CELL]);
+    cell[BYTES_PER_CELL - BYTES_PER_FIELD_ELEMENT..].copy_from_slice(&non_canonical_scalar());
+    cell
+}
+
+#[track_caller]
+fn assert_rejected<T>(entry_point: &str, expected: Malformed, result: Result<T, Error>) {
+    let Err(err) = result else {
+        panic!("{entry_point} accepted an input with a {expected:?}");
+    };
+    assert_eq!(
+        Malformed::of(&err),
+        Some(expected),
+        "{entry_point} rejected an input with a {expected:?} with {err:?}"
+    );
+}
+
+#[test]
+fn non_canonical_blobs_are_rejected() {
+    let ctx = DASContext::default();
+    let blob = non_canonical_blob();
+    let blob = &*blob;
+    let commitment = hex_point(IDENTITY);
+    let z = [0u8; BYTES_PER_FIELD_ELEMENT];
+    let expected = Malformed::NonCanonicalScalar;
+
+    assert_rejected(
+        "blob_to_kzg_commitment",
+        expected,
+        ctx.blob_to_kzg_commitment(blob),
+    );
+    assert_rejected("compute_cells", expected, ctx.compute_cells(blob));
+    assert_rejected(
+        "compute_cells_and_kzg_proofs",
+        expected,
+        ctx.compute_cells_and_kzg_proofs(blob),
+    );
+    assert_rejected(
+        "compute_cells_and_kzg_proofs_with_scratch",
+        expected,
+        ctx.compute_cells_and_kzg_proofs_with_scratch(blob, &mut ctx.new_scratch())
+            .map(|_| ()),
+    );
+    assert_rejected(
+        "compute_cells_and_kzg_proofs_batch",
+        expected,
+        ctx.compute_cells_and_kzg_proofs_batch(vec![blob]),
+    );
+    assert_rejected("process_slot", expected, ctx.process_slot(&[blob]));
+    assert_rejected(
+        "compute_cell_and_kzg_proof",
+        expected,
+        ctx.compute_cell_and_kzg_proof(blob, 0),
+    );
+    assert_rejected(
+        "compute_kzg_proof",
+        expected,
+        ctx.compute_kzg_proof(blob, z),
+    );
+    assert_rejected(
+        "compute_blob_kzg_proof",
+        expected,
+        ctx.compute_blob_kzg_proof(blob, &commitment),
+    );
+    assert_rejected(
+        "verify_blob_kzg_proof",
+        expected,
+        ctx.verify_blob_kzg_proof(blob, &commitment, &commitment),
+    );
+    assert_rejected(
+        "verify_blob_kzg_proof_batch",
+        expected,
+        ctx.verify_blob_kzg_proof_batch(vec![blob], vec![&commitment], vec![&commitment]),
+    );
+}
+
+#[test]
+fn non_canonical_scalars_are_rejected() {
+    let ctx = DASContext::default();
+    let blob = [0u8; BYTES_PER_BLOB];
+    let point = hex_point(IDENTITY);
+    let valid = [0u8; BYTES_PER_FIELD_ELEMENT];
+    let invalid = non_canonical_scalar();
+    let expected = Malformed::NonCanonicalScalar;
+
+    assert_rejected(
+        "compute_kzg_proof",
+        expected,
+        ctx.compute_kzg_proof(&blob, invalid),
+    );
+    assert_rejected(
+        "verify_kzg_proof",
+        expected,
+        ctx.verify_kzg_proof(&point, invalid, valid, &point),
+    );
+    assert_rejected(
+        "verify_kzg_proof",
+        expected,
+        ctx.verify_kzg_proof(&point, valid, invalid, &point),
+    );
+}
+
+#[test]
+fn non_canonical_cells_are_rejected() {
+    let ctx = DASContext::default();
+    let valid = Box::new([0u8; BYTES_PER_CELL]);
+    let invalid = non_canonical_cell();
+    let commitment = hex_point(IDENTITY);
+    let expected = Malformed::NonCanonicalScalar;
+
+    // Recovery needs at least half of the cells, so one of them is replaced.
+    let half = CELLS_PER_EXT_BLOB / 2;
+    let cell_indices: Vec<_> = (0..half as u64).collect();
+    let mut cells = vec![&*valid; half];
+    cells[half - 1] = &*invalid;
+
+    assert_rejected(
+        "recover_cells_and_kzg_proofs",
+        expected,
+        ctx.recover_cells_and_kzg_proofs(cell_indices, cells),
+    );
+    assert_rejected(
+        "verify_cell_kzg_proof_batch",
+        expected,
+        ctx.verify_cell_kzg_proof_batch(
+            vec![&commitment],
+            &[0],
+            vec![&*invalid],
+            vec![&commitment],
+        ),
+    );
+}
+
+#[test]
+fn malformed_points_are_rejected() {
+    let ctx = DASContext::default();
+    let blob = [0u8; BYTES_PER_BLOB];
+    let cell = [0u8; BYTES_PER_CELL];
+    let valid = hex_point(IDENTITY);
+    let z = [0u8; BYTES_PER_FIELD_ELEMENT];
+
+    for expected in Malformed::POINTS {
+        let invalid = expected.point();
+
+        assert_rejected(
+            "compute_blob_kzg_proof",
+            expected,
+            ctx.compute_blob_kzg_proof(&blob, &invalid),
+        );
+        assert_rejected(
+            "verify_kzg_proof",
+            expected,
+            ctx.verify_kzg_proof(&invalid, z, z, &valid),
+        );
+        assert_rejected(
+            "verify_kzg_proof",
+            expected,
+            ctx.verify_kzg_proof(&valid, z, z, &invalid),
+        );
+        assert_rejected(
+            "verify_blob_kzg_proof",
+            expected,
+            ctx.verify_blob_kzg_proof(&blob, &invalid, &valid),
+        );
+        assert_rejected(
+            "verify_blob_kzg_proof",
+            expected,
+            ctx.verify_blob_kzg_proof(&blob, &valid, &invalid),
+        );
+        assert_rejected(
+            "verify_blob_kzg_proof_batch",
+            expected,
+            ctx.verify_blob_kzg_proof_batch(vec![&blob], vec![&invalid], vec![&valid]),
+        );
+        assert_rejected(
+            "verify_blob_kzg_proof_batch",
+            expected,
+            ctx.verify_blob_kzg_proof_batch(vec![&blob], vec![&valid], vec![&invalid]),
+        );
+        assert_rejected(
+            "verify_cell_kzg_proof_batch",
+            expected,
+            ctx.verify_cell_kzg_proof_batch(vec![&invalid], &[0], vec![&cell], vec![&valid]),
+        );
+        assert_rejected(
+            "verify_cell_kzg_proof_batch",
+            expected,
+            ctx.verify_cell_kzg_proof_batch(vec![&valid], &[0], vec![&cell], vec![&invalid]),
+        );
+    }
+}
+
+#[test]
+fn inputs_of_the_wrong_length_are_rejected() {
+    // The byte lengths of blobs, cells, scalars and points are checked by the types of the
+    // public API, so the lengths that remain are the number of elements in a batch.
+    let ctx = DASContext::default();
+    let blob = [0u8; BYTES_PER_BLOB];
+    let cell = [0u8; BYTES_PER_CELL];
+    let point = hex_point(IDENTITY);
+
+    assert!(matches!(
+        ctx.verify_blob_kzg_proof_batch(vec![&blob, &blob], vec![&point], vec![&point]),
+        Err(Error::EIP4844(eip4844::Error::Verifier(_)))
+    ));
+    assert!(matches!(
+        ctx.verify_cell_kzg_proof_batch(vec![&point], &[0, 1], vec![&cell], vec![&point]),
+        Err(Error::Verifier(_))
+    ));
+    assert!(matches!(
+        ctx.recover_cells_and_kzg_proofs(vec![0, 1], vec![&cell]),
+        Err(Error::Recovery(_))
+    ));
+}
@@ -16,15 +16,31 @@ pub const BYTES_PER_FIELD_ELEMENT: usize = 32;
 /// Note: This value must be a power of two between 1 and 64. The greatest value is 64 because there
 /// are only 65 G2 points in the trusted setup. Technically, it's still feasible to have a cell with
 /// more points, say 128, but it will require two proofs per cell.
+#[cfg(not(feature = "testing"))]
 pub const FIELD_ELEMENTS_PER_CELL: usize = 64;
 
+/// The number of field elements in a cell, when using the reduced `testing` parameters.
+///
+/// This keeps the number of cells in an extended blob equal to 32, so that recovery and
+/// sampling can still be exercised with a meaningful number of cells.
+#[cfg(feature = "testing")]
+pub const FIELD_ELEMENTS_PER_CELL: usize = 4;
+
 /// The number of field elements needed to represent a blob.
 ///
 /// Note: This is originally specified in the 4844 specs.
 ///
 /// See: https://github.com/ethereum/EIPs/blob/master/EIPS/eip-4844.md
+#[cfg(not(feature = "testing"))]
 pub const FIELD_ELEMENTS_PER_BLOB: usize = 4096;
 
+/// The number of field elements needed to represent a blob, when using the reduced `testing` parameters.
+///
+/// Note: These parameters are not compatible with mainnet and only exist so that
+/// unit tests and fuzzers that do not need mainnet sizes can run much faster.
+#[cfg(feature = "testing")]
+pub const FIELD_ELEMENTS_PER_BLOB: usize = 64;
+
 /// The number of bytes needed to represent a blob.
 pub const BYTES_PER_BLOB: usize = FIELD_ELEMENTS_PER_BLOB * BYTES_PER_FIELD_ELEMENT;
 
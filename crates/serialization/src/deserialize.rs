@@ -0,0 +1,284 @@
+//! Deserialization of untrusted input.
+//!
+//! Every blob, cell, field element, commitment and proof that is passed to the public API
+//! is deserialized by a function in this module, and nowhere else. Each function checks,
+//! in this order:
+//!
+//! - that the input has the expected length,
+//! - that every field element is canonical, ie strictly less than the scalar field modulus,
+//! - that every point is a valid compressed encoding, is on the curve and is in the G1 subgroup.
+//!
+//! Each of these failures has its own error variant, so that callers and the strictness tests
+//! can tell which check rejected an input. The trusted setup is deserialized separately in
+//! [`crate::trusted_setup`], since it is not untrusted input.
+
+use bls12_381::{G1Point, Scalar};
+
+use crate::{
+    constants::{
+        BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_FIELD_ELEMENT, BYTES_PER_G1_POINT,
+        FIELD_ELEMENTS_PER_BLOB,
+    },
+    SerializationError,
+};
+
+/// The flag in the most significant byte of a point that marks it as compressed.
+const COMPRESSION_FLAG: u8 = 0x80;
+
+/// The flag in the most significant byte of a point that marks it as the point at infinity.
+const INFINITY_FLAG: u8 = 0x40;
+
+/// The bits of the most significant byte of a point that are used for flags.
+const FLAG_BITS: u8 = 0xe0;
+
+/// The base field modulus in big-endian.
+const FIELD_MODULUS: [u8; BYTES_PER_G1_POINT] = [
+    0x1a, 0x01, 0x11, 0xea, 0x39, 0x7f, 0xe6, 0x9a, 0x4b, 0x1b, 0xa7, 0xb6, 0x43, 0x4b, 0xac, 0xd7,
+    0x64, 0x77, 0x4b, 0x84, 0xf3, 0x85, 0x12, 0xbf, 0x67, 0x30, 0xd2, 0xa0, 0xf6, 0xb0, 0xf6, 0x24,
+    0x1e, 0xab, 0xff, 0xfe, 0xb1, 0x53, 0xff, 0xff, 0xb9, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xaa, 0xab,
+];
+
+/// Deserializes a byte slice into a vector of `Scalar`s.
+///
+/// The input must be a multiple of the scalar size (32 bytes).
+pub(crate) fn deserialize_bytes_to_scalars(
+    bytes: &[u8],
+) -> Result<Vec<Scalar>, SerializationError> {
+    // Check that the bytes are a multiple of the scalar size
+    if bytes.len() % BYTES_PER_FIELD_ELEMENT != 0 {
+        return Err(SerializationError::ScalarHasInvalidLength {
+            length: bytes.len(),
+            bytes: bytes.to_vec(),
+        });
+    }
+
+    bytes
+        .chunks_exact(BYTES_PER_FIELD_ELEMENT)
+        .map(deserialize_bytes_to_scalar)
+        .collect()
+}
+
+/// Deserializes a blob into a vector of `Scalar`s.
+///
+/// The blob must be exactly `BYTES_PER_BLOB` long (4096 field elements).
+/// Returns an error if the length is incorrect or parsing fails.
+pub fn deserialize_blob_to_scalars(blob_bytes: &[u8]) -> Result<Vec<Scalar>, SerializationError> {
+    if blob_bytes.len() != BYTES_PER_BLOB {
+        return Err(SerializationError::BlobHasInvalidLength {
+            length: blob_bytes.len(),
+            bytes: blob_bytes.to_vec(),
+        });
+    }
+    deserialize_bytes_to_scalars(blob_bytes)
+}
+
+/// Deserializes a blob into `out`, without allocating.
+///
+/// The blob must be exactly `BYTES_PER_BLOB` long and `out` must hold exactly
+/// `FIELD_ELEMENTS_PER_BLOB` scalars. Returns an error if the length is incorrect or parsing fails,
+/// in which case the contents of `out` are unspecified.
+pub fn deserialize_blob_to_scalars_into(
+    blob_bytes: &[u8],
+    out: &mut [Scalar],
+) -> Result<(), SerializationError> {
+    if blob_bytes.len() != BYTES_PER_BLOB {
+        return Err(SerializationError::BlobHasInvalidLength {
+            length: blob_bytes.len(),
+            bytes: blob_bytes.to_vec(),
+        });
+    }
+    assert_eq!(
+        out.len(),
+        FIELD_ELEMENTS_PER_BLOB,
+        "output must have room for exactly {FIELD_ELEMENTS_PER_BLOB} scalars"
+    );
+
+    for (scalar, bytes) in out
+        .iter_mut()
+        .zip(blob_bytes.chunks_exact(BYTES_PER_FIELD_ELEMENT))
+    {
+        *scalar = deserialize_bytes_to_scalar(bytes)?;
+    }
+    Ok(())
+}
+
+/// Deserializes a 32-byte slice into a single `Scalar`.
+///
+/// Returns an error if the input is not exactly 32 bytes, or if the bytes are not
+/// a canonical encoding of a field element.
+pub fn deserialize_bytes_to_scalar(scalar_bytes: &[u8]) -> Result<Scalar, SerializationError> {
+    let Ok(bytes32) = scalar_bytes.try_into() else {
+        return Err(SerializationError::ScalarHasInvalidLength {
+            length: scalar_bytes.len(),
+            bytes: scalar_bytes.to_vec(),
+        });
+    };
+
+    bls12_381::scalar::from_bytes_be(bytes32).map_or_else(
+        || {
+            Err(SerializationError::CouldNotDeserializeScalar {
+                bytes: scalar_bytes.to_vec(),
+            })
+        },
+        Ok,
+    )
+}
+
+/// Converts a compressed G1 point (48 bytes) to a `G1Point`.
+///
+/// Returns an error if the length is incorrect, if the bytes are not a valid compressed
+/// encoding, or if the point is not on the curve or not in the G1 subgroup.
+pub fn deserialize_compressed_g1(point_bytes: &[u8]) -> Result<G1Point, SerializationError> {
+    let Ok(point_bytes) = point_bytes.try_into() else {
+        return Err(SerializationError::G1PointHasInvalidLength {
+            length: point_bytes.len(),
+            bytes: point_bytes.to_vec(),
+        });
+    };
+
+    let opt_g1: Option<G1Point> = Option::from(G1Point::from_compressed(point_bytes));
+    opt_g1.ok_or_else(|| classify_invalid_g1(point_bytes))
+}
+
+/// Works out why `point_bytes` was rejected by `G1Point::from_compressed`.
+///
+/// This is only called once a point has been rejected, so the valid path does a
+/// single decompression.
+fn classify_invalid_g1(point_bytes: &[u8; BYTES_PER_G1_POINT]) -> SerializationError {
+    let bytes = point_bytes.to_vec();
+
+    // `from_compressed_unchecked` only skips the subgroup check, so if it accepts the
+    // point then the subgroup check is what failed.
+    let on_curve: Option<G1Point> = Option::from(G1Point::from_compressed_unchecked(point_bytes));
+    if on_curve.is_some() {
+        return SerializationError::G1PointNotInSubgroup { bytes };
+    }
+
+    let flags = point_bytes[0];
+    let mut x = *point_bytes;
+    x[0] &= !FLAG_BITS;
+    let is_encoding_invalid = flags & COMPRESSION_FLAG == 0
+        || flags & INFINITY_FLAG != 0
+        // Big-endian byte arrays compare in the same order as the integers they encode
+        || x >= FIELD_MODULUS;
+    if is_encoding_invalid {
+        SerializationError::CouldNotDeserializeG1Point { bytes }
+    } else {
+        SerializationError::G1PointNotOnCurve { bytes }
+    }
+}
+
+/// Deserializes a list of compressed G1 point byte slices.
+///
+/// Returns a vector of `G1Point`s or fails on the first invalid point.
+/// Each input slice must be exactly 48 bytes.
+pub fn deserialize_compressed_g1_points(
+    points: Vec<&[u8; BYTES_PER_G1_POINT]>,
+) -> Result<Vec<G1Point>, SerializationError> {
+    points
+        .into_iter()
+        .map(|point| deserialize_compressed_g1(point))
+        .collect()
+}
+
+/// Deserializes a vector of cell byte slices into vectors of `Scalar`s.
+///
+/// Each cell must be `BYTES_PER_CELL` bytes long.
+/// Returns an error if parsing any cell fails.
+pub fn deserialize_cells(
+    cells: Vec<&[u8; BYTES_PER_CELL]>,
+) -> Result<Vec<Vec<Scalar>>, SerializationError> {
+    cells
+        .into_iter()
+        .map(|c| deserialize_bytes_to_scalars(c))
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use bls12_381::{traits::*, G1Projective};
+
+    use super::*;
+
+    /// `P1_NOT_IN_G1` from the consensus specs tests.
+    const P1_NOT_IN_G1: &str = "8123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef";
+
+    /// `P1_NOT_ON_CURVE` from the consensus specs tests.
+    const P1_NOT_ON_CURVE: &str = "8123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcde0";
+
+    fn point(hex_str: &str) -> Vec<u8> {
+        hex::decode(hex_str).unwrap()
+    }
+
+    #[test]
+    fn valid_points_are_accepted() {
+        let generator = G1Point::generator().to_compressed();
+        assert_eq!(
+            deserialize_compressed_g1(&generator).unwrap(),
+            G1Point::generator()
+        );
+
+        let identity = G1Point::from(G1Projective::identity()).to_compressed();
+        assert!(bool::from(
+            deserialize_compressed_g1(&identity).unwrap().is_identity()
+        ));
+    }
+
+    #[test]
+    fn invalid_points_are_classified() {
+        assert!(matches!(
+            deserialize_compressed_g1(&point(P1_NOT_IN_G1)),
+            Err(SerializationError::G1PointNotInSubgroup { .. })
+        ));
+        assert!(matches!(
+            deserialize_compressed_g1(&point(P1_NOT_ON_CURVE)),
+            Err(SerializationError::G1PointNotOnCurve { .. })
+        ));
+
+        let mut non_canonical_x = FIELD_MODULUS;
+        non_canonical_x[0] |= COMPRESSION_FLAG;
+        let mut missing_compression_flag = G1Point::generator().to_compressed();
+        missing_compression_flag[0] &= !COMPRESSION_FLAG;
+        let mut infinity_with_data = [0u8; BYTES_PER_G1_POINT];
+        infinity_with_data[0] = COMPRESSION_FLAG | INFINITY_FLAG;
+        infinity_with_data[BYTES_PER_G1_POINT - 1] = 1;
+
+        for bytes in [
+            non_canonical_x,
+            missing_compression_flag,
+            infinity_with_data,
+        ] {
+            assert!(matches!(
+                deserialize_compressed_g1(&bytes),
+                Err(SerializationError::CouldNotDeserializeG1Point { .. })
+            ));
+        }
+    }
+
+    #[test]
+    fn scalars_of_the_wrong_length_are_rejected() {
+        for length in [0, BYTES_PER_FIELD_ELEMENT - 1, BYTES_PER_FIELD_ELEMENT + 1] {
+            assert!(matches!(
+                deserialize_bytes_to_scalar(&vec![0u8; length]),
+                Err(SerializationError::ScalarHasInvalidLength { length: l, .. }) if l == length
+            ));
+        }
+    }
+
+    #[test]
+    fn non_canonical_scalars_are_rejected() {
+        let mut modulus = (-Scalar::ONE).to_bytes_be();
+        assert!(deserialize_bytes_to_scalar(&modulus).is_ok());
+
+        // r - 1 + 1 = r, the smallest non-canonical encoding.
+        modulus[BYTES_PER_FIELD_ELEMENT - 1] += 1;
+        assert!(matches!(
+            deserialize_bytes_to_scalar(&modulus),
+            Err(SerializationError::CouldNotDeserializeScalar { .. })
+        ));
+        assert!(matches!(
+            deserialize_bytes_to_scalar(&[0xff; BYTES_PER_FIELD_ELEMENT]),
+            Err(SerializationError::CouldNotDeserializeScalar { .. })
+        ));
+    }
+}
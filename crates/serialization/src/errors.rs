@@ -2,16 +2,30 @@
 /// or the trusted setup.
 #[derive(Debug)]
 pub enum Error {
-    /// Failed to deserialize a scalar value from the given bytes.
+    /// Failed to deserialize a scalar value from the given bytes, because they encode
+    /// an integer that is not less than the scalar field modulus.
     CouldNotDeserializeScalar {
         /// Raw bytes attempted to deserialize.
         bytes: Vec<u8>,
     },
-    /// Failed to deserialize a G1 group point from the given bytes.
+    /// Failed to deserialize a G1 group point from the given bytes, because they are not
+    /// a valid compressed encoding: the compression flag is not set, the infinity flag is set
+    /// with other non-zero bits, or the x coordinate is not less than the base field modulus.
     CouldNotDeserializeG1Point {
         /// Raw bytes attempted to deserialize.
         bytes: Vec<u8>,
     },
+    /// The bytes are a valid encoding of an x coordinate, but there is no point on the curve
+    /// with that x coordinate.
+    G1PointNotOnCurve {
+        /// Raw bytes attempted to deserialize.
+        bytes: Vec<u8>,
+    },
+    /// The bytes encode a point on the curve that is not in the G1 subgroup.
+    G1PointNotInSubgroup {
+        /// Raw bytes attempted to deserialize.
+        bytes: Vec<u8>,
+    },
     /// Scalar had an incorrect byte length.
     ScalarHasInvalidLength {
         /// Raw bytes with incorrect length.
@@ -1,103 +1,31 @@
 pub mod constants;
+mod deserialize;
 pub mod errors;
+pub mod prefixed_hex;
+#[cfg(feature = "serde")]
+pub mod serde;
+#[cfg(feature = "ssz")]
+pub mod ssz;
 pub mod types;
 
 use bls12_381::{G1Point, Scalar};
 use constants::{
-    BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_FIELD_ELEMENT, BYTES_PER_G1_POINT,
-    CELLS_PER_EXT_BLOB, FIELD_ELEMENTS_PER_CELL,
+    BYTES_PER_CELL, BYTES_PER_FIELD_ELEMENT, BYTES_PER_G1_POINT, CELLS_PER_EXT_BLOB,
+    FIELD_ELEMENTS_PER_CELL,
+};
+pub use deserialize::{
+    deserialize_blob_to_scalars, deserialize_blob_to_scalars_into, deserialize_bytes_to_scalar,
+    deserialize_cells, deserialize_compressed_g1, deserialize_compressed_g1_points,
 };
 use types::*;
 
 pub use crate::errors::Error as SerializationError;
 
-/// Deserializes a byte slice into a vector of `Scalar`s.
-///
-/// The input must be a multiple of the scalar size (32 bytes).
-fn deserialize_bytes_to_scalars(bytes: &[u8]) -> Result<Vec<Scalar>, SerializationError> {
-    // Check that the bytes are a multiple of the scalar size
-    if bytes.len() % BYTES_PER_FIELD_ELEMENT != 0 {
-        return Err(SerializationError::ScalarHasInvalidLength {
-            length: bytes.len(),
-            bytes: bytes.to_vec(),
-        });
-    }
-
-    bytes
-        .chunks_exact(BYTES_PER_FIELD_ELEMENT)
-        .map(deserialize_bytes_to_scalar)
-        .collect()
-}
-
-/// Deserializes a blob into a vector of `Scalar`s.
-///
-/// The blob must be exactly `BYTES_PER_BLOB` long (4096 field elements).
-/// Returns an error if the length is incorrect or parsing fails.
-pub fn deserialize_blob_to_scalars(blob_bytes: &[u8]) -> Result<Vec<Scalar>, SerializationError> {
-    if blob_bytes.len() != BYTES_PER_BLOB {
-        return Err(SerializationError::BlobHasInvalidLength {
-            length: blob_bytes.len(),
-            bytes: blob_bytes.to_vec(),
-        });
-    }
-    deserialize_bytes_to_scalars(blob_bytes)
-}
-
-/// Deserializes a 32-byte slice into a single `Scalar`.
-///
-/// This expects the input to be exactly 32 bytes.
-/// Fails if the bytes do not correspond to a valid field element.
-pub fn deserialize_bytes_to_scalar(scalar_bytes: &[u8]) -> Result<Scalar, SerializationError> {
-    let bytes32 = scalar_bytes.try_into().expect("infallible: expected blob chunks to be exactly {SCALAR_SERIALIZED_SIZE} bytes, since blob was a multiple of {SCALAR_SERIALIZED_SIZE");
-
-    // Convert the CtOption into Option
-    let option_scalar: Option<Scalar> = Scalar::from_bytes_be(bytes32).into();
-    option_scalar.map_or_else(
-        || {
-            Err(SerializationError::CouldNotDeserializeScalar {
-                bytes: scalar_bytes.to_vec(),
-            })
-        },
-        Ok,
-    )
-}
-
-/// Converts a compressed G1 point (48 bytes) to a `G1Point`.
-///
-/// Returns an error if the length is incorrect or the bytes are invalid.
-/// Wraps the `from_compressed` function from the BLS12-381 crate.
-pub fn deserialize_compressed_g1(point_bytes: &[u8]) -> Result<G1Point, SerializationError> {
-    let Ok(point_bytes) = point_bytes.try_into() else {
-        return Err(SerializationError::G1PointHasInvalidLength {
-            length: point_bytes.len(),
-            bytes: point_bytes.to_vec(),
-        });
-    };
-
-    let opt_g1: Option<G1Point> = Option::from(G1Point::from_compressed(point_bytes));
-    opt_g1.ok_or_else(|| SerializationError::CouldNotDeserializeG1Point {
-        bytes: point_bytes.to_vec(),
-    })
-}
-
 /// Serializes a G1 point into its compressed representation.
 pub fn serialize_g1_compressed(point: &G1Point) -> [u8; BYTES_PER_G1_POINT] {
     point.to_compressed()
 }
 
-/// Deserializes a list of compressed G1 point byte slices.
-///
-/// Returns a vector of `G1Point`s or fails on the first invalid point.
-/// Each input slice must be exactly 48 bytes.
-pub fn deserialize_compressed_g1_points(
-    points: Vec<&[u8; BYTES_PER_G1_POINT]>,
-) -> Result<Vec<G1Point>, SerializationError> {
-    points
-        .into_iter()
-        .map(|point| deserialize_compressed_g1(point))
-        .collect()
-}
-
 /// Serializes a slice of `Scalar`s into a byte vector representing a cell.
 ///
 /// The input must be exactly `FIELD_ELEMENTS_PER_CELL` elements long.
@@ -112,19 +40,6 @@ pub(crate) fn serialize_scalars_to_cell(scalars: &[Scalar]) -> Vec<u8> {
     scalars.iter().flat_map(Scalar::to_bytes_be).collect()
 }
 
-/// Deserializes a vector of cell byte slices into vectors of `Scalar`s.
-///
-/// Each cell must be `BYTES_PER_CELL` bytes long.
-/// Returns an error if parsing any cell fails.
-pub fn deserialize_cells(
-    cells: Vec<&[u8; BYTES_PER_CELL]>,
-) -> Result<Vec<Vec<Scalar>>, SerializationError> {
-    cells
-        .into_iter()
-        .map(|c| deserialize_bytes_to_scalars(c))
-        .collect()
-}
-
 /// Serializes both cells and corresponding proofs into flat output formats.
 ///
 /// Converts evaluation sets to `Cell`s and G1 points to `KZGProof`s.
@@ -139,25 +54,47 @@ pub fn serialize_cells_and_proofs(
     )
 }
 
+/// Serializes a single evaluation set into a `Cell`.
+///
+/// The set must contain exactly `FIELD_ELEMENTS_PER_CELL` scalars.
+pub fn serialize_cell(coset_evaluation: &[Scalar]) -> Cell {
+    serialize_scalars_to_cell(coset_evaluation)
+        .into_boxed_slice()
+        .try_into()
+        .expect("infallible: serialized cell must be BYTES_PER_CELL long")
+}
+
+/// Serializes a single evaluation set into an existing `Cell`, without allocating.
+///
+/// The set must contain exactly `FIELD_ELEMENTS_PER_CELL` scalars.
+pub fn serialize_cell_into(coset_evaluation: &[Scalar], out: &mut [u8; BYTES_PER_CELL]) {
+    assert_eq!(
+        coset_evaluation.len(),
+        FIELD_ELEMENTS_PER_CELL,
+        "must have exactly {FIELD_ELEMENTS_PER_CELL} scalars to serialize to a cell"
+    );
+
+    for (bytes, scalar) in out
+        .chunks_exact_mut(BYTES_PER_FIELD_ELEMENT)
+        .zip(coset_evaluation)
+    {
+        bytes.copy_from_slice(&scalar.to_bytes_be());
+    }
+}
+
 /// Serializes a list of evaluation sets into an array of `Cell`s.
 ///
 /// Each set must contain exactly `FIELD_ELEMENTS_PER_CELL` scalars.
 /// Returns a fixed-size array with length `CELLS_PER_EXT_BLOB`.
 pub fn serialize_cells(coset_evaluations: &[Vec<Scalar>]) -> [Cell; CELLS_PER_EXT_BLOB] {
     // Serialize the evaluation sets into `Cell`s.
-    std::array::from_fn(|i| {
-        let evals = &coset_evaluations[i];
-        let bytes = serialize_scalars_to_cell(evals);
-        bytes
-            .into_boxed_slice()
-            .try_into()
-            .expect("infallible: serialized cell must be BYTES_PER_CELL long")
-    })
+    std::array::from_fn(|i| serialize_cell(&coset_evaluations[i]))
 }
 
 /// Serialization methods that are used for the trusted setup
 pub mod trusted_setup {
     use bls12_381::{G1Point, G2Point};
+    use maybe_rayon::prelude::*;
 
     /// An enum used to specify whether to check that the points are in the correct subgroup
     #[derive(Debug, Copy, Clone)]
@@ -168,14 +105,16 @@ pub mod trusted_setup {
         NoCheck,
     }
 
-    /// Deserialize G1 points from hex strings without checking that the element
+    /// Deserialize G1 points from hex strings, optionally checking that each element
     /// is in the correct subgroup.
-    pub fn deserialize_g1_points<T: AsRef<str>>(
+    ///
+    /// With the `multithreaded` feature, the points are decompressed in parallel.
+    pub fn deserialize_g1_points<T: AsRef<str> + Sync>(
         g1_points_hex_str: &[T],
         check: SubgroupCheck,
     ) -> Vec<G1Point> {
         g1_points_hex_str
-            .iter()
+            .maybe_into_par_iter()
             .map(|hex_str| {
                 let hex_str = hex_str
                     .as_ref()
@@ -196,14 +135,16 @@ pub mod trusted_setup {
             .collect()
     }
 
-    /// Deserialize G2 points from hex strings without checking that the element
+    /// Deserialize G2 points from hex strings, optionally checking that each element
     /// is in the correct subgroup.
-    pub fn deserialize_g2_points<T: AsRef<str>>(
+    ///
+    /// With the `multithreaded` feature, the points are decompressed in parallel.
+    pub fn deserialize_g2_points<T: AsRef<str> + Sync>(
         g2_points_hex_str: &[T],
         subgroup_check: SubgroupCheck,
     ) -> Vec<G2Point> {
         g2_points_hex_str
-            .iter()
+            .maybe_into_par_iter()
             .map(|hex_str| {
                 let hex_str = hex_str
                     .as_ref()
@@ -231,7 +172,10 @@ mod tests {
     use rand::thread_rng;
 
     use super::*;
-    use crate::constants::FIELD_ELEMENTS_PER_BLOB;
+    use crate::{
+        constants::{BYTES_PER_BLOB, FIELD_ELEMENTS_PER_BLOB},
+        deserialize::deserialize_bytes_to_scalars,
+    };
 
     /// Returns a randomly generated scalar field element.
     fn random_scalar() -> Scalar {
@@ -264,10 +208,12 @@ mod tests {
     }
 
     #[test]
-    #[should_panic]
     fn test_deserialize_scalar_invalid_length() {
         let bytes = vec![1u8; 31]; // invalid
-        let _ = deserialize_bytes_to_scalar(&bytes);
+        assert!(matches!(
+            deserialize_bytes_to_scalar(&bytes),
+            Err(SerializationError::ScalarHasInvalidLength { length: 31, .. })
+        ));
     }
 
     #[test]
@@ -286,6 +232,30 @@ mod tests {
         ));
     }
 
+    #[test]
+    fn test_deserialize_blob_to_scalars_into_matches() {
+        let blob = valid_blob();
+        let mut scalars = vec![Scalar::ZERO; FIELD_ELEMENTS_PER_BLOB];
+        deserialize_blob_to_scalars_into(&blob, &mut scalars).unwrap();
+        assert_eq!(scalars, deserialize_blob_to_scalars(&blob).unwrap());
+
+        let blob = vec![0u8; BYTES_PER_BLOB - 1];
+        assert!(matches!(
+            deserialize_blob_to_scalars_into(&blob, &mut scalars),
+            Err(SerializationError::BlobHasInvalidLength { .. })
+        ));
+    }
+
+    #[test]
+    fn test_serialize_cell_into_matches() {
+        let scalars: Vec<_> = (0..FIELD_ELEMENTS_PER_CELL)
+            .map(|_| random_scalar())
+            .collect();
+        let mut cell = [0u8; BYTES_PER_CELL];
+        serialize_cell_into(&scalars, &mut cell);
+        assert_eq!(cell, *serialize_cell(&scalars));
+    }
+
     #[test]
     fn test_deserialize_bytes_to_scalars_valid() {
         let cell = valid_cell();
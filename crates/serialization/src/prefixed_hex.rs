@@ -0,0 +1,132 @@
+//! `0x`-prefixed hex strings, as used by the JSON-RPC and beacon APIs.
+//!
+//! Blobs, cells, commitments, proofs and field elements are aliases of byte arrays, so they
+//! cannot implement `Display` and `FromStr`. They implement [`PrefixedHex`] instead:
+//!
+//! ```ignore
+//! let commitment = KZGCommitment::from_hex(&response.kzg_commitment)?;
+//! assert_eq!(commitment.to_hex(), response.kzg_commitment);
+//! ```
+
+/// Errors returned by [`PrefixedHex::from_hex`].
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum HexError {
+    /// The string, without its `0x` prefix, is not valid hex.
+    InvalidHex(hex::FromHexError),
+    /// The string encodes the wrong number of bytes.
+    InvalidLength {
+        /// The number of bytes of the type being parsed.
+        expected: usize,
+        /// The number of bytes in the string.
+        length: usize,
+    },
+}
+
+/// Conversion to and from `0x`-prefixed hex strings.
+pub trait PrefixedHex: Sized {
+    /// Returns the bytes as a lowercase hex string, prefixed with `0x`.
+    fn to_hex(&self) -> String;
+
+    /// Parses a hex string that encodes exactly `Self`'s number of bytes.
+    ///
+    /// The `0x` prefix is optional and both cases are accepted. The bytes are only checked
+    /// to have the right length, since they are checked to be canonical scalars or valid
+    /// points by the methods they are passed to.
+    fn from_hex(hex_str: &str) -> Result<Self, HexError>;
+}
+
+impl<const N: usize> PrefixedHex for [u8; N] {
+    fn to_hex(&self) -> String {
+        encode(self)
+    }
+
+    fn from_hex(hex_str: &str) -> Result<Self, HexError> {
+        let bytes = decode(hex_str)?;
+        let length = bytes.len();
+        bytes.try_into().map_err(|_| HexError::InvalidLength {
+            expected: N,
+            length,
+        })
+    }
+}
+
+/// Blobs and cells are boxed, since they are too large to be passed around on the stack.
+impl<const N: usize> PrefixedHex for Box<[u8; N]> {
+    fn to_hex(&self) -> String {
+        encode(self.as_slice())
+    }
+
+    fn from_hex(hex_str: &str) -> Result<Self, HexError> {
+        let bytes = decode(hex_str)?;
+        let length = bytes.len();
+        bytes
+            .into_boxed_slice()
+            .try_into()
+            .map_err(|_| HexError::InvalidLength {
+                expected: N,
+                length,
+            })
+    }
+}
+
+/// Returns `bytes` as a lowercase hex string, prefixed with `0x`.
+pub(crate) fn encode(bytes: &[u8]) -> String {
+    format!("0x{}", hex::encode(bytes))
+}
+
+/// Decodes a hex string with an optional `0x` prefix.
+pub(crate) fn decode(hex_str: &str) -> Result<Vec<u8>, HexError> {
+    let hex_str = hex_str.strip_prefix("0x").unwrap_or(hex_str);
+    hex::decode(hex_str).map_err(HexError::InvalidHex)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::{HexError, PrefixedHex};
+    use crate::{
+        constants::BYTES_PER_CELL,
+        types::{Cell, KZGCommitment, SerializedScalar},
+    };
+
+    /// The commitment to the zero blob, ie the point at infinity.
+    const IDENTITY: &str = "0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000";
+
+    #[test]
+    fn round_trips() {
+        let commitment = KZGCommitment::from_hex(IDENTITY).unwrap();
+        assert_eq!(commitment[0], 0xc0);
+        assert_eq!(commitment.to_hex(), IDENTITY);
+
+        let cell: Cell = Box::new([0xab; BYTES_PER_CELL]);
+        assert_eq!(Cell::from_hex(&cell.to_hex()).unwrap(), cell);
+    }
+
+    #[test]
+    fn prefix_and_case_are_optional() {
+        let expected = KZGCommitment::from_hex(IDENTITY).unwrap();
+        assert_eq!(KZGCommitment::from_hex(&IDENTITY[2..]).unwrap(), expected);
+        assert_eq!(
+            KZGCommitment::from_hex(&IDENTITY.to_uppercase().replace("0X", "0x")).unwrap(),
+            expected
+        );
+    }
+
+    #[test]
+    fn invalid_strings_are_rejected() {
+        assert_eq!(
+            SerializedScalar::from_hex("0x00ff"),
+            Err(HexError::InvalidLength {
+                expected: 32,
+                length: 2
+            })
+        );
+        assert!(matches!(
+            SerializedScalar::from_hex("0x0"),
+            Err(HexError::InvalidHex(_))
+        ));
+        assert!(matches!(
+            SerializedScalar::from_hex(&"zz".repeat(32)),
+            Err(HexError::InvalidHex(_))
+        ));
+    }
+}
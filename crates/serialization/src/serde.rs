@@ -0,0 +1,231 @@
+//! Serde support for blobs, cells, commitments, proofs and field elements.
+//!
+//! These types are aliases of byte arrays, which serde cannot implement its traits for,
+//! so each of them has a module that can be used with `#[serde(with = "...")]`:
+//!
+//! ```ignore
+//! #[derive(Serialize, Deserialize)]
+//! struct Sidecar {
+//!     #[serde(with = "rust_eth_kzg::serde::cell")]
+//!     cell: Cell,
+//!     #[serde(with = "rust_eth_kzg::serde::bytes48")]
+//!     proof: KZGProof,
+//!     // A `CellIndex` is a `u64`, which serde already supports.
+//!     index: CellIndex,
+//! }
+//! ```
+//!
+//! Human-readable formats such as JSON use a `0x`-prefixed hex string, as in the consensus
+//! specs. Binary formats use the raw bytes. When deserializing, the prefix is optional and
+//! the length is checked, but the contents are not: like any other input, they are only
+//! checked to be canonical scalars and valid points by the methods they are passed to.
+
+use std::fmt;
+
+use ::serde::{
+    de::{self, SeqAccess, Visitor},
+    Deserializer, Serializer,
+};
+
+use crate::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_FIELD_ELEMENT},
+    prefixed_hex,
+};
+
+fn serialize_bytes<S: Serializer>(bytes: &[u8], serializer: S) -> Result<S::Ok, S::Error> {
+    if serializer.is_human_readable() {
+        serializer.serialize_str(&prefixed_hex::encode(bytes))
+    } else {
+        serializer.serialize_bytes(bytes)
+    }
+}
+
+fn deserialize_array<'de, D: Deserializer<'de>, const N: usize>(
+    deserializer: D,
+) -> Result<Box<[u8; N]>, D::Error> {
+    if deserializer.is_human_readable() {
+        deserializer.deserialize_str(ArrayVisitor::<N>)
+    } else {
+        deserializer.deserialize_bytes(ArrayVisitor::<N>)
+    }
+}
+
+/// Accepts a hex string, a byte string or a sequence of bytes of length `N`.
+///
+/// The array is boxed, since blobs and cells are too large to be passed around on the stack.
+struct ArrayVisitor<const N: usize>;
+
+impl<'de, const N: usize> Visitor<'de> for ArrayVisitor<N> {
+    type Value = Box<[u8; N]>;
+
+    fn expecting(&self, formatter: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            formatter,
+            "{N} bytes, or a hex string that encodes {N} bytes"
+        )
+    }
+
+    fn visit_str<E: de::Error>(self, value: &str) -> Result<Self::Value, E> {
+        let bytes = prefixed_hex::decode(value).map_err(|err| E::custom(format!("{err:?}")))?;
+        self.visit_byte_buf(bytes)
+    }
+
+    fn visit_bytes<E: de::Error>(self, value: &[u8]) -> Result<Self::Value, E> {
+        self.visit_byte_buf(value.to_vec())
+    }
+
+    fn visit_byte_buf<E: de::Error>(self, value: Vec<u8>) -> Result<Self::Value, E> {
+        let length = value.len();
+        value
+            .into_boxed_slice()
+            .try_into()
+            .map_err(|_| E::invalid_length(length, &self))
+    }
+
+    fn visit_seq<A: SeqAccess<'de>>(self, mut seq: A) -> Result<Self::Value, A::Error> {
+        let mut bytes = Vec::with_capacity(N);
+        while let Some(byte) = seq.next_element()? {
+            if bytes.len() == N {
+                return Err(de::Error::invalid_length(N + 1, &self));
+            }
+            bytes.push(byte);
+        }
+        self.visit_byte_buf(bytes)
+    }
+}
+
+/// Serde support for a `Box<[u8; BYTES_PER_BLOB]>`, ie an owned blob.
+pub mod blob {
+    use super::*;
+
+    pub fn serialize<S: Serializer>(
+        blob: &[u8; BYTES_PER_BLOB],
+        serializer: S,
+    ) -> Result<S::Ok, S::Error> {
+        serialize_bytes(blob, serializer)
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(
+        deserializer: D,
+    ) -> Result<Box<[u8; BYTES_PER_BLOB]>, D::Error> {
+        deserialize_array(deserializer)
+    }
+}
+
+/// Serde support for a [`crate::types::Cell`].
+pub mod cell {
+    use super::*;
+    use crate::types::Cell;
+
+    pub fn serialize<S: Serializer>(
+        cell: &[u8; BYTES_PER_CELL],
+        serializer: S,
+    ) -> Result<S::Ok, S::Error> {
+        serialize_bytes(cell, serializer)
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(deserializer: D) -> Result<Cell, D::Error> {
+        deserialize_array(deserializer)
+    }
+}
+
+/// Serde support for a [`crate::types::KZGCommitment`] or a [`crate::types::KZGProof`].
+pub mod bytes48 {
+    use super::*;
+
+    pub fn serialize<S: Serializer>(bytes: &[u8; 48], serializer: S) -> Result<S::Ok, S::Error> {
+        serialize_bytes(bytes, serializer)
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(deserializer: D) -> Result<[u8; 48], D::Error> {
+        deserialize_array(deserializer).map(|bytes| *bytes)
+    }
+}
+
+/// Serde support for a [`crate::types::SerializedScalar`].
+pub mod field_element {
+    use super::*;
+
+    pub fn serialize<S: Serializer>(
+        bytes: &[u8; BYTES_PER_FIELD_ELEMENT],
+        serializer: S,
+    ) -> Result<S::Ok, S::Error> {
+        serialize_bytes(bytes, serializer)
+    }
+
+    pub fn deserialize<'de, D: Deserializer<'de>>(
+        deserializer: D,
+    ) -> Result<[u8; BYTES_PER_FIELD_ELEMENT], D::Error> {
+        deserialize_array(deserializer).map(|bytes| *bytes)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use ::serde::{Deserialize, Serialize};
+
+    use crate::{
+        constants::{BYTES_PER_BLOB, BYTES_PER_CELL},
+        types::{Cell, KZGProof, SerializedScalar},
+    };
+
+    #[derive(Debug, PartialEq, Eq, Serialize, Deserialize)]
+    struct Sidecar {
+        #[serde(with = "super::blob")]
+        blob: Box<[u8; BYTES_PER_BLOB]>,
+        #[serde(with = "super::cell")]
+        cell: Cell,
+        #[serde(with = "super::bytes48")]
+        proof: KZGProof,
+        #[serde(with = "super::field_element")]
+        z: SerializedScalar,
+        index: u64,
+    }
+
+    fn sidecar() -> Sidecar {
+        Sidecar {
+            blob: Box::new([1; BYTES_PER_BLOB]),
+            cell: Box::new([2; BYTES_PER_CELL]),
+            proof: [0xc0; 48],
+            z: [4; 32],
+            index: 5,
+        }
+    }
+
+    #[test]
+    fn json_uses_prefixed_hex() {
+        let json = serde_json::to_value(sidecar()).unwrap();
+        assert_eq!(json["proof"], format!("0x{}", "c0".repeat(48)));
+        assert_eq!(json["z"], format!("0x{}", "04".repeat(32)));
+        assert_eq!(json["index"], 5);
+
+        let decoded: Sidecar = serde_json::from_value(json).unwrap();
+        assert_eq!(decoded, sidecar());
+    }
+
+    #[test]
+    fn json_accepts_unprefixed_hex_and_rejects_wrong_lengths() {
+        let mut json = serde_json::to_value(sidecar()).unwrap();
+        json["proof"] = "c0".repeat(48).into();
+        let decoded: Sidecar = serde_json::from_value(json.clone()).unwrap();
+        assert_eq!(decoded, sidecar());
+
+        json["proof"] = "c0".repeat(47).into();
+        assert!(serde_json::from_value::<Sidecar>(json.clone()).is_err());
+        json["proof"] = "0xzz".into();
+        assert!(serde_json::from_value::<Sidecar>(json).is_err());
+    }
+
+    #[test]
+    fn binary_formats_use_raw_bytes() {
+        let encoded = bincode::serialize(&sidecar()).unwrap();
+        // Each byte array is prefixed with its length, followed by the index.
+        assert_eq!(
+            encoded.len(),
+            4 * 8 + BYTES_PER_BLOB + BYTES_PER_CELL + 48 + 32 + 8
+        );
+
+        let decoded: Sidecar = bincode::deserialize(&encoded).unwrap();
+        assert_eq!(decoded, sidecar());
+    }
+}
@@ -0,0 +1,449 @@
+//! SSZ support for cells, commitments and proofs, and for the cells of a data column sidecar.
+//!
+//! Cells, commitments and proofs are aliases of byte arrays, which the SSZ traits cannot be
+//! implemented for outside of the `ssz` crate, so each of them has a module that can be used
+//! with `#[ssz(with = "...")]`:
+//!
+//! ```ignore
+//! #[derive(Encode, Decode)]
+//! struct CellAndProof {
+//!     #[ssz(with = "rust_eth_kzg::ssz::cell")]
+//!     cell: Cell,
+//!     #[ssz(with = "rust_eth_kzg::ssz::bytes48")]
+//!     proof: KZGProof,
+//! }
+//! ```
+//!
+//! [`DataColumnSidecarCells`] holds the fields of a `DataColumnSidecar` that are passed to
+//! the library, and decodes the cells straight into the boxes that the library takes, either
+//! from its own encoding or from that of a whole sidecar.
+//!
+//! As with any other input, decoded cells, commitments and proofs are only checked to have
+//! the right length; they are checked to be canonical scalars and valid points by the
+//! methods they are passed to.
+
+use ::ssz::{Decode, DecodeError, Encode, BYTES_PER_LENGTH_OFFSET};
+
+use crate::{
+    constants::{BYTES_PER_CELL, BYTES_PER_COMMITMENT},
+    types::{Cell, KZGCommitment, KZGProof},
+};
+
+/// The maximum number of blobs in a block, and so of cells in a column, as set by the
+/// `MAX_BLOB_COMMITMENTS_PER_BLOCK` preset of the consensus specs.
+///
+/// This is the limit of the SSZ lists in a data column sidecar.
+pub const MAX_BLOB_COMMITMENTS_PER_BLOCK: usize = 4096;
+
+/// SSZ support for a [`Cell`], which is encoded as a `ByteVector[BYTES_PER_CELL]`.
+pub mod cell {
+    use super::*;
+
+    pub mod encode {
+        use super::*;
+
+        pub const fn is_ssz_fixed_len() -> bool {
+            true
+        }
+
+        pub const fn ssz_fixed_len() -> usize {
+            BYTES_PER_CELL
+        }
+
+        pub const fn ssz_bytes_len(_cell: &Cell) -> usize {
+            BYTES_PER_CELL
+        }
+
+        pub fn ssz_append(cell: &Cell, buf: &mut Vec<u8>) {
+            buf.extend_from_slice(cell.as_slice());
+        }
+    }
+
+    pub mod decode {
+        use super::*;
+
+        pub const fn is_ssz_fixed_len() -> bool {
+            true
+        }
+
+        pub const fn ssz_fixed_len() -> usize {
+            BYTES_PER_CELL
+        }
+
+        pub fn from_ssz_bytes(bytes: &[u8]) -> Result<Cell, DecodeError> {
+            decode_cell(bytes)
+        }
+    }
+}
+
+/// SSZ support for a [`KZGCommitment`] or a [`KZGProof`], which are encoded as a `Bytes48`.
+pub mod bytes48 {
+    use super::*;
+
+    pub mod encode {
+        use super::*;
+
+        pub const fn is_ssz_fixed_len() -> bool {
+            true
+        }
+
+        pub const fn ssz_fixed_len() -> usize {
+            BYTES_PER_COMMITMENT
+        }
+
+        pub const fn ssz_bytes_len(_bytes: &[u8; BYTES_PER_COMMITMENT]) -> usize {
+            BYTES_PER_COMMITMENT
+        }
+
+        pub fn ssz_append(bytes: &[u8; BYTES_PER_COMMITMENT], buf: &mut Vec<u8>) {
+            buf.extend_from_slice(bytes);
+        }
+    }
+
+    pub mod decode {
+        use super::*;
+
+        pub const fn is_ssz_fixed_len() -> bool {
+            true
+        }
+
+        pub const fn ssz_fixed_len() -> usize {
+            BYTES_PER_COMMITMENT
+        }
+
+        pub fn from_ssz_bytes(bytes: &[u8]) -> Result<[u8; BYTES_PER_COMMITMENT], DecodeError> {
+            decode_bytes48(bytes)
+        }
+    }
+}
+
+fn decode_cell(bytes: &[u8]) -> Result<Cell, DecodeError> {
+    bytes
+        .to_vec()
+        .into_boxed_slice()
+        .try_into()
+        .map_err(|_| DecodeError::InvalidByteLength {
+            len: bytes.len(),
+            expected: BYTES_PER_CELL,
+        })
+}
+
+fn decode_bytes48(bytes: &[u8]) -> Result<[u8; BYTES_PER_COMMITMENT], DecodeError> {
+    bytes
+        .try_into()
+        .map_err(|_| DecodeError::InvalidByteLength {
+            len: bytes.len(),
+            expected: BYTES_PER_COMMITMENT,
+        })
+}
+
+/// The fields of a `DataColumnSidecar` that are needed to verify its cells.
+///
+/// This is encoded as the SSZ container
+///
+/// ```text
+/// class DataColumnSidecarCells(Container):
+///     index: ColumnIndex
+///     column: List[Cell, MAX_BLOB_COMMITMENTS_PER_BLOCK]
+///     kzg_commitments: List[KZGCommitment, MAX_BLOB_COMMITMENTS_PER_BLOCK]
+///     kzg_proofs: List[KZGProof, MAX_BLOB_COMMITMENTS_PER_BLOCK]
+/// ```
+///
+/// whose fields are the first four fields of a `DataColumnSidecar`, in the same order.
+/// The `i`-th cell of the column is the cell at `index` of the `i`-th blob of the block.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct DataColumnSidecarCells {
+    pub index: u64,
+    pub column: Vec<Cell>,
+    pub kzg_commitments: Vec<KZGCommitment>,
+    pub kzg_proofs: Vec<KZGProof>,
+}
+
+/// The length of the fixed part of the encoding: the index and an offset for each list.
+const FIXED_LEN: usize = 8 + 3 * BYTES_PER_LENGTH_OFFSET;
+
+/// The length of an encoded `SignedBeaconBlockHeader`: the slot, the proposer index, three
+/// roots and a BLS signature.
+const SIGNED_BEACON_BLOCK_HEADER_LEN: usize = 8 + 8 + 3 * 32 + 96;
+
+/// `KZG_COMMITMENTS_INCLUSION_PROOF_DEPTH` from the consensus specs.
+const KZG_COMMITMENTS_INCLUSION_PROOF_DEPTH: usize = 4;
+
+/// The length of the fixed part of an encoded `DataColumnSidecar`, which is followed by the
+/// same three lists as a [`DataColumnSidecarCells`].
+const SIDECAR_FIXED_LEN: usize =
+    FIXED_LEN + SIGNED_BEACON_BLOCK_HEADER_LEN + KZG_COMMITMENTS_INCLUSION_PROOF_DEPTH * 32;
+
+impl DataColumnSidecarCells {
+    /// Decodes the cells, commitments and proofs of an SSZ encoded `DataColumnSidecar`,
+    /// ignoring the signed block header and the inclusion proof of the commitments.
+    pub fn from_sidecar_ssz_bytes(bytes: &[u8]) -> Result<Self, DecodeError> {
+        decode_lists(bytes, SIDECAR_FIXED_LEN)
+    }
+}
+
+impl Encode for DataColumnSidecarCells {
+    fn is_ssz_fixed_len() -> bool {
+        false
+    }
+
+    fn ssz_bytes_len(&self) -> usize {
+        FIXED_LEN
+            + self.column.len() * BYTES_PER_CELL
+            + (self.kzg_commitments.len() + self.kzg_proofs.len()) * BYTES_PER_COMMITMENT
+    }
+
+    fn ssz_append(&self, buf: &mut Vec<u8>) {
+        buf.reserve(self.ssz_bytes_len());
+
+        let column_offset = FIXED_LEN;
+        let commitments_offset = column_offset + self.column.len() * BYTES_PER_CELL;
+        let proofs_offset = commitments_offset + self.kzg_commitments.len() * BYTES_PER_COMMITMENT;
+
+        buf.extend_from_slice(&self.index.to_le_bytes());
+        // The lists have at most `MAX_BLOB_COMMITMENTS_PER_BLOCK` items, so the offsets fit in a u32.
+        #[allow(clippy::cast_possible_truncation)]
+        for offset in [column_offset, commitments_offset, proofs_offset] {
+            buf.extend_from_slice(&(offset as u32).to_le_bytes());
+        }
+        for cell in &self.column {
+            buf.extend_from_slice(cell.as_slice());
+        }
+        for bytes in self.kzg_commitments.iter().chain(&self.kzg_proofs) {
+            buf.extend_from_slice(bytes);
+        }
+    }
+}
+
+impl Decode for DataColumnSidecarCells {
+    fn is_ssz_fixed_len() -> bool {
+        false
+    }
+
+    fn from_ssz_bytes(bytes: &[u8]) -> Result<Self, DecodeError> {
+        decode_lists(bytes, FIXED_LEN)
+    }
+}
+
+/// Decodes a container that starts with the index and the offsets of the three lists of a
+/// [`DataColumnSidecarCells`], followed by other fields for a total of `fixed_len` bytes.
+fn decode_lists(bytes: &[u8], fixed_len: usize) -> Result<DataColumnSidecarCells, DecodeError> {
+    if bytes.len() < fixed_len {
+        return Err(DecodeError::InvalidByteLength {
+            len: bytes.len(),
+            expected: fixed_len,
+        });
+    }
+
+    let index = u64::from_le_bytes(bytes[..8].try_into().expect("length was checked"));
+    let offsets: Vec<usize> = bytes[8..FIXED_LEN]
+        .chunks_exact(BYTES_PER_LENGTH_OFFSET)
+        .map(|offset| u32::from_le_bytes(offset.try_into().expect("length was checked")) as usize)
+        .collect();
+
+    // The first list starts right after the fixed part, and each list ends where the
+    // next one starts, or at the end of the bytes for the last one.
+    match offsets[0] {
+        offset if offset < fixed_len => return Err(DecodeError::OffsetIntoFixedPortion(offset)),
+        offset if offset > fixed_len => return Err(DecodeError::OffsetSkipsVariableBytes(offset)),
+        _ => {}
+    }
+    for pair in offsets.windows(2) {
+        if pair[1] < pair[0] {
+            return Err(DecodeError::OffsetsAreDecreasing(pair[1]));
+        }
+    }
+    if offsets[2] > bytes.len() {
+        return Err(DecodeError::OffsetOutOfBounds(offsets[2]));
+    }
+
+    Ok(DataColumnSidecarCells {
+        index,
+        column: decode_list(&bytes[offsets[0]..offsets[1]], BYTES_PER_CELL, decode_cell)?,
+        kzg_commitments: decode_list(
+            &bytes[offsets[1]..offsets[2]],
+            BYTES_PER_COMMITMENT,
+            decode_bytes48,
+        )?,
+        kzg_proofs: decode_list(&bytes[offsets[2]..], BYTES_PER_COMMITMENT, decode_bytes48)?,
+    })
+}
+
+/// Decodes a `List[T, MAX_BLOB_COMMITMENTS_PER_BLOCK]` of items that are `item_len` bytes long.
+fn decode_list<T>(
+    bytes: &[u8],
+    item_len: usize,
+    decode_item: impl Fn(&[u8]) -> Result<T, DecodeError>,
+) -> Result<Vec<T>, DecodeError> {
+    if bytes.len() % item_len != 0 {
+        return Err(DecodeError::InvalidListFixedBytesLen(bytes.len()));
+    }
+    let num_items = bytes.len() / item_len;
+    if num_items > MAX_BLOB_COMMITMENTS_PER_BLOCK {
+        return Err(DecodeError::BytesInvalid(format!(
+            "list has {num_items} items, but at most {MAX_BLOB_COMMITMENTS_PER_BLOCK} are allowed"
+        )));
+    }
+    bytes.chunks_exact(item_len).map(decode_item).collect()
+}
+
+#[cfg(feature = "tree-hash")]
+mod tree_hash_impl {
+    use tree_hash::{merkle_root, mix_in_length, Hash256, PackedEncoding, TreeHash, TreeHashType};
+
+    use super::{DataColumnSidecarCells, MAX_BLOB_COMMITMENTS_PER_BLOCK};
+
+    /// Returns the root of a list of byte vectors, each of which is merkleized on its own.
+    fn list_root<'a>(items: impl ExactSizeIterator<Item = &'a [u8]>) -> Hash256 {
+        let num_items = items.len();
+        let roots: Vec<u8> = items.flat_map(|item| merkle_root(item, 0).0).collect();
+        mix_in_length(
+            &merkle_root(&roots, MAX_BLOB_COMMITMENTS_PER_BLOCK),
+            num_items,
+        )
+    }
+
+    impl TreeHash for DataColumnSidecarCells {
+        fn tree_hash_type() -> TreeHashType {
+            TreeHashType::Container
+        }
+
+        fn tree_hash_packed_encoding(&self) -> PackedEncoding {
+            unreachable!("containers are never packed")
+        }
+
+        fn tree_hash_packing_factor() -> usize {
+            unreachable!("containers are never packed")
+        }
+
+        fn tree_hash_root(&self) -> Hash256 {
+            let field_roots = [
+                self.index.tree_hash_root(),
+                list_root(self.column.iter().map(|cell| cell.as_slice())),
+                list_root(self.kzg_commitments.iter().map(|bytes| bytes.as_slice())),
+                list_root(self.kzg_proofs.iter().map(|bytes| bytes.as_slice())),
+            ];
+            let field_roots: Vec<u8> = field_roots.iter().flat_map(|root| root.0).collect();
+            merkle_root(&field_roots, 0)
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use ::ssz::{Decode, DecodeError, Encode};
+
+    use super::{DataColumnSidecarCells, FIXED_LEN, SIDECAR_FIXED_LEN};
+    use crate::constants::{BYTES_PER_CELL, BYTES_PER_COMMITMENT};
+
+    fn sidecar_cells() -> DataColumnSidecarCells {
+        DataColumnSidecarCells {
+            index: 3,
+            column: vec![Box::new([1; BYTES_PER_CELL])],
+            kzg_commitments: vec![[0xc0; BYTES_PER_COMMITMENT]],
+            kzg_proofs: vec![[0xc0; BYTES_PER_COMMITMENT]],
+        }
+    }
+
+    #[test]
+    fn round_trips() {
+        let encoded = sidecar_cells().as_ssz_bytes();
+        assert_eq!(
+            encoded.len(),
+            FIXED_LEN + BYTES_PER_CELL + 2 * BYTES_PER_COMMITMENT
+        );
+        assert_eq!(encoded.len(), sidecar_cells().ssz_bytes_len());
+        assert_eq!(
+            DataColumnSidecarCells::from_ssz_bytes(&encoded),
+            Ok(sidecar_cells())
+        );
+
+        let empty = DataColumnSidecarCells::default();
+        assert_eq!(
+            DataColumnSidecarCells::from_ssz_bytes(&empty.as_ssz_bytes()),
+            Ok(empty)
+        );
+    }
+
+    #[test]
+    fn malformed_encodings_are_rejected() {
+        let encoded = sidecar_cells().as_ssz_bytes();
+
+        // A truncated proof
+        assert_eq!(
+            DataColumnSidecarCells::from_ssz_bytes(&encoded[..encoded.len() - 1]),
+            Err(DecodeError::InvalidListFixedBytesLen(
+                BYTES_PER_COMMITMENT - 1
+            ))
+        );
+
+        // The offset of the column points into the fixed part
+        let mut bad_offset = encoded.clone();
+        bad_offset[8..12].copy_from_slice(&4u32.to_le_bytes());
+        assert_eq!(
+            DataColumnSidecarCells::from_ssz_bytes(&bad_offset),
+            Err(DecodeError::OffsetIntoFixedPortion(4))
+        );
+
+        // The offset of the proofs is before the offset of the commitments
+        let mut decreasing = encoded;
+        decreasing[16..20].copy_from_slice(&(FIXED_LEN as u32).to_le_bytes());
+        assert_eq!(
+            DataColumnSidecarCells::from_ssz_bytes(&decreasing),
+            Err(DecodeError::OffsetsAreDecreasing(FIXED_LEN))
+        );
+    }
+
+    #[test]
+    fn decodes_whole_sidecars() {
+        // A sidecar has the same lists, after a block header and an inclusion proof.
+        let lists = &sidecar_cells().as_ssz_bytes()[FIXED_LEN..];
+        let mut sidecar = 3u64.to_le_bytes().to_vec();
+        for offset in [0, BYTES_PER_CELL, BYTES_PER_CELL + BYTES_PER_COMMITMENT] {
+            sidecar.extend_from_slice(&((SIDECAR_FIXED_LEN + offset) as u32).to_le_bytes());
+        }
+        sidecar.resize(SIDECAR_FIXED_LEN, 0xaa);
+        sidecar.extend_from_slice(lists);
+
+        assert_eq!(
+            DataColumnSidecarCells::from_sidecar_ssz_bytes(&sidecar),
+            Ok(sidecar_cells())
+        );
+        assert_eq!(
+            DataColumnSidecarCells::from_ssz_bytes(&sidecar),
+            Err(DecodeError::OffsetSkipsVariableBytes(SIDECAR_FIXED_LEN))
+        );
+    }
+
+    #[test]
+    fn with_modules_use_the_raw_bytes() {
+        let cell = sidecar_cells().column.remove(0);
+        let mut buf = Vec::new();
+        super::cell::encode::ssz_append(&cell, &mut buf);
+        assert_eq!(buf.as_slice(), cell.as_slice());
+        assert_eq!(super::cell::decode::from_ssz_bytes(&buf), Ok(cell));
+        assert!(super::cell::decode::from_ssz_bytes(&buf[1..]).is_err());
+
+        let proof = [0xc0; BYTES_PER_COMMITMENT];
+        let mut buf = Vec::new();
+        super::bytes48::encode::ssz_append(&proof, &mut buf);
+        assert_eq!(super::bytes48::decode::from_ssz_bytes(&buf), Ok(proof));
+    }
+
+    // The root was computed with an independent implementation of the SSZ merkleization,
+    // for the mainnet cell size.
+    #[cfg(all(feature = "tree-hash", not(feature = "testing")))]
+    #[test]
+    fn tree_hash_root_matches_the_specs() {
+        use tree_hash::TreeHash;
+
+        assert_eq!(
+            hex::encode(sidecar_cells().tree_hash_root()),
+            "ec44a30fb4a3e5404e23d21acf9cacffc6aeeab0d94519d7c80a3b4fa161de59"
+        );
+        assert_eq!(
+            hex::encode(DataColumnSidecarCells::default().tree_hash_root()),
+            "ead6ba48472cf5114829d60a7f17567d3888f352f4c8c26a8168b72434e59066"
+        );
+    }
+}
@@ -0,0 +1,267 @@
+//! The handlers for each spec function, ie how to parse a case and call the library with it.
+//!
+//! Inputs that the library cannot represent, such as a 47 byte proof, are treated like an
+//! error of the library, since the spec expects those cases to have no output.
+
+use std::fmt::Debug;
+
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_CELL},
+    Cell, DASContext, Error, KZGProof,
+};
+use serde::{de::DeserializeOwned, Deserialize};
+
+use crate::Outcome;
+
+/// The contents of a `data.yaml` file.
+#[derive(Deserialize)]
+struct Case<I, O> {
+    input: I,
+    /// `None` if the input is invalid and the function is expected to fail.
+    output: Option<O>,
+}
+
+/// What the library returned, or why the input was rejected.
+type Actual<T> = Result<T, String>;
+
+/// Runs a case of `handler` from its yaml encoding.
+pub(crate) fn run(ctx: &DASContext, handler: &str, yaml: &str) -> Outcome {
+    match handler {
+        "blob_to_kzg_commitment" => check(yaml, |input: BlobInput| {
+            let blob = bytes(&input.blob)?;
+            let commitment = ctx.blob_to_kzg_commitment(&blob).map_err(describe)?;
+            Ok(commitment.to_vec())
+        }),
+        "compute_kzg_proof" => check(yaml, |input: ComputeKzgProofInput| {
+            let blob = bytes(&input.blob)?;
+            let z = bytes(&input.z)?;
+            let (proof, y) = ctx.compute_kzg_proof(&blob, z).map_err(describe)?;
+            Ok([proof.to_vec(), y.to_vec()])
+        }),
+        "compute_blob_kzg_proof" => check(yaml, |input: ComputeBlobKzgProofInput| {
+            let blob = bytes(&input.blob)?;
+            let commitment = bytes(&input.commitment)?;
+            let proof = ctx
+                .compute_blob_kzg_proof(&blob, &commitment)
+                .map_err(describe)?;
+            Ok(proof.to_vec())
+        }),
+        "verify_kzg_proof" => check(yaml, |input: VerifyKzgProofInput| {
+            let commitment = bytes(&input.commitment)?;
+            let z = bytes(&input.z)?;
+            let y = bytes(&input.y)?;
+            let proof = bytes(&input.proof)?;
+            verified(ctx.verify_kzg_proof(&commitment, z, y, &proof))
+        }),
+        "verify_blob_kzg_proof" => check(yaml, |input: VerifyBlobKzgProofInput| {
+            let blob = bytes(&input.blob)?;
+            let commitment = bytes(&input.commitment)?;
+            let proof = bytes(&input.proof)?;
+            verified(ctx.verify_blob_kzg_proof(&blob, &commitment, &proof))
+        }),
+        "verify_blob_kzg_proof_batch" => check(yaml, |input: VerifyBlobKzgProofBatchInput| {
+            let blobs: Vec<[u8; BYTES_PER_BLOB]> = all_bytes(&input.blobs)?;
+            let commitments: Vec<[u8; 48]> = all_bytes(&input.commitments)?;
+            let proofs: Vec<[u8; 48]> = all_bytes(&input.proofs)?;
+            verified(ctx.verify_blob_kzg_proof_batch(
+                blobs.iter().collect(),
+                commitments.iter().collect(),
+                proofs.iter().collect(),
+            ))
+        }),
+        "compute_cells" => check(yaml, |input: BlobInput| {
+            let blob = bytes(&input.blob)?;
+            let cells = ctx.compute_cells(&blob).map_err(describe)?;
+            Ok(cells.iter().map(|cell| cell.to_vec()).collect::<Vec<_>>())
+        }),
+        "compute_cells_and_kzg_proofs" => check(yaml, |input: BlobInput| {
+            let blob = bytes(&input.blob)?;
+            let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(&blob).map_err(describe)?;
+            Ok(cells_and_proofs(&cells, &proofs))
+        }),
+        "recover_cells_and_kzg_proofs" => check(yaml, |input: RecoverCellsAndKzgProofsInput| {
+            let cells: Vec<[u8; BYTES_PER_CELL]> = all_bytes(&input.cells)?;
+            let (cells, proofs) = ctx
+                .recover_cells_and_kzg_proofs(input.cell_indices, cells.iter().collect())
+                .map_err(describe)?;
+            Ok(cells_and_proofs(&cells, &proofs))
+        }),
+        "verify_cell_kzg_proof_batch" => check(yaml, |input: VerifyCellKzgProofBatchInput| {
+            let commitments: Vec<[u8; 48]> = all_bytes(&input.commitments)?;
+            let cells: Vec<[u8; BYTES_PER_CELL]> = all_bytes(&input.cells)?;
+            let proofs: Vec<[u8; 48]> = all_bytes(&input.proofs)?;
+            verified(ctx.verify_cell_kzg_proof_batch(
+                commitments.iter().collect(),
+                &input.cell_indices,
+                cells.iter().collect(),
+                proofs.iter().collect(),
+            ))
+        }),
+        _ => Outcome::Skipped(format!("no handler for `{handler}`")),
+    }
+}
+
+/// Parses the case in `yaml`, runs `f` on its input and compares the result with its output.
+fn check<I, T>(yaml: &str, f: impl FnOnce(I) -> Actual<T>) -> Outcome
+where
+    I: DeserializeOwned,
+    T: Output + PartialEq + Debug,
+{
+    let case: Case<I, T::Encoded> = match serde_yaml::from_str(yaml) {
+        Ok(case) => case,
+        Err(err) => return Outcome::Failed(format!("could not parse the case: {err}")),
+    };
+    let expected = match case.output.map(T::decode).transpose() {
+        Ok(expected) => expected,
+        Err(err) => return Outcome::Failed(format!("could not decode the output: {err}")),
+    };
+
+    match (f(case.input), expected) {
+        (Ok(actual), Some(expected)) if actual == expected => Outcome::Passed,
+        (Ok(_), Some(_)) => Outcome::Failed("output differs from the expected output".to_owned()),
+        (Ok(_), None) => Outcome::Failed("expected an error, but got an output".to_owned()),
+        (Err(_), None) => Outcome::Passed,
+        (Err(err), Some(_)) => Outcome::Failed(format!("expected an output, but got {err}")),
+    }
+}
+
+/// An output returned by the library, which is encoded with hex strings in the cases.
+trait Output: Sized {
+    type Encoded: DeserializeOwned;
+
+    fn decode(encoded: Self::Encoded) -> Result<Self, String>;
+}
+
+impl Output for bool {
+    type Encoded = Self;
+
+    fn decode(encoded: Self) -> Result<Self, String> {
+        Ok(encoded)
+    }
+}
+
+impl Output for Vec<u8> {
+    type Encoded = String;
+
+    fn decode(encoded: String) -> Result<Self, String> {
+        decode_hex(&encoded)
+    }
+}
+
+impl Output for [Vec<u8>; 2] {
+    type Encoded = [String; 2];
+
+    fn decode([a, b]: [String; 2]) -> Result<Self, String> {
+        Ok([decode_hex(&a)?, decode_hex(&b)?])
+    }
+}
+
+impl Output for Vec<Vec<u8>> {
+    type Encoded = Vec<String>;
+
+    fn decode(encoded: Vec<String>) -> Result<Self, String> {
+        encoded.iter().map(|hex| decode_hex(hex)).collect()
+    }
+}
+
+impl Output for (Vec<Vec<u8>>, Vec<Vec<u8>>) {
+    type Encoded = (Vec<String>, Vec<String>);
+
+    fn decode((cells, proofs): Self::Encoded) -> Result<Self, String> {
+        Ok((Output::decode(cells)?, Output::decode(proofs)?))
+    }
+}
+
+/// Maps the result of a verification to whether the proof was valid, keeping other errors.
+fn verified(result: Result<(), Error>) -> Actual<bool> {
+    match result {
+        Ok(()) => Ok(true),
+        Err(err) if err.is_proof_invalid() => Ok(false),
+        Err(err) => Err(describe(err)),
+    }
+}
+
+fn cells_and_proofs(cells: &[Cell], proofs: &[KZGProof]) -> (Vec<Vec<u8>>, Vec<Vec<u8>>) {
+    (
+        cells.iter().map(|cell| cell.to_vec()).collect(),
+        proofs.iter().map(|proof| proof.to_vec()).collect(),
+    )
+}
+
+fn describe(err: Error) -> String {
+    format!("{err:?}")
+}
+
+/// Decodes a `0x` prefixed hex string.
+fn decode_hex(string: &str) -> Result<Vec<u8>, String> {
+    let digits = string
+        .strip_prefix("0x")
+        .ok_or_else(|| format!("hex string is not prefixed with 0x: {string}"))?;
+    hex::decode(digits).map_err(|err| format!("invalid hex string: {err}"))
+}
+
+/// Decodes a `0x` prefixed hex string of exactly `N` bytes.
+fn bytes<const N: usize>(hex: &str) -> Actual<[u8; N]> {
+    let bytes = decode_hex(hex)?;
+    bytes
+        .as_slice()
+        .try_into()
+        .map_err(|_| format!("expected {N} bytes, got {}", bytes.len()))
+}
+
+fn all_bytes<const N: usize>(hexes: &[String]) -> Actual<Vec<[u8; N]>> {
+    hexes.iter().map(|hex| bytes(hex)).collect()
+}
+
+#[derive(Deserialize)]
+struct BlobInput {
+    blob: String,
+}
+
+#[derive(Deserialize)]
+struct ComputeKzgProofInput {
+    blob: String,
+    z: String,
+}
+
+#[derive(Deserialize)]
+struct ComputeBlobKzgProofInput {
+    blob: String,
+    commitment: String,
+}
+
+#[derive(Deserialize)]
+struct VerifyKzgProofInput {
+    commitment: String,
+    z: String,
+    y: String,
+    proof: String,
+}
+
+#[derive(Deserialize)]
+struct VerifyBlobKzgProofInput {
+    blob: String,
+    commitment: String,
+    proof: String,
+}
+
+#[derive(Deserialize)]
+struct VerifyBlobKzgProofBatchInput {
+    blobs: Vec<String>,
+    commitments: Vec<String>,
+    proofs: Vec<String>,
+}
+
+#[derive(Deserialize)]
+struct RecoverCellsAndKzgProofsInput {
+    cell_indices: Vec<u64>,
+    cells: Vec<String>,
+}
+
+#[derive(Deserialize)]
+struct VerifyCellKzgProofBatchInput {
+    commitments: Vec<String>,
+    cell_indices: Vec<u64>,
+    cells: Vec<String>,
+    proofs: Vec<String>,
+}
@@ -0,0 +1,43 @@
+//! Downloads the `general.tar.gz` artifact of a consensus-spec-tests release.
+
+use std::{
+    fs, io,
+    path::{Path, PathBuf},
+};
+
+/// The URL of the releases of ethereum/consensus-spec-tests.
+const RELEASES_URL: &str = "https://github.com/ethereum/consensus-spec-tests/releases/download";
+
+/// Downloads the KZG test vectors of the release tagged `version`, ie `v1.5.0`, into
+/// `cache_dir` and returns the directory they were extracted to.
+///
+/// Only the files below a `kzg` directory are extracted. If the vectors of `version` were
+/// already extracted into `cache_dir`, they are reused without downloading them again.
+pub fn download(version: &str, cache_dir: &Path) -> io::Result<PathBuf> {
+    let dir = cache_dir.join(version);
+    let done_marker = dir.join(".complete");
+    if done_marker.exists() {
+        return Ok(dir);
+    }
+
+    let url = format!("{RELEASES_URL}/{version}/general.tar.gz");
+    let response = ureq::get(&url)
+        .call()
+        .map_err(|err| io::Error::other(format!("could not download {url}: {err}")))?;
+
+    let mut archive = tar::Archive::new(flate2::read::GzDecoder::new(response.into_reader()));
+    for entry in archive.entries()? {
+        let mut entry = entry?;
+        let path = entry.path()?.into_owned();
+        if path
+            .components()
+            .any(|component| component.as_os_str() == "kzg")
+        {
+            entry.unpack_in(&dir)?;
+        }
+    }
+
+    // Mark the extraction as complete, so that an interrupted download is not reused.
+    fs::write(done_marker, url)?;
+    Ok(dir)
+}
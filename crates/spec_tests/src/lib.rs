@@ -0,0 +1,188 @@
+//! Runs the KZG test vectors of [consensus-spec-tests] against the library.
+//!
+//! The vectors can either be a checkout of the repository, an extracted `general.tar.gz`
+//! release artifact, or the `test_vectors` folder of this repository. Every `data.yaml`
+//! file below the given directory is treated as a case, and the handler is read from the
+//! path, ie `<handler>/<suite>/<case>/data.yaml`. Cases generated as JSON, in `data.json`
+//! files, are read too, since JSON is valid yaml.
+//!
+//! [consensus-spec-tests]: https://github.com/ethereum/consensus-spec-tests
+
+mod cases;
+#[cfg(feature = "download")]
+pub mod download;
+
+use std::{
+    fs, io,
+    path::{Path, PathBuf},
+    time::Instant,
+};
+
+use rust_eth_kzg::DASContext;
+use serde::Serialize;
+
+/// The names of the file that holds the input and output of a case.
+const CASE_FILE_NAMES: [&str; 2] = ["data.yaml", "data.json"];
+
+/// The result of running a single case.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", content = "reason", rename_all = "snake_case")]
+pub enum Outcome {
+    /// The library returned the expected output, or rejected an invalid input.
+    Passed,
+    /// The library returned a different output, or the case could not be parsed.
+    Failed(String),
+    /// The handler is not implemented by the library, for example a spec helper.
+    Skipped(String),
+}
+
+/// The outcome of a case, along with where it was found.
+#[derive(Debug, Clone, Serialize)]
+pub struct CaseReport {
+    /// The spec function that the case tests, ie `verify_cell_kzg_proof_batch`.
+    pub handler: String,
+    /// The name of the case directory.
+    pub case: String,
+    /// The path to the `data.yaml` or `data.json` file of the case.
+    pub path: PathBuf,
+    pub outcome: Outcome,
+    /// The time taken to run the case, including parsing it.
+    pub elapsed_micros: u64,
+}
+
+/// The number of cases with each outcome.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize)]
+pub struct Summary {
+    pub passed: usize,
+    pub failed: usize,
+    pub skipped: usize,
+}
+
+impl Summary {
+    /// Counts the outcomes of `reports`.
+    pub fn from_reports(reports: &[CaseReport]) -> Self {
+        let mut summary = Self::default();
+        for report in reports {
+            match report.outcome {
+                Outcome::Passed => summary.passed += 1,
+                Outcome::Failed(_) => summary.failed += 1,
+                Outcome::Skipped(_) => summary.skipped += 1,
+            }
+        }
+        summary
+    }
+}
+
+/// Runs every case below `dir` whose handler contains `filter`, in path order.
+pub fn run_dir(ctx: &DASContext, dir: &Path, filter: Option<&str>) -> io::Result<Vec<CaseReport>> {
+    let mut paths = Vec::new();
+    collect_case_files(dir, &mut paths)?;
+    paths.sort();
+
+    let mut reports = Vec::with_capacity(paths.len());
+    for path in paths {
+        let handler = handler_name(&path);
+        if filter.is_some_and(|filter| !handler.contains(filter)) {
+            continue;
+        }
+        reports.push(run_case(ctx, path, handler));
+    }
+    Ok(reports)
+}
+
+/// Runs the case in the `data.yaml` or `data.json` file at `path`.
+fn run_case(ctx: &DASContext, path: PathBuf, handler: String) -> CaseReport {
+    let case = path
+        .parent()
+        .and_then(Path::file_name)
+        .map(|name| name.to_string_lossy().into_owned())
+        .unwrap_or_default();
+
+    let start = Instant::now();
+    let outcome = match fs::read_to_string(&path) {
+        Ok(yaml) => cases::run(ctx, &handler, &yaml),
+        Err(err) => Outcome::Failed(format!("could not read the case: {err}")),
+    };
+
+    CaseReport {
+        handler,
+        case,
+        path,
+        outcome,
+        elapsed_micros: start.elapsed().as_micros() as u64,
+    }
+}
+
+/// Returns the handler of the case at `path`, ie the directory above the suite of the case.
+fn handler_name(path: &Path) -> String {
+    path.ancestors()
+        .nth(3)
+        .and_then(Path::file_name)
+        .map(|name| name.to_string_lossy().into_owned())
+        .unwrap_or_default()
+}
+
+fn collect_case_files(dir: &Path, files: &mut Vec<PathBuf>) -> io::Result<()> {
+    for entry in fs::read_dir(dir)? {
+        let path = entry?.path();
+        if path.is_dir() {
+            collect_case_files(&path, files)?;
+        } else if path
+            .file_name()
+            .is_some_and(|name| CASE_FILE_NAMES.iter().any(|case_file| name == *case_file))
+        {
+            files.push(path);
+        }
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::Path;
+
+    use rust_eth_kzg::{test_vectors, DASContext};
+
+    use crate::{handler_name, run_dir, Outcome, Summary};
+
+    #[test]
+    fn handler_is_read_from_the_path() {
+        let path = Path::new("general/fulu/kzg/compute_cells/kzg-mainnet/case_0/data.yaml");
+        assert_eq!(handler_name(path), "compute_cells");
+    }
+
+    #[test]
+    fn vendored_vectors_pass() {
+        let ctx = DASContext::default();
+        let dir = Path::new(env!("CARGO_MANIFEST_DIR")).join("../../test_vectors");
+        let reports = run_dir(&ctx, &dir, None).unwrap();
+
+        for report in &reports {
+            assert_eq!(report.outcome, Outcome::Passed, "{}", report.path.display());
+        }
+        let summary = Summary::from_reports(&reports);
+        assert!(summary.passed > 0);
+        assert_eq!(summary.failed, 0);
+    }
+
+    #[test]
+    fn generated_vectors_pass() {
+        let ctx = DASContext::default();
+        for format in [test_vectors::Format::Yaml, test_vectors::Format::Json] {
+            let dir = std::env::temp_dir().join(format!(
+                "ekzg-generated-vectors-{format:?}-{}",
+                std::process::id()
+            ));
+            let cases = test_vectors::generate(&ctx, 11);
+            test_vectors::write_to_dir(&cases, &dir, format).unwrap();
+
+            let reports = run_dir(&ctx, &dir, None).unwrap();
+            std::fs::remove_dir_all(&dir).unwrap();
+
+            for report in &reports {
+                assert_eq!(report.outcome, Outcome::Passed, "{}", report.path.display());
+            }
+            assert_eq!(reports.len(), cases.len());
+        }
+    }
+}
@@ -0,0 +1,139 @@
+//! Command line interface for running the consensus-spec-tests KZG vectors.
+//!
+//! ```text
+//! spec-tests [--json] [--filter <handler>] <dir>
+//! spec-tests [--json] [--filter <handler>] --download <version> [--cache-dir <dir>]
+//! ```
+//!
+//! Each case is printed on its own line, either as text or as a JSON object with `--json`,
+//! followed by a summary. The exit code is 1 if any case failed.
+
+use std::{path::PathBuf, process::ExitCode};
+
+use ekzg_spec_tests::{run_dir, CaseReport, Outcome, Summary};
+use rust_eth_kzg::DASContext;
+
+const USAGE: &str = "\
+usage: spec-tests [--json] [--filter <handler>] <dir>
+       spec-tests [--json] [--filter <handler>] --download <version> [--cache-dir <dir>]";
+
+#[derive(Default)]
+struct Args {
+    json: bool,
+    filter: Option<String>,
+    dir: Option<PathBuf>,
+    download: Option<String>,
+    cache_dir: Option<PathBuf>,
+}
+
+fn parse_args() -> Result<Args, String> {
+    let mut args = Args::default();
+    let mut iter = std::env::args().skip(1);
+    while let Some(arg) = iter.next() {
+        let mut value = || {
+            iter.next()
+                .ok_or_else(|| format!("missing value for {arg}"))
+        };
+        match arg.as_str() {
+            "--json" => args.json = true,
+            "--filter" => args.filter = Some(value()?),
+            "--download" => args.download = Some(value()?),
+            "--cache-dir" => args.cache_dir = Some(value()?.into()),
+            "-h" | "--help" => return Err(String::new()),
+            _ if arg.starts_with('-') => return Err(format!("unknown option {arg}")),
+            _ => args.dir = Some(arg.into()),
+        }
+    }
+    Ok(args)
+}
+
+fn vectors_dir(args: &Args) -> Result<PathBuf, String> {
+    match (&args.dir, &args.download) {
+        (Some(dir), None) => Ok(dir.clone()),
+        #[cfg(feature = "download")]
+        (None, Some(version)) => {
+            let cache_dir = args
+                .cache_dir
+                .clone()
+                .unwrap_or_else(|| std::env::temp_dir().join("consensus-spec-tests"));
+            ekzg_spec_tests::download::download(version, &cache_dir).map_err(|err| err.to_string())
+        }
+        #[cfg(not(feature = "download"))]
+        (None, Some(_)) => Err("--download requires the `download` feature".to_owned()),
+        _ => Err("expected either a directory or --download".to_owned()),
+    }
+}
+
+fn print_report(report: &CaseReport, json: bool) {
+    if json {
+        println!(
+            "{}",
+            serde_json::to_string(report).expect("reports can be serialized")
+        );
+        return;
+    }
+    match &report.outcome {
+        Outcome::Passed => println!("PASS {}/{}", report.handler, report.case),
+        Outcome::Failed(reason) => println!("FAIL {}/{}: {reason}", report.handler, report.case),
+        Outcome::Skipped(reason) => println!("SKIP {}/{}: {reason}", report.handler, report.case),
+    }
+}
+
+fn main() -> ExitCode {
+    let args = match parse_args() {
+        Ok(args) => args,
+        Err(err) => {
+            if !err.is_empty() {
+                eprintln!("{err}");
+            }
+            eprintln!("{USAGE}");
+            return ExitCode::from(2);
+        }
+    };
+    let dir = match vectors_dir(&args) {
+        Ok(dir) => dir,
+        Err(err) => {
+            eprintln!("{err}\n{USAGE}");
+            return ExitCode::from(2);
+        }
+    };
+
+    let ctx = DASContext::default();
+    let reports = match run_dir(&ctx, &dir, args.filter.as_deref()) {
+        Ok(reports) => reports,
+        Err(err) => {
+            eprintln!("could not read {}: {err}", dir.display());
+            return ExitCode::from(2);
+        }
+    };
+    for report in &reports {
+        print_report(report, args.json);
+    }
+
+    let summary = Summary::from_reports(&reports);
+    if args.json {
+        println!(
+            "{}",
+            serde_json::json!({ "summary": summary, "platform": platform() })
+        );
+    } else {
+        println!(
+            "{} passed, {} failed, {} skipped on {}",
+            summary.passed,
+            summary.failed,
+            summary.skipped,
+            platform()
+        );
+    }
+
+    if summary.failed == 0 {
+        ExitCode::SUCCESS
+    } else {
+        ExitCode::FAILURE
+    }
+}
+
+/// Returns the target the binary was built for, so that reports from different platforms can be told apart.
+fn platform() -> String {
+    format!("{}-{}", std::env::consts::ARCH, std::env::consts::OS)
+}
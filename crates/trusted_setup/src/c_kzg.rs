@@ -0,0 +1,103 @@
+//! Parsing for the text format of the trusted setup used by c-kzg-4844.
+//!
+//! The file starts with the number of G1 points and the number of G2 points, each on
+//! their own line. These are followed by the G1 points in lagrange form, the G2 points in
+//! monomial form and finally the G1 points in monomial form, one hex encoded compressed point
+//! per line and without a `0x` prefix.
+use crate::TrustedSetupJSON;
+
+impl TrustedSetupJSON {
+    /// Parse a string in the c-kzg-4844 `trusted_setup.txt` format.
+    ///
+    /// The lagrange points are skipped, since they are not needed.
+    ///
+    /// Panics if the header is malformed or if the file does not contain as many
+    /// points as the header states. Note: older versions of the format did not include
+    /// the G1 monomial points and are therefore not supported.
+    pub(crate) fn from_c_kzg_txt(txt: &str) -> Self {
+        let mut lines = txt.lines().map(str::trim).filter(|line| !line.is_empty());
+
+        let mut read_count = |name: &str| -> usize {
+            lines
+                .next()
+                .unwrap_or_else(|| panic!("trusted setup is missing the number of {name} points"))
+                .parse()
+                .unwrap_or_else(|_| panic!("trusted setup has a malformed number of {name} points"))
+        };
+        let num_g1_points = read_count("G1");
+        let num_g2_points = read_count("G2");
+
+        let mut read_points = |count: usize, name: &str| -> Vec<String> {
+            let points: Vec<_> = lines
+                .by_ref()
+                .take(count)
+                .map(|line| format!("0x{line}"))
+                .collect();
+            assert_eq!(
+                points.len(),
+                count,
+                "trusted setup has fewer {name} points than stated in its header"
+            );
+            points
+        };
+        let _g1_lagrange = read_points(num_g1_points, "G1 lagrange");
+        let g2_monomial = read_points(num_g2_points, "G2 monomial");
+        let g1_monomial = read_points(num_g1_points, "G1 monomial");
+
+        assert!(
+            lines.next().is_none(),
+            "trusted setup has more points than stated in its header"
+        );
+
+        Self {
+            g1_monomial,
+            g2_monomial,
+        }
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::{TrustedSetup, TRUSTED_SETUP_JSON};
+
+    /// Converts the embedded JSON trusted setup into the c-kzg text format.
+    fn embedded_setup_as_txt() -> String {
+        let json: serde_json::Value = serde_json::from_str(TRUSTED_SETUP_JSON).unwrap();
+        let points = |key: &str| -> Vec<String> {
+            json[key]
+                .as_array()
+                .unwrap()
+                .iter()
+                .map(|point| point.as_str().unwrap().trim_start_matches("0x").to_string())
+                .collect()
+        };
+
+        let g1_lagrange = points("g1_lagrange");
+        let g2_monomial = points("g2_monomial");
+        let g1_monomial = points("g1_monomial");
+
+        let mut lines = vec![g1_monomial.len().to_string(), g2_monomial.len().to_string()];
+        lines.extend(g1_lagrange);
+        lines.extend(g2_monomial);
+        lines.extend(g1_monomial);
+        lines.join("\n") + "\n"
+    }
+
+    #[test]
+    fn c_kzg_txt_matches_json_setup() {
+        let txt = embedded_setup_as_txt();
+        let expected = TrustedSetup::from_json_unchecked(TRUSTED_SETUP_JSON);
+        assert_eq!(TrustedSetup::from_c_kzg_txt(&txt), expected);
+        assert_eq!(TrustedSetup::parse(&txt), expected);
+        assert_eq!(TrustedSetup::parse(TRUSTED_SETUP_JSON), expected);
+    }
+
+    #[test]
+    #[should_panic(expected = "fewer G1 monomial points")]
+    fn c_kzg_txt_without_monomial_points_is_rejected() {
+        // Older versions of the format only contain the lagrange points.
+        let txt = embedded_setup_as_txt();
+        let truncated: Vec<_> = txt.lines().take(2 + 4096 + 65).collect();
+        TrustedSetup::from_c_kzg_txt_unchecked(&truncated.join("\n"));
+    }
+}
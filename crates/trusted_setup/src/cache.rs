@@ -0,0 +1,171 @@
+//! A binary cache format for trusted setups.
+//!
+//! Parsing the JSON setup requires a square root per compressed point and, when checked,
+//! a subgroup check per point. The cache stores the decoded points uncompressed, so that
+//! restart-heavy deployments can parse the setup once and then reload it quickly.
+//!
+//! Layout: magic (8 bytes) || version (u32 LE) || num_g1_points (u64 LE) || num_g2_points (u64 LE)
+//! || g1 points (96 bytes each) || g2 points (192 bytes each) || sha256 of all preceding bytes
+use std::io::{self, Read, Write};
+
+use bls12_381::{G1Point, G2Point};
+use sha2::{Digest, Sha256};
+
+use crate::TrustedSetup;
+
+const MAGIC: &[u8; 8] = b"EKZGSRS\0";
+/// Bumped whenever the cache format changes.
+const VERSION: u32 = 1;
+const HEADER_SIZE: usize = 8 + 4 + 8 + 8;
+const CHECKSUM_SIZE: usize = 32;
+const UNCOMPRESSED_G1_SIZE: usize = 96;
+const UNCOMPRESSED_G2_SIZE: usize = 192;
+
+impl TrustedSetup {
+    /// Writes the setup in the binary cache format, so that it can be reloaded
+    /// with [`TrustedSetup::from_cache`].
+    pub fn write_cache<W: Write>(&self, writer: &mut W) -> io::Result<()> {
+        let mut bytes = Vec::with_capacity(
+            HEADER_SIZE
+                + self.g1_monomial.len() * UNCOMPRESSED_G1_SIZE
+                + self.g2_monomial.len() * UNCOMPRESSED_G2_SIZE
+                + CHECKSUM_SIZE,
+        );
+        bytes.extend_from_slice(MAGIC);
+        bytes.extend_from_slice(&VERSION.to_le_bytes());
+        bytes.extend_from_slice(&(self.g1_monomial.len() as u64).to_le_bytes());
+        bytes.extend_from_slice(&(self.g2_monomial.len() as u64).to_le_bytes());
+        for point in &self.g1_monomial {
+            bytes.extend_from_slice(&point.to_uncompressed());
+        }
+        for point in &self.g2_monomial {
+            bytes.extend_from_slice(&point.to_uncompressed());
+        }
+        let checksum = Sha256::digest(&bytes);
+        bytes.extend_from_slice(&checksum);
+
+        writer.write_all(&bytes)
+    }
+
+    /// Loads a setup written by [`TrustedSetup::write_cache`].
+    ///
+    /// The checksum is verified before any of the points are decoded, which detects a
+    /// truncated or corrupted cache. The points are checked to be on the curve, but they
+    /// are not subgroup checked again, so the cache should only be loaded from a trusted
+    /// location. The checksum does not protect against a cache that was deliberately
+    /// replaced; use [`TrustedSetup::verify`] for that.
+    pub fn from_cache<R: Read>(reader: &mut R) -> io::Result<Self> {
+        let mut bytes = Vec::new();
+        reader.read_to_end(&mut bytes)?;
+
+        if bytes.len() < HEADER_SIZE + CHECKSUM_SIZE {
+            return Err(invalid_data("trusted setup cache is too short"));
+        }
+        let (contents, checksum) = bytes.split_at(bytes.len() - CHECKSUM_SIZE);
+        if Sha256::digest(contents).as_slice() != checksum {
+            return Err(invalid_data("trusted setup cache checksum mismatch"));
+        }
+
+        let (header, points) = contents.split_at(HEADER_SIZE);
+        if &header[..8] != MAGIC {
+            return Err(invalid_data("not a trusted setup cache"));
+        }
+        if header[8..12] != VERSION.to_le_bytes() {
+            return Err(invalid_data("unsupported trusted setup cache version"));
+        }
+        let num_g1_points = read_len(&header[12..20])?;
+        let num_g2_points = read_len(&header[20..28])?;
+
+        let g1_size = num_g1_points
+            .checked_mul(UNCOMPRESSED_G1_SIZE)
+            .ok_or_else(|| invalid_data("trusted setup cache has too many points"))?;
+        let g2_size = num_g2_points
+            .checked_mul(UNCOMPRESSED_G2_SIZE)
+            .ok_or_else(|| invalid_data("trusted setup cache has too many points"))?;
+        if g1_size.checked_add(g2_size) != Some(points.len()) {
+            return Err(invalid_data(
+                "trusted setup cache length does not match its header",
+            ));
+        }
+        let (g1_bytes, g2_bytes) = points.split_at(g1_size);
+
+        let g1_monomial = g1_bytes
+            .chunks_exact(UNCOMPRESSED_G1_SIZE)
+            .map(|chunk| {
+                let chunk = chunk.try_into().expect("chunk has the size of a G1 point");
+                Option::from(G1Point::from_uncompressed_unchecked(chunk))
+                    .ok_or_else(|| invalid_data("invalid G1 point in trusted setup cache"))
+            })
+            .collect::<io::Result<_>>()?;
+        let g2_monomial = g2_bytes
+            .chunks_exact(UNCOMPRESSED_G2_SIZE)
+            .map(|chunk| {
+                let chunk = chunk.try_into().expect("chunk has the size of a G2 point");
+                Option::from(G2Point::from_uncompressed_unchecked(chunk))
+                    .ok_or_else(|| invalid_data("invalid G2 point in trusted setup cache"))
+            })
+            .collect::<io::Result<_>>()?;
+
+        Ok(Self {
+            g1_monomial,
+            g2_monomial,
+        })
+    }
+
+    /// Returns true if `bytes` start like a trusted setup cache.
+    pub(crate) fn is_cache(bytes: &[u8]) -> bool {
+        bytes.starts_with(MAGIC)
+    }
+
+    /// Writes the setup in the binary cache format to the file at `path`.
+    pub fn write_cache_file(&self, path: impl AsRef<std::path::Path>) -> io::Result<()> {
+        let mut writer = io::BufWriter::new(std::fs::File::create(path)?);
+        self.write_cache(&mut writer)?;
+        writer.flush()
+    }
+
+    /// Loads a setup from a cache file written by [`TrustedSetup::write_cache_file`].
+    pub fn from_cache_file(path: impl AsRef<std::path::Path>) -> io::Result<Self> {
+        Self::from_cache(&mut std::fs::File::open(path)?)
+    }
+}
+
+/// Decodes a little endian length from the header.
+fn read_len(bytes: &[u8]) -> io::Result<usize> {
+    let len = u64::from_le_bytes(bytes.try_into().expect("lengths are 8 bytes"));
+    usize::try_from(len).map_err(|_| invalid_data("length does not fit in usize"))
+}
+
+fn invalid_data(msg: &'static str) -> io::Error {
+    io::Error::new(io::ErrorKind::InvalidData, msg)
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::TrustedSetup;
+
+    #[test]
+    fn cache_roundtrip() {
+        let setup = TrustedSetup::default();
+
+        let mut bytes = Vec::new();
+        setup.write_cache(&mut bytes).unwrap();
+        let loaded = TrustedSetup::from_cache(&mut bytes.as_slice()).unwrap();
+
+        assert_eq!(loaded, setup);
+    }
+
+    #[test]
+    fn corrupted_cache_is_rejected() {
+        let setup = TrustedSetup::default();
+        let mut bytes = Vec::new();
+        setup.write_cache(&mut bytes).unwrap();
+
+        let mut corrupted = bytes.clone();
+        corrupted[100] ^= 1;
+        assert!(TrustedSetup::from_cache(&mut corrupted.as_slice()).is_err());
+
+        let truncated = &bytes[..bytes.len() - 1];
+        assert!(TrustedSetup::from_cache(&mut &truncated[..]).is_err());
+    }
+}
@@ -0,0 +1,128 @@
+//! Parsing for the transcript produced by the Ethereum KZG ceremony.
+//!
+//! The transcript contains one set of powers of tau for each of the ceremony's sub-ceremonies,
+//! which differ in the number of G1 powers (4096, 8192, 16384 and 32768). The powers are the
+//! monomial points, so they can be used directly without any conversion.
+//!
+//! The transcript is located here: https://github.com/ethereum/kzg-ceremony-sequencer
+use serde::Deserialize;
+
+use crate::TrustedSetupJSON;
+
+/// The output transcript of the Ethereum KZG ceremony.
+///
+/// Only the fields needed to extract the powers of tau are parsed; the witness
+/// and the participant signatures are skipped.
+#[derive(Deserialize, Debug)]
+struct CeremonyTranscript {
+    transcripts: Vec<SubCeremonyTranscript>,
+}
+
+/// The transcript of a single sub-ceremony.
+#[derive(Deserialize, Debug)]
+#[serde(rename_all = "camelCase")]
+struct SubCeremonyTranscript {
+    num_g1_powers: usize,
+    num_g2_powers: usize,
+    powers_of_tau: PowersOfTau,
+}
+
+/// Hex encoded, compressed powers of tau in G1 and G2.
+#[derive(Deserialize, Debug)]
+struct PowersOfTau {
+    #[serde(rename = "G1Powers")]
+    g1_powers: Vec<String>,
+    #[serde(rename = "G2Powers")]
+    g2_powers: Vec<String>,
+}
+
+impl TrustedSetupJSON {
+    /// Extract the powers of tau for the sub-ceremony with `num_g1_powers` G1 powers from
+    /// the ceremony transcript.
+    ///
+    /// Panics if the transcript cannot be parsed, if there is no sub-ceremony with
+    /// `num_g1_powers` G1 powers or if the sub-ceremony does not have as many points as it states.
+    pub(crate) fn from_ceremony_transcript(json: &str, num_g1_powers: usize) -> Self {
+        let transcript: CeremonyTranscript = serde_json::from_str(json)
+            .expect("could not parse json string into a ceremony transcript");
+
+        let sub_ceremony = transcript
+            .transcripts
+            .into_iter()
+            .find(|transcript| transcript.num_g1_powers == num_g1_powers)
+            .unwrap_or_else(|| {
+                panic!("ceremony transcript has no sub-ceremony with {num_g1_powers} G1 powers")
+            });
+
+        let PowersOfTau {
+            g1_powers,
+            g2_powers,
+        } = sub_ceremony.powers_of_tau;
+        assert_eq!(
+            g1_powers.len(),
+            sub_ceremony.num_g1_powers,
+            "ceremony transcript has an unexpected number of G1 powers"
+        );
+        assert_eq!(
+            g2_powers.len(),
+            sub_ceremony.num_g2_powers,
+            "ceremony transcript has an unexpected number of G2 powers"
+        );
+
+        Self {
+            g1_monomial: g1_powers,
+            g2_monomial: g2_powers,
+        }
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::{TrustedSetup, TRUSTED_SETUP_JSON};
+
+    /// Creates a ceremony transcript whose 4096 sub-ceremony contains the embedded setup.
+    fn embedded_setup_as_transcript() -> String {
+        let json: serde_json::Value = serde_json::from_str(TRUSTED_SETUP_JSON).unwrap();
+        let g1_powers = &json["g1_monomial"];
+        let g2_powers = &json["g2_monomial"];
+
+        let transcript = serde_json::json!({
+            "transcripts": [
+                {
+                    "numG1Powers": 1,
+                    "numG2Powers": 1,
+                    "powersOfTau": {
+                        "G1Powers": [g1_powers[0]],
+                        "G2Powers": [g2_powers[0]],
+                    },
+                    "witness": { "runningProducts": [], "potPubkeys": [], "blsSignatures": [] },
+                },
+                {
+                    "numG1Powers": 4096,
+                    "numG2Powers": 65,
+                    "powersOfTau": { "G1Powers": g1_powers, "G2Powers": g2_powers },
+                    "witness": { "runningProducts": [], "potPubkeys": [], "blsSignatures": [] },
+                },
+            ],
+            "participantIds": [],
+            "participantEcdsaSignatures": [],
+        });
+        transcript.to_string()
+    }
+
+    #[test]
+    fn ceremony_transcript_matches_json_setup() {
+        let transcript = embedded_setup_as_transcript();
+        assert_eq!(
+            TrustedSetup::from_ceremony_transcript(&transcript, 4096),
+            TrustedSetup::from_json_unchecked(TRUSTED_SETUP_JSON)
+        );
+    }
+
+    #[test]
+    #[should_panic(expected = "no sub-ceremony with 8192 G1 powers")]
+    fn ceremony_transcript_missing_sub_ceremony() {
+        let transcript = embedded_setup_as_transcript();
+        TrustedSetup::from_ceremony_transcript_unchecked(&transcript, 8192);
+    }
+}
@@ -0,0 +1,156 @@
+//! Loading the trusted setup from a path, an environment variable or a default location.
+//!
+//! This lets operators swap the trusted setup without recompiling or changing code in
+//! every binding. The lookup order used by [`TrustedSetup::from_env`] is:
+//!
+//! 1. The file named by the `ETH_KZG_TRUSTED_SETUP` environment variable, if it is set.
+//! 2. The first file that exists in [`TrustedSetup::default_paths`].
+//! 3. The embedded mainnet setup, unless the `no-embedded-setup` feature is enabled.
+use std::{
+    io,
+    path::{Path, PathBuf},
+};
+
+use crate::TrustedSetup;
+
+/// The environment variable that holds the path of the trusted setup file to load.
+pub const TRUSTED_SETUP_ENV_VAR: &str = "ETH_KZG_TRUSTED_SETUP";
+
+/// The name of the trusted setup file in the default locations.
+const TRUSTED_SETUP_FILE_NAME: &str = "trusted_setup.json";
+
+/// The name of the directory that holds the trusted setup file in the default locations.
+const TRUSTED_SETUP_DIR_NAME: &str = "eth-kzg";
+
+impl TrustedSetup {
+    /// Loads a trusted setup from the file at `path`.
+    ///
+    /// The file may be in any of the formats that this crate can read: the JSON format
+    /// used by the consensus specs, the c-kzg-4844 text format or the binary cache format
+    /// written by [`TrustedSetup::write_cache`]. The format is detected from the contents.
+    ///
+    /// Panics if the file is a JSON or text setup with malformed points, like the other parsers.
+    pub fn from_path(path: impl AsRef<Path>) -> io::Result<Self> {
+        let bytes = std::fs::read(path)?;
+        if Self::is_cache(&bytes) {
+            return Self::from_cache(&mut bytes.as_slice());
+        }
+        let contents = std::str::from_utf8(&bytes).map_err(|_| {
+            io::Error::new(
+                io::ErrorKind::InvalidData,
+                "trusted setup file is neither valid UTF-8 nor a trusted setup cache",
+            )
+        })?;
+        Ok(Self::parse(contents))
+    }
+
+    /// Loads a trusted setup from the first of these that is available:
+    ///
+    /// 1. The file named by the [`TRUSTED_SETUP_ENV_VAR`] environment variable.
+    /// 2. The first file that exists in [`TrustedSetup::default_paths`].
+    /// 3. The embedded mainnet setup, unless the `no-embedded-setup` feature is enabled.
+    ///
+    /// Returns an error if the environment variable is set but the file cannot be read,
+    /// or if no setup was found and the `no-embedded-setup` feature is enabled.
+    pub fn from_env() -> io::Result<Self> {
+        if let Some(path) = std::env::var_os(TRUSTED_SETUP_ENV_VAR) {
+            return Self::from_path(path);
+        }
+
+        if let Some(path) = Self::default_paths()
+            .into_iter()
+            .find(|path| path.is_file())
+        {
+            return Self::from_path(path);
+        }
+
+        #[cfg(not(feature = "no-embedded-setup"))]
+        {
+            Ok(Self::default())
+        }
+
+        #[cfg(feature = "no-embedded-setup")]
+        {
+            Err(io::Error::new(
+                io::ErrorKind::NotFound,
+                format!(
+                    "no trusted setup found: set {TRUSTED_SETUP_ENV_VAR} or place {TRUSTED_SETUP_FILE_NAME} in one of the default locations"
+                ),
+            ))
+        }
+    }
+
+    /// Returns the default locations of the trusted setup file on this platform, in the
+    /// order in which they are searched.
+    ///
+    /// - Linux and other unix platforms: `$XDG_DATA_HOME/eth-kzg/trusted_setup.json`
+    ///   (defaulting to `~/.local/share`), then `/usr/local/share/eth-kzg/trusted_setup.json`
+    ///   and `/usr/share/eth-kzg/trusted_setup.json`.
+    /// - macOS: `~/Library/Application Support/eth-kzg/trusted_setup.json`, then the unix locations.
+    /// - Windows: `%APPDATA%\eth-kzg\trusted_setup.json`, then `%PROGRAMDATA%\eth-kzg\trusted_setup.json`.
+    pub fn default_paths() -> Vec<PathBuf> {
+        let env_dir = |name: &str| {
+            std::env::var_os(name)
+                .filter(|value| !value.is_empty())
+                .map(PathBuf::from)
+        };
+
+        let mut dirs = Vec::new();
+        if cfg!(windows) {
+            dirs.extend(env_dir("APPDATA"));
+            dirs.extend(env_dir("PROGRAMDATA"));
+        } else {
+            let home = env_dir("HOME");
+            if cfg!(target_os = "macos") {
+                dirs.extend(
+                    home.as_ref()
+                        .map(|home| home.join("Library").join("Application Support")),
+                );
+            }
+            dirs.extend(
+                env_dir("XDG_DATA_HOME")
+                    .or_else(|| home.map(|home| home.join(".local").join("share"))),
+            );
+            dirs.push(PathBuf::from("/usr/local/share"));
+            dirs.push(PathBuf::from("/usr/share"));
+        }
+
+        dirs.into_iter()
+            .map(|dir| {
+                dir.join(TRUSTED_SETUP_DIR_NAME)
+                    .join(TRUSTED_SETUP_FILE_NAME)
+            })
+            .collect()
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use crate::{TrustedSetup, TRUSTED_SETUP_JSON};
+
+    #[test]
+    fn from_path_detects_the_format() {
+        let dir = std::env::temp_dir().join(format!("ekzg-setup-{}", std::process::id()));
+        std::fs::create_dir_all(&dir).unwrap();
+        let expected = TrustedSetup::from_json_unchecked(TRUSTED_SETUP_JSON);
+
+        let json_path = dir.join("trusted_setup.json");
+        std::fs::write(&json_path, TRUSTED_SETUP_JSON).unwrap();
+        assert_eq!(TrustedSetup::from_path(&json_path).unwrap(), expected);
+
+        let cache_path = dir.join("trusted_setup.bin");
+        expected.write_cache_file(&cache_path).unwrap();
+        assert_eq!(TrustedSetup::from_path(&cache_path).unwrap(), expected);
+
+        assert!(TrustedSetup::from_path(dir.join("missing.json")).is_err());
+
+        std::fs::remove_dir_all(dir).unwrap();
+    }
+
+    #[test]
+    fn default_paths_end_with_file_name() {
+        for path in TrustedSetup::default_paths() {
+            assert!(path.ends_with("eth-kzg/trusted_setup.json"));
+        }
+    }
+}
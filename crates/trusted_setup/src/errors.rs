@@ -0,0 +1,27 @@
+/// Errors that can occur while validating a trusted setup.
+#[derive(Debug)]
+pub enum TrustedSetupError {
+    /// The setup does not have enough points to check that they are powers of tau.
+    NotEnoughPoints {
+        /// Number of G1 points in the setup.
+        num_g1_points: usize,
+        /// Number of G2 points in the setup.
+        num_g2_points: usize,
+    },
+    /// The first G1 or G2 point is not the generator of its group.
+    FirstPointIsNotGenerator,
+    /// A G1 point is not in the prime order subgroup.
+    G1PointNotInSubgroup {
+        /// Position of the point in the G1 monomial points.
+        index: usize,
+    },
+    /// A G2 point is not in the prime order subgroup.
+    G2PointNotInSubgroup {
+        /// Position of the point in the G2 monomial points.
+        index: usize,
+    },
+    /// The G1 points are not successive powers of the same secret.
+    InconsistentG1Powers,
+    /// The G2 points are not successive powers of the same secret as the G1 points.
+    InconsistentG2Powers,
+}
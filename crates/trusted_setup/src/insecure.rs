@@ -0,0 +1,88 @@
+//! Generation of insecure trusted setups for testing.
+//!
+//! The secret `tau` of these setups is derived from a public seed, so anyone can forge
+//! proofs for them. They must never be used outside of tests and tooling.
+
+use bls12_381::{
+    g1_batch_normalize, g2_batch_normalize, reduce_bytes_to_scalar_bias, traits::*, G1Projective,
+    G2Projective, Scalar,
+};
+use sha2::{Digest, Sha256};
+
+use crate::TrustedSetup;
+
+/// The number of G2 points in a generated setup.
+///
+/// This matches the number of G2 points in the mainnet setup.
+const NUM_G2_POINTS: usize = 65;
+
+impl TrustedSetup {
+    /// Creates a setup with `size` G1 points and 65 G2 points, whose secret is derived from `seed`.
+    ///
+    /// The same seed and size always produce the same setup, so test suites and spec tooling
+    /// can create self-consistent setups of arbitrary size without shipping fixtures.
+    ///
+    /// This is INSECURE: the secret can be recomputed from the seed, which allows proofs for
+    /// false statements to be created. It is only available with the `insecure-setup` feature.
+    ///
+    /// # Panics
+    /// Panics if `size` is zero.
+    pub fn insecure_from_seed(seed: &[u8], size: usize) -> Self {
+        assert!(size > 0, "a trusted setup needs at least one G1 point");
+
+        let mut hasher = Sha256::new();
+        hasher.update(b"EKZG_INSECURE_TRUSTED_SETUP_V1");
+        hasher.update(seed);
+        let tau = reduce_bytes_to_scalar_bias(hasher.finalize().into());
+
+        let tau_powers: Vec<_> =
+            std::iter::successors(Some(Scalar::ONE), |power| Some(power * tau))
+                .take(size.max(NUM_G2_POINTS))
+                .collect();
+
+        let g1_monomial: Vec<_> = tau_powers[..size]
+            .iter()
+            .map(|power| G1Projective::generator() * power)
+            .collect();
+        let g2_monomial: Vec<_> = tau_powers[..NUM_G2_POINTS]
+            .iter()
+            .map(|power| G2Projective::generator() * power)
+            .collect();
+
+        Self {
+            g1_monomial: g1_batch_normalize(&g1_monomial),
+            g2_monomial: g2_batch_normalize(&g2_monomial),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use crate::TrustedSetup;
+
+    #[test]
+    fn insecure_setup_passes_verification() {
+        let setup = TrustedSetup::insecure_from_seed(b"seed", 64);
+        assert_eq!(setup.g1_monomial.len(), 64);
+        assert_eq!(setup.g2_monomial.len(), 65);
+        setup.verify().expect("generated setup should be valid");
+    }
+
+    #[test]
+    fn insecure_setup_is_deterministic() {
+        assert_eq!(
+            TrustedSetup::insecure_from_seed(b"seed", 16),
+            TrustedSetup::insecure_from_seed(b"seed", 16)
+        );
+        assert_ne!(
+            TrustedSetup::insecure_from_seed(b"seed", 16),
+            TrustedSetup::insecure_from_seed(b"other seed", 16)
+        );
+
+        // A smaller setup is a prefix of a larger one with the same seed
+        let small = TrustedSetup::insecure_from_seed(b"seed", 16);
+        let large = TrustedSetup::insecure_from_seed(b"seed", 128);
+        assert_eq!(small.g1_monomial[..], large.g1_monomial[..16]);
+        assert_eq!(small.g2_monomial, large.g2_monomial);
+    }
+}
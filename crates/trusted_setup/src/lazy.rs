@@ -0,0 +1,132 @@
+use std::sync::OnceLock;
+
+use bls12_381::{G1Point, G2Point};
+use serialization::trusted_setup::{deserialize_g1_points, deserialize_g2_points, SubgroupCheck};
+
+use crate::{TrustedSetup, TrustedSetupJSON};
+
+/// A trusted setup whose points are only decoded when they are first accessed.
+///
+/// Decompressing and subgroup-checking the 4096 G1 points of the mainnet setup is the
+/// bulk of the cost of loading it. This type only parses the hex strings up front and
+/// decodes the G1 and G2 points independently, the first time each of them is needed.
+/// For example, a process that never touches the G2 points never pays for decoding them.
+#[derive(Debug)]
+pub struct LazyTrustedSetup {
+    /// The hex encoded points of the setup.
+    encoded: TrustedSetupJSON,
+    /// Whether the points are checked to be in the correct subgroup when they are decoded.
+    subgroup_check: SubgroupCheck,
+    /// The decoded G1 points, once they have been accessed.
+    g1_monomial: OnceLock<Vec<G1Point>>,
+    /// The decoded G2 points, once they have been accessed.
+    g2_monomial: OnceLock<Vec<G2Point>>,
+}
+
+impl LazyTrustedSetup {
+    fn new(encoded: TrustedSetupJSON, subgroup_check: SubgroupCheck) -> Self {
+        Self {
+            encoded,
+            subgroup_check,
+            g1_monomial: OnceLock::new(),
+            g2_monomial: OnceLock::new(),
+        }
+    }
+
+    /// Parse a Json string in the format specified by the ethereum trusted setup.
+    ///
+    /// The points are checked to be in the correct subgroup when they are first accessed.
+    /// See [`TrustedSetup::from_json`] for the format.
+    pub fn from_json(json: &str) -> Self {
+        Self::new(
+            TrustedSetupJSON::from_json_unchecked(json),
+            SubgroupCheck::Check,
+        )
+    }
+
+    /// Parse a Json string in the format specified by the ethereum trusted setup.
+    ///
+    /// This method does not check that the points are in the correct subgroup.
+    pub fn from_json_unchecked(json: &str) -> Self {
+        Self::new(
+            TrustedSetupJSON::from_json_unchecked(json),
+            SubgroupCheck::NoCheck,
+        )
+    }
+
+    /// Returns the G1 points of the setup, decoding them on first use.
+    ///
+    /// Panics if any of the points are malformed.
+    pub fn g1_monomial(&self) -> &[G1Point] {
+        self.g1_monomial
+            .get_or_init(|| deserialize_g1_points(&self.encoded.g1_monomial, self.subgroup_check))
+    }
+
+    /// Returns the G2 points of the setup, decoding them on first use.
+    ///
+    /// Panics if any of the points are malformed.
+    pub fn g2_monomial(&self) -> &[G2Point] {
+        self.g2_monomial
+            .get_or_init(|| deserialize_g2_points(&self.encoded.g2_monomial, self.subgroup_check))
+    }
+
+    /// Decodes all of the points and returns the resulting `TrustedSetup`.
+    pub fn to_trusted_setup(&self) -> TrustedSetup {
+        TrustedSetup {
+            g1_monomial: self.g1_monomial().to_vec(),
+            g2_monomial: self.g2_monomial().to_vec(),
+        }
+    }
+}
+
+/// Returns the mainnet trusted setup, without decoding any of its points.
+#[cfg(not(feature = "no-embedded-setup"))]
+impl Default for LazyTrustedSetup {
+    fn default() -> Self {
+        // We have a test that checks the embedded trusted setup is well-formed.
+        Self::new(TrustedSetupJSON::from_embed(), SubgroupCheck::NoCheck)
+    }
+}
+
+impl From<LazyTrustedSetup> for TrustedSetup {
+    fn from(setup: LazyTrustedSetup) -> Self {
+        // Decode whichever points have not been accessed yet
+        setup.g1_monomial();
+        setup.g2_monomial();
+        Self {
+            g1_monomial: setup.g1_monomial.into_inner().unwrap_or_default(),
+            g2_monomial: setup.g2_monomial.into_inner().unwrap_or_default(),
+        }
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
+mod tests {
+    use super::LazyTrustedSetup;
+    use crate::{TrustedSetup, TRUSTED_SETUP_JSON};
+
+    #[test]
+    fn lazy_setup_matches_eager_setup() {
+        let lazy = LazyTrustedSetup::from_json(TRUSTED_SETUP_JSON);
+        let eager = TrustedSetup::from_json(TRUSTED_SETUP_JSON);
+
+        assert_eq!(lazy.g2_monomial(), eager.g2_monomial);
+        assert_eq!(lazy.to_trusted_setup(), eager);
+        assert_eq!(TrustedSetup::from(lazy), eager);
+        assert_eq!(
+            TrustedSetup::from(LazyTrustedSetup::default()),
+            TrustedSetup::default()
+        );
+    }
+
+    #[test]
+    fn lazy_setup_only_decodes_accessed_points() {
+        let lazy = LazyTrustedSetup::default();
+        assert!(lazy.g1_monomial.get().is_none());
+        assert!(lazy.g2_monomial.get().is_none());
+
+        lazy.g2_monomial();
+        assert!(lazy.g1_monomial.get().is_none());
+        assert!(lazy.g2_monomial.get().is_some());
+    }
+}
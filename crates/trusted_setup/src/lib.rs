@@ -1,7 +1,29 @@
-use bls12_381::{G1Point, G2Point};
+use bls12_381::{
+    g1_batch_normalize, g2_batch_normalize,
+    lincomb::{g1_lincomb, g2_lincomb},
+    multi_pairings, reduce_bytes_to_scalar_bias,
+    traits::*,
+    G1Point, G2Point, G2Prepared, Scalar,
+};
 use serde::Deserialize;
 use serialization::trusted_setup::{deserialize_g1_points, deserialize_g2_points, SubgroupCheck};
+use sha2::{Digest, Sha256};
 
+mod c_kzg;
+mod cache;
+mod ceremony;
+mod env;
+mod errors;
+#[cfg(feature = "insecure-setup")]
+mod insecure;
+mod lazy;
+
+pub use env::TRUSTED_SETUP_ENV_VAR;
+pub use errors::TrustedSetupError;
+pub use lazy::LazyTrustedSetup;
+
+/// The mainnet trusted setup, embedded into the binary unless the `no-embedded-setup` feature is enabled.
+#[cfg(not(feature = "no-embedded-setup"))]
 const TRUSTED_SETUP_JSON: &str = include_str!("../data/trusted_setup_4096.json");
 
 /// Represents an Ethereum trusted setup used for KZG commitments on the BLS12-381 curve.
@@ -38,6 +60,10 @@ impl TrustedSetupJSON {
     /// Converts the `TrustedSetupJSON` into a `TrustedSetup` with subgroup checks.
     ///
     /// Panics if any of the points are not in the correct subgroup
+    #[cfg_attr(
+        feature = "tracing",
+        tracing::instrument(skip_all, fields(num_g1_points = self.g1_monomial.len()))
+    )]
     fn to_trusted_setup(&self) -> TrustedSetup {
         let g1_monomial = deserialize_g1_points(&self.g1_monomial, SubgroupCheck::Check);
         let g2_monomial = deserialize_g2_points(&self.g2_monomial, SubgroupCheck::Check);
@@ -52,6 +78,10 @@ impl TrustedSetupJSON {
     /// Panics if:
     ///     - The hex string does not start with 0x
     ///     - The hex string does not represent a valid point in the G1/G2 group
+    #[cfg_attr(
+        feature = "tracing",
+        tracing::instrument(skip_all, fields(num_g1_points = self.g1_monomial.len()))
+    )]
     fn to_trusted_setup_unchecked(&self) -> TrustedSetup {
         let g1_monomial = deserialize_g1_points(&self.g1_monomial, SubgroupCheck::NoCheck);
         let g2_monomial = deserialize_g2_points(&self.g2_monomial, SubgroupCheck::NoCheck);
@@ -64,6 +94,7 @@ impl TrustedSetupJSON {
     /// Parse a JSON string in the format specified by the ethereum trusted setup.
     ///
     /// This method does not check that the points are in the correct subgroup.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
     fn from_json_unchecked(json: &str) -> Self {
         // Note: it is fine to panic here since this method is called on startup
         // and we want to fail fast if the trusted setup is malformed.
@@ -72,11 +103,27 @@ impl TrustedSetupJSON {
     }
 
     /// Loads the official trusted setup file being used on mainnet from the embedded data folder.
+    ///
+    /// With the `testing` feature, only the first `FIELD_ELEMENTS_PER_BLOB` G1 points are kept,
+    /// matching the reduced blob size. Truncating a setup keeps it valid, since the remaining
+    /// points are still successive powers of the same secret.
+    #[cfg(not(feature = "no-embedded-setup"))]
     fn from_embed() -> Self {
-        Self::from_json_unchecked(TRUSTED_SETUP_JSON)
+        #[allow(unused_mut)]
+        let mut setup = Self::from_json_unchecked(TRUSTED_SETUP_JSON);
+        #[cfg(feature = "testing")]
+        setup
+            .g1_monomial
+            .truncate(serialization::constants::FIELD_ELEMENTS_PER_BLOB);
+        setup
     }
 }
 
+/// Returns the mainnet trusted setup.
+///
+/// This is not available when the `no-embedded-setup` feature is enabled; the setup
+/// must then be supplied at runtime, for example through [`TrustedSetup::parse`].
+#[cfg(not(feature = "no-embedded-setup"))]
 impl Default for TrustedSetup {
     fn default() -> Self {
         let trusted_setup_json = TrustedSetupJSON::from_embed();
@@ -121,9 +168,162 @@ impl TrustedSetup {
         let trusted_setup = TrustedSetupJSON::from_json_unchecked(json);
         trusted_setup.to_trusted_setup_unchecked()
     }
+
+    /// Parse a string in the `trusted_setup.txt` format used by c-kzg-4844.
+    ///
+    /// The file being used on mainnet is located here: https://github.com/ethereum/c-kzg-4844/blob/main/src/trusted_setup.txt
+    ///
+    // The format starts with the number of G1 and G2 points, followed by the G1 lagrange points,
+    // the G2 monomial points and the G1 monomial points, hex encoded without a `0x` prefix:
+    /*
+    4096
+    65
+    a0413c0dcafec6dbc9f47d66785cf1e8c981044f7d13cfe3e4fcbb71b5408dfde6312493cb3c1d30516cb3ca88c03654
+    ...
+    */
+    /// Note: That we do not need the g1_lagrange points so they are skipped.
+    pub fn from_c_kzg_txt(txt: &str) -> Self {
+        let trusted_setup = TrustedSetupJSON::from_c_kzg_txt(txt);
+        trusted_setup.to_trusted_setup()
+    }
+
+    /// Parse a string in the `trusted_setup.txt` format used by c-kzg-4844.
+    ///
+    /// This method does not check that the points are in the correct subgroup.
+    pub fn from_c_kzg_txt_unchecked(txt: &str) -> Self {
+        let trusted_setup = TrustedSetupJSON::from_c_kzg_txt(txt);
+        trusted_setup.to_trusted_setup_unchecked()
+    }
+
+    /// Load a trusted setup from the output transcript of the Ethereum KZG ceremony.
+    ///
+    /// The transcript contains the powers of tau for multiple sub-ceremonies; `num_g1_powers`
+    /// selects which of them to use. The setup being used on mainnet corresponds to `4096`.
+    pub fn from_ceremony_transcript(json: &str, num_g1_powers: usize) -> Self {
+        let trusted_setup = TrustedSetupJSON::from_ceremony_transcript(json, num_g1_powers);
+        trusted_setup.to_trusted_setup()
+    }
+
+    /// Load a trusted setup from the output transcript of the Ethereum KZG ceremony.
+    ///
+    /// This method does not check that the points are in the correct subgroup.
+    pub fn from_ceremony_transcript_unchecked(json: &str, num_g1_powers: usize) -> Self {
+        let trusted_setup = TrustedSetupJSON::from_ceremony_transcript(json, num_g1_powers);
+        trusted_setup.to_trusted_setup_unchecked()
+    }
+
+    /// Parse a trusted setup in either the JSON format or the c-kzg-4844 text format.
+    ///
+    /// The format is detected from the contents: JSON files start with `{`, whereas
+    /// text files start with the number of G1 points.
+    pub fn parse(contents: &str) -> Self {
+        if contents.trim_start().starts_with('{') {
+            Self::from_json(contents)
+        } else {
+            Self::from_c_kzg_txt(contents)
+        }
+    }
 }
 
-#[cfg(test)]
+impl TrustedSetup {
+    /// Checks that the setup is well-formed.
+    ///
+    /// This checks that:
+    /// - The first G1 and G2 points are the generators of their groups.
+    /// - All of the points are in the prime order subgroup.
+    /// - The G1 points are of the form `[tau^i]_1` and the G2 points are of the form `[tau^i]_2`,
+    ///   for the same `tau`.
+    ///
+    /// The last check is done with a randomized pairing check on a random linear combination
+    /// of successive points, where the randomness is derived from the setup itself.
+    ///
+    /// This is relatively expensive, so it is only needed when loading a setup from a source
+    /// that is not trusted, such as a third party file. The embedded setup does not need it.
+    #[cfg_attr(feature = "tracing", tracing::instrument(skip_all))]
+    pub fn verify(&self) -> Result<(), TrustedSetupError> {
+        let num_g1_points = self.g1_monomial.len();
+        let num_g2_points = self.g2_monomial.len();
+        if num_g1_points < 2 || num_g2_points < 2 {
+            return Err(TrustedSetupError::NotEnoughPoints {
+                num_g1_points,
+                num_g2_points,
+            });
+        }
+
+        if self.g1_monomial[0] != G1Point::generator()
+            || self.g2_monomial[0] != G2Point::generator()
+        {
+            return Err(TrustedSetupError::FirstPointIsNotGenerator);
+        }
+
+        if let Some(index) = self
+            .g1_monomial
+            .iter()
+            .position(|point| !bool::from(point.is_torsion_free()))
+        {
+            return Err(TrustedSetupError::G1PointNotInSubgroup { index });
+        }
+        if let Some(index) = self
+            .g2_monomial
+            .iter()
+            .position(|point| !bool::from(point.is_torsion_free()))
+        {
+            return Err(TrustedSetupError::G2PointNotInSubgroup { index });
+        }
+
+        // Derive the randomness used to combine the points from the points themselves.
+        let mut hasher = Sha256::new();
+        hasher.update(b"EKZG_TRUSTED_SETUP_VERIFY_V1");
+        for point in &self.g1_monomial {
+            hasher.update(point.to_compressed());
+        }
+        for point in &self.g2_monomial {
+            hasher.update(point.to_compressed());
+        }
+        let r = reduce_bytes_to_scalar_bias(hasher.finalize().into());
+        let r_powers: Vec<_> = std::iter::successors(Some(Scalar::ONE), |power| Some(power * r))
+            .take(num_g1_points.max(num_g2_points) - 1)
+            .collect();
+
+        let g1_gen = &self.g1_monomial[0];
+        let g2_gen = G2Prepared::from(self.g2_monomial[0]);
+        let g2_tau = G2Prepared::from(self.g2_monomial[1]);
+
+        // Check that e(\sum r^i [tau^{i+1}]_1, [1]_2) == e(\sum r^i [tau^i]_1, [tau]_2)
+        let combine_g1 = |points: &[G1Point]| {
+            g1_lincomb(points, &r_powers[..points.len()])
+                .expect("number of points and scalars should be equal")
+        };
+        let g1_shifted = combine_g1(&self.g1_monomial[1..]);
+        let g1_unshifted = combine_g1(&self.g1_monomial[..num_g1_points - 1]);
+        let g1_sums = g1_batch_normalize(&[g1_shifted, -g1_unshifted]);
+        if !multi_pairings(&[(&g1_sums[0], &g2_gen), (&g1_sums[1], &g2_tau)]) {
+            return Err(TrustedSetupError::InconsistentG1Powers);
+        }
+
+        // Check that e([1]_1, \sum r^i [tau^{i+1}]_2) == e([tau]_1, \sum r^i [tau^i]_2)
+        //
+        // Since the G1 points have been checked, this ties the G2 points to the same `tau`.
+        let combine_g2 = |points: &[G2Point]| {
+            g2_lincomb(points, &r_powers[..points.len()])
+                .expect("number of points and scalars should be equal")
+        };
+        let g2_shifted = combine_g2(&self.g2_monomial[1..]);
+        let g2_unshifted = combine_g2(&self.g2_monomial[..num_g2_points - 1]);
+        let g2_sums = g2_batch_normalize(&[g2_shifted, g2_unshifted]);
+        let neg_g1_tau = -self.g1_monomial[1];
+        if !multi_pairings(&[
+            (g1_gen, &G2Prepared::from(g2_sums[0])),
+            (&neg_g1_tau, &G2Prepared::from(g2_sums[1])),
+        ]) {
+            return Err(TrustedSetupError::InconsistentG2Powers);
+        }
+
+        Ok(())
+    }
+}
+
+#[cfg(all(test, not(feature = "no-embedded-setup")))]
 mod tests {
     use super::*;
 
@@ -132,4 +332,42 @@ mod tests {
         let setup = TrustedSetupJSON::from_embed();
         setup.to_trusted_setup();
     }
+
+    #[test]
+    fn test_embedded_setup_passes_verification() {
+        TrustedSetup::default()
+            .verify()
+            .expect("embedded setup should be valid");
+    }
+
+    #[test]
+    fn test_tampered_setup_fails_verification() {
+        let mut setup = TrustedSetup::default();
+        setup.g1_monomial.swap(10, 11);
+        assert!(matches!(
+            setup.verify(),
+            Err(TrustedSetupError::InconsistentG1Powers)
+        ));
+
+        let mut setup = TrustedSetup::default();
+        setup.g2_monomial.swap(10, 11);
+        assert!(matches!(
+            setup.verify(),
+            Err(TrustedSetupError::InconsistentG2Powers)
+        ));
+
+        let mut setup = TrustedSetup::default();
+        setup.g1_monomial[0] = setup.g1_monomial[1];
+        assert!(matches!(
+            setup.verify(),
+            Err(TrustedSetupError::FirstPointIsNotGenerator)
+        ));
+
+        let mut setup = TrustedSetup::default();
+        setup.g2_monomial.truncate(1);
+        assert!(matches!(
+            setup.verify(),
+            Err(TrustedSetupError::NotEnoughPoints { .. })
+        ));
+    }
 }
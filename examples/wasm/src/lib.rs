@@ -0,0 +1,110 @@
+//! Verification of PeerDAS cells and EIP-4844 blobs, exported to JavaScript with wasm-bindgen.
+//!
+//! Build with:
+//!
+//! ```text
+//! RUSTFLAGS="-C target-feature=+simd128" CC=clang \
+//!   cargo build -p eth-kzg-wasm-example --target wasm32-unknown-unknown --release
+//! ```
+//!
+//! Inputs are passed as flat byte arrays, ie the commitments of a batch are concatenated
+//! into a single `Uint8Array` of `48 * n` bytes, which avoids an allocation per element
+//! when crossing the JavaScript boundary.
+
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_CELL, BYTES_PER_COMMITMENT},
+    DASContext, Error,
+};
+use wasm_bindgen::prelude::*;
+
+/// A context for verifying proofs, created from the embedded mainnet trusted setup.
+#[wasm_bindgen]
+pub struct Verifier {
+    ctx: DASContext,
+}
+
+#[wasm_bindgen]
+impl Verifier {
+    #[wasm_bindgen(constructor)]
+    pub fn new() -> Self {
+        Self {
+            ctx: DASContext::default(),
+        }
+    }
+
+    /// Verifies a batch of cells against their commitments and proofs.
+    ///
+    /// `commitments`, `cells` and `proofs` are the concatenation of `n` commitments, cells
+    /// and proofs, where `n` is the length of `cell_indices`. Returns false if a proof
+    /// is invalid, and throws if the inputs are malformed.
+    #[wasm_bindgen(js_name = verifyCellKzgProofBatch)]
+    pub fn verify_cell_kzg_proof_batch(
+        &self,
+        commitments: &[u8],
+        cell_indices: &[u64],
+        cells: &[u8],
+        proofs: &[u8],
+    ) -> Result<bool, JsError> {
+        let commitments = split::<BYTES_PER_COMMITMENT>(commitments, "commitments")?;
+        let cells = split::<BYTES_PER_CELL>(cells, "cells")?;
+        let proofs = split::<BYTES_PER_COMMITMENT>(proofs, "proofs")?;
+
+        let result = self
+            .ctx
+            .verify_cell_kzg_proof_batch(commitments, cell_indices, cells, proofs);
+        into_bool(result)
+    }
+
+    /// Verifies that `proof` attests to the blob matching `commitment`.
+    ///
+    /// Returns false if the proof is invalid, and throws if the inputs are malformed.
+    #[wasm_bindgen(js_name = verifyBlobKzgProof)]
+    pub fn verify_blob_kzg_proof(
+        &self,
+        blob: &[u8],
+        commitment: &[u8],
+        proof: &[u8],
+    ) -> Result<bool, JsError> {
+        let blob = exact::<BYTES_PER_BLOB>(blob, "blob")?;
+        let commitment = exact::<BYTES_PER_COMMITMENT>(commitment, "commitment")?;
+        let proof = exact::<BYTES_PER_COMMITMENT>(proof, "proof")?;
+
+        into_bool(self.ctx.verify_blob_kzg_proof(blob, commitment, proof))
+    }
+}
+
+impl Default for Verifier {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+/// Splits `bytes` into chunks of `N` bytes, failing if its length is not a multiple of `N`.
+fn split<'a, const N: usize>(bytes: &'a [u8], name: &str) -> Result<Vec<&'a [u8; N]>, JsError> {
+    if bytes.len() % N != 0 {
+        return Err(JsError::new(&format!(
+            "{name} must be a multiple of {N} bytes, got {}",
+            bytes.len()
+        )));
+    }
+    Ok(bytes
+        .chunks_exact(N)
+        .map(|chunk| chunk.try_into().expect("chunk has N bytes"))
+        .collect())
+}
+
+/// Converts `bytes` to an array of `N` bytes, failing if it has a different length.
+fn exact<'a, const N: usize>(bytes: &'a [u8], name: &str) -> Result<&'a [u8; N], JsError> {
+    bytes
+        .try_into()
+        .map_err(|_| JsError::new(&format!("{name} must be {N} bytes, got {}", bytes.len())))
+}
+
+/// Maps an invalid proof to false, and any other error to a JavaScript exception.
+fn into_bool(result: Result<(), Error>) -> Result<bool, JsError> {
+    match result {
+        Ok(()) => Ok(true),
+        Err(err) if err.is_proof_invalid() => Ok(false),
+        Err(err) => Err(JsError::new(&format!("{err:?}"))),
+    }
+}
@@ -0,0 +1,36 @@
+#![cfg(target_arch = "wasm32")]
+
+use eth_kzg_wasm_example::Verifier;
+use rust_eth_kzg::{
+    constants::{BYTES_PER_BLOB, BYTES_PER_FIELD_ELEMENT},
+    DASContext,
+};
+use wasm_bindgen_test::wasm_bindgen_test;
+
+#[wasm_bindgen_test]
+fn verifies_cells_computed_natively() {
+    let ctx = DASContext::default();
+    let mut blob = vec![0u8; BYTES_PER_BLOB];
+    for (i, chunk) in blob.chunks_exact_mut(BYTES_PER_FIELD_ELEMENT).enumerate() {
+        chunk[BYTES_PER_FIELD_ELEMENT - 1] = (i % 251) as u8 + 1;
+    }
+    let blob = blob.as_slice().try_into().unwrap();
+
+    let commitment = ctx.blob_to_kzg_commitment(blob).unwrap();
+    let (cells, proofs) = ctx.compute_cells_and_kzg_proofs(blob).unwrap();
+
+    let verifier = Verifier::new();
+    let cell_indices: Vec<u64> = (0..2).collect();
+    let commitments = [commitment, commitment].concat();
+    let cells_bytes = [cells[0].as_slice(), cells[1].as_slice()].concat();
+    let proofs_bytes = [proofs[0], proofs[1]].concat();
+    assert!(verifier
+        .verify_cell_kzg_proof_batch(&commitments, &cell_indices, &cells_bytes, &proofs_bytes)
+        .unwrap());
+
+    // Swapping the proofs makes the batch invalid.
+    let swapped = [proofs[1], proofs[0]].concat();
+    assert!(!verifier
+        .verify_cell_kzg_proof_batch(&commitments, &cell_indices, &cells_bytes, &swapped)
+        .unwrap());
+}
@@ -0,0 +1,82 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use ark_ff::{AdditiveGroup as _, Field as _};
+use bls12_381::traits::*;
+use blstrs::Fp;
+use eth_kzg_fuzz::arkworks::{add_offset, field_bytes, field_from_bytes, BASE_MODULUS};
+use libfuzzer_sys::fuzz_target;
+
+type ArkFp = ark_bls12_381::Fq;
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    a: [u8; 48],
+    b: [u8; 48],
+    /// Replaces `a` with the modulus plus this offset.
+    near_modulus: Option<i8>,
+    exponent: u64,
+}
+
+fn ark_bytes(value: &ArkFp) -> [u8; 48] {
+    field_bytes(value)
+}
+
+/// Deserializes a field element with both implementations, checking that they agree on
+/// whether it is canonical.
+fn deserialize(bytes: &[u8; 48]) -> Option<(Fp, ArkFp)> {
+    let ours = Option::<Fp>::from(Fp::from_bytes_be(bytes));
+    let theirs = field_from_bytes::<ArkFp>(bytes);
+    assert_eq!(
+        ours.map(|x| x.to_bytes_be()),
+        theirs.map(|x| ark_bytes(&x)),
+        "from_bytes_be"
+    );
+    ours.zip(theirs)
+}
+
+fuzz_target!(|input: Input| {
+    let a = input
+        .near_modulus
+        .map_or(input.a, |offset| add_offset(BASE_MODULUS, offset));
+
+    // Clearing the top bits makes most encodings canonical, so that the arithmetic is reached
+    let mut b = input.b;
+    b[0] &= 0x1f;
+
+    let (Some((x, ark_x)), Some((y, ark_y))) = (deserialize(&a), deserialize(&b)) else {
+        return;
+    };
+
+    assert_eq!((x + y).to_bytes_be(), ark_bytes(&(ark_x + ark_y)), "add");
+    assert_eq!((x - y).to_bytes_be(), ark_bytes(&(ark_x - ark_y)), "sub");
+    assert_eq!((x * y).to_bytes_be(), ark_bytes(&(ark_x * ark_y)), "mul");
+    assert_eq!((-x).to_bytes_be(), ark_bytes(&-ark_x), "neg");
+    assert_eq!(
+        x.double().to_bytes_be(),
+        ark_bytes(&ark_x.double()),
+        "double"
+    );
+    assert_eq!(
+        x.square().to_bytes_be(),
+        ark_bytes(&ark_x.square()),
+        "square"
+    );
+    assert_eq!(
+        x.pow_vartime([input.exponent]).to_bytes_be(),
+        ark_bytes(&ark_x.pow([input.exponent])),
+        "pow"
+    );
+    assert_eq!(
+        Option::<Fp>::from(x.invert()).map(|x| x.to_bytes_be()),
+        ark_x.inverse().map(|x| ark_bytes(&x)),
+        "invert"
+    );
+
+    // Square roots are only unique up to sign, so only their existence is compared
+    let root = Option::<Fp>::from(x.sqrt());
+    assert_eq!(root.is_some(), ark_x.sqrt().is_some(), "sqrt");
+    if let Some(root) = root {
+        assert_eq!(root.square(), x, "sqrt");
+    }
+});
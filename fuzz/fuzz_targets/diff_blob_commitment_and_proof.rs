@@ -0,0 +1,41 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use eth_kzg_fuzz::{assert_same, blob, bytes48, contexts, verified, FuzzBlob, IDENTITY};
+use libfuzzer_sys::fuzz_target;
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    blob: FuzzBlob,
+    /// Replaces the commitment of the blob, to check that both reject the same commitments.
+    commitment: Option<[u8; 48]>,
+    /// Replaces the proof of the blob.
+    proof: Option<[u8; 48]>,
+}
+
+fuzz_target!(|input: Input| {
+    let (ctx, ckzg) = contexts();
+    let blob_bytes = input.blob.to_bytes();
+    let ours_blob = blob_bytes.as_slice().try_into().unwrap();
+    let c_blob = blob(&blob_bytes);
+
+    let ours = ctx.blob_to_kzg_commitment(ours_blob);
+    let theirs = ckzg
+        .blob_to_kzg_commitment(&c_blob)
+        .map(|commitment| *commitment.to_bytes());
+    let computed_commitment = ours.as_ref().ok().copied();
+    assert_same("blob_to_kzg_commitment", ours, theirs);
+
+    let commitment = input.commitment.or(computed_commitment).unwrap_or(IDENTITY);
+    let ours = ctx.compute_blob_kzg_proof(ours_blob, &commitment);
+    let theirs = ckzg
+        .compute_blob_kzg_proof(&c_blob, &bytes48(&commitment))
+        .map(|proof| *proof.to_bytes());
+    let computed_proof = ours.as_ref().ok().copied();
+    assert_same("compute_blob_kzg_proof", ours, theirs);
+
+    let proof = input.proof.or(computed_proof).unwrap_or(IDENTITY);
+    let ours = verified(ctx.verify_blob_kzg_proof(ours_blob, &commitment, &proof));
+    let theirs = ckzg.verify_blob_kzg_proof(&c_blob, &bytes48(&commitment), &bytes48(&proof));
+    assert_same("verify_blob_kzg_proof", ours, theirs);
+});
@@ -0,0 +1,105 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use eth_kzg_fuzz::{assert_same, blob, bytes48, cell, contexts, verified, FuzzBlob, IDENTITY};
+use libfuzzer_sys::fuzz_target;
+use rust_eth_kzg::constants::CELLS_PER_EXT_BLOB;
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    blob: FuzzBlob,
+    /// The cells given to recovery and verification, as indices into the extended blob.
+    ///
+    /// Indices are not deduplicated or sorted, and may be out of range.
+    cell_indices: Vec<u8>,
+    /// Flips a bit of the byte at this offset of the first cell before verifying.
+    corrupt_byte: Option<u16>,
+    /// Replaces the first proof before verifying.
+    proof: Option<[u8; 48]>,
+}
+
+fuzz_target!(|input: Input| {
+    let (ctx, ckzg) = contexts();
+    let blob_bytes = input.blob.to_bytes();
+    let ours_blob = blob_bytes.as_slice().try_into().unwrap();
+    let c_blob = blob(&blob_bytes);
+
+    let ours = ctx
+        .compute_cells(ours_blob)
+        .map(|cells| cells.iter().map(|cell| cell.to_vec()).collect::<Vec<_>>());
+    let theirs = ckzg
+        .compute_cells(&c_blob)
+        .map(|cells| cells.iter().map(|cell| cell.to_bytes().to_vec()).collect());
+    assert_same("compute_cells", ours, theirs);
+
+    let ours = ctx.compute_cells_and_kzg_proofs(ours_blob);
+    let theirs = ckzg
+        .compute_cells_and_kzg_proofs(&c_blob)
+        .map(|(cells, proofs)| {
+            (
+                (*cells).map(|cell| Box::new(cell.to_bytes())),
+                (*proofs).map(|proof| *proof.to_bytes()),
+            )
+        });
+    let computed = ours.as_ref().ok().cloned();
+    assert_same("compute_cells_and_kzg_proofs", ours, theirs);
+    let Some((cells, proofs)) = computed else {
+        return;
+    };
+    let commitment = ctx.blob_to_kzg_commitment(ours_blob).unwrap();
+
+    // Select the cells, keeping out of range indices so that both have to reject them.
+    let cell_indices: Vec<u64> = input
+        .cell_indices
+        .iter()
+        .take(CELLS_PER_EXT_BLOB)
+        .map(|&index| u64::from(index))
+        .collect();
+    let mut selected_cells: Vec<Vec<u8>> = cell_indices
+        .iter()
+        .map(|&index| cells[index as usize % CELLS_PER_EXT_BLOB].to_vec())
+        .collect();
+    let mut selected_proofs: Vec<[u8; 48]> = cell_indices
+        .iter()
+        .map(|&index| proofs[index as usize % CELLS_PER_EXT_BLOB])
+        .collect();
+    if let (Some(offset), Some(first)) = (input.corrupt_byte, selected_cells.first_mut()) {
+        let offset = offset as usize % first.len();
+        first[offset] ^= 1;
+    }
+    if let (Some(proof), Some(first)) = (input.proof, selected_proofs.first_mut()) {
+        *first = proof;
+    }
+
+    let ours_cells: Vec<_> = selected_cells
+        .iter()
+        .map(|cell| cell.as_slice().try_into().unwrap())
+        .collect();
+    let c_cells: Vec<_> = selected_cells.iter().map(|bytes| cell(bytes)).collect();
+
+    let ours = ctx
+        .recover_cells_and_kzg_proofs(cell_indices.clone(), ours_cells.clone())
+        .map(|(cells, proofs)| (cells.map(|cell| cell.to_vec()), proofs));
+    let theirs = ckzg
+        .recover_cells_and_kzg_proofs(&cell_indices, &c_cells)
+        .map(|(cells, proofs)| {
+            (
+                (*cells).map(|cell| cell.to_bytes().to_vec()),
+                (*proofs).map(|proof| *proof.to_bytes()),
+            )
+        });
+    assert_same("recover_cells_and_kzg_proofs", ours, theirs);
+
+    let commitments = vec![commitment; cell_indices.len()];
+    let ours = verified(ctx.verify_cell_kzg_proof_batch(
+        commitments.iter().collect(),
+        &cell_indices,
+        ours_cells,
+        selected_proofs.iter().collect(),
+    ));
+    let c_commitments: Vec<_> = commitments.iter().map(bytes48).collect();
+    let c_proofs: Vec<_> = selected_proofs.iter().map(bytes48).collect();
+    let theirs =
+        ckzg.verify_cell_kzg_proof_batch(&c_commitments, &cell_indices, &c_cells, &c_proofs);
+    assert_same("verify_cell_kzg_proof_batch", ours, theirs);
+});
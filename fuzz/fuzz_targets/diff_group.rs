@@ -0,0 +1,187 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use ark_ec::{CurveGroup, PrimeGroup, VariableBaseMSM};
+use ark_ff::{AdditiveGroup, PrimeField};
+use bls12_381::{
+    lincomb::{g1_lincomb, g2_lincomb},
+    scalar,
+    traits::*,
+    G1Point, G1Projective, G2Point, G2Projective, Scalar,
+};
+use eth_kzg_fuzz::arkworks::{
+    compressed, has_infinity_flag, is_canonical_infinity, point_from_compressed,
+};
+use libfuzzer_sys::fuzz_target;
+
+/// How a term of the sums and multi-scalar multiplications is chosen.
+///
+/// Besides multiples of the generator, the terms hit the edge cases of the addition formulas:
+/// the identity, and a point added to itself or to its negation.
+#[derive(Arbitrary, Debug, Clone, Copy)]
+enum Term {
+    /// The generator times the scalar.
+    Multiple([u8; 32]),
+    Identity,
+    /// The previous term again.
+    Repeat,
+    /// The negation of the previous term.
+    Negate,
+}
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    g1_encoding: [u8; 48],
+    g2_encoding: [u8; 96],
+    terms: Vec<(Term, [u8; 32])>,
+    /// The scalar that the sum is multiplied by.
+    k: [u8; 32],
+}
+
+/// Checks that both implementations accept the same compressed encodings, and decode them
+/// to the same point.
+fn check_deserialization<Ours, Theirs>(
+    name: &str,
+    bytes: &[u8],
+    ours: Option<Ours>,
+    to_bytes: impl Fn(&Ours) -> Vec<u8>,
+) where
+    Theirs: ark_serialize::CanonicalDeserialize + ark_serialize::CanonicalSerialize,
+{
+    if has_infinity_flag(bytes) {
+        assert_eq!(
+            ours.is_some(),
+            is_canonical_infinity(bytes),
+            "{name}: infinity"
+        );
+        return;
+    }
+    let theirs = point_from_compressed::<Theirs>(bytes);
+    assert_eq!(
+        ours.map(|point| to_bytes(&point)),
+        theirs.map(|point| compressed(&point)),
+        "{name}"
+    );
+}
+
+/// Returns the terms of the sums, with both implementations.
+fn terms<P, A>(
+    terms: &[(Term, [u8; 32])],
+    generator: P,
+    ark_generator: A,
+) -> Vec<(P, A, Scalar, ark_bls12_381::Fr)>
+where
+    P: Group<Scalar = Scalar>,
+    A: PrimeGroup<ScalarField = ark_bls12_381::Fr>,
+{
+    let mut out: Vec<(P, A, Scalar, ark_bls12_381::Fr)> = Vec::with_capacity(terms.len());
+    for &(term, coefficient) in terms.iter().take(16) {
+        let previous = out
+            .last()
+            .map(|(point, ark_point, _, _)| (*point, *ark_point));
+        let (point, ark_point) = match (term, previous) {
+            (Term::Multiple(bytes), _) => (
+                generator * scalar::from_bytes_be_reduced(bytes),
+                ark_generator * ark_bls12_381::Fr::from_be_bytes_mod_order(&bytes),
+            ),
+            (Term::Repeat, Some(previous)) => previous,
+            (Term::Negate, Some((point, ark_point))) => (-point, -ark_point),
+            (Term::Identity | Term::Repeat | Term::Negate, _) => (P::identity(), A::ZERO),
+        };
+        out.push((
+            point,
+            ark_point,
+            scalar::from_bytes_be_reduced(coefficient),
+            ark_bls12_381::Fr::from_be_bytes_mod_order(&coefficient),
+        ));
+    }
+    out
+}
+
+fuzz_target!(|input: Input| {
+    // Deserialization
+    check_deserialization::<G1Point, ark_bls12_381::G1Affine>(
+        "G1 from_compressed",
+        &input.g1_encoding,
+        Option::from(G1Point::from_compressed(&input.g1_encoding)),
+        |point| point.to_compressed().to_vec(),
+    );
+    check_deserialization::<G2Point, ark_bls12_381::G2Affine>(
+        "G2 from_compressed",
+        &input.g2_encoding,
+        Option::from(G2Point::from_compressed(&input.g2_encoding)),
+        |point| point.to_compressed().to_vec(),
+    );
+
+    let k = scalar::from_bytes_be_reduced(input.k);
+    let ark_k = ark_bls12_381::Fr::from_be_bytes_mod_order(&input.k);
+
+    // G1 additions, doublings, multiplications and multi-scalar multiplications
+    let g1_terms = terms(
+        &input.terms,
+        G1Projective::generator(),
+        ark_bls12_381::G1Projective::generator(),
+    );
+    let mut sum = G1Projective::identity();
+    let mut ark_sum = ark_bls12_381::G1Projective::ZERO;
+    for (point, ark_point, _, _) in &g1_terms {
+        sum += point;
+        ark_sum += ark_point;
+    }
+    let check_g1 = |name: &str, ours: G1Projective, theirs: ark_bls12_381::G1Projective| {
+        assert_eq!(
+            ours.to_affine().to_compressed().to_vec(),
+            compressed(&theirs.into_affine()),
+            "G1 {name}"
+        );
+    };
+    check_g1("add", sum, ark_sum);
+    check_g1("double", sum.double(), ark_sum.double());
+    check_g1("mul", sum * k, ark_sum * ark_k);
+
+    let points: Vec<G1Point> = g1_terms.iter().map(|(p, _, _, _)| p.to_affine()).collect();
+    let ark_points: Vec<_> = g1_terms
+        .iter()
+        .map(|(_, p, _, _)| p.into_affine())
+        .collect();
+    let scalars: Vec<Scalar> = g1_terms.iter().map(|(_, _, s, _)| *s).collect();
+    let ark_scalars: Vec<_> = g1_terms.iter().map(|(_, _, _, s)| *s).collect();
+    check_g1(
+        "lincomb",
+        g1_lincomb(&points, &scalars).expect("one scalar per point"),
+        ark_bls12_381::G1Projective::msm(&ark_points, &ark_scalars).expect("one scalar per point"),
+    );
+
+    // G2 additions, multiplications and multi-scalar multiplications
+    let g2_terms = terms(
+        &input.terms,
+        G2Projective::generator(),
+        ark_bls12_381::G2Projective::generator(),
+    );
+    let mut sum = G2Projective::identity();
+    let mut ark_sum = ark_bls12_381::G2Projective::ZERO;
+    for (point, ark_point, _, _) in &g2_terms {
+        sum += point;
+        ark_sum += ark_point;
+    }
+    let check_g2 = |name: &str, ours: G2Projective, theirs: ark_bls12_381::G2Projective| {
+        assert_eq!(
+            ours.to_affine().to_compressed().to_vec(),
+            compressed(&theirs.into_affine()),
+            "G2 {name}"
+        );
+    };
+    check_g2("add", sum, ark_sum);
+    check_g2("mul", sum * k, ark_sum * ark_k);
+
+    let points: Vec<G2Point> = g2_terms.iter().map(|(p, _, _, _)| p.to_affine()).collect();
+    let ark_points: Vec<_> = g2_terms
+        .iter()
+        .map(|(_, p, _, _)| p.into_affine())
+        .collect();
+    check_g2(
+        "lincomb",
+        g2_lincomb(&points, &scalars).expect("one scalar per point"),
+        ark_bls12_381::G2Projective::msm(&ark_points, &ark_scalars).expect("one scalar per point"),
+    );
+});
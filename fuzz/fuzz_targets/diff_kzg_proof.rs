@@ -0,0 +1,50 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use eth_kzg_fuzz::{assert_same, blob, bytes32, bytes48, contexts, verified, FuzzBlob, IDENTITY};
+use libfuzzer_sys::fuzz_target;
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    blob: FuzzBlob,
+    z: [u8; 32],
+    /// Replaces the evaluation of the blob at `z`.
+    y: Option<[u8; 32]>,
+    /// Replaces the commitment of the blob.
+    commitment: Option<[u8; 48]>,
+    /// Replaces the proof of the evaluation.
+    proof: Option<[u8; 48]>,
+}
+
+fuzz_target!(|input: Input| {
+    let (ctx, ckzg) = contexts();
+    let blob_bytes = input.blob.to_bytes();
+    let ours_blob = blob_bytes.as_slice().try_into().unwrap();
+    let c_blob = blob(&blob_bytes);
+
+    let ours = ctx.compute_kzg_proof(ours_blob, input.z);
+    let theirs = ckzg
+        .compute_kzg_proof(&c_blob, &bytes32(&input.z))
+        .map(|(proof, y)| (*proof.to_bytes(), *y));
+    let computed = ours.as_ref().ok().copied();
+    assert_same("compute_kzg_proof", ours, theirs);
+
+    let commitment = input
+        .commitment
+        .or_else(|| ctx.blob_to_kzg_commitment(ours_blob).ok())
+        .unwrap_or(IDENTITY);
+    let y = input.y.or(computed.map(|(_, y)| y)).unwrap_or([0; 32]);
+    let proof = input
+        .proof
+        .or(computed.map(|(proof, _)| proof))
+        .unwrap_or(IDENTITY);
+
+    let ours = verified(ctx.verify_kzg_proof(&commitment, input.z, y, &proof));
+    let theirs = ckzg.verify_kzg_proof(
+        &bytes48(&commitment),
+        &bytes32(&input.z),
+        &bytes32(&y),
+        &bytes48(&proof),
+    );
+    assert_same("verify_kzg_proof", ours, theirs);
+});
@@ -0,0 +1,81 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use ark_ff::{AdditiveGroup as _, Field as _, PrimeField};
+use bls12_381::{scalar, traits::*, Scalar};
+use eth_kzg_fuzz::arkworks::{add_offset, field_bytes, field_from_bytes, SCALAR_MODULUS};
+use libfuzzer_sys::fuzz_target;
+
+type ArkScalar = ark_bls12_381::Fr;
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    a: [u8; 32],
+    b: [u8; 32],
+    /// Replaces `a` with the modulus plus this offset.
+    near_modulus: Option<i8>,
+    wide: [u8; 64],
+    exponent: u64,
+}
+
+fn bytes(scalar: &Scalar) -> [u8; 32] {
+    scalar::to_bytes_be(scalar)
+}
+
+fn ark_bytes(scalar: &ArkScalar) -> [u8; 32] {
+    field_bytes(scalar)
+}
+
+fuzz_target!(|input: Input| {
+    let a = input
+        .near_modulus
+        .map_or(input.a, |offset| add_offset(SCALAR_MODULUS, offset));
+
+    // Deserialization, which must only accept encodings that are less than the modulus
+    assert_eq!(
+        scalar::from_bytes_be(&a).map(|x| bytes(&x)),
+        field_from_bytes::<ArkScalar>(&a).map(|x| ark_bytes(&x)),
+        "from_bytes_be"
+    );
+
+    // Reductions
+    let (x, ark_x) = (
+        scalar::from_bytes_be_reduced(a),
+        ArkScalar::from_be_bytes_mod_order(&a),
+    );
+    let (y, ark_y) = (
+        scalar::from_bytes_be_reduced(input.b),
+        ArkScalar::from_be_bytes_mod_order(&input.b),
+    );
+    assert_eq!(bytes(&x), ark_bytes(&ark_x), "from_bytes_be_reduced");
+    assert_eq!(
+        bytes(&scalar::from_bytes_be_wide(&input.wide)),
+        ark_bytes(&ArkScalar::from_be_bytes_mod_order(&input.wide)),
+        "from_bytes_be_wide"
+    );
+
+    // Arithmetic
+    assert_eq!(bytes(&(x + y)), ark_bytes(&(ark_x + ark_y)), "add");
+    assert_eq!(bytes(&(x - y)), ark_bytes(&(ark_x - ark_y)), "sub");
+    assert_eq!(bytes(&(x * y)), ark_bytes(&(ark_x * ark_y)), "mul");
+    assert_eq!(bytes(&-x), ark_bytes(&-ark_x), "neg");
+    assert_eq!(bytes(&x.double()), ark_bytes(&ark_x.double()), "double");
+    assert_eq!(bytes(&x.square()), ark_bytes(&ark_x.square()), "square");
+    assert_eq!(
+        bytes(&x.pow_vartime([input.exponent])),
+        ark_bytes(&ark_x.pow([input.exponent])),
+        "pow"
+    );
+    assert_eq!(
+        Option::<Scalar>::from(x.invert()).map(|x| bytes(&x)),
+        ark_x.inverse().map(|x| ark_bytes(&x)),
+        "invert"
+    );
+
+    // Square roots are only unique up to sign, so only their existence is compared
+    let root = Option::<Scalar>::from(x.sqrt());
+    assert_eq!(root.is_some(), ark_x.sqrt().is_some(), "sqrt");
+    if let Some(root) = root {
+        assert_eq!(root.square(), x, "sqrt");
+    }
+});
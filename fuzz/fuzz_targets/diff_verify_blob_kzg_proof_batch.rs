@@ -0,0 +1,65 @@
+#![no_main]
+
+use arbitrary::Arbitrary;
+use eth_kzg_fuzz::{assert_same, blob, bytes48, contexts, verified, FuzzBlob, IDENTITY};
+use libfuzzer_sys::fuzz_target;
+
+/// The largest batch that is generated, since each blob is 128KiB.
+const MAX_BATCH_SIZE: usize = 4;
+
+#[derive(Arbitrary, Debug)]
+struct Entry {
+    blob: FuzzBlob,
+    /// Replaces the commitment of the blob.
+    commitment: Option<[u8; 48]>,
+    /// Replaces the proof of the blob.
+    proof: Option<[u8; 48]>,
+}
+
+#[derive(Arbitrary, Debug)]
+struct Input {
+    entries: Vec<Entry>,
+    /// Drops the last proof, so that the lengths of the inputs differ.
+    drop_proof: bool,
+}
+
+fuzz_target!(|input: Input| {
+    let (ctx, ckzg) = contexts();
+    let entries = &input.entries[..input.entries.len().min(MAX_BATCH_SIZE)];
+
+    let blobs: Vec<Vec<u8>> = entries.iter().map(|entry| entry.blob.to_bytes()).collect();
+    let mut commitments = Vec::with_capacity(entries.len());
+    let mut proofs = Vec::with_capacity(entries.len());
+    for (entry, blob) in entries.iter().zip(&blobs) {
+        let blob = blob.as_slice().try_into().unwrap();
+        let commitment = entry
+            .commitment
+            .or_else(|| ctx.blob_to_kzg_commitment(blob).ok())
+            .unwrap_or(IDENTITY);
+        let proof = entry
+            .proof
+            .or_else(|| ctx.compute_blob_kzg_proof(blob, &commitment).ok())
+            .unwrap_or(IDENTITY);
+        commitments.push(commitment);
+        proofs.push(proof);
+    }
+    if input.drop_proof {
+        proofs.pop();
+    }
+
+    let ours = verified(
+        ctx.verify_blob_kzg_proof_batch(
+            blobs
+                .iter()
+                .map(|blob| blob.as_slice().try_into().unwrap())
+                .collect(),
+            commitments.iter().collect(),
+            proofs.iter().collect(),
+        ),
+    );
+    let c_blobs: Vec<_> = blobs.iter().map(|bytes| blob(bytes)).collect();
+    let c_commitments: Vec<_> = commitments.iter().map(bytes48).collect();
+    let c_proofs: Vec<_> = proofs.iter().map(bytes48).collect();
+    let theirs = ckzg.verify_blob_kzg_proof_batch(&c_blobs, &c_commitments, &c_proofs);
+    assert_same("verify_blob_kzg_proof_batch", ours, theirs);
+});
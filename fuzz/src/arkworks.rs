@@ -0,0 +1,89 @@
+//! Helpers for the targets that compare the field and group operations of `ekzg-bls12-381`
+//! with `ark-bls12-381`.
+//!
+//! Values are compared through their big-endian encodings, so that a bug in a conversion
+//! between the two libraries cannot hide a bug in one of them.
+
+use ark_ff::{BigInteger, PrimeField};
+use ark_serialize::{CanonicalDeserialize, CanonicalSerialize};
+
+/// The big-endian encoding of the scalar field modulus.
+pub const SCALAR_MODULUS: [u8; 32] = [
+    0x73, 0xed, 0xa7, 0x53, 0x29, 0x9d, 0x7d, 0x48, 0x33, 0x39, 0xd8, 0x08, 0x09, 0xa1, 0xd8, 0x05,
+    0x53, 0xbd, 0xa4, 0x02, 0xff, 0xfe, 0x5b, 0xfe, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01,
+];
+
+/// The big-endian encoding of the base field modulus.
+pub const BASE_MODULUS: [u8; 48] = [
+    0x1a, 0x01, 0x11, 0xea, 0x39, 0x7f, 0xe6, 0x9a, 0x4b, 0x1b, 0xa7, 0xb6, 0x43, 0x4b, 0xac, 0xd7,
+    0x64, 0x77, 0x4b, 0x84, 0xf3, 0x85, 0x12, 0xbf, 0x67, 0x30, 0xd2, 0xa0, 0xf6, 0xb0, 0xf6, 0x24,
+    0x1e, 0xab, 0xff, 0xfe, 0xb1, 0x53, 0xff, 0xff, 0xb9, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xaa, 0xab,
+];
+
+/// Returns `bytes` with `offset` added, as big-endian integers, wrapping around.
+///
+/// This is used to build encodings next to the moduli, which the fuzzer rarely finds on
+/// its own.
+pub fn add_offset<const N: usize>(bytes: [u8; N], offset: i8) -> [u8; N] {
+    let mut out = bytes;
+    let mut carry = i16::from(offset);
+    for byte in out.iter_mut().rev() {
+        let sum = i16::from(*byte) + carry;
+        *byte = sum.rem_euclid(256) as u8;
+        carry = sum.div_euclid(256);
+        if carry == 0 {
+            break;
+        }
+    }
+    out
+}
+
+/// Returns the big-endian encoding of an arkworks field element.
+pub fn field_bytes<F: PrimeField, const N: usize>(value: &F) -> [u8; N] {
+    let bytes = value.into_bigint().to_bytes_be();
+    // The big integers of arkworks are a whole number of limbs, which may be wider than N
+    let (padding, bytes) = bytes.split_at(bytes.len() - N);
+    assert!(padding.iter().all(|&byte| byte == 0));
+    bytes.try_into().expect("the field element has N bytes")
+}
+
+/// Returns the field element that the big-endian `bytes` encode with arkworks, or `None` if
+/// they are not less than the modulus.
+///
+/// The bytes are read as an integer, rather than deserialized, since arkworks reads flags
+/// from the unused top bits of its serialization of field elements.
+pub fn field_from_bytes<F: PrimeField>(bytes: &[u8]) -> Option<F> {
+    let bits: Vec<bool> = bytes
+        .iter()
+        .flat_map(|byte| (0..8).rev().map(move |i| (byte >> i) & 1 == 1))
+        .collect();
+    F::from_bigint(F::BigInt::from_bits_be(&bits))
+}
+
+/// Deserializes a compressed point with arkworks, checking that it is in the subgroup.
+pub fn point_from_compressed<P: CanonicalDeserialize>(bytes: &[u8]) -> Option<P> {
+    P::deserialize_compressed(bytes).ok()
+}
+
+/// Returns the compressed encoding of a point with arkworks.
+pub fn compressed<P: CanonicalSerialize>(point: &P) -> Vec<u8> {
+    let mut bytes = Vec::new();
+    point
+        .serialize_compressed(&mut bytes)
+        .expect("a point can always be serialized");
+    bytes
+}
+
+/// Returns true if `bytes` has the infinity flag of the compressed encoding set.
+///
+/// The only valid such encoding is the flags `0xc0` followed by zeros. The targets check
+/// these encodings against that rule of the specs rather than against arkworks, so that they
+/// only fail for a bug in this library.
+pub const fn has_infinity_flag(bytes: &[u8]) -> bool {
+    bytes[0] & 0x40 != 0
+}
+
+/// Returns true if `bytes` is the compressed encoding of the point at infinity.
+pub fn is_canonical_infinity(bytes: &[u8]) -> bool {
+    bytes[0] == 0xc0 && bytes[1..].iter().all(|&byte| byte == 0)
+}
@@ -0,0 +1,135 @@
+//! Helpers shared by the differential fuzz targets.
+
+#[cfg(feature = "arkworks")]
+pub mod arkworks;
+
+use std::{fmt::Debug, sync::OnceLock};
+
+use arbitrary::Arbitrary;
+use rust_eth_kzg::{constants::BYTES_PER_BLOB, DASContext, Error, UsePrecomp};
+
+/// The compressed encoding of the point at infinity, which is a valid commitment and proof.
+pub const IDENTITY: [u8; 48] = {
+    let mut bytes = [0u8; 48];
+    bytes[0] = 0xc0;
+    bytes
+};
+
+/// Returns the contexts of both implementations, created once per process.
+pub fn contexts() -> (&'static DASContext, &'static c_kzg::KzgSettings) {
+    static CTX: OnceLock<DASContext> = OnceLock::new();
+    let ctx =
+        CTX.get_or_init(|| DASContext::new(&rust_eth_kzg::TrustedSetup::default(), UsePrecomp::No));
+    (ctx, c_kzg::ethereum_kzg_settings(0))
+}
+
+/// A blob generated from a few field elements, since the fuzzer rarely produces 128KiB inputs.
+#[derive(Arbitrary, Debug)]
+pub struct FuzzBlob {
+    /// Clears the top bits of every field element, so that the blob is valid.
+    ///
+    /// Otherwise about half of the field elements are larger than the modulus.
+    canonical: bool,
+    field_elements: Vec<[u8; 32]>,
+}
+
+impl FuzzBlob {
+    /// Returns the blob, repeating the field elements and mixing in their index so that
+    /// the polynomial is not constant.
+    pub fn to_bytes(&self) -> Vec<u8> {
+        let mut blob = vec![0u8; BYTES_PER_BLOB];
+        if self.field_elements.is_empty() {
+            return blob;
+        }
+        for (i, chunk) in blob.chunks_exact_mut(32).enumerate() {
+            chunk.copy_from_slice(&self.field_elements[i % self.field_elements.len()]);
+            chunk[31] ^= i as u8;
+            if self.canonical {
+                chunk[0] &= 0x3f;
+            }
+        }
+        blob
+    }
+}
+
+/// The kinds of failure that both implementations can be compared on.
+///
+/// c-kzg reports most failures as `C_KZG_BADARGS`, so the codes of this crate are only
+/// compared up to whether the inputs were rejected.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ErrorClass {
+    /// The inputs were rejected, for example a point was not on the curve or an index was out
+    /// of range.
+    BadArgs,
+    /// The operation failed for a reason that does not depend on the inputs.
+    Internal,
+}
+
+impl ErrorClass {
+    /// Returns the class of an error of this crate.
+    pub const fn of(err: &Error) -> Self {
+        // The codes below 400 are raised on invalid inputs, see `ErrorCode`.
+        if err.code().as_u32() < 400 {
+            Self::BadArgs
+        } else {
+            Self::Internal
+        }
+    }
+
+    /// Returns the class of an error of c-kzg.
+    pub const fn of_c_kzg(err: &c_kzg::Error) -> Self {
+        match err {
+            c_kzg::Error::CError(c_kzg::C_KZG_RET::C_KZG_BADARGS)
+            | c_kzg::Error::InvalidBytesLength(_)
+            | c_kzg::Error::InvalidHexFormat(_)
+            | c_kzg::Error::InvalidKzgProof(_)
+            | c_kzg::Error::InvalidKzgCommitment(_)
+            | c_kzg::Error::MismatchLength(_) => Self::BadArgs,
+            _ => Self::Internal,
+        }
+    }
+}
+
+/// Panics unless both results are equal, or both are errors of the same [`ErrorClass`].
+pub fn assert_same<T: PartialEq + Debug>(
+    api: &str,
+    ours: Result<T, Error>,
+    theirs: Result<T, c_kzg::Error>,
+) {
+    match (ours, theirs) {
+        (Ok(ours), Ok(theirs)) => assert_eq!(ours, theirs, "{api}: outputs differ"),
+        (Err(ours), Err(theirs)) => assert_eq!(
+            ErrorClass::of(&ours),
+            ErrorClass::of_c_kzg(&theirs),
+            "{api}: the implementations failed differently\nours: {ours:?}\nc-kzg: {theirs:?}"
+        ),
+        (ours, theirs) => {
+            panic!("{api}: only one implementation failed\nours: {ours:?}\nc-kzg: {theirs:?}")
+        }
+    }
+}
+
+/// Maps the result of a verification to whether the proof was valid, like c-kzg does.
+pub fn verified(result: Result<(), Error>) -> Result<bool, Error> {
+    match result {
+        Ok(()) => Ok(true),
+        Err(err) if err.is_proof_invalid() => Ok(false),
+        Err(err) => Err(err),
+    }
+}
+
+pub fn blob(bytes: &[u8]) -> c_kzg::Blob {
+    c_kzg::Blob::from_bytes(bytes).expect("blob has the right length")
+}
+
+pub fn bytes32(bytes: &[u8; 32]) -> c_kzg::Bytes32 {
+    c_kzg::Bytes32::from_bytes(bytes).expect("32 bytes")
+}
+
+pub fn bytes48(bytes: &[u8; 48]) -> c_kzg::Bytes48 {
+    c_kzg::Bytes48::from_bytes(bytes).expect("48 bytes")
+}
+
+pub fn cell(bytes: &[u8]) -> c_kzg::Cell {
+    c_kzg::Cell::from_bytes(bytes).expect("cell has the right length")
+}
@@ -0,0 +1,130 @@
+#!/bin/bash
+# Builds libeth_kzg, a shared library of the C bindings for distro packagers and for
+# bindings that load the library at runtime.
+#
+# Unlike the cdylib that cargo builds for `c_eth_kzg`, the library:
+# - only exports the symbols listed in `bindings/c/eth_kzg.map`, with the symbol version
+#   ETH_KZG_<major> on Linux,
+# - has the soname libeth_kzg.so.<major> on Linux, the install name
+#   @rpath/libeth_kzg.<major>.dylib on macOS, and an import library on Windows,
+# where <major> is ETH_KZG_ABI_VERSION_MAJOR, so that it only changes when the ABI breaks.
+#
+# The static library built by cargo is linked into the shared library with the C compiler,
+# since rustc passes its own list of exported symbols to the linker when it builds a cdylib.
+# The exported symbols are checked against the list after linking.
+#
+# Usage: compile_shared_lib.sh [OUT_DIR]
+# OUT_DIR defaults to ./bindings/c/build/shared.
+
+set -euo pipefail
+
+# Determine the script's directory
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(cd "$SCRIPT_DIR/.." && pwd)"
+OS=$(uname)
+OUT_DIR="${1:-$PROJECT_ROOT/bindings/c/build/shared}"
+CC="${CC:-cc}"
+
+MAP_FILE="$PROJECT_ROOT/bindings/c/eth_kzg.map"
+ABI_FILE="$PROJECT_ROOT/bindings/c/src/abi.rs"
+
+MAJOR=$(sed -n 's/^pub const ETH_KZG_ABI_VERSION_MAJOR: u32 = \([0-9]*\);$/\1/p' "$ABI_FILE")
+MINOR=$(sed -n 's/^pub const ETH_KZG_ABI_VERSION_MINOR: u32 = \([0-9]*\);$/\1/p' "$ABI_FILE")
+SYMBOLS=$(sed -n 's/^ *\(eth_kzg_[a-z0-9_]*\);$/\1/p' "$MAP_FILE")
+echo "ABI version: $MAJOR.$MINOR"
+
+# The static library is linked with MinGW on Windows, so it is built for the GNU target,
+# as in compile_to_native.sh
+case "$OS" in
+    MINGW64_NT-*|CYGWIN_NT-*|"Windows")
+        TARGET_ARGS=(--target x86_64-pc-windows-gnu)
+        STATIC_LIB="$PROJECT_ROOT/target/x86_64-pc-windows-gnu/release/libc_eth_kzg.a"
+        ;;
+    *)
+        TARGET_ARGS=()
+        STATIC_LIB="$PROJECT_ROOT/target/release/libc_eth_kzg.a"
+        ;;
+esac
+
+# Build the static library, and ask rustc for the system libraries that it needs
+cd "$PROJECT_ROOT"
+NATIVE_LIBS=$(cargo rustc --release -p c_eth_kzg --lib --crate-type staticlib ${TARGET_ARGS[@]+"${TARGET_ARGS[@]}"} -- --print native-static-libs 2>&1 |
+    sed -n 's/.*native-static-libs: //p' | tail -n 1)
+echo "Native libraries: $NATIVE_LIBS"
+
+mkdir -p "$OUT_DIR"
+cp "$PROJECT_ROOT/bindings/c/build/c_eth_kzg.h" "$OUT_DIR/eth_kzg.h"
+
+# Lists the symbols exported by the shared library
+exported_symbols() {
+    case "$OS" in
+        "Darwin")
+            nm -gU "$1" | awk '{print $3}' | sed 's/^_//'
+            ;;
+        "Linux")
+            nm -D --defined-only "$1" | awk '$2 == "T" {print $3}' | sed 's/@.*//'
+            ;;
+        *)
+            objdump -p "$1" | awk '/\[Ordinal\/Name Pointer\] Table/ {table = 1; next} table && NF == 0 {table = 0} table {print $NF}'
+            ;;
+    esac
+}
+
+case "$OS" in
+    "Darwin")
+        LIB_NAME="libeth_kzg.$MAJOR.dylib"
+        EXPORTS_FILE="$OUT_DIR/eth_kzg.exports"
+        echo "$SYMBOLS" | sed 's/^/_/' > "$EXPORTS_FILE"
+        # shellcheck disable=SC2086
+        "$CC" -dynamiclib -o "$OUT_DIR/$LIB_NAME" \
+            -install_name "@rpath/$LIB_NAME" \
+            -compatibility_version "$MAJOR.0.0" \
+            -current_version "$MAJOR.$MINOR.0" \
+            -Wl,-exported_symbols_list,"$EXPORTS_FILE" \
+            -Wl,-force_load,"$STATIC_LIB" \
+            -Wl,-dead_strip \
+            $NATIVE_LIBS
+        ln -sf "$LIB_NAME" "$OUT_DIR/libeth_kzg.dylib"
+        ;;
+    "Linux")
+        LIB_NAME="libeth_kzg.so.$MAJOR.$MINOR.0"
+        # shellcheck disable=SC2086
+        "$CC" -shared -o "$OUT_DIR/$LIB_NAME" \
+            -Wl,-soname,"libeth_kzg.so.$MAJOR" \
+            -Wl,--version-script="$MAP_FILE" \
+            -Wl,--whole-archive "$STATIC_LIB" -Wl,--no-whole-archive \
+            -Wl,--gc-sections \
+            -Wl,-z,relro,-z,now \
+            $NATIVE_LIBS
+        ln -sf "$LIB_NAME" "$OUT_DIR/libeth_kzg.so.$MAJOR"
+        ln -sf "libeth_kzg.so.$MAJOR" "$OUT_DIR/libeth_kzg.so"
+        ;;
+        # Github runners will return MINGW64_NT-10.0-20348
+        # so we add a wildcard to match the prefix
+    MINGW64_NT-*|CYGWIN_NT-*|"Windows")
+        LIB_NAME="eth_kzg.dll"
+        DEF_FILE="$OUT_DIR/eth_kzg.def"
+        { echo "LIBRARY eth_kzg"; echo "EXPORTS"; echo "$SYMBOLS" | sed 's/^/    /'; } > "$DEF_FILE"
+        # shellcheck disable=SC2086
+        "$CC" -shared -o "$OUT_DIR/$LIB_NAME" "$DEF_FILE" \
+            -Wl,--out-implib,"$OUT_DIR/libeth_kzg.dll.a" \
+            -Wl,--whole-archive "$STATIC_LIB" -Wl,--no-whole-archive \
+            -Wl,--gc-sections \
+            $NATIVE_LIBS
+        ;;
+    *)
+        echo "Unsupported OS: $OS"
+        exit 1
+        ;;
+esac
+
+# Check that exactly the public API is exported
+EXPECTED=$(echo "$SYMBOLS" | sort)
+ACTUAL=$(exported_symbols "$OUT_DIR/$LIB_NAME" | sort)
+if [ "$EXPECTED" != "$ACTUAL" ]; then
+    echo "The exported symbols of $LIB_NAME do not match $MAP_FILE:"
+    diff <(echo "$EXPECTED") <(echo "$ACTUAL") || true
+    exit 1
+fi
+
+echo "Built $OUT_DIR/$LIB_NAME"